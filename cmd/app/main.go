@@ -2,6 +2,8 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"flag"
 	"log"
 	"os"
 	"os/signal"
@@ -15,6 +17,10 @@ import (
 
 const (
 	defaultPath = "config/config.yaml"
+
+	commandServe       = "serve"
+	commandMigrateKeys = "migrate-keys"
+	commandGC          = "gc"
 )
 
 // @title MusicMan Backend API
@@ -29,12 +35,15 @@ const (
 // @description JWT токен в формате: "Bearer {token}"
 
 func main() {
-	path := defaultPath
-	if len(os.Args) > 2 {
-		path = os.Args[1]
+	path := flag.String("config", defaultPath, "путь до конфига")
+	flag.Parse()
+
+	command := flag.Arg(0)
+	if command == "" {
+		command = commandServe
 	}
 
-	cfg, err := config.ParseConfig(path)
+	cfg, err := config.ParseConfig(*path)
 	if err != nil {
 		log.Fatalf("parsing config: %s", err.Error())
 	}
@@ -46,8 +55,47 @@ func main() {
 		log.Fatalf("creating application: %s", err.Error())
 	}
 
+	switch command {
+	case commandServe:
+		serve(ctx, application)
+	case commandMigrateKeys:
+		err = application.MigrateObjectKeys(ctx)
+		application.Shutdown(ctx)
+		if err != nil {
+			log.Fatalf("migrating object keys: %s", err.Error())
+		}
+	case commandGC:
+		err = collectGarbage(ctx, application, flag.Args()[1:])
+		application.Shutdown(ctx)
+		if err != nil {
+			log.Fatalf("collecting garbage: %s", err.Error())
+		}
+	default:
+		application.Shutdown(ctx)
+		log.Fatalf("unknown command: %s", command)
+	}
+}
+
+// collectGarbage - musicman gc [-delete]: печатает отчет о сверке MinIO с базой в stdout
+func collectGarbage(ctx context.Context, application *app.App, args []string) error {
+	flags := flag.NewFlagSet(commandGC, flag.ExitOnError)
+	deleteOrphans := flags.Bool("delete", false, "удалить осиротевшие объекты, а не только показать их")
+	_ = flags.Parse(args)
+
+	report, err := application.CollectGarbage(ctx, *deleteOrphans)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(report)
+}
+
+func serve(ctx context.Context, application *app.App) {
 	go func(ctx context.Context) {
-		err = application.Run(ctx)
+		err := application.Run(ctx)
 		if err != nil {
 			log.Fatalf("running application: %s", err.Error())
 		}
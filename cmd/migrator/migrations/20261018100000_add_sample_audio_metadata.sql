@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+ALTER TABLE samples ALTER COLUMN minio_key SET DEFAULT '';
+ALTER TABLE samples ADD COLUMN audio_version INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE samples ADD COLUMN audio_hash VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE samples ADD COLUMN original_filename VARCHAR(255) NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+ALTER TABLE samples DROP COLUMN original_filename;
+ALTER TABLE samples DROP COLUMN audio_hash;
+ALTER TABLE samples DROP COLUMN audio_version;
+ALTER TABLE samples ALTER COLUMN minio_key DROP DEFAULT;
+-- +goose StatementEnd
@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS sample_versions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    sample_id UUID NOT NULL REFERENCES samples(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    minio_key VARCHAR(500) NOT NULL,
+    hash VARCHAR(64) NOT NULL,
+    original_filename VARCHAR(255) NOT NULL DEFAULT '',
+    size BIGINT NOT NULL,
+    duration FLOAT NOT NULL,
+    uploaded_at TIMESTAMP NOT NULL,
+    UNIQUE(sample_id, version)
+);
+
+INSERT INTO sample_versions (sample_id, version, minio_key, hash, original_filename, size, duration, uploaded_at)
+SELECT id, audio_version, minio_key, audio_hash, original_filename, size, duration, updated_at
+FROM samples
+WHERE audio_version > 0;
+
+-- sample_version = 0 - покупка сделана до версионирования, отдаем текущую версию
+ALTER TABLE purchases ADD COLUMN sample_version INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE purchases ADD COLUMN follow_updates BOOLEAN NOT NULL DEFAULT FALSE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE purchases DROP COLUMN follow_updates;
+ALTER TABLE purchases DROP COLUMN sample_version;
+DROP TABLE IF EXISTS sample_versions;
+-- +goose StatementEnd
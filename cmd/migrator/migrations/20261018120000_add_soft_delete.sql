@@ -0,0 +1,38 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users ADD COLUMN role VARCHAR(32) NOT NULL DEFAULT 'user';
+
+ALTER TABLE samples ADD COLUMN deleted_at TIMESTAMP;
+ALTER TABLE packs ADD COLUMN deleted_at TIMESTAMP;
+
+CREATE INDEX idx_samples_deleted_at ON samples(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_packs_deleted_at ON packs(deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- покупки не должны пропадать вместе с семплом, а удаление пака не должно удалять семплы
+ALTER TABLE purchases DROP CONSTRAINT purchases_sample_id_fkey;
+ALTER TABLE purchases ADD CONSTRAINT purchases_sample_id_fkey
+    FOREIGN KEY (sample_id) REFERENCES samples(id) ON DELETE RESTRICT;
+
+ALTER TABLE samples DROP CONSTRAINT samples_pack_id_fkey;
+ALTER TABLE samples ADD CONSTRAINT samples_pack_id_fkey
+    FOREIGN KEY (pack_id) REFERENCES packs(id) ON DELETE SET NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE samples DROP CONSTRAINT samples_pack_id_fkey;
+ALTER TABLE samples ADD CONSTRAINT samples_pack_id_fkey
+    FOREIGN KEY (pack_id) REFERENCES packs(id) ON DELETE CASCADE;
+
+ALTER TABLE purchases DROP CONSTRAINT purchases_sample_id_fkey;
+ALTER TABLE purchases ADD CONSTRAINT purchases_sample_id_fkey
+    FOREIGN KEY (sample_id) REFERENCES samples(id) ON DELETE CASCADE;
+
+DROP INDEX IF EXISTS idx_packs_deleted_at;
+DROP INDEX IF EXISTS idx_samples_deleted_at;
+
+ALTER TABLE packs DROP COLUMN deleted_at;
+ALTER TABLE samples DROP COLUMN deleted_at;
+
+ALTER TABLE users DROP COLUMN role;
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE rate_limits (
+    key VARCHAR(512) PRIMARY KEY,
+    tokens DOUBLE PRECISION NOT NULL DEFAULT 0,
+    failures INT NOT NULL DEFAULT 0,
+    locked_until TIMESTAMP,
+    updated_at TIMESTAMP
+);
+
+CREATE INDEX idx_rate_limits_updated_at ON rate_limits(updated_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE rate_limits;
+-- +goose StatementEnd
@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+-- вход через внешних провайдеров: один аккаунт провайдера - один пользователь
+CREATE TABLE user_identities (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_uuid UUID NOT NULL REFERENCES users(uuid) ON DELETE CASCADE,
+    provider VARCHAR(32) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    email VARCHAR(320) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (provider, subject),
+    UNIQUE (user_uuid, provider)
+);
+
+-- state и code_verifier начатых входов, user_uuid заполнен при привязке к существующему аккаунту
+CREATE TABLE oauth_states (
+    state VARCHAR(64) PRIMARY KEY,
+    provider VARCHAR(32) NOT NULL,
+    code_verifier VARCHAR(128) NOT NULL,
+    user_uuid UUID REFERENCES users(uuid) ON DELETE CASCADE,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_oauth_states_expires_at ON oauth_states(expires_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE oauth_states;
+DROP TABLE user_identities;
+-- +goose StatementEnd
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users ADD COLUMN display_name VARCHAR(64);
+ALTER TABLE users ADD COLUMN bio TEXT;
+ALTER TABLE users ADD COLUMN links TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE users ADD COLUMN avatar_key VARCHAR(500);
+-- удаленный аккаунт не удаляется строкой: на него ссылаются покупки и платежи
+ALTER TABLE users ADD COLUMN deleted_at TIMESTAMP;
+
+CREATE INDEX idx_samples_author ON samples(author);
+CREATE INDEX idx_packs_author ON packs(author);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX idx_packs_author;
+DROP INDEX idx_samples_author;
+
+ALTER TABLE users DROP COLUMN deleted_at;
+ALTER TABLE users DROP COLUMN avatar_key;
+ALTER TABLE users DROP COLUMN links;
+ALTER TABLE users DROP COLUMN bio;
+ALTER TABLE users DROP COLUMN display_name;
+-- +goose StatementEnd
@@ -0,0 +1,51 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE samples ADD COLUMN author_uuid UUID;
+ALTER TABLE packs ADD COLUMN author_uuid UUID;
+
+-- раньше автора указывали логином вручную, у таких записей заводим удаленный аккаунт без пароля,
+-- чтобы контент не потерял автора
+INSERT INTO users (login, password, deleted_at)
+SELECT DISTINCT a.author, '', now()
+FROM (SELECT author FROM samples UNION SELECT author FROM packs) a
+WHERE NOT EXISTS (SELECT 1 FROM users u WHERE u.login = a.author);
+
+UPDATE samples s SET author_uuid = u.uuid FROM users u WHERE u.login = s.author;
+UPDATE packs p SET author_uuid = u.uuid FROM users u WHERE u.login = p.author;
+
+ALTER TABLE samples ALTER COLUMN author_uuid SET NOT NULL;
+ALTER TABLE packs ALTER COLUMN author_uuid SET NOT NULL;
+
+ALTER TABLE samples ADD CONSTRAINT samples_author_uuid_fkey
+    FOREIGN KEY (author_uuid) REFERENCES users(uuid) ON DELETE RESTRICT;
+ALTER TABLE packs ADD CONSTRAINT packs_author_uuid_fkey
+    FOREIGN KEY (author_uuid) REFERENCES users(uuid) ON DELETE RESTRICT;
+
+DROP INDEX idx_samples_author;
+DROP INDEX idx_packs_author;
+ALTER TABLE samples DROP COLUMN author;
+ALTER TABLE packs DROP COLUMN author;
+
+CREATE INDEX idx_samples_author_uuid ON samples(author_uuid);
+CREATE INDEX idx_packs_author_uuid ON packs(author_uuid);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE samples ADD COLUMN author VARCHAR(255);
+ALTER TABLE packs ADD COLUMN author VARCHAR(255);
+
+UPDATE samples s SET author = u.login FROM users u WHERE u.uuid = s.author_uuid;
+UPDATE packs p SET author = u.login FROM users u WHERE u.uuid = p.author_uuid;
+
+ALTER TABLE samples ALTER COLUMN author SET NOT NULL;
+ALTER TABLE packs ALTER COLUMN author SET NOT NULL;
+
+CREATE INDEX idx_samples_author ON samples(author);
+CREATE INDEX idx_packs_author ON packs(author);
+
+DROP INDEX idx_packs_author_uuid;
+DROP INDEX idx_samples_author_uuid;
+ALTER TABLE packs DROP COLUMN author_uuid;
+ALTER TABLE samples DROP COLUMN author_uuid;
+-- +goose StatementEnd
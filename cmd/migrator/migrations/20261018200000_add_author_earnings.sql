@@ -0,0 +1,45 @@
+-- +goose Up
+-- +goose StatementBegin
+-- NULL - комиссия площадки по умолчанию из конфига
+ALTER TABLE users ADD COLUMN commission_percent SMALLINT
+    CHECK (commission_percent BETWEEN 0 AND 100);
+
+-- доля автора с каждой покупки, строки только добавляются
+CREATE TABLE author_earnings (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    author_uuid UUID NOT NULL REFERENCES users(uuid) ON DELETE RESTRICT,
+    purchase_id UUID NOT NULL UNIQUE REFERENCES purchases(id) ON DELETE RESTRICT,
+    sample_id UUID NOT NULL REFERENCES samples(id) ON DELETE RESTRICT,
+    price INTEGER NOT NULL,
+    commission_percent SMALLINT NOT NULL,
+    commission INTEGER NOT NULL,
+    amount INTEGER NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_author_earnings_author_uuid ON author_earnings(author_uuid);
+
+CREATE TABLE payouts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    author_uuid UUID NOT NULL REFERENCES users(uuid) ON DELETE RESTRICT,
+    amount INTEGER NOT NULL CHECK (amount > 0),
+    status VARCHAR(16) NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    reviewed_by UUID REFERENCES users(uuid) ON DELETE SET NULL,
+    reviewed_at TIMESTAMP,
+    reject_reason TEXT,
+    external_id VARCHAR(255),
+    failure TEXT,
+    paid_at TIMESTAMP
+);
+
+CREATE INDEX idx_payouts_author_uuid ON payouts(author_uuid);
+CREATE INDEX idx_payouts_status ON payouts(status);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE payouts;
+DROP TABLE author_earnings;
+ALTER TABLE users DROP COLUMN commission_percent;
+-- +goose StatementEnd
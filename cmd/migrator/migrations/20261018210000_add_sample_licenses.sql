@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+-- лицензии, которые автор продает на семпл. samples.price - минимальная цена среди них, 0 - семпл бесплатный
+CREATE TABLE sample_licenses (
+    sample_id UUID NOT NULL REFERENCES samples(id) ON DELETE CASCADE,
+    license_type VARCHAR(16) NOT NULL,
+    price INTEGER NOT NULL CHECK (price > 0),
+    PRIMARY KEY (sample_id, license_type)
+);
+
+-- раньше покупка давала бессрочное право на коммерческое использование
+INSERT INTO sample_licenses (sample_id, license_type, price)
+SELECT id, 'commercial', price FROM samples WHERE price > 0;
+
+ALTER TABLE purchases ADD COLUMN license VARCHAR(16) NOT NULL DEFAULT 'commercial';
+ALTER TABLE purchases ALTER COLUMN license DROP DEFAULT;
+
+-- семпл снят с продажи после покупки эксклюзивной лицензии, покупатели сохраняют доступ
+ALTER TABLE samples ADD COLUMN delisted_at TIMESTAMP;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE samples DROP COLUMN delisted_at;
+ALTER TABLE purchases DROP COLUMN license;
+DROP TABLE sample_licenses;
+-- +goose StatementEnd
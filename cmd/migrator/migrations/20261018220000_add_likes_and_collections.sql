@@ -0,0 +1,53 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE sample_likes (
+    user_uuid UUID NOT NULL REFERENCES users(uuid) ON DELETE CASCADE,
+    sample_id UUID NOT NULL REFERENCES samples(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_uuid, sample_id)
+);
+CREATE INDEX idx_sample_likes_sample ON sample_likes(sample_id);
+
+CREATE TABLE pack_likes (
+    user_uuid UUID NOT NULL REFERENCES users(uuid) ON DELETE CASCADE,
+    pack_id UUID NOT NULL REFERENCES packs(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_uuid, pack_id)
+);
+CREATE INDEX idx_pack_likes_pack ON pack_likes(pack_id);
+
+-- счетчики лайков храним в самих записях, чтобы сортировка по популярности не считала лайки на каждый запрос
+ALTER TABLE samples ADD COLUMN like_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE packs ADD COLUMN like_count INTEGER NOT NULL DEFAULT 0;
+CREATE INDEX idx_samples_like_count ON samples(like_count DESC, created_at DESC) WHERE deleted_at IS NULL;
+
+CREATE TABLE collections (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_uuid UUID NOT NULL REFERENCES users(uuid) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    is_public BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP NOT NULL DEFAULT now()
+);
+CREATE INDEX idx_collections_user ON collections(user_uuid);
+
+CREATE TABLE collection_samples (
+    collection_id UUID NOT NULL REFERENCES collections(id) ON DELETE CASCADE,
+    sample_id UUID NOT NULL REFERENCES samples(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    added_at TIMESTAMP NOT NULL DEFAULT now(),
+    PRIMARY KEY (collection_id, sample_id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE collection_samples;
+DROP TABLE collections;
+DROP INDEX idx_samples_like_count;
+ALTER TABLE packs DROP COLUMN like_count;
+ALTER TABLE samples DROP COLUMN like_count;
+DROP TABLE pack_likes;
+DROP TABLE sample_likes;
+-- +goose StatementEnd
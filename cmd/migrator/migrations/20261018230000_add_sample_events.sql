@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+-- сырые события только дописываются, старые удаляются после сворачивания в дневную статистику
+CREATE TABLE sample_events (
+    id BIGSERIAL PRIMARY KEY,
+    sample_id UUID NOT NULL REFERENCES samples(id) ON DELETE CASCADE,
+    user_uuid UUID,
+    event_type VARCHAR(16) NOT NULL,
+    amount INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL
+);
+CREATE INDEX idx_sample_events_created_at ON sample_events(created_at);
+
+CREATE TABLE sample_stats_daily (
+    day DATE NOT NULL,
+    sample_id UUID NOT NULL REFERENCES samples(id) ON DELETE CASCADE,
+    plays INTEGER NOT NULL DEFAULT 0,
+    downloads INTEGER NOT NULL DEFAULT 0,
+    purchases INTEGER NOT NULL DEFAULT 0,
+    revenue INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (day, sample_id)
+);
+CREATE INDEX idx_sample_stats_daily_sample ON sample_stats_daily(sample_id, day);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE sample_stats_daily;
+DROP TABLE sample_events;
+-- +goose StatementEnd
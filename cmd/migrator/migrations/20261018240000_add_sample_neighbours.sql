@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+-- похожие семплы пересчитываются фоновой задачей целиком, между пересчетами таблица только читается
+CREATE TABLE sample_neighbours (
+    sample_id UUID NOT NULL REFERENCES samples(id) ON DELETE CASCADE,
+    neighbour_id UUID NOT NULL REFERENCES samples(id) ON DELETE CASCADE,
+    score DOUBLE PRECISION NOT NULL,
+    PRIMARY KEY (sample_id, neighbour_id)
+);
+CREATE INDEX idx_sample_neighbours_score ON sample_neighbours(sample_id, score DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE sample_neighbours;
+-- +goose StatementEnd
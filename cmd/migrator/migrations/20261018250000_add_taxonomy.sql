@@ -0,0 +1,82 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE genres (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    slug VARCHAR(50) NOT NULL UNIQUE,
+    name VARCHAR(50) NOT NULL,
+    parent_id UUID REFERENCES genres(id) ON DELETE RESTRICT,
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+CREATE INDEX idx_genres_parent ON genres(parent_id);
+
+-- жанр семпла и пака становится slug из справочника: "Hip Hop" и "hip-hop" склеиваются сразу,
+-- остальные дубли вроде "hiphop" админ объединяет через merge
+UPDATE samples SET genre = coalesce(nullif(trim(BOTH '-' FROM regexp_replace(lower(genre), '[^[:alnum:]]+', '-', 'g')), ''), 'other');
+UPDATE packs SET genre = coalesce(nullif(trim(BOTH '-' FROM regexp_replace(lower(genre), '[^[:alnum:]]+', '-', 'g')), ''), 'other');
+
+INSERT INTO genres (slug, name)
+SELECT g.slug, initcap(replace(g.slug, '-', ' '))
+FROM (SELECT genre AS slug FROM samples UNION SELECT genre FROM packs) g;
+
+ALTER TABLE samples ADD CONSTRAINT samples_genre_fkey
+    FOREIGN KEY (genre) REFERENCES genres(slug) ON UPDATE CASCADE;
+ALTER TABLE packs ADD CONSTRAINT packs_genre_fkey
+    FOREIGN KEY (genre) REFERENCES genres(slug) ON UPDATE CASCADE;
+CREATE INDEX idx_samples_genre ON samples(genre);
+
+-- kind: tag - свободные теги авторов, instrument и mood - справочники, которые ведет админ
+CREATE TABLE tags (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    kind VARCHAR(16) NOT NULL,
+    slug VARCHAR(50) NOT NULL,
+    name VARCHAR(50) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    UNIQUE (kind, slug)
+);
+
+CREATE TABLE sample_tags (
+    sample_id UUID NOT NULL REFERENCES samples(id) ON DELETE CASCADE,
+    tag_id UUID NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (sample_id, tag_id)
+);
+CREATE INDEX idx_sample_tags_tag ON sample_tags(tag_id);
+
+CREATE TABLE pack_tags (
+    pack_id UUID NOT NULL REFERENCES packs(id) ON DELETE CASCADE,
+    tag_id UUID NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (pack_id, tag_id)
+);
+CREATE INDEX idx_pack_tags_tag ON pack_tags(tag_id);
+
+INSERT INTO tags (kind, slug, name) VALUES
+    ('instrument', 'drums', 'Drums'),
+    ('instrument', 'percussion', 'Percussion'),
+    ('instrument', 'bass', 'Bass'),
+    ('instrument', 'guitar', 'Guitar'),
+    ('instrument', 'piano', 'Piano'),
+    ('instrument', 'synth', 'Synth'),
+    ('instrument', 'strings', 'Strings'),
+    ('instrument', 'brass', 'Brass'),
+    ('instrument', 'vocals', 'Vocals'),
+    ('instrument', 'fx', 'FX'),
+    ('mood', 'dark', 'Dark'),
+    ('mood', 'chill', 'Chill'),
+    ('mood', 'happy', 'Happy'),
+    ('mood', 'sad', 'Sad'),
+    ('mood', 'energetic', 'Energetic'),
+    ('mood', 'aggressive', 'Aggressive'),
+    ('mood', 'epic', 'Epic'),
+    ('mood', 'romantic', 'Romantic');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE pack_tags;
+DROP TABLE sample_tags;
+DROP TABLE tags;
+
+DROP INDEX idx_samples_genre;
+ALTER TABLE packs DROP CONSTRAINT packs_genre_fkey;
+ALTER TABLE samples DROP CONSTRAINT samples_genre_fkey;
+DROP TABLE genres;
+-- +goose StatementEnd
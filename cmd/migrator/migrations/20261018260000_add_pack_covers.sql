@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- cover_key - ключ обложки в бакете covers, от него строятся ключи миниатюр
+ALTER TABLE packs ADD COLUMN cover_key VARCHAR(255);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE packs DROP COLUMN cover_key;
+-- +goose StatementEnd
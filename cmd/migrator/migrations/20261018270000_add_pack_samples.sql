@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+-- семпл может входить в несколько паков, position - номер трека внутри пака
+CREATE TABLE pack_samples (
+    pack_id UUID NOT NULL REFERENCES packs(id) ON DELETE CASCADE,
+    sample_id UUID NOT NULL REFERENCES samples(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    added_at TIMESTAMP NOT NULL DEFAULT now(),
+    PRIMARY KEY (pack_id, sample_id)
+);
+CREATE INDEX idx_pack_samples_sample ON pack_samples(sample_id);
+
+INSERT INTO pack_samples (pack_id, sample_id, position, added_at)
+SELECT pack_id, id, row_number() OVER (PARTITION BY pack_id ORDER BY created_at, id), created_at
+FROM samples
+WHERE pack_id IS NOT NULL;
+
+ALTER TABLE samples DROP COLUMN pack_id;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE samples ADD COLUMN pack_id UUID;
+ALTER TABLE samples ADD CONSTRAINT samples_pack_id_fkey
+    FOREIGN KEY (pack_id) REFERENCES packs(id) ON DELETE SET NULL;
+
+-- в старой схеме семпл принадлежит одному паку - оставляем тот, куда он попал первым
+UPDATE samples s SET pack_id = ps.pack_id
+FROM (
+    SELECT DISTINCT ON (sample_id) sample_id, pack_id
+    FROM pack_samples
+    ORDER BY sample_id, added_at, position
+) ps
+WHERE ps.sample_id = s.id;
+
+DROP TABLE pack_samples;
+-- +goose StatementEnd
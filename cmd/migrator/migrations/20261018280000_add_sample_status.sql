@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+-- status: draft -> pending (на модерации) -> published, delisted - продан эксклюзивно.
+-- publish_at - отложенная публикация: опубликованный семпл виден в каталоге только с этого момента
+ALTER TABLE samples ADD COLUMN status VARCHAR(16) NOT NULL DEFAULT 'draft';
+ALTER TABLE samples ADD COLUMN publish_at TIMESTAMP;
+ALTER TABLE samples ADD COLUMN submitted_at TIMESTAMP;
+ALTER TABLE samples ADD COLUMN reviewed_by UUID REFERENCES users(uuid) ON DELETE SET NULL;
+ALTER TABLE samples ADD COLUMN reviewed_at TIMESTAMP;
+ALTER TABLE samples ADD COLUMN reject_reason TEXT;
+
+-- семплы, которые уже видны в каталоге, модерацию не проходят. Ключ назначался при создании,
+-- поэтому опубликованными считаются только семплы с реально загруженным аудио
+UPDATE samples SET status = CASE
+    WHEN delisted_at IS NOT NULL THEN 'delisted'
+    WHEN size > 0 OR EXISTS (SELECT 1 FROM sample_versions v WHERE v.sample_id = samples.id) THEN 'published'
+    ELSE 'draft'
+END;
+
+CREATE INDEX idx_samples_status ON samples(status);
+CREATE INDEX idx_samples_pending ON samples(submitted_at) WHERE status = 'pending';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX idx_samples_pending;
+DROP INDEX idx_samples_status;
+
+ALTER TABLE samples DROP COLUMN reject_reason;
+ALTER TABLE samples DROP COLUMN reviewed_at;
+ALTER TABLE samples DROP COLUMN reviewed_by;
+ALTER TABLE samples DROP COLUMN submitted_at;
+ALTER TABLE samples DROP COLUMN publish_at;
+ALTER TABLE samples DROP COLUMN status;
+-- +goose StatementEnd
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+-- адрес занимает только подтвержденная почта: иначе чужой адрес можно было бы указать при регистрации
+-- и закрыть владельцу регистрацию, подтверждение и сброс пароля
+DROP INDEX idx_users_email;
+CREATE UNIQUE INDEX idx_users_email ON users(lower(email)) WHERE email_verified_at IS NOT NULL;
+CREATE INDEX idx_users_email_lookup ON users(lower(email)) WHERE email IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- в старой схеме адрес уникален среди всех: оставляем подтвержденный, иначе любой один
+UPDATE users u SET email = NULL
+WHERE u.email IS NOT NULL AND u.uuid <> (
+    SELECT o.uuid FROM users o
+    WHERE lower(o.email) = lower(u.email)
+    ORDER BY o.email_verified_at IS NULL, o.uuid
+    LIMIT 1
+);
+
+DROP INDEX idx_users_email_lookup;
+DROP INDEX idx_users_email;
+CREATE UNIQUE INDEX idx_users_email ON users(lower(email)) WHERE email IS NOT NULL;
+-- +goose StatementEnd
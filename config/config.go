@@ -3,19 +3,38 @@ package config
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
 type Config struct {
-	Postgres Postgres    `yaml:"postgres"`
-	Http     HttpConfig  `yaml:"http"`
-	Minio    MinioConfig `yaml:"minio"`
-	YooKassa YooKassa   `yaml:"yookassa"`
+	Postgres        Postgres        `yaml:"postgres"`
+	Http            HttpConfig      `yaml:"http"`
+	Minio           MinioConfig     `yaml:"minio"`
+	YooKassa        YooKassa        `yaml:"yookassa"`
+	Trash           Trash           `yaml:"trash"`
+	Uploads         Uploads         `yaml:"uploads"`
+	GC              GC              `yaml:"gc"`
+	Storage         Storage         `yaml:"storage"`
+	RateLimit       RateLimit       `yaml:"rate_limit"`
+	Mail            Mail            `yaml:"mail"`
+	Accounts        Accounts        `yaml:"accounts"`
+	OAuth           OAuth           `yaml:"oauth"`
+	TwoFactor       TwoFactor       `yaml:"two_factor"`
+	Sessions        Sessions        `yaml:"sessions"`
+	Revenue         Revenue         `yaml:"revenue"`
+	Signing         Signing         `yaml:"signing"`
+	Analytics       Analytics       `yaml:"analytics"`
+	Recommendations Recommendations `yaml:"recommendations"`
+	Catalog         Catalog         `yaml:"catalog"`
 }
 
 type HttpConfig struct {
 	Addr string `yaml:"addr"`
+	// TrustedProxies адреса и подсети прокси, которым можно верить в X-Forwarded-For.
+	// Пусто - заголовок игнорируется и ip клиента берется из соединения
+	TrustedProxies []string `yaml:"trusted_proxies"`
 }
 
 type MinioConfig struct {
@@ -89,6 +108,211 @@ type YooKassa struct {
 	AccountID string `yaml:"account_id"`
 }
 
+type Trash struct {
+	// Retention сколько удаленные семплы и паки можно восстановить
+	Retention time.Duration `yaml:"retention"`
+	// PurgeInterval как часто чистить корзину от просроченного
+	PurgeInterval time.Duration `yaml:"purge_interval"`
+}
+
+type Uploads struct {
+	// TempDir куда складывать загружаемые файлы до отправки в хранилище, пусто - системный temp
+	TempDir string `yaml:"temp_dir"`
+}
+
+type GC struct {
+	// Interval как часто сверять хранилище с базой
+	Interval time.Duration `yaml:"interval"`
+	// DeleteOrphans удалять ли осиротевшие объекты по расписанию, иначе только отчет
+	DeleteOrphans bool `yaml:"delete_orphans"`
+	// MinObjectAge объекты моложе не трогаем - они могут загружаться прямо сейчас
+	MinObjectAge time.Duration `yaml:"min_object_age"`
+	// TempFileMaxAge временные файлы старше удаляются
+	TempFileMaxAge time.Duration `yaml:"temp_file_max_age"`
+}
+
+const (
+	StorageMinio  = "minio"
+	StorageLocal  = "local"
+	StorageMemory = "memory"
+)
+
+type Storage struct {
+	// Backend где хранить файлы: minio (по умолчанию), local или memory
+	Backend string       `yaml:"backend"`
+	Local   LocalStorage `yaml:"local"`
+}
+
+type LocalStorage struct {
+	// Root каталог, в котором лежат бакеты
+	Root string `yaml:"root"`
+	// BaseURL адрес приложения, от которого строятся ссылки на файлы
+	BaseURL string `yaml:"base_url"`
+	// Secret ключ подписи ссылок
+	Secret string `yaml:"secret"`
+	// URLTTL сколько живет подписанная ссылка
+	URLTTL time.Duration `yaml:"url_ttl"`
+}
+
+const (
+	RateLimitStoreMemory   = "memory"
+	RateLimitStorePostgres = "postgres"
+)
+
+type RateLimit struct {
+	// Store где держать счетчики: memory (в процессе) или postgres (общие для всех инстансов)
+	Store string `yaml:"store"`
+	// SignIn и SignUp ограничивают запросы с одного ip
+	SignIn Limit `yaml:"sign_in"`
+	SignUp Limit `yaml:"sign_up"`
+	// ForgotPassword ограничивает запросы писем со сбросом пароля с одного ip
+	ForgotPassword Limit `yaml:"forgot_password"`
+	// Login ограничивает попытки входа в один аккаунт со всех ip
+	Login   Limit   `yaml:"login"`
+	Lockout Lockout `yaml:"lockout"`
+	// IdleTTL через сколько забывать неактивные ключи
+	IdleTTL time.Duration `yaml:"idle_ttl"`
+}
+
+// Limit - токен-бакет: Requests запросов за Period, не больше Burst подряд
+type Limit struct {
+	Requests int           `yaml:"requests"`
+	Period   time.Duration `yaml:"period"`
+	Burst    int           `yaml:"burst"`
+}
+
+// Lockout - после Threshold неудачных входов подряд аккаунт блокируется на BaseDuration,
+// каждая следующая неудача удваивает блокировку, но не больше MaxDuration
+type Lockout struct {
+	Threshold    int           `yaml:"threshold"`
+	BaseDuration time.Duration `yaml:"base_duration"`
+	MaxDuration  time.Duration `yaml:"max_duration"`
+}
+
+const (
+	MailDriverSMTP = "smtp"
+	MailDriverFile = "file"
+)
+
+type Mail struct {
+	// Driver smtp или file, file с пустым FilePath пишет письма в лог
+	Driver   string `yaml:"driver"`
+	From     string `yaml:"from"`
+	FilePath string `yaml:"file_path"`
+	SMTP     SMTP   `yaml:"smtp"`
+}
+
+type SMTP struct {
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+}
+
+type Accounts struct {
+	// Secret ключ подписи токенов из писем
+	Secret string `yaml:"secret"`
+	// VerifyEmailURL и ResetPasswordURL - страницы фронтенда, токен добавляется параметром token
+	VerifyEmailURL   string        `yaml:"verify_email_url"`
+	ResetPasswordURL string        `yaml:"reset_password_url"`
+	VerifyEmailTTL   time.Duration `yaml:"verify_email_ttl"`
+	ResetPasswordTTL time.Duration `yaml:"reset_password_ttl"`
+}
+
+type OAuth struct {
+	// StateTTL сколько ждать возврата пользователя от провайдера
+	StateTTL time.Duration `yaml:"state_ttl"`
+	// Providers провайдеры по имени, провайдер без client_id выключен
+	Providers map[string]OAuthProvider `yaml:"providers"`
+}
+
+type OAuthProvider struct {
+	ClientID     string `yaml:"client_id"`
+	ClientSecret string `yaml:"client_secret"`
+	AuthURL      string `yaml:"auth_url"`
+	TokenURL     string `yaml:"token_url"`
+	UserInfoURL  string `yaml:"userinfo_url"`
+	// UserInfoAuth как передавать токен в userinfo: bearer, oauth или form
+	UserInfoAuth string `yaml:"userinfo_auth"`
+	// RedirectURL страница фронтенда, которая передает code и state в callback
+	RedirectURL string   `yaml:"redirect_url"`
+	Scopes      []string `yaml:"scopes"`
+	// поля ответа userinfo, вложенные через точку: "user.user_id"
+	SubjectField       string `yaml:"subject_field"`
+	EmailField         string `yaml:"email_field"`
+	EmailVerifiedField string `yaml:"email_verified_field"`
+	LoginField         string `yaml:"login_field"`
+	// TrustEmail провайдер отдает только подтвержденную почту
+	TrustEmail bool `yaml:"trust_email"`
+}
+
+type TwoFactor struct {
+	// Issuer подпись аккаунта в приложении-аутентификаторе
+	Issuer string `yaml:"issuer"`
+	// ChallengeTTL сколько действует токен между вводом пароля и кода
+	ChallengeTTL time.Duration `yaml:"challenge_ttl"`
+	// PurchaseThreshold покупки от этой цены в токенах требуют код 2FA, 0 - не требуют
+	PurchaseThreshold int `yaml:"purchase_threshold"`
+}
+
+type Sessions struct {
+	// TTL срок жизни сессии и ее JWT
+	TTL time.Duration `yaml:"ttl"`
+	// TouchInterval как часто запрос сверяет сессию с базой и обновляет last_seen.
+	// Отзыв на другом инстансе доходит не позже чем через этот интервал
+	TouchInterval time.Duration `yaml:"touch_interval"`
+	// Retention сколько хранить отозванные и истекшие сессии
+	Retention time.Duration `yaml:"retention"`
+	// LegacyTokensUntil до этого момента принимаются токены без сессии, выданные до появления сессий,
+	// чтобы обновление не разлогинило всех сразу. Такие токены нельзя отозвать, они действуют до своего exp.
+	// Не задано - токены без сессии отклоняются
+	LegacyTokensUntil time.Time `yaml:"legacy_tokens_until"`
+}
+
+type Revenue struct {
+	// Commission комиссия площадки в процентах от цены, если автору не задана своя
+	Commission int `yaml:"commission"`
+	// MinPayout минимальная сумма вывода в токенах
+	MinPayout int `yaml:"min_payout"`
+}
+
+type Signing struct {
+	// PrivateKey seed ключа Ed25519 в base64 для подписи сертификатов и чеков, без него приложение не запускается
+	PrivateKey string `yaml:"private_key"`
+	// RetiredPublicKeys публичные ключи в base64, которыми подписывали до смены ключа. Выданные под ними
+	// документы продолжают проверяться
+	RetiredPublicKeys []string `yaml:"retired_public_keys"`
+	// EphemeralKey - только для разработки: без PrivateKey ключ генерируется на каждый запуск,
+	// и подписи перестают проверяться после перезапуска
+	EphemeralKey bool `yaml:"ephemeral_key"`
+}
+
+type Analytics struct {
+	// BufferSize сколько событий ждут записи в памяти, при переполнении новые события теряются
+	BufferSize int `yaml:"buffer_size"`
+	// BatchSize и FlushInterval - события пишутся пачкой, когда набралось BatchSize или прошел FlushInterval
+	BatchSize     int           `yaml:"batch_size"`
+	FlushInterval time.Duration `yaml:"flush_interval"`
+	// RollupInterval как часто сворачивать события в дневную статистику
+	RollupInterval time.Duration `yaml:"rollup_interval"`
+	// EventRetention сколько хранить сырые события после свертки
+	EventRetention time.Duration `yaml:"event_retention"`
+}
+
+type Recommendations struct {
+	// Interval как часто пересчитывать похожие семплы
+	Interval time.Duration `yaml:"interval"`
+	// Neighbours сколько похожих хранить на каждый семпл
+	Neighbours int `yaml:"neighbours"`
+	// TrendingWindow за какой период считать популярное для новых пользователей и семплов без похожих
+	TrendingWindow time.Duration `yaml:"trending_window"`
+}
+
+type Catalog struct {
+	// FacetsTTL сколько держать в памяти посчитанные фасеты для одного набора фильтров
+	FacetsTTL time.Duration `yaml:"facets_ttl"`
+}
+
 func ParseConfig(path string) (*Config, error) {
 	f, err := os.Open(path)
 	if err != nil {
@@ -102,5 +326,35 @@ func ParseConfig(path string) (*Config, error) {
 		return nil, fmt.Errorf("error parsing config file: %v", err)
 	}
 
+	if err = cfg.setIntervals(); err != nil {
+		return nil, err
+	}
+
 	return &cfg, nil
 }
+
+// setIntervals подставляет интервалы фоновых задач по умолчанию вместо незаданных.
+// Отрицательный интервал - ошибка конфига, тикер с ним не запустится
+func (c *Config) setIntervals() error {
+	intervals := []struct {
+		name     string
+		value    *time.Duration
+		fallback time.Duration
+	}{
+		{"trash.purge_interval", &c.Trash.PurgeInterval, time.Hour},
+		{"gc.interval", &c.GC.Interval, 24 * time.Hour},
+		{"analytics.rollup_interval", &c.Analytics.RollupInterval, 10 * time.Minute},
+		{"recommendations.interval", &c.Recommendations.Interval, time.Hour},
+	}
+
+	for _, interval := range intervals {
+		if *interval.value < 0 {
+			return fmt.Errorf("%s must be positive, got %s", interval.name, *interval.value)
+		}
+		if *interval.value == 0 {
+			*interval.value = interval.fallback
+		}
+	}
+
+	return nil
+}
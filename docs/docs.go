@@ -15,51 +15,63 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/auth/sign-in": {
-            "post": {
-                "description": "Выполняет вход пользователя в систему и возвращает JWT токен",
-                "consumes": [
-                    "application/json"
+        "/admin/authors/{uuid}/commission": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
-                "produces": [
+                "description": "Задает автору свой процент комиссии, null - комиссия по умолчанию. Действует на следующие покупки",
+                "consumes": [
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "admin"
                 ],
-                "summary": "Аутентификация пользователя",
+                "summary": "Комиссия площадки для автора",
                 "parameters": [
                     {
-                        "description": "Данные для входа",
+                        "type": "string",
+                        "description": "UUID автора",
+                        "name": "uuid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Комиссия в процентах",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.LoginRequest"
+                            "$ref": "#/definitions/dto.CommissionRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Успешная аутентификация",
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/dto.LoginResponse"
+                            "$ref": "#/definitions/dto.ApiError"
                         }
                     },
-                    "400": {
-                        "description": "Неверный формат запроса",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/dto.ApiError"
                         }
                     },
-                    "401": {
-                        "description": "Неверные учетные данные",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/dto.ApiError"
                         }
                     },
                     "500": {
-                        "description": "Внутренняя ошибка сервера",
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/dto.ApiError"
                         }
@@ -67,9 +79,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/auth/sign-up": {
+        "/admin/genres": {
             "post": {
-                "description": "Создает нового пользователя и возвращает JWT токен",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "slug строится из названия",
                 "consumes": [
                     "application/json"
                 ],
@@ -77,41 +94,47 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "admin"
                 ],
-                "summary": "Регистрация нового пользователя",
+                "summary": "Добавить жанр",
                 "parameters": [
                     {
-                        "description": "Данные для регистрации",
+                        "description": "Жанр",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.RegisterRequest"
+                            "$ref": "#/definitions/dto.CreateGenreRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Успешная регистрация",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/dto.RegisterResponse"
+                            "$ref": "#/definitions/dto.GenreDTO"
                         }
                     },
                     "400": {
-                        "description": "Неверный формат запроса",
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/dto.ApiError"
                         }
                     },
                     "409": {
-                        "description": "Пользователь уже существует",
+                        "description": "Жанр с таким slug уже есть",
                         "schema": {
                             "$ref": "#/definitions/dto.ApiError"
                         }
                     },
                     "500": {
-                        "description": "Внутренняя ошибка сервера",
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/dto.ApiError"
                         }
@@ -119,28 +142,59 @@ const docTemplate = `{
                 }
             }
         },
-        "/packs": {
-            "get": {
+        "/admin/genres/{slug}": {
+            "patch": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
+                "description": "Новый slug сразу применяется к семплам и пакам жанра",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "packs"
+                    "admin"
+                ],
+                "summary": "Переименовать жанр или перенести в другой",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Slug жанра",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Изменения",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UpdateGenreRequest"
+                        }
+                    }
                 ],
-                "summary": "Получение всех паков (без семплов)",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/dto.PackDTO"
-                            }
+                            "$ref": "#/definitions/dto.GenreDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
                         }
                     },
                     "404": {
@@ -149,6 +203,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/dto.ApiError"
                         }
                     },
+                    "409": {
+                        "description": "Жанр с таким slug уже есть",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -156,13 +216,16 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/admin/genres/{slug}/merge": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
+                "description": "Семплы, паки и поджанры переносятся в жанр into, исходный жанр удаляется",
                 "consumes": [
                     "application/json"
                 ],
@@ -170,25 +233,32 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "packs"
+                    "admin"
                 ],
-                "summary": "Создает пак семплов (без семплов)",
+                "summary": "Объединить жанры",
                 "parameters": [
                     {
-                        "description": "Pack data",
+                        "type": "string",
+                        "description": "Slug исходного жанра",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Куда перенести",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.CreatePackRequest"
+                            "$ref": "#/definitions/dto.MergeGenreRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.UUIDResponse"
+                            "$ref": "#/definitions/dto.GenreDTO"
                         }
                     },
                     "400": {
@@ -197,6 +267,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/dto.ApiError"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -206,7 +288,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/packs/{id}": {
+        "/admin/payouts": {
             "get": {
                 "security": [
                     {
@@ -217,13 +299,68 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "packs"
+                    "admin"
                 ],
-                "summary": "Получает пак вместе с семплами (но без аудио содержимого)",
+                "summary": "Заявки на вывод всех авторов",
                 "parameters": [
                     {
+                        "enum": [
+                            "pending",
+                            "approved",
+                            "paid",
+                            "rejected",
+                            "failed"
+                        ],
                         "type": "string",
-                        "description": "Pack ID",
+                        "description": "Фильтр по статусу",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.PayoutDTO"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/payouts/{id}/approve": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Одобренная заявка сразу уходит на выплату. Если выплата не прошла, заявка получает статус failed и сумма возвращается на баланс автора",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Одобрить заявку на вывод",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID заявки",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -233,7 +370,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.PackWithSamplesResponse"
+                            "$ref": "#/definitions/dto.PayoutDTO"
                         }
                     },
                     "400": {
@@ -242,12 +379,24 @@ const docTemplate = `{
                             "$ref": "#/definitions/dto.ApiError"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/dto.ApiError"
                         }
                     },
+                    "409": {
+                        "description": "Заявка уже рассмотрена",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -255,8 +404,10 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/admin/payouts/{id}/reject": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
@@ -269,30 +420,33 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "packs"
+                    "admin"
                 ],
-                "summary": "Обновляет пак",
+                "summary": "Отклонить заявку на вывод",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Pack ID",
+                        "description": "ID заявки",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Update data",
+                        "description": "Причина отказа",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.UpdatePackRequest"
+                            "$ref": "#/definitions/dto.RejectPayoutRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PayoutDTO"
+                        }
                     },
                     "400": {
                         "description": "Bad Request",
@@ -300,6 +454,24 @@ const docTemplate = `{
                             "$ref": "#/definitions/dto.ApiError"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "409": {
+                        "description": "Заявка уже рассмотрена",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -307,29 +479,38 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/admin/samples/pending": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "tags": [
-                    "packs"
+                "description": "Семплы, отправленные на модерацию, раньше отправленные первыми",
+                "produces": [
+                    "application/json"
                 ],
-                "summary": "Удаляет пак",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Pack ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
+                "tags": [
+                    "admin"
                 ],
+                "summary": "Очередь модерации семплов",
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.SampleDTO"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
                     },
                     "500": {
                         "description": "Internal Server Error",
@@ -340,143 +521,133 @@ const docTemplate = `{
                 }
             }
         },
-        "/payments/history": {
-            "get": {
-                "description": "Возвращает историю платежей текущего авторизованного пользователя",
+        "/admin/samples/{id}/approve": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Семпл публикуется сразу или в publish_at, если автор задал отложенную публикацию",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "payments"
+                    "admin"
                 ],
-                "summary": "Получить список платежей пользователя",
+                "summary": "Одобрить семпл",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Bearer токен",
-                        "name": "Authorization",
-                        "in": "header",
+                        "description": "Sample ID",
+                        "name": "id",
+                        "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Список платежей пользователя",
+                        "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/dto.UserPayment"
-                            }
+                            "$ref": "#/definitions/dto.SampleDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "409": {
+                        "description": "Семпл не на модерации",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
                         }
                     },
                     "500": {
-                        "description": "Ошибка сервера или не удалось получить платежи"
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
                     }
                 }
             }
         },
-        "/payments/new": {
+        "/admin/samples/{id}/reject": {
             "post": {
-                "description": "Создаёт платёж через YooKassa и перенаправляет пользователя на страницу оплаты.",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Семпл возвращается автору в черновики, причина отказа видна автору",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "payments"
+                    "admin"
                 ],
-                "summary": "Создание нового платежа",
+                "summary": "Отклонить семпл",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Bearer токен",
-                        "name": "Authorization",
-                        "in": "header",
+                        "description": "Sample ID",
+                        "name": "id",
+                        "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Данные для создания платежа. return_uri - ссылка на которую вернуть пользователя после оплаты. amount в копейках",
+                        "description": "Причина отказа",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.CreatePaymentRequest"
+                            "$ref": "#/definitions/dto.RejectSampleRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "ссылка на платеж YooKassa",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.PaymentURL"
+                            "$ref": "#/definitions/dto.SampleDTO"
                         }
                     },
                     "400": {
-                        "description": "Невалидное тело запроса",
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/dto.ApiError"
                         }
                     },
-                    "500": {
-                        "description": "Ошибка сервера или не удалось создать платёж"
-                    }
-                }
-            }
-        },
-        "/profile/me": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Возвращает информацию о текущем авторизованном пользователе",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "profile"
-                ],
-                "summary": "Получить профиль пользователя",
-                "responses": {
-                    "200": {
-                        "description": "Успешное получение профиля",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/dto.UserProfile"
+                            "$ref": "#/definitions/dto.ApiError"
                         }
                     },
-                    "401": {
-                        "description": "Пользователь не авторизован",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/dto.ApiError"
                         }
-                    }
-                }
-            }
-        },
-        "/purchases": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Возвращает список всех купленных семплов текущего пользователя, отсортированный по дате покупки (от новых к старым)",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "purchases"
-                ],
-                "summary": "Получить список всех покупок пользователя",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/dto.PurchaseDTO"
-                            }
-                        }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "409": {
+                        "description": "Семпл не на модерации",
                         "schema": {
                             "$ref": "#/definitions/dto.ApiError"
                         }
@@ -490,32 +661,62 @@ const docTemplate = `{
                 }
             }
         },
-        "/samples": {
+        "/admin/stats": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
+                "description": "То же, что статистика автора, но по всем авторам",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "samples"
+                    "admin"
+                ],
+                "summary": "Статистика площадки",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Первый день периода, YYYY-MM-DD, по умолчанию 30 дней назад",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Последний день периода включительно, YYYY-MM-DD, по умолчанию сегодня",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Только этот семпл",
+                        "name": "sample_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Только семплы этого пака",
+                        "name": "pack_id",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Получение всех семплов, но без файлов",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/dto.SampleDTO"
-                            }
+                            "$ref": "#/definitions/dto.StatsResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "400": {
+                        "description": "Неверный период, период длиннее 366 дней",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/dto.ApiError"
                         }
@@ -527,7 +728,9 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/admin/tags": {
             "post": {
                 "security": [
                     {
@@ -541,17 +744,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "samples"
+                    "admin"
                 ],
-                "summary": "Создает новый семпл (аудио загружается для созданного семпла через UploadAudio эндпоинт по ID семпла)",
+                "summary": "Добавить тег, инструмент или настроение",
                 "parameters": [
                     {
-                        "description": "Pack data",
+                        "description": "Тег",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.CreateSampleRequest"
+                            "$ref": "#/definitions/dto.CreateTagRequest"
                         }
                     }
                 ],
@@ -559,7 +762,7 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/dto.UUIDResponse"
+                            "$ref": "#/definitions/dto.TagDTO"
                         }
                     },
                     "400": {
@@ -568,6 +771,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/dto.ApiError"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "409": {
+                        "description": "Тег с таким slug уже есть",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -577,34 +792,46 @@ const docTemplate = `{
                 }
             }
         },
-        "/samples/{id}": {
-            "get": {
+        "/admin/tags/{id}": {
+            "patch": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "samples"
+                    "admin"
                 ],
-                "summary": "Получение семпла по ID",
+                "summary": "Переименовать тег",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Sample ID",
+                        "description": "ID тега",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Новое название",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.RenameTagRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.SampleDTO"
+                            "$ref": "#/definitions/dto.TagDTO"
                         }
                     },
                     "400": {
@@ -613,12 +840,24 @@ const docTemplate = `{
                             "$ref": "#/definitions/dto.ApiError"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/dto.ApiError"
                         }
                     },
+                    "409": {
+                        "description": "Тег с таким slug уже есть, их нужно объединить",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -626,13 +865,16 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/admin/tags/{id}/merge": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
+                "description": "Семплы и паки переносятся на тег into того же вида, исходный тег удаляется",
                 "consumes": [
                     "application/json"
                 ],
@@ -640,17 +882,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "samples"
+                    "admin"
                 ],
-                "summary": "Обновляет семпл",
+                "summary": "Объединить теги",
                 "parameters": [
                     {
-                        "description": "Update data",
+                        "type": "string",
+                        "description": "ID исходного тега",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Куда перенести",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.UpdateSampleRequest"
+                            "$ref": "#/definitions/dto.MergeTagRequest"
                         }
                     }
                 ],
@@ -658,7 +907,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.SampleDTO"
+                            "$ref": "#/definitions/dto.TagDTO"
                         }
                     },
                     "400": {
@@ -667,6 +916,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/dto.ApiError"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -674,81 +935,159 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/auth/2fa": {
             "post": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
+                "description": "Меняет challenge_token из входа и код 2FA (или код восстановления) на JWT токен",
                 "consumes": [
-                    "multipart/form-data"
+                    "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "samples"
+                    "auth"
                 ],
-                "summary": "Загрузка .wav аудио файла для созданного семпла",
+                "summary": "Второй шаг входа",
                 "parameters": [
                     {
-                        "type": "file",
-                        "description": "Аудио файл (sample)",
-                        "name": "file",
-                        "in": "formData",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Sample ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Токен первого шага и код",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.TwoFactorLoginRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "Успешная аутентификация",
                         "schema": {
-                            "$ref": "#/definitions/dto.DownloadURLResponse"
+                            "$ref": "#/definitions/dto.LoginResponse"
                         }
                     },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "Неверный формат запроса",
                         "schema": {
                             "$ref": "#/definitions/dto.ApiError"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "401": {
+                        "description": "Неверный код или токен первого шага устарел",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много попыток, повторить через Retry-After секунд",
                         "schema": {
                             "$ref": "#/definitions/dto.ApiError"
+                        },
+                        "headers": {
+                            "Retry-After": {
+                                "type": "integer",
+                                "description": "Через сколько секунд можно повторить"
+                            }
                         }
                     },
                     "500": {
-                        "description": "Internal Server Error",
+                        "description": "Внутренняя ошибка сервера",
                         "schema": {
                             "$ref": "#/definitions/dto.ApiError"
                         }
                     }
                 }
-            },
-            "delete": {
-                "security": [
+            }
+        },
+        "/auth/forgot-password": {
+            "post": {
+                "description": "Отправляет ссылку для сброса пароля на подтвержденную почту. Ответ не зависит от того, есть ли такой пользователь",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Запрос сброса пароля",
+                "parameters": [
                     {
-                        "BearerAuth": []
+                        "description": "Почта аккаунта",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.ForgotPasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Если почта зарегистрирована и подтверждена, письмо отправлено"
+                    },
+                    "400": {
+                        "description": "Некорректная почта",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много запросов, повторить через Retry-After секунд",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        },
+                        "headers": {
+                            "Retry-After": {
+                                "type": "integer",
+                                "description": "Через сколько секунд можно повторить"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
                     }
+                }
+            }
+        },
+        "/auth/oauth/providers": {
+            "get": {
+                "description": "Возвращает провайдеров, через которых можно войти (vk, yandex, google)",
+                "produces": [
+                    "application/json"
                 ],
                 "tags": [
-                    "samples"
+                    "oauth"
+                ],
+                "summary": "Провайдеры входа",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.OAuthProvidersResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/oauth/{provider}": {
+            "get": {
+                "description": "Возвращает ссылку на страницу входа провайдера (authorization code + PKCE). После входа провайдер вернет пользователя на redirect_url фронтенда с code и state",
+                "produces": [
+                    "application/json"
                 ],
-                "summary": "Удаляет семпл",
+                "tags": [
+                    "oauth"
+                ],
+                "summary": "Начать вход через провайдера",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Sample ID",
-                        "name": "id",
+                        "description": "Провайдер",
+                        "name": "provider",
                         "in": "path",
                         "required": true
                     }
@@ -757,14 +1096,11 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.SampleDTO"
+                            "$ref": "#/definitions/dto.OAuthURLResponse"
                         }
                     },
-                    "204": {
-                        "description": "No Content"
-                    },
-                    "400": {
-                        "description": "Bad Request",
+                    "404": {
+                        "description": "Провайдер не настроен",
                         "schema": {
                             "$ref": "#/definitions/dto.ApiError"
                         }
@@ -778,51 +1114,58 @@ const docTemplate = `{
                 }
             }
         },
-        "/samples/{id}/purchase": {
+        "/auth/oauth/{provider}/callback": {
             "post": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
+                "description": "Принимает code и state, которые провайдер передал фронтенду, и возвращает JWT (при включенной 2FA - challenge_token, как при входе по паролю). Новый пользователь создается автоматически, аккаунт с той же подтвержденной почтой привязывается",
+                "consumes": [
+                    "application/json"
                 ],
-                "description": "Покупает семпл за токены пользователя. После покупки семпл можно скачивать неограниченное количество раз. Если семпл уже куплен, возвращает ошибку 400.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "purchases"
+                    "oauth"
                 ],
-                "summary": "Покупка семпла",
+                "summary": "Завершить вход через провайдера",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Sample ID",
-                        "name": "id",
+                        "description": "Провайдер",
+                        "name": "provider",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "code и state из редиректа",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.OAuthCallbackRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.PurchaseDTO"
+                            "$ref": "#/definitions/dto.LoginResponse"
                         }
                     },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "Неверный или просроченный state",
                         "schema": {
                             "$ref": "#/definitions/dto.ApiError"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "404": {
+                        "description": "Провайдер не настроен",
                         "schema": {
                             "$ref": "#/definitions/dto.ApiError"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "409": {
+                        "description": "Аккаунт провайдера привязан к другому пользователю",
                         "schema": {
                             "$ref": "#/definitions/dto.ApiError"
                         }
@@ -832,148 +1175,5943 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/dto.ApiError"
                         }
+                    },
+                    "502": {
+                        "description": "Провайдер не ответил или отклонил код",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "dto.ApiError": {
-            "type": "object",
-            "properties": {
-                "message": {
-                    "type": "string"
+        },
+        "/auth/reset-password": {
+            "post": {
+                "description": "Задает новый пароль по одноразовому токену из письма",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Сброс пароля",
+                "parameters": [
+                    {
+                        "description": "Токен из письма и новый пароль",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.ResetPasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Пароль изменен"
+                    },
+                    "400": {
+                        "description": "Токен недействителен или пароль слишком слабый",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
                 }
             }
         },
-        "dto.CreatePackRequest": {
-            "type": "object",
-            "required": [
-                "author",
-                "description",
-                "genre",
-                "name"
-            ],
-            "properties": {
-                "author": {
+        "/auth/sign-in": {
+            "post": {
+                "description": "Выполняет вход пользователя в систему и возвращает JWT токен. При включенной 2FA вместо него возвращает challenge_token для POST /auth/2fa",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Аутентификация пользователя",
+                "parameters": [
+                    {
+                        "description": "Данные для входа",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.LoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Успешная аутентификация",
+                        "schema": {
+                            "$ref": "#/definitions/dto.LoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный формат запроса",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "401": {
+                        "description": "Неверные учетные данные",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много попыток, повторить через Retry-After секунд",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        },
+                        "headers": {
+                            "Retry-After": {
+                                "type": "integer",
+                                "description": "Через сколько секунд можно повторить"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/sign-up": {
+            "post": {
+                "description": "Создает нового пользователя и возвращает JWT токен",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Регистрация нового пользователя",
+                "parameters": [
+                    {
+                        "description": "Данные для регистрации",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.RegisterRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Успешная регистрация",
+                        "schema": {
+                            "$ref": "#/definitions/dto.RegisterResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный формат запроса, недопустимый логин, слабый пароль или некорректная почта",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "409": {
+                        "description": "Пользователь или почта уже существуют",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много регистраций, повторить через Retry-After секунд",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        },
+                        "headers": {
+                            "Retry-After": {
+                                "type": "integer",
+                                "description": "Через сколько секунд можно повторить"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/verify-email": {
+            "post": {
+                "description": "Подтверждает почту по одноразовому токену из письма",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Подтверждение почты",
+                "parameters": [
+                    {
+                        "description": "Токен из письма",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.VerifyEmailRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Почта подтверждена"
+                    },
+                    "400": {
+                        "description": "Токен неверный, просрочен или уже использован",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "409": {
+                        "description": "Почту уже подтвердил другой пользователь",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            }
+        },
+        "/authors/me": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Профиль текущего пользователя как автора вместе с количеством черновиков и семплов на модерации",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "authors"
+                ],
+                "summary": "Кабинет автора",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.AuthorDashboardDTO"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            }
+        },
+        "/authors/me/packs": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "authors"
+                ],
+                "summary": "Мои паки",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Размер страницы, по умолчанию 20, максимум 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Сколько пропустить",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.AuthorPacksResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            }
+        },
+        "/authors/me/samples": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Все семплы текущего автора, включая черновики, семплы на модерации и снятые с продажи. У черновика без аудио пустой listen_url",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "authors"
+                ],
+                "summary": "Мои семплы",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Размер страницы, по умолчанию 20, максимум 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Сколько пропустить",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.AuthorSamplesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            }
+        },
+        "/authors/me/stats": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Прослушивания, скачивания, покупки и выручка по дням, а также самые популярные семплы и паки автора. Статистика обновляется раз в несколько минут",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "authors"
+                ],
+                "summary": "Статистика автора",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Первый день периода, YYYY-MM-DD, по умолчанию 30 дней назад",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Последний день периода включительно, YYYY-MM-DD, по умолчанию сегодня",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Только этот семпл",
+                        "name": "sample_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Только семплы этого пака",
+                        "name": "pack_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.StatsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный период, период длиннее 366 дней",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            }
+        },
+        "/authors/{login}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Публичный профиль: био, аватар, количество семплов и паков, сколько раз покупали семплы автора",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "authors"
+                ],
+                "summary": "Профиль автора",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Логин автора",
+                        "name": "login",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.AuthorProfileDTO"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            }
+        },
+        "/authors/{login}/collections": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "collections"
+                ],
+                "summary": "Публичные подборки пользователя",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Логин",
+                        "name": "login",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.CollectionDTO"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            }
+        },
+        "/authors/{login}/packs": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "authors"
+                ],
+                "summary": "Паки автора",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Логин автора",
+                        "name": "login",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Размер страницы, по умолчанию 20, максимум 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Сколько пропустить",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.AuthorPacksResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            }
+        },
+        "/authors/{login}/samples": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Опубликованные семплы автора (с загруженным аудио), новые первыми",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "authors"
+                ],
+                "summary": "Семплы автора",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Логин автора",
+                        "name": "login",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Размер страницы, по умолчанию 20, максимум 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Сколько пропустить",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.AuthorSamplesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            }
+        },
+        "/collections": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "collections"
+                ],
+                "summary": "Мои подборки",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.CollectionDTO"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "collections"
+                ],
+                "summary": "Создать подборку",
+                "parameters": [
+                    {
+                        "description": "Подборка",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreateCollectionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CollectionDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            }
+        },
+        "/collections/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Свою подборку видно всегда, чужую - только если она публичная",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "collections"
+                ],
+                "summary": "Подборка с семплами",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Collection ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CollectionWithSamplesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "tags": [
+                    "collections"
+                ],
+                "summary": "Удалить подборку",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Collection ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "collections"
+                ],
+                "summary": "Изменить подборку",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Collection ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Изменения",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UpdateCollectionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CollectionDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            }
+        },
+        "/collections/{id}/samples": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Передаются все семплы подборки в новом порядке",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "collections"
+                ],
+                "summary": "Изменить порядок семплов в подборке",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Collection ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Новый порядок",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.ReorderCollectionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Семпл добавляется в конец, повторное добавление ничего не меняет",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "collections"
+                ],
+                "summary": "Добавить семпл в подборку",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Collection ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Семпл",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.AddCollectionSampleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            }
+        },
+        "/collections/{id}/samples/{sample_id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "tags": [
+                    "collections"
+                ],
+                "summary": "Убрать семпл из подборки",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Collection ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sample ID",
+                        "name": "sample_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            }
+        },
+        "/feed": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Семплы, похожие на купленные и лайкнутые пользователем, без уже купленных и его собственных. Новому пользователю - популярные за неделю",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "recommendations"
+                ],
+                "summary": "Лента рекомендаций",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Сколько семплов вернуть, по умолчанию 20, не больше 100",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.SampleDTO"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            }
+        },
+        "/genres": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Плоский список, дерево собирается по parent_id. Счетчики - опубликованные семплы и паки в самом жанре",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "taxonomy"
+                ],
+                "summary": "Справочник жанров",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.GenreDTO"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            }
+        },
+        "/packs": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "packs"
+                ],
+                "summary": "Получение всех паков (без семплов)",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.PackDTO"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Автором пака становится текущий пользователь",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "packs"
+                ],
+                "summary": "Создает пак семплов (без семплов)",
+                "parameters": [
+                    {
+                        "description": "Pack data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreatePackRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UUIDResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            }
+        },
+        "/packs/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Семплы идут в порядке треков, номер трека - поле track",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "packs"
+                ],
+                "summary": "Получает пак вместе с семплами (но без аудио содержимого)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Pack ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PackWithSamplesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "packs"
+                ],
+                "summary": "Обновляет пак (только автор или админ)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Pack ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UpdatePackRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Семплы пака остаются в каталоге и в других паках",
+                "tags": [
+                    "packs"
+                ],
+                "summary": "Перемещает пак в корзину (только автор или админ)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Pack ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            }
+        },
+        "/packs/{id}/cover": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "png, jpeg, webp или gif до 10 МБ, каждая сторона от 480 до 4096 пикселей. Из центра вырезается квадрат, сохраняются миниатюры 160, 480 и 1000 пикселей, предыдущая обложка удаляется",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "packs"
+                ],
+                "summary": "Загружает обложку пака (только автор или админ)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Pack ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Обложка",
+                        "name": "cover",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PackDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "tags": [
+                    "packs"
+                ],
+                "summary": "Удаляет обложку пака (только автор или админ)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Pack ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            }
+        },
+        "/packs/{id}/download": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Архив собирается на лету и отдается потоком. В него входят свои семплы, купленные в купленной версии и бесплатные, а также README.txt со списком файлов и LICENSE.txt с условиями лицензий. Некупленные и недоступные для покупки семплы пропускаются, их число указано в README.txt",
+                "produces": [
+                    "application/zip"
+                ],
+                "tags": [
+                    "packs"
+                ],
+                "summary": "Скачать пак одним zip-архивом",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Pack ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "zip-архив",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "403": {
+                        "description": "В паке нет своих, купленных или бесплатных семплов",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            }
+        },
+        "/packs/{id}/like": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Повторный лайк ничего не меняет",
+                "tags": [
+                    "likes"
+                ],
+                "summary": "Лайкнуть пак",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Pack ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "tags": [
+                    "likes"
+                ],
+                "summary": "Убрать лайк с пака",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Pack ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            }
+        },
+        "/packs/{id}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "tags": [
+                    "packs"
+                ],
+                "summary": "Восстанавливает пак из корзины вместе с семплами, удаленными вместе с ним",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Pack ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "410": {
+                        "description": "Срок хранения в корзине истек",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            }
+        },
+        "/packs/{id}/samples": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Передаются все семплы пака в новом порядке",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "packs"
+                ],
+                "summary": "Меняет порядок треков пака (только автор или админ)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Pack ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Новый порядок",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.ReorderPackRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Семпл добавляется последним треком, повторное добавление ничего не меняет. Один семпл может входить в несколько паков",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "packs"
+                ],
+                "summary": "Добавляет семпл в пак (только автор или админ)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Pack ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Семпл",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.AddPackSampleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            }
+        },
+        "/packs/{id}/samples/{sample_id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Сам семпл остается в каталоге",
+                "tags": [
+                    "packs"
+                ],
+                "summary": "Убирает семпл из пака (только автор или админ)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Pack ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sample ID",
+                        "name": "sample_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            }
+        },
+        "/payments/history": {
+            "get": {
+                "description": "Возвращает историю платежей текущего авторизованного пользователя",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payments"
+                ],
+                "summary": "Получить список платежей пользователя",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer токен",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Список платежей пользователя",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.UserPayment"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка сервера или не удалось получить платежи"
+                    }
+                }
+            }
+        },
+        "/payments/new": {
+            "post": {
+                "description": "Создаёт платёж через YooKassa и перенаправляет пользователя на страницу оплаты.",
+                "tags": [
+                    "payments"
+                ],
+                "summary": "Создание нового платежа",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer токен",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Данные для создания платежа. return_uri - ссылка на которую вернуть пользователя после оплаты. amount в копейках",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreatePaymentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "ссылка на платеж YooKassa",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PaymentURL"
+                        }
+                    },
+                    "400": {
+                        "description": "Невалидное тело запроса",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Ошибка сервера или не удалось создать платёж"
+                    }
+                }
+            }
+        },
+        "/profile/2fa": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "2fa"
+                ],
+                "summary": "Состояние 2FA",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.TwoFactorStatus"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            }
+        },
+        "/profile/2fa/confirm": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Включает 2FA по первому коду из приложения и возвращает одноразовые коды восстановления. Коды показываются только один раз",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "2fa"
+                ],
+                "summary": "Подтвердить и включить 2FA",
+                "parameters": [
+                    {
+                        "description": "Код из приложения",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.TwoFactorCodeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.RecoveryCodesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный код или настройка не начата",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "409": {
+                        "description": "2FA уже включена",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много неверных кодов 2FA, повторить через Retry-After секунд",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            }
+        },
+        "/profile/2fa/disable": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "2fa"
+                ],
+                "summary": "Выключить 2FA",
+                "parameters": [
+                    {
+                        "description": "Код из приложения или код восстановления",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.TwoFactorCodeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "2FA не включена",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "403": {
+                        "description": "Неверный код",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много неверных кодов 2FA, повторить через Retry-After секунд",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            }
+        },
+        "/profile/2fa/enroll": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Генерирует секрет и возвращает otpauth ссылку и QR-код (PNG в base64) для приложения-аутентификатора. 2FA включится после подтверждения кодом",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "2fa"
+                ],
+                "summary": "Начать настройку 2FA",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.TwoFactorEnrollResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "409": {
+                        "description": "2FA уже включена",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            }
+        },
+        "/profile/2fa/recovery-codes": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Выдает новый набор кодов восстановления, старые перестают действовать",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "2fa"
+                ],
+                "summary": "Новые коды восстановления",
+                "parameters": [
+                    {
+                        "description": "Код из приложения или код восстановления",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.TwoFactorCodeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.RecoveryCodesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "2FA не включена",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "403": {
+                        "description": "Неверный код",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много неверных кодов 2FA, повторить через Retry-After секунд",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            }
+        },
+        "/profile/earnings": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Баланс в токенах и разбивка по семплам. Доля автора начисляется с каждой покупки за вычетом комиссии площадки",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "earnings"
+                ],
+                "summary": "Заработок автора",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.EarningsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            }
+        },
+        "/profile/email": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Ставит новую почту и отправляет на нее письмо с подтверждением. С текущей неподтвержденной почтой отправляет письмо повторно. При включенной 2FA нужен код в заголовке X-2FA-Code",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "profile"
+                ],
+                "summary": "Смена почты",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Код 2FA или код восстановления",
+                        "name": "X-2FA-Code",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Новая почта",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.ChangeEmailRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Письмо с подтверждением отправлено"
+                    },
+                    "400": {
+                        "description": "Некорректная почта",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "401": {
+                        "description": "Пользователь не авторизован",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "403": {
+                        "description": "Нужен код 2FA или он неверный",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "409": {
+                        "description": "Почта занята другим пользователем",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много неверных кодов 2FA, повторить через Retry-After секунд",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            }
+        },
+        "/profile/identities": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "oauth"
+                ],
+                "summary": "Привязанные провайдеры",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.IdentityDTO"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            }
+        },
+        "/profile/identities/{provider}": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает ссылку на вход у провайдера, после завершения через callback аккаунт провайдера привяжется к текущему пользователю",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "oauth"
+                ],
+                "summary": "Привязать провайдера",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Провайдер",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.OAuthURLResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "404": {
+                        "description": "Провайдер не настроен",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Нельзя отвязать единственный способ входа, если у пользователя нет пароля",
+                "tags": [
+                    "oauth"
+                ],
+                "summary": "Отвязать провайдера",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Провайдер",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "404": {
+                        "description": "Провайдер не привязан",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "409": {
+                        "description": "Это единственный способ входа",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            }
+        },
+        "/profile/likes": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "likes"
+                ],
+                "summary": "Лайкнутые семплы и паки",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.LikesResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            }
+        },
+        "/profile/login": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Меняет логин, автор семплов и паков меняется вместе с ним. Возвращает новый токен для текущей сессии. При включенной 2FA нужен код в заголовке X-2FA-Code, аккаунту без пароля и 2FA - вход не раньше 10 минут назад",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "profile"
+                ],
+                "summary": "Смена логина",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Код 2FA или код восстановления",
+                        "name": "X-2FA-Code",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Новый логин и пароль",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.ChangeLoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Новый токен",
+                        "schema": {
+                            "$ref": "#/definitions/dto.LoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный логин",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "401": {
+                        "description": "Неверный пароль",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "403": {
+                        "description": "Нужен код 2FA или он неверный, либо нужен свежий вход",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "409": {
+                        "description": "Логин занят",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много неверных кодов 2FA, повторить через Retry-After секунд",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            }
+        },
+        "/profile/me": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает информацию о текущем авторизованном пользователе",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "profile"
+                ],
+                "summary": "Получить профиль пользователя",
+                "responses": {
+                    "200": {
+                        "description": "Успешное получение профиля",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserProfile"
+                        }
+                    },
+                    "401": {
+                        "description": "Пользователь не авторизован",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Обезличивает аккаунт: стирает почту, профиль, аватар, способы входа и сессии. Покупки и платежи сохраняются для отчетности, семплы и паки уходят в корзину и снимаются с продажи, купленные остаются у покупателей под обезличенным автором. При включенной 2FA нужен код в заголовке X-2FA-Code, аккаунту без пароля и 2FA - вход не раньше 10 минут назад",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "profile"
+                ],
+                "summary": "Удаление аккаунта",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Код 2FA или код восстановления",
+                        "name": "X-2FA-Code",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Пароль",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.DeleteAccountRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Аккаунт удален"
+                    },
+                    "401": {
+                        "description": "Неверный пароль",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "403": {
+                        "description": "Нужен код 2FA или он неверный, либо нужен свежий вход",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много неверных кодов 2FA, повторить через Retry-After секунд",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Меняет только переданные поля. JSON для текстовых полей или multipart/form-data, если вместе с ними загружается аватар (png, jpeg, webp, gif до 5 МБ, каждая сторона от 128 до 4096 пикселей; сохраняются квадратные миниатюры 64, 256 и 512 пикселей)",
+                "consumes": [
+                    "application/json",
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "profile"
+                ],
+                "summary": "Изменить профиль",
+                "parameters": [
+                    {
+                        "description": "Поля профиля",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UpdateProfileRequest"
+                        }
+                    },
+                    {
+                        "type": "file",
+                        "description": "Аватар",
+                        "name": "avatar",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserProfile"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректные поля или картинка",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "401": {
+                        "description": "Пользователь не авторизован",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            }
+        },
+        "/profile/password": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Меняет пароль по старому. Остальные сессии завершаются. При включенной 2FA нужен код в заголовке X-2FA-Code",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "profile"
+                ],
+                "summary": "Смена пароля",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Код 2FA или код восстановления",
+                        "name": "X-2FA-Code",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Старый и новый пароль",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.ChangePasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Пароль изменен"
+                    },
+                    "400": {
+                        "description": "Слишком слабый пароль",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "401": {
+                        "description": "Неверный старый пароль",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "403": {
+                        "description": "Нужен код 2FA или он неверный",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много попыток, повторить через Retry-After секунд",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            }
+        },
+        "/profile/payouts": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "earnings"
+                ],
+                "summary": "Мои заявки на вывод",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.PayoutDTO"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Сумма резервируется на балансе до решения администратора. При включенной 2FA нужен код в заголовке X-2FA-Code",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "earnings"
+                ],
+                "summary": "Заявка на вывод заработка",
+                "parameters": [
+                    {
+                        "description": "Сумма в токенах",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.PayoutRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PayoutDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Сумма меньше минимальной",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "403": {
+                        "description": "Нужен код 2FA",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "409": {
+                        "description": "Недостаточно средств",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много неверных кодов 2FA, повторить через Retry-After секунд",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            }
+        },
+        "/profile/sessions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Устройства, на которых выполнен вход. Текущая сессия помечена current",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sessions"
+                ],
+                "summary": "Активные сессии",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.SessionDTO"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            }
+        },
+        "/profile/sessions/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Выходит с устройства: токен этой сессии перестает приниматься. Можно завершить и текущую",
+                "tags": [
+                    "sessions"
+                ],
+                "summary": "Завершить сессию",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID сессии",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "404": {
+                        "description": "Сессия не найдена или уже завершена",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            }
+        },
+        "/purchases": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Возвращает список всех купленных семплов текущего пользователя, отсортированный по дате покупки (от новых к старым)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "purchases"
+                ],
+                "summary": "Получить список всех покупок пользователя",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.PurchaseDTO"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            }
+        },
+        "/purchases/{id}/license": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Сертификат купленной лицензии, подписанный Ed25519 ключом площадки. Подпись считается от поля document байт в байт",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "purchases"
+                ],
+                "summary": "Сертификат лицензии",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Purchase ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SignedDocumentDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            }
+        },
+        "/purchases/{id}/receipt": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Чек с пользователем, хешем купленного аудио, лицензией, ценой и временем покупки, подписанный Ed25519 ключом площадки. Подпись считается от поля document байт в байт, проверить чек можно через POST /receipts/verify или публичным ключом из GET /receipts/key",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "purchases"
+                ],
+                "summary": "Чек покупки",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Purchase ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SignedDocumentDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            }
+        },
+        "/purchases/{id}/updates": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "По умолчанию покупатель получает ту версию аудио, которую купил. С подпиской ссылка на скачивание всегда ведет на текущую версию семпла, при отписке покупка закрепляется за текущей версией.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "purchases"
+                ],
+                "summary": "Подписка покупки на обновления аудио",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Purchase ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Подписка",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.FollowUpdatesRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PurchaseDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            }
+        },
+        "/receipts/key": {
+            "get": {
+                "description": "Ключ Ed25519, которым подписаны чеки и сертификаты лицензий, для проверки без обращения к серверу",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "receipts"
+                ],
+                "summary": "Публичный ключ подписи",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SigningKeyDTO"
+                        }
+                    }
+                }
+            }
+        },
+        "/receipts/verify": {
+            "post": {
+                "description": "Публичная проверка без авторизации: подпись чека верна и покупка по нему существует. Чек передается в том виде, в котором был выдан",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "receipts"
+                ],
+                "summary": "Проверка чека покупки",
+                "parameters": [
+                    {
+                        "description": "Чек",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.SignedDocumentDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ReceiptVerificationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            }
+        },
+        "/samples": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "samples"
+                ],
+                "summary": "Получение всех семплов, но без файлов",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Сортировка: new (по умолчанию) или popular - по количеству лайков",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Slug жанра, включая поджанры",
+                        "name": "genre",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Slug свободного тега",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Slug инструмента",
+                        "name": "instrument",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Slug настроения",
+                        "name": "mood",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "UUID автора",
+                        "name": "author",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID пака",
+                        "name": "pack_id",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "free",
+                            "under-100",
+                            "100-500",
+                            "over-500"
+                        ],
+                        "type": "string",
+                        "description": "Корзина цены",
+                        "name": "price",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "under-5s",
+                            "5-15s",
+                            "15-30s",
+                            "over-30s"
+                        ],
+                        "type": "string",
+                        "description": "Корзина длительности",
+                        "name": "duration",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.SampleDTO"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Автором семпла становится текущий пользователь",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "samples"
+                ],
+                "summary": "Создает новый семпл (аудио загружается для созданного семпла через UploadAudio эндпоинт по ID семпла)",
+                "parameters": [
+                    {
+                        "description": "Pack data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreateSampleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UUIDResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            }
+        },
+        "/samples/facets": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Сколько семплов каталога в каждом жанре, у каждого автора, в каждом паке, корзине цены и длительности при уже выбранных фильтрах. Параметры те же, что у списка семплов. Результат кэшируется на несколько секунд",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "samples"
+                ],
+                "summary": "Счетчики для фильтров каталога",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Slug жанра, включая поджанры",
+                        "name": "genre",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Slug свободного тега",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Slug инструмента",
+                        "name": "instrument",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Slug настроения",
+                        "name": "mood",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "UUID автора",
+                        "name": "author",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID пака",
+                        "name": "pack_id",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "free",
+                            "under-100",
+                            "100-500",
+                            "over-500"
+                        ],
+                        "type": "string",
+                        "description": "Корзина цены",
+                        "name": "price",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "under-5s",
+                            "5-15s",
+                            "15-30s",
+                            "over-30s"
+                        ],
+                        "type": "string",
+                        "description": "Корзина длительности",
+                        "name": "duration",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.FacetsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            }
+        },
+        "/samples/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "samples"
+                ],
+                "summary": "Получение семпла по ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Sample ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SampleDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "samples"
+                ],
+                "summary": "Обновляет семпл (только автор или админ)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Sample ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UpdateSampleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SampleDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Каждая загрузка создает новую версию аудио и делает ее текущей. Покупатели сохраняют доступ к купленной версии. Опубликованный семпл с новым аудио возвращается на модерацию",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "samples"
+                ],
+                "summary": "Загрузка .wav аудио файла для созданного семпла (только автор или админ)",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "Аудио файл (sample)",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sample ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.DownloadURLResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Семпл можно восстановить, пока не истек срок хранения корзины. Покупатели сохраняют доступ к купленному семплу.",
+                "tags": [
+                    "samples"
+                ],
+                "summary": "Перемещает семпл в корзину (только автор или админ)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Sample ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            }
+        },
+        "/samples/{id}/download": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Перенаправляет на файл аудио и учитывает скачивание в статистике. Бесплатный семпл доступен всем, платный - только купленная версия",
+                "tags": [
+                    "samples"
+                ],
+                "summary": "Скачивание семпла",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Sample ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "302": {
+                        "description": "Redirect на файл"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "403": {
+                        "description": "Семпл не куплен",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "404": {
+                        "description": "Семпл не найден или аудио еще не загружено",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            }
+        },
+        "/samples/{id}/licenses": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Заменяет список лицензий целиком: personal, commercial, exclusive, у каждой своя цена в токенах. Пустой список делает семпл бесплатным. Цена семпла становится минимальной ценой лицензии",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "samples"
+                ],
+                "summary": "Задает лицензии семпла (только автор или админ)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Sample ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Лицензии",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.SetLicensesRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SampleDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "409": {
+                        "description": "Семпл продан эксклюзивно",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            }
+        },
+        "/samples/{id}/like": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Повторный лайк ничего не меняет",
+                "tags": [
+                    "likes"
+                ],
+                "summary": "Лайкнуть семпл",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Sample ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "tags": [
+                    "likes"
+                ],
+                "summary": "Убрать лайк с семпла",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Sample ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            }
+        },
+        "/samples/{id}/purchase": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Покупает лицензию на семпл за токены пользователя, без тела покупается коммерческая лицензия. После покупки семпл можно скачивать неограниченное количество раз. Эксклюзивная лицензия снимает семпл с продажи. Если семпл уже куплен, возвращает ошибку 400. Дорогие покупки при включенной 2FA требуют код в заголовке X-2FA-Code",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "purchases"
+                ],
+                "summary": "Покупка семпла",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Sample ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Тип лицензии",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PurchaseRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Код 2FA или код восстановления",
+                        "name": "X-2FA-Code",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PurchaseDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "403": {
+                        "description": "Нужен код 2FA или он неверный",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "409": {
+                        "description": "Семпл продан эксклюзивно",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много неверных кодов 2FA, повторить через Retry-After секунд",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            }
+        },
+        "/samples/{id}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "tags": [
+                    "samples"
+                ],
+                "summary": "Восстанавливает семпл из корзины (только автор или админ)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Sample ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "410": {
+                        "description": "Срок хранения в корзине истек",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            }
+        },
+        "/samples/{id}/similar": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Семплы, которые покупают вместе с этим, и близкие по жанру, автору, цене и длительности. Пересчитываются фоновой задачей, пока похожих нет - популярные",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "recommendations"
+                ],
+                "summary": "Похожие семплы",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Sample ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Сколько семплов вернуть, по умолчанию 20, не больше 100",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.SampleDTO"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            }
+        },
+        "/samples/{id}/submit": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Отправить можно черновик с загруженным аудио. После одобрения семпл появится в каталоге сразу или в publish_at, если он задан. Отклоненный семпл возвращается в черновики с причиной отказа",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "samples"
+                ],
+                "summary": "Отправить семпл на модерацию (только автор или админ)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Sample ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Отложенная публикация",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SubmitSampleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SampleDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "409": {
+                        "description": "Семпл не черновик или без аудио",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            }
+        },
+        "/samples/{id}/versions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "samples"
+                ],
+                "summary": "Получение всех загруженных версий аудио семпла (только автор или админ)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Sample ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.SampleVersionDTO"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            }
+        },
+        "/samples/{id}/versions/{version}/rollback": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Опубликованный семпл с другим аудио возвращается на модерацию",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "samples"
+                ],
+                "summary": "Откат аудио семпла на одну из предыдущих версий (только автор или админ)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Sample ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Номер версии",
+                        "name": "version",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.SampleDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            }
+        },
+        "/tags": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Для автодополнения: q - начало названия. Сначала самые используемые",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "taxonomy"
+                ],
+                "summary": "Справочник тегов, инструментов и настроений",
+                "parameters": [
+                    {
+                        "enum": [
+                            "tag",
+                            "instrument",
+                            "mood"
+                        ],
+                        "type": "string",
+                        "description": "Вид",
+                        "name": "kind",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Начало названия",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "По умолчанию 50, не больше 200",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.TagCountDTO"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            }
+        },
+        "/trash": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Автор видит свои удаленные семплы и паки, админ - все",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "trash"
+                ],
+                "summary": "Содержимое корзины",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.TrashResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApiError"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "dto.AddCollectionSampleRequest": {
+            "type": "object",
+            "required": [
+                "sample_id"
+            ],
+            "properties": {
+                "sample_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.AddPackSampleRequest": {
+            "type": "object",
+            "required": [
+                "sample_id"
+            ],
+            "properties": {
+                "sample_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.ApiError": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.AuthorDashboardDTO": {
+            "type": "object",
+            "properties": {
+                "avatar": {
+                    "$ref": "#/definitions/dto.ImageDTO"
+                },
+                "avatar_url": {
+                    "description": "средняя миниатюра аватара",
+                    "type": "string"
+                },
+                "bio": {
+                    "type": "string"
+                },
+                "display_name": {
+                    "type": "string"
+                },
+                "downloads": {
+                    "type": "integer"
+                },
+                "drafts": {
+                    "type": "integer"
+                },
+                "links": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "login": {
+                    "type": "string"
+                },
+                "packs": {
+                    "type": "integer"
+                },
+                "pending": {
+                    "type": "integer"
+                },
+                "samples": {
+                    "type": "integer"
+                },
+                "uuid": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.AuthorPacksResponse": {
+            "type": "object",
+            "properties": {
+                "packs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.PackDTO"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.AuthorProfileDTO": {
+            "type": "object",
+            "properties": {
+                "avatar": {
+                    "$ref": "#/definitions/dto.ImageDTO"
+                },
+                "avatar_url": {
+                    "description": "средняя миниатюра аватара",
+                    "type": "string"
+                },
+                "bio": {
+                    "type": "string"
+                },
+                "display_name": {
+                    "type": "string"
+                },
+                "downloads": {
+                    "type": "integer"
+                },
+                "links": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "login": {
+                    "type": "string"
+                },
+                "packs": {
+                    "type": "integer"
+                },
+                "samples": {
+                    "type": "integer"
+                },
+                "uuid": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.AuthorSamplesResponse": {
+            "type": "object",
+            "properties": {
+                "samples": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.SampleDTO"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.ChangeEmailRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.ChangeLoginRequest": {
+            "type": "object",
+            "properties": {
+                "login": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.ChangePasswordRequest": {
+            "type": "object",
+            "properties": {
+                "new_password": {
+                    "type": "string"
+                },
+                "old_password": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.CollectionDTO": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "is_public": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "sample_count": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_uuid": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.CollectionWithSamplesResponse": {
+            "type": "object",
+            "properties": {
+                "collection": {
+                    "$ref": "#/definitions/dto.CollectionDTO"
+                },
+                "samples": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.SampleDTO"
+                    }
+                }
+            }
+        },
+        "dto.CommissionRequest": {
+            "type": "object",
+            "properties": {
+                "commission_percent": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.CreateCollectionRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "is_public": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.CreateGenreRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "parent": {
+                    "description": "slug родителя, пусто - корневой жанр",
+                    "type": "string"
+                }
+            }
+        },
+        "dto.CreatePackRequest": {
+            "type": "object",
+            "required": [
+                "description",
+                "genre",
+                "name"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "genre": {
+                    "description": "slug или название жанра из справочника",
+                    "type": "string"
+                },
+                "instruments": {
+                    "description": "из справочника, до 5",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "moods": {
+                    "description": "из справочника, до 3",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "tags": {
+                    "description": "свободные теги, до 10, новые создаются автоматически",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "dto.CreatePaymentRequest": {
+            "type": "object",
+            "required": [
+                "amount",
+                "return_uri"
+            ],
+            "properties": {
+                "amount": {
+                    "description": "Amount сумма платежа в КОПЕЙКАХ",
+                    "type": "integer"
+                },
+                "return_uri": {
+                    "description": "ReturnURI ссылка на которую вернуть после оплаты",
+                    "type": "string"
+                }
+            }
+        },
+        "dto.CreateSampleRequest": {
+            "type": "object",
+            "required": [
+                "description",
+                "genre",
+                "price",
+                "title"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "genre": {
+                    "description": "slug или название жанра из справочника",
+                    "type": "string"
+                },
+                "instruments": {
+                    "description": "из справочника, до 5",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "moods": {
+                    "description": "из справочника, до 3",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "pack_id": {
+                    "description": "пак, в конец которого добавить семпл",
+                    "type": "string"
+                },
+                "price": {
+                    "description": "цена коммерческой лицензии",
+                    "type": "integer"
+                },
+                "tags": {
+                    "description": "свободные теги, до 10, новые создаются автоматически",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.CreateTagRequest": {
+            "type": "object",
+            "required": [
+                "kind",
+                "name"
+            ],
+            "properties": {
+                "kind": {
+                    "type": "string",
+                    "enum": [
+                        "tag",
+                        "instrument",
+                        "mood"
+                    ]
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.DeleteAccountRequest": {
+            "type": "object",
+            "properties": {
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.DownloadURLResponse": {
+            "type": "object",
+            "properties": {
+                "download_url": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.EarningsResponse": {
+            "type": "object",
+            "properties": {
+                "balance": {
+                    "description": "доступно к выводу",
+                    "type": "integer"
+                },
+                "commission_percent": {
+                    "type": "integer"
+                },
+                "earned": {
+                    "type": "integer"
+                },
+                "paid_out": {
+                    "type": "integer"
+                },
+                "reserved": {
+                    "description": "в заявках на вывод, которые еще не выплачены",
+                    "type": "integer"
+                },
+                "samples": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.SampleEarningsDTO"
+                    }
+                }
+            }
+        },
+        "dto.FacetValueDTO": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "label": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.FacetsResponse": {
+            "type": "object",
+            "properties": {
+                "authors": {
+                    "description": "value - uuid автора, до 50 самых частых",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.FacetValueDTO"
+                    }
+                },
+                "durations": {
+                    "description": "все корзины длительности, в том числе пустые",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.FacetValueDTO"
+                    }
+                },
+                "genres": {
+                    "description": "value - slug жанра",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.FacetValueDTO"
+                    }
+                },
+                "packs": {
+                    "description": "value - id пака, до 50 самых частых",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.FacetValueDTO"
+                    }
+                },
+                "prices": {
+                    "description": "все корзины цены, в том числе пустые",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.FacetValueDTO"
+                    }
+                }
+            }
+        },
+        "dto.FollowUpdatesRequest": {
+            "type": "object",
+            "properties": {
+                "followUpdates": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "dto.ForgotPasswordRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.GenreDTO": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "pack_count": {
+                    "type": "integer"
+                },
+                "parent_id": {
+                    "type": "string"
+                },
+                "sample_count": {
+                    "description": "опубликованных семплов в жанре без поджанров",
+                    "type": "integer"
+                },
+                "slug": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.IdentityDTO": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "provider": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.ImageDTO": {
+            "type": "object",
+            "properties": {
+                "large": {
+                    "type": "string"
+                },
+                "medium": {
+                    "type": "string"
+                },
+                "small": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.ItemStatsDTO": {
+            "type": "object",
+            "properties": {
+                "conversion": {
+                    "description": "доля прослушиваний, закончившихся покупкой",
+                    "type": "number"
+                },
+                "day": {
+                    "description": "YYYY-MM-DD, у итогов пусто",
+                    "type": "string"
+                },
+                "downloads": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "plays": {
+                    "type": "integer"
+                },
+                "purchases": {
+                    "type": "integer"
+                },
+                "revenue": {
+                    "description": "выручка в токенах до комиссии",
+                    "type": "integer"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.LicenseDTO": {
+            "type": "object",
+            "required": [
+                "price",
+                "type"
+            ],
+            "properties": {
+                "price": {
+                    "type": "integer"
+                },
+                "type": {
+                    "description": "personal, commercial или exclusive",
+                    "type": "string",
+                    "example": "commercial"
+                }
+            }
+        },
+        "dto.LikesResponse": {
+            "type": "object",
+            "properties": {
+                "packs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.PackDTO"
+                    }
+                },
+                "samples": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.SampleDTO"
+                    }
+                }
+            }
+        },
+        "dto.LoginRequest": {
+            "type": "object",
+            "properties": {
+                "device_name": {
+                    "description": "имя устройства в списке сессий, по умолчанию из User-Agent",
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.LoginResponse": {
+            "type": "object",
+            "properties": {
+                "challenge_token": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                },
+                "two_factor_required": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "dto.MergeGenreRequest": {
+            "type": "object",
+            "required": [
+                "into"
+            ],
+            "properties": {
+                "into": {
+                    "description": "slug жанра, в который переносится все",
+                    "type": "string"
+                }
+            }
+        },
+        "dto.MergeTagRequest": {
+            "type": "object",
+            "required": [
+                "into"
+            ],
+            "properties": {
+                "into": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.OAuthCallbackRequest": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "device_id": {
+                    "description": "DeviceID передает VK ID вместе с кодом, остальным провайдерам не нужен",
+                    "type": "string"
+                },
+                "device_name": {
+                    "description": "имя устройства в списке сессий",
+                    "type": "string"
+                },
+                "state": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.OAuthProvidersResponse": {
+            "type": "object",
+            "properties": {
+                "providers": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "dto.OAuthURLResponse": {
+            "type": "object",
+            "properties": {
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.PackDTO": {
+            "type": "object",
+            "properties": {
+                "author": {
+                    "description": "логин автора",
+                    "type": "string"
+                },
+                "author_name": {
+                    "description": "отображаемое имя автора",
+                    "type": "string"
+                },
+                "author_uuid": {
+                    "type": "string"
+                },
+                "cover": {
+                    "description": "миниатюры обложки, нет - обложка не загружена",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dto.ImageDTO"
+                        }
+                    ]
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "genre": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "likes": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.TagDTO"
+                    }
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.PackWithSamplesResponse": {
+            "type": "object",
+            "properties": {
+                "pack": {
+                    "$ref": "#/definitions/dto.PackDTO"
+                },
+                "samples": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.SampleDTO"
+                    }
+                }
+            }
+        },
+        "dto.PaymentURL": {
+            "type": "object",
+            "properties": {
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.PayoutDTO": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "author_uuid": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "failure": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "paid_at": {
+                    "type": "string"
+                },
+                "reject_reason": {
+                    "type": "string"
+                },
+                "reviewed_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "pending",
+                        "approved",
+                        "paid",
+                        "rejected",
+                        "failed"
+                    ]
+                }
+            }
+        },
+        "dto.PayoutRequest": {
+            "type": "object",
+            "required": [
+                "amount"
+            ],
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.PurchaseDTO": {
+            "type": "object",
+            "properties": {
+                "followUpdates": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "license": {
+                    "description": "personal, commercial или exclusive",
+                    "type": "string"
+                },
+                "price": {
+                    "type": "integer"
+                },
+                "purchasedAt": {
+                    "type": "string"
+                },
+                "sample": {
+                    "description": "опционально, для списка покупок",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dto.SampleDTO"
+                        }
+                    ]
+                },
+                "sampleId": {
+                    "type": "string"
+                },
+                "sampleVersion": {
+                    "description": "SampleVersion купленная версия аудио, 0 - текущая",
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.PurchaseRequest": {
+            "type": "object",
+            "properties": {
+                "license": {
+                    "type": "string",
+                    "example": "commercial"
+                }
+            }
+        },
+        "dto.ReceiptVerificationResponse": {
+            "type": "object",
+            "properties": {
+                "purchase": {
+                    "$ref": "#/definitions/dto.VerifiedPurchaseDTO"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "valid": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "dto.RecoveryCodesResponse": {
+            "type": "object",
+            "properties": {
+                "recovery_codes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "dto.RegisterRequest": {
+            "type": "object",
+            "properties": {
+                "device_name": {
+                    "type": "string"
+                },
+                "email": {
+                    "description": "необязательно, на нее придет письмо с подтверждением",
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.RegisterResponse": {
+            "type": "object",
+            "properties": {
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.RejectPayoutRequest": {
+            "type": "object",
+            "required": [
+                "reason"
+            ],
+            "properties": {
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.RejectSampleRequest": {
+            "type": "object",
+            "required": [
+                "reason"
+            ],
+            "properties": {
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.RenameTagRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.ReorderCollectionRequest": {
+            "type": "object",
+            "required": [
+                "sample_ids"
+            ],
+            "properties": {
+                "sample_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "dto.ReorderPackRequest": {
+            "type": "object",
+            "required": [
+                "sample_ids"
+            ],
+            "properties": {
+                "sample_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "dto.ResetPasswordRequest": {
+            "type": "object",
+            "properties": {
+                "password": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.SampleDTO": {
+            "type": "object",
+            "properties": {
+                "author": {
+                    "description": "логин автора",
+                    "type": "string"
+                },
+                "author_name": {
+                    "description": "отображаемое имя автора",
+                    "type": "string"
+                },
+                "author_uuid": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "description": "DeletedAt семпл удален автором, но остается доступным покупателям",
+                    "type": "string"
+                },
+                "delisted_at": {
+                    "description": "DelistedAt семпл продан эксклюзивно и снят с продажи",
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "download_url": {
+                    "type": "string"
+                },
+                "duration": {
+                    "type": "number"
+                },
+                "genre": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "is_liked": {
+                    "description": "текущий пользователь лайкнул семпл",
+                    "type": "boolean"
+                },
+                "licenses": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.LicenseDTO"
+                    }
+                },
+                "likes": {
+                    "type": "integer"
+                },
+                "listen_url": {
+                    "type": "string"
+                },
+                "original_filename": {
+                    "description": "OriginalFilename имя файла, под которым автор загрузил аудио",
+                    "type": "string"
+                },
+                "pack_ids": {
+                    "description": "паки, в которые входит семпл",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "price": {
+                    "description": "минимальная цена лицензии, 0 - бесплатный",
+                    "type": "integer"
+                },
+                "publish_at": {
+                    "description": "PublishAt отложенная публикация: до этого времени семпла нет в каталоге",
+                    "type": "string"
+                },
+                "reject_reason": {
+                    "description": "RejectReason причина последнего отказа модератора",
+                    "type": "string"
+                },
+                "reviewed_at": {
+                    "type": "string"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "draft, pending, published или delisted",
+                    "type": "string"
+                },
+                "submitted_at": {
+                    "type": "string"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.TagDTO"
+                    }
+                },
+                "title": {
+                    "type": "string"
+                },
+                "track": {
+                    "description": "номер трека, только в ответе с паком",
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.SampleEarningsDTO": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "доля автора",
+                    "type": "integer"
+                },
+                "commission": {
+                    "description": "доля площадки",
+                    "type": "integer"
+                },
+                "gross": {
+                    "description": "сколько заплатили покупатели",
+                    "type": "integer"
+                },
+                "sales": {
+                    "type": "integer"
+                },
+                "sample_id": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.SampleVersionDTO": {
+            "type": "object",
+            "properties": {
+                "duration": {
+                    "type": "number"
+                },
+                "hash": {
+                    "type": "string"
+                },
+                "original_filename": {
+                    "type": "string"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "uploaded_at": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.SessionDTO": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "current": {
+                    "description": "сессия, с которой сделан запрос",
+                    "type": "boolean"
+                },
+                "device_name": {
                     "type": "string"
                 },
-                "description": {
+                "expires_at": {
                     "type": "string"
                 },
-                "genre": {
+                "id": {
                     "type": "string"
                 },
-                "name": {
+                "ip": {
+                    "type": "string"
+                },
+                "last_seen_at": {
+                    "type": "string"
+                },
+                "user_agent": {
                     "type": "string"
                 }
             }
         },
-        "dto.CreatePaymentRequest": {
+        "dto.SetLicensesRequest": {
             "type": "object",
-            "required": [
-                "amount",
-                "return_uri"
-            ],
             "properties": {
-                "amount": {
-                    "description": "Amount сумма платежа в КОПЕЙКАХ",
-                    "type": "integer"
-                },
-                "return_uri": {
-                    "description": "ReturnURI ссылка на которую вернуть после оплаты",
-                    "type": "string"
+                "licenses": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.LicenseDTO"
+                    }
                 }
             }
         },
-        "dto.CreateSampleRequest": {
+        "dto.SignedDocumentDTO": {
             "type": "object",
             "required": [
-                "author",
-                "description",
-                "genre",
-                "price",
-                "title"
+                "algorithm",
+                "document",
+                "key_id",
+                "signature"
             ],
             "properties": {
-                "author": {
-                    "type": "string"
+                "algorithm": {
+                    "type": "string",
+                    "example": "Ed25519"
                 },
-                "description": {
-                    "type": "string"
+                "document": {
+                    "type": "object"
                 },
-                "genre": {
+                "key_id": {
                     "type": "string"
                 },
-                "pack_id": {
+                "signature": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.SigningKeyDTO": {
+            "type": "object",
+            "properties": {
+                "algorithm": {
                     "type": "string"
                 },
-                "price": {
-                    "type": "integer"
+                "key_id": {
+                    "type": "string"
                 },
-                "title": {
+                "public_key": {
+                    "description": "base64",
                     "type": "string"
                 }
             }
         },
-        "dto.DownloadURLResponse": {
+        "dto.StatsPointDTO": {
             "type": "object",
             "properties": {
-                "download_url": {
+                "conversion": {
+                    "description": "доля прослушиваний, закончившихся покупкой",
+                    "type": "number"
+                },
+                "day": {
+                    "description": "YYYY-MM-DD, у итогов пусто",
                     "type": "string"
+                },
+                "downloads": {
+                    "type": "integer"
+                },
+                "plays": {
+                    "type": "integer"
+                },
+                "purchases": {
+                    "type": "integer"
+                },
+                "revenue": {
+                    "description": "выручка в токенах до комиссии",
+                    "type": "integer"
                 }
             }
         },
-        "dto.LoginRequest": {
+        "dto.StatsResponse": {
             "type": "object",
             "properties": {
-                "password": {
+                "from": {
                     "type": "string"
                 },
-                "username": {
+                "packs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.ItemStatsDTO"
+                    }
+                },
+                "samples": {
+                    "description": "до 20 самых прослушиваемых",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.ItemStatsDTO"
+                    }
+                },
+                "series": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.StatsPointDTO"
+                    }
+                },
+                "to": {
                     "type": "string"
+                },
+                "totals": {
+                    "$ref": "#/definitions/dto.StatsPointDTO"
                 }
             }
         },
-        "dto.LoginResponse": {
+        "dto.SubmitSampleRequest": {
             "type": "object",
             "properties": {
-                "token": {
+                "publish_at": {
                     "type": "string"
                 }
             }
         },
-        "dto.PackDTO": {
+        "dto.TagCountDTO": {
             "type": "object",
             "properties": {
-                "author": {
+                "id": {
                     "type": "string"
                 },
-                "created_at": {
-                    "type": "string"
+                "kind": {
+                    "type": "string",
+                    "enum": [
+                        "tag",
+                        "instrument",
+                        "mood"
+                    ]
                 },
-                "description": {
+                "name": {
                     "type": "string"
                 },
-                "genre": {
-                    "type": "string"
+                "sample_count": {
+                    "description": "опубликованных семплов с тегом",
+                    "type": "integer"
                 },
+                "slug": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.TagDTO": {
+            "type": "object",
+            "properties": {
                 "id": {
                     "type": "string"
                 },
+                "kind": {
+                    "type": "string",
+                    "enum": [
+                        "tag",
+                        "instrument",
+                        "mood"
+                    ]
+                },
                 "name": {
                     "type": "string"
                 },
-                "updated_at": {
+                "slug": {
                     "type": "string"
                 }
             }
         },
-        "dto.PackWithSamplesResponse": {
+        "dto.TrashResponse": {
             "type": "object",
             "properties": {
-                "pack": {
-                    "$ref": "#/definitions/dto.PackDTO"
+                "packs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.PackDTO"
+                    }
                 },
                 "samples": {
                     "type": "array",
@@ -983,106 +7121,87 @@ const docTemplate = `{
                 }
             }
         },
-        "dto.PaymentURL": {
+        "dto.TwoFactorCodeRequest": {
             "type": "object",
             "properties": {
-                "url": {
+                "code": {
                     "type": "string"
                 }
             }
         },
-        "dto.PurchaseDTO": {
+        "dto.TwoFactorEnrollResponse": {
             "type": "object",
             "properties": {
-                "id": {
+                "otpauth_uri": {
                     "type": "string"
                 },
-                "price": {
-                    "type": "integer"
-                },
-                "purchasedAt": {
+                "qr_code": {
+                    "description": "PNG в base64",
                     "type": "string"
                 },
-                "sample": {
-                    "description": "опционально, для списка покупок",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/dto.SampleDTO"
-                        }
-                    ]
-                },
-                "sampleId": {
+                "secret": {
                     "type": "string"
                 }
             }
         },
-        "dto.RegisterRequest": {
+        "dto.TwoFactorLoginRequest": {
             "type": "object",
             "properties": {
-                "password": {
+                "challenge_token": {
                     "type": "string"
                 },
-                "username": {
+                "code": {
+                    "description": "код из приложения или код восстановления",
+                    "type": "string"
+                },
+                "device_name": {
                     "type": "string"
                 }
             }
         },
-        "dto.RegisterResponse": {
+        "dto.TwoFactorStatus": {
             "type": "object",
             "properties": {
-                "token": {
+                "enabled": {
+                    "type": "boolean"
+                },
+                "enabled_at": {
                     "type": "string"
+                },
+                "recovery_codes_left": {
+                    "type": "integer"
                 }
             }
         },
-        "dto.SampleDTO": {
+        "dto.UUIDResponse": {
             "type": "object",
             "properties": {
-                "author": {
-                    "type": "string"
-                },
-                "created_at": {
+                "uuid": {
                     "type": "string"
-                },
+                }
+            }
+        },
+        "dto.UpdateCollectionRequest": {
+            "type": "object",
+            "properties": {
                 "description": {
                     "type": "string"
                 },
-                "download_url": {
-                    "type": "string"
-                },
-                "duration": {
-                    "type": "number"
-                },
-                "genre": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "string"
-                },
-                "listen_url": {
-                    "type": "string"
-                },
-                "pack_id": {
-                    "type": "string"
-                },
-                "price": {
-                    "type": "integer"
-                },
-                "size": {
-                    "type": "integer"
-                },
-                "title": {
-                    "type": "string"
+                "is_public": {
+                    "type": "boolean"
                 },
-                "updated_at": {
+                "name": {
                     "type": "string"
                 }
             }
         },
-        "dto.UUIDResponse": {
+        "dto.UpdateGenreRequest": {
             "type": "object",
             "properties": {
-                "uuid": {
+                "name": {
+                    "type": "string"
+                },
+                "parent": {
                     "type": "string"
                 }
             }
@@ -1090,37 +7209,87 @@ const docTemplate = `{
         "dto.UpdatePackRequest": {
             "type": "object",
             "properties": {
-                "author": {
-                    "type": "string"
-                },
                 "description": {
                     "type": "string"
                 },
                 "genre": {
                     "type": "string"
                 },
+                "instruments": {
+                    "description": "из справочника, до 5",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "moods": {
+                    "description": "из справочника, до 3",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "name": {
                     "type": "string"
+                },
+                "tags": {
+                    "description": "свободные теги, до 10, новые создаются автоматически",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 }
             }
         },
-        "dto.UpdateSampleRequest": {
+        "dto.UpdateProfileRequest": {
             "type": "object",
             "properties": {
-                "author": {
+                "bio": {
+                    "type": "string"
+                },
+                "display_name": {
                     "type": "string"
                 },
+                "links": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "remove_avatar": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "dto.UpdateSampleRequest": {
+            "type": "object",
+            "properties": {
                 "description": {
                     "type": "string"
                 },
                 "genre": {
                     "type": "string"
                 },
-                "pack_id": {
-                    "type": "string"
+                "instruments": {
+                    "description": "из справочника, до 5",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 },
-                "price": {
-                    "type": "integer"
+                "moods": {
+                    "description": "из справочника, до 3",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "tags": {
+                    "description": "свободные теги, до 10, новые создаются автоматически",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 },
                 "title": {
                     "type": "string"
@@ -1150,16 +7319,75 @@ const docTemplate = `{
         "dto.UserProfile": {
             "type": "object",
             "properties": {
+                "avatar": {
+                    "$ref": "#/definitions/dto.ImageDTO"
+                },
+                "avatar_url": {
+                    "description": "средняя миниатюра аватара",
+                    "type": "string"
+                },
+                "bio": {
+                    "type": "string"
+                },
+                "display_name": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "email_verified": {
+                    "type": "boolean"
+                },
+                "links": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "login": {
                     "type": "string"
                 },
                 "tokens": {
                     "type": "integer"
                 },
+                "two_factor_enabled": {
+                    "type": "boolean"
+                },
                 "uuid": {
                     "type": "string"
                 }
             }
+        },
+        "dto.VerifiedPurchaseDTO": {
+            "type": "object",
+            "properties": {
+                "license": {
+                    "type": "string"
+                },
+                "price": {
+                    "type": "integer"
+                },
+                "purchase_id": {
+                    "type": "string"
+                },
+                "purchased_at": {
+                    "type": "string"
+                },
+                "sample_id": {
+                    "type": "string"
+                },
+                "user_uuid": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.VerifyEmailRequest": {
+            "type": "object",
+            "properties": {
+                "token": {
+                    "type": "string"
+                }
+            }
         }
     },
     "securityDefinitions": {
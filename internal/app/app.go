@@ -3,10 +3,12 @@ package app
 import (
 	"context"
 	"fmt"
+	"log/slog"
 
 	"github.com/gin-gonic/gin"
 	"github.com/musicman-backend/config"
 	"github.com/musicman-backend/internal/di"
+	"github.com/musicman-backend/internal/domain/entity"
 	"github.com/musicman-backend/internal/http"
 	"github.com/musicman-backend/internal/scheduler"
 	"time"
@@ -17,6 +19,12 @@ type App struct {
 	http      *config.HttpConfig
 	router    *gin.Engine
 	scheduler *scheduler.PaymentScheduler
+	trash     *scheduler.TrashScheduler
+	gc        *scheduler.GCScheduler
+	rateLimit *scheduler.RateLimitScheduler
+	sessions  *scheduler.SessionScheduler
+	analytics *scheduler.AnalyticsScheduler
+	recommend *scheduler.RecommendationScheduler
 }
 
 func BuildApp(ctx context.Context, cfg *config.Config) (*App, error) {
@@ -30,9 +38,19 @@ func BuildApp(ctx context.Context, cfg *config.Config) (*App, error) {
 
 	app.http = &cfg.Http
 
-	app.router = http.SetupRouter(app.container)
+	app.router, err = http.SetupRouter(cfg, app.container)
+	if err != nil {
+		return nil, fmt.Errorf("setup router: %w", err)
+	}
 
 	app.scheduler = scheduler.NewPaymentScheduler(time.Second, app.container.Repository.PaymentRepository, app.container.Service.Payment)
+	app.trash = scheduler.NewTrashScheduler(cfg.Trash.PurgeInterval, app.container.Service.Music)
+	app.gc = scheduler.NewGCScheduler(cfg.GC.Interval, cfg.GC.DeleteOrphans, app.container.Service.GC)
+	app.rateLimit = scheduler.NewRateLimitScheduler(time.Hour, app.container.Service.RateLimit)
+	app.sessions = scheduler.NewSessionScheduler(time.Hour, app.container.Service.Session)
+
+	app.analytics = scheduler.NewAnalyticsScheduler(cfg.Analytics.RollupInterval, app.container.Service.Analytics)
+	app.recommend = scheduler.NewRecommendationScheduler(cfg.Recommendations.Interval, app.container.Service.Recommend)
 
 	return &app, nil
 }
@@ -51,6 +69,34 @@ func (a *App) Run(ctx context.Context) error {
 		a.scheduler.Start(ctx)
 	}(a)
 
+	go func(a *App) {
+		a.trash.Start(ctx)
+	}(a)
+
+	go func(a *App) {
+		a.gc.Start(ctx)
+	}(a)
+
+	go func(a *App) {
+		a.rateLimit.Start(ctx)
+	}(a)
+
+	go func(a *App) {
+		a.sessions.Start(ctx)
+	}(a)
+
+	go func(a *App) {
+		a.container.Service.EventWriter.Start(ctx)
+	}(a)
+
+	go func(a *App) {
+		a.analytics.Start(ctx)
+	}(a)
+
+	go func(a *App) {
+		a.recommend.Start(ctx)
+	}(a)
+
 	err := <-errChan
 	if err != nil {
 		return fmt.Errorf("http server err: %w", err)
@@ -59,6 +105,31 @@ func (a *App) Run(ctx context.Context) error {
 	return nil
 }
 
+// MigrateObjectKeys переносит аудио со старыми ключами в MinIO в новую раскладку
+func (a *App) MigrateObjectKeys(ctx context.Context) error {
+	migrated, err := a.container.Service.Music.MigrateLegacyKeys(ctx)
+	slog.Info("object keys migrated", slog.Int("count", migrated))
+	if err != nil {
+		return fmt.Errorf("migrate legacy keys: %w", err)
+	}
+
+	return nil
+}
+
+// CollectGarbage сверяет хранилище с базой один раз и возвращает отчет
+func (a *App) CollectGarbage(ctx context.Context, deleteOrphans bool) (entity.GCReport, error) {
+	report, err := a.container.Service.GC.Run(ctx, deleteOrphans)
+	if err != nil {
+		return report, fmt.Errorf("collect garbage: %w", err)
+	}
+
+	scheduler.LogGCReport(report)
+
+	return report, nil
+}
+
 func (a *App) Shutdown(ctx context.Context) {
+	// накопленные события статистики дописываются до закрытия базы
+	a.container.Service.EventWriter.Stop()
 	a.container.Repository.Close()
 }
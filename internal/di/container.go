@@ -2,13 +2,20 @@ package di
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/go-resty/resty/v2"
+	"log/slog"
 
 	"github.com/musicman-backend/config"
 	"github.com/musicman-backend/internal/repository"
 	"github.com/musicman-backend/internal/service"
+	"github.com/musicman-backend/internal/service/auth"
+	oauthService "github.com/musicman-backend/internal/service/oauth"
+	"github.com/musicman-backend/pkg/client/oauth"
 	"github.com/musicman-backend/pkg/client/yookassa"
+	"github.com/musicman-backend/pkg/mailer"
+	"github.com/musicman-backend/pkg/signer"
 	"net/url"
 )
 
@@ -37,7 +44,83 @@ func CreateContainer(ctx context.Context, cfg *config.Config) (*Container, error
 		AccountID: cfg.YooKassa.AccountID,
 	})
 
-	container.Service = service.NewManager(container.Repository, yookassaClient)
+	mailClient, err := newMailer(cfg.Mail)
+	if err != nil {
+		return nil, fmt.Errorf("init mailer: %w", err)
+	}
+
+	oauthProviders := make(map[string]oauthService.Provider)
+	for name, provider := range cfg.OAuth.Providers {
+		if provider.ClientID == "" {
+			continue
+		}
+
+		oauthProviders[name] = oauth.New(resty.New(), oauth.Config{
+			ClientID:           provider.ClientID,
+			ClientSecret:       provider.ClientSecret,
+			AuthURL:            provider.AuthURL,
+			TokenURL:           provider.TokenURL,
+			UserInfoURL:        provider.UserInfoURL,
+			RedirectURL:        provider.RedirectURL,
+			Scopes:             provider.Scopes,
+			UserInfoAuth:       provider.UserInfoAuth,
+			SubjectField:       provider.SubjectField,
+			EmailField:         provider.EmailField,
+			EmailVerifiedField: provider.EmailVerifiedField,
+			LoginField:         provider.LoginField,
+			TrustEmail:         provider.TrustEmail,
+		})
+	}
+
+	documentSigner, err := newSigner(cfg.Signing)
+	if err != nil {
+		return nil, fmt.Errorf("init signer: %w", err)
+	}
+
+	container.Service = service.NewManager(cfg, container.Repository, yookassaClient, mailClient, oauthProviders, documentSigner)
 
 	return &container, nil
 }
+
+func newSigner(cfg config.Signing) (*signer.Signer, error) {
+	var documentSigner *signer.Signer
+	var err error
+
+	switch {
+	case cfg.PrivateKey != "":
+		documentSigner, err = signer.New(cfg.PrivateKey)
+	case cfg.EphemeralKey:
+		slog.Warn("signing key is not configured, generated a temporary one: signatures will not verify after restart")
+		documentSigner, err = signer.Generate()
+	default:
+		return nil, errors.New("signing.private_key is not configured")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range cfg.RetiredPublicKeys {
+		if err = documentSigner.AddRetired(key); err != nil {
+			return nil, err
+		}
+	}
+
+	return documentSigner, nil
+}
+
+func newMailer(cfg config.Mail) (auth.Mailer, error) {
+	switch cfg.Driver {
+	case config.MailDriverSMTP:
+		return mailer.NewSMTP(mailer.SMTPConfig{
+			Host:     cfg.SMTP.Host,
+			Port:     cfg.SMTP.Port,
+			Username: cfg.SMTP.Username,
+			Password: cfg.SMTP.Password,
+			From:     cfg.From,
+		}), nil
+	case "", config.MailDriverFile:
+		return mailer.NewFile(cfg.FilePath, cfg.From), nil
+	default:
+		return nil, fmt.Errorf("unknown mail driver: %s", cfg.Driver)
+	}
+}
@@ -3,4 +3,5 @@ package constant
 const (
 	CtxUserUUID  = "ctx-user-uuid"
 	CtxUserLogin = "ctx-user-login"
+	CtxSessionID = "ctx-session-id"
 )
@@ -0,0 +1,8 @@
+package constant
+
+// Типы событий семпла для аналитики
+const (
+	EventPlay     = "play"     // прослушивание превью
+	EventDownload = "download" // скачивание файла
+	EventPurchase = "purchase" // покупка лицензии, amount - цена
+)
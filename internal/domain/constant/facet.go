@@ -0,0 +1,23 @@
+package constant
+
+// Корзины цены в токенах для фильтра и фасетов каталога
+const (
+	PriceFree     = "free"
+	PriceUnder100 = "under-100"
+	Price100To500 = "100-500"
+	PriceOver500  = "over-500"
+)
+
+// Корзины длительности: ваншоты, короткие и длинные лупы
+const (
+	DurationUnder5s = "under-5s"
+	Duration5To15s  = "5-15s"
+	Duration15To30s = "15-30s"
+	DurationOver30s = "over-30s"
+)
+
+// PriceBuckets и DurationBuckets - порядок корзин в фасетах
+var (
+	PriceBuckets    = []string{PriceFree, PriceUnder100, Price100To500, PriceOver500}
+	DurationBuckets = []string{DurationUnder5s, Duration5To15s, Duration15To30s, DurationOver30s}
+)
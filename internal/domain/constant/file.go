@@ -0,0 +1,9 @@
+package constant
+
+const (
+	// TempFilePrefix - префикс всех временных файлов приложения, по нему их находит сборщик мусора
+	TempFilePrefix = "musicman-"
+
+	UploadTempPattern  = TempFilePrefix + "upload-*"
+	MigrateTempPattern = TempFilePrefix + "migrate-*"
+)
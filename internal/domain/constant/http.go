@@ -2,4 +2,6 @@ package constant
 
 const (
 	HeaderAuthorization = "Authorization"
+	// HeaderTwoFactorCode - код 2FA для действий, которые требуют свежей проверки
+	HeaderTwoFactorCode = "X-2FA-Code"
 )
@@ -0,0 +1,8 @@
+package constant
+
+// Размеры миниатюр картинок, они же имена объектов в хранилище
+const (
+	ImageSmall  = "small"
+	ImageMedium = "medium"
+	ImageLarge  = "large"
+)
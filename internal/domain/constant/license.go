@@ -0,0 +1,14 @@
+package constant
+
+const (
+	LicensePersonal   = "personal"   // только некоммерческое использование
+	LicenseCommercial = "commercial" // коммерческие релизы без ограничения тиража
+	LicenseExclusive  = "exclusive"  // единственный покупатель, семпл снимается с продажи
+)
+
+// LicenseTerms - условия лицензий, попадают в сертификат
+var LicenseTerms = map[string]string{
+	LicensePersonal:   "Использование в личных некоммерческих проектах. Продажа, монетизация и публикация на стриминговых площадках запрещены.",
+	LicenseCommercial: "Использование в коммерческих релизах без ограничения тиража и прослушиваний. Перепродажа семпла в исходном виде запрещена.",
+	LicenseExclusive:  "Исключительное право коммерческого использования. После покупки семпл снимается с продажи, ранее выданные лицензии сохраняют силу.",
+}
@@ -0,0 +1,9 @@
+package constant
+
+const (
+	PayoutStatusPending  = "pending"  // ждет решения админа
+	PayoutStatusApproved = "approved" // одобрена, выплата выполняется
+	PayoutStatusPaid     = "paid"
+	PayoutStatusRejected = "rejected"
+	PayoutStatusFailed   = "failed" // выплата не прошла, сумма вернулась на баланс
+)
@@ -0,0 +1,6 @@
+package constant
+
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
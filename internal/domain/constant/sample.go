@@ -0,0 +1,9 @@
+package constant
+
+// Статусы семпла: в каталоге виден только опубликованный, и то не раньше publish_at
+const (
+	SampleStatusDraft     = "draft"     // создан или отклонен модератором, виден только автору
+	SampleStatusPending   = "pending"   // отправлен на модерацию
+	SampleStatusPublished = "published" // одобрен модератором
+	SampleStatusDelisted  = "delisted"  // снят с продажи эксклюзивной покупкой, виден автору и покупателю
+)
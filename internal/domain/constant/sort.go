@@ -0,0 +1,7 @@
+package constant
+
+// Сортировки каталога
+const (
+	SortNew     = "new"     // сначала новые
+	SortPopular = "popular" // по количеству лайков
+)
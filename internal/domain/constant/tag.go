@@ -0,0 +1,14 @@
+package constant
+
+// Виды тегов: свободные теги создают авторы, инструменты и настроения - справочники админа
+const (
+	TagKindTag        = "tag"
+	TagKindInstrument = "instrument"
+	TagKindMood       = "mood"
+)
+
+var TagKinds = map[string]struct{}{
+	TagKindTag:        {},
+	TagKindInstrument: {},
+	TagKindMood:       {},
+}
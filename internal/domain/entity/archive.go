@@ -0,0 +1,19 @@
+package entity
+
+// PackArchive - состав zip-архива пака для конкретного пользователя
+type PackArchive struct {
+	Pack    Pack
+	Files   []ArchiveFile
+	Skipped int // семплы пака, которые пользователь не купил, в архив не входят
+	// Unavailable - некупленные семплы, которые сейчас не продаются: не опубликованы или сняты с продажи
+	Unavailable int
+}
+
+// ArchiveFile - семпл в архиве пака
+type ArchiveFile struct {
+	Sample  Sample
+	Track   int    // номер трека в паке, с единицы
+	Key     string // ключ аудио: купленная версия или текущая у бесплатного семпла
+	License string // тип купленной лицензии, пусто - семпл бесплатный или свой
+	Own     bool   // семпл самого пользователя, лицензия на него не нужна
+}
@@ -0,0 +1,16 @@
+package entity
+
+// AuthorStats - счетчики каталога автора, в корзине не учитываются
+type AuthorStats struct {
+	Samples   int // опубликованные семплы, видимые в каталоге
+	Drafts    int // черновики и отклоненные модератором, видны только автору
+	Pending   int // ждут модерации
+	Packs     int
+	Downloads int // сколько раз скачали семплы автора, включая снятые с продажи
+}
+
+// AuthorProfile - публичный профиль автора
+type AuthorProfile struct {
+	User  User
+	Stats AuthorStats
+}
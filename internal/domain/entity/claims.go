@@ -8,5 +8,7 @@ import (
 type JWTClaims struct {
 	UserUUID uuid.UUID `json:"user_uuid"`
 	Login    string    `json:"login"`
+	// SessionID сессия, по которой выдан токен; у токена второго шага входа пустая
+	SessionID uuid.UUID `json:"sid,omitempty"`
 	jwt.RegisteredClaims
 }
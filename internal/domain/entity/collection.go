@@ -0,0 +1,19 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Collection - именованная подборка семплов пользователя
+type Collection struct {
+	ID          uuid.UUID
+	UserUUID    uuid.UUID
+	Name        string
+	Description string
+	IsPublic    bool // публичную подборку видят все, приватную - только владелец
+	SampleCount int
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
@@ -0,0 +1,55 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Earning - доля автора с одной покупки его семпла
+type Earning struct {
+	ID                uuid.UUID
+	AuthorUUID        uuid.UUID
+	PurchaseID        uuid.UUID
+	SampleID          uuid.UUID
+	Price             int // сколько заплатил покупатель
+	CommissionPercent int
+	Commission        int // доля площадки
+	Amount            int // доля автора
+	CreatedAt         time.Time
+}
+
+// SampleEarnings - заработок автора на одном семпле
+type SampleEarnings struct {
+	SampleID   uuid.UUID
+	Title      string
+	Sales      int
+	Gross      int
+	Commission int
+	Amount     int
+}
+
+// EarningsSummary - баланс автора в токенах
+type EarningsSummary struct {
+	CommissionPercent int
+	Earned            int // всего начислено
+	PaidOut           int // уже выплачено
+	Reserved          int // в заявках на вывод, которые еще не выплачены
+	Balance           int // доступно к выводу
+	Samples           []SampleEarnings
+}
+
+// Payout - заявка автора на вывод заработка
+type Payout struct {
+	ID           uuid.UUID
+	AuthorUUID   uuid.UUID
+	Amount       int
+	Status       string
+	CreatedAt    time.Time
+	ReviewedBy   *uuid.UUID
+	ReviewedAt   *time.Time
+	RejectReason string
+	ExternalID   string // идентификатор выплаты у платежного провайдера
+	Failure      string
+	PaidAt       *time.Time
+}
@@ -0,0 +1,31 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FileInfo - объект в файловом хранилище
+type FileInfo struct {
+	Key          string
+	Size         int64
+	LastModified time.Time
+}
+
+// GCReport - итог сверки базы с файловым хранилищем
+type GCReport struct {
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+
+	ObjectsTotal int `json:"objects_total"`
+	// OrphanedObjects объекты <бакет>/<ключ>, на которые не ссылаются ни версии семплов, ни обложки, ни аватары
+	OrphanedObjects     []string    `json:"orphaned_objects"`
+	OrphanedBytes       int64       `json:"orphaned_bytes"`
+	DeletedObjects      int         `json:"deleted_objects"`
+	MissingObjects      []string    `json:"missing_objects"`
+	SamplesWithoutAudio []uuid.UUID `json:"samples_without_audio"`
+	StaleTempFiles      []string    `json:"stale_temp_files"`
+	DeletedTempFiles    int         `json:"deleted_temp_files"`
+	Errors              []string    `json:"errors"`
+}
@@ -0,0 +1,27 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserIdentity - аккаунт внешнего провайдера (VK ID, Яндекс ID, Google), привязанный к пользователю
+type UserIdentity struct {
+	ID        uuid.UUID
+	UserUUID  uuid.UUID
+	Provider  string
+	Subject   string // id пользователя у провайдера
+	Email     string
+	CreatedAt time.Time
+}
+
+// OAuthState - начатый вход через провайдера
+type OAuthState struct {
+	State        string
+	Provider     string
+	CodeVerifier string
+	UserUUID     *uuid.UUID // не nil - привязка провайдера к уже вошедшему пользователю
+	ExpiresAt    time.Time
+	CreatedAt    time.Time
+}
@@ -0,0 +1,18 @@
+package entity
+
+// ImageSpec - требования к загружаемой картинке и стороны квадратных миниатюр в пикселях
+type ImageSpec struct {
+	Bucket  string
+	MinSide int
+	MaxSide int
+	Small   int
+	Medium  int
+	Large   int
+}
+
+// ImageURLs - ссылки на миниатюры картинки, пустые, если картинки нет
+type ImageURLs struct {
+	Small  string
+	Medium string
+	Large  string
+}
@@ -0,0 +1,7 @@
+package entity
+
+// Page - параметры постраничной выдачи
+type Page struct {
+	Limit  int
+	Offset int
+}
@@ -11,9 +11,13 @@ type Purchase struct {
 	ID        uuid.UUID
 	UserUUID  uuid.UUID
 	SampleID  uuid.UUID
-	Price     int // цена в токенах на момент покупки
+	Price     int    // цена в токенах на момент покупки
+	License   string // тип купленной лицензии
 	CreatedAt time.Time
 
+	SampleVersion int  // купленная версия аудио, 0 - покупка до версионирования
+	FollowUpdates bool // покупатель хочет получать новые версии аудио
+
 	Sample      *Sample
 	DownloadURL string
 	ListenURL   string
@@ -0,0 +1,13 @@
+package entity
+
+import "time"
+
+// RateLimitState - состояние ограничителя по одному ключу (ip, логин).
+// Одна и та же запись хранит и токен-бакет, и счетчик неудачных входов
+type RateLimitState struct {
+	Key         string
+	Tokens      float64
+	Failures    int
+	LockedUntil time.Time
+	UpdatedAt   time.Time // нулевое значение - ключ встречается впервые
+}
@@ -0,0 +1,25 @@
+package entity
+
+import "github.com/google/uuid"
+
+// SampleFeatures - признаки опубликованного семпла, по которым ищутся похожие
+type SampleFeatures struct {
+	ID         uuid.UUID
+	AuthorUUID uuid.UUID
+	Genre      Genre
+	Price      int
+	Duration   float64
+}
+
+// SampleNeighbour - похожий семпл, Score от 0 до 1
+type SampleNeighbour struct {
+	SampleID    uuid.UUID
+	NeighbourID uuid.UUID
+	Score       float64
+}
+
+// UserSample - семпл, купленный пользователем
+type UserSample struct {
+	UserUUID uuid.UUID
+	SampleID uuid.UUID
+}
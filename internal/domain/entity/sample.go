@@ -4,24 +4,68 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+
+	"github.com/musicman-backend/internal/domain/constant"
 )
 
+// Genre - slug жанра из справочника genres
 type Genre = string
 
 // Sample - доменная модель сэмпла
 type Sample struct {
 	ID          uuid.UUID
 	Title       string
-	Author      string
+	AuthorUUID  uuid.UUID
+	Author      string // логин автора, только для чтения
+	AuthorName  string // отображаемое имя автора, логин если имя не задано
 	Description string
 	Genre       Genre
 	Duration    float64
 	Size        int64
-	MinioKey    string
-	PackID      *uuid.UUID // ну типа нуллабл :)
-	Price       int        // цена в токенах
+	MinioKey    string // пустой, пока аудио не загружено
+	Price       int    // минимальная цена лицензии в токенах, 0 - семпл бесплатный
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
+
+	AudioVersion     int    // номер загруженной версии аудио, 0 - аудио нет
+	AudioHash        string // sha256 содержимого аудио
+	OriginalFilename string // имя загруженного файла, только для отображения
+
+	Licenses   []SampleLicense // лицензии в продаже, у бесплатного семпла пусто
+	DelistedAt *time.Time      // когда снят с продажи эксклюзивной покупкой
+	Likes      int
+	Tags       []Tag       // теги, инструменты и настроения
+	PackIDs    []uuid.UUID // паки, в которые входит семпл, в порядке добавления
+
+	Status       string     // constant.SampleStatus*
+	PublishAt    *time.Time // отложенная публикация, nil - сразу после одобрения
+	SubmittedAt  *time.Time // когда автор отправил на модерацию
+	ReviewedAt   *time.Time
+	RejectReason string // причина последнего отказа модератора
+
+	DeletedAt *time.Time // семпл в корзине, покупатели сохраняют к нему доступ
+}
+
+// Public - семпл виден в каталоге: одобрен, время публикации наступило и он не в корзине
+func (s Sample) Public(now time.Time) bool {
+	return s.DeletedAt == nil && s.Status == constant.SampleStatusPublished && (s.PublishAt == nil || !s.PublishAt.After(now))
+}
+
+// SampleLicense - лицензия на семпл и ее цена в токенах
+type SampleLicense struct {
+	Type  string
+	Price int
+}
+
+// License возвращает лицензию типа licenseType, если автор ее продает
+func (s Sample) License(licenseType string) (SampleLicense, bool) {
+	for _, l := range s.Licenses {
+		if l.Type == licenseType {
+			return l, true
+		}
+	}
+
+	return SampleLicense{}, false
 }
 
 // Pack - доменная модель пака
@@ -30,12 +74,31 @@ type Pack struct {
 	Name        string
 	Description string
 	Genre       Genre
-	Author      string
+	AuthorUUID  uuid.UUID
+	Author      string // логин автора, только для чтения
+	AuthorName  string // отображаемое имя автора, логин если имя не задано
+	Likes       int
+	Tags        []Tag
+	CoverKey    string // ключ обложки, пусто - обложки нет
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
+	DeletedAt   *time.Time
 }
 
 type PackWithSamples struct {
 	Pack
 	Samples []Sample
 }
+
+// SampleVersion - загруженная версия аудио семпла
+type SampleVersion struct {
+	ID               uuid.UUID
+	SampleID         uuid.UUID
+	Version          int
+	MinioKey         string
+	Hash             string
+	OriginalFilename string
+	Size             int64
+	Duration         float64
+	UploadedAt       time.Time
+}
@@ -0,0 +1,27 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Session - вход пользователя с конкретного устройства, к ней привязан выданный JWT
+type Session struct {
+	ID         uuid.UUID
+	UserUUID   uuid.UUID
+	DeviceName string
+	UserAgent  string
+	IP         string
+	CreatedAt  time.Time
+	LastSeenAt time.Time
+	ExpiresAt  time.Time
+	RevokedAt  *time.Time
+}
+
+// ClientInfo - откуда пришел запрос на вход
+type ClientInfo struct {
+	DeviceName string // имя устройства от клиента, если не передано - берется из UserAgent
+	UserAgent  string
+	IP         string
+}
@@ -0,0 +1,9 @@
+package entity
+
+// SignedDocument - документ, подписанный ключом площадки. Подпись считается от Payload байт в байт
+type SignedDocument struct {
+	Payload   []byte // JSON документа
+	Signature string
+	KeyID     string
+	Algorithm string
+}
@@ -0,0 +1,48 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SampleEvent - событие прослушивания, скачивания или покупки семпла
+type SampleEvent struct {
+	SampleID  uuid.UUID
+	UserUUID  *uuid.UUID
+	Type      string
+	Amount    int // цена покупки в токенах, у остальных событий 0
+	CreatedAt time.Time
+}
+
+// StatsFilter - период и срез статистики, nil - без ограничения
+type StatsFilter struct {
+	From       time.Time // первый день периода
+	To         time.Time // последний день периода включительно
+	AuthorUUID *uuid.UUID
+	SampleID   *uuid.UUID
+	PackID     *uuid.UUID
+}
+
+// StatsPoint - счетчики за день или за период
+type StatsPoint struct {
+	Day       time.Time
+	Plays     int
+	Downloads int
+	Purchases int
+	Revenue   int
+}
+
+// ItemStats - счетчики семпла или пака за период
+type ItemStats struct {
+	ID    uuid.UUID
+	Title string
+	StatsPoint
+}
+
+type Stats struct {
+	Totals  StatsPoint
+	Series  []StatsPoint // по дню на каждый день периода, дни без событий нулевые
+	Samples []ItemStats
+	Packs   []ItemStats
+}
@@ -0,0 +1,65 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// GenreNode - жанр из справочника, жанры образуют дерево через ParentID
+type GenreNode struct {
+	ID        uuid.UUID
+	Slug      Genre
+	Name      string
+	ParentID  *uuid.UUID
+	CreatedAt time.Time
+
+	Samples int // опубликованных семплов в самом жанре, без поджанров
+	Packs   int
+}
+
+// Tag - тег, инструмент или настроение семпла и пака
+type Tag struct {
+	ID   uuid.UUID
+	Kind string
+	Slug string
+	Name string
+
+	Samples int // опубликованных семплов с тегом, заполняется только в справочнике
+}
+
+// TagSet - названия тегов из запроса по видам. nil при обновлении - оставить теги этого вида как есть
+type TagSet struct {
+	Tags        []string
+	Instruments []string
+	Moods       []string
+}
+
+// SampleFilter - параметры каталога, пустые поля не фильтруют
+type SampleFilter struct {
+	Sort       string
+	Genre      Genre // вместе с поджанрами
+	Tag        string
+	Instrument string
+	Mood       string
+	Author     *uuid.UUID
+	PackID     *uuid.UUID
+	Price      string // корзина из constant.PriceBuckets
+	Duration   string // корзина из constant.DurationBuckets
+}
+
+// FacetValue - значение фасета и сколько семплов каталога с ним при текущих фильтрах
+type FacetValue struct {
+	Value string
+	Label string
+	Count int
+}
+
+// Facets - счетчики для фильтров каталога
+type Facets struct {
+	Genres    []FacetValue
+	Authors   []FacetValue
+	Packs     []FacetValue
+	Prices    []FacetValue
+	Durations []FacetValue
+}
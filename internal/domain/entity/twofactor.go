@@ -0,0 +1,16 @@
+package entity
+
+import "time"
+
+// TwoFactorEnrollment - данные для добавления аккаунта в приложение-аутентификатор
+type TwoFactorEnrollment struct {
+	Secret string
+	URI    string
+	QRCode []byte // PNG
+}
+
+type TwoFactorStatus struct {
+	Enabled       bool
+	EnabledAt     *time.Time
+	RecoveryCodes int // сколько неиспользованных кодов восстановления осталось
+}
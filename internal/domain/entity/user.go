@@ -1,10 +1,69 @@
 package entity
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Role string
 
 type User struct {
 	UUID     uuid.UUID
 	Login    string
 	PassHash string
 	Tokens   int
+	Role     Role
+
+	Email           string     // пустой, если почта не указана
+	EmailVerifiedAt *time.Time // nil, пока почта не подтверждена
+
+	TOTPSecret    string     // секрет 2FA, задан и во время настройки, и после включения
+	TOTPEnabledAt *time.Time // nil - 2FA выключена
+	TOTPLastStep  int64      // последний принятый интервал TOTP
+
+	DisplayName string
+	Bio         string
+	Links       []string
+	AvatarKey   string     // ключ аватара в хранилище, пустой - без аватара
+	DeletedAt   *time.Time // аккаунт удален и обезличен
+
+	CommissionPercent *int // комиссия площадки с продаж автора, nil - по умолчанию
+}
+
+// ProfileUpdate - изменяемые поля профиля, nil - поле не меняется
+type ProfileUpdate struct {
+	DisplayName *string
+	Bio         *string
+	Links       *[]string
+}
+
+// TwoFactorEnabled - для входа нужен второй фактор
+func (u User) TwoFactorEnabled() bool {
+	return u.TOTPEnabledAt != nil
+}
+
+// LoginResult - итог входа: либо JWT, либо, при включенной 2FA, токен для второго шага
+type LoginResult struct {
+	Token          string
+	ChallengeToken string
+}
+
+type TokenPurpose string
+
+const (
+	TokenPurposeVerifyEmail   TokenPurpose = "verify_email"
+	TokenPurposeResetPassword TokenPurpose = "reset_password"
+)
+
+// UserToken - одноразовый токен из письма. В базе хранится только хеш
+type UserToken struct {
+	ID        uuid.UUID
+	UserUUID  uuid.UUID
+	Purpose   TokenPurpose
+	Hash      string
+	Email     string // адрес, который подтверждается токеном
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
 }
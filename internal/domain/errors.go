@@ -1,6 +1,10 @@
 package domain
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"time"
+)
 
 var (
 	ErrNotFound           = errors.New("not found")
@@ -10,4 +14,61 @@ var (
 	ErrAlreadyPurchased   = errors.New("sample already purchased")
 	ErrInsufficientTokens = errors.New("insufficient tokens")
 	ErrSampleIsFree       = errors.New("sample is free")
+	ErrForbidden          = errors.New("forbidden")
+	ErrRestoreExpired     = errors.New("restore period expired")
+	ErrInvalidSignature   = errors.New("invalid signature")
+	ErrLinkExpired        = errors.New("link expired")
+	ErrTooManyRequests    = errors.New("too many requests")
+	ErrWeakPassword       = errors.New("weak password")
+	ErrInvalidEmail       = errors.New("invalid email")
+	ErrEmailAlreadyUsed   = errors.New("email already used")
+	// ErrIdentityAlreadyLinked аккаунт провайдера уже привязан к другому пользователю
+	ErrIdentityAlreadyLinked = errors.New("identity already linked")
+	// ErrLastLoginMethod нельзя отвязать единственный способ входа
+	ErrLastLoginMethod = errors.New("last login method")
+	ErrProviderFailed  = errors.New("identity provider request failed")
+	// ErrTwoFactorRequired действие требует кода 2FA, а он не передан
+	ErrTwoFactorRequired    = errors.New("two-factor code required")
+	ErrInvalidTwoFactorCode = errors.New("invalid two-factor code")
+	ErrTwoFactorEnabled     = errors.New("two-factor authentication already enabled")
+	ErrTwoFactorDisabled    = errors.New("two-factor authentication is not enabled")
+	ErrInvalidLogin         = errors.New("invalid login")
+	// ErrReauthRequired аккаунту без пароля и 2FA для опасного действия нужен свежий вход
+	ErrReauthRequired = errors.New("recent sign-in required")
+	ErrInvalidProfile = errors.New("invalid profile")
+	ErrInvalidImage   = errors.New("invalid image")
+	// ErrInsufficientEarnings на балансе автора меньше запрошенной к выводу суммы
+	ErrInsufficientEarnings = errors.New("insufficient earnings")
+	ErrInvalidPayout        = errors.New("invalid payout")
+	ErrInvalidCommission    = errors.New("invalid commission")
+	// ErrPayoutProcessed заявка на вывод уже рассмотрена
+	ErrPayoutProcessed = errors.New("payout already processed")
+	ErrInvalidLicense  = errors.New("invalid license")
+	// ErrLicenseNotOffered автор не продает лицензию такого типа
+	ErrLicenseNotOffered = errors.New("license not offered")
+	// ErrSampleUnavailable семпл не опубликован или снят с продажи эксклюзивной покупкой
+	ErrSampleUnavailable = errors.New("sample unavailable")
+	ErrInvalidCollection = errors.New("invalid collection")
+	ErrInvalidStatsRange = errors.New("invalid stats range")
+	ErrInvalidGenre      = errors.New("invalid genre")
+	ErrInvalidTag        = errors.New("invalid tag")
+	ErrInvalidPack       = errors.New("invalid pack")
+	// ErrSampleStatus действие недоступно в текущем статусе семпла, например одобрить можно только семпл на модерации
+	ErrSampleStatus  = errors.New("action not allowed in sample status")
+	ErrInvalidReview = errors.New("invalid review")
+	// ErrTaxonomyExists жанр или тег с таким slug уже есть, дубли объединяются через merge
+	ErrTaxonomyExists = errors.New("genre or tag already exists")
 )
+
+// RetryAfterError - запрос отклонен ограничением частоты, повторить можно через RetryAfter
+type RetryAfterError struct {
+	RetryAfter time.Duration
+}
+
+func (e *RetryAfterError) Error() string {
+	return fmt.Sprintf("%s, retry after %s", ErrTooManyRequests, e.RetryAfter)
+}
+
+func (e *RetryAfterError) Unwrap() error {
+	return ErrTooManyRequests
+}
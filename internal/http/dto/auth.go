@@ -1,19 +1,55 @@
 package dto
 
+import "github.com/musicman-backend/internal/domain/entity"
+
 type LoginRequest struct {
-	Username string `json:"username"`
-	Password string `json:"password"`
+	Username   string `json:"username"`
+	Password   string `json:"password"`
+	DeviceName string `json:"device_name,omitempty"` // имя устройства в списке сессий, по умолчанию из User-Agent
 }
 
+// LoginResponse - либо token, либо при включенной 2FA challenge_token для POST /auth/2fa
 type LoginResponse struct {
-	Token string `json:"token"`
+	Token             string `json:"token,omitempty"`
+	TwoFactorRequired bool   `json:"two_factor_required,omitempty"`
+	ChallengeToken    string `json:"challenge_token,omitempty"`
+}
+
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code"` // код из приложения или код восстановления
+	DeviceName     string `json:"device_name,omitempty"`
 }
 
 type RegisterRequest struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
+	Email    string `json:"email,omitempty"` // необязательно, на нее придет письмо с подтверждением
+
+	DeviceName string `json:"device_name,omitempty"`
 }
 
 type RegisterResponse struct {
 	Token string `json:"token"`
 }
+
+type VerifyEmailRequest struct {
+	Token string `json:"token"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+func NewLoginResponse(result entity.LoginResult) LoginResponse {
+	return LoginResponse{
+		Token:             result.Token,
+		TwoFactorRequired: result.ChallengeToken != "",
+		ChallengeToken:    result.ChallengeToken,
+	}
+}
@@ -0,0 +1,59 @@
+package dto
+
+import (
+	"github.com/google/uuid"
+
+	"github.com/musicman-backend/internal/domain/entity"
+)
+
+type AuthorProfileDTO struct {
+	UUID        uuid.UUID `json:"uuid"`
+	Login       string    `json:"login"`
+	DisplayName string    `json:"display_name,omitempty"`
+	Bio         string    `json:"bio,omitempty"`
+	Links       []string  `json:"links"`
+	AvatarURL   string    `json:"avatar_url,omitempty"` // средняя миниатюра аватара
+	Avatar      *ImageDTO `json:"avatar,omitempty"`
+
+	Samples   int `json:"samples"`
+	Packs     int `json:"packs"`
+	Downloads int `json:"downloads"`
+}
+
+// AuthorDashboardDTO - профиль автора для него самого, с черновиками и семплами на модерации
+type AuthorDashboardDTO struct {
+	AuthorProfileDTO
+	Drafts  int `json:"drafts"`
+	Pending int `json:"pending"`
+}
+
+type AuthorSamplesResponse struct {
+	Samples []SampleDTO `json:"samples"`
+	Total   int         `json:"total"`
+}
+
+type AuthorPacksResponse struct {
+	Packs []PackDTO `json:"packs"`
+	Total int       `json:"total"`
+}
+
+func ToAuthorProfileDTO(profile entity.AuthorProfile, avatar entity.ImageURLs) AuthorProfileDTO {
+	links := profile.User.Links
+	if links == nil {
+		links = []string{}
+	}
+
+	return AuthorProfileDTO{
+		UUID:        profile.User.UUID,
+		Login:       profile.User.Login,
+		DisplayName: profile.User.DisplayName,
+		Bio:         profile.User.Bio,
+		Links:       links,
+		AvatarURL:   avatar.Medium,
+		Avatar:      ToImageDTO(avatar),
+
+		Samples:   profile.Stats.Samples,
+		Packs:     profile.Stats.Packs,
+		Downloads: profile.Stats.Downloads,
+	}
+}
@@ -0,0 +1,67 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/musicman-backend/internal/domain/entity"
+)
+
+type CollectionDTO struct {
+	ID          uuid.UUID `json:"id"`
+	UserUUID    uuid.UUID `json:"user_uuid"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	IsPublic    bool      `json:"is_public"`
+	SampleCount int       `json:"sample_count"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type CollectionWithSamplesResponse struct {
+	CollectionDTO `json:"collection"`
+	Samples       []SampleDTO `json:"samples"`
+}
+
+type CreateCollectionRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+	IsPublic    bool   `json:"is_public"`
+}
+
+type UpdateCollectionRequest struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+	IsPublic    *bool   `json:"is_public"`
+}
+
+type AddCollectionSampleRequest struct {
+	SampleID uuid.UUID `json:"sample_id" binding:"required"`
+}
+
+// ReorderCollectionRequest - все семплы подборки в новом порядке
+type ReorderCollectionRequest struct {
+	SampleIDs []uuid.UUID `json:"sample_ids" binding:"required"`
+}
+
+func ToCollectionDTO(c entity.Collection) CollectionDTO {
+	return CollectionDTO{
+		ID:          c.ID,
+		UserUUID:    c.UserUUID,
+		Name:        c.Name,
+		Description: c.Description,
+		IsPublic:    c.IsPublic,
+		SampleCount: c.SampleCount,
+		CreatedAt:   c.CreatedAt,
+		UpdatedAt:   c.UpdatedAt,
+	}
+}
+
+func ToCollectionDTOs(collections []entity.Collection) []CollectionDTO {
+	res := make([]CollectionDTO, len(collections))
+	for i, c := range collections {
+		res[i] = ToCollectionDTO(c)
+	}
+
+	return res
+}
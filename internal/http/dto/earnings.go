@@ -0,0 +1,98 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/musicman-backend/internal/domain/entity"
+)
+
+type SampleEarningsDTO struct {
+	SampleID   uuid.UUID `json:"sample_id"`
+	Title      string    `json:"title"`
+	Sales      int       `json:"sales"`
+	Gross      int       `json:"gross"`      // сколько заплатили покупатели
+	Commission int       `json:"commission"` // доля площадки
+	Amount     int       `json:"amount"`     // доля автора
+}
+
+type EarningsResponse struct {
+	CommissionPercent int                 `json:"commission_percent"`
+	Earned            int                 `json:"earned"`
+	PaidOut           int                 `json:"paid_out"`
+	Reserved          int                 `json:"reserved"` // в заявках на вывод, которые еще не выплачены
+	Balance           int                 `json:"balance"`  // доступно к выводу
+	Samples           []SampleEarningsDTO `json:"samples"`
+}
+
+type PayoutDTO struct {
+	ID           uuid.UUID  `json:"id"`
+	AuthorUUID   uuid.UUID  `json:"author_uuid"`
+	Amount       int        `json:"amount"`
+	Status       string     `json:"status" enums:"pending,approved,paid,rejected,failed"`
+	CreatedAt    time.Time  `json:"created_at"`
+	ReviewedAt   *time.Time `json:"reviewed_at,omitempty"`
+	RejectReason string     `json:"reject_reason,omitempty"`
+	Failure      string     `json:"failure,omitempty"`
+	PaidAt       *time.Time `json:"paid_at,omitempty"`
+}
+
+type PayoutRequest struct {
+	Amount int `json:"amount" binding:"required"`
+}
+
+type RejectPayoutRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// CommissionRequest - null сбрасывает комиссию автора на значение по умолчанию
+type CommissionRequest struct {
+	CommissionPercent *int `json:"commission_percent"`
+}
+
+func ToEarningsResponse(summary entity.EarningsSummary) EarningsResponse {
+	samples := make([]SampleEarningsDTO, len(summary.Samples))
+	for i, e := range summary.Samples {
+		samples[i] = SampleEarningsDTO{
+			SampleID:   e.SampleID,
+			Title:      e.Title,
+			Sales:      e.Sales,
+			Gross:      e.Gross,
+			Commission: e.Commission,
+			Amount:     e.Amount,
+		}
+	}
+
+	return EarningsResponse{
+		CommissionPercent: summary.CommissionPercent,
+		Earned:            summary.Earned,
+		PaidOut:           summary.PaidOut,
+		Reserved:          summary.Reserved,
+		Balance:           summary.Balance,
+		Samples:           samples,
+	}
+}
+
+func ToPayoutDTO(payout entity.Payout) PayoutDTO {
+	return PayoutDTO{
+		ID:           payout.ID,
+		AuthorUUID:   payout.AuthorUUID,
+		Amount:       payout.Amount,
+		Status:       payout.Status,
+		CreatedAt:    payout.CreatedAt,
+		ReviewedAt:   payout.ReviewedAt,
+		RejectReason: payout.RejectReason,
+		Failure:      payout.Failure,
+		PaidAt:       payout.PaidAt,
+	}
+}
+
+func ToPayoutDTOs(payouts []entity.Payout) []PayoutDTO {
+	res := make([]PayoutDTO, len(payouts))
+	for i, payout := range payouts {
+		res[i] = ToPayoutDTO(payout)
+	}
+
+	return res
+}
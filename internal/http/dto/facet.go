@@ -0,0 +1,37 @@
+package dto
+
+import "github.com/musicman-backend/internal/domain/entity"
+
+// FacetValueDTO - value передается в одноименный параметр каталога, label - для показа
+type FacetValueDTO struct {
+	Value string `json:"value"`
+	Label string `json:"label"`
+	Count int    `json:"count"`
+}
+
+type FacetsResponse struct {
+	Genres    []FacetValueDTO `json:"genres"`    // value - slug жанра
+	Authors   []FacetValueDTO `json:"authors"`   // value - uuid автора, до 50 самых частых
+	Packs     []FacetValueDTO `json:"packs"`     // value - id пака, до 50 самых частых
+	Prices    []FacetValueDTO `json:"prices"`    // все корзины цены, в том числе пустые
+	Durations []FacetValueDTO `json:"durations"` // все корзины длительности, в том числе пустые
+}
+
+func ToFacetsResponse(facets entity.Facets) FacetsResponse {
+	return FacetsResponse{
+		Genres:    toFacetValueDTOs(facets.Genres),
+		Authors:   toFacetValueDTOs(facets.Authors),
+		Packs:     toFacetValueDTOs(facets.Packs),
+		Prices:    toFacetValueDTOs(facets.Prices),
+		Durations: toFacetValueDTOs(facets.Durations),
+	}
+}
+
+func toFacetValueDTOs(values []entity.FacetValue) []FacetValueDTO {
+	res := make([]FacetValueDTO, len(values))
+	for i, v := range values {
+		res[i] = FacetValueDTO{Value: v.Value, Label: v.Label, Count: v.Count}
+	}
+
+	return res
+}
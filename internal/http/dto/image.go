@@ -0,0 +1,23 @@
+package dto
+
+import "github.com/musicman-backend/internal/domain/entity"
+
+// ImageDTO - ссылки на квадратные миниатюры картинки
+type ImageDTO struct {
+	Small  string `json:"small"`
+	Medium string `json:"medium"`
+	Large  string `json:"large"`
+}
+
+// ToImageDTO - nil, если картинки нет
+func ToImageDTO(urls entity.ImageURLs) *ImageDTO {
+	if urls == (entity.ImageURLs{}) {
+		return nil
+	}
+
+	return &ImageDTO{
+		Small:  urls.Small,
+		Medium: urls.Medium,
+		Large:  urls.Large,
+	}
+}
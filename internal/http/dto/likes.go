@@ -0,0 +1,7 @@
+package dto
+
+// LikesResponse - лайкнутые пользователем семплы и паки, последние лайки первыми
+type LikesResponse struct {
+	Samples []SampleDTO `json:"samples"`
+	Packs   []PackDTO   `json:"packs"`
+}
@@ -0,0 +1,43 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/musicman-backend/internal/domain/entity"
+)
+
+type OAuthProvidersResponse struct {
+	Providers []string `json:"providers"`
+}
+
+type OAuthURLResponse struct {
+	URL string `json:"url"`
+}
+
+type OAuthCallbackRequest struct {
+	Code  string `json:"code"`
+	State string `json:"state"`
+	// DeviceID передает VK ID вместе с кодом, остальным провайдерам не нужен
+	DeviceID string `json:"device_id,omitempty"`
+
+	DeviceName string `json:"device_name,omitempty"` // имя устройства в списке сессий
+}
+
+type IdentityDTO struct {
+	Provider  string    `json:"provider"`
+	Email     string    `json:"email,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func ToIdentityDTOs(identities []entity.UserIdentity) []IdentityDTO {
+	dtos := make([]IdentityDTO, len(identities))
+	for i, identity := range identities {
+		dtos[i] = IdentityDTO{
+			Provider:  identity.Provider,
+			Email:     identity.Email,
+			CreatedAt: identity.CreatedAt,
+		}
+	}
+
+	return dtos
+}
@@ -0,0 +1,20 @@
+package dto
+
+import "github.com/musicman-backend/internal/domain/entity"
+
+const DefaultPageLimit = 20
+
+// PageQuery - параметры пагинации из query, limit по умолчанию DefaultPageLimit, не больше 100
+type PageQuery struct {
+	Limit  int `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset int `form:"offset" binding:"omitempty,min=0"`
+}
+
+func (q PageQuery) ToEntity() entity.Page {
+	limit := q.Limit
+	if limit == 0 {
+		limit = DefaultPageLimit
+	}
+
+	return entity.Page{Limit: limit, Offset: q.Offset}
+}
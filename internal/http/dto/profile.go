@@ -1,9 +1,95 @@
 package dto
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/musicman-backend/internal/domain/entity"
+)
 
 type UserProfile struct {
 	UUID   uuid.UUID `json:"uuid"`
 	Login  string    `json:"login"`
 	Tokens int       `json:"tokens"`
+
+	Email         string `json:"email,omitempty"`
+	EmailVerified bool   `json:"email_verified"`
+
+	TwoFactorEnabled bool `json:"two_factor_enabled"`
+
+	DisplayName string    `json:"display_name,omitempty"`
+	Bio         string    `json:"bio,omitempty"`
+	Links       []string  `json:"links"`
+	AvatarURL   string    `json:"avatar_url,omitempty"` // средняя миниатюра аватара
+	Avatar      *ImageDTO `json:"avatar,omitempty"`
+}
+
+func ToUserProfile(user entity.User, avatar entity.ImageURLs) UserProfile {
+	links := user.Links
+	if links == nil {
+		links = []string{}
+	}
+
+	return UserProfile{
+		UUID:          user.UUID,
+		Login:         user.Login,
+		Tokens:        user.Tokens,
+		Email:         user.Email,
+		EmailVerified: user.EmailVerifiedAt != nil,
+
+		TwoFactorEnabled: user.TwoFactorEnabled(),
+
+		DisplayName: user.DisplayName,
+		Bio:         user.Bio,
+		Links:       links,
+		AvatarURL:   avatar.Medium,
+		Avatar:      ToImageDTO(avatar),
+	}
+}
+
+// UpdateProfileRequest - отсутствующее поле не меняется, пустая строка его очищает
+type UpdateProfileRequest struct {
+	DisplayName  *string   `json:"display_name,omitempty"`
+	Bio          *string   `json:"bio,omitempty"`
+	Links        *[]string `json:"links,omitempty"`
+	RemoveAvatar bool      `json:"remove_avatar,omitempty"`
+}
+
+type ChangePasswordRequest struct {
+	OldPassword string `json:"old_password"`
+	NewPassword string `json:"new_password"`
+}
+
+type ChangeLoginRequest struct {
+	Login    string `json:"login"`
+	Password string `json:"password"`
+}
+
+type DeleteAccountRequest struct {
+	Password string `json:"password"`
+}
+
+type ChangeEmailRequest struct {
+	Email string `json:"email"`
+}
+
+type TwoFactorEnrollResponse struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+	QRCode string `json:"qr_code"` // PNG в base64
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type TwoFactorStatus struct {
+	Enabled       bool       `json:"enabled"`
+	EnabledAt     *time.Time `json:"enabled_at,omitempty"`
+	RecoveryCodes int        `json:"recovery_codes_left"`
 }
@@ -13,7 +13,22 @@ type PurchaseDTO struct {
 	SampleID    uuid.UUID  `json:"sampleId"`
 	Sample      *SampleDTO `json:"sample,omitempty"` // опционально, для списка покупок
 	Price       int        `json:"price"`
+	License     string     `json:"license"` // personal, commercial или exclusive
 	PurchasedAt time.Time  `json:"purchasedAt"`
+
+	// SampleVersion купленная версия аудио, 0 - текущая
+	SampleVersion int  `json:"sampleVersion"`
+	FollowUpdates bool `json:"followUpdates"`
+}
+
+// PurchaseRequest - тип покупаемой лицензии, без тела покупается коммерческая
+type PurchaseRequest struct {
+	License string `json:"license" example:"commercial"`
+}
+
+// FollowUpdatesRequest - подписка покупки на новые версии аудио
+type FollowUpdatesRequest struct {
+	FollowUpdates bool `json:"followUpdates"`
 }
 
 // ToPurchaseDTO - конвертирует entity.Purchase в PurchaseDTO
@@ -27,8 +42,12 @@ func ToPurchaseDTO(purchase entity.Purchase) PurchaseDTO {
 		ID:          purchase.ID,
 		SampleID:    purchase.SampleID,
 		Price:       purchase.Price,
+		License:     purchase.License,
 		PurchasedAt: purchase.CreatedAt,
 		Sample:      sample,
+
+		SampleVersion: purchase.SampleVersion,
+		FollowUpdates: purchase.FollowUpdates,
 	}
 }
 
@@ -0,0 +1,36 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/musicman-backend/internal/domain/entity"
+)
+
+// ReceiptVerificationResponse - результат проверки чека, reason заполнен у недействительного
+type ReceiptVerificationResponse struct {
+	Valid    bool                 `json:"valid"`
+	Reason   string               `json:"reason,omitempty"`
+	Purchase *VerifiedPurchaseDTO `json:"purchase,omitempty"`
+}
+
+// VerifiedPurchaseDTO - покупка, подтвержденная чеком
+type VerifiedPurchaseDTO struct {
+	PurchaseID  uuid.UUID `json:"purchase_id"`
+	UserUUID    uuid.UUID `json:"user_uuid"`
+	SampleID    uuid.UUID `json:"sample_id"`
+	License     string    `json:"license"`
+	Price       int       `json:"price"`
+	PurchasedAt time.Time `json:"purchased_at"`
+}
+
+func ToVerifiedPurchaseDTO(purchase entity.Purchase) *VerifiedPurchaseDTO {
+	return &VerifiedPurchaseDTO{
+		PurchaseID:  purchase.ID,
+		UserUUID:    purchase.UserUUID,
+		SampleID:    purchase.SampleID,
+		License:     purchase.License,
+		Price:       purchase.Price,
+		PurchasedAt: purchase.CreatedAt,
+	}
+}
@@ -8,19 +8,36 @@ import (
 )
 
 type UpdateSampleRequest struct {
-	Title       *string    `json:"title"`
-	Author      *string    `json:"author"`
-	Description *string    `json:"description"`
-	Genre       *string    `json:"genre"`
-	PackID      *uuid.UUID `json:"pack_id"`
-	Price       *int       `json:"price"`
+	Title       *string `json:"title"`
+	Description *string `json:"description"`
+	Genre       *string `json:"genre"`
+	TagsRequest
+}
+
+type LicenseDTO struct {
+	Type  string `json:"type" binding:"required" example:"commercial"` // personal, commercial или exclusive
+	Price int    `json:"price" binding:"required"`
+}
+
+// SetLicensesRequest - полный список лицензий семпла, пустой делает семпл бесплатным
+type SetLicensesRequest struct {
+	Licenses []LicenseDTO `json:"licenses" binding:"dive"`
+}
+
+func (r SetLicensesRequest) ToEntity() []entity.SampleLicense {
+	res := make([]entity.SampleLicense, len(r.Licenses))
+	for i, l := range r.Licenses {
+		res[i] = entity.SampleLicense{Type: l.Type, Price: l.Price}
+	}
+
+	return res
 }
 
 type CreatePackRequest struct {
 	Name        string `json:"name" binding:"required"`
 	Description string `json:"description" binding:"required"`
-	Genre       string `json:"genre" binding:"required"`
-	Author      string `json:"author" binding:"required"`
+	Genre       string `json:"genre" binding:"required"` // slug или название жанра из справочника
+	TagsRequest
 }
 
 type UUIDResponse struct {
@@ -35,42 +52,126 @@ type UpdatePackRequest struct {
 	Name        *string `json:"name"`
 	Description *string `json:"description"`
 	Genre       *string `json:"genre"`
-	Author      *string `json:"author"`
+	TagsRequest
 }
 
 type SampleDTO struct {
-	ID          uuid.UUID  `json:"id"`
-	Title       string     `json:"title"`
-	Author      string     `json:"author"`
-	Description string     `json:"description"`
-	Genre       string     `json:"genre"`
-	Duration    float64    `json:"duration"`
-	Size        int64      `json:"size"`
-	PackID      *uuid.UUID `json:"pack_id,omitempty"`
-	Price       int        `json:"price"`
-	ListenURL   string     `json:"listen_url"`
-	DownloadURL string     `json:"download_url"`
-	CreatedAt   time.Time  `json:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at"`
+	ID          uuid.UUID    `json:"id"`
+	Title       string       `json:"title"`
+	AuthorUUID  uuid.UUID    `json:"author_uuid"`
+	Author      string       `json:"author"`      // логин автора
+	AuthorName  string       `json:"author_name"` // отображаемое имя автора
+	Description string       `json:"description"`
+	Genre       string       `json:"genre"`
+	Duration    float64      `json:"duration"`
+	Size        int64        `json:"size"`
+	PackIDs     []uuid.UUID  `json:"pack_ids"`        // паки, в которые входит семпл
+	Track       int          `json:"track,omitempty"` // номер трека, только в ответе с паком
+	Price       int          `json:"price"`           // минимальная цена лицензии, 0 - бесплатный
+	Licenses    []LicenseDTO `json:"licenses"`
+	Tags        []TagDTO     `json:"tags"`
+	Likes       int          `json:"likes"`
+	IsLiked     bool         `json:"is_liked"` // текущий пользователь лайкнул семпл
+	ListenURL   string       `json:"listen_url"`
+	DownloadURL string       `json:"download_url"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+
+	// OriginalFilename имя файла, под которым автор загрузил аудио
+	OriginalFilename string `json:"original_filename,omitempty"`
+	// DeletedAt семпл удален автором, но остается доступным покупателям
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	// DelistedAt семпл продан эксклюзивно и снят с продажи
+	DelistedAt *time.Time `json:"delisted_at,omitempty"`
+
+	Status string `json:"status"` // draft, pending, published или delisted
+	// PublishAt отложенная публикация: до этого времени семпла нет в каталоге
+	PublishAt   *time.Time `json:"publish_at,omitempty"`
+	SubmittedAt *time.Time `json:"submitted_at,omitempty"`
+	ReviewedAt  *time.Time `json:"reviewed_at,omitempty"`
+	// RejectReason причина последнего отказа модератора
+	RejectReason string `json:"reject_reason,omitempty"`
+}
+
+// SampleListQuery - параметры каталога семплов
+type SampleListQuery struct {
+	Sort       string `form:"sort" binding:"omitempty,oneof=new popular"` // new - сначала новые, popular - по лайкам
+	Genre      string `form:"genre"`                                      // вместе с поджанрами
+	Tag        string `form:"tag"`
+	Instrument string `form:"instrument"`
+	Mood       string `form:"mood"`
+	Author     string `form:"author" binding:"omitempty,uuid"`
+	PackID     string `form:"pack_id" binding:"omitempty,uuid"`
+	Price      string `form:"price" binding:"omitempty,oneof=free under-100 100-500 over-500"`
+	Duration   string `form:"duration" binding:"omitempty,oneof=under-5s 5-15s 15-30s over-30s"`
+}
+
+// ToEntity - author и pack_id уже проверены при биндинге
+func (q SampleListQuery) ToEntity() entity.SampleFilter {
+	filter := entity.SampleFilter{
+		Sort:       q.Sort,
+		Genre:      q.Genre,
+		Tag:        q.Tag,
+		Instrument: q.Instrument,
+		Mood:       q.Mood,
+		Price:      q.Price,
+		Duration:   q.Duration,
+	}
+	if id, err := uuid.Parse(q.Author); err == nil {
+		filter.Author = &id
+	}
+	if id, err := uuid.Parse(q.PackID); err == nil {
+		filter.PackID = &id
+	}
+
+	return filter
+}
+
+// RecommendationQuery - сколько рекомендаций вернуть, по умолчанию DefaultPageLimit
+type RecommendationQuery struct {
+	Limit int `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+func (q RecommendationQuery) GetLimit() int {
+	if q.Limit == 0 {
+		return DefaultPageLimit
+	}
+
+	return q.Limit
 }
 
 type CreateSampleRequest struct {
 	Title       string     `json:"title" binding:"required"`
-	Author      string     `json:"author" binding:"required"`
 	Description string     `json:"description" binding:"required"`
-	Genre       string     `json:"genre" binding:"required"`
-	PackID      *uuid.UUID `json:"pack_id,omitempty"`
-	Price       int        `json:"price" binding:"required"`
+	Genre       string     `json:"genre" binding:"required"` // slug или название жанра из справочника
+	PackID      *uuid.UUID `json:"pack_id,omitempty"`        // пак, в конец которого добавить семпл
+	Price       int        `json:"price" binding:"required"` // цена коммерческой лицензии
+	TagsRequest
+}
+
+// SubmitSampleRequest - publish_at не задан, семпл появится в каталоге сразу после одобрения
+type SubmitSampleRequest struct {
+	PublishAt *time.Time `json:"publish_at,omitempty"`
+}
+
+type RejectSampleRequest struct {
+	Reason string `json:"reason" binding:"required"`
 }
 
 type PackDTO struct {
-	ID          string    `json:"id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	Genre       string    `json:"genre"`
-	Author      string    `json:"author"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Genre       string     `json:"genre"`
+	AuthorUUID  uuid.UUID  `json:"author_uuid"`
+	Author      string     `json:"author"`      // логин автора
+	AuthorName  string     `json:"author_name"` // отображаемое имя автора
+	Likes       int        `json:"likes"`
+	Tags        []TagDTO   `json:"tags"`
+	Cover       *ImageDTO  `json:"cover,omitempty"` // миниатюры обложки, нет - обложка не загружена
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
 }
 
 type PackWithSamplesResponse struct {
@@ -78,47 +179,118 @@ type PackWithSamplesResponse struct {
 	Samples []SampleDTO `json:"samples"`
 }
 
+type AddPackSampleRequest struct {
+	SampleID uuid.UUID `json:"sample_id" binding:"required"`
+}
+
+// ReorderPackRequest - все семплы пака в порядке треков
+type ReorderPackRequest struct {
+	SampleIDs []uuid.UUID `json:"sample_ids" binding:"required"`
+}
+
 func ToSampleDTO(sample entity.Sample, listenURL string, downloadURL string) SampleDTO {
 	return SampleDTO{
 		ID:          sample.ID,
 		Title:       sample.Title,
+		AuthorUUID:  sample.AuthorUUID,
 		Author:      sample.Author,
+		AuthorName:  sample.AuthorName,
 		Description: sample.Description,
 		Genre:       sample.Genre,
 		Duration:    sample.Duration,
 		Size:        sample.Size,
-		PackID:      sample.PackID,
+		PackIDs:     sample.PackIDs,
 		Price:       sample.Price,
+		Licenses:    ToLicenseDTOs(sample.Licenses),
+		Tags:        ToTagDTOs(sample.Tags),
+		Likes:       sample.Likes,
 		ListenURL:   listenURL,
 		DownloadURL: downloadURL,
 		CreatedAt:   sample.CreatedAt,
 		UpdatedAt:   sample.UpdatedAt,
+
+		OriginalFilename: sample.OriginalFilename,
+		DeletedAt:        sample.DeletedAt,
+		DelistedAt:       sample.DelistedAt,
+
+		Status:       sample.Status,
+		PublishAt:    sample.PublishAt,
+		SubmittedAt:  sample.SubmittedAt,
+		ReviewedAt:   sample.ReviewedAt,
+		RejectReason: sample.RejectReason,
 	}
 }
+
+func ToLicenseDTOs(licenses []entity.SampleLicense) []LicenseDTO {
+	res := make([]LicenseDTO, len(licenses))
+	for i, l := range licenses {
+		res[i] = LicenseDTO{Type: l.Type, Price: l.Price}
+	}
+
+	return res
+}
 func (s *SampleDTO) ToEntity() entity.Sample {
 	return entity.Sample{
 		ID:          s.ID,
 		Title:       s.Title,
+		AuthorUUID:  s.AuthorUUID,
 		Author:      s.Author,
+		AuthorName:  s.AuthorName,
 		Description: s.Description,
 		Genre:       s.Genre,
 		Duration:    s.Duration,
 		Size:        s.Size,
-		PackID:      s.PackID,
+		PackIDs:     s.PackIDs,
 		Price:       s.Price,
 		CreatedAt:   s.CreatedAt,
 		UpdatedAt:   s.UpdatedAt,
 	}
 }
 
-func ToPackDTO(pack entity.Pack) PackDTO {
+func ToPackDTO(pack entity.Pack, cover entity.ImageURLs) PackDTO {
 	return PackDTO{
 		ID:          pack.ID.String(),
 		Name:        pack.Name,
 		Description: pack.Description,
 		Genre:       pack.Genre,
+		AuthorUUID:  pack.AuthorUUID,
 		Author:      pack.Author,
+		AuthorName:  pack.AuthorName,
+		Likes:       pack.Likes,
+		Tags:        ToTagDTOs(pack.Tags),
+		Cover:       ToImageDTO(cover),
 		CreatedAt:   pack.CreatedAt,
 		UpdatedAt:   pack.UpdatedAt,
+		DeletedAt:   pack.DeletedAt,
+	}
+}
+
+type SampleVersionDTO struct {
+	Version          int       `json:"version"`
+	Hash             string    `json:"hash"`
+	OriginalFilename string    `json:"original_filename"`
+	Size             int64     `json:"size"`
+	Duration         float64   `json:"duration"`
+	UploadedAt       time.Time `json:"uploaded_at"`
+}
+
+func ToSampleVersionDTOs(versions []entity.SampleVersion) []SampleVersionDTO {
+	res := make([]SampleVersionDTO, len(versions))
+	for i, v := range versions {
+		res[i] = SampleVersionDTO{
+			Version:          v.Version,
+			Hash:             v.Hash,
+			OriginalFilename: v.OriginalFilename,
+			Size:             v.Size,
+			Duration:         v.Duration,
+			UploadedAt:       v.UploadedAt,
+		}
 	}
+
+	return res
+}
+
+type TrashResponse struct {
+	Samples []SampleDTO `json:"samples"`
+	Packs   []PackDTO   `json:"packs"`
 }
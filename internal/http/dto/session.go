@@ -0,0 +1,38 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/musicman-backend/internal/domain/entity"
+)
+
+type SessionDTO struct {
+	ID         uuid.UUID `json:"id"`
+	DeviceName string    `json:"device_name"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"` // сессия, с которой сделан запрос
+}
+
+func ToSessionDTOs(sessions []entity.Session, current uuid.UUID) []SessionDTO {
+	result := make([]SessionDTO, 0, len(sessions))
+	for _, session := range sessions {
+		result = append(result, SessionDTO{
+			ID:         session.ID,
+			DeviceName: session.DeviceName,
+			UserAgent:  session.UserAgent,
+			IP:         session.IP,
+			CreatedAt:  session.CreatedAt,
+			LastSeenAt: session.LastSeenAt,
+			ExpiresAt:  session.ExpiresAt,
+			Current:    session.ID == current,
+		})
+	}
+
+	return result
+}
@@ -0,0 +1,41 @@
+package dto
+
+import (
+	"encoding/json"
+
+	"github.com/musicman-backend/internal/domain/entity"
+)
+
+// SignedDocumentDTO - документ с подписью площадки. Подпись считается от document байт в байт,
+// публичный ключ для проверки отдается по key_id
+type SignedDocumentDTO struct {
+	Document  json.RawMessage `json:"document" binding:"required" swaggertype:"object"`
+	Signature string          `json:"signature" binding:"required"`
+	KeyID     string          `json:"key_id" binding:"required"`
+	Algorithm string          `json:"algorithm" binding:"required" example:"Ed25519"`
+}
+
+func ToSignedDocumentDTO(doc entity.SignedDocument) SignedDocumentDTO {
+	return SignedDocumentDTO{
+		Document:  doc.Payload,
+		Signature: doc.Signature,
+		KeyID:     doc.KeyID,
+		Algorithm: doc.Algorithm,
+	}
+}
+
+func (d SignedDocumentDTO) ToEntity() entity.SignedDocument {
+	return entity.SignedDocument{
+		Payload:   d.Document,
+		Signature: d.Signature,
+		KeyID:     d.KeyID,
+		Algorithm: d.Algorithm,
+	}
+}
+
+// SigningKeyDTO - публичный ключ площадки для проверки подписей без сервера
+type SigningKeyDTO struct {
+	KeyID     string `json:"key_id"`
+	Algorithm string `json:"algorithm"`
+	PublicKey string `json:"public_key"` // base64
+}
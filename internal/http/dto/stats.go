@@ -0,0 +1,137 @@
+package dto
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/musicman-backend/internal/domain"
+	"github.com/musicman-backend/internal/domain/entity"
+)
+
+// StatsQuery - период в днях включительно и срез статистики, по умолчанию последние 30 дней
+type StatsQuery struct {
+	From     string `form:"from"` // YYYY-MM-DD
+	To       string `form:"to"`   // YYYY-MM-DD
+	SampleID string `form:"sample_id"`
+	PackID   string `form:"pack_id"`
+}
+
+func (q StatsQuery) ToEntity() (entity.StatsFilter, error) {
+	var filter entity.StatsFilter
+	var err error
+
+	if filter.From, err = parseDay(q.From, "from"); err != nil {
+		return filter, err
+	}
+	if filter.To, err = parseDay(q.To, "to"); err != nil {
+		return filter, err
+	}
+	if filter.SampleID, err = parseOptionalUUID(q.SampleID, "sample_id"); err != nil {
+		return filter, err
+	}
+	if filter.PackID, err = parseOptionalUUID(q.PackID, "pack_id"); err != nil {
+		return filter, err
+	}
+
+	return filter, nil
+}
+
+func parseDay(value, name string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	day, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %s must be YYYY-MM-DD", domain.ErrInvalidStatsRange, name)
+	}
+
+	return day, nil
+}
+
+func parseOptionalUUID(value, name string) (*uuid.UUID, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s must be uuid", domain.ErrInvalidStatsRange, name)
+	}
+
+	return &id, nil
+}
+
+type StatsPointDTO struct {
+	Day        string  `json:"day,omitempty"` // YYYY-MM-DD, у итогов пусто
+	Plays      int     `json:"plays"`
+	Downloads  int     `json:"downloads"`
+	Purchases  int     `json:"purchases"`
+	Revenue    int     `json:"revenue"`    // выручка в токенах до комиссии
+	Conversion float64 `json:"conversion"` // доля прослушиваний, закончившихся покупкой
+}
+
+type ItemStatsDTO struct {
+	ID    uuid.UUID `json:"id"`
+	Title string    `json:"title"`
+	StatsPointDTO
+}
+
+type StatsResponse struct {
+	From    string          `json:"from"`
+	To      string          `json:"to"`
+	Totals  StatsPointDTO   `json:"totals"`
+	Series  []StatsPointDTO `json:"series"`
+	Samples []ItemStatsDTO  `json:"samples"` // до 20 самых прослушиваемых
+	Packs   []ItemStatsDTO  `json:"packs"`
+}
+
+func ToStatsResponse(stats entity.Stats) StatsResponse {
+	response := StatsResponse{
+		Totals:  toStatsPointDTO(stats.Totals),
+		Series:  make([]StatsPointDTO, len(stats.Series)),
+		Samples: toItemStatsDTOs(stats.Samples),
+		Packs:   toItemStatsDTOs(stats.Packs),
+	}
+
+	for i, p := range stats.Series {
+		response.Series[i] = toStatsPointDTO(p)
+		response.Series[i].Day = p.Day.Format(time.DateOnly)
+	}
+	if len(response.Series) > 0 {
+		response.From = response.Series[0].Day
+		response.To = response.Series[len(response.Series)-1].Day
+	}
+
+	return response
+}
+
+func toItemStatsDTOs(items []entity.ItemStats) []ItemStatsDTO {
+	result := make([]ItemStatsDTO, len(items))
+	for i, item := range items {
+		result[i] = ItemStatsDTO{
+			ID:            item.ID,
+			Title:         item.Title,
+			StatsPointDTO: toStatsPointDTO(item.StatsPoint),
+		}
+	}
+
+	return result
+}
+
+func toStatsPointDTO(p entity.StatsPoint) StatsPointDTO {
+	var conversion float64
+	if p.Plays > 0 {
+		conversion = float64(p.Purchases) / float64(p.Plays)
+	}
+
+	return StatsPointDTO{
+		Plays:      p.Plays,
+		Downloads:  p.Downloads,
+		Purchases:  p.Purchases,
+		Revenue:    p.Revenue,
+		Conversion: conversion,
+	}
+}
@@ -0,0 +1,136 @@
+package dto
+
+import (
+	"github.com/google/uuid"
+
+	"github.com/musicman-backend/internal/domain/entity"
+)
+
+// TagsRequest - теги семпла или пака по видам. При обновлении отсутствующее поле оставляет теги
+// этого вида как есть, пустой список их удаляет
+type TagsRequest struct {
+	Tags        []string `json:"tags"`        // свободные теги, до 10, новые создаются автоматически
+	Instruments []string `json:"instruments"` // из справочника, до 5
+	Moods       []string `json:"moods"`       // из справочника, до 3
+}
+
+func (r TagsRequest) ToEntity() entity.TagSet {
+	return entity.TagSet{
+		Tags:        r.Tags,
+		Instruments: r.Instruments,
+		Moods:       r.Moods,
+	}
+}
+
+// ToUpdate - nil, если теги в запросе не переданы
+func (r TagsRequest) ToUpdate() *entity.TagSet {
+	if r.Tags == nil && r.Instruments == nil && r.Moods == nil {
+		return nil
+	}
+
+	set := r.ToEntity()
+	return &set
+}
+
+type TagDTO struct {
+	ID   uuid.UUID `json:"id"`
+	Kind string    `json:"kind" enums:"tag,instrument,mood"`
+	Slug string    `json:"slug"`
+	Name string    `json:"name"`
+}
+
+type TagCountDTO struct {
+	TagDTO
+	SampleCount int `json:"sample_count"` // опубликованных семплов с тегом
+}
+
+type GenreDTO struct {
+	ID          uuid.UUID  `json:"id"`
+	Slug        string     `json:"slug"`
+	Name        string     `json:"name"`
+	ParentID    *uuid.UUID `json:"parent_id,omitempty"`
+	SampleCount int        `json:"sample_count"` // опубликованных семплов в жанре без поджанров
+	PackCount   int        `json:"pack_count"`
+}
+
+// TagListQuery - фильтр справочника тегов
+type TagListQuery struct {
+	Kind   string `form:"kind" binding:"omitempty,oneof=tag instrument mood"`
+	Search string `form:"q"` // начало названия
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=200"`
+}
+
+type CreateGenreRequest struct {
+	Name   string `json:"name" binding:"required"`
+	Parent string `json:"parent"` // slug родителя, пусто - корневой жанр
+}
+
+// UpdateGenreRequest - новое название меняет и slug, пустой parent делает жанр корневым
+type UpdateGenreRequest struct {
+	Name   *string `json:"name"`
+	Parent *string `json:"parent"`
+}
+
+type MergeGenreRequest struct {
+	Into string `json:"into" binding:"required"` // slug жанра, в который переносится все
+}
+
+type CreateTagRequest struct {
+	Kind string `json:"kind" binding:"required,oneof=tag instrument mood"`
+	Name string `json:"name" binding:"required"`
+}
+
+type RenameTagRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type MergeTagRequest struct {
+	Into uuid.UUID `json:"into" binding:"required"`
+}
+
+func ToTagDTO(tag entity.Tag) TagDTO {
+	return TagDTO{
+		ID:   tag.ID,
+		Kind: tag.Kind,
+		Slug: tag.Slug,
+		Name: tag.Name,
+	}
+}
+
+func ToTagDTOs(tags []entity.Tag) []TagDTO {
+	res := make([]TagDTO, len(tags))
+	for i, t := range tags {
+		res[i] = ToTagDTO(t)
+	}
+
+	return res
+}
+
+func ToTagCountDTOs(tags []entity.Tag) []TagCountDTO {
+	res := make([]TagCountDTO, len(tags))
+	for i, t := range tags {
+		res[i] = TagCountDTO{TagDTO: ToTagDTO(t), SampleCount: t.Samples}
+	}
+
+	return res
+}
+
+func ToGenreDTO(genre entity.GenreNode) GenreDTO {
+	return GenreDTO{
+		ID:          genre.ID,
+		Slug:        genre.Slug,
+		Name:        genre.Name,
+		ParentID:    genre.ParentID,
+		SampleCount: genre.Samples,
+		PackCount:   genre.Packs,
+	}
+}
+
+func ToGenreDTOs(genres []entity.GenreNode) []GenreDTO {
+	res := make([]GenreDTO, len(genres))
+	for i, g := range genres {
+		res[i] = ToGenreDTO(g)
+	}
+
+	return res
+}
@@ -0,0 +1,115 @@
+package analytics
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/musicman-backend/internal/domain"
+	"github.com/musicman-backend/internal/domain/constant"
+	"github.com/musicman-backend/internal/domain/entity"
+	"github.com/musicman-backend/internal/http/dto"
+)
+
+type Service interface {
+	AuthorStats(ctx context.Context, userUUID uuid.UUID, filter entity.StatsFilter) (entity.Stats, error)
+	Stats(ctx context.Context, filter entity.StatsFilter) (entity.Stats, error)
+}
+
+type Handler struct {
+	service Service
+}
+
+func New(service Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+// GetAuthorStats
+// @Summary Статистика автора
+// @Description Прослушивания, скачивания, покупки и выручка по дням, а также самые популярные семплы и паки автора. Статистика обновляется раз в несколько минут
+// @Tags authors
+// @Produce json
+// @Security BearerAuth
+// @Param from query string false "Первый день периода, YYYY-MM-DD, по умолчанию 30 дней назад"
+// @Param to query string false "Последний день периода включительно, YYYY-MM-DD, по умолчанию сегодня"
+// @Param sample_id query string false "Только этот семпл"
+// @Param pack_id query string false "Только семплы этого пака"
+// @Success 200 {object} dto.StatsResponse
+// @Failure 400 {object} dto.ApiError "Неверный период, период длиннее 366 дней"
+// @Failure 401 {object} dto.ApiError
+// @Failure 500 {object} dto.ApiError
+// @Router /authors/me/stats [get]
+func (h *Handler) GetAuthorStats(c *gin.Context) {
+	userUUID, err := uuid.Parse(c.GetString(constant.CtxUserUUID))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, dto.NewApiError("unauthorized"))
+		return
+	}
+
+	filter, ok := bindFilter(c)
+	if !ok {
+		return
+	}
+
+	stats, err := h.service.AuthorStats(c.Request.Context(), userUUID, filter)
+	h.respond(c, stats, err)
+}
+
+// GetStats
+// @Summary Статистика площадки
+// @Description То же, что статистика автора, но по всем авторам
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param from query string false "Первый день периода, YYYY-MM-DD, по умолчанию 30 дней назад"
+// @Param to query string false "Последний день периода включительно, YYYY-MM-DD, по умолчанию сегодня"
+// @Param sample_id query string false "Только этот семпл"
+// @Param pack_id query string false "Только семплы этого пака"
+// @Success 200 {object} dto.StatsResponse
+// @Failure 400 {object} dto.ApiError "Неверный период, период длиннее 366 дней"
+// @Failure 403 {object} dto.ApiError
+// @Failure 500 {object} dto.ApiError
+// @Router /admin/stats [get]
+func (h *Handler) GetStats(c *gin.Context) {
+	filter, ok := bindFilter(c)
+	if !ok {
+		return
+	}
+
+	stats, err := h.service.Stats(c.Request.Context(), filter)
+	h.respond(c, stats, err)
+}
+
+func bindFilter(c *gin.Context) (entity.StatsFilter, bool) {
+	var query dto.StatsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewApiError(err.Error()))
+		return entity.StatsFilter{}, false
+	}
+
+	filter, err := query.ToEntity()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewApiError(err.Error()))
+		return entity.StatsFilter{}, false
+	}
+
+	return filter, true
+}
+
+func (h *Handler) respond(c *gin.Context, stats entity.Stats, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidStatsRange):
+		c.JSON(http.StatusBadRequest, dto.NewApiError(err.Error()))
+	case err != nil:
+		slog.Error("failed to get stats", slog.String("err", err.Error()))
+		c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
+	default:
+		c.JSON(http.StatusOK, dto.ToStatsResponse(stats))
+	}
+}
@@ -0,0 +1,83 @@
+package archive
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/musicman-backend/internal/domain"
+	"github.com/musicman-backend/internal/domain/constant"
+	"github.com/musicman-backend/internal/domain/entity"
+	"github.com/musicman-backend/internal/http/dto"
+)
+
+type Service interface {
+	Pack(ctx context.Context, userUUID, packID uuid.UUID) (entity.PackArchive, error)
+	Write(ctx context.Context, userUUID uuid.UUID, archive entity.PackArchive, w io.Writer) error
+}
+
+type Handler struct {
+	service Service
+}
+
+func New(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// DownloadPack
+// @Summary Скачать пак одним zip-архивом
+// @Description Архив собирается на лету и отдается потоком. В него входят свои семплы, купленные в купленной версии и бесплатные, а также README.txt со списком файлов и LICENSE.txt с условиями лицензий. Некупленные и недоступные для покупки семплы пропускаются, их число указано в README.txt
+// @Tags packs
+// @Produce application/zip
+// @Security BearerAuth
+// @Param id path string true "Pack ID"
+// @Success 200 {file} file "zip-архив"
+// @Failure 400 {object} dto.ApiError
+// @Failure 401 {object} dto.ApiError
+// @Failure 403 {object} dto.ApiError "В паке нет своих, купленных или бесплатных семплов"
+// @Failure 404 {object} dto.ApiError
+// @Failure 500 {object} dto.ApiError
+// @Router /packs/{id}/download [get]
+func (h *Handler) DownloadPack(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewApiError(err.Error()))
+		return
+	}
+
+	userUUID, err := uuid.Parse(c.GetString(constant.CtxUserUUID))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, dto.NewApiError("unauthorized"))
+		return
+	}
+
+	archive, err := h.service.Pack(c.Request.Context(), userUUID, id)
+	if errors.Is(err, domain.ErrNotFound) {
+		c.JSON(http.StatusNotFound, dto.NewApiError(err.Error()))
+		return
+	}
+	if errors.Is(err, domain.ErrForbidden) {
+		c.JSON(http.StatusForbidden, dto.NewApiError("в паке нет купленных или бесплатных семплов"))
+		return
+	}
+	if err != nil {
+		slog.Error("failed to prepare pack archive", slog.String("err", err.Error()))
+		c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
+		return
+	}
+
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", `attachment; filename="pack-`+id.String()+`.zip"`)
+	c.Status(http.StatusOK)
+
+	// заголовки уже отправлены, обрыв архива клиент увидит как битый zip
+	if err = h.service.Write(c.Request.Context(), userUUID, archive, c.Writer); err != nil {
+		slog.Error("failed to stream pack archive",
+			slog.String("pack_id", id.String()), slog.String("err", err.Error()))
+	}
+}
@@ -9,12 +9,18 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"github.com/musicman-backend/internal/domain"
+	"github.com/musicman-backend/internal/domain/entity"
 	"github.com/musicman-backend/internal/http/dto"
+	"github.com/musicman-backend/internal/http/middleware"
 )
 
 type Auth interface {
-	Login(ctx context.Context, login, password string) (string, error)
-	Register(ctx context.Context, login, password string) (string, error)
+	Login(ctx context.Context, login, password string, client entity.ClientInfo) (entity.LoginResult, error)
+	LoginTwoFactor(ctx context.Context, challenge, code string, client entity.ClientInfo) (string, error)
+	Register(ctx context.Context, login, password, email string, client entity.ClientInfo) (string, error)
+	VerifyEmail(ctx context.Context, token string) error
+	ForgotPassword(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, token, password string) error
 }
 
 type Handler struct {
@@ -29,7 +35,7 @@ func NewHandler(auth Auth) *Handler {
 
 // Login
 // @Summary Аутентификация пользователя
-// @Description Выполняет вход пользователя в систему и возвращает JWT токен
+// @Description Выполняет вход пользователя в систему и возвращает JWT токен. При включенной 2FA вместо него возвращает challenge_token для POST /auth/2fa
 // @Tags auth
 // @Accept json
 // @Produce json
@@ -37,6 +43,8 @@ func NewHandler(auth Auth) *Handler {
 // @Success 200 {object} dto.LoginResponse "Успешная аутентификация"
 // @Failure 400 {object} dto.ApiError "Неверный формат запроса"
 // @Failure 401 {object} dto.ApiError "Неверные учетные данные"
+// @Failure 429 {object} dto.ApiError "Слишком много попыток, повторить через Retry-After секунд"
+// @Header 429 {integer} Retry-After "Через сколько секунд можно повторить"
 // @Failure 500 {object} dto.ApiError "Внутренняя ошибка сервера"
 // @Router /auth/sign-in [post]
 func (h *Handler) Login(ctx *gin.Context) {
@@ -47,7 +55,15 @@ func (h *Handler) Login(ctx *gin.Context) {
 		return
 	}
 
-	token, err := h.auth.Login(ctx, req.Username, req.Password)
+	result, err := h.auth.Login(ctx, req.Username, req.Password, middleware.ClientInfo(ctx, req.DeviceName))
+
+	var retryErr *domain.RetryAfterError
+	if errors.As(err, &retryErr) {
+		slog.Warn("login rate limited", slog.String("login", req.Username))
+		middleware.AbortTooManyRequests(ctx, retryErr)
+		return
+	}
+
 	if err != nil && !errors.Is(err, domain.ErrInvalidCredentials) {
 		slog.Error("failed ti login", slog.String("err", err.Error()))
 		ctx.AbortWithStatus(http.StatusInternalServerError)
@@ -60,6 +76,55 @@ func (h *Handler) Login(ctx *gin.Context) {
 		return
 	}
 
+	ctx.JSON(http.StatusOK, dto.NewLoginResponse(result))
+}
+
+// LoginTwoFactor
+// @Summary Второй шаг входа
+// @Description Меняет challenge_token из входа и код 2FA (или код восстановления) на JWT токен
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body dto.TwoFactorLoginRequest true "Токен первого шага и код"
+// @Success 200 {object} dto.LoginResponse "Успешная аутентификация"
+// @Failure 400 {object} dto.ApiError "Неверный формат запроса"
+// @Failure 401 {object} dto.ApiError "Неверный код или токен первого шага устарел"
+// @Failure 429 {object} dto.ApiError "Слишком много попыток, повторить через Retry-After секунд"
+// @Header 429 {integer} Retry-After "Через сколько секунд можно повторить"
+// @Failure 500 {object} dto.ApiError "Внутренняя ошибка сервера"
+// @Router /auth/2fa [post]
+func (h *Handler) LoginTwoFactor(ctx *gin.Context) {
+	var req dto.TwoFactorLoginRequest
+	if err := ctx.ShouldBind(&req); err != nil || req.ChallengeToken == "" {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.NewApiError("некорекктное тело запроса"))
+		return
+	}
+
+	token, err := h.auth.LoginTwoFactor(ctx, req.ChallengeToken, req.Code, middleware.ClientInfo(ctx, req.DeviceName))
+
+	var retryErr *domain.RetryAfterError
+	if errors.As(err, &retryErr) {
+		middleware.AbortTooManyRequests(ctx, retryErr)
+		return
+	}
+
+	if errors.Is(err, domain.ErrInvalidToken) {
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, dto.NewApiError("Вход устарел, начните заново"))
+		return
+	}
+
+	if errors.Is(err, domain.ErrInvalidTwoFactorCode) {
+		slog.Warn("invalid two-factor code on login")
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, dto.NewApiError("Неверный код"))
+		return
+	}
+
+	if err != nil {
+		slog.Error("failed to login with second factor", slog.String("err", err.Error()))
+		ctx.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
 	ctx.JSON(http.StatusOK, dto.LoginResponse{Token: token})
 }
 
@@ -71,8 +136,10 @@ func (h *Handler) Login(ctx *gin.Context) {
 // @Produce json
 // @Param request body dto.RegisterRequest true "Данные для регистрации"
 // @Success 200 {object} dto.RegisterResponse "Успешная регистрация"
-// @Failure 400 {object} dto.ApiError "Неверный формат запроса"
-// @Failure 409 {object} dto.ApiError "Пользователь уже существует"
+// @Failure 400 {object} dto.ApiError "Неверный формат запроса, недопустимый логин, слабый пароль или некорректная почта"
+// @Failure 409 {object} dto.ApiError "Пользователь или почта уже существуют"
+// @Failure 429 {object} dto.ApiError "Слишком много регистраций, повторить через Retry-After секунд"
+// @Header 429 {integer} Retry-After "Через сколько секунд можно повторить"
 // @Failure 500 {object} dto.ApiError "Внутренняя ошибка сервера"
 // @Router /auth/sign-up [post]
 func (h *Handler) Register(ctx *gin.Context) {
@@ -83,7 +150,17 @@ func (h *Handler) Register(ctx *gin.Context) {
 		return
 	}
 
-	token, err := h.auth.Register(ctx, req.Username, req.Password)
+	token, err := h.auth.Register(ctx, req.Username, req.Password, req.Email, middleware.ClientInfo(ctx, req.DeviceName))
+	if errors.Is(err, domain.ErrInvalidLogin) || errors.Is(err, domain.ErrWeakPassword) || errors.Is(err, domain.ErrInvalidEmail) {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.NewApiError(err.Error()))
+		return
+	}
+
+	if errors.Is(err, domain.ErrEmailAlreadyUsed) {
+		ctx.AbortWithStatusJSON(http.StatusConflict, dto.NewApiError("Пользователь с такой почтой уже существует"))
+		return
+	}
+
 	if err != nil && !errors.Is(err, domain.ErrUserAlreadyExists) {
 		slog.Error("failed to register", slog.String("err", err.Error()))
 		ctx.AbortWithStatus(http.StatusInternalServerError)
@@ -98,3 +175,115 @@ func (h *Handler) Register(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, dto.RegisterResponse{Token: token})
 }
+
+// VerifyEmail
+// @Summary Подтверждение почты
+// @Description Подтверждает почту по одноразовому токену из письма
+// @Tags auth
+// @Accept json
+// @Param request body dto.VerifyEmailRequest true "Токен из письма"
+// @Success 204 "Почта подтверждена"
+// @Failure 400 {object} dto.ApiError "Токен неверный, просрочен или уже использован"
+// @Failure 409 {object} dto.ApiError "Почту уже подтвердил другой пользователь"
+// @Failure 500 {object} dto.ApiError "Внутренняя ошибка сервера"
+// @Router /auth/verify-email [post]
+func (h *Handler) VerifyEmail(ctx *gin.Context) {
+	var req dto.VerifyEmailRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		slog.Warn("invalid request", slog.String("err", err.Error()))
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.NewApiError("некорекктное тело запроса"))
+		return
+	}
+
+	err := h.auth.VerifyEmail(ctx, req.Token)
+	if errors.Is(err, domain.ErrInvalidToken) {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.NewApiError("Ссылка недействительна или устарела"))
+		return
+	}
+
+	if errors.Is(err, domain.ErrEmailAlreadyUsed) {
+		ctx.AbortWithStatusJSON(http.StatusConflict, dto.NewApiError("Почту уже подтвердил другой пользователь"))
+		return
+	}
+
+	if err != nil {
+		slog.Error("failed to verify email", slog.String("err", err.Error()))
+		ctx.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// ForgotPassword
+// @Summary Запрос сброса пароля
+// @Description Отправляет ссылку для сброса пароля на подтвержденную почту. Ответ не зависит от того, есть ли такой пользователь
+// @Tags auth
+// @Accept json
+// @Param request body dto.ForgotPasswordRequest true "Почта аккаунта"
+// @Success 202 "Если почта зарегистрирована и подтверждена, письмо отправлено"
+// @Failure 400 {object} dto.ApiError "Некорректная почта"
+// @Failure 429 {object} dto.ApiError "Слишком много запросов, повторить через Retry-After секунд"
+// @Header 429 {integer} Retry-After "Через сколько секунд можно повторить"
+// @Failure 500 {object} dto.ApiError "Внутренняя ошибка сервера"
+// @Router /auth/forgot-password [post]
+func (h *Handler) ForgotPassword(ctx *gin.Context) {
+	var req dto.ForgotPasswordRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		slog.Warn("invalid request", slog.String("err", err.Error()))
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.NewApiError("некорекктное тело запроса"))
+		return
+	}
+
+	err := h.auth.ForgotPassword(ctx, req.Email)
+	if errors.Is(err, domain.ErrInvalidEmail) {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.NewApiError(err.Error()))
+		return
+	}
+
+	if err != nil {
+		slog.Error("failed to send password reset", slog.String("err", err.Error()))
+		ctx.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	ctx.Status(http.StatusAccepted)
+}
+
+// ResetPassword
+// @Summary Сброс пароля
+// @Description Задает новый пароль по одноразовому токену из письма
+// @Tags auth
+// @Accept json
+// @Param request body dto.ResetPasswordRequest true "Токен из письма и новый пароль"
+// @Success 204 "Пароль изменен"
+// @Failure 400 {object} dto.ApiError "Токен недействителен или пароль слишком слабый"
+// @Failure 500 {object} dto.ApiError "Внутренняя ошибка сервера"
+// @Router /auth/reset-password [post]
+func (h *Handler) ResetPassword(ctx *gin.Context) {
+	var req dto.ResetPasswordRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		slog.Warn("invalid request", slog.String("err", err.Error()))
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.NewApiError("некорекктное тело запроса"))
+		return
+	}
+
+	err := h.auth.ResetPassword(ctx, req.Token, req.Password)
+	if errors.Is(err, domain.ErrInvalidToken) {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.NewApiError("Ссылка недействительна или устарела"))
+		return
+	}
+
+	if errors.Is(err, domain.ErrWeakPassword) {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.NewApiError(err.Error()))
+		return
+	}
+
+	if err != nil {
+		slog.Error("failed to reset password", slog.String("err", err.Error()))
+		ctx.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
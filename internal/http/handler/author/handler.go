@@ -0,0 +1,278 @@
+package author
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/musicman-backend/internal/domain"
+	"github.com/musicman-backend/internal/domain/constant"
+	"github.com/musicman-backend/internal/domain/entity"
+	"github.com/musicman-backend/internal/http/dto"
+)
+
+type Service interface {
+	GetProfile(ctx context.Context, login string) (entity.AuthorProfile, error)
+	GetSamples(ctx context.Context, login string, page entity.Page) ([]entity.Sample, int, error)
+	GetPacks(ctx context.Context, login string, page entity.Page) ([]entity.Pack, int, error)
+	GetDashboard(ctx context.Context, userUUID uuid.UUID) (entity.AuthorProfile, error)
+	GetOwnSamples(ctx context.Context, userUUID uuid.UUID, page entity.Page) ([]entity.Sample, int, error)
+	GetOwnPacks(ctx context.Context, userUUID uuid.UUID, page entity.Page) ([]entity.Pack, int, error)
+}
+
+type AvatarURLGetter interface {
+	AvatarURLs(ctx context.Context, key string) (entity.ImageURLs, error)
+}
+
+// SampleConverter собирает семплы со ссылками, доступными пользователю, и паки с обложками
+type SampleConverter interface {
+	SampleDTOs(ctx context.Context, userUUID uuid.UUID, samples []entity.Sample) ([]dto.SampleDTO, error)
+	PackDTOs(ctx context.Context, packs []entity.Pack) ([]dto.PackDTO, error)
+}
+
+type Handler struct {
+	service Service
+	avatars AvatarURLGetter
+	samples SampleConverter
+}
+
+func New(service Service, avatars AvatarURLGetter, samples SampleConverter) *Handler {
+	return &Handler{
+		service: service,
+		avatars: avatars,
+		samples: samples,
+	}
+}
+
+// GetAuthor
+// @Summary Профиль автора
+// @Description Публичный профиль: био, аватар, количество семплов и паков, сколько раз покупали семплы автора
+// @Tags authors
+// @Produce json
+// @Security BearerAuth
+// @Param login path string true "Логин автора"
+// @Success 200 {object} dto.AuthorProfileDTO
+// @Failure 404 {object} dto.ApiError
+// @Failure 500 {object} dto.ApiError
+// @Router /authors/{login} [get]
+func (h *Handler) GetAuthor(c *gin.Context) {
+	profile, err := h.service.GetProfile(c.Request.Context(), c.Param("login"))
+	if errors.Is(err, domain.ErrNotFound) {
+		c.JSON(http.StatusNotFound, dto.NewApiError("автор не найден"))
+		return
+	}
+	if err != nil {
+		slog.Error("failed to get author", slog.String("err", err.Error()))
+		c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
+		return
+	}
+
+	response, err := h.profileDTO(c.Request.Context(), profile)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetAuthorSamples
+// @Summary Семплы автора
+// @Description Опубликованные семплы автора (с загруженным аудио), новые первыми
+// @Tags authors
+// @Produce json
+// @Security BearerAuth
+// @Param login path string true "Логин автора"
+// @Param limit query int false "Размер страницы, по умолчанию 20, максимум 100"
+// @Param offset query int false "Сколько пропустить"
+// @Success 200 {object} dto.AuthorSamplesResponse
+// @Failure 400 {object} dto.ApiError
+// @Failure 404 {object} dto.ApiError
+// @Failure 500 {object} dto.ApiError
+// @Router /authors/{login}/samples [get]
+func (h *Handler) GetAuthorSamples(c *gin.Context) {
+	page, userUUID, ok := h.pageRequest(c)
+	if !ok {
+		return
+	}
+
+	samples, total, err := h.service.GetSamples(c.Request.Context(), c.Param("login"), page)
+	h.writeSamples(c, userUUID, samples, total, err)
+}
+
+// GetAuthorPacks
+// @Summary Паки автора
+// @Tags authors
+// @Produce json
+// @Security BearerAuth
+// @Param login path string true "Логин автора"
+// @Param limit query int false "Размер страницы, по умолчанию 20, максимум 100"
+// @Param offset query int false "Сколько пропустить"
+// @Success 200 {object} dto.AuthorPacksResponse
+// @Failure 400 {object} dto.ApiError
+// @Failure 404 {object} dto.ApiError
+// @Failure 500 {object} dto.ApiError
+// @Router /authors/{login}/packs [get]
+func (h *Handler) GetAuthorPacks(c *gin.Context) {
+	page, _, ok := h.pageRequest(c)
+	if !ok {
+		return
+	}
+
+	packs, total, err := h.service.GetPacks(c.Request.Context(), c.Param("login"), page)
+	h.writePacks(c, packs, total, err)
+}
+
+// GetDashboard
+// @Summary Кабинет автора
+// @Description Профиль текущего пользователя как автора вместе с количеством черновиков и семплов на модерации
+// @Tags authors
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.AuthorDashboardDTO
+// @Failure 401 {object} dto.ApiError
+// @Failure 500 {object} dto.ApiError
+// @Router /authors/me [get]
+func (h *Handler) GetDashboard(c *gin.Context) {
+	userUUID, err := uuid.Parse(c.GetString(constant.CtxUserUUID))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, dto.NewApiError("unauthorized"))
+		return
+	}
+
+	profile, err := h.service.GetDashboard(c.Request.Context(), userUUID)
+	if err != nil {
+		slog.Error("failed to get author dashboard", slog.String("err", err.Error()))
+		c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
+		return
+	}
+
+	response, err := h.profileDTO(c.Request.Context(), profile)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.AuthorDashboardDTO{
+		AuthorProfileDTO: response,
+		Drafts:           profile.Stats.Drafts,
+		Pending:          profile.Stats.Pending,
+	})
+}
+
+// GetOwnSamples
+// @Summary Мои семплы
+// @Description Все семплы текущего автора, включая черновики, семплы на модерации и снятые с продажи. У черновика без аудио пустой listen_url
+// @Tags authors
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "Размер страницы, по умолчанию 20, максимум 100"
+// @Param offset query int false "Сколько пропустить"
+// @Success 200 {object} dto.AuthorSamplesResponse
+// @Failure 400 {object} dto.ApiError
+// @Failure 401 {object} dto.ApiError
+// @Failure 500 {object} dto.ApiError
+// @Router /authors/me/samples [get]
+func (h *Handler) GetOwnSamples(c *gin.Context) {
+	page, userUUID, ok := h.pageRequest(c)
+	if !ok {
+		return
+	}
+
+	samples, total, err := h.service.GetOwnSamples(c.Request.Context(), userUUID, page)
+	h.writeSamples(c, userUUID, samples, total, err)
+}
+
+// GetOwnPacks
+// @Summary Мои паки
+// @Tags authors
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "Размер страницы, по умолчанию 20, максимум 100"
+// @Param offset query int false "Сколько пропустить"
+// @Success 200 {object} dto.AuthorPacksResponse
+// @Failure 400 {object} dto.ApiError
+// @Failure 401 {object} dto.ApiError
+// @Failure 500 {object} dto.ApiError
+// @Router /authors/me/packs [get]
+func (h *Handler) GetOwnPacks(c *gin.Context) {
+	page, userUUID, ok := h.pageRequest(c)
+	if !ok {
+		return
+	}
+
+	packs, total, err := h.service.GetOwnPacks(c.Request.Context(), userUUID, page)
+	h.writePacks(c, packs, total, err)
+}
+
+// pageRequest разбирает пагинацию и текущего пользователя, false - ответ уже записан
+func (h *Handler) pageRequest(c *gin.Context) (entity.Page, uuid.UUID, bool) {
+	userUUID, err := uuid.Parse(c.GetString(constant.CtxUserUUID))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, dto.NewApiError("unauthorized"))
+		return entity.Page{}, uuid.Nil, false
+	}
+
+	var query dto.PageQuery
+	if err = c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewApiError("некорректные параметры страницы"))
+		return entity.Page{}, uuid.Nil, false
+	}
+
+	return query.ToEntity(), userUUID, true
+}
+
+func (h *Handler) writeSamples(c *gin.Context, userUUID uuid.UUID, samples []entity.Sample, total int, err error) {
+	if errors.Is(err, domain.ErrNotFound) {
+		c.JSON(http.StatusNotFound, dto.NewApiError("автор не найден"))
+		return
+	}
+	if err != nil {
+		slog.Error("failed to get author samples", slog.String("err", err.Error()))
+		c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
+		return
+	}
+
+	response, err := h.samples.SampleDTOs(c.Request.Context(), userUUID, samples)
+	if err != nil {
+		slog.Error("failed to build sample urls", slog.String("err", err.Error()))
+		c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.AuthorSamplesResponse{Samples: response, Total: total})
+}
+
+func (h *Handler) writePacks(c *gin.Context, packs []entity.Pack, total int, err error) {
+	if errors.Is(err, domain.ErrNotFound) {
+		c.JSON(http.StatusNotFound, dto.NewApiError("автор не найден"))
+		return
+	}
+	if err != nil {
+		slog.Error("failed to get author packs", slog.String("err", err.Error()))
+		c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
+		return
+	}
+
+	response, err := h.samples.PackDTOs(c.Request.Context(), packs)
+	if err != nil {
+		slog.Error("failed to build pack covers", slog.String("err", err.Error()))
+		c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.AuthorPacksResponse{Packs: response, Total: total})
+}
+
+func (h *Handler) profileDTO(ctx context.Context, profile entity.AuthorProfile) (dto.AuthorProfileDTO, error) {
+	avatar, err := h.avatars.AvatarURLs(ctx, profile.User.AvatarKey)
+	if err != nil {
+		return dto.AuthorProfileDTO{}, err
+	}
+
+	return dto.ToAuthorProfileDTO(profile, avatar), nil
+}
@@ -0,0 +1,343 @@
+package collection
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/musicman-backend/internal/domain"
+	"github.com/musicman-backend/internal/domain/constant"
+	"github.com/musicman-backend/internal/domain/entity"
+	"github.com/musicman-backend/internal/http/dto"
+)
+
+type Service interface {
+	Create(ctx context.Context, userUUID uuid.UUID, name, description string, isPublic bool) (entity.Collection, error)
+	GetOwn(ctx context.Context, userUUID uuid.UUID) ([]entity.Collection, error)
+	GetPublic(ctx context.Context, login string) ([]entity.Collection, error)
+	Get(ctx context.Context, userUUID, id uuid.UUID) (entity.Collection, []entity.Sample, error)
+	Update(ctx context.Context, userUUID, id uuid.UUID, name, description *string, isPublic *bool) (entity.Collection, error)
+	Delete(ctx context.Context, userUUID, id uuid.UUID) error
+	AddSample(ctx context.Context, userUUID, id, sampleID uuid.UUID) error
+	RemoveSample(ctx context.Context, userUUID, id, sampleID uuid.UUID) error
+	Reorder(ctx context.Context, userUUID, id uuid.UUID, sampleIDs []uuid.UUID) error
+}
+
+// SampleConverter собирает семплы со ссылками, доступными пользователю
+type SampleConverter interface {
+	SampleDTOs(ctx context.Context, userUUID uuid.UUID, samples []entity.Sample) ([]dto.SampleDTO, error)
+}
+
+type Handler struct {
+	service Service
+	samples SampleConverter
+}
+
+func New(service Service, samples SampleConverter) *Handler {
+	return &Handler{
+		service: service,
+		samples: samples,
+	}
+}
+
+// GetCollections
+// @Summary Мои подборки
+// @Tags collections
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} dto.CollectionDTO
+// @Failure 401 {object} dto.ApiError
+// @Failure 500 {object} dto.ApiError
+// @Router /collections [get]
+func (h *Handler) GetCollections(c *gin.Context) {
+	userUUID, err := uuid.Parse(c.GetString(constant.CtxUserUUID))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, dto.NewApiError("unauthorized"))
+		return
+	}
+
+	collections, err := h.service.GetOwn(c.Request.Context(), userUUID)
+	if err != nil {
+		slog.Error("failed to get collections", slog.String("err", err.Error()))
+		c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToCollectionDTOs(collections))
+}
+
+// GetAuthorCollections
+// @Summary Публичные подборки пользователя
+// @Tags collections
+// @Produce json
+// @Security BearerAuth
+// @Param login path string true "Логин"
+// @Success 200 {array} dto.CollectionDTO
+// @Failure 404 {object} dto.ApiError
+// @Failure 500 {object} dto.ApiError
+// @Router /authors/{login}/collections [get]
+func (h *Handler) GetAuthorCollections(c *gin.Context) {
+	collections, err := h.service.GetPublic(c.Request.Context(), c.Param("login"))
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		c.JSON(http.StatusNotFound, dto.NewApiError("пользователь не найден"))
+	case err != nil:
+		slog.Error("failed to get collections", slog.String("err", err.Error()))
+		c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
+	default:
+		c.JSON(http.StatusOK, dto.ToCollectionDTOs(collections))
+	}
+}
+
+// CreateCollection
+// @Summary Создать подборку
+// @Tags collections
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.CreateCollectionRequest true "Подборка"
+// @Success 201 {object} dto.CollectionDTO
+// @Failure 400 {object} dto.ApiError
+// @Failure 500 {object} dto.ApiError
+// @Router /collections [post]
+func (h *Handler) CreateCollection(c *gin.Context) {
+	userUUID, err := uuid.Parse(c.GetString(constant.CtxUserUUID))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, dto.NewApiError("unauthorized"))
+		return
+	}
+
+	var req dto.CreateCollectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewApiError("некорекктное тело запроса"))
+		return
+	}
+
+	collection, err := h.service.Create(c.Request.Context(), userUUID, req.Name, req.Description, req.IsPublic)
+	if h.handleError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.ToCollectionDTO(collection))
+}
+
+// GetCollection
+// @Summary Подборка с семплами
+// @Description Свою подборку видно всегда, чужую - только если она публичная
+// @Tags collections
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Collection ID"
+// @Success 200 {object} dto.CollectionWithSamplesResponse
+// @Failure 400 {object} dto.ApiError
+// @Failure 404 {object} dto.ApiError
+// @Failure 500 {object} dto.ApiError
+// @Router /collections/{id} [get]
+func (h *Handler) GetCollection(c *gin.Context) {
+	userUUID, id, ok := h.params(c)
+	if !ok {
+		return
+	}
+
+	collection, samples, err := h.service.Get(c.Request.Context(), userUUID, id)
+	if h.handleError(c, err) {
+		return
+	}
+
+	sampleDTOs, err := h.samples.SampleDTOs(c.Request.Context(), userUUID, samples)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.CollectionWithSamplesResponse{
+		CollectionDTO: dto.ToCollectionDTO(collection),
+		Samples:       sampleDTOs,
+	})
+}
+
+// UpdateCollection
+// @Summary Изменить подборку
+// @Tags collections
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Collection ID"
+// @Param request body dto.UpdateCollectionRequest true "Изменения"
+// @Success 200 {object} dto.CollectionDTO
+// @Failure 400 {object} dto.ApiError
+// @Failure 404 {object} dto.ApiError
+// @Failure 500 {object} dto.ApiError
+// @Router /collections/{id} [patch]
+func (h *Handler) UpdateCollection(c *gin.Context) {
+	userUUID, id, ok := h.params(c)
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateCollectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewApiError("некорекктное тело запроса"))
+		return
+	}
+
+	collection, err := h.service.Update(c.Request.Context(), userUUID, id, req.Name, req.Description, req.IsPublic)
+	if h.handleError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToCollectionDTO(collection))
+}
+
+// DeleteCollection
+// @Summary Удалить подборку
+// @Tags collections
+// @Security BearerAuth
+// @Param id path string true "Collection ID"
+// @Success 204
+// @Failure 400 {object} dto.ApiError
+// @Failure 404 {object} dto.ApiError
+// @Failure 500 {object} dto.ApiError
+// @Router /collections/{id} [delete]
+func (h *Handler) DeleteCollection(c *gin.Context) {
+	userUUID, id, ok := h.params(c)
+	if !ok {
+		return
+	}
+
+	if h.handleError(c, h.service.Delete(c.Request.Context(), userUUID, id)) {
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// AddSample
+// @Summary Добавить семпл в подборку
+// @Description Семпл добавляется в конец, повторное добавление ничего не меняет
+// @Tags collections
+// @Accept json
+// @Security BearerAuth
+// @Param id path string true "Collection ID"
+// @Param request body dto.AddCollectionSampleRequest true "Семпл"
+// @Success 204
+// @Failure 400 {object} dto.ApiError
+// @Failure 404 {object} dto.ApiError
+// @Failure 500 {object} dto.ApiError
+// @Router /collections/{id}/samples [post]
+func (h *Handler) AddSample(c *gin.Context) {
+	userUUID, id, ok := h.params(c)
+	if !ok {
+		return
+	}
+
+	var req dto.AddCollectionSampleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewApiError("некорекктное тело запроса"))
+		return
+	}
+
+	if h.handleError(c, h.service.AddSample(c.Request.Context(), userUUID, id, req.SampleID)) {
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RemoveSample
+// @Summary Убрать семпл из подборки
+// @Tags collections
+// @Security BearerAuth
+// @Param id path string true "Collection ID"
+// @Param sample_id path string true "Sample ID"
+// @Success 204
+// @Failure 400 {object} dto.ApiError
+// @Failure 404 {object} dto.ApiError
+// @Failure 500 {object} dto.ApiError
+// @Router /collections/{id}/samples/{sample_id} [delete]
+func (h *Handler) RemoveSample(c *gin.Context) {
+	userUUID, id, ok := h.params(c)
+	if !ok {
+		return
+	}
+
+	sampleID, err := uuid.Parse(c.Param("sample_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewApiError(err.Error()))
+		return
+	}
+
+	if h.handleError(c, h.service.RemoveSample(c.Request.Context(), userUUID, id, sampleID)) {
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ReorderSamples
+// @Summary Изменить порядок семплов в подборке
+// @Description Передаются все семплы подборки в новом порядке
+// @Tags collections
+// @Accept json
+// @Security BearerAuth
+// @Param id path string true "Collection ID"
+// @Param request body dto.ReorderCollectionRequest true "Новый порядок"
+// @Success 204
+// @Failure 400 {object} dto.ApiError
+// @Failure 404 {object} dto.ApiError
+// @Failure 500 {object} dto.ApiError
+// @Router /collections/{id}/samples [put]
+func (h *Handler) ReorderSamples(c *gin.Context) {
+	userUUID, id, ok := h.params(c)
+	if !ok {
+		return
+	}
+
+	var req dto.ReorderCollectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewApiError("некорекктное тело запроса"))
+		return
+	}
+
+	if h.handleError(c, h.service.Reorder(c.Request.Context(), userUUID, id, req.SampleIDs)) {
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) params(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	userUUID, err := uuid.Parse(c.GetString(constant.CtxUserUUID))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, dto.NewApiError("unauthorized"))
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewApiError(err.Error()))
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return userUUID, id, true
+}
+
+func (h *Handler) handleError(c *gin.Context, err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, domain.ErrInvalidCollection):
+		c.JSON(http.StatusBadRequest, dto.NewApiError(err.Error()))
+	case errors.Is(err, domain.ErrNotFound):
+		c.JSON(http.StatusNotFound, dto.NewApiError("подборка не найдена"))
+	default:
+		slog.Error("collection request failed", slog.String("err", err.Error()))
+		c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
+	}
+
+	return true
+}
@@ -0,0 +1,279 @@
+package earnings
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/musicman-backend/internal/domain"
+	"github.com/musicman-backend/internal/domain/constant"
+	"github.com/musicman-backend/internal/domain/entity"
+	"github.com/musicman-backend/internal/http/dto"
+)
+
+type Service interface {
+	GetEarnings(ctx context.Context, userUUID uuid.UUID) (entity.EarningsSummary, error)
+	RequestPayout(ctx context.Context, userUUID uuid.UUID, amount int) (entity.Payout, error)
+	GetPayouts(ctx context.Context, userUUID uuid.UUID) ([]entity.Payout, error)
+	ListPayouts(ctx context.Context, status string) ([]entity.Payout, error)
+	ApprovePayout(ctx context.Context, adminUUID, id uuid.UUID) (entity.Payout, error)
+	RejectPayout(ctx context.Context, adminUUID, id uuid.UUID, reason string) (entity.Payout, error)
+	SetCommission(ctx context.Context, authorUUID uuid.UUID, percent *int) error
+}
+
+type Handler struct {
+	service Service
+}
+
+func New(service Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+// GetEarnings
+// @Summary Заработок автора
+// @Description Баланс в токенах и разбивка по семплам. Доля автора начисляется с каждой покупки за вычетом комиссии площадки
+// @Tags earnings
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.EarningsResponse
+// @Failure 401 {object} dto.ApiError
+// @Failure 500 {object} dto.ApiError
+// @Router /profile/earnings [get]
+func (h *Handler) GetEarnings(c *gin.Context) {
+	userUUID, err := uuid.Parse(c.GetString(constant.CtxUserUUID))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, dto.NewApiError("unauthorized"))
+		return
+	}
+
+	summary, err := h.service.GetEarnings(c.Request.Context(), userUUID)
+	if err != nil {
+		slog.Error("failed to get earnings", slog.String("err", err.Error()))
+		c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToEarningsResponse(summary))
+}
+
+// GetPayouts
+// @Summary Мои заявки на вывод
+// @Tags earnings
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} dto.PayoutDTO
+// @Failure 401 {object} dto.ApiError
+// @Failure 500 {object} dto.ApiError
+// @Router /profile/payouts [get]
+func (h *Handler) GetPayouts(c *gin.Context) {
+	userUUID, err := uuid.Parse(c.GetString(constant.CtxUserUUID))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, dto.NewApiError("unauthorized"))
+		return
+	}
+
+	payouts, err := h.service.GetPayouts(c.Request.Context(), userUUID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToPayoutDTOs(payouts))
+}
+
+// RequestPayout
+// @Summary Заявка на вывод заработка
+// @Description Сумма резервируется на балансе до решения администратора. При включенной 2FA нужен код в заголовке X-2FA-Code
+// @Tags earnings
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.PayoutRequest true "Сумма в токенах"
+// @Success 201 {object} dto.PayoutDTO
+// @Failure 400 {object} dto.ApiError "Сумма меньше минимальной"
+// @Failure 401 {object} dto.ApiError
+// @Failure 403 {object} dto.ApiError "Нужен код 2FA"
+// @Failure 409 {object} dto.ApiError "Недостаточно средств"
+// @Failure 429 {object} dto.ApiError "Слишком много неверных кодов 2FA, повторить через Retry-After секунд"
+// @Failure 500 {object} dto.ApiError
+// @Router /profile/payouts [post]
+func (h *Handler) RequestPayout(c *gin.Context) {
+	userUUID, err := uuid.Parse(c.GetString(constant.CtxUserUUID))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, dto.NewApiError("unauthorized"))
+		return
+	}
+
+	var req dto.PayoutRequest
+	if err = c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewApiError("некорекктное тело запроса"))
+		return
+	}
+
+	payout, err := h.service.RequestPayout(c.Request.Context(), userUUID, req.Amount)
+	switch {
+	case errors.Is(err, domain.ErrInvalidPayout):
+		c.JSON(http.StatusBadRequest, dto.NewApiError(err.Error()))
+	case errors.Is(err, domain.ErrInsufficientEarnings):
+		c.JSON(http.StatusConflict, dto.NewApiError("недостаточно средств для вывода"))
+	case err != nil:
+		slog.Error("failed to request payout", slog.String("err", err.Error()))
+		c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
+	default:
+		c.JSON(http.StatusCreated, dto.ToPayoutDTO(payout))
+	}
+}
+
+// ListPayouts
+// @Summary Заявки на вывод всех авторов
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "Фильтр по статусу" Enums(pending, approved, paid, rejected, failed)
+// @Success 200 {array} dto.PayoutDTO
+// @Failure 403 {object} dto.ApiError
+// @Failure 500 {object} dto.ApiError
+// @Router /admin/payouts [get]
+func (h *Handler) ListPayouts(c *gin.Context) {
+	payouts, err := h.service.ListPayouts(c.Request.Context(), c.Query("status"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToPayoutDTOs(payouts))
+}
+
+// ApprovePayout
+// @Summary Одобрить заявку на вывод
+// @Description Одобренная заявка сразу уходит на выплату. Если выплата не прошла, заявка получает статус failed и сумма возвращается на баланс автора
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID заявки"
+// @Success 200 {object} dto.PayoutDTO
+// @Failure 400 {object} dto.ApiError
+// @Failure 403 {object} dto.ApiError
+// @Failure 404 {object} dto.ApiError
+// @Failure 409 {object} dto.ApiError "Заявка уже рассмотрена"
+// @Failure 500 {object} dto.ApiError
+// @Router /admin/payouts/{id}/approve [post]
+func (h *Handler) ApprovePayout(c *gin.Context) {
+	adminUUID, id, ok := h.reviewRequest(c)
+	if !ok {
+		return
+	}
+
+	payout, err := h.service.ApprovePayout(c.Request.Context(), adminUUID, id)
+	h.writeReview(c, payout, err)
+}
+
+// RejectPayout
+// @Summary Отклонить заявку на вывод
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID заявки"
+// @Param request body dto.RejectPayoutRequest true "Причина отказа"
+// @Success 200 {object} dto.PayoutDTO
+// @Failure 400 {object} dto.ApiError
+// @Failure 403 {object} dto.ApiError
+// @Failure 404 {object} dto.ApiError
+// @Failure 409 {object} dto.ApiError "Заявка уже рассмотрена"
+// @Failure 500 {object} dto.ApiError
+// @Router /admin/payouts/{id}/reject [post]
+func (h *Handler) RejectPayout(c *gin.Context) {
+	adminUUID, id, ok := h.reviewRequest(c)
+	if !ok {
+		return
+	}
+
+	var req dto.RejectPayoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewApiError("некорекктное тело запроса"))
+		return
+	}
+
+	payout, err := h.service.RejectPayout(c.Request.Context(), adminUUID, id, req.Reason)
+	h.writeReview(c, payout, err)
+}
+
+// SetCommission
+// @Summary Комиссия площадки для автора
+// @Description Задает автору свой процент комиссии, null - комиссия по умолчанию. Действует на следующие покупки
+// @Tags admin
+// @Accept json
+// @Security BearerAuth
+// @Param uuid path string true "UUID автора"
+// @Param request body dto.CommissionRequest true "Комиссия в процентах"
+// @Success 204
+// @Failure 400 {object} dto.ApiError
+// @Failure 403 {object} dto.ApiError
+// @Failure 404 {object} dto.ApiError
+// @Failure 500 {object} dto.ApiError
+// @Router /admin/authors/{uuid}/commission [put]
+func (h *Handler) SetCommission(c *gin.Context) {
+	authorUUID, err := uuid.Parse(c.Param("uuid"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewApiError(err.Error()))
+		return
+	}
+
+	var req dto.CommissionRequest
+	if err = c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewApiError("некорекктное тело запроса"))
+		return
+	}
+
+	err = h.service.SetCommission(c.Request.Context(), authorUUID, req.CommissionPercent)
+	switch {
+	case errors.Is(err, domain.ErrInvalidCommission):
+		c.JSON(http.StatusBadRequest, dto.NewApiError(err.Error()))
+	case errors.Is(err, domain.ErrNotFound):
+		c.JSON(http.StatusNotFound, dto.NewApiError("автор не найден"))
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
+	default:
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// reviewRequest разбирает админа и заявку из запроса, false - ответ уже записан
+func (h *Handler) reviewRequest(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	adminUUID, err := uuid.Parse(c.GetString(constant.CtxUserUUID))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, dto.NewApiError("unauthorized"))
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewApiError(err.Error()))
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return adminUUID, id, true
+}
+
+func (h *Handler) writeReview(c *gin.Context, payout entity.Payout, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidPayout):
+		c.JSON(http.StatusBadRequest, dto.NewApiError(err.Error()))
+	case errors.Is(err, domain.ErrNotFound):
+		c.JSON(http.StatusNotFound, dto.NewApiError("заявка не найдена"))
+	case errors.Is(err, domain.ErrPayoutProcessed):
+		c.JSON(http.StatusConflict, dto.NewApiError("заявка уже рассмотрена"))
+	case err != nil:
+		slog.Error("failed to review payout", slog.String("err", err.Error()))
+		c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
+	default:
+		c.JSON(http.StatusOK, dto.ToPayoutDTO(payout))
+	}
+}
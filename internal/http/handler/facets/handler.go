@@ -0,0 +1,60 @@
+package facets
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/musicman-backend/internal/domain/entity"
+	"github.com/musicman-backend/internal/http/dto"
+)
+
+type Service interface {
+	Facets(ctx context.Context, filter entity.SampleFilter) (entity.Facets, error)
+}
+
+type Handler struct {
+	service Service
+}
+
+func New(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// GetFacets
+// @Summary Счетчики для фильтров каталога
+// @Description Сколько семплов каталога в каждом жанре, у каждого автора, в каждом паке, корзине цены и длительности при уже выбранных фильтрах. Параметры те же, что у списка семплов. Результат кэшируется на несколько секунд
+// @Tags samples
+// @Produce json
+// @Security BearerAuth
+// @Param genre query string false "Slug жанра, включая поджанры"
+// @Param tag query string false "Slug свободного тега"
+// @Param instrument query string false "Slug инструмента"
+// @Param mood query string false "Slug настроения"
+// @Param author query string false "UUID автора"
+// @Param pack_id query string false "ID пака"
+// @Param price query string false "Корзина цены" Enums(free, under-100, 100-500, over-500)
+// @Param duration query string false "Корзина длительности" Enums(under-5s, 5-15s, 15-30s, over-30s)
+// @Success 200 {object} dto.FacetsResponse
+// @Failure 400 {object} dto.ApiError
+// @Failure 401 {object} dto.ApiError
+// @Failure 500 {object} dto.ApiError
+// @Router /samples/facets [get]
+func (h *Handler) GetFacets(c *gin.Context) {
+	var query dto.SampleListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewApiError(err.Error()))
+		return
+	}
+
+	facets, err := h.service.Facets(c.Request.Context(), query.ToEntity())
+	if err != nil {
+		slog.Error("failed to get facets", slog.String("err", err.Error()))
+		c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToFacetsResponse(facets))
+}
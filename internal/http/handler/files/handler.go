@@ -0,0 +1,53 @@
+package files
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/musicman-backend/internal/domain"
+	"github.com/musicman-backend/internal/http/dto"
+)
+
+// SignedFileResolver - хранилище, которое само раздает файлы по подписанным ссылкам
+type SignedFileResolver interface {
+	ResolveSignedFile(bucketName, objectName, expires, signature string) (string, error)
+}
+
+type Handler struct {
+	resolver SignedFileResolver
+}
+
+func New(resolver SignedFileResolver) *Handler {
+	return &Handler{
+		resolver: resolver,
+	}
+}
+
+// GetFile отдает файл локального хранилища по ссылке из GetFileURL.
+// Живет вне /api/v1, как и /health, поэтому в swagger не описан
+func (h *Handler) GetFile(c *gin.Context) {
+	bucket := c.Param("bucket")
+	key := strings.TrimPrefix(c.Param("key"), "/")
+
+	path, err := h.resolver.ResolveSignedFile(bucket, key, c.Query("expires"), c.Query("signature"))
+	switch {
+	case errors.Is(err, domain.ErrInvalidSignature):
+		c.JSON(http.StatusForbidden, dto.NewApiError(err.Error()))
+		return
+	case errors.Is(err, domain.ErrLinkExpired):
+		c.JSON(http.StatusGone, dto.NewApiError(err.Error()))
+		return
+	case errors.Is(err, domain.ErrNotFound):
+		c.JSON(http.StatusNotFound, dto.NewApiError(err.Error()))
+		return
+	case err != nil:
+		slog.Error("failed to resolve signed file", slog.String("err", err.Error()))
+		c.JSON(http.StatusInternalServerError, dto.NewApiError("failed to get file"))
+		return
+	}
+
+	c.File(path)
+}
@@ -0,0 +1,161 @@
+package likes
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/musicman-backend/internal/domain"
+	"github.com/musicman-backend/internal/domain/constant"
+	"github.com/musicman-backend/internal/domain/entity"
+	"github.com/musicman-backend/internal/http/dto"
+)
+
+type Service interface {
+	LikeSample(ctx context.Context, userUUID, sampleID uuid.UUID) error
+	UnlikeSample(ctx context.Context, userUUID, sampleID uuid.UUID) error
+	LikePack(ctx context.Context, userUUID, packID uuid.UUID) error
+	UnlikePack(ctx context.Context, userUUID, packID uuid.UUID) error
+	GetLiked(ctx context.Context, userUUID uuid.UUID) ([]entity.Sample, []entity.Pack, error)
+}
+
+// SampleConverter собирает семплы со ссылками, доступными пользователю, и паки с обложками
+type SampleConverter interface {
+	SampleDTOs(ctx context.Context, userUUID uuid.UUID, samples []entity.Sample) ([]dto.SampleDTO, error)
+	PackDTOs(ctx context.Context, packs []entity.Pack) ([]dto.PackDTO, error)
+}
+
+type Handler struct {
+	service Service
+	samples SampleConverter
+}
+
+func New(service Service, samples SampleConverter) *Handler {
+	return &Handler{
+		service: service,
+		samples: samples,
+	}
+}
+
+// LikeSample
+// @Summary Лайкнуть семпл
+// @Description Повторный лайк ничего не меняет
+// @Tags likes
+// @Security BearerAuth
+// @Param id path string true "Sample ID"
+// @Success 204
+// @Failure 400 {object} dto.ApiError
+// @Failure 404 {object} dto.ApiError
+// @Failure 500 {object} dto.ApiError
+// @Router /samples/{id}/like [post]
+func (h *Handler) LikeSample(c *gin.Context) {
+	h.handle(c, h.service.LikeSample)
+}
+
+// UnlikeSample
+// @Summary Убрать лайк с семпла
+// @Tags likes
+// @Security BearerAuth
+// @Param id path string true "Sample ID"
+// @Success 204
+// @Failure 400 {object} dto.ApiError
+// @Failure 500 {object} dto.ApiError
+// @Router /samples/{id}/like [delete]
+func (h *Handler) UnlikeSample(c *gin.Context) {
+	h.handle(c, h.service.UnlikeSample)
+}
+
+// LikePack
+// @Summary Лайкнуть пак
+// @Description Повторный лайк ничего не меняет
+// @Tags likes
+// @Security BearerAuth
+// @Param id path string true "Pack ID"
+// @Success 204
+// @Failure 400 {object} dto.ApiError
+// @Failure 404 {object} dto.ApiError
+// @Failure 500 {object} dto.ApiError
+// @Router /packs/{id}/like [post]
+func (h *Handler) LikePack(c *gin.Context) {
+	h.handle(c, h.service.LikePack)
+}
+
+// UnlikePack
+// @Summary Убрать лайк с пака
+// @Tags likes
+// @Security BearerAuth
+// @Param id path string true "Pack ID"
+// @Success 204
+// @Failure 400 {object} dto.ApiError
+// @Failure 500 {object} dto.ApiError
+// @Router /packs/{id}/like [delete]
+func (h *Handler) UnlikePack(c *gin.Context) {
+	h.handle(c, h.service.UnlikePack)
+}
+
+// GetLiked
+// @Summary Лайкнутые семплы и паки
+// @Tags likes
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.LikesResponse
+// @Failure 401 {object} dto.ApiError
+// @Failure 500 {object} dto.ApiError
+// @Router /profile/likes [get]
+func (h *Handler) GetLiked(c *gin.Context) {
+	userUUID, err := uuid.Parse(c.GetString(constant.CtxUserUUID))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, dto.NewApiError("unauthorized"))
+		return
+	}
+
+	samples, packs, err := h.service.GetLiked(c.Request.Context(), userUUID)
+	if err != nil {
+		slog.Error("failed to get likes", slog.String("err", err.Error()))
+		c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
+		return
+	}
+
+	sampleDTOs, err := h.samples.SampleDTOs(c.Request.Context(), userUUID, samples)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
+		return
+	}
+
+	packDTOs, err := h.samples.PackDTOs(c.Request.Context(), packs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.LikesResponse{Samples: sampleDTOs, Packs: packDTOs})
+}
+
+func (h *Handler) handle(c *gin.Context, action func(ctx context.Context, userUUID, id uuid.UUID) error) {
+	userUUID, err := uuid.Parse(c.GetString(constant.CtxUserUUID))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, dto.NewApiError("unauthorized"))
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewApiError(err.Error()))
+		return
+	}
+
+	err = action(c.Request.Context(), userUUID, id)
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		c.JSON(http.StatusNotFound, dto.NewApiError(err.Error()))
+	case err != nil:
+		slog.Error("failed to update like", slog.String("err", err.Error()))
+		c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
+	default:
+		c.Status(http.StatusNoContent)
+	}
+}
@@ -8,7 +8,10 @@ import (
 	"log/slog"
 	"mime/multipart"
 	"net/http"
+	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -16,37 +19,75 @@ import (
 	"github.com/musicman-backend/internal/domain/constant"
 	"github.com/musicman-backend/internal/domain/entity"
 	"github.com/musicman-backend/internal/http/dto"
+	"github.com/musicman-backend/internal/http/upload"
 )
 
+// maxCoverSize - предел размера загружаемой обложки
+const maxCoverSize = 10 << 20
+
 type PurchaseChecker interface {
-	IsPurchased(ctx context.Context, userUUID, sampleID uuid.UUID) (bool, error)
+	PurchasedKey(ctx context.Context, userUUID uuid.UUID, sample entity.Sample) (string, bool, error)
+}
+
+// LikeChecker отмечает семплы, которые лайкнул пользователь
+type LikeChecker interface {
+	LikedSamples(ctx context.Context, userUUID uuid.UUID, samples []entity.Sample) (map[uuid.UUID]bool, error)
+}
+
+// EventRecorder пишет прослушивания и скачивания в статистику, не блокируя запрос
+type EventRecorder interface {
+	RecordPlay(sampleID, userUUID uuid.UUID)
+	RecordDownload(sampleID, userUUID uuid.UUID)
 }
 
 type Service interface {
-	GetSamples(ctx context.Context) ([]entity.Sample, error)
+	GetSamples(ctx context.Context, filter entity.SampleFilter) ([]entity.Sample, error)
 	GetSampleDownloadURL(ctx context.Context, minioKey string) (string, error)
 	GetSample(ctx context.Context, sampleID uuid.UUID) (entity.Sample, error)
-	CreateSample(ctx context.Context, author, title, description, genre string, packID *uuid.UUID, price int) (uuid.UUID, error)
-	UploadAudio(ctx context.Context, audioFilePath string, sampleID uuid.UUID) error
-	UpdateSample(ctx context.Context, id uuid.UUID, packID *uuid.UUID, title, author, description, genre *string, price *int, size *int64, duration *float64) (entity.Sample, error)
-	DeleteSample(ctx context.Context, id uuid.UUID) error
+	CreateSample(ctx context.Context, authorUUID uuid.UUID, title, description, genre string, packID *uuid.UUID, price int, tags entity.TagSet) (uuid.UUID, error)
+	UploadAudio(ctx context.Context, userUUID, sampleID uuid.UUID, audioFilePath, filename string, size int64, duration float64) (entity.Sample, error)
+	UpdateSample(ctx context.Context, userUUID, id uuid.UUID, title, description, genre *string, tags *entity.TagSet) (entity.Sample, error)
+	SetLicenses(ctx context.Context, userUUID, sampleID uuid.UUID, licenses []entity.SampleLicense) (entity.Sample, error)
+	DeleteSample(ctx context.Context, userUUID, id uuid.UUID) error
+	RestoreSample(ctx context.Context, userUUID, id uuid.UUID) error
+	GetTrash(ctx context.Context, userUUID uuid.UUID) ([]entity.Sample, []entity.Pack, error)
+	GetSampleVersions(ctx context.Context, userUUID, sampleID uuid.UUID) ([]entity.SampleVersion, error)
+	RollbackSample(ctx context.Context, userUUID, sampleID uuid.UUID, version int) (entity.Sample, error)
+	SubmitSample(ctx context.Context, userUUID, id uuid.UUID, publishAt *time.Time) (entity.Sample, error)
+	GetPendingSamples(ctx context.Context) ([]entity.Sample, error)
+	ApproveSample(ctx context.Context, adminUUID, id uuid.UUID) (entity.Sample, error)
+	RejectSample(ctx context.Context, adminUUID, id uuid.UUID, reason string) (entity.Sample, error)
 
 	GetAllPacks(ctx context.Context) ([]entity.Pack, error)
 	GetPack(ctx context.Context, id uuid.UUID) (entity.Pack, error)
-	CreatePack(ctx context.Context, name, description, genre, author string) (uuid.UUID, error)
-	UpdatePack(ctx context.Context, id uuid.UUID, name, description, genre *string) error
-	DeletePack(ctx context.Context, id uuid.UUID) error
+	GetPackWithSamples(ctx context.Context, id uuid.UUID) (entity.Pack, []entity.Sample, error)
+	CreatePack(ctx context.Context, authorUUID uuid.UUID, name, description, genre string, tags entity.TagSet) (uuid.UUID, error)
+	UpdatePack(ctx context.Context, userUUID, id uuid.UUID, name, description, genre *string, tags *entity.TagSet) error
+	SetPackCover(ctx context.Context, userUUID, id uuid.UUID, filePath string) (entity.Pack, error)
+	RemovePackCover(ctx context.Context, userUUID, id uuid.UUID) error
+	PackCoverURLs(ctx context.Context, pack entity.Pack) (entity.ImageURLs, error)
+	DeletePack(ctx context.Context, userUUID, id uuid.UUID) error
+	RestorePack(ctx context.Context, userUUID, id uuid.UUID) error
+	AddPackSample(ctx context.Context, userUUID, id, sampleID uuid.UUID) error
+	RemovePackSample(ctx context.Context, userUUID, id, sampleID uuid.UUID) error
+	ReorderPack(ctx context.Context, userUUID, id uuid.UUID, sampleIDs []uuid.UUID) error
 }
 
 type Handler struct {
 	service         Service
 	purchaseChecker PurchaseChecker // может быть nil, если нет авторизации
+	likes           LikeChecker
+	events          EventRecorder
+	tempDir         string // куда сохранять загружаемые файлы, пусто - системный temp
 }
 
-func New(service Service, purchaseChecker PurchaseChecker) *Handler {
+func New(service Service, purchaseChecker PurchaseChecker, likes LikeChecker, events EventRecorder, tempDir string) *Handler {
 	return &Handler{
 		service:         service,
 		purchaseChecker: purchaseChecker,
+		likes:           likes,
+		events:          events,
+		tempDir:         tempDir,
 	}
 }
 
@@ -55,12 +96,28 @@ func New(service Service, purchaseChecker PurchaseChecker) *Handler {
 // @Tags samples
 // @Produce json
 // @Security BearerAuth
+// @Param sort query string false "Сортировка: new (по умолчанию) или popular - по количеству лайков"
+// @Param genre query string false "Slug жанра, включая поджанры"
+// @Param tag query string false "Slug свободного тега"
+// @Param instrument query string false "Slug инструмента"
+// @Param mood query string false "Slug настроения"
+// @Param author query string false "UUID автора"
+// @Param pack_id query string false "ID пака"
+// @Param price query string false "Корзина цены" Enums(free, under-100, 100-500, over-500)
+// @Param duration query string false "Корзина длительности" Enums(under-5s, 5-15s, 15-30s, over-30s)
 // @Success 200 {array} dto.SampleDTO
+// @Success 400 {object} dto.ApiError
 // @Success 404 {object} dto.ApiError
 // @Success 500 {object} dto.ApiError
 // @Router /samples [get]
 func (h *Handler) GetSamples(c *gin.Context) {
-	samples, err := h.service.GetSamples(c.Request.Context())
+	var query dto.SampleListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewApiError(err.Error()))
+		return
+	}
+
+	samples, err := h.service.GetSamples(c.Request.Context(), query.ToEntity())
 	if errors.Is(err, domain.ErrNotFound) {
 		c.JSON(http.StatusNotFound, dto.NewApiError(err.Error()))
 		return
@@ -79,32 +136,57 @@ func (h *Handler) GetSamples(c *gin.Context) {
 		return
 	}
 
+	response, err := h.SampleDTOs(c.Request.Context(), userUUID, samples)
+	if err != nil {
+		slog.Error(err.Error())
+		c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// SampleDTOs собирает семплы для ответа со ссылками на прослушивание и скачивание для userUUID
+func (h *Handler) SampleDTOs(ctx context.Context, userUUID uuid.UUID, samples []entity.Sample) ([]dto.SampleDTO, error) {
 	response := make([]dto.SampleDTO, len(samples))
 	for i, sample := range samples {
-		listenURL, err := h.service.GetSampleDownloadURL(c.Request.Context(), sample.MinioKey)
+		listenURL, err := h.service.GetSampleDownloadURL(ctx, sample.MinioKey)
 		if err != nil {
-			slog.Error(err.Error())
-			c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
-			return
+			return nil, err
 		}
 
-		var downloadURL string
-		isPurchased, err := h.purchaseChecker.IsPurchased(c.Request.Context(), userUUID, sample.ID)
+		downloadURL, err := h.downloadURL(ctx, userUUID, sample, listenURL)
 		if err != nil {
-			slog.Error(err.Error())
-			c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
-			return
+			return nil, err
 		}
 
-		if sample.Price == 0 || isPurchased {
-			// Бесплатный семпл - всегда доступен для скачивания
-			downloadURL = listenURL
+		response[i] = dto.ToSampleDTO(sample, listenURL, downloadURL)
+	}
+
+	liked, err := h.likes.LikedSamples(ctx, userUUID, samples)
+	if err != nil {
+		return nil, err
+	}
+	for i := range response {
+		response[i].IsLiked = liked[response[i].ID]
+	}
+
+	return response, nil
+}
+
+// PackDTOs собирает паки со ссылками на обложки
+func (h *Handler) PackDTOs(ctx context.Context, packs []entity.Pack) ([]dto.PackDTO, error) {
+	response := make([]dto.PackDTO, len(packs))
+	for i, pack := range packs {
+		cover, err := h.service.PackCoverURLs(ctx, pack)
+		if err != nil {
+			return nil, err
 		}
 
-		response[i] = dto.ToSampleDTO(sample, listenURL, downloadURL)
+		response[i] = dto.ToPackDTO(pack, cover)
 	}
 
-	c.JSON(http.StatusOK, response)
+	return response, nil
 }
 
 // GetSample godoc
@@ -148,25 +230,152 @@ func (h *Handler) GetSample(c *gin.Context) {
 		return
 	}
 
-	var downloadURL string
+	downloadURL, err := h.downloadURL(c.Request.Context(), userUUID, sample, listenURL)
+	if err != nil {
+		slog.Error(err.Error())
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	visible, err := h.visible(c.Request.Context(), userUUID, sample)
+	if err != nil {
+		slog.Error(err.Error())
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	if !visible {
+		c.JSON(http.StatusNotFound, dto.NewApiError(domain.ErrNotFound.Error()))
+		return
+	}
+
+	// автор, слушающий свой семпл, статистику не накручивает
+	if sample.MinioKey != "" && sample.AuthorUUID != userUUID {
+		h.events.RecordPlay(sample.ID, userUUID)
+	}
 
-	isPurchased, err := h.purchaseChecker.IsPurchased(c.Request.Context(), userUUID, sample.ID)
+	liked, err := h.likes.LikedSamples(c.Request.Context(), userUUID, []entity.Sample{sample})
 	if err != nil {
 		slog.Error(err.Error())
 		c.AbortWithStatus(http.StatusInternalServerError)
 		return
 	}
 
-	if sample.Price == 0 || isPurchased {
+	response := dto.ToSampleDTO(sample, listenURL, downloadURL)
+	response.IsLiked = liked[sample.ID]
+	c.JSON(http.StatusOK, response)
+}
+
+// DownloadSample godoc
+// @Summary Скачивание семпла
+// @Description Перенаправляет на файл аудио и учитывает скачивание в статистике. Бесплатный семпл доступен всем, платный - только купленная версия
+// @Tags samples
+// @Security BearerAuth
+// @Param id path string true "Sample ID"
+// @Success 302 "Redirect на файл"
+// @Failure 400 {object} dto.ApiError
+// @Failure 403 {object} dto.ApiError "Семпл не куплен"
+// @Failure 404 {object} dto.ApiError "Семпл не найден или аудио еще не загружено"
+// @Failure 500 {object} dto.ApiError
+// @Router /samples/{id}/download [get]
+func (h *Handler) DownloadSample(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewApiError(err.Error()))
+		return
+	}
+
+	userUUID, err := uuid.Parse(c.GetString(constant.CtxUserUUID))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, dto.NewApiError("unauthorized"))
+		return
+	}
+
+	sample, err := h.service.GetSample(c.Request.Context(), id)
+	if errors.Is(err, domain.ErrNotFound) {
+		c.JSON(http.StatusNotFound, dto.NewApiError(err.Error()))
+		return
+	}
+	if err != nil {
+		slog.Error("failed to get sample", slog.String("err", err.Error()))
+		c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
+		return
+	}
+
+	visible, err := h.visible(c.Request.Context(), userUUID, sample)
+	if err != nil {
+		slog.Error("failed to check sample visibility", slog.String("err", err.Error()))
+		c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
+		return
+	}
+	if !visible || sample.MinioKey == "" {
+		c.JSON(http.StatusNotFound, dto.NewApiError(domain.ErrNotFound.Error()))
+		return
+	}
+
+	listenURL, err := h.service.GetSampleDownloadURL(c.Request.Context(), sample.MinioKey)
+	if err != nil {
+		slog.Error("failed to get sample url", slog.String("err", err.Error()))
+		c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
+		return
+	}
+
+	downloadURL, err := h.downloadURL(c.Request.Context(), userUUID, sample, listenURL)
+	if err != nil {
+		slog.Error("failed to get download url", slog.String("err", err.Error()))
+		c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
+		return
+	}
+	if downloadURL == "" {
+		c.JSON(http.StatusForbidden, dto.NewApiError("семпл не куплен"))
+		return
+	}
+
+	if sample.AuthorUUID != userUUID {
+		h.events.RecordDownload(sample.ID, userUUID)
+	}
+
+	c.Redirect(http.StatusFound, downloadURL)
+}
+
+// visible - неопубликованный и проданный эксклюзивно семпл видят только автор и те, кто его купил,
+// удаленный - только купившие
+func (h *Handler) visible(ctx context.Context, userUUID uuid.UUID, sample entity.Sample) (bool, error) {
+	if sample.DeletedAt == nil && (sample.Public(time.Now()) || sample.AuthorUUID == userUUID) {
+		return true, nil
+	}
+
+	_, isPurchased, err := h.purchaseChecker.PurchasedKey(ctx, userUUID, sample)
+	if err != nil {
+		return false, err
+	}
+
+	return isPurchased, nil
+}
+
+// downloadURL - ссылка на скачивание: для бесплатного семпла всегда, для платного - только купленной версии
+func (h *Handler) downloadURL(ctx context.Context, userUUID uuid.UUID, sample entity.Sample, listenURL string) (string, error) {
+	if sample.Price == 0 {
 		// Бесплатный семпл - всегда доступен для скачивания
-		downloadURL = listenURL
+		return listenURL, nil
 	}
 
-	c.JSON(http.StatusOK, dto.ToSampleDTO(sample, listenURL, downloadURL))
+	key, isPurchased, err := h.purchaseChecker.PurchasedKey(ctx, userUUID, sample)
+	if err != nil {
+		return "", err
+	}
+	if !isPurchased {
+		return "", nil
+	}
+	if key == sample.MinioKey {
+		return listenURL, nil
+	}
+
+	return h.service.GetSampleDownloadURL(ctx, key)
 }
 
 // UploadAudio godoc
-// @Summary Загрузка .wav аудио файла для созданного семпла
+// @Summary Загрузка .wav аудио файла для созданного семпла (только автор или админ)
+// @Description Каждая загрузка создает новую версию аудио и делает ее текущей. Покупатели сохраняют доступ к купленной версии. Опубликованный семпл с новым аудио возвращается на модерацию
 // @Tags samples
 // @Accept multipart/form-data
 // @Produce json
@@ -175,6 +384,7 @@ func (h *Handler) GetSample(c *gin.Context) {
 // @Param id path string true "Sample ID"
 // @Success 201 {object} dto.DownloadURLResponse
 // @Success 400 {object} dto.ApiError
+// @Success 403 {object} dto.ApiError
 // @Success 404 {object} dto.ApiError
 // @Success 500 {object} dto.ApiError
 // @Router /samples/{id} [post]
@@ -185,6 +395,12 @@ func (h *Handler) UploadAudio(c *gin.Context) {
 		return
 	}
 
+	userUUID, err := uuid.Parse(c.GetString(constant.CtxUserUUID))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, dto.NewApiError("unauthorized"))
+		return
+	}
+
 	file, err := c.FormFile("file")
 	if err != nil {
 		c.JSON(http.StatusBadRequest, dto.NewApiError(err.Error()))
@@ -201,12 +417,6 @@ func (h *Handler) UploadAudio(c *gin.Context) {
 		return
 	}
 
-	filePath := "/tmp/" + file.Filename
-	if err := c.SaveUploadedFile(file, filePath); err != nil {
-		c.JSON(http.StatusInternalServerError, dto.NewApiError("failed to save file"))
-		return
-	}
-
 	sample, err := h.service.GetSample(c.Request.Context(), id)
 	if errors.Is(err, domain.ErrNotFound) {
 		c.JSON(http.StatusNotFound, dto.NewApiError(err.Error()))
@@ -217,10 +427,18 @@ func (h *Handler) UploadAudio(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.UploadAudio(c.Request.Context(), filePath, sample.ID); err != nil {
-		c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
+	filePath, err := upload.SaveTempFile(file, h.tempDir)
+	if err != nil {
+		slog.Error("failed to save uploaded file", slog.String("err", err.Error()))
+		c.JSON(http.StatusInternalServerError, dto.NewApiError("failed to save file"))
 		return
 	}
+	defer func() {
+		if err := os.Remove(filePath); err != nil {
+			slog.Warn("failed to remove temp file", slog.String("path", filePath), slog.String("err", err.Error()))
+		}
+	}()
+
 	// Открываем файл для получения duration
 	fileReader, err := file.Open()
 	if err != nil {
@@ -235,10 +453,8 @@ func (h *Handler) UploadAudio(c *gin.Context) {
 		return
 	}
 
-	sizeMB := file.Size
-
-	if _, err := h.service.UpdateSample(c.Request.Context(), id, nil, nil, nil, nil, nil, nil, &sizeMB, &duration); err != nil {
-		c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
+	sample, err = h.service.UploadAudio(c.Request.Context(), userUUID, sample.ID, filePath, file.Filename, file.Size, duration)
+	if h.handleManageError(c, err) {
 		return
 	}
 
@@ -340,6 +556,7 @@ func getWAVDuration(file multipart.File) (float64, error) {
 
 // CreateSample godoc
 // @Summary Создает новый семпл (аудио загружается для созданного семпла через UploadAudio эндпоинт по ID семпла)
+// @Description Автором семпла становится текущий пользователь
 // @Tags samples
 // @Accept application/json
 // @Produce json
@@ -356,9 +573,15 @@ func (h *Handler) CreateSample(c *gin.Context) {
 		return
 	}
 
-	id, err := h.service.CreateSample(c.Request.Context(), sampleDto.Author, sampleDto.Title, sampleDto.Description, sampleDto.Genre, sampleDto.PackID, sampleDto.Price)
+	userUUID, err := uuid.Parse(c.GetString(constant.CtxUserUUID))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
+		slog.Error(err.Error())
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	id, err := h.service.CreateSample(c.Request.Context(), userUUID, sampleDto.Title, sampleDto.Description, sampleDto.Genre, sampleDto.PackID, sampleDto.Price, sampleDto.ToEntity())
+	if h.handleManageError(c, err) {
 		return
 	}
 
@@ -366,14 +589,17 @@ func (h *Handler) CreateSample(c *gin.Context) {
 }
 
 // UpdateSample godoc
-// @Summary Обновляет семпл
+// @Summary Обновляет семпл (только автор или админ)
 // @Tags samples
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Param id path string true "Sample ID"
 // @Param request body dto.UpdateSampleRequest true "Update data"
 // @Success 200 {object} dto.SampleDTO
 // @Success 400 {object} dto.ApiError
+// @Success 403 {object} dto.ApiError
+// @Success 404 {object} dto.ApiError
 // @Success 500 {object} dto.ApiError
 // @Router /samples/{id} [put]
 func (h *Handler) UpdateSample(c *gin.Context) {
@@ -389,9 +615,15 @@ func (h *Handler) UpdateSample(c *gin.Context) {
 		return
 	}
 
-	sample, err := h.service.UpdateSample(c.Request.Context(), id, req.PackID, req.Title, req.Author, req.Description, req.Genre, req.Price, nil, nil)
+	userUUID, err := uuid.Parse(c.GetString(constant.CtxUserUUID))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
+		slog.Error(err.Error())
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	sample, err := h.service.UpdateSample(c.Request.Context(), userUUID, id, req.Title, req.Description, req.Genre, req.ToUpdate())
+	if h.handleManageError(c, err) {
 		return
 	}
 
@@ -404,170 +636,592 @@ func (h *Handler) UpdateSample(c *gin.Context) {
 	c.JSON(http.StatusOK, dto.ToSampleDTO(sample, listenURL, listenURL))
 }
 
-// DeleteSample godoc
-// @Summary Удаляет семпл
+// SetLicenses godoc
+// @Summary Задает лицензии семпла (только автор или админ)
+// @Description Заменяет список лицензий целиком: personal, commercial, exclusive, у каждой своя цена в токенах. Пустой список делает семпл бесплатным. Цена семпла становится минимальной ценой лицензии
 // @Tags samples
+// @Accept json
+// @Produce json
 // @Security BearerAuth
 // @Param id path string true "Sample ID"
-// @Success 204
+// @Param request body dto.SetLicensesRequest true "Лицензии"
 // @Success 200 {object} dto.SampleDTO
 // @Success 400 {object} dto.ApiError
+// @Success 403 {object} dto.ApiError
+// @Success 404 {object} dto.ApiError
+// @Success 409 {object} dto.ApiError "Семпл продан эксклюзивно"
 // @Success 500 {object} dto.ApiError
-// @Router /samples/{id} [delete]
-func (h *Handler) DeleteSample(c *gin.Context) {
+// @Router /samples/{id}/licenses [put]
+func (h *Handler) SetLicenses(c *gin.Context) {
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, dto.NewApiError(err.Error()))
 		return
 	}
 
-	if err := h.service.DeleteSample(c.Request.Context(), id); err != nil {
-		c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
+	var req dto.SetLicensesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewApiError(err.Error()))
 		return
 	}
 
-	c.Status(http.StatusNoContent)
-}
+	userUUID, err := uuid.Parse(c.GetString(constant.CtxUserUUID))
+	if err != nil {
+		slog.Error(err.Error())
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
 
-// GetPacks godoc
-// @Summary Получение всех паков (без семплов)
-// @Tags packs
-// @Produce json
-// @Security BearerAuth
-// @Success 200 {array} dto.PackDTO
-// @Success 404 {object} dto.ApiError
-// @Success 500 {object} dto.ApiError
-// @Router /packs [get]
-func (h *Handler) GetPacks(c *gin.Context) {
-	packs, err := h.service.GetAllPacks(c.Request.Context())
-	if errors.Is(err, domain.ErrNotFound) {
-		c.JSON(http.StatusNotFound, dto.NewApiError(err.Error()))
+	sample, err := h.service.SetLicenses(c.Request.Context(), userUUID, id, req.ToEntity())
+	switch {
+	case errors.Is(err, domain.ErrInvalidLicense):
+		c.JSON(http.StatusBadRequest, dto.NewApiError(err.Error()))
+		return
+	case errors.Is(err, domain.ErrSampleUnavailable):
+		c.JSON(http.StatusConflict, dto.NewApiError("семпл продан эксклюзивно"))
 		return
 	}
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
+	if h.handleManageError(c, err) {
 		return
 	}
 
-	response := make([]dto.PackDTO, len(packs))
-	for i, pack := range packs {
-		response[i] = dto.ToPackDTO(pack)
+	listenURL, err := h.service.GetSampleDownloadURL(c.Request.Context(), sample.MinioKey)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
+		return
 	}
 
-	c.JSON(http.StatusOK, response)
+	c.JSON(http.StatusOK, dto.ToSampleDTO(sample, listenURL, listenURL))
 }
 
-// GetPack godoc
-// @Summary Получает пак вместе с семплами (но без аудио содержимого)
-// @Tags packs
+// SubmitSample godoc
+// @Summary Отправить семпл на модерацию (только автор или админ)
+// @Description Отправить можно черновик с загруженным аудио. После одобрения семпл появится в каталоге сразу или в publish_at, если он задан. Отклоненный семпл возвращается в черновики с причиной отказа
+// @Tags samples
+// @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param id path string true "Pack ID"
-// @Success 200 {object} dto.PackWithSamplesResponse
+// @Param id path string true "Sample ID"
+// @Param request body dto.SubmitSampleRequest false "Отложенная публикация"
+// @Success 200 {object} dto.SampleDTO
 // @Success 400 {object} dto.ApiError
+// @Success 403 {object} dto.ApiError
 // @Success 404 {object} dto.ApiError
+// @Success 409 {object} dto.ApiError "Семпл не черновик или без аудио"
 // @Success 500 {object} dto.ApiError
-// @Router /packs/{id} [get]
-func (h *Handler) GetPack(c *gin.Context) {
+// @Router /samples/{id}/submit [post]
+func (h *Handler) SubmitSample(c *gin.Context) {
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, dto.NewApiError(err.Error()))
 		return
 	}
 
-	pack, err := h.service.GetPack(c.Request.Context(), id)
-	if errors.Is(err, domain.ErrNotFound) {
-		c.JSON(http.StatusNotFound, dto.NewApiError(err.Error()))
-		return
+	var req dto.SubmitSampleRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, dto.NewApiError(err.Error()))
+			return
+		}
 	}
+
+	userUUID, err := uuid.Parse(c.GetString(constant.CtxUserUUID))
 	if err != nil {
-		c.JSON(http.StatusNotFound, dto.NewApiError(err.Error()))
+		c.JSON(http.StatusUnauthorized, dto.NewApiError("unauthorized"))
 		return
 	}
 
-	samples, err := h.service.GetSamples(c.Request.Context())
-	if errors.Is(err, domain.ErrNotFound) {
-		c.JSON(http.StatusNotFound, dto.NewApiError(err.Error()))
+	sample, err := h.service.SubmitSample(c.Request.Context(), userUUID, id, req.PublishAt)
+	if h.handleManageError(c, err) {
 		return
 	}
+
+	h.writeSample(c, sample)
+}
+
+// GetPendingSamples godoc
+// @Summary Очередь модерации семплов
+// @Description Семплы, отправленные на модерацию, раньше отправленные первыми
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} dto.SampleDTO
+// @Failure 403 {object} dto.ApiError
+// @Failure 500 {object} dto.ApiError
+// @Router /admin/samples/pending [get]
+func (h *Handler) GetPendingSamples(c *gin.Context) {
+	adminUUID, err := uuid.Parse(c.GetString(constant.CtxUserUUID))
 	if err != nil {
-		c.JSON(http.StatusNotFound, dto.NewApiError(err.Error()))
+		c.JSON(http.StatusUnauthorized, dto.NewApiError("unauthorized"))
 		return
 	}
 
-	// Получить userUUID из контекста (если есть)
-	userUUIDStr := c.GetString(constant.CtxUserUUID)
-	var userUUID *uuid.UUID
-	if userUUIDStr != "" {
-		parsed, err := uuid.Parse(userUUIDStr)
-		if err == nil {
-			userUUID = &parsed
-		}
+	samples, err := h.service.GetPendingSamples(c.Request.Context())
+	if err != nil {
+		slog.Error(err.Error())
+		c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
+		return
 	}
 
-	packSamples := make([]dto.SampleDTO, 0)
-	for _, sample := range samples {
-		if sample.PackID == nil {
-			continue
-		}
-		if *sample.PackID != pack.ID {
-			continue
-		}
-
-		listenURL, err := h.service.GetSampleDownloadURL(c.Request.Context(), sample.MinioKey)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
-			return
-		}
+	response, err := h.SampleDTOs(c.Request.Context(), adminUUID, samples)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
+		return
+	}
 
-		// Определить downloadURL: только если куплен или бесплатный
-		var downloadURL string
-		if sample.Price == 0 {
-			// Бесплатный семпл - всегда доступен для скачивания
-			downloadURL = listenURL
-		} else if userUUID != nil && h.purchaseChecker != nil {
-			// Проверить покупку
-			isPurchased, err := h.purchaseChecker.IsPurchased(c.Request.Context(), *userUUID, sample.ID)
-			if err == nil && isPurchased {
-				downloadURL = listenURL
-			}
-		}
+	c.JSON(http.StatusOK, response)
+}
 
-		packSamples = append(packSamples, dto.ToSampleDTO(sample, listenURL, downloadURL))
+// ApproveSample godoc
+// @Summary Одобрить семпл
+// @Description Семпл публикуется сразу или в publish_at, если автор задал отложенную публикацию
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Sample ID"
+// @Success 200 {object} dto.SampleDTO
+// @Failure 400 {object} dto.ApiError
+// @Failure 403 {object} dto.ApiError
+// @Failure 404 {object} dto.ApiError
+// @Failure 409 {object} dto.ApiError "Семпл не на модерации"
+// @Failure 500 {object} dto.ApiError
+// @Router /admin/samples/{id}/approve [post]
+func (h *Handler) ApproveSample(c *gin.Context) {
+	adminUUID, id, ok := h.reviewRequest(c)
+	if !ok {
+		return
 	}
 
-	response := dto.PackWithSamplesResponse{
-		PackDTO: dto.ToPackDTO(pack),
-		Samples: packSamples,
+	sample, err := h.service.ApproveSample(c.Request.Context(), adminUUID, id)
+	if h.handleManageError(c, err) {
+		return
 	}
 
-	c.JSON(http.StatusOK, response)
+	h.writeSample(c, sample)
 }
 
-// CreatePack godoc
-// @Summary Создает пак семплов (без семплов)
-// @Tags packs
+// RejectSample godoc
+// @Summary Отклонить семпл
+// @Description Семпл возвращается автору в черновики, причина отказа видна автору
+// @Tags admin
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param request body dto.CreatePackRequest true "Pack data"
-// @Success 201 {object} dto.UUIDResponse
-// @Success 400 {object} dto.ApiError
-// @Success 500 {object} dto.ApiError
-// @Router /packs [post]
-func (h *Handler) CreatePack(c *gin.Context) {
+// @Param id path string true "Sample ID"
+// @Param request body dto.RejectSampleRequest true "Причина отказа"
+// @Success 200 {object} dto.SampleDTO
+// @Failure 400 {object} dto.ApiError
+// @Failure 403 {object} dto.ApiError
+// @Failure 404 {object} dto.ApiError
+// @Failure 409 {object} dto.ApiError "Семпл не на модерации"
+// @Failure 500 {object} dto.ApiError
+// @Router /admin/samples/{id}/reject [post]
+func (h *Handler) RejectSample(c *gin.Context) {
+	adminUUID, id, ok := h.reviewRequest(c)
+	if !ok {
+		return
+	}
+
+	var req dto.RejectSampleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewApiError("некорекктное тело запроса"))
+		return
+	}
+
+	sample, err := h.service.RejectSample(c.Request.Context(), adminUUID, id, req.Reason)
+	if h.handleManageError(c, err) {
+		return
+	}
+
+	h.writeSample(c, sample)
+}
+
+// reviewRequest разбирает админа и семпл из запроса, false - ответ уже записан
+func (h *Handler) reviewRequest(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	adminUUID, err := uuid.Parse(c.GetString(constant.CtxUserUUID))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, dto.NewApiError("unauthorized"))
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewApiError(err.Error()))
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return adminUUID, id, true
+}
+
+// writeSample отвечает семплом со ссылкой на прослушивание, как после изменения автором
+func (h *Handler) writeSample(c *gin.Context, sample entity.Sample) {
+	listenURL, err := h.service.GetSampleDownloadURL(c.Request.Context(), sample.MinioKey)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToSampleDTO(sample, listenURL, listenURL))
+}
+
+// DeleteSample godoc
+// @Summary Перемещает семпл в корзину (только автор или админ)
+// @Description Семпл можно восстановить, пока не истек срок хранения корзины. Покупатели сохраняют доступ к купленному семплу.
+// @Tags samples
+// @Security BearerAuth
+// @Param id path string true "Sample ID"
+// @Success 204
+// @Success 400 {object} dto.ApiError
+// @Success 403 {object} dto.ApiError
+// @Success 404 {object} dto.ApiError
+// @Success 500 {object} dto.ApiError
+// @Router /samples/{id} [delete]
+func (h *Handler) DeleteSample(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewApiError(err.Error()))
+		return
+	}
+
+	userUUID, err := uuid.Parse(c.GetString(constant.CtxUserUUID))
+	if err != nil {
+		slog.Error(err.Error())
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	err = h.service.DeleteSample(c.Request.Context(), userUUID, id)
+	if h.handleTrashError(c, err) {
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RestoreSample godoc
+// @Summary Восстанавливает семпл из корзины (только автор или админ)
+// @Tags samples
+// @Security BearerAuth
+// @Param id path string true "Sample ID"
+// @Success 204
+// @Success 400 {object} dto.ApiError
+// @Success 403 {object} dto.ApiError
+// @Success 404 {object} dto.ApiError
+// @Success 410 {object} dto.ApiError "Срок хранения в корзине истек"
+// @Success 500 {object} dto.ApiError
+// @Router /samples/{id}/restore [post]
+func (h *Handler) RestoreSample(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewApiError(err.Error()))
+		return
+	}
+
+	userUUID, err := uuid.Parse(c.GetString(constant.CtxUserUUID))
+	if err != nil {
+		slog.Error(err.Error())
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	err = h.service.RestoreSample(c.Request.Context(), userUUID, id)
+	if h.handleTrashError(c, err) {
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetTrash godoc
+// @Summary Содержимое корзины
+// @Description Автор видит свои удаленные семплы и паки, админ - все
+// @Tags trash
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.TrashResponse
+// @Success 500 {object} dto.ApiError
+// @Router /trash [get]
+func (h *Handler) GetTrash(c *gin.Context) {
+	userUUID, err := uuid.Parse(c.GetString(constant.CtxUserUUID))
+	if err != nil {
+		slog.Error(err.Error())
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	samples, packs, err := h.service.GetTrash(c.Request.Context(), userUUID)
+	if err != nil {
+		slog.Error(err.Error())
+		c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
+		return
+	}
+
+	response := dto.TrashResponse{
+		Samples: make([]dto.SampleDTO, len(samples)),
+	}
+	for i, sample := range samples {
+		listenURL, err := h.service.GetSampleDownloadURL(c.Request.Context(), sample.MinioKey)
+		if err != nil {
+			slog.Error(err.Error())
+			c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
+			return
+		}
+		response.Samples[i] = dto.ToSampleDTO(sample, listenURL, listenURL)
+	}
+	response.Packs, err = h.PackDTOs(c.Request.Context(), packs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// handleTrashError отвечает клиенту на ошибку удаления/восстановления, возвращает true если ответ уже отправлен
+func (h *Handler) handleTrashError(c *gin.Context, err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, domain.ErrNotFound):
+		c.JSON(http.StatusNotFound, dto.NewApiError(err.Error()))
+	case errors.Is(err, domain.ErrForbidden):
+		c.JSON(http.StatusForbidden, dto.NewApiError("удалять и восстанавливать может только автор"))
+	case errors.Is(err, domain.ErrRestoreExpired):
+		c.JSON(http.StatusGone, dto.NewApiError("срок хранения в корзине истек"))
+	default:
+		slog.Error(err.Error())
+		c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
+	}
+
+	return true
+}
+
+// handleManageError отвечает на ошибку изменения семпла или пака, true - ответ уже записан
+func (h *Handler) handleManageError(c *gin.Context, err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, domain.ErrNotFound):
+		c.JSON(http.StatusNotFound, dto.NewApiError(err.Error()))
+	case errors.Is(err, domain.ErrForbidden):
+		c.JSON(http.StatusForbidden, dto.NewApiError("изменять может только автор"))
+	case errors.Is(err, domain.ErrInvalidGenre), errors.Is(err, domain.ErrInvalidTag), errors.Is(err, domain.ErrInvalidImage),
+		errors.Is(err, domain.ErrInvalidPack), errors.Is(err, domain.ErrInvalidReview), errors.Is(err, domain.ErrInvalidLicense):
+		c.JSON(http.StatusBadRequest, dto.NewApiError(err.Error()))
+	case errors.Is(err, domain.ErrSampleStatus):
+		c.JSON(http.StatusConflict, dto.NewApiError(err.Error()))
+	default:
+		c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
+	}
+
+	return true
+}
+
+// GetSampleVersions godoc
+// @Summary Получение всех загруженных версий аудио семпла (только автор или админ)
+// @Tags samples
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Sample ID"
+// @Success 200 {array} dto.SampleVersionDTO
+// @Success 400 {object} dto.ApiError
+// @Success 403 {object} dto.ApiError
+// @Success 404 {object} dto.ApiError
+// @Success 500 {object} dto.ApiError
+// @Router /samples/{id}/versions [get]
+func (h *Handler) GetSampleVersions(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewApiError(err.Error()))
+		return
+	}
+
+	userUUID, err := uuid.Parse(c.GetString(constant.CtxUserUUID))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, dto.NewApiError("unauthorized"))
+		return
+	}
+
+	versions, err := h.service.GetSampleVersions(c.Request.Context(), userUUID, id)
+	if h.handleManageError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToSampleVersionDTOs(versions))
+}
+
+// RollbackSample godoc
+// @Summary Откат аудио семпла на одну из предыдущих версий (только автор или админ)
+// @Description Опубликованный семпл с другим аудио возвращается на модерацию
+// @Tags samples
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Sample ID"
+// @Param version path int true "Номер версии"
+// @Success 200 {object} dto.SampleDTO
+// @Success 400 {object} dto.ApiError
+// @Success 403 {object} dto.ApiError
+// @Success 404 {object} dto.ApiError
+// @Success 500 {object} dto.ApiError
+// @Router /samples/{id}/versions/{version}/rollback [post]
+func (h *Handler) RollbackSample(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewApiError(err.Error()))
+		return
+	}
+
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewApiError(err.Error()))
+		return
+	}
+
+	userUUID, err := uuid.Parse(c.GetString(constant.CtxUserUUID))
+	if err != nil {
+		slog.Error(err.Error())
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	sample, err := h.service.RollbackSample(c.Request.Context(), userUUID, id, version)
+	if h.handleManageError(c, err) {
+		return
+	}
+
+	listenURL, err := h.service.GetSampleDownloadURL(c.Request.Context(), sample.MinioKey)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToSampleDTO(sample, listenURL, listenURL))
+}
+
+// GetPacks godoc
+// @Summary Получение всех паков (без семплов)
+// @Tags packs
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} dto.PackDTO
+// @Success 404 {object} dto.ApiError
+// @Success 500 {object} dto.ApiError
+// @Router /packs [get]
+func (h *Handler) GetPacks(c *gin.Context) {
+	packs, err := h.service.GetAllPacks(c.Request.Context())
+	if errors.Is(err, domain.ErrNotFound) {
+		c.JSON(http.StatusNotFound, dto.NewApiError(err.Error()))
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
+		return
+	}
+
+	response, err := h.PackDTOs(c.Request.Context(), packs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetPack godoc
+// @Summary Получает пак вместе с семплами (но без аудио содержимого)
+// @Description Семплы идут в порядке треков, номер трека - поле track
+// @Tags packs
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Pack ID"
+// @Success 200 {object} dto.PackWithSamplesResponse
+// @Success 400 {object} dto.ApiError
+// @Success 404 {object} dto.ApiError
+// @Success 500 {object} dto.ApiError
+// @Router /packs/{id} [get]
+func (h *Handler) GetPack(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewApiError(err.Error()))
+		return
+	}
+
+	userUUID, err := uuid.Parse(c.GetString(constant.CtxUserUUID))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, dto.NewApiError("unauthorized"))
+		return
+	}
+
+	pack, samples, err := h.service.GetPackWithSamples(c.Request.Context(), id)
+	if errors.Is(err, domain.ErrNotFound) {
+		c.JSON(http.StatusNotFound, dto.NewApiError(err.Error()))
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
+		return
+	}
+
+	packSamples, err := h.SampleDTOs(c.Request.Context(), userUUID, samples)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
+		return
+	}
+	for i := range packSamples {
+		packSamples[i].Track = i + 1
+	}
+
+	cover, err := h.service.PackCoverURLs(c.Request.Context(), pack)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
+		return
+	}
+
+	response := dto.PackWithSamplesResponse{
+		PackDTO: dto.ToPackDTO(pack, cover),
+		Samples: packSamples,
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// CreatePack godoc
+// @Summary Создает пак семплов (без семплов)
+// @Description Автором пака становится текущий пользователь
+// @Tags packs
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.CreatePackRequest true "Pack data"
+// @Success 201 {object} dto.UUIDResponse
+// @Success 400 {object} dto.ApiError
+// @Success 500 {object} dto.ApiError
+// @Router /packs [post]
+func (h *Handler) CreatePack(c *gin.Context) {
 	var req dto.CreatePackRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, dto.NewApiError(err.Error()))
 		return
 	}
 
+	userUUID, err := uuid.Parse(c.GetString(constant.CtxUserUUID))
+	if err != nil {
+		slog.Error(err.Error())
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
 	id, err := h.service.CreatePack(c.Request.Context(),
+		userUUID,
 		req.Name,
 		req.Description,
 		req.Genre,
-		req.Author,
+		req.ToEntity(),
 	)
+	if errors.Is(err, domain.ErrInvalidGenre) || errors.Is(err, domain.ErrInvalidTag) {
+		c.JSON(http.StatusBadRequest, dto.NewApiError(err.Error()))
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
 		return
@@ -577,7 +1231,7 @@ func (h *Handler) CreatePack(c *gin.Context) {
 }
 
 // UpdatePack godoc
-// @Summary Обновляет пак
+// @Summary Обновляет пак (только автор или админ)
 // @Tags packs
 // @Accept json
 // @Produce json
@@ -586,6 +1240,8 @@ func (h *Handler) CreatePack(c *gin.Context) {
 // @Param request body dto.UpdatePackRequest true "Update data"
 // @Success 204
 // @Success 400 {object} dto.ApiError
+// @Success 403 {object} dto.ApiError
+// @Success 404 {object} dto.ApiError
 // @Success 500 {object} dto.ApiError
 // @Router /packs/{id} [put]
 func (h *Handler) UpdatePack(c *gin.Context) {
@@ -600,20 +1256,126 @@ func (h *Handler) UpdatePack(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.UpdatePack(c.Request.Context(), id, req.Name, req.Description, req.Genre); err != nil {
+	userUUID, err := uuid.Parse(c.GetString(constant.CtxUserUUID))
+	if err != nil {
+		slog.Error(err.Error())
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	err = h.service.UpdatePack(c.Request.Context(), userUUID, id, req.Name, req.Description, req.Genre, req.ToUpdate())
+	if h.handleManageError(c, err) {
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// SetPackCover godoc
+// @Summary Загружает обложку пака (только автор или админ)
+// @Description png, jpeg, webp или gif до 10 МБ, каждая сторона от 480 до 4096 пикселей. Из центра вырезается квадрат, сохраняются миниатюры 160, 480 и 1000 пикселей, предыдущая обложка удаляется
+// @Tags packs
+// @Accept mpfd
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Pack ID"
+// @Param cover formData file true "Обложка"
+// @Success 200 {object} dto.PackDTO
+// @Success 400 {object} dto.ApiError
+// @Success 403 {object} dto.ApiError
+// @Success 404 {object} dto.ApiError
+// @Success 500 {object} dto.ApiError
+// @Router /packs/{id}/cover [put]
+func (h *Handler) SetPackCover(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewApiError(err.Error()))
+		return
+	}
+
+	userUUID, err := uuid.Parse(c.GetString(constant.CtxUserUUID))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, dto.NewApiError("unauthorized"))
+		return
+	}
+
+	file, err := c.FormFile("cover")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewApiError(err.Error()))
+		return
+	}
+	if file.Size > maxCoverSize {
+		c.JSON(http.StatusBadRequest, dto.NewApiError("обложка должна быть не больше 10 МБ"))
+		return
+	}
+
+	filePath, err := upload.SaveTempFile(file, h.tempDir)
+	if err != nil {
+		slog.Error("failed to save uploaded file", slog.String("err", err.Error()))
+		c.JSON(http.StatusInternalServerError, dto.NewApiError("failed to save file"))
+		return
+	}
+	defer func() {
+		if err := os.Remove(filePath); err != nil {
+			slog.Warn("failed to remove temp file", slog.String("path", filePath), slog.String("err", err.Error()))
+		}
+	}()
+
+	pack, err := h.service.SetPackCover(c.Request.Context(), userUUID, id, filePath)
+	if h.handleManageError(c, err) {
+		return
+	}
+
+	cover, err := h.service.PackCoverURLs(c.Request.Context(), pack)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
 		return
 	}
 
+	c.JSON(http.StatusOK, dto.ToPackDTO(pack, cover))
+}
+
+// RemovePackCover godoc
+// @Summary Удаляет обложку пака (только автор или админ)
+// @Tags packs
+// @Security BearerAuth
+// @Param id path string true "Pack ID"
+// @Success 204
+// @Success 400 {object} dto.ApiError
+// @Success 403 {object} dto.ApiError
+// @Success 404 {object} dto.ApiError
+// @Success 500 {object} dto.ApiError
+// @Router /packs/{id}/cover [delete]
+func (h *Handler) RemovePackCover(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewApiError(err.Error()))
+		return
+	}
+
+	userUUID, err := uuid.Parse(c.GetString(constant.CtxUserUUID))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, dto.NewApiError("unauthorized"))
+		return
+	}
+
+	err = h.service.RemovePackCover(c.Request.Context(), userUUID, id)
+	if h.handleManageError(c, err) {
+		return
+	}
+
 	c.Status(http.StatusNoContent)
 }
 
 // DeletePack godoc
-// @Summary Удаляет пак
+// @Summary Перемещает пак в корзину (только автор или админ)
+// @Description Семплы пака остаются в каталоге и в других паках
 // @Tags packs
 // @Security BearerAuth
 // @Param id path string true "Pack ID"
 // @Success 204
+// @Success 403 {object} dto.ApiError
+// @Success 404 {object} dto.ApiError
 // @Success 500 {object} dto.ApiError
 // @Router /packs/{id} [delete]
 func (h *Handler) DeletePack(c *gin.Context) {
@@ -623,8 +1385,170 @@ func (h *Handler) DeletePack(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.DeletePack(c.Request.Context(), id); err != nil {
-		c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
+	userUUID, err := uuid.Parse(c.GetString(constant.CtxUserUUID))
+	if err != nil {
+		slog.Error(err.Error())
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	err = h.service.DeletePack(c.Request.Context(), userUUID, id)
+	if h.handleTrashError(c, err) {
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RestorePack godoc
+// @Summary Восстанавливает пак из корзины вместе с семплами, удаленными вместе с ним
+// @Tags packs
+// @Security BearerAuth
+// @Param id path string true "Pack ID"
+// @Success 204
+// @Success 403 {object} dto.ApiError
+// @Success 404 {object} dto.ApiError
+// @Success 410 {object} dto.ApiError "Срок хранения в корзине истек"
+// @Success 500 {object} dto.ApiError
+// @Router /packs/{id}/restore [post]
+func (h *Handler) RestorePack(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewApiError(err.Error()))
+		return
+	}
+
+	userUUID, err := uuid.Parse(c.GetString(constant.CtxUserUUID))
+	if err != nil {
+		slog.Error(err.Error())
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	err = h.service.RestorePack(c.Request.Context(), userUUID, id)
+	if h.handleTrashError(c, err) {
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// AddPackSample godoc
+// @Summary Добавляет семпл в пак (только автор или админ)
+// @Description Семпл добавляется последним треком, повторное добавление ничего не меняет. Один семпл может входить в несколько паков
+// @Tags packs
+// @Accept json
+// @Security BearerAuth
+// @Param id path string true "Pack ID"
+// @Param request body dto.AddPackSampleRequest true "Семпл"
+// @Success 204
+// @Success 400 {object} dto.ApiError
+// @Success 403 {object} dto.ApiError
+// @Success 404 {object} dto.ApiError
+// @Success 500 {object} dto.ApiError
+// @Router /packs/{id}/samples [post]
+func (h *Handler) AddPackSample(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewApiError(err.Error()))
+		return
+	}
+
+	var req dto.AddPackSampleRequest
+	if err = c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewApiError(err.Error()))
+		return
+	}
+
+	userUUID, err := uuid.Parse(c.GetString(constant.CtxUserUUID))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, dto.NewApiError("unauthorized"))
+		return
+	}
+
+	err = h.service.AddPackSample(c.Request.Context(), userUUID, id, req.SampleID)
+	if h.handleManageError(c, err) {
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RemovePackSample godoc
+// @Summary Убирает семпл из пака (только автор или админ)
+// @Description Сам семпл остается в каталоге
+// @Tags packs
+// @Security BearerAuth
+// @Param id path string true "Pack ID"
+// @Param sample_id path string true "Sample ID"
+// @Success 204
+// @Success 400 {object} dto.ApiError
+// @Success 403 {object} dto.ApiError
+// @Success 404 {object} dto.ApiError
+// @Success 500 {object} dto.ApiError
+// @Router /packs/{id}/samples/{sample_id} [delete]
+func (h *Handler) RemovePackSample(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewApiError(err.Error()))
+		return
+	}
+
+	sampleID, err := uuid.Parse(c.Param("sample_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewApiError(err.Error()))
+		return
+	}
+
+	userUUID, err := uuid.Parse(c.GetString(constant.CtxUserUUID))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, dto.NewApiError("unauthorized"))
+		return
+	}
+
+	err = h.service.RemovePackSample(c.Request.Context(), userUUID, id, sampleID)
+	if h.handleManageError(c, err) {
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ReorderPackSamples godoc
+// @Summary Меняет порядок треков пака (только автор или админ)
+// @Description Передаются все семплы пака в новом порядке
+// @Tags packs
+// @Accept json
+// @Security BearerAuth
+// @Param id path string true "Pack ID"
+// @Param request body dto.ReorderPackRequest true "Новый порядок"
+// @Success 204
+// @Success 400 {object} dto.ApiError
+// @Success 403 {object} dto.ApiError
+// @Success 404 {object} dto.ApiError
+// @Success 500 {object} dto.ApiError
+// @Router /packs/{id}/samples [put]
+func (h *Handler) ReorderPackSamples(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewApiError(err.Error()))
+		return
+	}
+
+	var req dto.ReorderPackRequest
+	if err = c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewApiError(err.Error()))
+		return
+	}
+
+	userUUID, err := uuid.Parse(c.GetString(constant.CtxUserUUID))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, dto.NewApiError("unauthorized"))
+		return
+	}
+
+	err = h.service.ReorderPack(c.Request.Context(), userUUID, id, req.SampleIDs)
+	if h.handleManageError(c, err) {
 		return
 	}
 
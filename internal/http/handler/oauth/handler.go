@@ -0,0 +1,208 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/musicman-backend/internal/domain"
+	"github.com/musicman-backend/internal/domain/constant"
+	"github.com/musicman-backend/internal/domain/entity"
+	"github.com/musicman-backend/internal/http/dto"
+	"github.com/musicman-backend/internal/http/middleware"
+)
+
+type Service interface {
+	Providers() []string
+	StartLogin(ctx context.Context, provider string) (string, error)
+	StartLink(ctx context.Context, provider string, userUUID uuid.UUID) (string, error)
+	Callback(ctx context.Context, provider, code, state string, extra map[string]string, client entity.ClientInfo) (entity.LoginResult, error)
+	GetIdentities(ctx context.Context, userUUID uuid.UUID) ([]entity.UserIdentity, error)
+	Unlink(ctx context.Context, userUUID uuid.UUID, provider string) error
+}
+
+type Handler struct {
+	service Service
+}
+
+func New(service Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+// GetProviders
+// @Summary Провайдеры входа
+// @Description Возвращает провайдеров, через которых можно войти (vk, yandex, google)
+// @Tags oauth
+// @Produce json
+// @Success 200 {object} dto.OAuthProvidersResponse
+// @Router /auth/oauth/providers [get]
+func (h *Handler) GetProviders(c *gin.Context) {
+	c.JSON(http.StatusOK, dto.OAuthProvidersResponse{Providers: h.service.Providers()})
+}
+
+// StartLogin
+// @Summary Начать вход через провайдера
+// @Description Возвращает ссылку на страницу входа провайдера (authorization code + PKCE). После входа провайдер вернет пользователя на redirect_url фронтенда с code и state
+// @Tags oauth
+// @Produce json
+// @Param provider path string true "Провайдер"
+// @Success 200 {object} dto.OAuthURLResponse
+// @Failure 404 {object} dto.ApiError "Провайдер не настроен"
+// @Failure 500 {object} dto.ApiError
+// @Router /auth/oauth/{provider} [get]
+func (h *Handler) StartLogin(c *gin.Context) {
+	url, err := h.service.StartLogin(c.Request.Context(), c.Param("provider"))
+	if errors.Is(err, domain.ErrNotFound) {
+		c.JSON(http.StatusNotFound, dto.NewApiError("провайдер не найден"))
+		return
+	}
+	if err != nil {
+		slog.Error("failed to start oauth login", slog.String("err", err.Error()))
+		c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.OAuthURLResponse{URL: url})
+}
+
+// Callback
+// @Summary Завершить вход через провайдера
+// @Description Принимает code и state, которые провайдер передал фронтенду, и возвращает JWT (при включенной 2FA - challenge_token, как при входе по паролю). Новый пользователь создается автоматически, аккаунт с той же подтвержденной почтой привязывается
+// @Tags oauth
+// @Accept json
+// @Produce json
+// @Param provider path string true "Провайдер"
+// @Param request body dto.OAuthCallbackRequest true "code и state из редиректа"
+// @Success 200 {object} dto.LoginResponse
+// @Failure 400 {object} dto.ApiError "Неверный или просроченный state"
+// @Failure 404 {object} dto.ApiError "Провайдер не настроен"
+// @Failure 409 {object} dto.ApiError "Аккаунт провайдера привязан к другому пользователю"
+// @Failure 502 {object} dto.ApiError "Провайдер не ответил или отклонил код"
+// @Failure 500 {object} dto.ApiError
+// @Router /auth/oauth/{provider}/callback [post]
+func (h *Handler) Callback(c *gin.Context) {
+	var req dto.OAuthCallbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Code == "" || req.State == "" {
+		c.JSON(http.StatusBadRequest, dto.NewApiError("некорекктное тело запроса"))
+		return
+	}
+
+	var extra map[string]string
+	if req.DeviceID != "" {
+		extra = map[string]string{"device_id": req.DeviceID, "state": req.State}
+	}
+
+	result, err := h.service.Callback(c.Request.Context(), c.Param("provider"), req.Code, req.State, extra, middleware.ClientInfo(c, req.DeviceName))
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		c.JSON(http.StatusNotFound, dto.NewApiError("провайдер не найден"))
+	case errors.Is(err, domain.ErrInvalidToken):
+		c.JSON(http.StatusBadRequest, dto.NewApiError("вход устарел, начните заново"))
+	case errors.Is(err, domain.ErrIdentityAlreadyLinked):
+		c.JSON(http.StatusConflict, dto.NewApiError("аккаунт уже привязан к другому пользователю"))
+	case errors.Is(err, domain.ErrProviderFailed):
+		slog.Warn("oauth provider failed", slog.String("err", err.Error()))
+		c.JSON(http.StatusBadGateway, dto.NewApiError("не удалось войти через провайдера"))
+	case err != nil:
+		slog.Error("failed to finish oauth login", slog.String("err", err.Error()))
+		c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
+	default:
+		c.JSON(http.StatusOK, dto.NewLoginResponse(result))
+	}
+}
+
+// GetIdentities
+// @Summary Привязанные провайдеры
+// @Tags oauth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} dto.IdentityDTO
+// @Failure 401 {object} dto.ApiError
+// @Failure 500 {object} dto.ApiError
+// @Router /profile/identities [get]
+func (h *Handler) GetIdentities(c *gin.Context) {
+	userUUID, err := uuid.Parse(c.GetString(constant.CtxUserUUID))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, dto.NewApiError("unauthorized"))
+		return
+	}
+
+	identities, err := h.service.GetIdentities(c.Request.Context(), userUUID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToIdentityDTOs(identities))
+}
+
+// LinkIdentity
+// @Summary Привязать провайдера
+// @Description Возвращает ссылку на вход у провайдера, после завершения через callback аккаунт провайдера привяжется к текущему пользователю
+// @Tags oauth
+// @Produce json
+// @Security BearerAuth
+// @Param provider path string true "Провайдер"
+// @Success 200 {object} dto.OAuthURLResponse
+// @Failure 401 {object} dto.ApiError
+// @Failure 404 {object} dto.ApiError "Провайдер не настроен"
+// @Failure 500 {object} dto.ApiError
+// @Router /profile/identities/{provider} [post]
+func (h *Handler) LinkIdentity(c *gin.Context) {
+	userUUID, err := uuid.Parse(c.GetString(constant.CtxUserUUID))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, dto.NewApiError("unauthorized"))
+		return
+	}
+
+	url, err := h.service.StartLink(c.Request.Context(), c.Param("provider"), userUUID)
+	if errors.Is(err, domain.ErrNotFound) {
+		c.JSON(http.StatusNotFound, dto.NewApiError("провайдер не найден"))
+		return
+	}
+	if err != nil {
+		slog.Error("failed to start oauth link", slog.String("err", err.Error()))
+		c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.OAuthURLResponse{URL: url})
+}
+
+// UnlinkIdentity
+// @Summary Отвязать провайдера
+// @Description Нельзя отвязать единственный способ входа, если у пользователя нет пароля
+// @Tags oauth
+// @Security BearerAuth
+// @Param provider path string true "Провайдер"
+// @Success 204
+// @Failure 401 {object} dto.ApiError
+// @Failure 404 {object} dto.ApiError "Провайдер не привязан"
+// @Failure 409 {object} dto.ApiError "Это единственный способ входа"
+// @Failure 500 {object} dto.ApiError
+// @Router /profile/identities/{provider} [delete]
+func (h *Handler) UnlinkIdentity(c *gin.Context) {
+	userUUID, err := uuid.Parse(c.GetString(constant.CtxUserUUID))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, dto.NewApiError("unauthorized"))
+		return
+	}
+
+	err = h.service.Unlink(c.Request.Context(), userUUID, c.Param("provider"))
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		c.JSON(http.StatusNotFound, dto.NewApiError("провайдер не привязан"))
+	case errors.Is(err, domain.ErrLastLoginMethod):
+		c.JSON(http.StatusConflict, dto.NewApiError("сначала задайте пароль или привяжите другой способ входа"))
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
+	default:
+		c.Status(http.StatusNoContent)
+	}
+}
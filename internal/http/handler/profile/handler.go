@@ -1,29 +1,57 @@
 package profile
 
 import (
+	"errors"
 	"net/http"
+	"os"
 
 	"context"
 	"log/slog"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/musicman-backend/internal/domain"
 	"github.com/musicman-backend/internal/domain/constant"
 	"github.com/musicman-backend/internal/domain/entity"
 	"github.com/musicman-backend/internal/http/dto"
+	"github.com/musicman-backend/internal/http/middleware"
+	"github.com/musicman-backend/internal/http/upload"
 )
 
-type UserRepo interface {
-	GetUserByUUID(ctx context.Context, userUUID uuid.UUID) (entity.User, error)
+// maxAvatarSize - предел размера загружаемого аватара
+const maxAvatarSize = 5 << 20
+
+type ProfileService interface {
+	GetProfile(ctx context.Context, userUUID uuid.UUID) (entity.User, error)
+	UpdateProfile(ctx context.Context, userUUID uuid.UUID, update entity.ProfileUpdate) (entity.User, error)
+	SetAvatar(ctx context.Context, userUUID uuid.UUID, filePath string) (entity.User, error)
+	RemoveAvatar(ctx context.Context, userUUID uuid.UUID) (entity.User, error)
+	AvatarURLs(ctx context.Context, key string) (entity.ImageURLs, error)
+	ChangeLogin(ctx context.Context, userUUID, sessionID uuid.UUID, login, password string) (string, error)
+	DeleteAccount(ctx context.Context, userUUID, sessionID uuid.UUID, password string) error
+}
+
+type EmailChanger interface {
+	ChangeEmail(ctx context.Context, userUUID uuid.UUID, email string) error
+}
+
+type PasswordChanger interface {
+	ChangePassword(ctx context.Context, userUUID, sessionID uuid.UUID, oldPassword, newPassword string) error
 }
 
 type Handler struct {
-	userRepo UserRepo
+	profile  ProfileService
+	email    EmailChanger
+	password PasswordChanger
+	tempDir  string // куда сохранять загружаемые аватары, пусто - системный temp
 }
 
-func NewHandler(userRepo UserRepo) *Handler {
+func NewHandler(profile ProfileService, email EmailChanger, password PasswordChanger, tempDir string) *Handler {
 	return &Handler{
-		userRepo: userRepo,
+		profile:  profile,
+		email:    email,
+		password: password,
+		tempDir:  tempDir,
 	}
 }
 
@@ -45,16 +73,354 @@ func (h *Handler) GetMyProfile(ctx *gin.Context) {
 		return
 	}
 
-	profile, err := h.userRepo.GetUserByUUID(ctx, userUUID)
+	profile, err := h.profile.GetProfile(ctx, userUUID)
 	if err != nil {
 		slog.Error("failed to get user", slog.String("err", err.Error()))
 		ctx.AbortWithStatus(http.StatusInternalServerError)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, dto.UserProfile{
-		UUID:   profile.UUID,
-		Login:  profile.Login,
-		Tokens: profile.Tokens,
+	h.respondProfile(ctx, profile)
+}
+
+// UpdateMyProfile
+// @Summary Изменить профиль
+// @Description Меняет только переданные поля. JSON для текстовых полей или multipart/form-data, если вместе с ними загружается аватар (png, jpeg, webp, gif до 5 МБ, каждая сторона от 128 до 4096 пикселей; сохраняются квадратные миниатюры 64, 256 и 512 пикселей)
+// @Tags profile
+// @Accept json,mpfd
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.UpdateProfileRequest false "Поля профиля"
+// @Param avatar formData file false "Аватар"
+// @Success 200 {object} dto.UserProfile
+// @Failure 400 {object} dto.ApiError "Некорректные поля или картинка"
+// @Failure 401 {object} dto.ApiError "Пользователь не авторизован"
+// @Failure 500 {object} dto.ApiError "Внутренняя ошибка сервера"
+// @Router /profile/me [patch]
+func (h *Handler) UpdateMyProfile(ctx *gin.Context) {
+	userUUID, err := uuid.Parse(ctx.GetString(constant.CtxUserUUID))
+	if err != nil {
+		slog.Error("failed to parse user uuid", slog.String("err", err.Error()))
+		ctx.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	req, err := bindUpdateProfile(ctx)
+	if err != nil {
+		slog.Warn("invalid request", slog.String("err", err.Error()))
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.NewApiError("некорекктное тело запроса"))
+		return
+	}
+
+	profile, err := h.profile.UpdateProfile(ctx, userUUID, entity.ProfileUpdate{
+		DisplayName: req.DisplayName,
+		Bio:         req.Bio,
+		Links:       req.Links,
 	})
+	if errors.Is(err, domain.ErrInvalidProfile) {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.NewApiError(err.Error()))
+		return
+	}
+	if err != nil {
+		slog.Error("failed to update profile", slog.String("err", err.Error()))
+		ctx.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	if req.RemoveAvatar {
+		profile, err = h.profile.RemoveAvatar(ctx, userUUID)
+		if err != nil {
+			slog.Error("failed to remove avatar", slog.String("err", err.Error()))
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if file, err := ctx.FormFile("avatar"); err == nil {
+		if file.Size > maxAvatarSize {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.NewApiError("аватар должен быть не больше 5 МБ"))
+			return
+		}
+
+		filePath, err := upload.SaveTempFile(file, h.tempDir)
+		if err != nil {
+			slog.Error("failed to save uploaded file", slog.String("err", err.Error()))
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+		defer func() {
+			if err := os.Remove(filePath); err != nil {
+				slog.Warn("failed to remove temp file", slog.String("path", filePath), slog.String("err", err.Error()))
+			}
+		}()
+
+		profile, err = h.profile.SetAvatar(ctx, userUUID, filePath)
+		if errors.Is(err, domain.ErrInvalidImage) {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.NewApiError(err.Error()))
+			return
+		}
+		if err != nil {
+			slog.Error("failed to set avatar", slog.String("err", err.Error()))
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+	}
+
+	h.respondProfile(ctx, profile)
+}
+
+// ChangePassword
+// @Summary Смена пароля
+// @Description Меняет пароль по старому. Остальные сессии завершаются. При включенной 2FA нужен код в заголовке X-2FA-Code
+// @Tags profile
+// @Accept json
+// @Security BearerAuth
+// @Param X-2FA-Code header string false "Код 2FA или код восстановления"
+// @Param request body dto.ChangePasswordRequest true "Старый и новый пароль"
+// @Success 204 "Пароль изменен"
+// @Failure 400 {object} dto.ApiError "Слишком слабый пароль"
+// @Failure 401 {object} dto.ApiError "Неверный старый пароль"
+// @Failure 403 {object} dto.ApiError "Нужен код 2FA или он неверный"
+// @Failure 429 {object} dto.ApiError "Слишком много попыток, повторить через Retry-After секунд"
+// @Failure 500 {object} dto.ApiError "Внутренняя ошибка сервера"
+// @Router /profile/password [post]
+func (h *Handler) ChangePassword(ctx *gin.Context) {
+	userUUID, err := uuid.Parse(ctx.GetString(constant.CtxUserUUID))
+	if err != nil {
+		slog.Error("failed to parse user uuid", slog.String("err", err.Error()))
+		ctx.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	sessionID, _ := uuid.Parse(ctx.GetString(constant.CtxSessionID))
+
+	var req dto.ChangePasswordRequest
+	if err = ctx.ShouldBindJSON(&req); err != nil {
+		slog.Warn("invalid request", slog.String("err", err.Error()))
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.NewApiError("некорекктное тело запроса"))
+		return
+	}
+
+	err = h.password.ChangePassword(ctx, userUUID, sessionID, req.OldPassword, req.NewPassword)
+
+	var retryErr *domain.RetryAfterError
+	if errors.As(err, &retryErr) {
+		middleware.AbortTooManyRequests(ctx, retryErr)
+		return
+	}
+
+	if errors.Is(err, domain.ErrInvalidCredentials) {
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, dto.NewApiError("Неверный пароль"))
+		return
+	}
+
+	if errors.Is(err, domain.ErrWeakPassword) {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.NewApiError(err.Error()))
+		return
+	}
+
+	if err != nil {
+		slog.Error("failed to change password", slog.String("err", err.Error()))
+		ctx.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// ChangeLogin
+// @Summary Смена логина
+// @Description Меняет логин, автор семплов и паков меняется вместе с ним. Возвращает новый токен для текущей сессии. При включенной 2FA нужен код в заголовке X-2FA-Code, аккаунту без пароля и 2FA - вход не раньше 10 минут назад
+// @Tags profile
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param X-2FA-Code header string false "Код 2FA или код восстановления"
+// @Param request body dto.ChangeLoginRequest true "Новый логин и пароль"
+// @Success 200 {object} dto.LoginResponse "Новый токен"
+// @Failure 400 {object} dto.ApiError "Некорректный логин"
+// @Failure 401 {object} dto.ApiError "Неверный пароль"
+// @Failure 403 {object} dto.ApiError "Нужен код 2FA или он неверный, либо нужен свежий вход"
+// @Failure 409 {object} dto.ApiError "Логин занят"
+// @Failure 429 {object} dto.ApiError "Слишком много неверных кодов 2FA, повторить через Retry-After секунд"
+// @Failure 500 {object} dto.ApiError "Внутренняя ошибка сервера"
+// @Router /profile/login [post]
+func (h *Handler) ChangeLogin(ctx *gin.Context) {
+	userUUID, err := uuid.Parse(ctx.GetString(constant.CtxUserUUID))
+	if err != nil {
+		slog.Error("failed to parse user uuid", slog.String("err", err.Error()))
+		ctx.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	sessionID, _ := uuid.Parse(ctx.GetString(constant.CtxSessionID))
+
+	var req dto.ChangeLoginRequest
+	if err = ctx.ShouldBindJSON(&req); err != nil {
+		slog.Warn("invalid request", slog.String("err", err.Error()))
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.NewApiError("некорекктное тело запроса"))
+		return
+	}
+
+	token, err := h.profile.ChangeLogin(ctx, userUUID, sessionID, req.Login, req.Password)
+	if errors.Is(err, domain.ErrInvalidLogin) {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.NewApiError(err.Error()))
+		return
+	}
+
+	if errors.Is(err, domain.ErrInvalidCredentials) {
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, dto.NewApiError("Неверный пароль"))
+		return
+	}
+
+	if errors.Is(err, domain.ErrReauthRequired) {
+		ctx.AbortWithStatusJSON(http.StatusForbidden, dto.NewApiError("Войдите заново и повторите действие"))
+		return
+	}
+
+	if errors.Is(err, domain.ErrUserAlreadyExists) {
+		ctx.AbortWithStatusJSON(http.StatusConflict, dto.NewApiError("Пользователь с таким логином уже существует"))
+		return
+	}
+
+	if err != nil {
+		slog.Error("failed to change login", slog.String("err", err.Error()))
+		ctx.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.LoginResponse{Token: token})
+}
+
+// DeleteAccount
+// @Summary Удаление аккаунта
+// @Description Обезличивает аккаунт: стирает почту, профиль, аватар, способы входа и сессии. Покупки и платежи сохраняются для отчетности, семплы и паки уходят в корзину и снимаются с продажи, купленные остаются у покупателей под обезличенным автором. При включенной 2FA нужен код в заголовке X-2FA-Code, аккаунту без пароля и 2FA - вход не раньше 10 минут назад
+// @Tags profile
+// @Accept json
+// @Security BearerAuth
+// @Param X-2FA-Code header string false "Код 2FA или код восстановления"
+// @Param request body dto.DeleteAccountRequest true "Пароль"
+// @Success 204 "Аккаунт удален"
+// @Failure 401 {object} dto.ApiError "Неверный пароль"
+// @Failure 403 {object} dto.ApiError "Нужен код 2FA или он неверный, либо нужен свежий вход"
+// @Failure 429 {object} dto.ApiError "Слишком много неверных кодов 2FA, повторить через Retry-After секунд"
+// @Failure 500 {object} dto.ApiError "Внутренняя ошибка сервера"
+// @Router /profile/me [delete]
+func (h *Handler) DeleteAccount(ctx *gin.Context) {
+	userUUID, err := uuid.Parse(ctx.GetString(constant.CtxUserUUID))
+	if err != nil {
+		slog.Error("failed to parse user uuid", slog.String("err", err.Error()))
+		ctx.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	var req dto.DeleteAccountRequest
+	if err = ctx.ShouldBindJSON(&req); err != nil {
+		slog.Warn("invalid request", slog.String("err", err.Error()))
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.NewApiError("некорекктное тело запроса"))
+		return
+	}
+
+	sessionID, _ := uuid.Parse(ctx.GetString(constant.CtxSessionID))
+
+	err = h.profile.DeleteAccount(ctx, userUUID, sessionID, req.Password)
+	if errors.Is(err, domain.ErrInvalidCredentials) {
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, dto.NewApiError("Неверный пароль"))
+		return
+	}
+
+	if errors.Is(err, domain.ErrReauthRequired) {
+		ctx.AbortWithStatusJSON(http.StatusForbidden, dto.NewApiError("Войдите заново и повторите действие"))
+		return
+	}
+
+	if err != nil {
+		slog.Error("failed to delete account", slog.String("err", err.Error()))
+		ctx.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func (h *Handler) respondProfile(ctx *gin.Context, profile entity.User) {
+	avatar, err := h.profile.AvatarURLs(ctx, profile.AvatarKey)
+	if err != nil {
+		slog.Error("failed to get avatar url", slog.String("err", err.Error()))
+		ctx.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.ToUserProfile(profile, avatar))
+}
+
+// bindUpdateProfile читает поля профиля из JSON или multipart формы. В форме поле без ключа не меняется
+func bindUpdateProfile(ctx *gin.Context) (dto.UpdateProfileRequest, error) {
+	var req dto.UpdateProfileRequest
+	if ctx.ContentType() != gin.MIMEMultipartPOSTForm {
+		err := ctx.ShouldBindJSON(&req)
+		return req, err
+	}
+
+	if name, ok := ctx.GetPostForm("display_name"); ok {
+		req.DisplayName = &name
+	}
+	if bio, ok := ctx.GetPostForm("bio"); ok {
+		req.Bio = &bio
+	}
+	if links, ok := ctx.GetPostFormArray("links"); ok {
+		req.Links = &links
+	}
+	req.RemoveAvatar = ctx.PostForm("remove_avatar") == "true"
+
+	return req, nil
+}
+
+// ChangeEmail
+// @Summary Смена почты
+// @Description Ставит новую почту и отправляет на нее письмо с подтверждением. С текущей неподтвержденной почтой отправляет письмо повторно. При включенной 2FA нужен код в заголовке X-2FA-Code
+// @Tags profile
+// @Accept json
+// @Security BearerAuth
+// @Param X-2FA-Code header string false "Код 2FA или код восстановления"
+// @Param request body dto.ChangeEmailRequest true "Новая почта"
+// @Success 202 "Письмо с подтверждением отправлено"
+// @Failure 400 {object} dto.ApiError "Некорректная почта"
+// @Failure 401 {object} dto.ApiError "Пользователь не авторизован"
+// @Failure 403 {object} dto.ApiError "Нужен код 2FA или он неверный"
+// @Failure 409 {object} dto.ApiError "Почта занята другим пользователем"
+// @Failure 429 {object} dto.ApiError "Слишком много неверных кодов 2FA, повторить через Retry-After секунд"
+// @Failure 500 {object} dto.ApiError "Внутренняя ошибка сервера"
+// @Router /profile/email [post]
+func (h *Handler) ChangeEmail(ctx *gin.Context) {
+	userUUID, err := uuid.Parse(ctx.GetString(constant.CtxUserUUID))
+	if err != nil {
+		slog.Error("failed to parse user uuid", slog.String("err", err.Error()))
+		ctx.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	var req dto.ChangeEmailRequest
+	if err = ctx.ShouldBind(&req); err != nil {
+		slog.Warn("invalid request", slog.String("err", err.Error()))
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.NewApiError("некорекктное тело запроса"))
+		return
+	}
+
+	err = h.email.ChangeEmail(ctx, userUUID, req.Email)
+	if errors.Is(err, domain.ErrInvalidEmail) {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.NewApiError(err.Error()))
+		return
+	}
+
+	if errors.Is(err, domain.ErrEmailAlreadyUsed) {
+		ctx.AbortWithStatusJSON(http.StatusConflict, dto.NewApiError("Почта уже используется"))
+		return
+	}
+
+	if err != nil {
+		slog.Error("failed to change email", slog.String("err", err.Error()))
+		ctx.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	ctx.Status(http.StatusAccepted)
 }
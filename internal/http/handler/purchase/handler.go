@@ -12,12 +12,19 @@ import (
 	"github.com/musicman-backend/internal/domain/constant"
 	"github.com/musicman-backend/internal/domain/entity"
 	"github.com/musicman-backend/internal/http/dto"
+	"github.com/musicman-backend/internal/http/middleware"
 )
 
 type PurchaseService interface {
-	PurchaseSample(ctx context.Context, userUUID, sampleID uuid.UUID) (entity.Purchase, error)
+	PurchaseSample(ctx context.Context, userUUID, sampleID uuid.UUID, licenseType, twoFactorCode string) (entity.Purchase, error)
 	GetUserPurchases(ctx context.Context, userUUID uuid.UUID) ([]entity.Purchase, error)
 	IsPurchased(ctx context.Context, userUUID, sampleID uuid.UUID) (bool, error)
+	DownloadKey(ctx context.Context, purchase entity.Purchase, sample entity.Sample) (string, error)
+	SetFollowUpdates(ctx context.Context, userUUID, purchaseID uuid.UUID, followUpdates bool) (entity.Purchase, error)
+	LicenseCertificate(ctx context.Context, userUUID, purchaseID uuid.UUID) (entity.SignedDocument, error)
+	Receipt(ctx context.Context, userUUID, purchaseID uuid.UUID) (entity.SignedDocument, error)
+	VerifyReceipt(ctx context.Context, doc entity.SignedDocument) (entity.Purchase, error)
+	SigningKey() (keyID, algorithm, publicKey string)
 }
 
 type SampleService interface {
@@ -39,15 +46,21 @@ func New(purchaseService PurchaseService, sampleService SampleService) *Handler
 
 // PurchaseSample godoc
 // @Summary Покупка семпла
-// @Description Покупает семпл за токены пользователя. После покупки семпл можно скачивать неограниченное количество раз. Если семпл уже куплен, возвращает ошибку 400.
+// @Description Покупает лицензию на семпл за токены пользователя, без тела покупается коммерческая лицензия. После покупки семпл можно скачивать неограниченное количество раз. Эксклюзивная лицензия снимает семпл с продажи. Если семпл уже куплен, возвращает ошибку 400. Дорогие покупки при включенной 2FA требуют код в заголовке X-2FA-Code
 // @Tags purchases
+// @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "Sample ID"
+// @Param request body dto.PurchaseRequest false "Тип лицензии"
+// @Param X-2FA-Code header string false "Код 2FA или код восстановления"
 // @Success 201 {object} dto.PurchaseDTO
 // @Failure 400 {object} dto.ApiError
 // @Failure 401 {object} dto.ApiError
+// @Failure 403 {object} dto.ApiError "Нужен код 2FA или он неверный"
 // @Failure 404 {object} dto.ApiError
+// @Failure 409 {object} dto.ApiError "Семпл продан эксклюзивно"
+// @Failure 429 {object} dto.ApiError "Слишком много неверных кодов 2FA, повторить через Retry-After секунд"
 // @Failure 500 {object} dto.ApiError
 // @Router /samples/{id}/purchase [post]
 func (h *Handler) PurchaseSample(c *gin.Context) {
@@ -68,8 +81,17 @@ func (h *Handler) PurchaseSample(c *gin.Context) {
 		return
 	}
 
+	// Тело необязательное, старые клиенты покупают без него
+	var req dto.PurchaseRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, dto.NewApiError("некорекктное тело запроса"))
+			return
+		}
+	}
+
 	// Вызвать purchaseService.PurchaseSample
-	purchase, err := h.purchaseService.PurchaseSample(c.Request.Context(), userUUID, sampleID)
+	purchase, err := h.purchaseService.PurchaseSample(c.Request.Context(), userUUID, sampleID, req.License, c.GetHeader(constant.HeaderTwoFactorCode))
 	if errors.Is(err, domain.ErrNotFound) {
 		c.JSON(http.StatusNotFound,
 			dto.NewApiError("Не нашли этот сэмпл… возможно, он спрятался. Попробуйте ещё раз позже 🙂"),
@@ -84,6 +106,20 @@ func (h *Handler) PurchaseSample(c *gin.Context) {
 		return
 	}
 
+	if errors.Is(err, domain.ErrLicenseNotOffered) {
+		c.JSON(http.StatusBadRequest,
+			dto.NewApiError("Автор не продает такую лицензию на этот сэмпл"),
+		)
+		return
+	}
+
+	if errors.Is(err, domain.ErrSampleUnavailable) {
+		c.JSON(http.StatusConflict,
+			dto.NewApiError("Сэмпл уже продан эксклюзивно и снят с продажи"),
+		)
+		return
+	}
+
 	if errors.Is(err, domain.ErrAlreadyPurchased) {
 		c.JSON(http.StatusBadRequest,
 			dto.NewApiError("Вы уже покупали этот сэмпл — он по-прежнему ваш 💛"),
@@ -91,6 +127,17 @@ func (h *Handler) PurchaseSample(c *gin.Context) {
 		return
 	}
 
+	var retryErr *domain.RetryAfterError
+	if errors.As(err, &retryErr) {
+		middleware.AbortTooManyRequests(c, retryErr)
+		return
+	}
+
+	if errors.Is(err, domain.ErrTwoFactorRequired) || errors.Is(err, domain.ErrInvalidTwoFactorCode) {
+		middleware.AbortTwoFactor(c, err)
+		return
+	}
+
 	if errors.Is(err, domain.ErrInsufficientTokens) {
 		c.JSON(http.StatusBadRequest,
 			dto.NewApiError("Похоже, не хватает токенов. Пополните баланс, и всё получится 💫"),
@@ -145,7 +192,14 @@ func (h *Handler) GetUserPurchases(c *gin.Context) {
 			return
 		}
 
-		downloadURL, err := h.sampleService.GetSampleDownloadURL(c.Request.Context(), sample.MinioKey)
+		key, err := h.purchaseService.DownloadKey(c.Request.Context(), purchase, sample)
+		if err != nil {
+			slog.Error(err.Error())
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		downloadURL, err := h.sampleService.GetSampleDownloadURL(c.Request.Context(), key)
 		if err != nil {
 			slog.Error(err.Error())
 			c.Status(http.StatusInternalServerError)
@@ -160,3 +214,174 @@ func (h *Handler) GetUserPurchases(c *gin.Context) {
 
 	c.JSON(http.StatusOK, result)
 }
+
+// SetFollowUpdates godoc
+// @Summary Подписка покупки на обновления аудио
+// @Description По умолчанию покупатель получает ту версию аудио, которую купил. С подпиской ссылка на скачивание всегда ведет на текущую версию семпла, при отписке покупка закрепляется за текущей версией.
+// @Tags purchases
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Purchase ID"
+// @Param request body dto.FollowUpdatesRequest true "Подписка"
+// @Success 200 {object} dto.PurchaseDTO
+// @Failure 400 {object} dto.ApiError
+// @Failure 404 {object} dto.ApiError
+// @Failure 500 {object} dto.ApiError
+// @Router /purchases/{id}/updates [put]
+func (h *Handler) SetFollowUpdates(c *gin.Context) {
+	userUUIDStr := c.GetString(constant.CtxUserUUID)
+	userUUID, err := uuid.Parse(userUUIDStr)
+	if err != nil {
+		slog.Error(err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	purchaseID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewApiError(err.Error()))
+		return
+	}
+
+	var req dto.FollowUpdatesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewApiError(err.Error()))
+		return
+	}
+
+	purchase, err := h.purchaseService.SetFollowUpdates(c.Request.Context(), userUUID, purchaseID, req.FollowUpdates)
+	if errors.Is(err, domain.ErrNotFound) {
+		c.JSON(http.StatusNotFound, dto.NewApiError("Покупка не найдена"))
+		return
+	}
+	if err != nil {
+		slog.Error(err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToPurchaseDTO(purchase))
+}
+
+// GetLicenseCertificate godoc
+// @Summary Сертификат лицензии
+// @Description Сертификат купленной лицензии, подписанный Ed25519 ключом площадки. Подпись считается от поля document байт в байт
+// @Tags purchases
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Purchase ID"
+// @Success 200 {object} dto.SignedDocumentDTO
+// @Failure 400 {object} dto.ApiError
+// @Failure 404 {object} dto.ApiError
+// @Failure 500 {object} dto.ApiError
+// @Router /purchases/{id}/license [get]
+func (h *Handler) GetLicenseCertificate(c *gin.Context) {
+	userUUID, err := uuid.Parse(c.GetString(constant.CtxUserUUID))
+	if err != nil {
+		slog.Error(err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	purchaseID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewApiError(err.Error()))
+		return
+	}
+
+	doc, err := h.purchaseService.LicenseCertificate(c.Request.Context(), userUUID, purchaseID)
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		c.JSON(http.StatusNotFound, dto.NewApiError("Покупка не найдена"))
+	case err != nil:
+		slog.Error("failed to issue license certificate", slog.String("err", err.Error()))
+		c.Status(http.StatusInternalServerError)
+	default:
+		c.Header("Content-Disposition", `attachment; filename="license-`+purchaseID.String()+`.json"`)
+		c.JSON(http.StatusOK, dto.ToSignedDocumentDTO(doc))
+	}
+}
+
+// GetReceipt godoc
+// @Summary Чек покупки
+// @Description Чек с пользователем, хешем купленного аудио, лицензией, ценой и временем покупки, подписанный Ed25519 ключом площадки. Подпись считается от поля document байт в байт, проверить чек можно через POST /receipts/verify или публичным ключом из GET /receipts/key
+// @Tags purchases
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Purchase ID"
+// @Success 200 {object} dto.SignedDocumentDTO
+// @Failure 400 {object} dto.ApiError
+// @Failure 404 {object} dto.ApiError
+// @Failure 500 {object} dto.ApiError
+// @Router /purchases/{id}/receipt [get]
+func (h *Handler) GetReceipt(c *gin.Context) {
+	userUUID, err := uuid.Parse(c.GetString(constant.CtxUserUUID))
+	if err != nil {
+		slog.Error(err.Error())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	purchaseID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewApiError(err.Error()))
+		return
+	}
+
+	doc, err := h.purchaseService.Receipt(c.Request.Context(), userUUID, purchaseID)
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		c.JSON(http.StatusNotFound, dto.NewApiError("Покупка не найдена"))
+	case err != nil:
+		slog.Error("failed to issue receipt", slog.String("err", err.Error()))
+		c.Status(http.StatusInternalServerError)
+	default:
+		c.Header("Content-Disposition", `attachment; filename="receipt-`+purchaseID.String()+`.json"`)
+		c.JSON(http.StatusOK, dto.ToSignedDocumentDTO(doc))
+	}
+}
+
+// VerifyReceipt godoc
+// @Summary Проверка чека покупки
+// @Description Публичная проверка без авторизации: подпись чека верна и покупка по нему существует. Чек передается в том виде, в котором был выдан
+// @Tags receipts
+// @Accept json
+// @Produce json
+// @Param request body dto.SignedDocumentDTO true "Чек"
+// @Success 200 {object} dto.ReceiptVerificationResponse
+// @Failure 400 {object} dto.ApiError
+// @Failure 500 {object} dto.ApiError
+// @Router /receipts/verify [post]
+func (h *Handler) VerifyReceipt(c *gin.Context) {
+	var req dto.SignedDocumentDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewApiError("некорекктное тело запроса"))
+		return
+	}
+
+	purchase, err := h.purchaseService.VerifyReceipt(c.Request.Context(), req.ToEntity())
+	switch {
+	case errors.Is(err, domain.ErrInvalidSignature):
+		c.JSON(http.StatusOK, dto.ReceiptVerificationResponse{Reason: "подпись недействительна"})
+	case errors.Is(err, domain.ErrNotFound):
+		c.JSON(http.StatusOK, dto.ReceiptVerificationResponse{Reason: "покупка по чеку не найдена"})
+	case err != nil:
+		slog.Error("failed to verify receipt", slog.String("err", err.Error()))
+		c.Status(http.StatusInternalServerError)
+	default:
+		c.JSON(http.StatusOK, dto.ReceiptVerificationResponse{Valid: true, Purchase: dto.ToVerifiedPurchaseDTO(purchase)})
+	}
+}
+
+// GetSigningKey godoc
+// @Summary Публичный ключ подписи
+// @Description Ключ Ed25519, которым подписаны чеки и сертификаты лицензий, для проверки без обращения к серверу
+// @Tags receipts
+// @Produce json
+// @Success 200 {object} dto.SigningKeyDTO
+// @Router /receipts/key [get]
+func (h *Handler) GetSigningKey(c *gin.Context) {
+	keyID, algorithm, publicKey := h.purchaseService.SigningKey()
+	c.JSON(http.StatusOK, dto.SigningKeyDTO{KeyID: keyID, Algorithm: algorithm, PublicKey: publicKey})
+}
@@ -0,0 +1,129 @@
+package recommendation
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/musicman-backend/internal/domain"
+	"github.com/musicman-backend/internal/domain/constant"
+	"github.com/musicman-backend/internal/domain/entity"
+	"github.com/musicman-backend/internal/http/dto"
+)
+
+type Service interface {
+	Similar(ctx context.Context, sampleID uuid.UUID, limit int) ([]entity.Sample, error)
+	Feed(ctx context.Context, userUUID uuid.UUID, limit int) ([]entity.Sample, error)
+}
+
+// SampleConverter собирает семплы со ссылками, доступными пользователю
+type SampleConverter interface {
+	SampleDTOs(ctx context.Context, userUUID uuid.UUID, samples []entity.Sample) ([]dto.SampleDTO, error)
+}
+
+type Handler struct {
+	service Service
+	samples SampleConverter
+}
+
+func New(service Service, samples SampleConverter) *Handler {
+	return &Handler{
+		service: service,
+		samples: samples,
+	}
+}
+
+// GetSimilar
+// @Summary Похожие семплы
+// @Description Семплы, которые покупают вместе с этим, и близкие по жанру, автору, цене и длительности. Пересчитываются фоновой задачей, пока похожих нет - популярные
+// @Tags recommendations
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Sample ID"
+// @Param limit query int false "Сколько семплов вернуть, по умолчанию 20, не больше 100"
+// @Success 200 {array} dto.SampleDTO
+// @Failure 400 {object} dto.ApiError
+// @Failure 401 {object} dto.ApiError
+// @Failure 404 {object} dto.ApiError
+// @Failure 500 {object} dto.ApiError
+// @Router /samples/{id}/similar [get]
+func (h *Handler) GetSimilar(c *gin.Context) {
+	userUUID, err := uuid.Parse(c.GetString(constant.CtxUserUUID))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, dto.NewApiError("unauthorized"))
+		return
+	}
+
+	sampleID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewApiError(err.Error()))
+		return
+	}
+
+	var query dto.RecommendationQuery
+	if err = c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewApiError(err.Error()))
+		return
+	}
+
+	samples, err := h.service.Similar(c.Request.Context(), sampleID, query.GetLimit())
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		c.JSON(http.StatusNotFound, dto.NewApiError(err.Error()))
+	case err != nil:
+		slog.Error("failed to get similar samples", slog.String("err", err.Error()))
+		c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
+	default:
+		h.respond(c, userUUID, samples)
+	}
+}
+
+// GetFeed
+// @Summary Лента рекомендаций
+// @Description Семплы, похожие на купленные и лайкнутые пользователем, без уже купленных и его собственных. Новому пользователю - популярные за неделю
+// @Tags recommendations
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "Сколько семплов вернуть, по умолчанию 20, не больше 100"
+// @Success 200 {array} dto.SampleDTO
+// @Failure 400 {object} dto.ApiError
+// @Failure 401 {object} dto.ApiError
+// @Failure 500 {object} dto.ApiError
+// @Router /feed [get]
+func (h *Handler) GetFeed(c *gin.Context) {
+	userUUID, err := uuid.Parse(c.GetString(constant.CtxUserUUID))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, dto.NewApiError("unauthorized"))
+		return
+	}
+
+	var query dto.RecommendationQuery
+	if err = c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewApiError(err.Error()))
+		return
+	}
+
+	samples, err := h.service.Feed(c.Request.Context(), userUUID, query.GetLimit())
+	if err != nil {
+		slog.Error("failed to get feed", slog.String("err", err.Error()))
+		c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
+		return
+	}
+
+	h.respond(c, userUUID, samples)
+}
+
+func (h *Handler) respond(c *gin.Context, userUUID uuid.UUID, samples []entity.Sample) {
+	response, err := h.samples.SampleDTOs(c.Request.Context(), userUUID, samples)
+	if err != nil {
+		slog.Error("failed to build samples", slog.String("err", err.Error()))
+		c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,97 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/musicman-backend/internal/domain"
+	"github.com/musicman-backend/internal/domain/constant"
+	"github.com/musicman-backend/internal/domain/entity"
+	"github.com/musicman-backend/internal/http/dto"
+)
+
+type Service interface {
+	List(ctx context.Context, userUUID uuid.UUID) ([]entity.Session, error)
+	Revoke(ctx context.Context, userUUID, sessionID uuid.UUID) error
+}
+
+type Handler struct {
+	service Service
+}
+
+func New(service Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+// GetSessions
+// @Summary Активные сессии
+// @Description Устройства, на которых выполнен вход. Текущая сессия помечена current
+// @Tags sessions
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} dto.SessionDTO
+// @Failure 401 {object} dto.ApiError
+// @Failure 500 {object} dto.ApiError
+// @Router /profile/sessions [get]
+func (h *Handler) GetSessions(c *gin.Context) {
+	userUUID, err := uuid.Parse(c.GetString(constant.CtxUserUUID))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, dto.NewApiError("unauthorized"))
+		return
+	}
+
+	sessions, err := h.service.List(c.Request.Context(), userUUID)
+	if err != nil {
+		slog.Error("failed to get sessions", slog.String("err", err.Error()))
+		c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
+		return
+	}
+
+	current, _ := uuid.Parse(c.GetString(constant.CtxSessionID))
+
+	c.JSON(http.StatusOK, dto.ToSessionDTOs(sessions, current))
+}
+
+// RevokeSession
+// @Summary Завершить сессию
+// @Description Выходит с устройства: токен этой сессии перестает приниматься. Можно завершить и текущую
+// @Tags sessions
+// @Security BearerAuth
+// @Param id path string true "ID сессии"
+// @Success 204
+// @Failure 400 {object} dto.ApiError
+// @Failure 401 {object} dto.ApiError
+// @Failure 404 {object} dto.ApiError "Сессия не найдена или уже завершена"
+// @Failure 500 {object} dto.ApiError
+// @Router /profile/sessions/{id} [delete]
+func (h *Handler) RevokeSession(c *gin.Context) {
+	userUUID, err := uuid.Parse(c.GetString(constant.CtxUserUUID))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, dto.NewApiError("unauthorized"))
+		return
+	}
+
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewApiError("некорректный id сессии"))
+		return
+	}
+
+	err = h.service.Revoke(c.Request.Context(), userUUID, sessionID)
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		c.JSON(http.StatusNotFound, dto.NewApiError("сессия не найдена"))
+	case err != nil:
+		slog.Error("failed to revoke session", slog.String("err", err.Error()))
+		c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
+	default:
+		c.Status(http.StatusNoContent)
+	}
+}
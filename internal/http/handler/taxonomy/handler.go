@@ -0,0 +1,293 @@
+package taxonomy
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/musicman-backend/internal/domain"
+	"github.com/musicman-backend/internal/domain/entity"
+	"github.com/musicman-backend/internal/http/dto"
+)
+
+type Service interface {
+	Genres(ctx context.Context) ([]entity.GenreNode, error)
+	Tags(ctx context.Context, kind, search string, limit int) ([]entity.Tag, error)
+	CreateGenre(ctx context.Context, name, parent string) (entity.GenreNode, error)
+	UpdateGenre(ctx context.Context, genreSlug string, name, parent *string) (entity.GenreNode, error)
+	MergeGenre(ctx context.Context, genreSlug, into string) (entity.GenreNode, error)
+	CreateTag(ctx context.Context, kind, name string) (entity.Tag, error)
+	RenameTag(ctx context.Context, id uuid.UUID, name string) (entity.Tag, error)
+	MergeTags(ctx context.Context, id, into uuid.UUID) (entity.Tag, error)
+}
+
+type Handler struct {
+	service Service
+}
+
+func New(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// GetGenres
+// @Summary Справочник жанров
+// @Description Плоский список, дерево собирается по parent_id. Счетчики - опубликованные семплы и паки в самом жанре
+// @Tags taxonomy
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} dto.GenreDTO
+// @Failure 401 {object} dto.ApiError
+// @Failure 500 {object} dto.ApiError
+// @Router /genres [get]
+func (h *Handler) GetGenres(c *gin.Context) {
+	genres, err := h.service.Genres(c.Request.Context())
+	if err != nil {
+		slog.Error("failed to get genres", slog.String("err", err.Error()))
+		c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToGenreDTOs(genres))
+}
+
+// GetTags
+// @Summary Справочник тегов, инструментов и настроений
+// @Description Для автодополнения: q - начало названия. Сначала самые используемые
+// @Tags taxonomy
+// @Produce json
+// @Security BearerAuth
+// @Param kind query string false "Вид" Enums(tag, instrument, mood)
+// @Param q query string false "Начало названия"
+// @Param limit query int false "По умолчанию 50, не больше 200"
+// @Success 200 {array} dto.TagCountDTO
+// @Failure 400 {object} dto.ApiError
+// @Failure 401 {object} dto.ApiError
+// @Failure 500 {object} dto.ApiError
+// @Router /tags [get]
+func (h *Handler) GetTags(c *gin.Context) {
+	var query dto.TagListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewApiError(err.Error()))
+		return
+	}
+
+	tags, err := h.service.Tags(c.Request.Context(), query.Kind, query.Search, query.Limit)
+	if h.handleError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToTagCountDTOs(tags))
+}
+
+// CreateGenre
+// @Summary Добавить жанр
+// @Description slug строится из названия
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.CreateGenreRequest true "Жанр"
+// @Success 201 {object} dto.GenreDTO
+// @Failure 400 {object} dto.ApiError
+// @Failure 403 {object} dto.ApiError
+// @Failure 409 {object} dto.ApiError "Жанр с таким slug уже есть"
+// @Failure 500 {object} dto.ApiError
+// @Router /admin/genres [post]
+func (h *Handler) CreateGenre(c *gin.Context) {
+	var req dto.CreateGenreRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewApiError(err.Error()))
+		return
+	}
+
+	genre, err := h.service.CreateGenre(c.Request.Context(), req.Name, req.Parent)
+	if h.handleError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.ToGenreDTO(genre))
+}
+
+// UpdateGenre
+// @Summary Переименовать жанр или перенести в другой
+// @Description Новый slug сразу применяется к семплам и пакам жанра
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param slug path string true "Slug жанра"
+// @Param request body dto.UpdateGenreRequest true "Изменения"
+// @Success 200 {object} dto.GenreDTO
+// @Failure 400 {object} dto.ApiError
+// @Failure 403 {object} dto.ApiError
+// @Failure 404 {object} dto.ApiError
+// @Failure 409 {object} dto.ApiError "Жанр с таким slug уже есть"
+// @Failure 500 {object} dto.ApiError
+// @Router /admin/genres/{slug} [patch]
+func (h *Handler) UpdateGenre(c *gin.Context) {
+	var req dto.UpdateGenreRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewApiError(err.Error()))
+		return
+	}
+
+	genre, err := h.service.UpdateGenre(c.Request.Context(), c.Param("slug"), req.Name, req.Parent)
+	if h.handleError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToGenreDTO(genre))
+}
+
+// MergeGenre
+// @Summary Объединить жанры
+// @Description Семплы, паки и поджанры переносятся в жанр into, исходный жанр удаляется
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param slug path string true "Slug исходного жанра"
+// @Param request body dto.MergeGenreRequest true "Куда перенести"
+// @Success 200 {object} dto.GenreDTO
+// @Failure 400 {object} dto.ApiError
+// @Failure 403 {object} dto.ApiError
+// @Failure 404 {object} dto.ApiError
+// @Failure 500 {object} dto.ApiError
+// @Router /admin/genres/{slug}/merge [post]
+func (h *Handler) MergeGenre(c *gin.Context) {
+	var req dto.MergeGenreRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewApiError(err.Error()))
+		return
+	}
+
+	genre, err := h.service.MergeGenre(c.Request.Context(), c.Param("slug"), req.Into)
+	if h.handleError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToGenreDTO(genre))
+}
+
+// CreateTag
+// @Summary Добавить тег, инструмент или настроение
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.CreateTagRequest true "Тег"
+// @Success 201 {object} dto.TagDTO
+// @Failure 400 {object} dto.ApiError
+// @Failure 403 {object} dto.ApiError
+// @Failure 409 {object} dto.ApiError "Тег с таким slug уже есть"
+// @Failure 500 {object} dto.ApiError
+// @Router /admin/tags [post]
+func (h *Handler) CreateTag(c *gin.Context) {
+	var req dto.CreateTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewApiError(err.Error()))
+		return
+	}
+
+	tag, err := h.service.CreateTag(c.Request.Context(), req.Kind, req.Name)
+	if h.handleError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.ToTagDTO(tag))
+}
+
+// RenameTag
+// @Summary Переименовать тег
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID тега"
+// @Param request body dto.RenameTagRequest true "Новое название"
+// @Success 200 {object} dto.TagDTO
+// @Failure 400 {object} dto.ApiError
+// @Failure 403 {object} dto.ApiError
+// @Failure 404 {object} dto.ApiError
+// @Failure 409 {object} dto.ApiError "Тег с таким slug уже есть, их нужно объединить"
+// @Failure 500 {object} dto.ApiError
+// @Router /admin/tags/{id} [patch]
+func (h *Handler) RenameTag(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewApiError(err.Error()))
+		return
+	}
+
+	var req dto.RenameTagRequest
+	if err = c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewApiError(err.Error()))
+		return
+	}
+
+	tag, err := h.service.RenameTag(c.Request.Context(), id, req.Name)
+	if h.handleError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToTagDTO(tag))
+}
+
+// MergeTag
+// @Summary Объединить теги
+// @Description Семплы и паки переносятся на тег into того же вида, исходный тег удаляется
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID исходного тега"
+// @Param request body dto.MergeTagRequest true "Куда перенести"
+// @Success 200 {object} dto.TagDTO
+// @Failure 400 {object} dto.ApiError
+// @Failure 403 {object} dto.ApiError
+// @Failure 404 {object} dto.ApiError
+// @Failure 500 {object} dto.ApiError
+// @Router /admin/tags/{id}/merge [post]
+func (h *Handler) MergeTag(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewApiError(err.Error()))
+		return
+	}
+
+	var req dto.MergeTagRequest
+	if err = c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewApiError(err.Error()))
+		return
+	}
+
+	tag, err := h.service.MergeTags(c.Request.Context(), id, req.Into)
+	if h.handleError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToTagDTO(tag))
+}
+
+// handleError отвечает на ошибку справочника, true - ответ уже записан
+func (h *Handler) handleError(c *gin.Context, err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, domain.ErrNotFound):
+		c.JSON(http.StatusNotFound, dto.NewApiError(err.Error()))
+	case errors.Is(err, domain.ErrInvalidGenre), errors.Is(err, domain.ErrInvalidTag):
+		c.JSON(http.StatusBadRequest, dto.NewApiError(err.Error()))
+	case errors.Is(err, domain.ErrTaxonomyExists):
+		c.JSON(http.StatusConflict, dto.NewApiError(err.Error()))
+	default:
+		slog.Error("taxonomy request failed", slog.String("err", err.Error()))
+		c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
+	}
+
+	return true
+}
@@ -0,0 +1,238 @@
+package twofactor
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/musicman-backend/internal/domain"
+	"github.com/musicman-backend/internal/domain/constant"
+	"github.com/musicman-backend/internal/domain/entity"
+	"github.com/musicman-backend/internal/http/dto"
+	"github.com/musicman-backend/internal/http/middleware"
+)
+
+type Service interface {
+	Status(ctx context.Context, userUUID uuid.UUID) (entity.TwoFactorStatus, error)
+	Enroll(ctx context.Context, userUUID uuid.UUID) (entity.TwoFactorEnrollment, error)
+	Confirm(ctx context.Context, userUUID uuid.UUID, code string) ([]string, error)
+	Disable(ctx context.Context, userUUID uuid.UUID, code string) error
+	RegenerateRecoveryCodes(ctx context.Context, userUUID uuid.UUID, code string) ([]string, error)
+}
+
+type Handler struct {
+	service Service
+}
+
+func New(service Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+// GetStatus
+// @Summary Состояние 2FA
+// @Tags 2fa
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.TwoFactorStatus
+// @Failure 401 {object} dto.ApiError
+// @Failure 500 {object} dto.ApiError
+// @Router /profile/2fa [get]
+func (h *Handler) GetStatus(c *gin.Context) {
+	userUUID, err := uuid.Parse(c.GetString(constant.CtxUserUUID))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, dto.NewApiError("unauthorized"))
+		return
+	}
+
+	status, err := h.service.Status(c.Request.Context(), userUUID)
+	if err != nil {
+		slog.Error("failed to get two-factor status", slog.String("err", err.Error()))
+		c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.TwoFactorStatus{
+		Enabled:       status.Enabled,
+		EnabledAt:     status.EnabledAt,
+		RecoveryCodes: status.RecoveryCodes,
+	})
+}
+
+// Enroll
+// @Summary Начать настройку 2FA
+// @Description Генерирует секрет и возвращает otpauth ссылку и QR-код (PNG в base64) для приложения-аутентификатора. 2FA включится после подтверждения кодом
+// @Tags 2fa
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.TwoFactorEnrollResponse
+// @Failure 401 {object} dto.ApiError
+// @Failure 409 {object} dto.ApiError "2FA уже включена"
+// @Failure 500 {object} dto.ApiError
+// @Router /profile/2fa/enroll [post]
+func (h *Handler) Enroll(c *gin.Context) {
+	userUUID, err := uuid.Parse(c.GetString(constant.CtxUserUUID))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, dto.NewApiError("unauthorized"))
+		return
+	}
+
+	enrollment, err := h.service.Enroll(c.Request.Context(), userUUID)
+	switch {
+	case errors.Is(err, domain.ErrTwoFactorEnabled):
+		c.JSON(http.StatusConflict, dto.NewApiError("2FA уже включена"))
+	case err != nil:
+		slog.Error("failed to enroll two-factor", slog.String("err", err.Error()))
+		c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
+	default:
+		c.JSON(http.StatusOK, dto.TwoFactorEnrollResponse{
+			Secret: enrollment.Secret,
+			URI:    enrollment.URI,
+			QRCode: base64.StdEncoding.EncodeToString(enrollment.QRCode),
+		})
+	}
+}
+
+// Confirm
+// @Summary Подтвердить и включить 2FA
+// @Description Включает 2FA по первому коду из приложения и возвращает одноразовые коды восстановления. Коды показываются только один раз
+// @Tags 2fa
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.TwoFactorCodeRequest true "Код из приложения"
+// @Success 200 {object} dto.RecoveryCodesResponse
+// @Failure 400 {object} dto.ApiError "Неверный код или настройка не начата"
+// @Failure 401 {object} dto.ApiError
+// @Failure 409 {object} dto.ApiError "2FA уже включена"
+// @Failure 429 {object} dto.ApiError "Слишком много неверных кодов 2FA, повторить через Retry-After секунд"
+// @Failure 500 {object} dto.ApiError
+// @Router /profile/2fa/confirm [post]
+func (h *Handler) Confirm(c *gin.Context) {
+	userUUID, err := uuid.Parse(c.GetString(constant.CtxUserUUID))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, dto.NewApiError("unauthorized"))
+		return
+	}
+
+	var req dto.TwoFactorCodeRequest
+	if err = c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewApiError("некорекктное тело запроса"))
+		return
+	}
+
+	codes, err := h.service.Confirm(c.Request.Context(), userUUID, req.Code)
+
+	var retryErr *domain.RetryAfterError
+	switch {
+	case errors.As(err, &retryErr):
+		middleware.AbortTooManyRequests(c, retryErr)
+	case errors.Is(err, domain.ErrTwoFactorEnabled):
+		c.JSON(http.StatusConflict, dto.NewApiError("2FA уже включена"))
+	case errors.Is(err, domain.ErrTwoFactorDisabled):
+		c.JSON(http.StatusBadRequest, dto.NewApiError("сначала начните настройку 2FA"))
+	case errors.Is(err, domain.ErrInvalidTwoFactorCode):
+		c.JSON(http.StatusBadRequest, dto.NewApiError("неверный код"))
+	case err != nil:
+		slog.Error("failed to confirm two-factor", slog.String("err", err.Error()))
+		c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
+	default:
+		c.JSON(http.StatusOK, dto.RecoveryCodesResponse{RecoveryCodes: codes})
+	}
+}
+
+// Disable
+// @Summary Выключить 2FA
+// @Tags 2fa
+// @Accept json
+// @Security BearerAuth
+// @Param request body dto.TwoFactorCodeRequest true "Код из приложения или код восстановления"
+// @Success 204
+// @Failure 400 {object} dto.ApiError "2FA не включена"
+// @Failure 401 {object} dto.ApiError
+// @Failure 403 {object} dto.ApiError "Неверный код"
+// @Failure 429 {object} dto.ApiError "Слишком много неверных кодов 2FA, повторить через Retry-After секунд"
+// @Failure 500 {object} dto.ApiError
+// @Router /profile/2fa/disable [post]
+func (h *Handler) Disable(c *gin.Context) {
+	userUUID, err := uuid.Parse(c.GetString(constant.CtxUserUUID))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, dto.NewApiError("unauthorized"))
+		return
+	}
+
+	var req dto.TwoFactorCodeRequest
+	if err = c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewApiError("некорекктное тело запроса"))
+		return
+	}
+
+	err = h.service.Disable(c.Request.Context(), userUUID, req.Code)
+
+	var retryErr *domain.RetryAfterError
+	switch {
+	case errors.As(err, &retryErr):
+		middleware.AbortTooManyRequests(c, retryErr)
+	case errors.Is(err, domain.ErrTwoFactorDisabled):
+		c.JSON(http.StatusBadRequest, dto.NewApiError("2FA не включена"))
+	case errors.Is(err, domain.ErrInvalidTwoFactorCode), errors.Is(err, domain.ErrTwoFactorRequired):
+		c.JSON(http.StatusForbidden, dto.NewApiError("неверный код"))
+	case err != nil:
+		slog.Error("failed to disable two-factor", slog.String("err", err.Error()))
+		c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
+	default:
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// RegenerateRecoveryCodes
+// @Summary Новые коды восстановления
+// @Description Выдает новый набор кодов восстановления, старые перестают действовать
+// @Tags 2fa
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.TwoFactorCodeRequest true "Код из приложения или код восстановления"
+// @Success 200 {object} dto.RecoveryCodesResponse
+// @Failure 400 {object} dto.ApiError "2FA не включена"
+// @Failure 401 {object} dto.ApiError
+// @Failure 403 {object} dto.ApiError "Неверный код"
+// @Failure 429 {object} dto.ApiError "Слишком много неверных кодов 2FA, повторить через Retry-After секунд"
+// @Failure 500 {object} dto.ApiError
+// @Router /profile/2fa/recovery-codes [post]
+func (h *Handler) RegenerateRecoveryCodes(c *gin.Context) {
+	userUUID, err := uuid.Parse(c.GetString(constant.CtxUserUUID))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, dto.NewApiError("unauthorized"))
+		return
+	}
+
+	var req dto.TwoFactorCodeRequest
+	if err = c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewApiError("некорекктное тело запроса"))
+		return
+	}
+
+	codes, err := h.service.RegenerateRecoveryCodes(c.Request.Context(), userUUID, req.Code)
+
+	var retryErr *domain.RetryAfterError
+	switch {
+	case errors.As(err, &retryErr):
+		middleware.AbortTooManyRequests(c, retryErr)
+	case errors.Is(err, domain.ErrTwoFactorDisabled):
+		c.JSON(http.StatusBadRequest, dto.NewApiError("2FA не включена"))
+	case errors.Is(err, domain.ErrInvalidTwoFactorCode), errors.Is(err, domain.ErrTwoFactorRequired):
+		c.JSON(http.StatusForbidden, dto.NewApiError("неверный код"))
+	case err != nil:
+		slog.Error("failed to regenerate recovery codes", slog.String("err", err.Error()))
+		c.JSON(http.StatusInternalServerError, dto.NewApiError(err.Error()))
+	default:
+		c.JSON(http.StatusOK, dto.RecoveryCodesResponse{RecoveryCodes: codes})
+	}
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/musicman-backend/internal/domain/constant"
+	"github.com/musicman-backend/internal/domain/entity"
+	"github.com/musicman-backend/internal/http/dto"
+)
+
+type UserGetter interface {
+	GetUserByUUID(ctx context.Context, userUUID uuid.UUID) (entity.User, error)
+}
+
+// RequireAdmin пропускает только админов. Роли нет в JWT, поэтому она читается из базы,
+// и снятие роли действует сразу. Ставится после AuthMiddleware
+func RequireAdmin(users UserGetter) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userUUID, err := uuid.Parse(ctx.GetString(constant.CtxUserUUID))
+		if err != nil {
+			slog.Error("failed to parse user uuid", slog.String("err", err.Error()))
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		user, err := users.GetUserByUUID(ctx, userUUID)
+		if err != nil {
+			slog.Error("failed to get user", slog.String("err", err.Error()))
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		if user.Role != constant.RoleAdmin {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, dto.NewApiError("доступно только администраторам"))
+			return
+		}
+
+		ctx.Next()
+	}
+}
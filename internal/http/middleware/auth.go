@@ -2,11 +2,13 @@ package middleware
 
 import (
 	"context"
+	"errors"
 	"log/slog"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/musicman-backend/internal/domain"
 	"github.com/musicman-backend/internal/domain/constant"
 	"github.com/musicman-backend/internal/domain/entity"
 	"github.com/musicman-backend/internal/http/dto"
@@ -17,7 +19,12 @@ type TokenVerifier interface {
 	VerifyToken(ctx context.Context, tokenString string) (entity.JWTClaims, error)
 }
 
-func AuthMiddleware(verifier TokenVerifier) gin.HandlerFunc {
+// SessionChecker проверяет, что сессия токена не отозвана
+type SessionChecker interface {
+	Check(ctx context.Context, claims entity.JWTClaims, ip string) error
+}
+
+func AuthMiddleware(verifier TokenVerifier, sessions SessionChecker) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		token, err := token_extracter.ExtractToken(ctx.Request)
 		if err != nil {
@@ -39,8 +46,30 @@ func AuthMiddleware(verifier TokenVerifier) gin.HandlerFunc {
 			return
 		}
 
+		err = sessions.Check(ctx, claims, ctx.ClientIP())
+		if errors.Is(err, domain.ErrInvalidToken) {
+			slog.Warn("session revoked or expired", slog.String("session", claims.SessionID.String()))
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, dto.NewApiError("сессия завершена, войдите заново"))
+			return
+		}
+		if err != nil {
+			slog.Error("failed to check session", slog.String("err", err.Error()))
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
 		ctx.Set(constant.CtxUserUUID, claims.UserUUID.String())
+		ctx.Set(constant.CtxSessionID, claims.SessionID.String())
 		ctx.Set(constant.CtxUserLogin, claims.Login)
 		ctx.Next()
 	}
 }
+
+// ClientInfo - устройство, с которого пришел запрос на вход
+func ClientInfo(ctx *gin.Context, deviceName string) entity.ClientInfo {
+	return entity.ClientInfo{
+		DeviceName: deviceName,
+		UserAgent:  ctx.Request.UserAgent(),
+		IP:         ctx.ClientIP(),
+	}
+}
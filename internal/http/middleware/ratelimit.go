@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/musicman-backend/internal/domain"
+	"github.com/musicman-backend/internal/http/dto"
+)
+
+// RateLimitMiddleware ограничивает частоту запросов с одного ip.
+// allow возвращает *domain.RetryAfterError, если лимит исчерпан
+func RateLimitMiddleware(allow func(ctx context.Context, ip string) error) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		err := allow(ctx, ctx.ClientIP())
+
+		var retryErr *domain.RetryAfterError
+		if errors.As(err, &retryErr) {
+			slog.Warn("rate limit exceeded",
+				slog.String("ip", ctx.ClientIP()),
+				slog.String("path", ctx.FullPath()),
+			)
+			AbortTooManyRequests(ctx, retryErr)
+			return
+		}
+
+		// недоступное хранилище счетчиков не должно ломать вход
+		if err != nil {
+			slog.Error("failed to check rate limit", slog.String("err", err.Error()))
+		}
+
+		ctx.Next()
+	}
+}
+
+// AbortTooManyRequests отвечает 429 с заголовком Retry-After в целых секундах
+func AbortTooManyRequests(ctx *gin.Context, err *domain.RetryAfterError) {
+	seconds := int(math.Ceil(err.RetryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+
+	ctx.Header("Retry-After", strconv.Itoa(seconds))
+	ctx.AbortWithStatusJSON(http.StatusTooManyRequests, dto.NewApiError("слишком много запросов, попробуйте позже"))
+}
@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/musicman-backend/internal/domain"
+	"github.com/musicman-backend/internal/domain/constant"
+	"github.com/musicman-backend/internal/http/dto"
+)
+
+type SensitiveChecker interface {
+	CheckSensitive(ctx context.Context, userUUID uuid.UUID, code string) error
+}
+
+// RequireTwoFactor требует свежий код 2FA в заголовке X-2FA-Code, если у пользователя включена 2FA.
+// Ставится после AuthMiddleware
+func RequireTwoFactor(checker SensitiveChecker) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userUUID, err := uuid.Parse(ctx.GetString(constant.CtxUserUUID))
+		if err != nil {
+			slog.Error("failed to parse user uuid", slog.String("err", err.Error()))
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		err = checker.CheckSensitive(ctx, userUUID, ctx.GetHeader(constant.HeaderTwoFactorCode))
+
+		var retryErr *domain.RetryAfterError
+		if errors.As(err, &retryErr) {
+			AbortTooManyRequests(ctx, retryErr)
+			return
+		}
+
+		if errors.Is(err, domain.ErrTwoFactorRequired) || errors.Is(err, domain.ErrInvalidTwoFactorCode) {
+			AbortTwoFactor(ctx, err)
+			return
+		}
+
+		if err != nil {
+			slog.Error("failed to check two-factor code", slog.String("err", err.Error()))
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// AbortTwoFactor отвечает 403 на отсутствующий или неверный код 2FA
+func AbortTwoFactor(ctx *gin.Context, err error) {
+	if errors.Is(err, domain.ErrTwoFactorRequired) {
+		ctx.AbortWithStatusJSON(http.StatusForbidden, dto.NewApiError("нужен код двухфакторной аутентификации"))
+		return
+	}
+
+	slog.Warn("invalid two-factor code", slog.String("path", ctx.FullPath()))
+	ctx.AbortWithStatusJSON(http.StatusForbidden, dto.NewApiError("неверный код двухфакторной аутентификации"))
+}
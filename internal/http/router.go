@@ -1,11 +1,26 @@
 package http
 
 import (
+	"fmt"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/musicman-backend/config"
+	"github.com/musicman-backend/internal/http/handler/analytics"
+	"github.com/musicman-backend/internal/http/handler/archive"
+	"github.com/musicman-backend/internal/http/handler/author"
+	"github.com/musicman-backend/internal/http/handler/collection"
+	"github.com/musicman-backend/internal/http/handler/earnings"
+	"github.com/musicman-backend/internal/http/handler/facets"
+	"github.com/musicman-backend/internal/http/handler/files"
+	"github.com/musicman-backend/internal/http/handler/likes"
 	"github.com/musicman-backend/internal/http/handler/music"
+	"github.com/musicman-backend/internal/http/handler/oauth"
 	"github.com/musicman-backend/internal/http/handler/payment"
 	"github.com/musicman-backend/internal/http/handler/purchase"
+	"github.com/musicman-backend/internal/http/handler/recommendation"
+	"github.com/musicman-backend/internal/http/handler/session"
+	"github.com/musicman-backend/internal/http/handler/taxonomy"
+	"github.com/musicman-backend/internal/http/handler/twofactor"
 	swaggerFiles "github.com/swaggo/files"
 	"log/slog"
 	"time"
@@ -15,16 +30,22 @@ import (
 	"github.com/musicman-backend/internal/http/handler/health"
 	"github.com/musicman-backend/internal/http/handler/profile"
 	"github.com/musicman-backend/internal/http/middleware"
+	"github.com/musicman-backend/internal/repository/local"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
-func SetupRouter(container *di.Container) *gin.Engine {
+func SetupRouter(cfg *config.Config, container *di.Container) (*gin.Engine, error) {
 	router := gin.New()
 
+	// по умолчанию gin верит X-Forwarded-For от кого угодно, и лимиты по ip обходятся подменой заголовка
+	if err := router.SetTrustedProxies(cfg.Http.TrustedProxies); err != nil {
+		return nil, fmt.Errorf("invalid trusted proxies: %w", err)
+	}
+
 	router.Use(cors.New(cors.Config{
 		AllowAllOrigins:  true,
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Length", "Content-Type", "Authorization"},
+		AllowHeaders:     []string{"Origin", "Content-Length", "Content-Type", "Authorization", "X-2FA-Code"},
 		AllowCredentials: false,
 		MaxAge:           12 * time.Hour,
 	}))
@@ -35,6 +56,13 @@ func SetupRouter(container *di.Container) *gin.Engine {
 
 	router.GET("/docs/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
+	// локальное хранилище раздает файлы само, у MinIO свои presigned ссылки
+	if resolver, ok := container.Repository.FileRepository.(files.SignedFileResolver); ok {
+		filesHandler := files.New(resolver)
+		router.GET(local.FilesPath+"/:bucket/*key", filesHandler.GetFile)
+		slog.Info("local file serving enabled")
+	}
+
 	apiV1 := router.Group("/api/v1")
 	apiV1.Use(
 		gin.Recovery(),
@@ -44,38 +72,165 @@ func SetupRouter(container *di.Container) *gin.Engine {
 	authGroup := apiV1.Group("/auth")
 	{
 		authHandler := auth.NewHandler(container.Service.Auth)
-		authGroup.POST("/sign-up", authHandler.Register)
-		authGroup.POST("/sign-in", authHandler.Login)
+		authGroup.POST("/sign-up", middleware.RateLimitMiddleware(container.Service.RateLimit.AllowSignUp), authHandler.Register)
+		authGroup.POST("/sign-in", middleware.RateLimitMiddleware(container.Service.RateLimit.AllowSignIn), authHandler.Login)
+		authGroup.POST("/verify-email", authHandler.VerifyEmail)
+		authGroup.POST("/forgot-password", middleware.RateLimitMiddleware(container.Service.RateLimit.AllowForgotPassword), authHandler.ForgotPassword)
+		authGroup.POST("/reset-password", authHandler.ResetPassword)
+		authGroup.POST("/2fa", middleware.RateLimitMiddleware(container.Service.RateLimit.AllowSignIn), authHandler.LoginTwoFactor)
+
+		oauthHandler := oauth.New(container.Service.OAuth)
+		authGroup.GET("/oauth/providers", oauthHandler.GetProviders)
+		authGroup.GET("/oauth/:provider", oauthHandler.StartLogin)
+		authGroup.POST("/oauth/:provider/callback", middleware.RateLimitMiddleware(container.Service.RateLimit.AllowSignIn), oauthHandler.Callback)
 	}
 
-	authMiddleware := middleware.AuthMiddleware(container.Service.Token)
+	authMiddleware := middleware.AuthMiddleware(container.Service.Token, container.Service.Session)
+
+	musicHandler := music.New(container.Service.Music, container.Service.Purchase, container.Service.Likes, container.Service.Analytics, cfg.Uploads.TempDir)
+	likesHandler := likes.New(container.Service.Likes, musicHandler)
+	collectionHandler := collection.New(container.Service.Collection, musicHandler)
+	analyticsHandler := analytics.New(container.Service.Analytics)
+	recommendationHandler := recommendation.New(container.Service.Recommend, musicHandler)
+	taxonomyHandler := taxonomy.New(container.Service.Taxonomy)
+	facetsHandler := facets.New(container.Service.Facets)
+	archiveHandler := archive.New(container.Service.Archive)
 
 	profileGroup := apiV1.Group("/profile")
 	profileGroup.Use(authMiddleware)
 	{
-		profileHandler := profile.NewHandler(container.Repository.UserRepository)
+		requireTwoFactor := middleware.RequireTwoFactor(container.Service.TwoFactor)
+
+		profileHandler := profile.NewHandler(container.Service.Profile, container.Service.Auth, container.Service.Auth, cfg.Uploads.TempDir)
 		profileGroup.GET("/me", profileHandler.GetMyProfile)
+		profileGroup.PATCH("/me", profileHandler.UpdateMyProfile)
+		profileGroup.DELETE("/me", requireTwoFactor, profileHandler.DeleteAccount)
+		profileGroup.POST("/email", requireTwoFactor, profileHandler.ChangeEmail)
+		profileGroup.POST("/password", requireTwoFactor, profileHandler.ChangePassword)
+		profileGroup.POST("/login", requireTwoFactor, profileHandler.ChangeLogin)
+
+		oauthHandler := oauth.New(container.Service.OAuth)
+		profileGroup.GET("/identities", oauthHandler.GetIdentities)
+		profileGroup.POST("/identities/:provider", oauthHandler.LinkIdentity)
+		profileGroup.DELETE("/identities/:provider", oauthHandler.UnlinkIdentity)
+
+		sessionHandler := session.New(container.Service.Session)
+		profileGroup.GET("/sessions", sessionHandler.GetSessions)
+		profileGroup.DELETE("/sessions/:id", sessionHandler.RevokeSession)
+
+		twoFactorHandler := twofactor.New(container.Service.TwoFactor)
+		profileGroup.GET("/2fa", twoFactorHandler.GetStatus)
+		profileGroup.POST("/2fa/enroll", twoFactorHandler.Enroll)
+		profileGroup.POST("/2fa/confirm", twoFactorHandler.Confirm)
+		profileGroup.POST("/2fa/disable", twoFactorHandler.Disable)
+		profileGroup.POST("/2fa/recovery-codes", twoFactorHandler.RegenerateRecoveryCodes)
+
+		earningsHandler := earnings.New(container.Service.Earnings)
+		profileGroup.GET("/earnings", earningsHandler.GetEarnings)
+		profileGroup.GET("/payouts", earningsHandler.GetPayouts)
+		profileGroup.POST("/payouts", requireTwoFactor, earningsHandler.RequestPayout)
+
+		profileGroup.GET("/likes", likesHandler.GetLiked)
 	}
 
-	musicHandler := music.New(container.Service.Music, container.Service.Purchase)
+	adminGroup := apiV1.Group("/admin")
+	adminGroup.Use(authMiddleware, middleware.RequireAdmin(container.Repository.UserRepository))
+	{
+		earningsHandler := earnings.New(container.Service.Earnings)
+		adminGroup.GET("/payouts", earningsHandler.ListPayouts)
+		adminGroup.POST("/payouts/:id/approve", earningsHandler.ApprovePayout)
+		adminGroup.POST("/payouts/:id/reject", earningsHandler.RejectPayout)
+		adminGroup.PUT("/authors/:uuid/commission", earningsHandler.SetCommission)
+
+		adminGroup.GET("/samples/pending", musicHandler.GetPendingSamples)
+		adminGroup.POST("/samples/:id/approve", musicHandler.ApproveSample)
+		adminGroup.POST("/samples/:id/reject", musicHandler.RejectSample)
+
+		adminGroup.GET("/stats", analyticsHandler.GetStats)
+
+		adminGroup.POST("/genres", taxonomyHandler.CreateGenre)
+		adminGroup.PATCH("/genres/:slug", taxonomyHandler.UpdateGenre)
+		adminGroup.POST("/genres/:slug/merge", taxonomyHandler.MergeGenre)
+		adminGroup.POST("/tags", taxonomyHandler.CreateTag)
+		adminGroup.PATCH("/tags/:id", taxonomyHandler.RenameTag)
+		adminGroup.POST("/tags/:id/merge", taxonomyHandler.MergeTag)
+	}
 
 	apiV1.Group("/samples").
 		Use(authMiddleware).
 		GET("", musicHandler.GetSamples).
+		GET("/facets", facetsHandler.GetFacets).
 		GET("/:id", musicHandler.GetSample).
+		GET("/:id/download", musicHandler.DownloadSample).
+		GET("/:id/similar", recommendationHandler.GetSimilar).
 		PUT("/:id", musicHandler.UpdateSample).
+		PUT("/:id/licenses", musicHandler.SetLicenses).
+		POST("/:id/submit", musicHandler.SubmitSample).
+		POST("/:id/like", likesHandler.LikeSample).
+		DELETE("/:id/like", likesHandler.UnlikeSample).
 		POST("/:id", musicHandler.UploadAudio).
 		DELETE("/:id", musicHandler.DeleteSample).
-		POST("", musicHandler.CreateSample)
+		POST("", musicHandler.CreateSample).
+		POST("/:id/restore", musicHandler.RestoreSample).
+		GET("/:id/versions", musicHandler.GetSampleVersions).
+		POST("/:id/versions/:version/rollback", musicHandler.RollbackSample)
 
 	apiV1.Group("/packs").
 		Use(authMiddleware).
 		GET("", musicHandler.GetPacks).
 		GET("/:id", musicHandler.GetPack).
-		PUT("", musicHandler.UpdatePack).
+		GET("/:id/download", archiveHandler.DownloadPack).
+		PUT("/:id", musicHandler.UpdatePack).
+		PUT("/:id/cover", musicHandler.SetPackCover).
+		DELETE("/:id/cover", musicHandler.RemovePackCover).
+		POST("/:id/samples", musicHandler.AddPackSample).
+		PUT("/:id/samples", musicHandler.ReorderPackSamples).
+		DELETE("/:id/samples/:sample_id", musicHandler.RemovePackSample).
 		DELETE("/:id", musicHandler.DeletePack).
+		POST("/:id/restore", musicHandler.RestorePack).
+		POST("/:id/like", likesHandler.LikePack).
+		DELETE("/:id/like", likesHandler.UnlikePack).
 		POST("", musicHandler.CreatePack)
 
+	authorHandler := author.New(container.Service.Author, container.Service.Profile, musicHandler)
+	apiV1.Group("/authors").
+		Use(authMiddleware).
+		GET("/me", authorHandler.GetDashboard).
+		GET("/me/samples", authorHandler.GetOwnSamples).
+		GET("/me/packs", authorHandler.GetOwnPacks).
+		GET("/me/stats", analyticsHandler.GetAuthorStats).
+		GET("/:login", authorHandler.GetAuthor).
+		GET("/:login/samples", authorHandler.GetAuthorSamples).
+		GET("/:login/packs", authorHandler.GetAuthorPacks).
+		GET("/:login/collections", collectionHandler.GetAuthorCollections)
+
+	apiV1.Group("/collections").
+		Use(authMiddleware).
+		GET("", collectionHandler.GetCollections).
+		POST("", collectionHandler.CreateCollection).
+		GET("/:id", collectionHandler.GetCollection).
+		PATCH("/:id", collectionHandler.UpdateCollection).
+		DELETE("/:id", collectionHandler.DeleteCollection).
+		POST("/:id/samples", collectionHandler.AddSample).
+		PUT("/:id/samples", collectionHandler.ReorderSamples).
+		DELETE("/:id/samples/:sample_id", collectionHandler.RemoveSample)
+
+	apiV1.Group("/genres").
+		Use(authMiddleware).
+		GET("", taxonomyHandler.GetGenres)
+
+	apiV1.Group("/tags").
+		Use(authMiddleware).
+		GET("", taxonomyHandler.GetTags)
+
+	apiV1.Group("/feed").
+		Use(authMiddleware).
+		GET("", recommendationHandler.GetFeed)
+
+	apiV1.Group("/trash").
+		Use(authMiddleware).
+		GET("", musicHandler.GetTrash)
+
 	paymentsGroup := apiV1.Group("/payments")
 	paymentsGroup.Use(authMiddleware)
 	{
@@ -96,7 +251,17 @@ func SetupRouter(container *di.Container) *gin.Engine {
 	purchasesGroup.Use(authMiddleware)
 	{
 		purchasesGroup.GET("", purchaseHandler.GetUserPurchases)
+		purchasesGroup.PUT("/:id/updates", purchaseHandler.SetFollowUpdates)
+		purchasesGroup.GET("/:id/license", purchaseHandler.GetLicenseCertificate)
+		purchasesGroup.GET("/:id/receipt", purchaseHandler.GetReceipt)
+	}
+
+	// Проверка чеков доступна без авторизации
+	receiptsGroup := apiV1.Group("/receipts")
+	{
+		receiptsGroup.GET("/key", purchaseHandler.GetSigningKey)
+		receiptsGroup.POST("/verify", purchaseHandler.VerifyReceipt)
 	}
 
-	return router
+	return router, nil
 }
@@ -0,0 +1,45 @@
+package upload
+
+import (
+	"fmt"
+	"io"
+	"mime/multipart"
+	"os"
+
+	"github.com/musicman-backend/internal/domain/constant"
+)
+
+// SaveTempFile сохраняет загруженный файл во временный каталог под уникальным именем.
+// Пустой tempDir - системный temp. Файл удаляет вызывающий
+func SaveTempFile(file *multipart.FileHeader, tempDir string) (string, error) {
+	tmp, err := os.CreateTemp(tempDir, constant.UploadTempPattern)
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp file: %w", err)
+	}
+	filePath := tmp.Name()
+	_ = tmp.Close()
+
+	src, err := file.Open()
+	if err != nil {
+		_ = os.Remove(filePath)
+		return "", fmt.Errorf("failed to open uploaded file: %w", err)
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(filePath, os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		_ = os.Remove(filePath)
+		return "", fmt.Errorf("failed to open temp file: %w", err)
+	}
+
+	_, err = io.Copy(dst, src)
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(filePath)
+		return "", fmt.Errorf("failed to write temp file: %w", err)
+	}
+
+	return filePath, nil
+}
@@ -0,0 +1,24 @@
+package local
+
+import (
+	"context"
+	"testing"
+
+	"github.com/musicman-backend/config"
+	"github.com/musicman-backend/internal/repository/storagetest"
+)
+
+func TestStorage(t *testing.T) {
+	storage, err := NewLocal(config.LocalStorage{
+		Root:    t.TempDir(),
+		BaseURL: "http://localhost:8080",
+		Secret:  "storagetest",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = storagetest.TestStorage(context.Background(), storage); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,313 @@
+package local
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/musicman-backend/config"
+	"github.com/musicman-backend/internal/domain"
+	"github.com/musicman-backend/internal/domain/entity"
+)
+
+const (
+	// FilesPath префикс ссылок на файлы, по нему роутер вешает раздачу
+	FilesPath = "/files"
+
+	uploadTempPrefix = ".upload-"
+	defaultURLTTL    = time.Hour
+)
+
+// Local хранит объекты файлами в каталоге root/<bucket>/<key>.
+// Ссылки на скачивание подписываются HMAC и отдаются самим приложением
+type Local struct {
+	root    string
+	baseURL string
+	secret  []byte
+	ttl     time.Duration
+}
+
+func NewLocal(cfg config.LocalStorage) (*Local, error) {
+	if cfg.Root == "" {
+		return nil, fmt.Errorf("local storage root is required")
+	}
+	if cfg.Secret == "" {
+		return nil, fmt.Errorf("local storage secret is required")
+	}
+
+	root, err := filepath.Abs(cfg.Root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve storage root: %w", err)
+	}
+	if err = os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create storage root: %w", err)
+	}
+
+	ttl := cfg.URLTTL
+	if ttl <= 0 {
+		ttl = defaultURLTTL
+	}
+
+	return &Local{
+		root:    root,
+		baseURL: strings.TrimRight(cfg.BaseURL, "/"),
+		secret:  []byte(cfg.Secret),
+		ttl:     ttl,
+	}, nil
+}
+
+func (l *Local) UploadFile(ctx context.Context, bucketName string, objectName string, filePath string) error {
+	dst, err := l.objectPath(bucketName, objectName)
+	if err != nil {
+		return err
+	}
+	if _, err = os.Stat(l.bucketPath(bucketName)); err != nil {
+		return fmt.Errorf("failed to upload file: bucket %s: %w", bucketName, err)
+	}
+
+	src, err := os.Open(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	defer src.Close()
+
+	if err = os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return fmt.Errorf("failed to create object dir: %w", err)
+	}
+
+	// пишем во временный файл рядом и переименовываем, чтобы читатели не увидели недописанный объект
+	tmp, err := os.CreateTemp(filepath.Dir(dst), uploadTempPrefix+"*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = io.Copy(tmp, src)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to upload file: %w", err)
+	}
+
+	if err = os.Rename(tmp.Name(), dst); err != nil {
+		return fmt.Errorf("failed to upload file: %w", err)
+	}
+
+	return nil
+}
+
+func (l *Local) DownloadFile(ctx context.Context, bucketName string, objectName string, filePath string) error {
+	srcPath, err := l.objectPath(bucketName, objectName)
+	if err != nil {
+		return err
+	}
+
+	src, err := os.Open(srcPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return domain.ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to download file: %w", err)
+	}
+	defer src.Close()
+
+	dst, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+
+	_, err = io.Copy(dst, src)
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to download file: %w", err)
+	}
+
+	return nil
+}
+
+func (l *Local) OpenFile(ctx context.Context, bucketName string, objectName string) (io.ReadCloser, error) {
+	objectPath, err := l.objectPath(bucketName, objectName)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(objectPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+
+	return file, nil
+}
+
+func (l *Local) GetFileURL(ctx context.Context, bucketName string, objectName string) (string, error) {
+	if _, err := l.objectPath(bucketName, objectName); err != nil {
+		return "", err
+	}
+
+	expires := strconv.FormatInt(time.Now().Add(l.ttl).Unix(), 10)
+
+	query := url.Values{}
+	query.Set("expires", expires)
+	query.Set("signature", l.sign(bucketName, objectName, expires))
+
+	return l.baseURL + FilesPath + "/" + url.PathEscape(bucketName) + "/" + escapeKey(objectName) + "?" + query.Encode(), nil
+}
+
+func (l *Local) DeleteFile(ctx context.Context, bucketName string, objectName string) error {
+	objectPath, err := l.objectPath(bucketName, objectName)
+	if err != nil {
+		return err
+	}
+
+	// как и в MinIO, удаление несуществующего объекта не ошибка
+	if err = os.Remove(objectPath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+
+	return nil
+}
+
+func (l *Local) ListFiles(ctx context.Context, bucketName string) ([]entity.FileInfo, error) {
+	bucketPath := l.bucketPath(bucketName)
+
+	var files []entity.FileInfo
+	err := filepath.WalkDir(bucketPath, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || strings.HasPrefix(d.Name(), uploadTempPrefix) {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(bucketPath, p)
+		if err != nil {
+			return err
+		}
+
+		files = append(files, entity.FileInfo{
+			Key:          filepath.ToSlash(rel),
+			Size:         info.Size(),
+			LastModified: info.ModTime(),
+		})
+
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files: %w", err)
+	}
+
+	return files, nil
+}
+
+func (l *Local) CreateBucketIfNotExists(ctx context.Context, bucketName string) error {
+	if err := validateBucket(bucketName); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(l.bucketPath(bucketName), 0o755); err != nil {
+		return fmt.Errorf("failed to create bucket: %w", err)
+	}
+
+	return nil
+}
+
+// ResolveSignedFile проверяет подпись ссылки из GetFileURL и возвращает путь к файлу на диске
+func (l *Local) ResolveSignedFile(bucketName, objectName, expires, signature string) (string, error) {
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return "", domain.ErrInvalidSignature
+	}
+
+	expected := l.sign(bucketName, objectName, expires)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return "", domain.ErrInvalidSignature
+	}
+	if time.Now().Unix() > expiresAt {
+		return "", domain.ErrLinkExpired
+	}
+
+	objectPath, err := l.objectPath(bucketName, objectName)
+	if err != nil {
+		return "", err
+	}
+
+	info, err := os.Stat(objectPath)
+	if errors.Is(err, fs.ErrNotExist) || (err == nil && info.IsDir()) {
+		return "", domain.ErrNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to stat file: %w", err)
+	}
+
+	return objectPath, nil
+}
+
+func (l *Local) sign(bucketName, objectName, expires string) string {
+	mac := hmac.New(sha256.New, l.secret)
+	mac.Write([]byte(bucketName + "/" + objectName + "\n" + expires))
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (l *Local) bucketPath(bucketName string) string {
+	return filepath.Join(l.root, bucketName)
+}
+
+// objectPath не дает ключу выйти за пределы бакета
+func (l *Local) objectPath(bucketName, objectName string) (string, error) {
+	if err := validateBucket(bucketName); err != nil {
+		return "", err
+	}
+	if objectName == "" || strings.HasPrefix(objectName, "/") || path.Clean(objectName) != objectName ||
+		strings.HasPrefix(objectName, "../") || objectName == ".." {
+		return "", fmt.Errorf("invalid object name: %q", objectName)
+	}
+	if strings.HasPrefix(path.Base(objectName), uploadTempPrefix) {
+		return "", fmt.Errorf("invalid object name: %q", objectName)
+	}
+
+	return filepath.Join(l.bucketPath(bucketName), filepath.FromSlash(objectName)), nil
+}
+
+func validateBucket(bucketName string) error {
+	if bucketName == "" || bucketName == "." || bucketName == ".." || strings.ContainsAny(bucketName, `/\`) {
+		return fmt.Errorf("invalid bucket name: %q", bucketName)
+	}
+
+	return nil
+}
+
+func escapeKey(objectName string) string {
+	parts := strings.Split(objectName, "/")
+	for i, part := range parts {
+		parts[i] = url.PathEscape(part)
+	}
+
+	return strings.Join(parts, "/")
+}
@@ -3,12 +3,26 @@ package repository
 import (
 	"context"
 	"fmt"
+	"io"
 
 	"github.com/musicman-backend/cmd/migrator"
+	"github.com/musicman-backend/internal/domain/entity"
+	"github.com/musicman-backend/internal/repository/local"
+	"github.com/musicman-backend/internal/repository/memory"
 	"github.com/musicman-backend/internal/repository/minio"
+	"github.com/musicman-backend/internal/repository/postgres/analytics"
+	"github.com/musicman-backend/internal/repository/postgres/authors"
+	"github.com/musicman-backend/internal/repository/postgres/collections"
+	"github.com/musicman-backend/internal/repository/postgres/earnings"
+	"github.com/musicman-backend/internal/repository/postgres/identities"
+	"github.com/musicman-backend/internal/repository/postgres/likes"
 	"github.com/musicman-backend/internal/repository/postgres/music"
 	"github.com/musicman-backend/internal/repository/postgres/payments"
 	"github.com/musicman-backend/internal/repository/postgres/purchases"
+	"github.com/musicman-backend/internal/repository/postgres/ratelimit"
+	"github.com/musicman-backend/internal/repository/postgres/recommendations"
+	"github.com/musicman-backend/internal/repository/postgres/sessions"
+	"github.com/musicman-backend/internal/repository/postgres/taxonomy"
 	"github.com/musicman-backend/internal/repository/postgres/users"
 
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -17,13 +31,38 @@ import (
 	"github.com/musicman-backend/internal/repository/postgres"
 )
 
+// FileStorage - файловое хранилище, реализации: minio, local, memory.
+// Все они должны проходить storagetest.TestStorage
+type FileStorage interface {
+	UploadFile(ctx context.Context, bucketName, objectName, filePath string) error
+	DownloadFile(ctx context.Context, bucketName, objectName, filePath string) error
+	OpenFile(ctx context.Context, bucketName, objectName string) (io.ReadCloser, error)
+	GetFileURL(ctx context.Context, bucketName, objectName string) (string, error)
+	DeleteFile(ctx context.Context, bucketName, objectName string) error
+	ListFiles(ctx context.Context, bucketName string) ([]entity.FileInfo, error)
+	CreateBucketIfNotExists(ctx context.Context, bucketName string) error
+}
+
 type Manager struct {
-	UserRepository     *users.Repository
-	PackRepository     *music.Pack
-	SampleRepository   *music.Sample
-	FileRepository     *minio.Minio
-	PaymentRepository  *payments.Repository
-	PurchaseRepository *purchases.Repository
+	UserRepository           *users.Repository
+	UserTokenRepository      *users.Tokens
+	RecoveryCodeRepository   *users.RecoveryCodes
+	PackRepository           *music.Pack
+	SampleRepository         *music.Sample
+	VersionRepository        *music.SampleVersion
+	FileRepository           FileStorage
+	PaymentRepository        *payments.Repository
+	PurchaseRepository       *purchases.Repository
+	RateLimitRepository      *ratelimit.Repository
+	IdentityRepository       *identities.Repository
+	SessionRepository        *sessions.Repository
+	AuthorRepository         *authors.Repository
+	EarningsRepository       *earnings.Repository
+	LikeRepository           *likes.Repository
+	CollectionRepository     *collections.Repository
+	AnalyticsRepository      *analytics.Repository
+	RecommendationRepository *recommendations.Repository
+	TaxonomyRepository       *taxonomy.Repository
 
 	pg *pgxpool.Pool
 }
@@ -42,21 +81,51 @@ func Init(ctx context.Context, cfg *config.Config) (*Manager, error) {
 		return nil, fmt.Errorf("failed to apply migrations: %w", err)
 	}
 
-	minioClient, err := minio.InitMinioClient(cfg.Minio)
+	manager.FileRepository, err = initFileStorage(cfg)
 	if err != nil {
-		return nil, fmt.Errorf("failed to init minio client: %w", err)
+		return nil, fmt.Errorf("failed to init file storage: %w", err)
 	}
 
 	manager.UserRepository = users.NewRepository(manager.pg)
+	manager.UserTokenRepository = users.NewTokens(manager.pg)
+	manager.RecoveryCodeRepository = users.NewRecoveryCodes(manager.pg)
 	manager.PackRepository = music.NewPack(manager.pg)
 	manager.SampleRepository = music.NewSample(manager.pg)
+	manager.VersionRepository = music.NewSampleVersion(manager.pg)
 	manager.PaymentRepository = payments.New(manager.pg)
 	manager.PurchaseRepository = purchases.New(manager.pg)
-	manager.FileRepository = minio.NewMinio(minioClient)
+	manager.RateLimitRepository = ratelimit.New(manager.pg)
+	manager.IdentityRepository = identities.New(manager.pg)
+	manager.SessionRepository = sessions.New(manager.pg)
+	manager.AuthorRepository = authors.New(manager.pg)
+	manager.EarningsRepository = earnings.New(manager.pg)
+	manager.LikeRepository = likes.New(manager.pg)
+	manager.CollectionRepository = collections.New(manager.pg)
+	manager.AnalyticsRepository = analytics.New(manager.pg)
+	manager.RecommendationRepository = recommendations.New(manager.pg)
+	manager.TaxonomyRepository = taxonomy.New(manager.pg)
 
 	return &manager, nil
 }
 
+func initFileStorage(cfg *config.Config) (FileStorage, error) {
+	switch cfg.Storage.Backend {
+	case "", config.StorageMinio:
+		minioClient, err := minio.InitMinioClient(cfg.Minio)
+		if err != nil {
+			return nil, fmt.Errorf("failed to init minio client: %w", err)
+		}
+
+		return minio.NewMinio(minioClient), nil
+	case config.StorageLocal:
+		return local.NewLocal(cfg.Storage.Local)
+	case config.StorageMemory:
+		return memory.NewMemory(), nil
+	default:
+		return nil, fmt.Errorf("unknown storage backend: %s", cfg.Storage.Backend)
+	}
+}
+
 func (m *Manager) Close() {
 	m.pg.Close()
 }
@@ -0,0 +1,14 @@
+package memory
+
+import (
+	"context"
+	"testing"
+
+	"github.com/musicman-backend/internal/repository/storagetest"
+)
+
+func TestStorage(t *testing.T) {
+	if err := storagetest.TestStorage(context.Background(), NewMemory()); err != nil {
+		t.Fatal(err)
+	}
+}
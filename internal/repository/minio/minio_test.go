@@ -0,0 +1,34 @@
+package minio
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/musicman-backend/config"
+	"github.com/musicman-backend/internal/repository/storagetest"
+)
+
+// TestStorage гоняется против настоящего MinIO, адрес и ключи берутся из окружения:
+//
+//	MINIO_TEST_ENDPOINT=localhost:9000 MINIO_TEST_ACCESS_KEY=minioadmin MINIO_TEST_SECRET_KEY=minioadmin go test ./internal/repository/minio
+func TestStorage(t *testing.T) {
+	endpoint := os.Getenv("MINIO_TEST_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("MINIO_TEST_ENDPOINT is not set")
+	}
+
+	client, err := InitMinioClient(config.MinioConfig{
+		Endpoint:  endpoint,
+		AccessKey: os.Getenv("MINIO_TEST_ACCESS_KEY"),
+		SecretKey: os.Getenv("MINIO_TEST_SECRET_KEY"),
+		UseSSL:    os.Getenv("MINIO_TEST_USE_SSL") == "true",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = storagetest.TestStorage(context.Background(), NewMinio(client)); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -3,11 +3,19 @@ package minio
 import (
 	"context"
 	"fmt"
+	"io"
 	"time"
 
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
 	"github.com/musicman-backend/config"
+	"github.com/musicman-backend/internal/domain"
+	"github.com/musicman-backend/internal/domain/entity"
+)
+
+const (
+	codeNoSuchKey    = "NoSuchKey"
+	codeNoSuchBucket = "NoSuchBucket"
 )
 
 func InitMinioClient(minioConfig config.MinioConfig) (*minio.Client, error) {
@@ -43,13 +51,36 @@ func (m *Minio) UploadFile(ctx context.Context, bucketName string, objectName st
 
 func (m *Minio) DownloadFile(ctx context.Context, bucketName string, objectName string, filePath string) error {
 	err := m.client.FGetObject(ctx, bucketName, objectName, filePath, minio.GetObjectOptions{})
-
+	if minio.ToErrorResponse(err).Code == codeNoSuchKey {
+		return domain.ErrNotFound
+	}
 	if err != nil {
 		return fmt.Errorf("failed to download file: %w", err)
 	}
 	return nil
 }
 
+// OpenFile открывает объект на чтение потоком, без загрузки целиком
+func (m *Minio) OpenFile(ctx context.Context, bucketName string, objectName string) (io.ReadCloser, error) {
+	obj, err := m.client.GetObject(ctx, bucketName, objectName, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+
+	// GetObject ленивый, отсутствие объекта видно только после первого запроса
+	_, err = obj.Stat()
+	if minio.ToErrorResponse(err).Code == codeNoSuchKey {
+		_ = obj.Close()
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		_ = obj.Close()
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+
+	return obj, nil
+}
+
 func (m *Minio) GetFileURL(ctx context.Context, bucketName string, objectName string) (string, error) {
 	// Generate presigned URL valid for 1 hour
 	url, err := m.client.PresignedGetObject(ctx, bucketName, objectName, time.Hour, nil)
@@ -67,6 +98,26 @@ func (m *Minio) DeleteFile(ctx context.Context, bucketName string, objectName st
 	return nil
 }
 
+func (m *Minio) ListFiles(ctx context.Context, bucketName string) ([]entity.FileInfo, error) {
+	var files []entity.FileInfo
+	for object := range m.client.ListObjects(ctx, bucketName, minio.ListObjectsOptions{Recursive: true}) {
+		if minio.ToErrorResponse(object.Err).Code == codeNoSuchBucket {
+			return nil, nil
+		}
+		if object.Err != nil {
+			return nil, fmt.Errorf("failed to list files: %w", object.Err)
+		}
+
+		files = append(files, entity.FileInfo{
+			Key:          object.Key,
+			Size:         object.Size,
+			LastModified: object.LastModified,
+		})
+	}
+
+	return files, nil
+}
+
 func (m *Minio) CreateBucketIfNotExists(ctx context.Context, bucketName string) error {
 	exists, err := m.client.BucketExists(ctx, bucketName)
 	if err != nil {
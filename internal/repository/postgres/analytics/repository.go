@@ -0,0 +1,165 @@
+package analytics
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/musicman-backend/internal/domain/constant"
+	"github.com/musicman-backend/internal/domain/entity"
+)
+
+// statsWhere - фильтр дневной статистики d по entity.StatsFilter, семпл s присоединен для автора и пака
+const statsWhere = `
+	WHERE d.day BETWEEN $1 AND $2
+	  AND ($3::uuid IS NULL OR s.author_uuid = $3)
+	  AND ($4::uuid IS NULL OR d.sample_id = $4)
+	  AND ($5::uuid IS NULL OR EXISTS (SELECT 1 FROM pack_samples fp WHERE fp.sample_id = s.id AND fp.pack_id = $5))`
+
+const statsSums = `coalesce(sum(d.plays), 0), coalesce(sum(d.downloads), 0), coalesce(sum(d.purchases), 0), coalesce(sum(d.revenue), 0)`
+
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func New(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+// InsertEvents записывает пачку событий одним COPY
+func (r *Repository) InsertEvents(ctx context.Context, events []entity.SampleEvent) error {
+	_, err := r.db.CopyFrom(ctx,
+		pgx.Identifier{"sample_events"},
+		[]string{"sample_id", "user_uuid", "event_type", "amount", "created_at"},
+		pgx.CopyFromSlice(len(events), func(i int) ([]any, error) {
+			e := events[i]
+			return []any{e.SampleID, e.UserUUID, e.Type, e.Amount, e.CreatedAt}, nil
+		}),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert sample events: %w", err)
+	}
+
+	return nil
+}
+
+// Rollup пересчитывает дневную статистику за дни начиная с since. Пересчет идемпотентный,
+// поэтому опоздавшие события попадают в свой день при следующем запуске
+func (r *Repository) Rollup(ctx context.Context, since time.Time) (int64, error) {
+	const query = `
+		INSERT INTO sample_stats_daily (day, sample_id, plays, downloads, purchases, revenue)
+		SELECT e.created_at::date, e.sample_id,
+			count(*) FILTER (WHERE e.event_type = '` + constant.EventPlay + `'),
+			count(*) FILTER (WHERE e.event_type = '` + constant.EventDownload + `'),
+			count(*) FILTER (WHERE e.event_type = '` + constant.EventPurchase + `'),
+			coalesce(sum(e.amount) FILTER (WHERE e.event_type = '` + constant.EventPurchase + `'), 0)
+		FROM sample_events e
+		WHERE e.created_at >= $1::date
+		GROUP BY 1, 2
+		ON CONFLICT (day, sample_id) DO UPDATE SET
+			plays = EXCLUDED.plays,
+			downloads = EXCLUDED.downloads,
+			purchases = EXCLUDED.purchases,
+			revenue = EXCLUDED.revenue
+	`
+
+	result, err := r.db.Exec(ctx, query, since)
+	if err != nil {
+		return 0, fmt.Errorf("failed to roll up sample events: %w", err)
+	}
+
+	return result.RowsAffected(), nil
+}
+
+// DeleteEventsBefore удаляет сырые события, уже свернутые в дневную статистику
+func (r *Repository) DeleteEventsBefore(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.db.Exec(ctx, `DELETE FROM sample_events WHERE created_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete sample events: %w", err)
+	}
+
+	return result.RowsAffected(), nil
+}
+
+// Series - счетчики по дням, дни без событий не возвращаются
+func (r *Repository) Series(ctx context.Context, filter entity.StatsFilter) ([]entity.StatsPoint, error) {
+	query := `SELECT d.day, ` + statsSums + `
+	FROM sample_stats_daily d JOIN samples s ON s.id = d.sample_id` + statsWhere + `
+	GROUP BY d.day
+	ORDER BY d.day`
+
+	rows, err := r.db.Query(ctx, query, filterArgs(filter)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stats series: %w", err)
+	}
+	defer rows.Close()
+
+	var series []entity.StatsPoint
+	for rows.Next() {
+		var p entity.StatsPoint
+		if err = rows.Scan(&p.Day, &p.Plays, &p.Downloads, &p.Purchases, &p.Revenue); err != nil {
+			return nil, fmt.Errorf("failed to scan stats point: %w", err)
+		}
+		series = append(series, p)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating stats series: %w", err)
+	}
+
+	return series, nil
+}
+
+// BySample - самые прослушиваемые семплы за период
+func (r *Repository) BySample(ctx context.Context, filter entity.StatsFilter, limit int) ([]entity.ItemStats, error) {
+	query := `SELECT s.id, s.title, ` + statsSums + `
+	FROM sample_stats_daily d JOIN samples s ON s.id = d.sample_id` + statsWhere + `
+	GROUP BY s.id, s.title
+	ORDER BY sum(d.plays) DESC, sum(d.revenue) DESC
+	LIMIT $6`
+
+	return r.items(ctx, query, append(filterArgs(filter), limit))
+}
+
+// ByPack - самые прослушиваемые паки за период, семплы без пака не учитываются,
+// семпл из нескольких паков засчитывается каждому
+func (r *Repository) ByPack(ctx context.Context, filter entity.StatsFilter, limit int) ([]entity.ItemStats, error) {
+	query := `SELECT p.id, p.name, ` + statsSums + `
+	FROM sample_stats_daily d JOIN samples s ON s.id = d.sample_id
+	JOIN pack_samples ps ON ps.sample_id = s.id JOIN packs p ON p.id = ps.pack_id` + statsWhere + `
+	GROUP BY p.id, p.name
+	ORDER BY sum(d.plays) DESC, sum(d.revenue) DESC
+	LIMIT $6`
+
+	return r.items(ctx, query, append(filterArgs(filter), limit))
+}
+
+func (r *Repository) items(ctx context.Context, query string, args []any) ([]entity.ItemStats, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get item stats: %w", err)
+	}
+	defer rows.Close()
+
+	var items []entity.ItemStats
+	for rows.Next() {
+		var i entity.ItemStats
+		if err = rows.Scan(&i.ID, &i.Title, &i.Plays, &i.Downloads, &i.Purchases, &i.Revenue); err != nil {
+			return nil, fmt.Errorf("failed to scan item stats: %w", err)
+		}
+		items = append(items, i)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating item stats: %w", err)
+	}
+
+	return items, nil
+}
+
+func filterArgs(f entity.StatsFilter) []any {
+	return []any{f.From, f.To, f.AuthorUUID, f.SampleID, f.PackID}
+}
@@ -0,0 +1,46 @@
+package authors
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/musicman-backend/internal/domain/constant"
+	"github.com/musicman-backend/internal/domain/entity"
+)
+
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func New(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+// Stats считает каталог автора одним запросом, корзина не учитывается.
+// Одобренные с отложенной публикацией считаются, только когда она наступила.
+// Скачивания берутся из дневной статистики, поэтому отстают на интервал ее сворачивания
+func (r *Repository) Stats(ctx context.Context, authorUUID uuid.UUID) (entity.AuthorStats, error) {
+	const query = `
+		SELECT
+			count(*) FILTER (WHERE s.status = '` + constant.SampleStatusPublished + `'
+				AND (s.publish_at IS NULL OR s.publish_at <= now() AT TIME ZONE 'UTC')),
+			count(*) FILTER (WHERE s.status = '` + constant.SampleStatusDraft + `'),
+			count(*) FILTER (WHERE s.status = '` + constant.SampleStatusPending + `'),
+			(SELECT count(*) FROM packs p WHERE p.author_uuid = $1 AND p.deleted_at IS NULL),
+			(SELECT coalesce(sum(d.downloads), 0) FROM sample_stats_daily d
+				JOIN samples ds ON ds.id = d.sample_id WHERE ds.author_uuid = $1)
+		FROM samples s
+		WHERE s.author_uuid = $1 AND s.deleted_at IS NULL
+	`
+
+	var stats entity.AuthorStats
+	err := r.db.QueryRow(ctx, query, authorUUID).Scan(&stats.Samples, &stats.Drafts, &stats.Pending, &stats.Packs, &stats.Downloads)
+	if err != nil {
+		return stats, fmt.Errorf("failed to get author stats: %w", err)
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,199 @@
+package collections
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/musicman-backend/internal/domain"
+	"github.com/musicman-backend/internal/domain/constant"
+	"github.com/musicman-backend/internal/domain/entity"
+)
+
+// sampleVisible - в подборке показываются только семплы, видимые в каталоге
+const sampleVisible = `s.deleted_at IS NULL AND s.status = '` + constant.SampleStatusPublished + `'
+	AND (s.publish_at IS NULL OR s.publish_at <= now() AT TIME ZONE 'UTC')`
+
+// collectionColumns - количество семплов считается так же, как и в выдаче подборки
+const collectionColumns = `c.id, c.user_uuid, c.name, c.description, c.is_public, c.created_at, c.updated_at,
+	(SELECT count(*) FROM collection_samples cs JOIN samples s ON s.id = cs.sample_id
+		WHERE cs.collection_id = c.id AND ` + sampleVisible + `)`
+
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func New(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+func (r *Repository) Create(ctx context.Context, collection entity.Collection) (uuid.UUID, error) {
+	const query = `
+		INSERT INTO collections (user_uuid, name, description, is_public, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`
+
+	var id uuid.UUID
+	err := r.db.QueryRow(ctx, query,
+		collection.UserUUID, collection.Name, collection.Description, collection.IsPublic,
+		collection.CreatedAt, collection.UpdatedAt,
+	).Scan(&id)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to create collection: %w", err)
+	}
+
+	return id, nil
+}
+
+func (r *Repository) GetByID(ctx context.Context, id uuid.UUID) (entity.Collection, error) {
+	query := `SELECT ` + collectionColumns + ` FROM collections c WHERE c.id = $1`
+
+	collection, err := scanCollection(r.db.QueryRow(ctx, query, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return collection, domain.ErrNotFound
+	}
+	if err != nil {
+		return collection, fmt.Errorf("failed to get collection: %w", err)
+	}
+
+	return collection, nil
+}
+
+// GetByUser возвращает подборки пользователя, onlyPublic - только публичные
+func (r *Repository) GetByUser(ctx context.Context, userUUID uuid.UUID, onlyPublic bool) ([]entity.Collection, error) {
+	query := `SELECT ` + collectionColumns + ` FROM collections c
+	WHERE c.user_uuid = $1 AND (NOT $2 OR c.is_public)
+	ORDER BY c.updated_at DESC`
+
+	rows, err := r.db.Query(ctx, query, userUUID, onlyPublic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get collections: %w", err)
+	}
+	defer rows.Close()
+
+	var collections []entity.Collection
+	for rows.Next() {
+		collection, err := scanCollection(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan collection: %w", err)
+		}
+		collections = append(collections, collection)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating collections: %w", err)
+	}
+
+	return collections, nil
+}
+
+func (r *Repository) Update(ctx context.Context, collection entity.Collection) error {
+	const query = `UPDATE collections SET name = $1, description = $2, is_public = $3, updated_at = $4 WHERE id = $5`
+
+	_, err := r.db.Exec(ctx, query,
+		collection.Name, collection.Description, collection.IsPublic, collection.UpdatedAt, collection.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update collection: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Repository) Delete(ctx context.Context, id uuid.UUID) error {
+	if _, err := r.db.Exec(ctx, `DELETE FROM collections WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to delete collection: %w", err)
+	}
+
+	return nil
+}
+
+// AddSample добавляет семпл в конец подборки, повторное добавление ничего не меняет
+func (r *Repository) AddSample(ctx context.Context, collectionID, sampleID uuid.UUID, addedAt time.Time) error {
+	const query = `
+		INSERT INTO collection_samples (collection_id, sample_id, position, added_at)
+		SELECT $1, $2, coalesce(max(position), 0) + 1, $3 FROM collection_samples WHERE collection_id = $1
+		ON CONFLICT DO NOTHING
+	`
+
+	if _, err := r.db.Exec(ctx, query, collectionID, sampleID, addedAt); err != nil {
+		return fmt.Errorf("failed to add sample to collection: %w", err)
+	}
+
+	return r.touch(ctx, collectionID, addedAt)
+}
+
+func (r *Repository) RemoveSample(ctx context.Context, collectionID, sampleID uuid.UUID, removedAt time.Time) error {
+	const query = `DELETE FROM collection_samples WHERE collection_id = $1 AND sample_id = $2`
+
+	result, err := r.db.Exec(ctx, query, collectionID, sampleID)
+	if err != nil {
+		return fmt.Errorf("failed to remove sample from collection: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+
+	return r.touch(ctx, collectionID, removedAt)
+}
+
+// SampleIDs - видимые семплы подборки по порядку, удаленные и снятые с публикации не возвращаются
+func (r *Repository) SampleIDs(ctx context.Context, collectionID uuid.UUID) ([]uuid.UUID, error) {
+	const query = `
+		SELECT cs.sample_id FROM collection_samples cs JOIN samples s ON s.id = cs.sample_id
+		WHERE cs.collection_id = $1 AND ` + sampleVisible + `
+		ORDER BY cs.position
+	`
+
+	rows, err := r.db.Query(ctx, query, collectionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get collection samples: %w", err)
+	}
+
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan collection samples: %w", err)
+	}
+
+	return ids, nil
+}
+
+// Reorder расставляет семплы подборки в порядке sampleIDs. Скрытые семплы в sampleIDs не попадают,
+// поэтому нумеруются все строки: скрытые идут после видимых в прежнем порядке и не пересекаются с ними
+func (r *Repository) Reorder(ctx context.Context, collectionID uuid.UUID, sampleIDs []uuid.UUID, updatedAt time.Time) error {
+	const query = `
+		UPDATE collection_samples c SET position = o.ord
+		FROM (
+			SELECT cs.sample_id,
+				row_number() OVER (ORDER BY array_position($2::uuid[], cs.sample_id) NULLS LAST, cs.position) AS ord
+			FROM collection_samples cs WHERE cs.collection_id = $1
+		) o
+		WHERE c.collection_id = $1 AND c.sample_id = o.sample_id
+	`
+
+	if _, err := r.db.Exec(ctx, query, collectionID, sampleIDs); err != nil {
+		return fmt.Errorf("failed to reorder collection: %w", err)
+	}
+
+	return r.touch(ctx, collectionID, updatedAt)
+}
+
+func (r *Repository) touch(ctx context.Context, collectionID uuid.UUID, updatedAt time.Time) error {
+	if _, err := r.db.Exec(ctx, `UPDATE collections SET updated_at = $1 WHERE id = $2`, updatedAt, collectionID); err != nil {
+		return fmt.Errorf("failed to update collection: %w", err)
+	}
+
+	return nil
+}
+
+func scanCollection(row pgx.Row) (entity.Collection, error) {
+	var c entity.Collection
+	err := row.Scan(&c.ID, &c.UserUUID, &c.Name, &c.Description, &c.IsPublic, &c.CreatedAt, &c.UpdatedAt, &c.SampleCount)
+
+	return c, err
+}
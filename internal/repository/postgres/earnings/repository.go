@@ -0,0 +1,234 @@
+package earnings
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/musicman-backend/internal/domain"
+	"github.com/musicman-backend/internal/domain/constant"
+	"github.com/musicman-backend/internal/domain/entity"
+)
+
+const payoutColumns = `id, author_uuid, amount, status, created_at, reviewed_by, reviewed_at,
+	coalesce(reject_reason, ''), coalesce(external_id, ''), coalesce(failure, ''), paid_at`
+
+// balanceQuery - начислено, выплачено и зарезервировано заявками; отклоненные и неудачные выплаты не учитываются
+const balanceQuery = `
+	SELECT
+		(SELECT coalesce(sum(amount), 0) FROM author_earnings WHERE author_uuid = $1),
+		coalesce(sum(amount) FILTER (WHERE status = '` + constant.PayoutStatusPaid + `'), 0),
+		coalesce(sum(amount) FILTER (WHERE status IN ('` + constant.PayoutStatusPending + `', '` + constant.PayoutStatusApproved + `')), 0)
+	FROM payouts WHERE author_uuid = $1
+`
+
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func New(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+// Summary возвращает баланс автора без разбивки по семплам
+func (r *Repository) Summary(ctx context.Context, authorUUID uuid.UUID) (entity.EarningsSummary, error) {
+	var summary entity.EarningsSummary
+	err := r.db.QueryRow(ctx, balanceQuery, authorUUID).Scan(&summary.Earned, &summary.PaidOut, &summary.Reserved)
+	if err != nil {
+		return summary, fmt.Errorf("failed to get earnings summary: %w", err)
+	}
+	summary.Balance = summary.Earned - summary.PaidOut - summary.Reserved
+
+	return summary, nil
+}
+
+// BySample - заработок автора по семплам, самые доходные первыми
+func (r *Repository) BySample(ctx context.Context, authorUUID uuid.UUID) ([]entity.SampleEarnings, error) {
+	const query = `
+		SELECT e.sample_id, s.title, count(*), sum(e.price), sum(e.commission), sum(e.amount)
+		FROM author_earnings e
+		JOIN samples s ON s.id = e.sample_id
+		WHERE e.author_uuid = $1
+		GROUP BY e.sample_id, s.title
+		ORDER BY sum(e.amount) DESC
+	`
+
+	rows, err := r.db.Query(ctx, query, authorUUID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get earnings by sample: %w", err)
+	}
+	defer rows.Close()
+
+	var res []entity.SampleEarnings
+	for rows.Next() {
+		var e entity.SampleEarnings
+		if err = rows.Scan(&e.SampleID, &e.Title, &e.Sales, &e.Gross, &e.Commission, &e.Amount); err != nil {
+			return nil, fmt.Errorf("failed to scan sample earnings: %w", err)
+		}
+		res = append(res, e)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating sample earnings: %w", err)
+	}
+
+	return res, nil
+}
+
+// CreatePayout создает заявку на вывод, если на балансе хватает средств.
+// Строка автора блокируется, чтобы параллельные заявки не вывели больше заработанного
+func (r *Repository) CreatePayout(ctx context.Context, payout entity.Payout) (entity.Payout, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return payout, fmt.Errorf("failed to start transaction: %w", err)
+	}
+
+	if _, err = tx.Exec(ctx, `select 1 from users where uuid = $1 for update`, payout.AuthorUUID); err != nil {
+		_ = tx.Rollback(ctx)
+		return payout, fmt.Errorf("failed to lock author: %w", err)
+	}
+
+	var earned, paidOut, reserved int
+	if err = tx.QueryRow(ctx, balanceQuery, payout.AuthorUUID).Scan(&earned, &paidOut, &reserved); err != nil {
+		_ = tx.Rollback(ctx)
+		return payout, fmt.Errorf("failed to get balance: %w", err)
+	}
+
+	if earned-paidOut-reserved < payout.Amount {
+		_ = tx.Rollback(ctx)
+		return payout, domain.ErrInsufficientEarnings
+	}
+
+	const query = `
+		INSERT INTO payouts (author_uuid, amount, status, created_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING ` + payoutColumns
+
+	payout, err = scanPayout(tx.QueryRow(ctx, query, payout.AuthorUUID, payout.Amount, payout.Status, payout.CreatedAt))
+	if err != nil {
+		_ = tx.Rollback(ctx)
+		return payout, fmt.Errorf("failed to create payout: %w", err)
+	}
+
+	return payout, tx.Commit(ctx)
+}
+
+func (r *Repository) GetPayout(ctx context.Context, id uuid.UUID) (entity.Payout, error) {
+	query := `SELECT ` + payoutColumns + ` FROM payouts WHERE id = $1`
+
+	payout, err := scanPayout(r.db.QueryRow(ctx, query, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return payout, domain.ErrNotFound
+	}
+	if err != nil {
+		return payout, fmt.Errorf("failed to get payout: %w", err)
+	}
+
+	return payout, nil
+}
+
+// GetPayouts возвращает заявки, новые первыми. authorUUID == nil - всех авторов, пустой status - в любом статусе
+func (r *Repository) GetPayouts(ctx context.Context, authorUUID *uuid.UUID, status string) ([]entity.Payout, error) {
+	query := `SELECT ` + payoutColumns + ` FROM payouts
+	WHERE ($1::uuid IS NULL OR author_uuid = $1) AND ($2 = '' OR status = $2)
+	ORDER BY created_at DESC`
+
+	rows, err := r.db.Query(ctx, query, authorUUID, status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payouts: %w", err)
+	}
+	defer rows.Close()
+
+	var payouts []entity.Payout
+	for rows.Next() {
+		payout, err := scanPayout(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan payout: %w", err)
+		}
+		payouts = append(payouts, payout)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating payouts: %w", err)
+	}
+
+	return payouts, nil
+}
+
+// Review переводит заявку из pending в status. Уже рассмотренная заявка - domain.ErrPayoutProcessed
+func (r *Repository) Review(ctx context.Context, id, reviewer uuid.UUID, status, reason string, reviewedAt time.Time) (entity.Payout, error) {
+	query := `
+		UPDATE payouts SET status = $1, reviewed_by = $2, reviewed_at = $3, reject_reason = nullif($4, '')
+		WHERE id = $5 AND status = '` + constant.PayoutStatusPending + `'
+		RETURNING ` + payoutColumns
+
+	payout, err := scanPayout(r.db.QueryRow(ctx, query, status, reviewer, reviewedAt, reason, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return payout, domain.ErrPayoutProcessed
+	}
+	if err != nil {
+		return payout, fmt.Errorf("failed to review payout: %w", err)
+	}
+
+	return payout, nil
+}
+
+// MarkPaid фиксирует успешную выплату одобренной заявки
+func (r *Repository) MarkPaid(ctx context.Context, id uuid.UUID, externalID string, paidAt time.Time) (entity.Payout, error) {
+	query := `
+		UPDATE payouts SET status = '` + constant.PayoutStatusPaid + `', external_id = $1, paid_at = $2
+		WHERE id = $3 AND status = '` + constant.PayoutStatusApproved + `'
+		RETURNING ` + payoutColumns
+
+	payout, err := scanPayout(r.db.QueryRow(ctx, query, externalID, paidAt, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return payout, domain.ErrPayoutProcessed
+	}
+	if err != nil {
+		return payout, fmt.Errorf("failed to mark payout paid: %w", err)
+	}
+
+	return payout, nil
+}
+
+// MarkFailed фиксирует неудачную выплату, сумма возвращается на баланс автора
+func (r *Repository) MarkFailed(ctx context.Context, id uuid.UUID, failure string) (entity.Payout, error) {
+	query := `
+		UPDATE payouts SET status = '` + constant.PayoutStatusFailed + `', failure = $1
+		WHERE id = $2 AND status = '` + constant.PayoutStatusApproved + `'
+		RETURNING ` + payoutColumns
+
+	payout, err := scanPayout(r.db.QueryRow(ctx, query, failure, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return payout, domain.ErrPayoutProcessed
+	}
+	if err != nil {
+		return payout, fmt.Errorf("failed to mark payout failed: %w", err)
+	}
+
+	return payout, nil
+}
+
+func scanPayout(row pgx.Row) (entity.Payout, error) {
+	var payout entity.Payout
+	err := row.Scan(
+		&payout.ID,
+		&payout.AuthorUUID,
+		&payout.Amount,
+		&payout.Status,
+		&payout.CreatedAt,
+		&payout.ReviewedBy,
+		&payout.ReviewedAt,
+		&payout.RejectReason,
+		&payout.ExternalID,
+		&payout.Failure,
+		&payout.PaidAt,
+	)
+
+	return payout, err
+}
@@ -0,0 +1,186 @@
+package identities
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/musicman-backend/internal/domain"
+	"github.com/musicman-backend/internal/domain/entity"
+)
+
+const codeUniqueViolation = "23505"
+
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func New(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+// Create привязывает аккаунт провайдера. Если он уже привязан, вернет domain.ErrIdentityAlreadyLinked
+func (r *Repository) Create(ctx context.Context, identity entity.UserIdentity) error {
+	const query = `
+		INSERT INTO user_identities (user_uuid, provider, subject, email, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+
+	_, err := r.db.Exec(ctx, query,
+		identity.UserUUID,
+		identity.Provider,
+		identity.Subject,
+		identity.Email,
+		identity.CreatedAt,
+	)
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == codeUniqueViolation {
+		return domain.ErrIdentityAlreadyLinked
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create identity: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Repository) GetBySubject(ctx context.Context, provider, subject string) (entity.UserIdentity, error) {
+	const query = `
+		SELECT id, user_uuid, provider, subject, email, created_at
+		FROM user_identities
+		WHERE provider = $1 AND subject = $2
+	`
+
+	identity, err := scanIdentity(r.db.QueryRow(ctx, query, provider, subject))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.UserIdentity{}, domain.ErrNotFound
+		}
+		return entity.UserIdentity{}, fmt.Errorf("failed to get identity: %w", err)
+	}
+
+	return identity, nil
+}
+
+func (r *Repository) GetByUser(ctx context.Context, userUUID uuid.UUID) ([]entity.UserIdentity, error) {
+	const query = `
+		SELECT id, user_uuid, provider, subject, email, created_at
+		FROM user_identities
+		WHERE user_uuid = $1
+		ORDER BY created_at
+	`
+
+	rows, err := r.db.Query(ctx, query, userUUID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get identities by user: %w", err)
+	}
+	defer rows.Close()
+
+	var identities []entity.UserIdentity
+	for rows.Next() {
+		identity, err := scanIdentity(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan identity: %w", err)
+		}
+		identities = append(identities, identity)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating identities: %w", err)
+	}
+
+	return identities, nil
+}
+
+func (r *Repository) Delete(ctx context.Context, userUUID uuid.UUID, provider string) error {
+	const query = `DELETE FROM user_identities WHERE user_uuid = $1 AND provider = $2`
+
+	result, err := r.db.Exec(ctx, query, userUUID, provider)
+	if err != nil {
+		return fmt.Errorf("failed to delete identity: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *Repository) CreateState(ctx context.Context, state entity.OAuthState) error {
+	const query = `
+		INSERT INTO oauth_states (state, provider, code_verifier, user_uuid, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	_, err := r.db.Exec(ctx, query,
+		state.State,
+		state.Provider,
+		state.CodeVerifier,
+		state.UserUUID,
+		state.ExpiresAt,
+		state.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create oauth state: %w", err)
+	}
+
+	return nil
+}
+
+// ConsumeState забирает state, второй раз тот же state уже не найдется
+func (r *Repository) ConsumeState(ctx context.Context, state string) (entity.OAuthState, error) {
+	const query = `
+		DELETE FROM oauth_states
+		WHERE state = $1
+		RETURNING state, provider, code_verifier, user_uuid, expires_at, created_at
+	`
+
+	var s entity.OAuthState
+	err := r.db.QueryRow(ctx, query, state).Scan(
+		&s.State,
+		&s.Provider,
+		&s.CodeVerifier,
+		&s.UserUUID,
+		&s.ExpiresAt,
+		&s.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.OAuthState{}, domain.ErrNotFound
+		}
+		return entity.OAuthState{}, fmt.Errorf("failed to consume oauth state: %w", err)
+	}
+
+	return s, nil
+}
+
+// DeleteExpiredStates чистит брошенные входы
+func (r *Repository) DeleteExpiredStates(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.db.Exec(ctx, `DELETE FROM oauth_states WHERE expires_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired oauth states: %w", err)
+	}
+
+	return result.RowsAffected(), nil
+}
+
+func scanIdentity(row pgx.Row) (entity.UserIdentity, error) {
+	var identity entity.UserIdentity
+	err := row.Scan(
+		&identity.ID,
+		&identity.UserUUID,
+		&identity.Provider,
+		&identity.Subject,
+		&identity.Email,
+		&identity.CreatedAt,
+	)
+
+	return identity, err
+}
@@ -0,0 +1,119 @@
+package likes
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func New(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+// target - таблица лайков и запись, у которой хранится счетчик
+type target struct {
+	likes  string
+	column string
+	table  string
+}
+
+var (
+	sampleTarget = target{likes: "sample_likes", column: "sample_id", table: "samples"}
+	packTarget   = target{likes: "pack_likes", column: "pack_id", table: "packs"}
+)
+
+func (r *Repository) LikeSample(ctx context.Context, userUUID, sampleID uuid.UUID) error {
+	return r.like(ctx, sampleTarget, userUUID, sampleID)
+}
+
+func (r *Repository) UnlikeSample(ctx context.Context, userUUID, sampleID uuid.UUID) error {
+	return r.unlike(ctx, sampleTarget, userUUID, sampleID)
+}
+
+func (r *Repository) LikePack(ctx context.Context, userUUID, packID uuid.UUID) error {
+	return r.like(ctx, packTarget, userUUID, packID)
+}
+
+func (r *Repository) UnlikePack(ctx context.Context, userUUID, packID uuid.UUID) error {
+	return r.unlike(ctx, packTarget, userUUID, packID)
+}
+
+// LikedSamples возвращает, какие из sampleIDs лайкнул пользователь
+func (r *Repository) LikedSamples(ctx context.Context, userUUID uuid.UUID, sampleIDs []uuid.UUID) (map[uuid.UUID]bool, error) {
+	const query = `SELECT sample_id FROM sample_likes WHERE user_uuid = $1 AND sample_id = ANY($2)`
+
+	rows, err := r.db.Query(ctx, query, userUUID, sampleIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get liked samples: %w", err)
+	}
+	defer rows.Close()
+
+	liked := make(map[uuid.UUID]bool)
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan liked sample: %w", err)
+		}
+		liked[id] = true
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating liked samples: %w", err)
+	}
+
+	return liked, nil
+}
+
+// like ставит лайк и увеличивает счетчик, повторный лайк ничего не меняет
+func (r *Repository) like(ctx context.Context, t target, userUUID, id uuid.UUID) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+
+	query := `INSERT INTO ` + t.likes + ` (user_uuid, ` + t.column + `) VALUES ($1, $2) ON CONFLICT DO NOTHING`
+	result, err := tx.Exec(ctx, query, userUUID, id)
+	if err != nil {
+		_ = tx.Rollback(ctx)
+		return fmt.Errorf("failed to like: %w", err)
+	}
+
+	if result.RowsAffected() > 0 {
+		if _, err = tx.Exec(ctx, `UPDATE `+t.table+` SET like_count = like_count + 1 WHERE id = $1`, id); err != nil {
+			_ = tx.Rollback(ctx)
+			return fmt.Errorf("failed to update like count: %w", err)
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// unlike снимает лайк и уменьшает счетчик, если лайка не было - ничего не меняет
+func (r *Repository) unlike(ctx context.Context, t target, userUUID, id uuid.UUID) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+
+	query := `DELETE FROM ` + t.likes + ` WHERE user_uuid = $1 AND ` + t.column + ` = $2`
+	result, err := tx.Exec(ctx, query, userUUID, id)
+	if err != nil {
+		_ = tx.Rollback(ctx)
+		return fmt.Errorf("failed to unlike: %w", err)
+	}
+
+	if result.RowsAffected() > 0 {
+		if _, err = tx.Exec(ctx, `UPDATE `+t.table+` SET like_count = like_count - 1 WHERE id = $1`, id); err != nil {
+			_ = tx.Rollback(ctx)
+			return fmt.Errorf("failed to update like count: %w", err)
+		}
+	}
+
+	return tx.Commit(ctx)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -13,6 +14,13 @@ import (
 	"github.com/musicman-backend/internal/domain/entity"
 )
 
+const packColumns = `p.id, p.name, p.description, p.genre, p.author_uuid, u.login, coalesce(u.display_name, u.login),
+	p.like_count, coalesce(p.cover_key, ''), p.created_at, p.updated_at, p.deleted_at,
+	coalesce((SELECT json_agg(json_build_object('id', t.id, 'kind', t.kind, 'slug', t.slug, 'name', t.name) ORDER BY t.kind, t.name)
+		FROM pack_tags pt JOIN tags t ON t.id = pt.tag_id WHERE pt.pack_id = p.id), '[]')`
+
+const packFrom = ` FROM packs p JOIN users u ON u.uuid = p.author_uuid`
+
 type Pack struct {
 	db *pgxpool.Pool
 }
@@ -23,7 +31,7 @@ func NewPack(db *pgxpool.Pool) *Pack {
 
 func (r *Pack) Create(ctx context.Context, pack entity.Pack) (uuid.UUID, error) {
 	query := `
-	INSERT INTO packs (name, description, genre, author, created_at, updated_at)
+	INSERT INTO packs (name, description, genre, author_uuid, created_at, updated_at)
 	VALUES ($1, $2, $3, $4, $5, $6)
 	RETURNING id`
 
@@ -33,7 +41,7 @@ func (r *Pack) Create(ctx context.Context, pack entity.Pack) (uuid.UUID, error)
 		pack.Name,
 		pack.Description,
 		pack.Genre,
-		pack.Author,
+		pack.AuthorUUID,
 		pack.CreatedAt,
 		pack.UpdatedAt,
 	).Scan(&id)
@@ -42,16 +50,9 @@ func (r *Pack) Create(ctx context.Context, pack entity.Pack) (uuid.UUID, error)
 }
 
 func (r *Pack) GetByID(ctx context.Context, id uuid.UUID) (entity.Pack, error) {
-	query := `
-	SELECT id, name, description, genre, author, created_at, updated_at
-	FROM packs WHERE id = $1`
+	query := `SELECT ` + packColumns + packFrom + ` WHERE p.id = $1`
 
-	var pack entity.Pack
-	row := r.db.QueryRow(ctx, query, id)
-	err := row.Scan(
-		&pack.ID, &pack.Name, &pack.Description, &pack.Genre, &pack.Author,
-		&pack.CreatedAt, &pack.UpdatedAt,
-	)
+	pack, err := scanPack(r.db.QueryRow(ctx, query, id))
 
 	if errors.Is(err, pgx.ErrNoRows) {
 		return pack, domain.ErrNotFound
@@ -64,9 +65,7 @@ func (r *Pack) GetByID(ctx context.Context, id uuid.UUID) (entity.Pack, error) {
 }
 
 func (r *Pack) GetAll(ctx context.Context) ([]entity.Pack, error) {
-	query := `
-	SELECT id, name, description, genre, author, created_at, updated_at
-	FROM packs ORDER BY created_at DESC`
+	query := `SELECT ` + packColumns + packFrom + ` WHERE p.deleted_at IS NULL ORDER BY p.created_at DESC`
 
 	rows, err := r.db.Query(ctx, query)
 	if err != nil {
@@ -77,37 +76,288 @@ func (r *Pack) GetAll(ctx context.Context) ([]entity.Pack, error) {
 		return nil, fmt.Errorf("failed get all packs from db: %w", err)
 	}
 
+	return r.scanPacks(rows)
+}
+
+// GetByAuthor возвращает страницу паков автора и их общее количество
+func (r *Pack) GetByAuthor(ctx context.Context, authorUUID uuid.UUID, page entity.Page) ([]entity.Pack, int, error) {
+	query := `SELECT ` + packColumns + `, count(*) OVER ()` + packFrom + `
+	WHERE p.author_uuid = $1 AND p.deleted_at IS NULL
+	ORDER BY p.created_at DESC
+	LIMIT $2 OFFSET $3`
+
+	rows, err := r.db.Query(ctx, query, authorUUID, page.Limit, page.Offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed get author packs from db: %w", err)
+	}
+	defer rows.Close()
+
 	var packs []entity.Pack
+	var total int
 	for rows.Next() {
-		var pack entity.Pack
-		err = rows.Scan(
-			&pack.ID, &pack.Name, &pack.Description, &pack.Genre, &pack.Author,
-			&pack.CreatedAt, &pack.UpdatedAt,
-		)
-
+		pack, err := scanPack(rows, &total)
 		if err != nil {
-			return nil, fmt.Errorf("failed get map packs from db: %w", err)
+			return nil, 0, fmt.Errorf("failed get map packs from db: %w", err)
 		}
 		packs = append(packs, pack)
 	}
 
-	return packs, nil
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating packs: %w", err)
+	}
+
+	return packs, total, nil
+}
+
+// GetLiked возвращает паки, которые лайкнул пользователь, последние лайки первыми
+func (r *Pack) GetLiked(ctx context.Context, userUUID uuid.UUID) ([]entity.Pack, error) {
+	query := `SELECT ` + packColumns + packFrom + `
+	JOIN pack_likes l ON l.pack_id = p.id
+	WHERE l.user_uuid = $1 AND p.deleted_at IS NULL
+	ORDER BY l.created_at DESC`
+
+	rows, err := r.db.Query(ctx, query, userUUID)
+	if err != nil {
+		return nil, fmt.Errorf("failed get liked packs from db: %w", err)
+	}
+
+	return r.scanPacks(rows)
+}
+
+// GetDeleted возвращает паки из корзины, authorUUID == nil - всех авторов
+func (r *Pack) GetDeleted(ctx context.Context, authorUUID *uuid.UUID) ([]entity.Pack, error) {
+	query := `SELECT ` + packColumns + packFrom + `
+	WHERE p.deleted_at IS NOT NULL AND ($1::uuid IS NULL OR p.author_uuid = $1)
+	ORDER BY p.deleted_at DESC`
+
+	rows, err := r.db.Query(ctx, query, authorUUID)
+	if err != nil {
+		return nil, fmt.Errorf("failed get deleted packs from db: %w", err)
+	}
+
+	return r.scanPacks(rows)
 }
 
 func (r *Pack) Update(ctx context.Context, pack entity.Pack) error {
 	query := `
-	UPDATE packs SET name=$1, description=$2, genre=$3, author=$4, updated_at=$5
-	WHERE id=$6`
+	UPDATE packs SET name=$1, description=$2, genre=$3, updated_at=$4
+	WHERE id=$5`
 
 	_, err := r.db.Exec(ctx, query,
-		pack.Name, pack.Description, pack.Genre, pack.Author,
+		pack.Name, pack.Description, pack.Genre,
 		pack.UpdatedAt, pack.ID)
+	if err != nil {
+		return fmt.Errorf("failed update pack from db: %w", err)
+	}
+
+	return nil
+}
+
+// SetCover ставит обложку пака, пустой key - убирает. Возвращает ключ предыдущей, чтобы ее удалить
+func (r *Pack) SetCover(ctx context.Context, id uuid.UUID, key string) (string, error) {
+	query := `
+		UPDATE packs p SET cover_key = nullif($1, ''), updated_at = now()
+		FROM (SELECT id, cover_key FROM packs WHERE id = $2 FOR UPDATE) old
+		WHERE p.id = old.id
+		RETURNING coalesce(old.cover_key, '')`
+
+	var oldKey string
+	err := r.db.QueryRow(ctx, query, key, id).Scan(&oldKey)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", domain.ErrNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to set pack cover: %w", err)
+	}
+
+	return oldKey, nil
+}
+
+// AddSample добавляет семпл последним треком пака, повторное добавление ничего не меняет
+func (r *Pack) AddSample(ctx context.Context, packID, sampleID uuid.UUID, addedAt time.Time) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+
+	if err = addPackSample(ctx, tx, packID, sampleID, addedAt); err != nil {
+		_ = tx.Rollback(ctx)
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// addPackSample - общая вставка трека для AddSample и создания семпла сразу в паке.
+// Обновление пака блокирует его строку до конца транзакции, поэтому параллельные
+// добавления не получают одинаковую позицию max+1
+func addPackSample(ctx context.Context, tx pgx.Tx, packID, sampleID uuid.UUID, addedAt time.Time) error {
+	if _, err := tx.Exec(ctx, `UPDATE packs SET updated_at = $1 WHERE id = $2`, addedAt, packID); err != nil {
+		return fmt.Errorf("failed to update pack: %w", err)
+	}
+
+	const query = `
+		INSERT INTO pack_samples (pack_id, sample_id, position, added_at)
+		SELECT $1, $2, coalesce(max(position), 0) + 1, $3 FROM pack_samples WHERE pack_id = $1
+		ON CONFLICT DO NOTHING
+	`
+
+	if _, err := tx.Exec(ctx, query, packID, sampleID, addedAt); err != nil {
+		return fmt.Errorf("failed to add sample to pack: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveSample убирает семпл из пака, сам семпл остается
+func (r *Pack) RemoveSample(ctx context.Context, packID, sampleID uuid.UUID, removedAt time.Time) error {
+	const query = `DELETE FROM pack_samples WHERE pack_id = $1 AND sample_id = $2`
+
+	result, err := r.db.Exec(ctx, query, packID, sampleID)
+	if err != nil {
+		return fmt.Errorf("failed to remove sample from pack: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
 
-	return fmt.Errorf("failed update pack from db: %w", err)
+	return r.touch(ctx, packID, removedAt)
 }
 
-func (r *Pack) Delete(ctx context.Context, id uuid.UUID) error {
-	query := `DELETE FROM packs WHERE id = $1`
+// SampleIDs - видимые семплы пака по порядку треков, удаленные в корзину не возвращаются
+func (r *Pack) SampleIDs(ctx context.Context, packID uuid.UUID) ([]uuid.UUID, error) {
+	const query = `
+		SELECT ps.sample_id FROM pack_samples ps JOIN samples s ON s.id = ps.sample_id
+		WHERE ps.pack_id = $1 AND s.deleted_at IS NULL
+		ORDER BY ps.position, ps.added_at
+	`
+
+	rows, err := r.db.Query(ctx, query, packID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pack samples: %w", err)
+	}
+
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan pack samples: %w", err)
+	}
+
+	return ids, nil
+}
+
+// Reorder расставляет треки пака в порядке sampleIDs. Семплы из корзины в sampleIDs не попадают,
+// поэтому нумеруются все строки: удаленные идут после видимых в прежнем порядке и после
+// восстановления не пересекаются с ними
+func (r *Pack) Reorder(ctx context.Context, packID uuid.UUID, sampleIDs []uuid.UUID, updatedAt time.Time) error {
+	const query = `
+		UPDATE pack_samples p SET position = o.ord
+		FROM (
+			SELECT ps.sample_id,
+				row_number() OVER (ORDER BY array_position($2::uuid[], ps.sample_id) NULLS LAST, ps.position, ps.added_at) AS ord
+			FROM pack_samples ps WHERE ps.pack_id = $1
+		) o
+		WHERE p.pack_id = $1 AND p.sample_id = o.sample_id
+	`
+
+	if _, err := r.db.Exec(ctx, query, packID, sampleIDs); err != nil {
+		return fmt.Errorf("failed to reorder pack: %w", err)
+	}
+
+	return r.touch(ctx, packID, updatedAt)
+}
+
+func (r *Pack) touch(ctx context.Context, packID uuid.UUID, updatedAt time.Time) error {
+	if _, err := r.db.Exec(ctx, `UPDATE packs SET updated_at = $1 WHERE id = $2`, updatedAt, packID); err != nil {
+		return fmt.Errorf("failed to update pack: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Pack) SoftDelete(ctx context.Context, id uuid.UUID, deletedAt time.Time) error {
+	query := `UPDATE packs SET deleted_at = $1 WHERE id = $2`
+	_, err := r.db.Exec(ctx, query, deletedAt, id)
+	if err != nil {
+		return fmt.Errorf("failed soft delete pack from db: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Pack) Restore(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE packs SET deleted_at = NULL WHERE id = $1`
 	_, err := r.db.Exec(ctx, query, id)
-	return fmt.Errorf("failed delete pack from db: %w", err)
+	if err != nil {
+		return fmt.Errorf("failed restore pack from db: %w", err)
+	}
+
+	return nil
+}
+
+// GetCoverKeys возвращает ключи обложек всех паков, в том числе из корзины - их еще можно восстановить
+func (r *Pack) GetCoverKeys(ctx context.Context) ([]string, error) {
+	rows, err := r.db.Query(ctx, `SELECT cover_key FROM packs WHERE cover_key <> ''`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cover keys from db: %w", err)
+	}
+
+	keys, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan cover keys: %w", err)
+	}
+
+	return keys, nil
+}
+
+// DeleteDeletedBefore окончательно удаляет паки, пролежавшие в корзине дольше срока хранения.
+// Семплы остаются, удаляется только их членство в паке.
+// Возвращает ключи обложек удаленных паков, пустые - у паков без обложки
+func (r *Pack) DeleteDeletedBefore(ctx context.Context, before time.Time) ([]string, error) {
+	query := `DELETE FROM packs WHERE deleted_at < $1 RETURNING coalesce(cover_key, '')`
+	rows, err := r.db.Query(ctx, query, before)
+	if err != nil {
+		return nil, fmt.Errorf("failed delete packs from db: %w", err)
+	}
+
+	covers, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("failed delete packs from db: %w", err)
+	}
+
+	return covers, nil
+}
+
+func (r *Pack) scanPacks(rows pgx.Rows) ([]entity.Pack, error) {
+	defer rows.Close()
+
+	var packs []entity.Pack
+	for rows.Next() {
+		pack, err := scanPack(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed get map packs from db: %w", err)
+		}
+		packs = append(packs, pack)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating packs: %w", err)
+	}
+
+	return packs, nil
+}
+
+// scanPack читает строку packColumns, extra - дополнительные колонки после них
+func scanPack(row pgx.Row, extra ...any) (entity.Pack, error) {
+	var pack entity.Pack
+	dest := []any{
+		&pack.ID, &pack.Name, &pack.Description, &pack.Genre, &pack.AuthorUUID, &pack.Author, &pack.AuthorName,
+		&pack.Likes, &pack.CoverKey, &pack.CreatedAt, &pack.UpdatedAt, &pack.DeletedAt, &pack.Tags,
+	}
+	err := row.Scan(append(dest, extra...)...)
+
+	return pack, err
 }
@@ -1,18 +1,77 @@
 package music
 
 import (
-	"database/sql"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/musicman-backend/internal/domain"
+	"github.com/musicman-backend/internal/domain/constant"
 	"github.com/musicman-backend/internal/domain/entity"
 	"golang.org/x/net/context"
 )
 
+const sampleColumns = `s.id, s.title, s.author_uuid, u.login, coalesce(u.display_name, u.login), s.description, s.genre,
+	s.duration, s.size, s.minio_key, s.price, s.created_at, s.updated_at,
+	s.audio_version, s.audio_hash, s.original_filename, s.deleted_at, s.delisted_at, s.like_count,
+	s.status, s.publish_at, s.submitted_at, s.reviewed_at, coalesce(s.reject_reason, ''),
+	coalesce((SELECT json_agg(ps.pack_id ORDER BY ps.added_at) FROM pack_samples ps WHERE ps.sample_id = s.id), '[]'),
+	coalesce((SELECT json_agg(json_build_object('type', l.license_type, 'price', l.price) ORDER BY l.price)
+		FROM sample_licenses l WHERE l.sample_id = s.id), '[]'),
+	coalesce((SELECT json_agg(json_build_object('id', t.id, 'kind', t.kind, 'slug', t.slug, 'name', t.name) ORDER BY t.kind, t.name)
+		FROM sample_tags st JOIN tags t ON t.id = st.tag_id WHERE st.sample_id = s.id), '[]')`
+
+// sampleFrom - автор подтягивается из users, чтобы отдавать его логин и имя
+const sampleFrom = ` FROM samples s JOIN users u ON u.uuid = s.author_uuid`
+
+// samplePublic - семпл виден в каталоге, то же, что entity.Sample.Public. publish_at хранится в UTC
+const samplePublic = `s.deleted_at IS NULL AND s.status = '` + constant.SampleStatusPublished + `'
+	AND (s.publish_at IS NULL OR s.publish_at <= now() AT TIME ZONE 'UTC')`
+
+// priceBucket и durationBucket - корзины constant.PriceBuckets и constant.DurationBuckets
+const (
+	priceBucket = `CASE WHEN s.price = 0 THEN '` + constant.PriceFree + `'
+		WHEN s.price < 100 THEN '` + constant.PriceUnder100 + `'
+		WHEN s.price <= 500 THEN '` + constant.Price100To500 + `'
+		ELSE '` + constant.PriceOver500 + `' END`
+	durationBucket = `CASE WHEN s.duration < 5 THEN '` + constant.DurationUnder5s + `'
+		WHEN s.duration < 15 THEN '` + constant.Duration5To15s + `'
+		WHEN s.duration <= 30 THEN '` + constant.Duration15To30s + `'
+		ELSE '` + constant.DurationOver30s + `' END`
+)
+
+// sampleFilter - условия entity.SampleFilter, жанр с поджанрами. Параметры $1-$8 - filterArgs
+const sampleFilter = `
+	AND ($1 = '' OR s.genre IN (
+		WITH RECURSIVE sub AS (
+			SELECT id, slug FROM genres WHERE slug = $1
+			UNION ALL
+			SELECT g.id, g.slug FROM genres g JOIN sub ON g.parent_id = sub.id
+		)
+		SELECT slug FROM sub))
+	AND ($2 = '' OR EXISTS (SELECT 1 FROM sample_tags st JOIN tags t ON t.id = st.tag_id
+		WHERE st.sample_id = s.id AND t.kind = '` + constant.TagKindTag + `' AND t.slug = $2))
+	AND ($3 = '' OR EXISTS (SELECT 1 FROM sample_tags st JOIN tags t ON t.id = st.tag_id
+		WHERE st.sample_id = s.id AND t.kind = '` + constant.TagKindInstrument + `' AND t.slug = $3))
+	AND ($4 = '' OR EXISTS (SELECT 1 FROM sample_tags st JOIN tags t ON t.id = st.tag_id
+		WHERE st.sample_id = s.id AND t.kind = '` + constant.TagKindMood + `' AND t.slug = $4))
+	AND ($5::uuid IS NULL OR s.author_uuid = $5)
+	AND ($6::uuid IS NULL OR EXISTS (SELECT 1 FROM pack_samples ps WHERE ps.sample_id = s.id AND ps.pack_id = $6))
+	AND ($7 = '' OR ` + priceBucket + ` = $7)
+	AND ($8 = '' OR ` + durationBucket + ` = $8)`
+
+func filterArgs(filter entity.SampleFilter) []any {
+	return []any{filter.Genre, filter.Tag, filter.Instrument, filter.Mood, filter.Author, filter.PackID, filter.Price, filter.Duration}
+}
+
+var sampleOrder = map[string]string{
+	constant.SortNew:     `s.created_at DESC`,
+	constant.SortPopular: `s.like_count DESC, s.created_at DESC`,
+}
+
 type Sample struct {
 	db *pgxpool.Pool
 }
@@ -21,28 +80,61 @@ func NewSample(db *pgxpool.Pool) *Sample {
 	return &Sample{db: db}
 }
 
-func (r *Sample) Create(ctx context.Context, sample entity.Sample) (uuid.UUID, error) {
+// Create создает семпл вместе с тегами tagIDs, лицензиями и треками паков sample.PackIDs в одной транзакции
+func (r *Sample) Create(ctx context.Context, sample entity.Sample, tagIDs []uuid.UUID) (uuid.UUID, error) {
 	query := `
-		INSERT INTO samples (title, author, description, genre, duration, size, minio_key, pack_id, price, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		INSERT INTO samples (title, author_uuid, description, genre, duration, size, minio_key, price, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 		RETURNING id`
 
 	var id uuid.UUID
 
-	row := r.db.QueryRow(ctx, query,
-		sample.Title, sample.Author, sample.Description, sample.Genre, sample.Duration, sample.Size, sample.MinioKey,
-		sample.PackID, sample.Price, sample.CreatedAt, sample.UpdatedAt)
-	if err := row.Scan(&id); err != nil {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return id, fmt.Errorf("failed to start transaction: %w", err)
+	}
+
+	row := tx.QueryRow(ctx, query,
+		sample.Title, sample.AuthorUUID, sample.Description, sample.Genre, sample.Duration, sample.Size, sample.MinioKey,
+		sample.Price, sample.CreatedAt, sample.UpdatedAt)
+	if err = row.Scan(&id); err != nil {
+		_ = tx.Rollback(ctx)
 		return id, fmt.Errorf("failed to create in db: %w", err)
 	}
 
+	if len(tagIDs) > 0 {
+		_, err = tx.Exec(ctx, `INSERT INTO sample_tags (sample_id, tag_id) SELECT $1, unnest($2::uuid[])`, id, tagIDs)
+		if err != nil {
+			_ = tx.Rollback(ctx)
+			return id, fmt.Errorf("failed to create sample tags: %w", err)
+		}
+	}
+
+	for _, l := range sample.Licenses {
+		_, err = tx.Exec(ctx, `INSERT INTO sample_licenses (sample_id, license_type, price) VALUES ($1, $2, $3)`,
+			id, l.Type, l.Price)
+		if err != nil {
+			_ = tx.Rollback(ctx)
+			return id, fmt.Errorf("failed to create sample license: %w", err)
+		}
+	}
+
+	for _, packID := range sample.PackIDs {
+		if err = addPackSample(ctx, tx, packID, id, sample.CreatedAt); err != nil {
+			_ = tx.Rollback(ctx)
+			return id, err
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return id, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
 	return id, nil
 }
 
 func (r *Sample) GetByID(ctx context.Context, id uuid.UUID) (entity.Sample, error) {
-	query := `
-	SELECT id, title, author, description, genre, duration, size, minio_key, pack_id, price, created_at, updated_at
-	FROM samples WHERE id = $1`
+	query := `SELECT ` + sampleColumns + sampleFrom + ` WHERE s.id = $1`
 
 	row := r.db.QueryRow(ctx, query, id)
 	sample, err := r.scanSample(row)
@@ -57,12 +149,15 @@ func (r *Sample) GetByID(ctx context.Context, id uuid.UUID) (entity.Sample, erro
 	return sample, nil
 }
 
-func (r *Sample) GetAll(ctx context.Context) ([]entity.Sample, error) {
-	query := `
-	SELECT id, title, author, description, genre, duration, size, minio_key, pack_id, price, created_at, updated_at
-	FROM samples ORDER BY created_at DESC`
+// GetAll возвращает каталог по фильтру в порядке filter.Sort, неизвестная сортировка - сначала новые
+func (r *Sample) GetAll(ctx context.Context, filter entity.SampleFilter) ([]entity.Sample, error) {
+	order, ok := sampleOrder[filter.Sort]
+	if !ok {
+		order = sampleOrder[constant.SortNew]
+	}
+	query := `SELECT ` + sampleColumns + sampleFrom + ` WHERE ` + samplePublic + sampleFilter + ` ORDER BY ` + order
 
-	rows, err := r.db.Query(ctx, query)
+	rows, err := r.db.Query(ctx, query, filterArgs(filter)...)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, domain.ErrNotFound
 	}
@@ -70,37 +165,80 @@ func (r *Sample) GetAll(ctx context.Context) ([]entity.Sample, error) {
 		return nil, fmt.Errorf("error get all samples from DB: %w", err)
 	}
 
-	var samples []entity.Sample
-	for rows.Next() {
-		var sample entity.Sample
-		var genre string
-		var packID sql.Null[uuid.UUID]
-
-		err = rows.Scan(
-			&sample.ID, &sample.Title, &sample.Author, &sample.Description, &genre,
-			&sample.Duration, &sample.Size, &sample.MinioKey,
-			&packID, &sample.Price, &sample.CreatedAt, &sample.UpdatedAt,
+	return r.scanSamples(rows)
+}
+
+// Facets считает семплы каталога по фильтру в разрезе жанра, автора, пака, цены и длительности
+// одним запросом. Семпл из нескольких паков учитывается в каждом. Значения каждого фасета
+// отсортированы по убыванию количества
+func (r *Sample) Facets(ctx context.Context, filter entity.SampleFilter) (entity.Facets, error) {
+	query := `
+		WITH f AS (
+			SELECT s.id, s.genre, s.author_uuid, ` + priceBucket + ` AS price, ` + durationBucket + ` AS duration
+			FROM samples s
+			WHERE ` + samplePublic + sampleFilter + `
+		), c AS (
+			SELECT CASE
+					WHEN GROUPING(genre) = 0 THEN 'genre'
+					WHEN GROUPING(author_uuid) = 0 THEN 'author'
+					WHEN GROUPING(price) = 0 THEN 'price'
+					ELSE 'duration'
+				END AS facet,
+				coalesce(genre, author_uuid::text, price, duration) AS value,
+				count(*) AS cnt
+			FROM f
+			GROUP BY GROUPING SETS ((genre), (author_uuid), (price), (duration))
+			UNION ALL
+			SELECT 'pack', ps.pack_id::text, count(*)
+			FROM f JOIN pack_samples ps ON ps.sample_id = f.id JOIN packs dp ON dp.id = ps.pack_id
+			WHERE dp.deleted_at IS NULL
+			GROUP BY ps.pack_id
 		)
+		SELECT c.facet, c.value, coalesce(g.name, coalesce(u.display_name, u.login), p.name, c.value), c.cnt
+		FROM c
+		LEFT JOIN genres g ON c.facet = 'genre' AND g.slug = c.value
+		LEFT JOIN users u ON c.facet = 'author' AND u.uuid::text = c.value
+		LEFT JOIN packs p ON c.facet = 'pack' AND p.id::text = c.value
+		WHERE c.value IS NOT NULL
+		ORDER BY c.cnt DESC, 3`
+
+	var facets entity.Facets
+	rows, err := r.db.Query(ctx, query, filterArgs(filter)...)
+	if err != nil {
+		return facets, fmt.Errorf("failed to count facets: %w", err)
+	}
+	defer rows.Close()
 
-		if err != nil {
-			return nil, fmt.Errorf("error get all samples from DB: %w", err)
+	for rows.Next() {
+		var facet string
+		var value entity.FacetValue
+		if err = rows.Scan(&facet, &value.Value, &value.Label, &value.Count); err != nil {
+			return facets, fmt.Errorf("failed to scan facet: %w", err)
 		}
 
-		sample.Genre = entity.Genre(genre)
-		if packID.Valid {
-			sample.PackID = &packID.V
+		switch facet {
+		case "genre":
+			facets.Genres = append(facets.Genres, value)
+		case "author":
+			facets.Authors = append(facets.Authors, value)
+		case "pack":
+			facets.Packs = append(facets.Packs, value)
+		case "price":
+			facets.Prices = append(facets.Prices, value)
+		case "duration":
+			facets.Durations = append(facets.Durations, value)
 		}
-
-		samples = append(samples, sample)
 	}
 
-	return samples, nil
+	return facets, rows.Err()
 }
 
+// GetByPack возвращает семплы пака в порядке треков
 func (r *Sample) GetByPack(ctx context.Context, packID uuid.UUID) ([]entity.Sample, error) {
-	query := `
-	SELECT id, title, author, description, genre, duration, size, minio_key, pack_id, price, created_at, updated_at
-	FROM samples WHERE pack_id = $1 ORDER BY created_at DESC`
+	query := `SELECT ` + sampleColumns + sampleFrom + `
+	JOIN pack_samples ps ON ps.sample_id = s.id
+	WHERE ps.pack_id = $1 AND ` + samplePublic + `
+	ORDER BY ps.position, ps.added_at`
 
 	rows, err := r.db.Query(ctx, query, packID)
 	if errors.Is(err, pgx.ErrNoRows) {
@@ -110,47 +248,148 @@ func (r *Sample) GetByPack(ctx context.Context, packID uuid.UUID) ([]entity.Samp
 		return nil, err
 	}
 
+	return r.scanSamples(rows)
+}
+
+// GetPackTracks возвращает все семплы пака в порядке треков, включая удаленные и снятые с продажи:
+// купившие их сохраняют доступ
+func (r *Sample) GetPackTracks(ctx context.Context, packID uuid.UUID) ([]entity.Sample, error) {
+	query := `SELECT ` + sampleColumns + sampleFrom + `
+	JOIN pack_samples ps ON ps.sample_id = s.id
+	WHERE ps.pack_id = $1
+	ORDER BY ps.position, ps.added_at`
+
+	rows, err := r.db.Query(ctx, query, packID)
+	if err != nil {
+		return nil, fmt.Errorf("error get pack tracks from DB: %w", err)
+	}
+
+	return r.scanSamples(rows)
+}
+
+// GetByAuthor возвращает страницу семплов автора и их общее количество.
+// drafts - включать неопубликованные и снятые с продажи, их видит только сам автор
+func (r *Sample) GetByAuthor(ctx context.Context, authorUUID uuid.UUID, drafts bool, page entity.Page) ([]entity.Sample, int, error) {
+	query := `SELECT ` + sampleColumns + `, count(*) OVER ()` + sampleFrom + `
+	WHERE s.author_uuid = $1 AND s.deleted_at IS NULL AND ($2 OR (` + samplePublic + `))
+	ORDER BY s.created_at DESC
+	LIMIT $3 OFFSET $4`
+
+	rows, err := r.db.Query(ctx, query, authorUUID, drafts, page.Limit, page.Offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error get author samples from DB: %w", err)
+	}
+	defer rows.Close()
+
 	var samples []entity.Sample
+	var total int
 	for rows.Next() {
-		var sample entity.Sample
-		var genre string
-		var packID sql.Null[uuid.UUID]
-
-		err = rows.Scan(
-			&sample.ID, &sample.Title, &sample.Author, &sample.Description, &genre,
-			&sample.Duration, &sample.Size, &sample.MinioKey,
-			&packID, &sample.Price, &sample.CreatedAt, &sample.UpdatedAt,
-		)
-
+		sample, err := r.scanSample(rows, &total)
 		if err != nil {
-			return nil, fmt.Errorf("error get all samples from DB: %w", err)
-		}
-
-		sample.Genre = entity.Genre(genre)
-		if packID.Valid {
-			sample.PackID = &packID.V
+			return nil, 0, fmt.Errorf("error get author samples from DB: %w", err)
 		}
 
 		samples = append(samples, sample)
 	}
 
-	return samples, nil
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating samples: %w", err)
+	}
+
+	return samples, total, nil
+}
+
+// GetPublished возвращает опубликованные семплы из ids в том же порядке, остальные пропускаются
+func (r *Sample) GetPublished(ctx context.Context, ids []uuid.UUID) ([]entity.Sample, error) {
+	query := `SELECT ` + sampleColumns + sampleFrom + `
+	WHERE s.id = ANY($1) AND ` + samplePublic + `
+	ORDER BY array_position($1, s.id)`
+
+	rows, err := r.db.Query(ctx, query, ids)
+	if err != nil {
+		return nil, fmt.Errorf("error get samples by ids from DB: %w", err)
+	}
+
+	return r.scanSamples(rows)
+}
+
+// GetLiked возвращает семплы, которые лайкнул пользователь, последние лайки первыми
+func (r *Sample) GetLiked(ctx context.Context, userUUID uuid.UUID) ([]entity.Sample, error) {
+	query := `SELECT ` + sampleColumns + sampleFrom + `
+	JOIN sample_likes l ON l.sample_id = s.id
+	WHERE l.user_uuid = $1 AND ` + samplePublic + `
+	ORDER BY l.created_at DESC`
+
+	rows, err := r.db.Query(ctx, query, userUUID)
+	if err != nil {
+		return nil, fmt.Errorf("error get liked samples from DB: %w", err)
+	}
+
+	return r.scanSamples(rows)
+}
+
+// GetByCollection возвращает семплы подборки в порядке, заданном владельцем
+func (r *Sample) GetByCollection(ctx context.Context, collectionID uuid.UUID) ([]entity.Sample, error) {
+	query := `SELECT ` + sampleColumns + sampleFrom + `
+	JOIN collection_samples c ON c.sample_id = s.id
+	WHERE c.collection_id = $1 AND ` + samplePublic + `
+	ORDER BY c.position`
+
+	rows, err := r.db.Query(ctx, query, collectionID)
+	if err != nil {
+		return nil, fmt.Errorf("error get collection samples from DB: %w", err)
+	}
+
+	return r.scanSamples(rows)
 }
 
 func (r *Sample) Update(ctx context.Context, sample entity.Sample) error {
 	query := `
-	UPDATE samples SET title=$1, author=$2, description=$3, genre=$4, 
-	                   duration=$5, size=$6, minio_key=$7, pack_id=$8, price=$9, updated_at=$10
+	UPDATE samples SET title=$1, description=$2, genre=$3,
+	                   duration=$4, size=$5, minio_key=$6, updated_at=$7,
+	                   audio_version=$8, audio_hash=$9, original_filename=$10
 	WHERE id=$11`
 
 	_, err := r.db.Exec(ctx, query,
-		sample.Title, sample.Author, sample.Description, sample.Genre,
-		sample.Duration, sample.Size, sample.MinioKey, sample.PackID, sample.Price,
-		sample.UpdatedAt, sample.ID)
+		sample.Title, sample.Description, sample.Genre,
+		sample.Duration, sample.Size, sample.MinioKey,
+		sample.UpdatedAt, sample.AudioVersion, sample.AudioHash, sample.OriginalFilename, sample.ID)
 
 	return err
 }
 
+// SetLicenses заменяет лицензии семпла и пересчитывает его цену как минимальную среди них
+func (r *Sample) SetLicenses(ctx context.Context, sampleID uuid.UUID, licenses []entity.SampleLicense) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+
+	if _, err = tx.Exec(ctx, `DELETE FROM sample_licenses WHERE sample_id = $1`, sampleID); err != nil {
+		_ = tx.Rollback(ctx)
+		return fmt.Errorf("failed to delete sample licenses: %w", err)
+	}
+
+	for _, l := range licenses {
+		_, err = tx.Exec(ctx, `INSERT INTO sample_licenses (sample_id, license_type, price) VALUES ($1, $2, $3)`,
+			sampleID, l.Type, l.Price)
+		if err != nil {
+			_ = tx.Rollback(ctx)
+			return fmt.Errorf("failed to create sample license: %w", err)
+		}
+	}
+
+	const query = `
+	UPDATE samples SET price = coalesce((SELECT min(price) FROM sample_licenses WHERE sample_id = $1), 0)
+	WHERE id = $1`
+	if _, err = tx.Exec(ctx, query, sampleID); err != nil {
+		_ = tx.Rollback(ctx)
+		return fmt.Errorf("failed to update sample price: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
 func (r *Sample) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `DELETE FROM samples WHERE id = $1`
 	_, err := r.db.Exec(ctx, query, id)
@@ -161,21 +400,181 @@ func (r *Sample) Delete(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
 
-func (r *Sample) scanSample(row pgx.Row) (entity.Sample, error) {
+// GetDeleted возвращает семплы из корзины, authorUUID == nil - всех авторов
+func (r *Sample) GetDeleted(ctx context.Context, authorUUID *uuid.UUID) ([]entity.Sample, error) {
+	query := `SELECT ` + sampleColumns + sampleFrom + `
+	WHERE s.deleted_at IS NOT NULL AND ($1::uuid IS NULL OR s.author_uuid = $1)
+	ORDER BY s.deleted_at DESC`
+
+	rows, err := r.db.Query(ctx, query, authorUUID)
+	if err != nil {
+		return nil, fmt.Errorf("error get deleted samples from DB: %w", err)
+	}
+
+	return r.scanSamples(rows)
+}
+
+// GetPurgeable возвращает семплы, удаленные раньше before, которые никто не покупал
+func (r *Sample) GetPurgeable(ctx context.Context, before time.Time) ([]entity.Sample, error) {
+	query := `SELECT ` + sampleColumns + sampleFrom + `
+	WHERE s.deleted_at < $1
+	  AND NOT EXISTS (SELECT 1 FROM purchases p WHERE p.sample_id = s.id)`
+
+	rows, err := r.db.Query(ctx, query, before)
+	if err != nil {
+		return nil, fmt.Errorf("error get purgeable samples from DB: %w", err)
+	}
+
+	return r.scanSamples(rows)
+}
+
+// GetLegacyKeyed возвращает все семплы с ключом старого формата, без фильтра по статусу и корзине
+func (r *Sample) GetLegacyKeyed(ctx context.Context) ([]entity.Sample, error) {
+	query := `SELECT ` + sampleColumns + sampleFrom + `
+	WHERE s.minio_key <> '' AND s.minio_key NOT LIKE 'samples/%'
+	ORDER BY s.created_at`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error get legacy keyed samples from DB: %w", err)
+	}
+
+	return r.scanSamples(rows)
+}
+
+// GetWithoutAudio возвращает неудаленные семплы, для которых так и не загрузили аудио
+func (r *Sample) GetWithoutAudio(ctx context.Context) ([]entity.Sample, error) {
+	query := `SELECT ` + sampleColumns + sampleFrom + ` WHERE s.minio_key = '' AND s.deleted_at IS NULL ORDER BY s.created_at`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error get samples without audio from DB: %w", err)
+	}
+
+	return r.scanSamples(rows)
+}
+
+func (r *Sample) SoftDelete(ctx context.Context, id uuid.UUID, deletedAt time.Time) error {
+	query := `UPDATE samples SET deleted_at = $1 WHERE id = $2`
+	_, err := r.db.Exec(ctx, query, deletedAt, id)
+	if err != nil {
+		return fmt.Errorf("failed soft delete sample in DB: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Sample) Restore(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE samples SET deleted_at = NULL WHERE id = $1`
+	_, err := r.db.Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed restore sample in DB: %w", err)
+	}
+
+	return nil
+}
+
+// Submit отправляет черновик на модерацию. Если семпл уже не черновик - domain.ErrSampleStatus
+func (r *Sample) Submit(ctx context.Context, id uuid.UUID, publishAt *time.Time, submittedAt time.Time) error {
+	query := `
+	UPDATE samples SET status = '` + constant.SampleStatusPending + `', publish_at = $1, submitted_at = $2,
+	                   reviewed_by = NULL, reviewed_at = NULL, reject_reason = NULL
+	WHERE id = $3 AND status = '` + constant.SampleStatusDraft + `' AND deleted_at IS NULL`
+
+	tag, err := r.db.Exec(ctx, query, publishAt, submittedAt, id)
+	if err != nil {
+		return fmt.Errorf("failed submit sample in DB: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrSampleStatus
+	}
+
+	return nil
+}
+
+// Resubmit возвращает опубликованный семпл на модерацию, например после замены аудио.
+// Семпл в другом статусе не меняется
+func (r *Sample) Resubmit(ctx context.Context, id uuid.UUID, submittedAt time.Time) error {
+	query := `
+	UPDATE samples SET status = '` + constant.SampleStatusPending + `', submitted_at = $1,
+	                   reviewed_by = NULL, reviewed_at = NULL, reject_reason = NULL
+	WHERE id = $2 AND status = '` + constant.SampleStatusPublished + `' AND deleted_at IS NULL`
+
+	if _, err := r.db.Exec(ctx, query, submittedAt, id); err != nil {
+		return fmt.Errorf("failed resubmit sample in DB: %w", err)
+	}
+
+	return nil
+}
+
+// Review фиксирует решение модератора: status - published или draft с причиной отказа.
+// Если семпл уже не на модерации - domain.ErrSampleStatus
+func (r *Sample) Review(ctx context.Context, id, reviewer uuid.UUID, status, reason string, reviewedAt time.Time) error {
+	query := `
+	UPDATE samples SET status = $1, reviewed_by = $2, reviewed_at = $3, reject_reason = nullif($4, '')
+	WHERE id = $5 AND status = '` + constant.SampleStatusPending + `'`
+
+	tag, err := r.db.Exec(ctx, query, status, reviewer, reviewedAt, reason, id)
+	if err != nil {
+		return fmt.Errorf("failed review sample in DB: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrSampleStatus
+	}
+
+	return nil
+}
+
+// GetPending возвращает очередь модерации, раньше отправленные первыми
+func (r *Sample) GetPending(ctx context.Context) ([]entity.Sample, error) {
+	query := `SELECT ` + sampleColumns + sampleFrom + `
+	WHERE s.status = '` + constant.SampleStatusPending + `' AND s.deleted_at IS NULL
+	ORDER BY s.submitted_at`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error get pending samples from DB: %w", err)
+	}
+
+	return r.scanSamples(rows)
+}
+
+func (r *Sample) scanSamples(rows pgx.Rows) ([]entity.Sample, error) {
+	defer rows.Close()
+
+	var samples []entity.Sample
+	for rows.Next() {
+		sample, err := r.scanSample(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error get all samples from DB: %w", err)
+		}
+
+		samples = append(samples, sample)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating samples: %w", err)
+	}
+
+	return samples, nil
+}
+
+// scanSample читает строку sampleColumns, extra - дополнительные колонки после них
+func (r *Sample) scanSample(row pgx.Row, extra ...any) (entity.Sample, error) {
 	var sample entity.Sample
 	var genre string
-	var packID sql.Null[uuid.UUID]
 
-	err := row.Scan(
-		&sample.ID, &sample.Title, &sample.Author, &sample.Description, &genre,
+	dest := []any{
+		&sample.ID, &sample.Title, &sample.AuthorUUID, &sample.Author, &sample.AuthorName, &sample.Description, &genre,
 		&sample.Duration, &sample.Size, &sample.MinioKey,
-		&packID, &sample.Price, &sample.CreatedAt, &sample.UpdatedAt,
-	)
+		&sample.Price, &sample.CreatedAt, &sample.UpdatedAt,
+		&sample.AudioVersion, &sample.AudioHash, &sample.OriginalFilename, &sample.DeletedAt, &sample.DelistedAt,
+		&sample.Likes, &sample.Status, &sample.PublishAt, &sample.SubmittedAt, &sample.ReviewedAt, &sample.RejectReason,
+		&sample.PackIDs, &sample.Licenses, &sample.Tags,
+	}
+	err := row.Scan(append(dest, extra...)...)
 
 	sample.Genre = entity.Genre(genre)
-	if packID.Valid {
-		sample.PackID = &packID.V
-	}
 
 	return sample, err
 }
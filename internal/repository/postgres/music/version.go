@@ -0,0 +1,119 @@
+package music
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/musicman-backend/internal/domain"
+	"github.com/musicman-backend/internal/domain/entity"
+)
+
+const versionColumns = `id, sample_id, version, minio_key, hash, original_filename, size, duration, uploaded_at`
+
+type SampleVersion struct {
+	db *pgxpool.Pool
+}
+
+func NewSampleVersion(db *pgxpool.Pool) *SampleVersion {
+	return &SampleVersion{db: db}
+}
+
+func (r *SampleVersion) Create(ctx context.Context, version entity.SampleVersion) (uuid.UUID, error) {
+	query := `
+	INSERT INTO sample_versions (sample_id, version, minio_key, hash, original_filename, size, duration, uploaded_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	RETURNING id`
+
+	var id uuid.UUID
+	err := r.db.QueryRow(ctx, query,
+		version.SampleID, version.Version, version.MinioKey, version.Hash,
+		version.OriginalFilename, version.Size, version.Duration, version.UploadedAt,
+	).Scan(&id)
+	if err != nil {
+		return id, fmt.Errorf("failed to create sample version in db: %w", err)
+	}
+
+	return id, nil
+}
+
+func (r *SampleVersion) GetBySample(ctx context.Context, sampleID uuid.UUID) ([]entity.SampleVersion, error) {
+	query := `SELECT ` + versionColumns + ` FROM sample_versions WHERE sample_id = $1 ORDER BY version DESC`
+
+	rows, err := r.db.Query(ctx, query, sampleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sample versions from db: %w", err)
+	}
+	defer rows.Close()
+
+	var versions []entity.SampleVersion
+	for rows.Next() {
+		version, err := r.scanVersion(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan sample version: %w", err)
+		}
+		versions = append(versions, version)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating sample versions: %w", err)
+	}
+
+	return versions, nil
+}
+
+func (r *SampleVersion) GetByVersion(ctx context.Context, sampleID uuid.UUID, version int) (entity.SampleVersion, error) {
+	query := `SELECT ` + versionColumns + ` FROM sample_versions WHERE sample_id = $1 AND version = $2`
+
+	v, err := r.scanVersion(r.db.QueryRow(ctx, query, sampleID, version))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return v, domain.ErrNotFound
+	}
+	if err != nil {
+		return v, fmt.Errorf("failed to get sample version from db: %w", err)
+	}
+
+	return v, nil
+}
+
+// GetAllKeys возвращает все ключи объектов, на которые ссылается база
+func (r *SampleVersion) GetAllKeys(ctx context.Context) ([]string, error) {
+	query := `
+	SELECT minio_key FROM sample_versions
+	UNION
+	SELECT minio_key FROM samples WHERE minio_key <> ''`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object keys from db: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, fmt.Errorf("failed to scan object key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating object keys: %w", err)
+	}
+
+	return keys, nil
+}
+
+func (r *SampleVersion) scanVersion(row pgx.Row) (entity.SampleVersion, error) {
+	var v entity.SampleVersion
+	err := row.Scan(
+		&v.ID, &v.SampleID, &v.Version, &v.MinioKey, &v.Hash,
+		&v.OriginalFilename, &v.Size, &v.Duration, &v.UploadedAt,
+	)
+
+	return v, err
+}
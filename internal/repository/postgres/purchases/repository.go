@@ -4,12 +4,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/musicman-backend/internal/domain"
+	"github.com/musicman-backend/internal/domain/constant"
 	"github.com/musicman-backend/internal/domain/entity"
 )
 
@@ -21,43 +23,125 @@ func New(db *pgxpool.Pool) *Repository {
 	return &Repository{db: db}
 }
 
-func (r *Repository) Create(ctx context.Context, purchase entity.Purchase) (uuid.UUID, error) {
+// Create сохраняет покупку вместе с начислением автору в одной транзакции.
+// Эксклюзивная покупка снимает семпл с продажи, покупка снятого семпла возвращает ErrSampleUnavailable
+func (r *Repository) Create(ctx context.Context, purchase entity.Purchase, earning entity.Earning) (uuid.UUID, error) {
 	const query = `
-		INSERT INTO purchases (id, user_uuid, sample_id, price, created_at)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO purchases (id, user_uuid, sample_id, price, created_at, sample_version, follow_updates, license)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		RETURNING id
 	`
 
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+
+	// блокировка строки семпла не дает двум эксклюзивным покупкам пройти одновременно
+	var delistedAt *time.Time
+	err = tx.QueryRow(ctx, `SELECT delisted_at FROM samples WHERE id = $1 FOR UPDATE`, purchase.SampleID).Scan(&delistedAt)
+	if err != nil {
+		_ = tx.Rollback(ctx)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return uuid.Nil, domain.ErrNotFound
+		}
+		return uuid.Nil, fmt.Errorf("failed to lock sample: %w", err)
+	}
+	if delistedAt != nil {
+		_ = tx.Rollback(ctx)
+		return uuid.Nil, domain.ErrSampleUnavailable
+	}
+
+	// списание с условием на баланс не уводит его в минус при параллельных покупках
+	result, err := tx.Exec(ctx, `UPDATE users SET tokens = tokens - $1 WHERE uuid = $2 AND tokens >= $1`,
+		purchase.Price, purchase.UserUUID)
+	if err != nil {
+		_ = tx.Rollback(ctx)
+		return uuid.Nil, fmt.Errorf("failed to debit buyer: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		_ = tx.Rollback(ctx)
+		return uuid.Nil, domain.ErrInsufficientTokens
+	}
+
+	if purchase.License == constant.LicenseExclusive {
+		_, err = tx.Exec(ctx, `UPDATE samples SET delisted_at = $1, status = $2 WHERE id = $3`,
+			purchase.CreatedAt, constant.SampleStatusDelisted, purchase.SampleID)
+		if err != nil {
+			_ = tx.Rollback(ctx)
+			return uuid.Nil, fmt.Errorf("failed to delist sample: %w", err)
+		}
+	}
+
 	var id uuid.UUID
-	err := r.db.QueryRow(ctx, query,
+	err = tx.QueryRow(ctx, query,
 		purchase.ID,
 		purchase.UserUUID,
 		purchase.SampleID,
 		purchase.Price,
 		purchase.CreatedAt,
+		purchase.SampleVersion,
+		purchase.FollowUpdates,
+		purchase.License,
 	).Scan(&id)
 	if err != nil {
+		_ = tx.Rollback(ctx)
 		return uuid.Nil, fmt.Errorf("failed to create purchase: %w", err)
 	}
 
+	const earningQuery = `
+		INSERT INTO author_earnings (author_uuid, purchase_id, sample_id, price, commission_percent, commission, amount, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	_, err = tx.Exec(ctx, earningQuery,
+		earning.AuthorUUID,
+		id,
+		purchase.SampleID,
+		earning.Price,
+		earning.CommissionPercent,
+		earning.Commission,
+		earning.Amount,
+		purchase.CreatedAt,
+	)
+	if err != nil {
+		_ = tx.Rollback(ctx)
+		return uuid.Nil, fmt.Errorf("failed to create author earning: %w", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return uuid.Nil, fmt.Errorf("failed to commit purchase: %w", err)
+	}
+
 	return id, nil
 }
 
+func (r *Repository) GetByID(ctx context.Context, id uuid.UUID) (entity.Purchase, error) {
+	const query = `
+		SELECT id, user_uuid, sample_id, price, created_at, sample_version, follow_updates, license
+		FROM purchases
+		WHERE id = $1
+	`
+
+	purchase, err := scanPurchase(r.db.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.Purchase{}, domain.ErrNotFound
+		}
+		return entity.Purchase{}, fmt.Errorf("failed to get purchase: %w", err)
+	}
+
+	return purchase, nil
+}
+
 func (r *Repository) GetByUserAndSample(ctx context.Context, userUUID, sampleID uuid.UUID) (entity.Purchase, error) {
 	const query = `
-		SELECT id, user_uuid, sample_id, price, created_at
+		SELECT id, user_uuid, sample_id, price, created_at, sample_version, follow_updates, license
 		FROM purchases
 		WHERE user_uuid = $1 AND sample_id = $2
 	`
 
-	var purchase entity.Purchase
-	err := r.db.QueryRow(ctx, query, userUUID, sampleID).Scan(
-		&purchase.ID,
-		&purchase.UserUUID,
-		&purchase.SampleID,
-		&purchase.Price,
-		&purchase.CreatedAt,
-	)
+	purchase, err := scanPurchase(r.db.QueryRow(ctx, query, userUUID, sampleID))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return entity.Purchase{}, domain.ErrNotFound
@@ -70,7 +154,7 @@ func (r *Repository) GetByUserAndSample(ctx context.Context, userUUID, sampleID
 
 func (r *Repository) GetByUser(ctx context.Context, userUUID uuid.UUID) ([]entity.Purchase, error) {
 	const query = `
-		SELECT id, user_uuid, sample_id, price, created_at
+		SELECT id, user_uuid, sample_id, price, created_at, sample_version, follow_updates, license
 		FROM purchases
 		WHERE user_uuid = $1
 		ORDER BY created_at DESC
@@ -84,14 +168,7 @@ func (r *Repository) GetByUser(ctx context.Context, userUUID uuid.UUID) ([]entit
 
 	var purchases []entity.Purchase
 	for rows.Next() {
-		var purchase entity.Purchase
-		err := rows.Scan(
-			&purchase.ID,
-			&purchase.UserUUID,
-			&purchase.SampleID,
-			&purchase.Price,
-			&purchase.CreatedAt,
-		)
+		purchase, err := scanPurchase(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan purchase: %w", err)
 		}
@@ -104,3 +181,38 @@ func (r *Repository) GetByUser(ctx context.Context, userUUID uuid.UUID) ([]entit
 
 	return purchases, nil
 }
+
+func (r *Repository) UpdateFollowUpdates(ctx context.Context, id uuid.UUID, followUpdates bool, sampleVersion int) error {
+	const query = `
+		UPDATE purchases
+		SET follow_updates = $1, sample_version = $2
+		WHERE id = $3
+	`
+
+	result, err := r.db.Exec(ctx, query, followUpdates, sampleVersion, id)
+	if err != nil {
+		return fmt.Errorf("failed to update purchase: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+func scanPurchase(row pgx.Row) (entity.Purchase, error) {
+	var purchase entity.Purchase
+	err := row.Scan(
+		&purchase.ID,
+		&purchase.UserUUID,
+		&purchase.SampleID,
+		&purchase.Price,
+		&purchase.CreatedAt,
+		&purchase.SampleVersion,
+		&purchase.FollowUpdates,
+		&purchase.License,
+	)
+
+	return purchase, err
+}
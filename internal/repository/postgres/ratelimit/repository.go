@@ -0,0 +1,93 @@
+package ratelimit
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/musicman-backend/internal/domain/entity"
+)
+
+// Repository - общее для всех инстансов хранилище счетчиков ограничителя
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func New(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+func (r *Repository) Update(ctx context.Context, key string, fn func(state entity.RateLimitState) entity.RateLimitState) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+
+	// строка должна существовать, чтобы параллельные запросы по новому ключу ждали друг друга на FOR UPDATE
+	_, err = tx.Exec(ctx, `INSERT INTO rate_limits (key) VALUES ($1) ON CONFLICT (key) DO NOTHING`, key)
+	if err != nil {
+		_ = tx.Rollback(ctx)
+		return fmt.Errorf("failed to create rate limit key: %w", err)
+	}
+
+	const selectQuery = `
+		SELECT key, tokens, failures, locked_until, updated_at
+		FROM rate_limits
+		WHERE key = $1
+		FOR UPDATE
+	`
+
+	var state entity.RateLimitState
+	var lockedUntil, updatedAt sql.Null[time.Time]
+	err = tx.QueryRow(ctx, selectQuery, key).Scan(&state.Key, &state.Tokens, &state.Failures, &lockedUntil, &updatedAt)
+	if err != nil {
+		_ = tx.Rollback(ctx)
+		return fmt.Errorf("failed to get rate limit state: %w", err)
+	}
+	state.LockedUntil = lockedUntil.V
+	state.UpdatedAt = updatedAt.V
+
+	state = fn(state)
+
+	const updateQuery = `
+		UPDATE rate_limits
+		SET tokens = $1, failures = $2, locked_until = $3, updated_at = $4
+		WHERE key = $5
+	`
+
+	_, err = tx.Exec(ctx, updateQuery,
+		state.Tokens,
+		state.Failures,
+		nullTime(state.LockedUntil),
+		nullTime(state.UpdatedAt),
+		key,
+	)
+	if err != nil {
+		_ = tx.Rollback(ctx)
+		return fmt.Errorf("failed to update rate limit state: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *Repository) DeleteIdle(ctx context.Context, before time.Time) (int64, error) {
+	const query = `
+		DELETE FROM rate_limits
+		WHERE (updated_at IS NULL OR updated_at < $1)
+		  AND (locked_until IS NULL OR locked_until < $2)
+	`
+
+	result, err := r.db.Exec(ctx, query, before, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete idle rate limits: %w", err)
+	}
+
+	return result.RowsAffected(), nil
+}
+
+func nullTime(t time.Time) sql.Null[time.Time] {
+	return sql.Null[time.Time]{V: t, Valid: !t.IsZero()}
+}
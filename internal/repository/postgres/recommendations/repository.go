@@ -0,0 +1,174 @@
+package recommendations
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/musicman-backend/internal/domain/constant"
+	"github.com/musicman-backend/internal/domain/entity"
+)
+
+// published - семпл виден в каталоге: одобрен модератором, время публикации наступило и не удален
+const published = `s.deleted_at IS NULL AND s.status = '` + constant.SampleStatusPublished + `'
+	AND (s.publish_at IS NULL OR s.publish_at <= now() AT TIME ZONE 'UTC')`
+
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func New(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+// Catalogue - признаки всех опубликованных семплов
+func (r *Repository) Catalogue(ctx context.Context) ([]entity.SampleFeatures, error) {
+	rows, err := r.db.Query(ctx, `SELECT s.id, s.author_uuid, s.genre, s.price, s.duration FROM samples s WHERE `+published)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sample features: %w", err)
+	}
+	defer rows.Close()
+
+	var features []entity.SampleFeatures
+	for rows.Next() {
+		var f entity.SampleFeatures
+		if err = rows.Scan(&f.ID, &f.AuthorUUID, &f.Genre, &f.Price, &f.Duration); err != nil {
+			return nil, fmt.Errorf("failed to scan sample features: %w", err)
+		}
+		features = append(features, f)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating sample features: %w", err)
+	}
+
+	return features, nil
+}
+
+// Purchases - все пары покупатель-семпл
+func (r *Repository) Purchases(ctx context.Context) ([]entity.UserSample, error) {
+	rows, err := r.db.Query(ctx, `SELECT user_uuid, sample_id FROM purchases`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get purchases: %w", err)
+	}
+	defer rows.Close()
+
+	var purchases []entity.UserSample
+	for rows.Next() {
+		var p entity.UserSample
+		if err = rows.Scan(&p.UserUUID, &p.SampleID); err != nil {
+			return nil, fmt.Errorf("failed to scan purchase: %w", err)
+		}
+		purchases = append(purchases, p)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating purchases: %w", err)
+	}
+
+	return purchases, nil
+}
+
+// ReplaceNeighbours заменяет все списки похожих семплов одной транзакцией,
+// читатели до коммита видят прошлый расчет
+func (r *Repository) ReplaceNeighbours(ctx context.Context, neighbours []entity.SampleNeighbour) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	if _, err = tx.Exec(ctx, `DELETE FROM sample_neighbours`); err != nil {
+		_ = tx.Rollback(ctx)
+		return fmt.Errorf("failed to delete neighbours: %w", err)
+	}
+
+	_, err = tx.CopyFrom(ctx,
+		pgx.Identifier{"sample_neighbours"},
+		[]string{"sample_id", "neighbour_id", "score"},
+		pgx.CopyFromSlice(len(neighbours), func(i int) ([]any, error) {
+			n := neighbours[i]
+			return []any{n.SampleID, n.NeighbourID, n.Score}, nil
+		}),
+	)
+	if err != nil {
+		_ = tx.Rollback(ctx)
+		return fmt.Errorf("failed to insert neighbours: %w", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// Neighbours - опубликованные семплы, похожие на sampleID, самые похожие первыми
+func (r *Repository) Neighbours(ctx context.Context, sampleID uuid.UUID, limit int) ([]uuid.UUID, error) {
+	query := `SELECT n.neighbour_id FROM sample_neighbours n
+	JOIN samples s ON s.id = n.neighbour_id
+	WHERE n.sample_id = $1 AND ` + published + `
+	ORDER BY n.score DESC, n.neighbour_id
+	LIMIT $2`
+
+	return r.ids(ctx, query, sampleID, limit)
+}
+
+// Seeds - семплы, которые пользователь купил или лайкнул: по ним строится его лента
+func (r *Repository) Seeds(ctx context.Context, userUUID uuid.UUID) ([]uuid.UUID, error) {
+	query := `SELECT sample_id FROM purchases WHERE user_uuid = $1
+	UNION
+	SELECT sample_id FROM sample_likes WHERE user_uuid = $1`
+
+	return r.ids(ctx, query, userUUID)
+}
+
+// Feed - семплы, похожие сразу на несколько seeds, выше. Сами seeds и семплы автора не попадают
+func (r *Repository) Feed(ctx context.Context, userUUID uuid.UUID, seeds []uuid.UUID, limit int) ([]uuid.UUID, error) {
+	query := `SELECT n.neighbour_id FROM sample_neighbours n
+	JOIN samples s ON s.id = n.neighbour_id
+	WHERE n.sample_id = ANY($2) AND NOT n.neighbour_id = ANY($2) AND s.author_uuid <> $1 AND ` + published + `
+	GROUP BY n.neighbour_id
+	ORDER BY sum(n.score) DESC, n.neighbour_id
+	LIMIT $3`
+
+	return r.ids(ctx, query, userUUID, seeds, limit)
+}
+
+// Trending - опубликованные семплы по активности с since: скачивания весят больше прослушиваний,
+// покупки больше скачиваний. Без активности - по лайкам и новизне. exclude и семплы excludeAuthor пропускаются
+func (r *Repository) Trending(ctx context.Context, since time.Time, exclude []uuid.UUID, excludeAuthor uuid.UUID, limit int) ([]uuid.UUID, error) {
+	query := `SELECT s.id FROM samples s
+	LEFT JOIN (
+		SELECT sample_id, sum(plays + 3 * downloads + 10 * purchases) AS score
+		FROM sample_stats_daily
+		WHERE day >= $1
+		GROUP BY sample_id
+	) t ON t.sample_id = s.id
+	WHERE ` + published + ` AND NOT s.id = ANY($2) AND s.author_uuid <> $3
+	ORDER BY coalesce(t.score, 0) DESC, s.like_count DESC, s.created_at DESC
+	LIMIT $4`
+
+	if exclude == nil {
+		exclude = []uuid.UUID{}
+	}
+
+	return r.ids(ctx, query, since, exclude, excludeAuthor, limit)
+}
+
+func (r *Repository) ids(ctx context.Context, query string, args ...any) ([]uuid.UUID, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recommendations: %w", err)
+	}
+
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan recommendations: %w", err)
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,175 @@
+package sessions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/musicman-backend/internal/domain"
+	"github.com/musicman-backend/internal/domain/entity"
+)
+
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func New(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+func (r *Repository) Create(ctx context.Context, session entity.Session) error {
+	const query = `
+		INSERT INTO sessions (id, user_uuid, device_name, user_agent, ip, created_at, last_seen_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	_, err := r.db.Exec(ctx, query,
+		session.ID,
+		session.UserUUID,
+		session.DeviceName,
+		session.UserAgent,
+		session.IP,
+		session.CreatedAt,
+		session.LastSeenAt,
+		session.ExpiresAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Repository) GetByID(ctx context.Context, id uuid.UUID) (entity.Session, error) {
+	const query = `
+		SELECT id, user_uuid, device_name, user_agent, ip, created_at, last_seen_at, expires_at, revoked_at
+		FROM sessions
+		WHERE id = $1
+	`
+
+	session, err := scanSession(r.db.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.Session{}, domain.ErrNotFound
+		}
+		return entity.Session{}, fmt.Errorf("failed to get session: %w", err)
+	}
+
+	return session, nil
+}
+
+// GetActiveByUser - неотозванные и неистекшие сессии, последние активные первыми
+func (r *Repository) GetActiveByUser(ctx context.Context, userUUID uuid.UUID, now time.Time) ([]entity.Session, error) {
+	const query = `
+		SELECT id, user_uuid, device_name, user_agent, ip, created_at, last_seen_at, expires_at, revoked_at
+		FROM sessions
+		WHERE user_uuid = $1 AND revoked_at IS NULL AND expires_at > $2
+		ORDER BY last_seen_at DESC
+	`
+
+	rows, err := r.db.Query(ctx, query, userUUID, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sessions by user: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []entity.Session
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan session: %w", err)
+		}
+		sessions = append(sessions, session)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating sessions: %w", err)
+	}
+
+	return sessions, nil
+}
+
+// Revoke отзывает сессию пользователя. Чужая или уже отозванная сессия - domain.ErrNotFound
+func (r *Repository) Revoke(ctx context.Context, userUUID, id uuid.UUID, revokedAt time.Time) error {
+	const query = `UPDATE sessions SET revoked_at = $1 WHERE id = $2 AND user_uuid = $3 AND revoked_at IS NULL`
+
+	result, err := r.db.Exec(ctx, query, revokedAt, id, userUUID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+// RevokeAll отзывает все сессии пользователя, кроме except, и возвращает их id
+func (r *Repository) RevokeAll(ctx context.Context, userUUID, except uuid.UUID, revokedAt time.Time) ([]uuid.UUID, error) {
+	const query = `
+		UPDATE sessions SET revoked_at = $1
+		WHERE user_uuid = $2 AND id <> $3 AND revoked_at IS NULL
+		RETURNING id
+	`
+
+	rows, err := r.db.Query(ctx, query, revokedAt, userUUID, except)
+	if err != nil {
+		return nil, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+	if err != nil {
+		return nil, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	return ids, nil
+}
+
+// Touch обновляет время последней активности и ip. false - сессия отозвана, истекла или ее нет
+func (r *Repository) Touch(ctx context.Context, id uuid.UUID, ip string, seenAt time.Time) (bool, error) {
+	const query = `
+		UPDATE sessions SET last_seen_at = $1, ip = $2
+		WHERE id = $3 AND revoked_at IS NULL AND expires_at > $1
+	`
+
+	result, err := r.db.Exec(ctx, query, seenAt, ip, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to touch session: %w", err)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+// DeleteStale удаляет истекшие и отозванные до before сессии
+func (r *Repository) DeleteStale(ctx context.Context, before time.Time) (int64, error) {
+	const query = `DELETE FROM sessions WHERE expires_at < $1 OR revoked_at < $1`
+
+	result, err := r.db.Exec(ctx, query, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete stale sessions: %w", err)
+	}
+
+	return result.RowsAffected(), nil
+}
+
+func scanSession(row pgx.Row) (entity.Session, error) {
+	var session entity.Session
+	err := row.Scan(
+		&session.ID,
+		&session.UserUUID,
+		&session.DeviceName,
+		&session.UserAgent,
+		&session.IP,
+		&session.CreatedAt,
+		&session.LastSeenAt,
+		&session.ExpiresAt,
+		&session.RevokedAt,
+	)
+
+	return session, err
+}
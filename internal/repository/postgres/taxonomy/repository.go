@@ -0,0 +1,318 @@
+package taxonomy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/musicman-backend/internal/domain"
+	"github.com/musicman-backend/internal/domain/constant"
+	"github.com/musicman-backend/internal/domain/entity"
+)
+
+const codeUniqueViolation = "23505"
+
+// published - семпл виден в каталоге, только такие попадают в счетчики
+const published = `s.deleted_at IS NULL AND s.status = '` + constant.SampleStatusPublished + `'
+	AND (s.publish_at IS NULL OR s.publish_at <= now() AT TIME ZONE 'UTC')`
+
+const genreColumns = `g.id, g.slug, g.name, g.parent_id, g.created_at`
+
+const tagColumns = `t.id, t.kind, t.slug, t.name`
+
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+func New(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+// Genres - весь справочник жанров со счетчиками
+func (r *Repository) Genres(ctx context.Context) ([]entity.GenreNode, error) {
+	query := `SELECT ` + genreColumns + `,
+		(SELECT count(*) FROM samples s WHERE s.genre = g.slug AND ` + published + `),
+		(SELECT count(*) FROM packs p WHERE p.genre = g.slug AND p.deleted_at IS NULL)
+	FROM genres g
+	ORDER BY g.name`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get genres: %w", err)
+	}
+	defer rows.Close()
+
+	var genres []entity.GenreNode
+	for rows.Next() {
+		var g entity.GenreNode
+		if err = rows.Scan(&g.ID, &g.Slug, &g.Name, &g.ParentID, &g.CreatedAt, &g.Samples, &g.Packs); err != nil {
+			return nil, fmt.Errorf("failed to scan genre: %w", err)
+		}
+		genres = append(genres, g)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating genres: %w", err)
+	}
+
+	return genres, nil
+}
+
+func (r *Repository) GenreBySlug(ctx context.Context, slug string) (entity.GenreNode, error) {
+	var g entity.GenreNode
+	err := r.db.QueryRow(ctx, `SELECT `+genreColumns+` FROM genres g WHERE g.slug = $1`, slug).
+		Scan(&g.ID, &g.Slug, &g.Name, &g.ParentID, &g.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return g, domain.ErrNotFound
+	}
+	if err != nil {
+		return g, fmt.Errorf("failed to get genre: %w", err)
+	}
+
+	return g, nil
+}
+
+func (r *Repository) CreateGenre(ctx context.Context, genre entity.GenreNode) (uuid.UUID, error) {
+	var id uuid.UUID
+	err := r.db.QueryRow(ctx, `INSERT INTO genres (slug, name, parent_id, created_at) VALUES ($1, $2, $3, $4) RETURNING id`,
+		genre.Slug, genre.Name, genre.ParentID, genre.CreatedAt).Scan(&id)
+	if isUniqueViolation(err) {
+		return id, domain.ErrTaxonomyExists
+	}
+	if err != nil {
+		return id, fmt.Errorf("failed to create genre: %w", err)
+	}
+
+	return id, nil
+}
+
+// UpdateGenre меняет название, slug и родителя. Новый slug каскадом переходит к семплам и пакам
+func (r *Repository) UpdateGenre(ctx context.Context, genre entity.GenreNode) error {
+	_, err := r.db.Exec(ctx, `UPDATE genres SET slug = $2, name = $3, parent_id = $4 WHERE id = $1`,
+		genre.ID, genre.Slug, genre.Name, genre.ParentID)
+	if isUniqueViolation(err) {
+		return domain.ErrTaxonomyExists
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update genre: %w", err)
+	}
+
+	return nil
+}
+
+// MergeGenres переносит семплы, паки и поджанры source в target и удаляет source
+func (r *Repository) MergeGenres(ctx context.Context, source, target entity.GenreNode) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	queries := []struct {
+		query string
+		args  []any
+	}{
+		{`UPDATE samples SET genre = $2 WHERE genre = $1`, []any{source.Slug, target.Slug}},
+		{`UPDATE packs SET genre = $2 WHERE genre = $1`, []any{source.Slug, target.Slug}},
+		{`UPDATE genres SET parent_id = $2 WHERE parent_id = $1 AND id <> $2`, []any{source.ID, target.ID}},
+		{`DELETE FROM genres WHERE id = $1`, []any{source.ID}},
+	}
+	for _, q := range queries {
+		if _, err = tx.Exec(ctx, q.query, q.args...); err != nil {
+			_ = tx.Rollback(ctx)
+			return fmt.Errorf("failed to merge genres: %w", err)
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// Tags - справочник тегов вида kind (пусто - всех видов), search ищет по началу slug. Популярные первыми
+func (r *Repository) Tags(ctx context.Context, kind, search string, limit int) ([]entity.Tag, error) {
+	query := `SELECT ` + tagColumns + `, count(s.id)
+	FROM tags t
+	LEFT JOIN sample_tags st ON st.tag_id = t.id
+	LEFT JOIN samples s ON s.id = st.sample_id AND ` + published + `
+	WHERE ($1 = '' OR t.kind = $1) AND ($2 = '' OR t.slug LIKE $2 || '%')
+	GROUP BY t.id
+	ORDER BY count(s.id) DESC, t.name
+	LIMIT $3`
+
+	rows, err := r.db.Query(ctx, query, kind, search, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tags: %w", err)
+	}
+	defer rows.Close()
+
+	var tags []entity.Tag
+	for rows.Next() {
+		var t entity.Tag
+		if err = rows.Scan(&t.ID, &t.Kind, &t.Slug, &t.Name, &t.Samples); err != nil {
+			return nil, fmt.Errorf("failed to scan tag: %w", err)
+		}
+		tags = append(tags, t)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tags: %w", err)
+	}
+
+	return tags, nil
+}
+
+func (r *Repository) TagByID(ctx context.Context, id uuid.UUID) (entity.Tag, error) {
+	var t entity.Tag
+	err := r.db.QueryRow(ctx, `SELECT `+tagColumns+` FROM tags t WHERE t.id = $1`, id).Scan(&t.ID, &t.Kind, &t.Slug, &t.Name)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return t, domain.ErrNotFound
+	}
+	if err != nil {
+		return t, fmt.Errorf("failed to get tag: %w", err)
+	}
+
+	return t, nil
+}
+
+// TagsBySlugs - существующие теги вида kind, отсутствующие slug пропускаются
+func (r *Repository) TagsBySlugs(ctx context.Context, kind string, slugs []string) ([]entity.Tag, error) {
+	rows, err := r.db.Query(ctx, `SELECT `+tagColumns+` FROM tags t WHERE t.kind = $1 AND t.slug = ANY($2)`, kind, slugs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tags: %w", err)
+	}
+
+	tags, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (entity.Tag, error) {
+		var t entity.Tag
+		err := row.Scan(&t.ID, &t.Kind, &t.Slug, &t.Name)
+		return t, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan tags: %w", err)
+	}
+
+	return tags, nil
+}
+
+func (r *Repository) CreateTag(ctx context.Context, tag entity.Tag) (uuid.UUID, error) {
+	var id uuid.UUID
+	err := r.db.QueryRow(ctx, `INSERT INTO tags (kind, slug, name) VALUES ($1, $2, $3) RETURNING id`,
+		tag.Kind, tag.Slug, tag.Name).Scan(&id)
+	if isUniqueViolation(err) {
+		return id, domain.ErrTaxonomyExists
+	}
+	if err != nil {
+		return id, fmt.Errorf("failed to create tag: %w", err)
+	}
+
+	return id, nil
+}
+
+// EnsureTags создает недостающие теги и возвращает все переданные
+func (r *Repository) EnsureTags(ctx context.Context, kind string, tags []entity.Tag) ([]entity.Tag, error) {
+	slugs := make([]string, len(tags))
+	names := make([]string, len(tags))
+	for i, t := range tags {
+		slugs[i] = t.Slug
+		names[i] = t.Name
+	}
+
+	_, err := r.db.Exec(ctx, `INSERT INTO tags (kind, slug, name)
+		SELECT $1, slug, name FROM unnest($2::text[], $3::text[]) AS n(slug, name)
+		ON CONFLICT (kind, slug) DO NOTHING`, kind, slugs, names)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tags: %w", err)
+	}
+
+	return r.TagsBySlugs(ctx, kind, slugs)
+}
+
+func (r *Repository) RenameTag(ctx context.Context, tag entity.Tag) error {
+	_, err := r.db.Exec(ctx, `UPDATE tags SET slug = $2, name = $3 WHERE id = $1`, tag.ID, tag.Slug, tag.Name)
+	if isUniqueViolation(err) {
+		return domain.ErrTaxonomyExists
+	}
+	if err != nil {
+		return fmt.Errorf("failed to rename tag: %w", err)
+	}
+
+	return nil
+}
+
+// MergeTags переносит связи source на target и удаляет source
+func (r *Repository) MergeTags(ctx context.Context, sourceID, targetID uuid.UUID) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	queries := []string{
+		`INSERT INTO sample_tags (sample_id, tag_id) SELECT sample_id, $2 FROM sample_tags WHERE tag_id = $1 ON CONFLICT DO NOTHING`,
+		`INSERT INTO pack_tags (pack_id, tag_id) SELECT pack_id, $2 FROM pack_tags WHERE tag_id = $1 ON CONFLICT DO NOTHING`,
+	}
+	for _, query := range queries {
+		if _, err = tx.Exec(ctx, query, sourceID, targetID); err != nil {
+			_ = tx.Rollback(ctx)
+			return fmt.Errorf("failed to merge tags: %w", err)
+		}
+	}
+
+	// связи source удаляются каскадом
+	if _, err = tx.Exec(ctx, `DELETE FROM tags WHERE id = $1`, sourceID); err != nil {
+		_ = tx.Rollback(ctx)
+		return fmt.Errorf("failed to delete merged tag: %w", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// SetSampleTags заменяет теги семпла
+func (r *Repository) SetSampleTags(ctx context.Context, sampleID uuid.UUID, tagIDs []uuid.UUID) error {
+	return r.setTags(ctx, `sample_tags`, `sample_id`, sampleID, tagIDs)
+}
+
+// SetPackTags заменяет теги пака
+func (r *Repository) SetPackTags(ctx context.Context, packID uuid.UUID, tagIDs []uuid.UUID) error {
+	return r.setTags(ctx, `pack_tags`, `pack_id`, packID, tagIDs)
+}
+
+func (r *Repository) setTags(ctx context.Context, table, column string, id uuid.UUID, tagIDs []uuid.UUID) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	if _, err = tx.Exec(ctx, `DELETE FROM `+table+` WHERE `+column+` = $1`, id); err != nil {
+		_ = tx.Rollback(ctx)
+		return fmt.Errorf("failed to delete tags: %w", err)
+	}
+
+	if len(tagIDs) > 0 {
+		_, err = tx.Exec(ctx, `INSERT INTO `+table+` (`+column+`, tag_id) SELECT $1, unnest($2::uuid[])`, id, tagIDs)
+		if err != nil {
+			_ = tx.Rollback(ctx)
+			return fmt.Errorf("failed to insert tags: %w", err)
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == codeUniqueViolation
+}
@@ -0,0 +1,84 @@
+package users
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/musicman-backend/internal/domain"
+)
+
+// RecoveryCodes - одноразовые коды восстановления 2FA, хранятся только хеши
+type RecoveryCodes struct {
+	db *pgxpool.Pool
+}
+
+func NewRecoveryCodes(db *pgxpool.Pool) *RecoveryCodes {
+	return &RecoveryCodes{db: db}
+}
+
+// Replace заменяет все коды пользователя новыми
+func (r *RecoveryCodes) Replace(ctx context.Context, userUUID uuid.UUID, hashes []string, createdAt time.Time) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `DELETE FROM recovery_codes WHERE user_uuid = $1`, userUUID)
+	if err != nil {
+		_ = tx.Rollback(ctx)
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+
+	for _, hash := range hashes {
+		_, err = tx.Exec(ctx,
+			`INSERT INTO recovery_codes (user_uuid, code_hash, created_at) VALUES ($1, $2, $3)`,
+			userUUID, hash, createdAt,
+		)
+		if err != nil {
+			_ = tx.Rollback(ctx)
+			return fmt.Errorf("failed to create recovery code: %w", err)
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Use гасит код. Неизвестный или уже использованный код - domain.ErrNotFound
+func (r *RecoveryCodes) Use(ctx context.Context, userUUID uuid.UUID, hash string, usedAt time.Time) error {
+	const query = `UPDATE recovery_codes SET used_at = $1 WHERE user_uuid = $2 AND code_hash = $3 AND used_at IS NULL`
+
+	result, err := r.db.Exec(ctx, query, usedAt, userUUID, hash)
+	if err != nil {
+		return fmt.Errorf("failed to use recovery code: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *RecoveryCodes) CountUnused(ctx context.Context, userUUID uuid.UUID) (int, error) {
+	var count int
+	err := r.db.QueryRow(ctx,
+		`SELECT count(*) FROM recovery_codes WHERE user_uuid = $1 AND used_at IS NULL`, userUUID,
+	).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count recovery codes: %w", err)
+	}
+
+	return count, nil
+}
+
+func (r *RecoveryCodes) DeleteByUser(ctx context.Context, userUUID uuid.UUID) error {
+	_, err := r.db.Exec(ctx, `DELETE FROM recovery_codes WHERE user_uuid = $1`, userUUID)
+	if err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+
+	return nil
+}
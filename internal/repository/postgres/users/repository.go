@@ -2,22 +2,43 @@ package users
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/musicman-backend/internal/domain"
 	"github.com/musicman-backend/internal/domain/entity"
 )
 
+const codeUniqueViolation = "23505"
+
+const userColumns = `uuid, login, password, tokens, role, email, email_verified_at,
+		totp_secret, totp_enabled_at, totp_last_step,
+		display_name, bio, links, avatar_key, deleted_at, commission_percent`
+
 type User struct {
-	UUID     uuid.UUID `db:"uuid"`
-	Login    string    `db:"login"`
-	PassHash string    `db:"password"`
-	Tokens   int       `db:"tokens"`
+	UUID            uuid.UUID           `db:"uuid"`
+	Login           string              `db:"login"`
+	PassHash        string              `db:"password"`
+	Tokens          int                 `db:"tokens"`
+	Role            string              `db:"role"`
+	Email           sql.Null[string]    `db:"email"`
+	EmailVerifiedAt sql.Null[time.Time] `db:"email_verified_at"`
+	TOTPSecret      sql.Null[string]    `db:"totp_secret"`
+	TOTPEnabledAt   sql.Null[time.Time] `db:"totp_enabled_at"`
+	TOTPLastStep    int64               `db:"totp_last_step"`
+	DisplayName     sql.Null[string]    `db:"display_name"`
+	Bio             sql.Null[string]    `db:"bio"`
+	Links           []string            `db:"links"`
+	AvatarKey       sql.Null[string]    `db:"avatar_key"`
+	DeletedAt       sql.Null[time.Time] `db:"deleted_at"`
+	Commission      sql.Null[int]       `db:"commission_percent"`
 }
 
 type Repository struct {
@@ -28,20 +49,14 @@ func NewRepository(db *pgxpool.Pool) *Repository {
 	return &Repository{db: db}
 }
 
-func (r *Repository) CreateUser(ctx context.Context, login, passHash string) (entity.User, error) {
+// CreateUser создает пользователя, пустой email - без почты
+func (r *Repository) CreateUser(ctx context.Context, login, passHash, email string) (entity.User, error) {
 	const query = `
-		INSERT INTO users (login, password) 
-		VALUES ($1, $2) 
-		RETURNING uuid, login, password, tokens 
-	`
+		INSERT INTO users (login, password, email) 
+		VALUES ($1, $2, $3) 
+		RETURNING ` + userColumns
 
-	var user User
-	err := r.db.QueryRow(ctx, query, login, passHash).Scan(
-		&user.UUID,
-		&user.Login,
-		&user.PassHash,
-		&user.Tokens,
-	)
+	user, err := scanUser(r.db.QueryRow(ctx, query, login, passHash, nullString(email)))
 	if err != nil {
 		return entity.User{}, fmt.Errorf("failed to create user: %w", err)
 	}
@@ -51,18 +66,12 @@ func (r *Repository) CreateUser(ctx context.Context, login, passHash string) (en
 
 func (r *Repository) GetUserByUUID(ctx context.Context, userUUID uuid.UUID) (entity.User, error) {
 	const query = `
-		SELECT uuid, login, password, tokens 
+		SELECT ` + userColumns + `
 		FROM users 
 		WHERE uuid = $1
 	`
 
-	var user User
-	err := r.db.QueryRow(ctx, query, userUUID).Scan(
-		&user.UUID,
-		&user.Login,
-		&user.PassHash,
-		&user.Tokens,
-	)
+	user, err := scanUser(r.db.QueryRow(ctx, query, userUUID))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return entity.User{}, domain.ErrNotFound
@@ -75,18 +84,12 @@ func (r *Repository) GetUserByUUID(ctx context.Context, userUUID uuid.UUID) (ent
 
 func (r *Repository) GetUserByLogin(ctx context.Context, login string) (entity.User, error) {
 	const query = `
-		SELECT uuid, login, password, tokens 
+		SELECT ` + userColumns + `
 		FROM users 
 		WHERE login = $1
 	`
 
-	var user User
-	err := r.db.QueryRow(ctx, query, login).Scan(
-		&user.UUID,
-		&user.Login,
-		&user.PassHash,
-		&user.Tokens,
-	)
+	user, err := scanUser(r.db.QueryRow(ctx, query, login))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return entity.User{}, domain.ErrNotFound
@@ -97,35 +100,370 @@ func (r *Repository) GetUserByLogin(ctx context.Context, login string) (entity.U
 	return toEntity(user), nil
 }
 
+// UpdateUserBalance прибавляет amount к балансу одним запросом, параллельные пополнения не теряются
 func (r *Repository) UpdateUserBalance(ctx context.Context, userUUID uuid.UUID, amount int) error {
+	result, err := r.db.Exec(ctx, `UPDATE users SET tokens = tokens + $1 WHERE uuid = $2`, amount, userUUID)
+	if err != nil {
+		return fmt.Errorf("failed to update user balance: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+// GetUserByEmail возвращает владельца подтвержденной почты. Неподтвержденный адрес может быть
+// указан у нескольких пользователей и никому не принадлежит
+func (r *Repository) GetUserByEmail(ctx context.Context, email string) (entity.User, error) {
+	const query = `
+		SELECT ` + userColumns + `
+		FROM users 
+		WHERE lower(email) = lower($1) AND email_verified_at IS NOT NULL
+	`
+
+	user, err := scanUser(r.db.QueryRow(ctx, query, email))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.User{}, domain.ErrNotFound
+		}
+		return entity.User{}, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return toEntity(user), nil
+}
+
+// SetEmail меняет почту пользователя и сбрасывает ее подтверждение
+func (r *Repository) SetEmail(ctx context.Context, userUUID uuid.UUID, email string) error {
+	const query = `update users set email = $1, email_verified_at = NULL where uuid = $2`
+
+	result, err := r.db.Exec(ctx, query, nullString(email), userUUID)
+	if err != nil {
+		return fmt.Errorf("failed to set user email: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+// VerifyEmail подтверждает почту, только если у пользователя все еще тот же адрес.
+// Подтвердивший владелец вытесняет всех, кто указал этот адрес без подтверждения.
+// Если адрес уже подтвердил другой пользователь - domain.ErrEmailAlreadyUsed
+func (r *Repository) VerifyEmail(ctx context.Context, userUUID uuid.UUID, email string, verifiedAt time.Time) error {
 	tx, err := r.db.Begin(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to start transaction: %w", err)
 	}
 
-	var curBalance int
-	err = tx.QueryRow(ctx, `select tokens from users where uuid = $1`, userUUID).Scan(&curBalance)
+	const query = `update users set email_verified_at = $1 where uuid = $2 and lower(email) = lower($3)`
+
+	result, err := tx.Exec(ctx, query, verifiedAt, userUUID, email)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == codeUniqueViolation {
+		_ = tx.Rollback(ctx)
+		return domain.ErrEmailAlreadyUsed
+	}
 	if err != nil {
 		_ = tx.Rollback(ctx)
-		return fmt.Errorf("failed to get user balance: %w", err)
+		return fmt.Errorf("failed to verify user email: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		_ = tx.Rollback(ctx)
+		return domain.ErrNotFound
+	}
+
+	const release = `
+		update users set email = NULL
+		where lower(email) = lower($1) and uuid <> $2 and email_verified_at IS NULL`
+	if _, err = tx.Exec(ctx, release, email, userUUID); err != nil {
+		_ = tx.Rollback(ctx)
+		return fmt.Errorf("failed to release unverified email claims: %w", err)
 	}
 
-	newBalance := curBalance + amount
+	return tx.Commit(ctx)
+}
+
+func (r *Repository) UpdatePassword(ctx context.Context, userUUID uuid.UUID, passHash string) error {
+	const query = `update users set password = $1 where uuid = $2`
+
+	result, err := r.db.Exec(ctx, query, passHash, userUUID)
+	if err != nil {
+		return fmt.Errorf("failed to update user password: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+// SetTOTPSecret начинает настройку 2FA: секрет сохранен, но пока не включен
+func (r *Repository) SetTOTPSecret(ctx context.Context, userUUID uuid.UUID, secret string) error {
+	const query = `update users set totp_secret = $1, totp_enabled_at = NULL, totp_last_step = 0 where uuid = $2`
+
+	result, err := r.db.Exec(ctx, query, secret, userUUID)
+	if err != nil {
+		return fmt.Errorf("failed to set totp secret: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *Repository) EnableTOTP(ctx context.Context, userUUID uuid.UUID, enabledAt time.Time) error {
+	const query = `update users set totp_enabled_at = $1 where uuid = $2 and totp_secret is not null`
+
+	result, err := r.db.Exec(ctx, query, enabledAt, userUUID)
+	if err != nil {
+		return fmt.Errorf("failed to enable totp: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *Repository) DisableTOTP(ctx context.Context, userUUID uuid.UUID) error {
+	const query = `update users set totp_secret = NULL, totp_enabled_at = NULL, totp_last_step = 0 where uuid = $1`
+
+	_, err := r.db.Exec(ctx, query, userUUID)
+	if err != nil {
+		return fmt.Errorf("failed to disable totp: %w", err)
+	}
+
+	return nil
+}
+
+// UseTOTPStep запоминает принятый интервал. false - код этого или более позднего интервала уже принимали
+func (r *Repository) UseTOTPStep(ctx context.Context, userUUID uuid.UUID, step int64) (bool, error) {
+	const query = `update users set totp_last_step = $1 where uuid = $2 and totp_last_step < $1`
 
-	_, err = tx.Exec(ctx, `update users set tokens = $1 where uuid = $2`, newBalance, userUUID)
+	result, err := r.db.Exec(ctx, query, step, userUUID)
 	if err != nil {
+		return false, fmt.Errorf("failed to update totp step: %w", err)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+// UpdateProfile меняет только заданные поля профиля
+func (r *Repository) UpdateProfile(ctx context.Context, userUUID uuid.UUID, update entity.ProfileUpdate) error {
+	const query = `
+		update users set
+			display_name = CASE WHEN $1 THEN $2 ELSE display_name END,
+			bio = CASE WHEN $3 THEN $4 ELSE bio END,
+			links = CASE WHEN $5 THEN $6::text[] ELSE links END
+		where uuid = $7
+	`
+
+	var displayName, bio sql.Null[string]
+	if update.DisplayName != nil {
+		displayName = nullString(*update.DisplayName)
+	}
+	if update.Bio != nil {
+		bio = nullString(*update.Bio)
+	}
+	links := []string{}
+	if update.Links != nil && *update.Links != nil {
+		links = *update.Links
+	}
+
+	result, err := r.db.Exec(ctx, query,
+		update.DisplayName != nil, displayName,
+		update.Bio != nil, bio,
+		update.Links != nil, links,
+		userUUID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update profile: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+// GetAvatarKeys возвращает ключи аватаров всех пользователей
+func (r *Repository) GetAvatarKeys(ctx context.Context) ([]string, error) {
+	rows, err := r.db.Query(ctx, `select avatar_key from users where avatar_key <> ''`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get avatar keys: %w", err)
+	}
+
+	keys, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan avatar keys: %w", err)
+	}
+
+	return keys, nil
+}
+
+// SetAvatar ставит новый аватар и возвращает ключ предыдущего, чтобы его удалить
+func (r *Repository) SetAvatar(ctx context.Context, userUUID uuid.UUID, key string) (string, error) {
+	const query = `
+		update users u set avatar_key = $1
+		from (select uuid, avatar_key from users where uuid = $2 for update) old
+		where u.uuid = old.uuid
+		returning coalesce(old.avatar_key, '')
+	`
+
+	var oldKey string
+	err := r.db.QueryRow(ctx, query, nullString(key), userUUID).Scan(&oldKey)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", domain.ErrNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to set avatar: %w", err)
+	}
+
+	return oldKey, nil
+}
+
+// SetCommission задает автору комиссию площадки в процентах, nil - комиссия по умолчанию
+func (r *Repository) SetCommission(ctx context.Context, userUUID uuid.UUID, percent *int) error {
+	result, err := r.db.Exec(ctx, `update users set commission_percent = $1 where uuid = $2`, percent, userUUID)
+	if err != nil {
+		return fmt.Errorf("failed to set commission: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+// ChangeLogin меняет логин пользователя. Занятый логин - domain.ErrUserAlreadyExists
+func (r *Repository) ChangeLogin(ctx context.Context, userUUID uuid.UUID, oldLogin, newLogin string) error {
+	result, err := r.db.Exec(ctx, `update users set login = $1 where uuid = $2 and login = $3`, newLogin, userUUID, oldLogin)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == codeUniqueViolation {
+		return domain.ErrUserAlreadyExists
+	}
+	if err != nil {
+		return fmt.Errorf("failed to change login: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+// Anonymize удаляет аккаунт по GDPR: стирает персональные данные, способы входа и сессии,
+// семплы и паки автора уходят в корзину. Строка пользователя остается, чтобы не терять покупки,
+// платежи и авторство купленного контента
+func (r *Repository) Anonymize(ctx context.Context, userUUID uuid.UUID, login string, deletedAt time.Time) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+
+	var found bool
+	err = tx.QueryRow(ctx, `select true from users where uuid = $1 and deleted_at is null for update`, userUUID).Scan(&found)
+	if errors.Is(err, pgx.ErrNoRows) {
 		_ = tx.Rollback(ctx)
-		return fmt.Errorf("failed to update user balance: %w", err)
+		return domain.ErrNotFound
+	}
+	if err != nil {
+		_ = tx.Rollback(ctx)
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	const query = `
+		update users set
+			login = $1, password = '', email = NULL, email_verified_at = NULL,
+			totp_secret = NULL, totp_enabled_at = NULL, totp_last_step = 0,
+			display_name = NULL, bio = NULL, links = '{}', avatar_key = NULL,
+			deleted_at = $2
+		where uuid = $3
+	`
+	if _, err = tx.Exec(ctx, query, login, deletedAt, userUUID); err != nil {
+		_ = tx.Rollback(ctx)
+		return fmt.Errorf("failed to anonymize user: %w", err)
+	}
+
+	for _, table := range []string{"recovery_codes", "user_identities", "user_tokens", "sessions"} {
+		if _, err = tx.Exec(ctx, `delete from `+table+` where user_uuid = $1`, userUUID); err != nil {
+			_ = tx.Rollback(ctx)
+			return fmt.Errorf("failed to delete %s: %w", table, err)
+		}
+	}
+
+	// из корзины покупатели по-прежнему скачивают купленное, а продажи и начисления автору прекращаются
+	for _, table := range []string{"samples", "packs"} {
+		_, err = tx.Exec(ctx, `update `+table+` set deleted_at = $1 where author_uuid = $2 and deleted_at is null`, deletedAt, userUUID)
+		if err != nil {
+			_ = tx.Rollback(ctx)
+			return fmt.Errorf("failed to delete %s: %w", table, err)
+		}
 	}
 
 	return tx.Commit(ctx)
 }
 
+func scanUser(row pgx.Row) (User, error) {
+	var user User
+	err := row.Scan(
+		&user.UUID,
+		&user.Login,
+		&user.PassHash,
+		&user.Tokens,
+		&user.Role,
+		&user.Email,
+		&user.EmailVerifiedAt,
+		&user.TOTPSecret,
+		&user.TOTPEnabledAt,
+		&user.TOTPLastStep,
+		&user.DisplayName,
+		&user.Bio,
+		&user.Links,
+		&user.AvatarKey,
+		&user.DeletedAt,
+		&user.Commission,
+	)
+
+	return user, err
+}
+
 func toEntity(user User) entity.User {
-	return entity.User{
+	u := entity.User{
 		UUID:     user.UUID,
 		Login:    user.Login,
 		PassHash: user.PassHash,
 		Tokens:   user.Tokens,
+		Role:     entity.Role(user.Role),
+		Email:    user.Email.V,
+
+		TOTPSecret:   user.TOTPSecret.V,
+		TOTPLastStep: user.TOTPLastStep,
+
+		DisplayName: user.DisplayName.V,
+		Bio:         user.Bio.V,
+		Links:       user.Links,
+		AvatarKey:   user.AvatarKey.V,
+	}
+	if user.EmailVerifiedAt.Valid {
+		u.EmailVerifiedAt = &user.EmailVerifiedAt.V
+	}
+	if user.TOTPEnabledAt.Valid {
+		u.TOTPEnabledAt = &user.TOTPEnabledAt.V
 	}
+	if user.DeletedAt.Valid {
+		u.DeletedAt = &user.DeletedAt.V
+	}
+	if user.Commission.Valid {
+		u.CommissionPercent = &user.Commission.V
+	}
+
+	return u
+}
+
+func nullString(s string) sql.Null[string] {
+	return sql.Null[string]{V: s, Valid: s != ""}
 }
@@ -0,0 +1,100 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/musicman-backend/internal/domain"
+	"github.com/musicman-backend/internal/domain/entity"
+)
+
+// Tokens - одноразовые токены из писем
+type Tokens struct {
+	db *pgxpool.Pool
+}
+
+func NewTokens(db *pgxpool.Pool) *Tokens {
+	return &Tokens{db: db}
+}
+
+func (r *Tokens) Create(ctx context.Context, token entity.UserToken) error {
+	const query = `
+		INSERT INTO user_tokens (user_uuid, purpose, token_hash, email, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	_, err := r.db.Exec(ctx, query,
+		token.UserUUID,
+		token.Purpose,
+		token.Hash,
+		token.Email,
+		token.ExpiresAt,
+		token.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create user token: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Tokens) GetByHash(ctx context.Context, hash string) (entity.UserToken, error) {
+	const query = `
+		SELECT id, user_uuid, purpose, token_hash, email, expires_at, used_at, created_at
+		FROM user_tokens
+		WHERE token_hash = $1
+	`
+
+	var token entity.UserToken
+	err := r.db.QueryRow(ctx, query, hash).Scan(
+		&token.ID,
+		&token.UserUUID,
+		&token.Purpose,
+		&token.Hash,
+		&token.Email,
+		&token.ExpiresAt,
+		&token.UsedAt,
+		&token.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.UserToken{}, domain.ErrNotFound
+		}
+		return entity.UserToken{}, fmt.Errorf("failed to get user token: %w", err)
+	}
+
+	return token, nil
+}
+
+// MarkUsed гасит токен. Если его уже использовали, вернет domain.ErrInvalidToken
+func (r *Tokens) MarkUsed(ctx context.Context, id uuid.UUID, usedAt time.Time) error {
+	const query = `UPDATE user_tokens SET used_at = $1 WHERE id = $2 AND used_at IS NULL`
+
+	result, err := r.db.Exec(ctx, query, usedAt, id)
+	if err != nil {
+		return fmt.Errorf("failed to mark user token used: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrInvalidToken
+	}
+
+	return nil
+}
+
+// InvalidateByUser гасит все неиспользованные токены пользователя с таким назначением
+func (r *Tokens) InvalidateByUser(ctx context.Context, userUUID uuid.UUID, purpose entity.TokenPurpose, usedAt time.Time) error {
+	const query = `UPDATE user_tokens SET used_at = $1 WHERE user_uuid = $2 AND purpose = $3 AND used_at IS NULL`
+
+	_, err := r.db.Exec(ctx, query, usedAt, userUUID, purpose)
+	if err != nil {
+		return fmt.Errorf("failed to invalidate user tokens: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,224 @@
+// Package storagetest - общий набор проверок, который должна проходить каждая реализация файлового хранилища.
+// Запускается против любого бэкенда, в том числе настоящего MinIO:
+//
+//	if err := storagetest.TestStorage(ctx, memory.NewMemory()); err != nil {
+//		t.Fatal(err)
+//	}
+package storagetest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/google/uuid"
+	"github.com/musicman-backend/internal/domain"
+	"github.com/musicman-backend/internal/domain/entity"
+)
+
+type Storage interface {
+	UploadFile(ctx context.Context, bucketName, objectName, filePath string) error
+	DownloadFile(ctx context.Context, bucketName, objectName, filePath string) error
+	OpenFile(ctx context.Context, bucketName, objectName string) (io.ReadCloser, error)
+	GetFileURL(ctx context.Context, bucketName, objectName string) (string, error)
+	DeleteFile(ctx context.Context, bucketName, objectName string) error
+	ListFiles(ctx context.Context, bucketName string) ([]entity.FileInfo, error)
+	CreateBucketIfNotExists(ctx context.Context, bucketName string) error
+}
+
+// TestStorage прогоняет проверки в отдельном бакете и возвращает все найденные расхождения разом.
+// Объекты, созданные проверками, удаляются, сам бакет остается
+func TestStorage(ctx context.Context, storage Storage) error {
+	dir, err := os.MkdirTemp("", "storagetest-")
+	if err != nil {
+		return fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	t := &tester{
+		ctx:     ctx,
+		storage: storage,
+		dir:     dir,
+		bucket:  "storagetest-" + uuid.NewString()[:8],
+	}
+	t.run()
+
+	return errors.Join(t.errs...)
+}
+
+type tester struct {
+	ctx     context.Context
+	storage Storage
+	dir     string
+	bucket  string
+
+	errs []error
+}
+
+func (t *tester) errorf(format string, args ...any) {
+	t.errs = append(t.errs, fmt.Errorf(format, args...))
+}
+
+func (t *tester) run() {
+	files, err := t.storage.ListFiles(t.ctx, t.bucket)
+	if err != nil {
+		t.errorf("ListFiles on missing bucket: %w", err)
+	} else if len(files) != 0 {
+		t.errorf("ListFiles on missing bucket: got %d objects, want 0", len(files))
+	}
+
+	for i := 0; i < 2; i++ {
+		if err = t.storage.CreateBucketIfNotExists(t.ctx, t.bucket); err != nil {
+			t.errorf("CreateBucketIfNotExists call %d: %w", i+1, err)
+			return
+		}
+	}
+
+	files, err = t.storage.ListFiles(t.ctx, t.bucket)
+	if err != nil {
+		t.errorf("ListFiles on empty bucket: %w", err)
+	} else if len(files) != 0 {
+		t.errorf("ListFiles on empty bucket: got %d objects, want 0", len(files))
+	}
+
+	const (
+		nestedKey = "samples/00000000-0000-0000-0000-000000000000/1/hash.wav"
+		flatKey   = "flat.wav"
+	)
+	first := []byte("first version of the object")
+	second := []byte("second, longer version of the same object")
+
+	defer func() {
+		for _, key := range []string{nestedKey, flatKey} {
+			_ = t.storage.DeleteFile(t.ctx, t.bucket, key)
+		}
+	}()
+
+	t.upload(nestedKey, first)
+	t.upload(flatKey, second)
+	t.expectContent(nestedKey, first)
+	t.expectContent(flatKey, second)
+	t.expectListed(map[string]int64{nestedKey: int64(len(first)), flatKey: int64(len(second))})
+
+	// повторная загрузка по тому же ключу перезаписывает объект
+	t.upload(nestedKey, second)
+	t.expectContent(nestedKey, second)
+	t.expectListed(map[string]int64{nestedKey: int64(len(second)), flatKey: int64(len(second))})
+
+	url, err := t.storage.GetFileURL(t.ctx, t.bucket, nestedKey)
+	if err != nil {
+		t.errorf("GetFileURL: %w", err)
+	} else if url == "" {
+		t.errorf("GetFileURL: got empty url")
+	}
+
+	t.expectNotFound("missing.wav")
+
+	if err = t.storage.DeleteFile(t.ctx, t.bucket, nestedKey); err != nil {
+		t.errorf("DeleteFile: %w", err)
+	}
+	t.expectNotFound(nestedKey)
+	t.expectListed(map[string]int64{flatKey: int64(len(second))})
+
+	// удаление отсутствующего объекта не ошибка
+	if err = t.storage.DeleteFile(t.ctx, t.bucket, nestedKey); err != nil {
+		t.errorf("DeleteFile of missing object: %w", err)
+	}
+}
+
+func (t *tester) upload(key string, content []byte) {
+	src := filepath.Join(t.dir, "upload")
+	if err := os.WriteFile(src, content, 0o600); err != nil {
+		t.errorf("write upload file: %w", err)
+		return
+	}
+
+	if err := t.storage.UploadFile(t.ctx, t.bucket, key, src); err != nil {
+		t.errorf("UploadFile %s: %w", key, err)
+	}
+}
+
+func (t *tester) expectContent(key string, want []byte) {
+	dst := filepath.Join(t.dir, "download")
+	defer os.Remove(dst)
+
+	if err := t.storage.DownloadFile(t.ctx, t.bucket, key, dst); err != nil {
+		t.errorf("DownloadFile %s: %w", key, err)
+		return
+	}
+
+	got, err := os.ReadFile(dst)
+	if err != nil {
+		t.errorf("read downloaded file: %w", err)
+		return
+	}
+	if !bytes.Equal(got, want) {
+		t.errorf("DownloadFile %s: got %q, want %q", key, got, want)
+	}
+
+	reader, err := t.storage.OpenFile(t.ctx, t.bucket, key)
+	if err != nil {
+		t.errorf("OpenFile %s: %w", key, err)
+		return
+	}
+	defer reader.Close()
+
+	got, err = io.ReadAll(reader)
+	if err != nil {
+		t.errorf("OpenFile %s: read: %w", key, err)
+		return
+	}
+	if !bytes.Equal(got, want) {
+		t.errorf("OpenFile %s: got %q, want %q", key, got, want)
+	}
+}
+
+func (t *tester) expectNotFound(key string) {
+	dst := filepath.Join(t.dir, "download")
+	defer os.Remove(dst)
+
+	err := t.storage.DownloadFile(t.ctx, t.bucket, key, dst)
+	if !errors.Is(err, domain.ErrNotFound) {
+		t.errorf("DownloadFile of missing %s: got %v, want domain.ErrNotFound", key, err)
+	}
+
+	reader, err := t.storage.OpenFile(t.ctx, t.bucket, key)
+	if err == nil {
+		_ = reader.Close()
+	}
+	if !errors.Is(err, domain.ErrNotFound) {
+		t.errorf("OpenFile of missing %s: got %v, want domain.ErrNotFound", key, err)
+	}
+}
+
+func (t *tester) expectListed(want map[string]int64) {
+	files, err := t.storage.ListFiles(t.ctx, t.bucket)
+	if err != nil {
+		t.errorf("ListFiles: %w", err)
+		return
+	}
+
+	got := make(map[string]int64, len(files))
+	for _, file := range files {
+		got[file.Key] = file.Size
+		if file.LastModified.IsZero() {
+			t.errorf("ListFiles: %s has zero LastModified", file.Key)
+		}
+	}
+
+	if len(got) != len(want) {
+		t.errorf("ListFiles: got %d objects %v, want %d %v", len(got), got, len(want), want)
+		return
+	}
+	for key, size := range want {
+		if gotSize, ok := got[key]; !ok {
+			t.errorf("ListFiles: %s not listed", key)
+		} else if gotSize != size {
+			t.errorf("ListFiles: %s size %d, want %d", key, gotSize, size)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+type StatsRollup interface {
+	Rollup(ctx context.Context) error
+}
+
+type AnalyticsScheduler struct {
+	interval time.Duration
+
+	rollup StatsRollup
+}
+
+func NewAnalyticsScheduler(interval time.Duration, rollup StatsRollup) *AnalyticsScheduler {
+	return &AnalyticsScheduler{
+		interval: interval,
+		rollup:   rollup,
+	}
+}
+
+func (s *AnalyticsScheduler) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	for {
+		select {
+		case <-ticker.C:
+			s.schedule(context.Background())
+		case <-ctx.Done():
+			ticker.Stop()
+			return
+		}
+	}
+}
+
+func (s *AnalyticsScheduler) schedule(ctx context.Context) {
+	if err := s.rollup.Rollup(ctx); err != nil {
+		slog.Error("failed to roll up sample events", slog.String("err", err.Error()))
+	}
+}
@@ -0,0 +1,66 @@
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/musicman-backend/internal/domain/entity"
+)
+
+type GarbageCollector interface {
+	Run(ctx context.Context, deleteOrphans bool) (entity.GCReport, error)
+}
+
+type GCScheduler struct {
+	interval      time.Duration
+	deleteOrphans bool
+
+	collector GarbageCollector
+}
+
+func NewGCScheduler(interval time.Duration, deleteOrphans bool, collector GarbageCollector) *GCScheduler {
+	return &GCScheduler{
+		interval:      interval,
+		deleteOrphans: deleteOrphans,
+		collector:     collector,
+	}
+}
+
+func (s *GCScheduler) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	for {
+		select {
+		case <-ticker.C:
+			s.schedule(context.Background())
+		case <-ctx.Done():
+			ticker.Stop()
+			return
+		}
+	}
+}
+
+func (s *GCScheduler) schedule(ctx context.Context) {
+	report, err := s.collector.Run(ctx, s.deleteOrphans)
+	if err != nil {
+		slog.Error("failed to collect garbage", slog.String("err", err.Error()))
+		return
+	}
+
+	LogGCReport(report)
+}
+
+// LogGCReport пишет в лог сводку сборки мусора
+func LogGCReport(report entity.GCReport) {
+	slog.Info("garbage collected",
+		slog.Int("objects_total", report.ObjectsTotal),
+		slog.Int("orphaned_objects", len(report.OrphanedObjects)),
+		slog.Int64("orphaned_bytes", report.OrphanedBytes),
+		slog.Int("deleted_objects", report.DeletedObjects),
+		slog.Int("missing_objects", len(report.MissingObjects)),
+		slog.Int("samples_without_audio", len(report.SamplesWithoutAudio)),
+		slog.Int("deleted_temp_files", report.DeletedTempFiles),
+		slog.Int("errors", len(report.Errors)),
+		slog.Duration("took", report.FinishedAt.Sub(report.StartedAt)),
+	)
+}
@@ -54,8 +54,8 @@ func (s *PaymentScheduler) schedule(ctx context.Context) {
 
 	wg := sync.WaitGroup{}
 	for _, payment := range payments {
+		wg.Add(1)
 		go func() {
-			wg.Add(1)
 			defer wg.Done()
 
 			err := s.executor.UpdatePaymentStatus(ctx, payment)
@@ -0,0 +1,48 @@
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+type RateLimitCleaner interface {
+	Cleanup(ctx context.Context) (int64, error)
+}
+
+type RateLimitScheduler struct {
+	interval time.Duration
+
+	cleaner RateLimitCleaner
+}
+
+func NewRateLimitScheduler(interval time.Duration, cleaner RateLimitCleaner) *RateLimitScheduler {
+	return &RateLimitScheduler{
+		interval: interval,
+		cleaner:  cleaner,
+	}
+}
+
+func (s *RateLimitScheduler) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	for {
+		select {
+		case <-ticker.C:
+			s.schedule(context.Background())
+		case <-ctx.Done():
+			ticker.Stop()
+			return
+		}
+	}
+}
+
+func (s *RateLimitScheduler) schedule(ctx context.Context) {
+	deleted, err := s.cleaner.Cleanup(ctx)
+	if err != nil {
+		slog.Error("failed to clean up rate limits", slog.String("err", err.Error()))
+	}
+
+	if deleted > 0 {
+		slog.Debug("idle rate limit keys removed", slog.Int64("count", deleted))
+	}
+}
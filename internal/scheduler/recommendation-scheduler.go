@@ -0,0 +1,43 @@
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+type NeighbourBuilder interface {
+	Rebuild(ctx context.Context) error
+}
+
+type RecommendationScheduler struct {
+	interval time.Duration
+
+	builder NeighbourBuilder
+}
+
+func NewRecommendationScheduler(interval time.Duration, builder NeighbourBuilder) *RecommendationScheduler {
+	return &RecommendationScheduler{
+		interval: interval,
+		builder:  builder,
+	}
+}
+
+func (s *RecommendationScheduler) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	for {
+		select {
+		case <-ticker.C:
+			s.schedule(context.Background())
+		case <-ctx.Done():
+			ticker.Stop()
+			return
+		}
+	}
+}
+
+func (s *RecommendationScheduler) schedule(ctx context.Context) {
+	if err := s.builder.Rebuild(ctx); err != nil {
+		slog.Error("failed to rebuild sample neighbours", slog.String("err", err.Error()))
+	}
+}
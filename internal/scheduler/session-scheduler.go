@@ -0,0 +1,48 @@
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+type SessionCleaner interface {
+	Cleanup(ctx context.Context) (int64, error)
+}
+
+type SessionScheduler struct {
+	interval time.Duration
+
+	cleaner SessionCleaner
+}
+
+func NewSessionScheduler(interval time.Duration, cleaner SessionCleaner) *SessionScheduler {
+	return &SessionScheduler{
+		interval: interval,
+		cleaner:  cleaner,
+	}
+}
+
+func (s *SessionScheduler) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	for {
+		select {
+		case <-ticker.C:
+			s.schedule(context.Background())
+		case <-ctx.Done():
+			ticker.Stop()
+			return
+		}
+	}
+}
+
+func (s *SessionScheduler) schedule(ctx context.Context) {
+	deleted, err := s.cleaner.Cleanup(ctx)
+	if err != nil {
+		slog.Error("failed to clean up sessions", slog.String("err", err.Error()))
+	}
+
+	if deleted > 0 {
+		slog.Debug("stale sessions removed", slog.Int64("count", deleted))
+	}
+}
@@ -0,0 +1,48 @@
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+type TrashPurger interface {
+	PurgeTrash(ctx context.Context) (int, error)
+}
+
+type TrashScheduler struct {
+	interval time.Duration
+
+	purger TrashPurger
+}
+
+func NewTrashScheduler(interval time.Duration, purger TrashPurger) *TrashScheduler {
+	return &TrashScheduler{
+		interval: interval,
+		purger:   purger,
+	}
+}
+
+func (s *TrashScheduler) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	for {
+		select {
+		case <-ticker.C:
+			s.schedule(context.Background())
+		case <-ctx.Done():
+			ticker.Stop()
+			return
+		}
+	}
+}
+
+func (s *TrashScheduler) schedule(ctx context.Context) {
+	purged, err := s.purger.PurgeTrash(ctx)
+	if err != nil {
+		slog.Error("failed to purge trash", slog.String("err", err.Error()))
+	}
+
+	if purged > 0 {
+		slog.Info("trash purged", slog.Int("count", purged))
+	}
+}
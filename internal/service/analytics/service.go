@@ -0,0 +1,179 @@
+package analytics
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/musicman-backend/internal/domain"
+	"github.com/musicman-backend/internal/domain/constant"
+	"github.com/musicman-backend/internal/domain/entity"
+)
+
+const (
+	defaultPeriodDays = 30
+	maxPeriodDays     = 366
+	topItemsLimit     = 20
+
+	// события хранятся хотя бы двое суток: свертка пересчитывает вчерашний и сегодняшний день
+	minEventRetention     = 48 * time.Hour
+	defaultEventRetention = 90 * 24 * time.Hour
+)
+
+type Repository interface {
+	Rollup(ctx context.Context, since time.Time) (int64, error)
+	DeleteEventsBefore(ctx context.Context, before time.Time) (int64, error)
+	Series(ctx context.Context, filter entity.StatsFilter) ([]entity.StatsPoint, error)
+	BySample(ctx context.Context, filter entity.StatsFilter, limit int) ([]entity.ItemStats, error)
+	ByPack(ctx context.Context, filter entity.StatsFilter, limit int) ([]entity.ItemStats, error)
+}
+
+type Recorder interface {
+	Record(event entity.SampleEvent)
+}
+
+type Service struct {
+	repo      Repository
+	recorder  Recorder
+	retention time.Duration
+}
+
+func New(repo Repository, recorder Recorder, retention time.Duration) *Service {
+	if retention <= 0 {
+		retention = defaultEventRetention
+	}
+	if retention < minEventRetention {
+		retention = minEventRetention
+	}
+
+	return &Service{
+		repo:      repo,
+		recorder:  recorder,
+		retention: retention,
+	}
+}
+
+// RecordPlay - пользователь открыл семпл с прослушиванием
+func (s *Service) RecordPlay(sampleID, userUUID uuid.UUID) {
+	s.record(sampleID, userUUID, constant.EventPlay, 0)
+}
+
+func (s *Service) RecordDownload(sampleID, userUUID uuid.UUID) {
+	s.record(sampleID, userUUID, constant.EventDownload, 0)
+}
+
+// RecordPurchase - покупка семпла, amount - цена в токенах
+func (s *Service) RecordPurchase(sampleID, userUUID uuid.UUID, amount int) {
+	s.record(sampleID, userUUID, constant.EventPurchase, amount)
+}
+
+func (s *Service) record(sampleID, userUUID uuid.UUID, eventType string, amount int) {
+	var user *uuid.UUID
+	if userUUID != uuid.Nil {
+		user = &userUUID
+	}
+
+	s.recorder.Record(entity.SampleEvent{
+		SampleID:  sampleID,
+		UserUUID:  user,
+		Type:      eventType,
+		Amount:    amount,
+		CreatedAt: time.Now(),
+	})
+}
+
+// Rollup сворачивает события со вчерашнего дня в дневную статистику и удаляет устаревшие события
+func (s *Service) Rollup(ctx context.Context) error {
+	now := time.Now()
+
+	rows, err := s.repo.Rollup(ctx, startOfDay(now.AddDate(0, 0, -1)))
+	if err != nil {
+		return err
+	}
+
+	deleted, err := s.repo.DeleteEventsBefore(ctx, now.Add(-s.retention))
+	if err != nil {
+		return err
+	}
+
+	slog.Info("sample events rolled up", slog.Int64("rows", rows), slog.Int64("deleted_events", deleted))
+
+	return nil
+}
+
+// AuthorStats - статистика семплов и паков автора
+func (s *Service) AuthorStats(ctx context.Context, userUUID uuid.UUID, filter entity.StatsFilter) (entity.Stats, error) {
+	filter.AuthorUUID = &userUUID
+
+	return s.Stats(ctx, filter)
+}
+
+// Stats - статистика по всей площадке или ее срезу. Пустой период - последние 30 дней
+func (s *Service) Stats(ctx context.Context, filter entity.StatsFilter) (entity.Stats, error) {
+	filter, err := normalizePeriod(filter, time.Now())
+	if err != nil {
+		return entity.Stats{}, err
+	}
+
+	points, err := s.repo.Series(ctx, filter)
+	if err != nil {
+		return entity.Stats{}, err
+	}
+
+	samples, err := s.repo.BySample(ctx, filter, topItemsLimit)
+	if err != nil {
+		return entity.Stats{}, err
+	}
+
+	packs, err := s.repo.ByPack(ctx, filter, topItemsLimit)
+	if err != nil {
+		return entity.Stats{}, err
+	}
+
+	byDay := make(map[string]entity.StatsPoint, len(points))
+	for _, p := range points {
+		byDay[p.Day.Format(time.DateOnly)] = p
+	}
+
+	stats := entity.Stats{Samples: samples, Packs: packs}
+	for day := filter.From; !day.After(filter.To); day = day.AddDate(0, 0, 1) {
+		point := byDay[day.Format(time.DateOnly)]
+		point.Day = day
+
+		stats.Series = append(stats.Series, point)
+		stats.Totals.Plays += point.Plays
+		stats.Totals.Downloads += point.Downloads
+		stats.Totals.Purchases += point.Purchases
+		stats.Totals.Revenue += point.Revenue
+	}
+
+	return stats, nil
+}
+
+func normalizePeriod(filter entity.StatsFilter, now time.Time) (entity.StatsFilter, error) {
+	if filter.To.IsZero() {
+		filter.To = now
+	}
+	if filter.From.IsZero() {
+		filter.From = filter.To.AddDate(0, 0, -(defaultPeriodDays - 1))
+	}
+
+	filter.From = startOfDay(filter.From)
+	filter.To = startOfDay(filter.To)
+
+	if filter.From.After(filter.To) {
+		return filter, fmt.Errorf("%w: from is after to", domain.ErrInvalidStatsRange)
+	}
+	if filter.To.Sub(filter.From) >= maxPeriodDays*24*time.Hour {
+		return filter, fmt.Errorf("%w: period is longer than %d days", domain.ErrInvalidStatsRange, maxPeriodDays)
+	}
+
+	return filter, nil
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
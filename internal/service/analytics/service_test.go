@@ -0,0 +1,86 @@
+package analytics
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/musicman-backend/internal/domain"
+	"github.com/musicman-backend/internal/domain/entity"
+)
+
+// fakeStats - дневные точки из свертки, дни без событий в ней отсутствуют
+type fakeStats struct {
+	Repository
+	points []entity.StatsPoint
+}
+
+func (f fakeStats) Series(context.Context, entity.StatsFilter) ([]entity.StatsPoint, error) {
+	return f.points, nil
+}
+
+func (f fakeStats) BySample(context.Context, entity.StatsFilter, int) ([]entity.ItemStats, error) {
+	return nil, nil
+}
+
+func (f fakeStats) ByPack(context.Context, entity.StatsFilter, int) ([]entity.ItemStats, error) {
+	return nil, nil
+}
+
+func day(d int) time.Time {
+	return time.Date(2026, 3, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestStatsFillsEmptyDays(t *testing.T) {
+	s := New(fakeStats{points: []entity.StatsPoint{
+		{Day: day(2), Plays: 10, Purchases: 1, Revenue: 100},
+		{Day: day(4), Plays: 5, Downloads: 2},
+	}}, nil, 0)
+
+	stats, err := s.Stats(context.Background(), entity.StatsFilter{From: day(1), To: day(5).Add(15 * time.Hour)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(stats.Series) != 5 {
+		t.Fatalf("%d points, want one per day from the 1st to the 5th", len(stats.Series))
+	}
+	for i, point := range stats.Series {
+		if !point.Day.Equal(day(i + 1)) {
+			t.Fatalf("point %d is for %s, want %s", i, point.Day, day(i+1))
+		}
+	}
+	if stats.Series[0].Plays != 0 || stats.Series[1].Plays != 10 || stats.Series[3].Downloads != 2 {
+		t.Fatalf("series %+v: days without events must be zero", stats.Series)
+	}
+	if totals := stats.Totals; totals.Plays != 15 || totals.Downloads != 2 || totals.Purchases != 1 || totals.Revenue != 100 {
+		t.Fatalf("totals %+v", totals)
+	}
+}
+
+func TestStatsPeriod(t *testing.T) {
+	s := New(fakeStats{}, nil, 0)
+	ctx := context.Background()
+
+	stats, err := s.Stats(ctx, entity.StatsFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stats.Series) != defaultPeriodDays {
+		t.Fatalf("%d days by default, want %d", len(stats.Series), defaultPeriodDays)
+	}
+
+	invalid := map[string]entity.StatsFilter{
+		"from after to": {From: day(5), To: day(1)},
+		"too long":      {From: day(1), To: day(1).AddDate(0, 0, maxPeriodDays)},
+	}
+	for name, filter := range invalid {
+		if _, err = s.Stats(ctx, filter); !errors.Is(err, domain.ErrInvalidStatsRange) {
+			t.Fatalf("%s: got %v, want ErrInvalidStatsRange", name, err)
+		}
+	}
+	if _, err = s.Stats(ctx, entity.StatsFilter{From: day(1), To: day(1).AddDate(0, 0, maxPeriodDays-1)}); err != nil {
+		t.Fatalf("longest allowed period: %v", err)
+	}
+}
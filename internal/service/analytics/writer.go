@@ -0,0 +1,144 @@
+package analytics
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/musicman-backend/internal/domain/entity"
+)
+
+const (
+	defaultBufferSize    = 10000
+	defaultBatchSize     = 500
+	defaultFlushInterval = 5 * time.Second
+
+	// flushTimeout ограничивает запись одной пачки, чтобы остановка приложения не зависла на базе
+	flushTimeout = 10 * time.Second
+)
+
+type EventRepository interface {
+	InsertEvents(ctx context.Context, events []entity.SampleEvent) error
+}
+
+// Writer копит события в памяти и пишет их в базу пачками в фоне, чтобы запись статистики
+// не замедляла запросы. События не переживают падение процесса и теряются при переполнении буфера
+type Writer struct {
+	repo          EventRepository
+	events        chan entity.SampleEvent
+	batchSize     int
+	flushInterval time.Duration
+
+	dropped atomic.Int64
+	started atomic.Bool
+	stopped sync.Once
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+func NewWriter(repo EventRepository, bufferSize, batchSize int, flushInterval time.Duration) *Writer {
+	if bufferSize <= 0 {
+		bufferSize = defaultBufferSize
+	}
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+	if flushInterval <= 0 {
+		flushInterval = defaultFlushInterval
+	}
+
+	return &Writer{
+		repo:          repo,
+		events:        make(chan entity.SampleEvent, bufferSize),
+		batchSize:     batchSize,
+		flushInterval: flushInterval,
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+}
+
+// Record ставит событие в очередь и никогда не блокирует запрос
+func (w *Writer) Record(event entity.SampleEvent) {
+	select {
+	case w.events <- event:
+	default:
+		w.dropped.Add(1)
+	}
+}
+
+// Start пишет события, пока не вызван Stop или не отменен ctx
+func (w *Writer) Start(ctx context.Context) {
+	w.started.Store(true)
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]entity.SampleEvent, 0, w.batchSize)
+	for {
+		select {
+		case event := <-w.events:
+			batch = append(batch, event)
+			if len(batch) >= w.batchSize {
+				batch = w.flush(batch)
+			}
+		case <-ticker.C:
+			batch = w.flush(batch)
+		case <-w.stop:
+			w.drain(batch)
+			return
+		case <-ctx.Done():
+			w.drain(batch)
+			return
+		}
+	}
+}
+
+// Stop дописывает накопленные события и дожидается завершения Start
+func (w *Writer) Stop() {
+	w.stopped.Do(func() {
+		close(w.stop)
+	})
+
+	if w.started.Load() {
+		<-w.done
+	}
+}
+
+// drain забирает из очереди все, что успело прийти, и пишет последними пачками
+func (w *Writer) drain(batch []entity.SampleEvent) {
+	for {
+		select {
+		case event := <-w.events:
+			batch = append(batch, event)
+			if len(batch) >= w.batchSize {
+				batch = w.flush(batch)
+			}
+		default:
+			w.flush(batch)
+			return
+		}
+	}
+}
+
+func (w *Writer) flush(batch []entity.SampleEvent) []entity.SampleEvent {
+	if dropped := w.dropped.Swap(0); dropped > 0 {
+		slog.Warn("sample events dropped, buffer is full", slog.Int64("count", dropped))
+	}
+
+	if len(batch) == 0 {
+		return batch
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), flushTimeout)
+	defer cancel()
+
+	// при ошибке пачка теряется: статистика не стоит того, чтобы копить события без ограничений
+	if err := w.repo.InsertEvents(ctx, batch); err != nil {
+		slog.Error("failed to write sample events", slog.Int("count", len(batch)), slog.String("err", err.Error()))
+	}
+
+	return batch[:0]
+}
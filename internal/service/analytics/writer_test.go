@@ -0,0 +1,105 @@
+package analytics
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/musicman-backend/internal/domain/constant"
+	"github.com/musicman-backend/internal/domain/entity"
+)
+
+// fakeEvents запоминает размеры записанных пачек. Writer переиспользует слайс пачки,
+// поэтому события копируются
+type fakeEvents struct {
+	mu      sync.Mutex
+	batches []int
+	events  []entity.SampleEvent
+}
+
+func (f *fakeEvents) InsertEvents(_ context.Context, events []entity.SampleEvent) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.batches = append(f.batches, len(events))
+	f.events = append(f.events, slices.Clone(events)...)
+	return nil
+}
+
+func (f *fakeEvents) written() ([]int, int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.Clone(f.batches), len(f.events)
+}
+
+func event() entity.SampleEvent {
+	return entity.SampleEvent{SampleID: uuid.New(), Type: constant.EventPlay, CreatedAt: time.Now()}
+}
+
+func TestWriterBatchesAndDrainsOnStop(t *testing.T) {
+	repo := &fakeEvents{}
+	w := NewWriter(repo, 100, 3, time.Hour)
+	for i := 0; i < 7; i++ {
+		w.Record(event())
+	}
+
+	// отмененный контекст останавливает Start сразу, как остановка приложения
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	w.Start(ctx)
+
+	batches, total := repo.written()
+	if total != 7 || !slices.Equal(batches, []int{3, 3, 1}) {
+		t.Fatalf("batches %v with %d events, want 3+3+1: full batches and the rest on stop", batches, total)
+	}
+}
+
+func TestWriterFlushesOnInterval(t *testing.T) {
+	repo := &fakeEvents{}
+	w := NewWriter(repo, 100, 100, 10*time.Millisecond)
+	go w.Start(context.Background())
+	defer w.Stop()
+
+	w.Record(event())
+	deadline := time.Now().Add(time.Second)
+	for {
+		if _, total := repo.written(); total == 1 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("incomplete batch was not flushed by the ticker")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestWriterDropsWhenFull(t *testing.T) {
+	repo := &fakeEvents{}
+	w := NewWriter(repo, 2, 10, time.Hour)
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 5; i++ {
+			w.Record(event())
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Record blocked on a full buffer")
+	}
+	if dropped := w.dropped.Load(); dropped != 3 {
+		t.Fatalf("%d events dropped, want 3", dropped)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	w.Start(ctx)
+	if _, total := repo.written(); total != 2 {
+		t.Fatalf("%d events written, want the 2 buffered ones", total)
+	}
+}
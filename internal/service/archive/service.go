@@ -0,0 +1,335 @@
+package archive
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"path"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/musicman-backend/internal/domain"
+	"github.com/musicman-backend/internal/domain/constant"
+	"github.com/musicman-backend/internal/domain/entity"
+)
+
+const (
+	readmeName  = "README.txt"
+	licenseName = "LICENSE.txt"
+
+	defaultExt = ".wav"
+	// maxTitleLength - длина названия в имени файла, длинные имена плохо переносят архиваторы
+	maxTitleLength = 80
+)
+
+// licenseOrder - порядок лицензий в LICENSE.txt, пустая - бесплатные семплы
+var licenseOrder = []string{constant.LicensePersonal, constant.LicenseCommercial, constant.LicenseExclusive, ""}
+
+type PackRepository interface {
+	GetByID(ctx context.Context, id uuid.UUID) (entity.Pack, error)
+}
+
+type SampleRepository interface {
+	GetPackTracks(ctx context.Context, packID uuid.UUID) ([]entity.Sample, error)
+}
+
+// Purchases находит покупку семпла и ключ купленной версии аудио
+type Purchases interface {
+	Purchased(ctx context.Context, userUUID uuid.UUID, sample entity.Sample) (entity.Purchase, string, bool, error)
+}
+
+type FileRepository interface {
+	OpenFile(ctx context.Context, bucketName, objectName string) (io.ReadCloser, error)
+}
+
+// EventRecorder учитывает скачивания в статистике
+type EventRecorder interface {
+	RecordDownload(sampleID, userUUID uuid.UUID)
+}
+
+// Service собирает zip-архив пака на лету из хранилища: файлы копируются в ответ потоком,
+// поэтому размер пака не ограничен памятью и временными файлами
+type Service struct {
+	packs     PackRepository
+	samples   SampleRepository
+	purchases Purchases
+	files     FileRepository
+	events    EventRecorder
+
+	// bucket - бакет с аудио семплов
+	bucket string
+}
+
+func New(packs PackRepository, samples SampleRepository, purchases Purchases, files FileRepository, events EventRecorder, bucket string) *Service {
+	return &Service{
+		packs:     packs,
+		samples:   samples,
+		purchases: purchases,
+		files:     files,
+		events:    events,
+		bucket:    bucket,
+	}
+}
+
+// Pack определяет, что войдет в архив пака для пользователя: его собственные семплы, купленные
+// в купленной версии и бесплатные. Если доступных семплов нет - domain.ErrForbidden
+func (s *Service) Pack(ctx context.Context, userUUID, packID uuid.UUID) (entity.PackArchive, error) {
+	var archive entity.PackArchive
+
+	pack, err := s.packs.GetByID(ctx, packID)
+	if errors.Is(err, domain.ErrNotFound) || pack.DeletedAt != nil {
+		return archive, domain.ErrNotFound
+	}
+	if err != nil {
+		return archive, fmt.Errorf("failed to get pack: %w", err)
+	}
+	archive.Pack = pack
+
+	tracks, err := s.samples.GetPackTracks(ctx, packID)
+	if err != nil {
+		return archive, fmt.Errorf("failed to get pack samples: %w", err)
+	}
+
+	now := time.Now()
+	for i, sample := range tracks {
+		if sample.MinioKey == "" {
+			// аудио еще не загружено, скачивать нечего
+			continue
+		}
+
+		file := entity.ArchiveFile{Sample: sample, Track: i + 1, Key: sample.MinioKey}
+		if sample.AuthorUUID == userUUID {
+			// автор получает свои семплы в текущей версии в любом статусе
+			file.Own = true
+			archive.Files = append(archive.Files, file)
+			continue
+		}
+		if sample.Price == 0 && sample.Public(now) {
+			archive.Files = append(archive.Files, file)
+			continue
+		}
+
+		purchase, key, isPurchased, err := s.purchases.Purchased(ctx, userUUID, sample)
+		if err != nil {
+			return archive, fmt.Errorf("failed to check purchase of sample %s: %w", sample.ID, err)
+		}
+		if !isPurchased {
+			if sample.Public(now) {
+				archive.Skipped++
+			} else {
+				archive.Unavailable++
+			}
+			continue
+		}
+
+		file.Key = key
+		file.License = purchase.License
+		archive.Files = append(archive.Files, file)
+	}
+
+	if len(archive.Files) == 0 {
+		return archive, fmt.Errorf("%w: no own, purchased or free samples in pack", domain.ErrForbidden)
+	}
+
+	return archive, nil
+}
+
+// Write пишет архив в w. Аудио кладется без сжатия: wav почти не сжимается, а сжатие тормозило бы отдачу.
+// README.txt и LICENSE.txt пишутся последними и перечисляют только реально вошедшие файлы:
+// ответ уже отправляется, поэтому пропавший из хранилища объект пропускается, а не прерывает архив
+func (s *Service) Write(ctx context.Context, userUUID uuid.UUID, archive entity.PackArchive, w io.Writer) error {
+	zw := zip.NewWriter(w)
+
+	written := make([]entity.ArchiveFile, 0, len(archive.Files))
+	names := make([]string, 0, len(archive.Files))
+	var missing []entity.ArchiveFile
+	for _, file := range archive.Files {
+		name := fileName(file)
+
+		err := s.writeFile(ctx, zw, name, file)
+		if errors.Is(err, domain.ErrNotFound) {
+			slog.Warn("pack archive: sample audio is missing in storage",
+				slog.String("sample_id", file.Sample.ID.String()), slog.String("key", file.Key))
+			missing = append(missing, file)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		written = append(written, file)
+		names = append(names, name)
+		if file.Sample.AuthorUUID != userUUID {
+			s.events.RecordDownload(file.Sample.ID, userUUID)
+		}
+	}
+
+	if err := writeText(zw, readmeName, readme(archive, written, names, missing)); err != nil {
+		return err
+	}
+	if err := writeText(zw, licenseName, licenseText(written, names)); err != nil {
+		return err
+	}
+
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to finish archive: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Service) writeFile(ctx context.Context, zw *zip.Writer, name string, file entity.ArchiveFile) error {
+	reader, err := s.files.OpenFile(ctx, s.bucket, file.Key)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	dst, err := zw.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Store,
+		Modified: file.Sample.UpdatedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add %s to archive: %w", name, err)
+	}
+
+	if _, err = io.Copy(dst, reader); err != nil {
+		return fmt.Errorf("failed to copy %s to archive: %w", name, err)
+	}
+
+	return nil
+}
+
+func writeText(zw *zip.Writer, name, text string) error {
+	dst, err := zw.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add %s to archive: %w", name, err)
+	}
+
+	if _, err = io.WriteString(dst, text); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// fileName - "03 - Название.wav": номер трека сохраняет порядок пака в любом файловом менеджере
+func fileName(file entity.ArchiveFile) string {
+	ext := path.Ext(file.Key)
+	if ext == "" {
+		ext = defaultExt
+	}
+
+	title := []rune(strings.TrimSpace(strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || strings.ContainsRune(`/\:*?"<>|`, r) {
+			return '_'
+		}
+		return r
+	}, file.Sample.Title)))
+	if len(title) > maxTitleLength {
+		title = title[:maxTitleLength]
+	}
+	if len(title) == 0 {
+		title = []rune(file.Sample.ID.String())
+	}
+
+	return fmt.Sprintf("%02d - %s%s", file.Track, strings.TrimSpace(string(title)), ext)
+}
+
+func readme(archive entity.PackArchive, written []entity.ArchiveFile, names []string, missing []entity.ArchiveFile) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "%s\n", archive.Pack.Name)
+	fmt.Fprintf(&b, "Автор: %s\n", archive.Pack.AuthorName)
+	fmt.Fprintf(&b, "Пак: %s\n", archive.Pack.ID)
+	fmt.Fprintf(&b, "Архив собран: %s\n", time.Now().UTC().Format(time.RFC3339))
+	if archive.Pack.Description != "" {
+		fmt.Fprintf(&b, "\n%s\n", archive.Pack.Description)
+	}
+
+	fmt.Fprintf(&b, "\nФайлы:\n")
+	for i, file := range written {
+		fmt.Fprintf(&b, "%s\t%s\t%s\n", names[i], licenseLabel(file), file.Sample.ID)
+	}
+
+	if archive.Skipped > 0 {
+		fmt.Fprintf(&b, "\nНе вошли, потому что не куплены: %d\n", archive.Skipped)
+	}
+	if archive.Unavailable > 0 {
+		fmt.Fprintf(&b, "\nНе вошли, потому что еще не опубликованы или сняты с продажи: %d\n", archive.Unavailable)
+	}
+	if len(missing) > 0 {
+		fmt.Fprintf(&b, "\nНе удалось добавить, скачайте по отдельности:\n")
+		for _, file := range missing {
+			fmt.Fprintf(&b, "%02d\t%s\t%s\n", file.Track, file.Sample.Title, file.Sample.ID)
+		}
+	}
+
+	return b.String()
+}
+
+// licenseText - условия каждой лицензии, которая встречается в архиве, и файлы под ней
+func licenseText(written []entity.ArchiveFile, names []string) string {
+	byLicense := make(map[string][]string)
+	var own []string
+	for i, file := range written {
+		if file.Own {
+			own = append(own, names[i])
+			continue
+		}
+		byLicense[file.License] = append(byLicense[file.License], names[i])
+	}
+
+	var b strings.Builder
+	if len(own) > 0 {
+		fmt.Fprintf(&b, "Ваши семплы\n")
+		fmt.Fprintf(&b, "Вы автор этих семплов, лицензия на них не нужна.\n")
+		for _, name := range own {
+			fmt.Fprintf(&b, "  %s\n", name)
+		}
+		b.WriteString("\n")
+	}
+	for _, license := range licenseOrder {
+		files := byLicense[license]
+		if len(files) == 0 {
+			continue
+		}
+
+		if license == "" {
+			fmt.Fprintf(&b, "Бесплатные семплы\n")
+			fmt.Fprintf(&b, "Автор выложил их бесплатно, лицензия не покупалась.\n")
+		} else {
+			fmt.Fprintf(&b, "Лицензия %s\n", license)
+			fmt.Fprintf(&b, "%s\n", constant.LicenseTerms[license])
+		}
+		for _, name := range files {
+			fmt.Fprintf(&b, "  %s\n", name)
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("Подписанный сертификат лицензии можно получить в списке покупок.\n")
+
+	return b.String()
+}
+
+func licenseLabel(file entity.ArchiveFile) string {
+	if file.Own {
+		return "ваш семпл"
+	}
+	if file.License == "" {
+		return "бесплатно"
+	}
+
+	return "лицензия " + file.License
+}
@@ -0,0 +1,253 @@
+package archive
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/musicman-backend/internal/domain"
+	"github.com/musicman-backend/internal/domain/constant"
+	"github.com/musicman-backend/internal/domain/entity"
+)
+
+const testBucket = "samples"
+
+type fakePacks struct {
+	pack entity.Pack
+}
+
+func (f fakePacks) GetByID(_ context.Context, id uuid.UUID) (entity.Pack, error) {
+	if id != f.pack.ID {
+		return entity.Pack{}, domain.ErrNotFound
+	}
+	return f.pack, nil
+}
+
+type fakeSamples struct {
+	tracks []entity.Sample
+}
+
+func (f fakeSamples) GetPackTracks(context.Context, uuid.UUID) ([]entity.Sample, error) {
+	return f.tracks, nil
+}
+
+// fakePurchases - купленные семплы с ключом купленной версии
+type fakePurchases map[uuid.UUID]string
+
+func (f fakePurchases) Purchased(_ context.Context, _ uuid.UUID, sample entity.Sample) (entity.Purchase, string, bool, error) {
+	key, ok := f[sample.ID]
+	if !ok {
+		return entity.Purchase{}, "", false, nil
+	}
+	return entity.Purchase{License: constant.LicenseCommercial}, key, true, nil
+}
+
+type fakeFiles struct {
+	objects map[string]string
+	err     error
+}
+
+func (f fakeFiles) OpenFile(_ context.Context, bucket, key string) (io.ReadCloser, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	content, ok := f.objects[bucket+"/"+key]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return io.NopCloser(strings.NewReader(content)), nil
+}
+
+type fakeEvents struct {
+	downloads []uuid.UUID
+}
+
+func (f *fakeEvents) RecordDownload(sampleID, _ uuid.UUID) {
+	f.downloads = append(f.downloads, sampleID)
+}
+
+func published(title string, price int, key string) entity.Sample {
+	return entity.Sample{
+		ID:         uuid.New(),
+		AuthorUUID: uuid.New(),
+		Title:      title,
+		Price:      price,
+		MinioKey:   key,
+		Status:     constant.SampleStatusPublished,
+		UpdatedAt:  time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+}
+
+func TestPackSelectsAvailableFiles(t *testing.T) {
+	userUUID := uuid.New()
+	free := published("Free kick", 0, "free.wav")
+	bought := published("Bought snare", 100, "snare-v2.wav")
+	notBought := published("Hat", 100, "hat.wav")
+	noAudio := published("Draft", 0, "")
+	own := published("Own clap", 100, "clap.wav")
+	own.AuthorUUID = userUUID
+	own.Status = constant.SampleStatusDraft
+	pendingFree := published("Pending crash", 0, "crash.wav")
+	pendingFree.Status = constant.SampleStatusPending
+	delisted := published("Sold ride", 100, "ride.wav")
+	delisted.Status = constant.SampleStatusDelisted
+
+	pack := entity.Pack{ID: uuid.New(), Name: "Drums"}
+	s := New(fakePacks{pack}, fakeSamples{[]entity.Sample{free, bought, notBought, noAudio, own, pendingFree, delisted}},
+		fakePurchases{bought.ID: "snare-v1.wav"}, fakeFiles{}, &fakeEvents{}, testBucket)
+
+	archive, err := s.Pack(context.Background(), userUUID, pack.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(archive.Files) != 3 || archive.Skipped != 1 || archive.Unavailable != 2 {
+		t.Fatalf("%d files, %d skipped, %d unavailable, want 3, 1 and 2", len(archive.Files), archive.Skipped, archive.Unavailable)
+	}
+	if f := archive.Files[0]; f.Sample.ID != free.ID || f.Track != 1 || f.License != "" || f.Own {
+		t.Fatalf("first file %+v, want the free sample as track 1", f)
+	}
+	if f := archive.Files[1]; f.Key != "snare-v1.wav" || f.Track != 2 || f.License != constant.LicenseCommercial {
+		t.Fatalf("second file %+v, want the purchased version as track 2", f)
+	}
+	if f := archive.Files[2]; f.Sample.ID != own.ID || f.Track != 5 || !f.Own || f.Key != own.MinioKey {
+		t.Fatalf("third file %+v, want the caller's own draft as track 5", f)
+	}
+}
+
+func TestPackWithoutAvailableFiles(t *testing.T) {
+	pack := entity.Pack{ID: uuid.New()}
+	s := New(fakePacks{pack}, fakeSamples{[]entity.Sample{published("Hat", 100, "hat.wav")}},
+		fakePurchases{}, fakeFiles{}, &fakeEvents{}, testBucket)
+
+	if _, err := s.Pack(context.Background(), uuid.New(), pack.ID); !errors.Is(err, domain.ErrForbidden) {
+		t.Fatalf("got %v, want ErrForbidden", err)
+	}
+
+	deletedAt := time.Now()
+	s.packs = fakePacks{entity.Pack{ID: pack.ID, DeletedAt: &deletedAt}}
+	if _, err := s.Pack(context.Background(), uuid.New(), pack.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("deleted pack: got %v, want ErrNotFound", err)
+	}
+}
+
+func TestWriteStreamsArchive(t *testing.T) {
+	userUUID := uuid.New()
+	kick := published("Kick", 0, "kick.wav")
+	own := published("My/Snare", 100, "snare.aiff")
+	own.AuthorUUID = userUUID
+	lost := published("Lost", 0, "lost.wav")
+
+	events := &fakeEvents{}
+	files := fakeFiles{objects: map[string]string{
+		testBucket + "/kick.wav":   "kick audio",
+		testBucket + "/snare.aiff": "snare audio",
+	}}
+	s := New(nil, nil, nil, files, events, testBucket)
+
+	archive := entity.PackArchive{
+		Pack: entity.Pack{ID: uuid.New(), Name: "Drums", AuthorName: "producer"},
+		Files: []entity.ArchiveFile{
+			{Sample: kick, Track: 1, Key: kick.MinioKey, License: constant.LicensePersonal},
+			{Sample: lost, Track: 2, Key: lost.MinioKey},
+			{Sample: own, Track: 3, Key: own.MinioKey, Own: true},
+		},
+		Skipped:     1,
+		Unavailable: 2,
+	}
+
+	var buf bytes.Buffer
+	if err := s.Write(context.Background(), userUUID, archive, &buf); err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	contents := make(map[string]string)
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(rc)
+		_ = rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		contents[f.Name] = string(data)
+
+		if strings.HasSuffix(f.Name, ".txt") != (f.Method == zip.Deflate) {
+			t.Fatalf("%s stored with method %d: audio must be stored, texts deflated", f.Name, f.Method)
+		}
+	}
+
+	want := []string{"01 - Kick.wav", "03 - My_Snare.aiff", readmeName, licenseName}
+	if strings.Join(names, "|") != strings.Join(want, "|") {
+		t.Fatalf("archive entries %v, want %v", names, want)
+	}
+	if contents["01 - Kick.wav"] != "kick audio" || contents["03 - My_Snare.aiff"] != "snare audio" {
+		t.Fatal("audio must be copied unchanged")
+	}
+
+	readme := contents[readmeName]
+	if !strings.Contains(readme, "Не вошли, потому что не куплены: 1") || !strings.Contains(readme, lost.ID.String()) {
+		t.Fatalf("readme must list skipped and missing samples:\n%s", readme)
+	}
+	if !strings.Contains(readme, "еще не опубликованы или сняты с продажи: 2") || !strings.Contains(readme, "03 - My_Snare.aiff\tваш семпл") {
+		t.Fatalf("readme must count unavailable samples apart and mark own ones:\n%s", readme)
+	}
+	license := contents[licenseName]
+	if strings.Contains(license, "Lost") || !strings.Contains(license, "Лицензия personal\n"+constant.LicenseTerms[constant.LicensePersonal]+"\n  01 - Kick.wav") {
+		t.Fatalf("license must list only written files under their licenses:\n%s", license)
+	}
+	if !strings.Contains(license, "Ваши семплы") || strings.Contains(license, "Бесплатные") {
+		t.Fatalf("own samples must get their own section, not the free one:\n%s", license)
+	}
+
+	if len(events.downloads) != 1 || events.downloads[0] != kick.ID {
+		t.Fatalf("recorded downloads %v, want only %s: own samples are not counted", events.downloads, kick.ID)
+	}
+}
+
+func TestWriteFailsOnStorageError(t *testing.T) {
+	s := New(nil, nil, nil, fakeFiles{err: errors.New("storage is down")}, &fakeEvents{}, testBucket)
+	archive := entity.PackArchive{Files: []entity.ArchiveFile{{Sample: published("Kick", 0, "kick.wav"), Track: 1, Key: "kick.wav"}}}
+
+	if err := s.Write(context.Background(), uuid.New(), archive, io.Discard); err == nil {
+		t.Fatal("storage error other than not found must abort the archive")
+	}
+}
+
+func TestFileName(t *testing.T) {
+	long := strings.Repeat("я", maxTitleLength+10)
+	id := uuid.New()
+
+	tests := []struct {
+		file entity.ArchiveFile
+		want string
+	}{
+		{entity.ArchiveFile{Track: 3, Key: "a/b.wav", Sample: entity.Sample{Title: "  Kick  "}}, "03 - Kick.wav"},
+		{entity.ArchiveFile{Track: 12, Key: "noext", Sample: entity.Sample{Title: `a:b*c?"d`}}, "12 - a_b_c__d.wav"},
+		{entity.ArchiveFile{Track: 1, Key: "x.flac", Sample: entity.Sample{ID: id, Title: " "}}, "01 - " + id.String() + ".flac"},
+		{entity.ArchiveFile{Track: 1, Key: "x.wav", Sample: entity.Sample{Title: long}}, "01 - " + strings.Repeat("я", maxTitleLength) + ".wav"},
+	}
+
+	for _, tt := range tests {
+		if got := fileName(tt.file); got != tt.want {
+			t.Fatalf("fileName(%q) = %q, want %q", tt.file.Sample.Title, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,302 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/musicman-backend/internal/domain"
+	"github.com/musicman-backend/internal/domain/entity"
+	"github.com/musicman-backend/pkg/mailer"
+)
+
+const tokenRandomBytes = 32
+
+// ChangeEmail ставит пользователю новую почту и отправляет на нее письмо с подтверждением.
+// Повторный вызов с тем же адресом отправляет письмо заново
+func (s *Service) ChangeEmail(ctx context.Context, userUUID uuid.UUID, email string) error {
+	email, err := normalizeEmail(email)
+	if err != nil {
+		return err
+	}
+	if email == "" {
+		return fmt.Errorf("%w: email is required", domain.ErrInvalidEmail)
+	}
+
+	if err = s.checkEmailFree(ctx, email, userUUID); err != nil {
+		return err
+	}
+
+	user, err := s.user.GetUserByUUID(ctx, userUUID)
+	if err != nil {
+		return fmt.Errorf("get user failed: %w", err)
+	}
+
+	if !strings.EqualFold(user.Email, email) {
+		if err = s.user.SetEmail(ctx, userUUID, email); err != nil {
+			return fmt.Errorf("set email failed: %w", err)
+		}
+	} else if user.EmailVerifiedAt != nil {
+		return nil
+	}
+
+	return s.sendVerification(ctx, userUUID, email)
+}
+
+// VerifyEmail подтверждает почту по токену из письма
+func (s *Service) VerifyEmail(ctx context.Context, rawToken string) error {
+	token, err := s.consumeToken(ctx, rawToken, entity.TokenPurposeVerifyEmail)
+	if err != nil {
+		return err
+	}
+
+	// если почту успели сменить, токен от старого адреса ничего не подтверждает
+	err = s.user.VerifyEmail(ctx, token.UserUUID, token.Email, time.Now())
+	if errors.Is(err, domain.ErrNotFound) {
+		return domain.ErrInvalidToken
+	}
+	if errors.Is(err, domain.ErrEmailAlreadyUsed) {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("verify email failed: %w", err)
+	}
+
+	return nil
+}
+
+// ForgotPassword отправляет письмо со ссылкой на сброс пароля, только на подтвержденную почту.
+// Чтобы по ответу нельзя было узнать, зарегистрирован ли адрес, для неизвестной почты ошибки нет
+func (s *Service) ForgotPassword(ctx context.Context, email string) error {
+	email, err := normalizeEmail(email)
+	if err != nil {
+		return err
+	}
+	if email == "" {
+		return fmt.Errorf("%w: email is required", domain.ErrInvalidEmail)
+	}
+
+	user, err := s.user.GetUserByEmail(ctx, email)
+	if errors.Is(err, domain.ErrNotFound) {
+		slog.Info("password reset requested for unknown or unverified email")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("get user failed: %w", err)
+	}
+
+	raw, err := s.issueToken(ctx, user.UUID, entity.TokenPurposeResetPassword, user.Email, s.accounts.ResetPasswordTTL)
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Сброс пароля MusicMan",
+		Body: fmt.Sprintf("Здравствуйте, %s!\n\nЧтобы задать новый пароль, перейдите по ссылке:\n%s\n\n"+
+			"Ссылка действует %s. Если вы не запрашивали сброс, просто проигнорируйте это письмо.\n",
+			user.Login, linkWithToken(s.accounts.ResetPasswordURL, raw), s.accounts.ResetPasswordTTL),
+	})
+}
+
+// ResetPassword задает новый пароль по токену из письма и гасит остальные ссылки на сброс
+func (s *Service) ResetPassword(ctx context.Context, rawToken, password string) error {
+	token, err := s.parseToken(ctx, rawToken, entity.TokenPurposeResetPassword)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.user.GetUserByUUID(ctx, token.UserUUID)
+	if err != nil {
+		return fmt.Errorf("get user failed: %w", err)
+	}
+
+	if err = validatePassword(user.Login, password); err != nil {
+		return err
+	}
+
+	// гасим токен до смены пароля, чтобы одну ссылку нельзя было использовать дважды параллельно
+	now := time.Now()
+	if err = s.tokens.MarkUsed(ctx, token.ID, now); err != nil {
+		return err
+	}
+
+	passHash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("generate password failed: %w", err)
+	}
+
+	if err = s.user.UpdatePassword(ctx, user.UUID, string(passHash)); err != nil {
+		return fmt.Errorf("update password failed: %w", err)
+	}
+
+	if err = s.tokens.InvalidateByUser(ctx, user.UUID, entity.TokenPurposeResetPassword, now); err != nil {
+		slog.Error("failed to invalidate reset tokens", slog.String("err", err.Error()))
+	}
+
+	// пароль сбрасывают, когда доступ к аккаунту мог попасть к кому-то еще - выходим со всех устройств
+	if err = s.sessions.RevokeAll(ctx, user.UUID); err != nil {
+		slog.Error("failed to revoke sessions", slog.String("err", err.Error()))
+	}
+
+	// владелец почты доказал, что это его аккаунт, блокировку за перебор снимаем
+	if err = s.limiter.LoginSucceeded(ctx, user.Login); err != nil {
+		slog.Error("failed to reset login failures", slog.String("err", err.Error()))
+	}
+
+	return nil
+}
+
+func (s *Service) sendVerification(ctx context.Context, userUUID uuid.UUID, email string) error {
+	raw, err := s.issueToken(ctx, userUUID, entity.TokenPurposeVerifyEmail, email, s.accounts.VerifyEmailTTL)
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(ctx, mailer.Message{
+		To:      email,
+		Subject: "Подтверждение почты MusicMan",
+		Body: fmt.Sprintf("Здравствуйте!\n\nЧтобы подтвердить адрес %s, перейдите по ссылке:\n%s\n\nСсылка действует %s.\n",
+			email, linkWithToken(s.accounts.VerifyEmailURL, raw), s.accounts.VerifyEmailTTL),
+	})
+}
+
+// checkEmailFree - адрес занят, только если его подтвердил другой пользователь.
+// Неподтвержденный чужой адрес не мешает владельцу: подтверждение вытеснит остальных
+func (s *Service) checkEmailFree(ctx context.Context, email string, userUUID uuid.UUID) error {
+	owner, err := s.user.GetUserByEmail(ctx, email)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("get user failed: %w", err)
+	}
+	if owner.UUID != userUUID {
+		return domain.ErrEmailAlreadyUsed
+	}
+
+	return nil
+}
+
+// issueToken создает токен вида <random>.<hmac>. Подпись отсекает мусор без похода в базу,
+// а в базе лежит только sha256 от случайной части
+func (s *Service) issueToken(ctx context.Context, userUUID uuid.UUID, purpose entity.TokenPurpose, email string, ttl time.Duration) (string, error) {
+	random := make([]byte, tokenRandomBytes)
+	if _, err := rand.Read(random); err != nil {
+		return "", fmt.Errorf("generate token failed: %w", err)
+	}
+
+	now := time.Now()
+	err := s.tokens.Create(ctx, entity.UserToken{
+		UserUUID:  userUUID,
+		Purpose:   purpose,
+		Hash:      hashToken(random),
+		Email:     email,
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	})
+	if err != nil {
+		return "", fmt.Errorf("save token failed: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(random) + "." + s.signToken(purpose, random), nil
+}
+
+// parseToken проверяет подпись, назначение и срок токена, но не гасит его
+func (s *Service) parseToken(ctx context.Context, raw string, purpose entity.TokenPurpose) (entity.UserToken, error) {
+	randomPart, signature, ok := strings.Cut(raw, ".")
+	if !ok {
+		return entity.UserToken{}, domain.ErrInvalidToken
+	}
+
+	random, err := base64.RawURLEncoding.DecodeString(randomPart)
+	if err != nil || len(random) != tokenRandomBytes {
+		return entity.UserToken{}, domain.ErrInvalidToken
+	}
+	if !hmac.Equal([]byte(signature), []byte(s.signToken(purpose, random))) {
+		return entity.UserToken{}, domain.ErrInvalidToken
+	}
+
+	token, err := s.tokens.GetByHash(ctx, hashToken(random))
+	if errors.Is(err, domain.ErrNotFound) {
+		return entity.UserToken{}, domain.ErrInvalidToken
+	}
+	if err != nil {
+		return entity.UserToken{}, fmt.Errorf("get token failed: %w", err)
+	}
+
+	if token.Purpose != purpose || token.UsedAt != nil || time.Now().After(token.ExpiresAt) {
+		return entity.UserToken{}, domain.ErrInvalidToken
+	}
+
+	return token, nil
+}
+
+// consumeToken проверяет и сразу гасит токен
+func (s *Service) consumeToken(ctx context.Context, raw string, purpose entity.TokenPurpose) (entity.UserToken, error) {
+	token, err := s.parseToken(ctx, raw, purpose)
+	if err != nil {
+		return token, err
+	}
+
+	if err = s.tokens.MarkUsed(ctx, token.ID, time.Now()); err != nil {
+		return token, err
+	}
+
+	return token, nil
+}
+
+func (s *Service) signToken(purpose entity.TokenPurpose, random []byte) string {
+	mac := hmac.New(sha256.New, []byte(s.accounts.Secret))
+	mac.Write([]byte(purpose))
+	mac.Write([]byte{0})
+	mac.Write(random)
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func hashToken(random []byte) string {
+	sum := sha256.Sum256(random)
+	return hex.EncodeToString(sum[:])
+}
+
+// normalizeEmail принимает только голый адрес, без имени: "user@example.com"
+func normalizeEmail(email string) (string, error) {
+	email = strings.TrimSpace(email)
+	if email == "" {
+		return "", nil
+	}
+
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Name != "" || addr.Address != email || len(email) > 320 {
+		return "", fmt.Errorf("%w: %s", domain.ErrInvalidEmail, email)
+	}
+
+	return email, nil
+}
+
+func linkWithToken(base, token string) string {
+	link, err := url.Parse(base)
+	if err != nil {
+		return base + "?token=" + url.QueryEscape(token)
+	}
+
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+
+	return link.String()
+}
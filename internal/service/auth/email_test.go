@@ -0,0 +1,272 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/musicman-backend/config"
+	"github.com/musicman-backend/internal/domain"
+	"github.com/musicman-backend/internal/domain/entity"
+	"github.com/musicman-backend/pkg/mailer"
+)
+
+type fakeTokens struct {
+	mu     sync.Mutex
+	byHash map[string]entity.UserToken
+}
+
+func newFakeTokens() *fakeTokens {
+	return &fakeTokens{byHash: make(map[string]entity.UserToken)}
+}
+
+func (f *fakeTokens) Create(ctx context.Context, token entity.UserToken) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	token.ID = uuid.New()
+	f.byHash[token.Hash] = token
+	return nil
+}
+
+func (f *fakeTokens) GetByHash(ctx context.Context, hash string) (entity.UserToken, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	token, ok := f.byHash[hash]
+	if !ok {
+		return token, domain.ErrNotFound
+	}
+	return token, nil
+}
+
+func (f *fakeTokens) MarkUsed(ctx context.Context, id uuid.UUID, usedAt time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for hash, token := range f.byHash {
+		if token.ID == id {
+			token.UsedAt = &usedAt
+			f.byHash[hash] = token
+			return nil
+		}
+	}
+	return domain.ErrNotFound
+}
+
+func (f *fakeTokens) InvalidateByUser(ctx context.Context, userUUID uuid.UUID, purpose entity.TokenPurpose, usedAt time.Time) error {
+	return nil
+}
+
+func newTokenService(tokens TokenRepository) *Service {
+	return &Service{tokens: tokens, accounts: config.Accounts{Secret: "test-secret"}}
+}
+
+func TestTokenRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	s := newTokenService(newFakeTokens())
+	userUUID := uuid.New()
+
+	raw, err := s.issueToken(ctx, userUUID, entity.TokenPurposeVerifyEmail, "user@example.com", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := s.consumeToken(ctx, raw, entity.TokenPurposeVerifyEmail)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.UserUUID != userUUID || token.Email != "user@example.com" {
+		t.Fatalf("unexpected token %+v", token)
+	}
+
+	if _, err = s.consumeToken(ctx, raw, entity.TokenPurposeVerifyEmail); !errors.Is(err, domain.ErrInvalidToken) {
+		t.Fatalf("token must be single use, got %v", err)
+	}
+}
+
+func TestTokenRejected(t *testing.T) {
+	ctx := context.Background()
+	tokens := newFakeTokens()
+	s := newTokenService(tokens)
+
+	raw, err := s.issueToken(ctx, uuid.New(), entity.TokenPurposeVerifyEmail, "user@example.com", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	random, signature, _ := strings.Cut(raw, ".")
+
+	expired, err := s.issueToken(ctx, uuid.New(), entity.TokenPurposeResetPassword, "user@example.com", -time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]struct {
+		raw     string
+		purpose entity.TokenPurpose
+		service *Service
+	}{
+		"wrong purpose":   {raw, entity.TokenPurposeResetPassword, s},
+		"no signature":    {random, entity.TokenPurposeVerifyEmail, s},
+		"bad signature":   {random + "." + strings.Repeat("A", len(signature)), entity.TokenPurposeVerifyEmail, s},
+		"bad random":      {"!!!." + signature, entity.TokenPurposeVerifyEmail, s},
+		"other secret":    {raw, entity.TokenPurposeVerifyEmail, &Service{tokens: tokens, accounts: config.Accounts{Secret: "other"}}},
+		"expired":         {expired, entity.TokenPurposeResetPassword, s},
+		"unknown token":   {raw, entity.TokenPurposeVerifyEmail, newTokenService(newFakeTokens())},
+		"empty":           {"", entity.TokenPurposeVerifyEmail, s},
+		"signature twice": {raw + "." + signature, entity.TokenPurposeVerifyEmail, s},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := tc.service.parseToken(ctx, tc.raw, tc.purpose); !errors.Is(err, domain.ErrInvalidToken) {
+				t.Fatalf("expected ErrInvalidToken, got %v", err)
+			}
+		})
+	}
+}
+
+func TestNormalizeEmail(t *testing.T) {
+	valid := map[string]string{
+		"user@example.com":     "user@example.com",
+		"  user@example.com  ": "user@example.com",
+		"":                     "",
+	}
+	for in, want := range valid {
+		got, err := normalizeEmail(in)
+		if err != nil || got != want {
+			t.Fatalf("normalizeEmail(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+
+	for _, in := range []string{"user", "User <user@example.com>", "<user@example.com>", strings.Repeat("a", 320) + "@example.com"} {
+		if _, err := normalizeEmail(in); !errors.Is(err, domain.ErrInvalidEmail) {
+			t.Fatalf("normalizeEmail(%q): expected ErrInvalidEmail, got %v", in, err)
+		}
+	}
+}
+
+// fakeUsers повторяет правила репозитория: GetUserByEmail находит только подтвержденную почту,
+// VerifyEmail вытесняет неподтвержденные заявки на тот же адрес
+type fakeUsers struct {
+	UserController
+	users map[uuid.UUID]entity.User
+}
+
+func (f *fakeUsers) GetUserByUUID(_ context.Context, userUUID uuid.UUID) (entity.User, error) {
+	user, ok := f.users[userUUID]
+	if !ok {
+		return user, domain.ErrNotFound
+	}
+	return user, nil
+}
+
+func (f *fakeUsers) GetUserByEmail(_ context.Context, email string) (entity.User, error) {
+	for _, user := range f.users {
+		if strings.EqualFold(user.Email, email) && user.EmailVerifiedAt != nil {
+			return user, nil
+		}
+	}
+	return entity.User{}, domain.ErrNotFound
+}
+
+func (f *fakeUsers) SetEmail(_ context.Context, userUUID uuid.UUID, email string) error {
+	user := f.users[userUUID]
+	user.Email, user.EmailVerifiedAt = email, nil
+	f.users[userUUID] = user
+	return nil
+}
+
+func (f *fakeUsers) VerifyEmail(_ context.Context, userUUID uuid.UUID, email string, verifiedAt time.Time) error {
+	user := f.users[userUUID]
+	if !strings.EqualFold(user.Email, email) {
+		return domain.ErrNotFound
+	}
+	if _, err := f.GetUserByEmail(context.Background(), email); err == nil {
+		// уникальный индекс по подтвержденной почте
+		return domain.ErrEmailAlreadyUsed
+	}
+
+	user.EmailVerifiedAt = &verifiedAt
+	f.users[userUUID] = user
+	for id, other := range f.users {
+		if id != userUUID && strings.EqualFold(other.Email, email) && other.EmailVerifiedAt == nil {
+			other.Email = ""
+			f.users[id] = other
+		}
+	}
+	return nil
+}
+
+// fakeMailer запоминает токен из последнего письма каждому адресату
+type fakeMailer struct {
+	tokens map[string]string
+}
+
+func (f *fakeMailer) Send(_ context.Context, msg mailer.Message) error {
+	_, rest, _ := strings.Cut(msg.Body, "token=")
+	raw, _, _ := strings.Cut(rest, "\n")
+	token, err := url.QueryUnescape(raw)
+	if err != nil {
+		return err
+	}
+	f.tokens[msg.To] = token
+	return nil
+}
+
+func TestVerifyEmailClaimedTwice(t *testing.T) {
+	ctx := context.Background()
+	first, second := uuid.New(), uuid.New()
+	users := &fakeUsers{users: map[uuid.UUID]entity.User{
+		first:  {UUID: first, Login: "first"},
+		second: {UUID: second, Login: "second"},
+	}}
+	mail := &fakeMailer{tokens: map[string]string{}}
+	s := &Service{user: users, tokens: newFakeTokens(), mailer: mail, accounts: config.Accounts{
+		Secret:         "test-secret",
+		VerifyEmailURL: "https://musicman.local/verify",
+		VerifyEmailTTL: time.Hour,
+	}}
+	const email = "shared@example.com"
+
+	// пока адрес никто не подтвердил, указать его могут оба
+	if err := s.ChangeEmail(ctx, first, email); err != nil {
+		t.Fatal(err)
+	}
+	firstToken := mail.tokens[email]
+	if err := s.ChangeEmail(ctx, second, email); err != nil {
+		t.Fatal(err)
+	}
+	secondToken := mail.tokens[email]
+
+	if err := s.VerifyEmail(ctx, firstToken); err != nil {
+		t.Fatal(err)
+	}
+	if users.users[second].Email != "" {
+		t.Fatalf("second claim kept %q after the first verification", users.users[second].Email)
+	}
+
+	if err := s.VerifyEmail(ctx, secondToken); !errors.Is(err, domain.ErrInvalidToken) {
+		t.Fatalf("released claim: got %v, want ErrInvalidToken", err)
+	}
+	if err := s.ChangeEmail(ctx, second, email); !errors.Is(err, domain.ErrEmailAlreadyUsed) {
+		t.Fatalf("claim of a verified email: got %v, want ErrEmailAlreadyUsed", err)
+	}
+
+	// заявку, которую не успели вытеснить, не пускает уникальность подтвержденной почты
+	users.users[second] = entity.User{UUID: second, Login: "second", Email: email}
+	if err := s.sendVerification(ctx, second, email); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.VerifyEmail(ctx, mail.tokens[email]); !errors.Is(err, domain.ErrEmailAlreadyUsed) {
+		t.Fatalf("racing claim: got %v, want ErrEmailAlreadyUsed", err)
+	}
+	if users.users[second].EmailVerifiedAt != nil || users.users[first].EmailVerifiedAt == nil {
+		t.Fatal("verified owner must not change")
+	}
+}
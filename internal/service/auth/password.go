@@ -0,0 +1,105 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/musicman-backend/internal/domain"
+)
+
+const (
+	minPasswordLength = 8
+	// bcrypt молча обрезает пароль длиннее 72 байт
+	maxPasswordBytes = 72
+)
+
+// commonPasswords - самые популярные пароли из утечек, которые проходят остальные правила
+var commonPasswords = map[string]struct{}{
+	"password1":   {},
+	"password123": {},
+	"qwerty123":   {},
+	"qwerty1234":  {},
+	"1q2w3e4r":    {},
+	"1qaz2wsx":    {},
+	"abc12345":    {},
+	"abcd1234":    {},
+	"a1234567":    {},
+	"iloveyou1":   {},
+	"zaq12wsx":    {},
+	"qwe123qwe":   {},
+}
+
+func validatePassword(login, password string) error {
+	if len([]rune(password)) < minPasswordLength {
+		return fmt.Errorf("%w: password must be at least %d characters long", domain.ErrWeakPassword, minPasswordLength)
+	}
+	if len(password) > maxPasswordBytes {
+		return fmt.Errorf("%w: password must be at most %d bytes long", domain.ErrWeakPassword, maxPasswordBytes)
+	}
+
+	var hasLetter, hasDigit bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLetter(r):
+			hasLetter = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		}
+	}
+	if !hasLetter || !hasDigit {
+		return fmt.Errorf("%w: password must contain both letters and digits", domain.ErrWeakPassword)
+	}
+
+	lower := strings.ToLower(password)
+	if login != "" && strings.Contains(lower, strings.ToLower(login)) {
+		return fmt.Errorf("%w: password must not contain login", domain.ErrWeakPassword)
+	}
+	if _, ok := commonPasswords[lower]; ok {
+		return fmt.Errorf("%w: password is too common", domain.ErrWeakPassword)
+	}
+
+	return nil
+}
+
+// ChangePassword меняет пароль по старому и завершает остальные сессии пользователя.
+// У аккаунта без пароля (вход только через провайдера) пароль задается через сброс по почте
+func (s *Service) ChangePassword(ctx context.Context, userUUID, sessionID uuid.UUID, oldPassword, newPassword string) error {
+	user, err := s.user.GetUserByUUID(ctx, userUUID)
+	if err != nil {
+		return fmt.Errorf("get user failed: %w", err)
+	}
+
+	if err = s.allowLogin(ctx, user.Login); err != nil {
+		return err
+	}
+
+	if user.PassHash == "" || bcrypt.CompareHashAndPassword([]byte(user.PassHash), []byte(oldPassword)) != nil {
+		s.loginFailed(ctx, user.Login)
+		return domain.ErrInvalidCredentials
+	}
+
+	if err = validatePassword(user.Login, newPassword); err != nil {
+		return err
+	}
+
+	passHash, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("generate password failed: %w", err)
+	}
+
+	if err = s.user.UpdatePassword(ctx, user.UUID, string(passHash)); err != nil {
+		return fmt.Errorf("update password failed: %w", err)
+	}
+
+	if err = s.sessions.RevokeOthers(ctx, user.UUID, sessionID); err != nil {
+		slog.Error("failed to revoke sessions", slog.String("err", err.Error()))
+	}
+
+	return nil
+}
@@ -4,53 +4,209 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
+	"time"
 
+	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 
+	"github.com/musicman-backend/config"
 	"github.com/musicman-backend/internal/domain"
 	"github.com/musicman-backend/internal/domain/entity"
+	"github.com/musicman-backend/pkg/mailer"
+	"github.com/musicman-backend/pkg/username"
 )
 
 type UserController interface {
 	GetUserByLogin(ctx context.Context, login string) (entity.User, error)
-	CreateUser(ctx context.Context, login, passHash string) (entity.User, error)
+	GetUserByUUID(ctx context.Context, userUUID uuid.UUID) (entity.User, error)
+	GetUserByEmail(ctx context.Context, email string) (entity.User, error)
+	CreateUser(ctx context.Context, login, passHash, email string) (entity.User, error)
+	SetEmail(ctx context.Context, userUUID uuid.UUID, email string) error
+	VerifyEmail(ctx context.Context, userUUID uuid.UUID, email string, verifiedAt time.Time) error
+	UpdatePassword(ctx context.Context, userUUID uuid.UUID, passHash string) error
+}
+
+type TokenRepository interface {
+	Create(ctx context.Context, token entity.UserToken) error
+	GetByHash(ctx context.Context, hash string) (entity.UserToken, error)
+	MarkUsed(ctx context.Context, id uuid.UUID, usedAt time.Time) error
+	InvalidateByUser(ctx context.Context, userUUID uuid.UUID, purpose entity.TokenPurpose, usedAt time.Time) error
+}
+
+type Mailer interface {
+	Send(ctx context.Context, msg mailer.Message) error
 }
 
 type Tokenizer interface {
-	CreateToken(ctx context.Context, user entity.User) (string, error)
+	CreateChallengeToken(ctx context.Context, user entity.User) (string, error)
+	VerifyChallengeToken(ctx context.Context, token string) (entity.JWTClaims, error)
+}
+
+// Sessions выдает JWT, привязанный к новой сессии пользователя
+type Sessions interface {
+	Start(ctx context.Context, user entity.User, client entity.ClientInfo) (string, error)
+	RevokeAll(ctx context.Context, userUUID uuid.UUID) error
+	RevokeOthers(ctx context.Context, userUUID, current uuid.UUID) error
+}
+
+// SecondFactor проверяет код 2FA пользователя
+type SecondFactor interface {
+	Verify(ctx context.Context, user entity.User, code string) error
+}
+
+// LoginLimiter ограничивает попытки входа в аккаунт и блокирует его после серии неудач
+type LoginLimiter interface {
+	AllowLogin(ctx context.Context, login string) error
+	LoginFailed(ctx context.Context, login string) error
+	LoginSucceeded(ctx context.Context, login string) error
 }
 
 type Service struct {
-	user  UserController
-	token Tokenizer
+	user     UserController
+	token    Tokenizer
+	sessions Sessions
+	limiter  LoginLimiter
+	tokens   TokenRepository
+	mailer   Mailer
+	second   SecondFactor
+
+	accounts config.Accounts
 }
 
-func NewService(user UserController, token Tokenizer) *Service {
+func NewService(user UserController, token Tokenizer, sessions Sessions, limiter LoginLimiter, tokens TokenRepository, mailer Mailer, second SecondFactor, accounts config.Accounts) *Service {
 	return &Service{
-		user:  user,
-		token: token,
+		user:     user,
+		token:    token,
+		sessions: sessions,
+		limiter:  limiter,
+		tokens:   tokens,
+		mailer:   mailer,
+		second:   second,
+		accounts: accounts,
 	}
 }
 
-func (s *Service) Login(ctx context.Context, login, password string) (string, error) {
+// Login проверяет пароль. При включенной 2FA вместо JWT возвращает токен для второго шага
+func (s *Service) Login(ctx context.Context, login, password string, client entity.ClientInfo) (entity.LoginResult, error) {
+	// несуществующие логины ограничиваются так же, чтобы по ответам нельзя было их перебирать
+	if err := s.allowLogin(ctx, login); err != nil {
+		return entity.LoginResult{}, err
+	}
+
 	user, err := s.user.GetUserByLogin(ctx, login)
 	if err != nil && !errors.Is(err, domain.ErrNotFound) {
-		return "", fmt.Errorf("get user failed: %w", err)
+		return entity.LoginResult{}, fmt.Errorf("get user failed: %w", err)
+	}
+
+	if errors.Is(err, domain.ErrNotFound) ||
+		bcrypt.CompareHashAndPassword([]byte(user.PassHash), []byte(password)) != nil {
+		s.loginFailed(ctx, login)
+		return entity.LoginResult{}, domain.ErrInvalidCredentials
+	}
+
+	// счетчик неудач сбрасываем только после второго шага, иначе с верным паролем можно перебирать коды
+	if user.TwoFactorEnabled() {
+		challenge, err := s.token.CreateChallengeToken(ctx, user)
+		if err != nil {
+			return entity.LoginResult{}, err
+		}
+		return entity.LoginResult{ChallengeToken: challenge}, nil
+	}
+
+	s.loginSucceeded(ctx, login)
+
+	token, err := s.sessions.Start(ctx, user, client)
+	if err != nil {
+		return entity.LoginResult{}, err
 	}
 
+	return entity.LoginResult{Token: token}, nil
+}
+
+// LoginTwoFactor - второй шаг входа: меняет токен из Login и код 2FA на JWT
+func (s *Service) LoginTwoFactor(ctx context.Context, challenge, code string, client entity.ClientInfo) (string, error) {
+	claims, err := s.token.VerifyChallengeToken(ctx, challenge)
+	if err != nil {
+		return "", domain.ErrInvalidToken
+	}
+
+	user, err := s.user.GetUserByUUID(ctx, claims.UserUUID)
 	if errors.Is(err, domain.ErrNotFound) {
-		return "", domain.ErrInvalidCredentials
+		return "", domain.ErrInvalidToken
 	}
+	if err != nil {
+		return "", fmt.Errorf("get user failed: %w", err)
+	}
+
+	// коды перебираются под тем же ограничением, что и пароли
+	if err = s.allowLogin(ctx, user.Login); err != nil {
+		return "", err
+	}
+
+	err = s.second.Verify(ctx, user, code)
+	if errors.Is(err, domain.ErrInvalidTwoFactorCode) || errors.Is(err, domain.ErrTwoFactorRequired) {
+		s.loginFailed(ctx, user.Login)
+		return "", domain.ErrInvalidTwoFactorCode
+	}
+	// 2FA выключили, пока пользователь вводил код - пароль уже проверен
+	if err != nil && !errors.Is(err, domain.ErrTwoFactorDisabled) {
+		return "", fmt.Errorf("verify second factor failed: %w", err)
+	}
+
+	s.loginSucceeded(ctx, user.Login)
 
-	if bcrypt.CompareHashAndPassword([]byte(user.PassHash), []byte(password)) != nil {
-		return "", domain.ErrInvalidCredentials
+	return s.sessions.Start(ctx, user, client)
+}
+
+// allowLogin пропускает попытку, если хранилище счетчиков недоступно, как и RateLimitMiddleware:
+// сбой лимитера не должен ломать вход. Ошибка - только *domain.RetryAfterError
+func (s *Service) allowLogin(ctx context.Context, login string) error {
+	err := s.limiter.AllowLogin(ctx, login)
+
+	var retryErr *domain.RetryAfterError
+	if errors.As(err, &retryErr) {
+		return err
+	}
+	if err != nil {
+		slog.Error("failed to check login rate limit", slog.String("err", err.Error()))
 	}
 
-	return s.token.CreateToken(ctx, user)
+	return nil
 }
 
-func (s *Service) Register(ctx context.Context, login, password string) (string, error) {
-	_, err := s.user.GetUserByLogin(ctx, login)
+func (s *Service) loginFailed(ctx context.Context, login string) {
+	if err := s.limiter.LoginFailed(ctx, login); err != nil {
+		slog.Error("failed to register login failure", slog.String("err", err.Error()))
+	}
+}
+
+func (s *Service) loginSucceeded(ctx context.Context, login string) {
+	if err := s.limiter.LoginSucceeded(ctx, login); err != nil {
+		slog.Error("failed to reset login failures", slog.String("err", err.Error()))
+	}
+}
+
+// Register создает пользователя. Почта необязательна, если указана - на нее уходит письмо с подтверждением
+func (s *Service) Register(ctx context.Context, login, password, email string, client entity.ClientInfo) (string, error) {
+	if err := username.Validate(login); err != nil {
+		return "", fmt.Errorf("%w: %s", domain.ErrInvalidLogin, err)
+	}
+	if err := validatePassword(login, password); err != nil {
+		return "", err
+	}
+
+	email, err := normalizeEmail(email)
+	if err != nil {
+		return "", err
+	}
+	if email != "" {
+		if err = s.checkEmailFree(ctx, email, uuid.Nil); err != nil {
+			return "", err
+		}
+	}
+
+	_, err = s.user.GetUserByLogin(ctx, login)
 	if err == nil {
 		return "", domain.ErrUserAlreadyExists
 	}
@@ -64,10 +220,17 @@ func (s *Service) Register(ctx context.Context, login, password string) (string,
 		return "", fmt.Errorf("generate password failed: %w", err)
 	}
 
-	user, err := s.user.CreateUser(ctx, login, string(passHash))
+	user, err := s.user.CreateUser(ctx, login, string(passHash), email)
 	if err != nil {
 		return "", fmt.Errorf("create user failed: %w", err)
 	}
 
-	return s.token.CreateToken(ctx, user)
+	if email != "" {
+		// письмо можно запросить повторно, регистрацию из-за него не откатываем
+		if err = s.sendVerification(ctx, user.UUID, email); err != nil {
+			slog.Error("failed to send verification email", slog.String("err", err.Error()))
+		}
+	}
+
+	return s.sessions.Start(ctx, user, client)
 }
@@ -0,0 +1,114 @@
+package author
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/musicman-backend/internal/domain"
+	"github.com/musicman-backend/internal/domain/entity"
+)
+
+type UserRepository interface {
+	GetUserByLogin(ctx context.Context, login string) (entity.User, error)
+	GetUserByUUID(ctx context.Context, userUUID uuid.UUID) (entity.User, error)
+}
+
+type SampleRepository interface {
+	GetByAuthor(ctx context.Context, authorUUID uuid.UUID, drafts bool, page entity.Page) ([]entity.Sample, int, error)
+}
+
+type PackRepository interface {
+	GetByAuthor(ctx context.Context, authorUUID uuid.UUID, page entity.Page) ([]entity.Pack, int, error)
+}
+
+type StatsRepository interface {
+	Stats(ctx context.Context, authorUUID uuid.UUID) (entity.AuthorStats, error)
+}
+
+type Service struct {
+	users   UserRepository
+	samples SampleRepository
+	packs   PackRepository
+	stats   StatsRepository
+}
+
+func New(users UserRepository, samples SampleRepository, packs PackRepository, stats StatsRepository) *Service {
+	return &Service{
+		users:   users,
+		samples: samples,
+		packs:   packs,
+		stats:   stats,
+	}
+}
+
+// GetProfile возвращает публичный профиль автора. Удаленные аккаунты не показываются
+func (s *Service) GetProfile(ctx context.Context, login string) (entity.AuthorProfile, error) {
+	user, err := s.getAuthor(ctx, login)
+	if err != nil {
+		return entity.AuthorProfile{}, err
+	}
+
+	return s.profile(ctx, user)
+}
+
+// GetSamples - страница опубликованных семплов автора
+func (s *Service) GetSamples(ctx context.Context, login string, page entity.Page) ([]entity.Sample, int, error) {
+	user, err := s.getAuthor(ctx, login)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return s.samples.GetByAuthor(ctx, user.UUID, false, page)
+}
+
+func (s *Service) GetPacks(ctx context.Context, login string, page entity.Page) ([]entity.Pack, int, error) {
+	user, err := s.getAuthor(ctx, login)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return s.packs.GetByAuthor(ctx, user.UUID, page)
+}
+
+// GetDashboard - профиль текущего автора вместе со счетчиками черновиков и семплов на модерации
+func (s *Service) GetDashboard(ctx context.Context, userUUID uuid.UUID) (entity.AuthorProfile, error) {
+	user, err := s.users.GetUserByUUID(ctx, userUUID)
+	if err != nil {
+		return entity.AuthorProfile{}, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return s.profile(ctx, user)
+}
+
+// GetOwnSamples - страница семплов текущего автора вместе с черновиками
+func (s *Service) GetOwnSamples(ctx context.Context, userUUID uuid.UUID, page entity.Page) ([]entity.Sample, int, error) {
+	return s.samples.GetByAuthor(ctx, userUUID, true, page)
+}
+
+func (s *Service) GetOwnPacks(ctx context.Context, userUUID uuid.UUID, page entity.Page) ([]entity.Pack, int, error) {
+	return s.packs.GetByAuthor(ctx, userUUID, page)
+}
+
+func (s *Service) getAuthor(ctx context.Context, login string) (entity.User, error) {
+	user, err := s.users.GetUserByLogin(ctx, login)
+	if errors.Is(err, domain.ErrNotFound) || user.DeletedAt != nil {
+		return entity.User{}, domain.ErrNotFound
+	}
+	if err != nil {
+		return entity.User{}, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return user, nil
+}
+
+func (s *Service) profile(ctx context.Context, user entity.User) (entity.AuthorProfile, error) {
+	stats, err := s.stats.Stats(ctx, user.UUID)
+	if err != nil {
+		return entity.AuthorProfile{}, err
+	}
+
+	return entity.AuthorProfile{User: user, Stats: stats}, nil
+}
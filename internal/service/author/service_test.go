@@ -0,0 +1,106 @@
+package author
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/musicman-backend/internal/domain"
+	"github.com/musicman-backend/internal/domain/entity"
+)
+
+type fakeUsers map[string]entity.User
+
+func (f fakeUsers) GetUserByLogin(_ context.Context, login string) (entity.User, error) {
+	user, ok := f[login]
+	if !ok {
+		return user, domain.ErrNotFound
+	}
+	return user, nil
+}
+
+func (f fakeUsers) GetUserByUUID(_ context.Context, userUUID uuid.UUID) (entity.User, error) {
+	for _, user := range f {
+		if user.UUID == userUUID {
+			return user, nil
+		}
+	}
+	return entity.User{}, domain.ErrNotFound
+}
+
+// fakeSamples - семплы автора, черновики отдаются только при drafts
+type fakeSamples struct {
+	published []entity.Sample
+	drafts    []entity.Sample
+	page      entity.Page
+}
+
+func (f *fakeSamples) GetByAuthor(_ context.Context, _ uuid.UUID, drafts bool, page entity.Page) ([]entity.Sample, int, error) {
+	f.page = page
+	samples := f.published
+	if drafts {
+		samples = append(samples, f.drafts...)
+	}
+	return samples, len(samples), nil
+}
+
+type fakePacks struct{}
+
+func (fakePacks) GetByAuthor(context.Context, uuid.UUID, entity.Page) ([]entity.Pack, int, error) {
+	return nil, 0, nil
+}
+
+type fakeStats struct{}
+
+func (fakeStats) Stats(context.Context, uuid.UUID) (entity.AuthorStats, error) {
+	return entity.AuthorStats{}, nil
+}
+
+func TestStorefrontHidesDrafts(t *testing.T) {
+	author := entity.User{UUID: uuid.New(), Login: "producer"}
+	samples := &fakeSamples{
+		published: []entity.Sample{{ID: uuid.New()}},
+		drafts:    []entity.Sample{{ID: uuid.New()}},
+	}
+	s := New(fakeUsers{author.Login: author}, samples, fakePacks{}, fakeStats{})
+	ctx := context.Background()
+	page := entity.Page{Limit: 20, Offset: 40}
+
+	public, total, err := s.GetSamples(ctx, author.Login, page)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(public) != 1 || total != 1 || samples.page != page {
+		t.Fatalf("storefront: %d samples of %d with page %+v, want only the published one on page %+v", len(public), total, samples.page, page)
+	}
+
+	own, total, err := s.GetOwnSamples(ctx, author.UUID, page)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(own) != 2 || total != 2 {
+		t.Fatalf("dashboard: %d samples of %d, want drafts included", len(own), total)
+	}
+}
+
+func TestDeletedAuthorIsHidden(t *testing.T) {
+	deletedAt := time.Now()
+	users := fakeUsers{"deleted": {UUID: uuid.New(), Login: "deleted", DeletedAt: &deletedAt}}
+	s := New(users, &fakeSamples{}, fakePacks{}, fakeStats{})
+	ctx := context.Background()
+
+	for _, login := range []string{"deleted", "unknown"} {
+		if _, err := s.GetProfile(ctx, login); !errors.Is(err, domain.ErrNotFound) {
+			t.Fatalf("profile of %s: got %v, want ErrNotFound", login, err)
+		}
+		if _, _, err := s.GetSamples(ctx, login, entity.Page{Limit: 10}); !errors.Is(err, domain.ErrNotFound) {
+			t.Fatalf("samples of %s: got %v, want ErrNotFound", login, err)
+		}
+		if _, _, err := s.GetPacks(ctx, login, entity.Page{Limit: 10}); !errors.Is(err, domain.ErrNotFound) {
+			t.Fatalf("packs of %s: got %v, want ErrNotFound", login, err)
+		}
+	}
+}
@@ -0,0 +1,271 @@
+package collection
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"github.com/musicman-backend/internal/domain"
+	"github.com/musicman-backend/internal/domain/entity"
+)
+
+const (
+	maxNameLength        = 100
+	maxDescriptionLength = 1000
+)
+
+type Repository interface {
+	Create(ctx context.Context, collection entity.Collection) (uuid.UUID, error)
+	GetByID(ctx context.Context, id uuid.UUID) (entity.Collection, error)
+	GetByUser(ctx context.Context, userUUID uuid.UUID, onlyPublic bool) ([]entity.Collection, error)
+	Update(ctx context.Context, collection entity.Collection) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	AddSample(ctx context.Context, collectionID, sampleID uuid.UUID, addedAt time.Time) error
+	RemoveSample(ctx context.Context, collectionID, sampleID uuid.UUID, removedAt time.Time) error
+	SampleIDs(ctx context.Context, collectionID uuid.UUID) ([]uuid.UUID, error)
+	Reorder(ctx context.Context, collectionID uuid.UUID, sampleIDs []uuid.UUID, updatedAt time.Time) error
+}
+
+type SampleRepository interface {
+	GetByID(ctx context.Context, id uuid.UUID) (entity.Sample, error)
+	GetByCollection(ctx context.Context, collectionID uuid.UUID) ([]entity.Sample, error)
+}
+
+type UserRepository interface {
+	GetUserByLogin(ctx context.Context, login string) (entity.User, error)
+}
+
+type Service struct {
+	repo    Repository
+	samples SampleRepository
+	users   UserRepository
+}
+
+func New(repo Repository, samples SampleRepository, users UserRepository) *Service {
+	return &Service{
+		repo:    repo,
+		samples: samples,
+		users:   users,
+	}
+}
+
+func (s *Service) Create(ctx context.Context, userUUID uuid.UUID, name, description string, isPublic bool) (entity.Collection, error) {
+	name, description, err := normalize(name, description)
+	if err != nil {
+		return entity.Collection{}, err
+	}
+
+	now := time.Now()
+	collection := entity.Collection{
+		UserUUID:    userUUID,
+		Name:        name,
+		Description: description,
+		IsPublic:    isPublic,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	collection.ID, err = s.repo.Create(ctx, collection)
+	if err != nil {
+		return entity.Collection{}, fmt.Errorf("failed to create collection: %w", err)
+	}
+
+	return collection, nil
+}
+
+// GetOwn - все подборки пользователя, включая приватные
+func (s *Service) GetOwn(ctx context.Context, userUUID uuid.UUID) ([]entity.Collection, error) {
+	collections, err := s.repo.GetByUser(ctx, userUUID, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get collections: %w", err)
+	}
+
+	return collections, nil
+}
+
+// GetPublic - публичные подборки пользователя по логину
+func (s *Service) GetPublic(ctx context.Context, login string) ([]entity.Collection, error) {
+	user, err := s.users.GetUserByLogin(ctx, login)
+	if errors.Is(err, domain.ErrNotFound) || user.DeletedAt != nil {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	collections, err := s.repo.GetByUser(ctx, user.UUID, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get collections: %w", err)
+	}
+
+	return collections, nil
+}
+
+// Get возвращает подборку с семплами. Чужая приватная подборка не находится
+func (s *Service) Get(ctx context.Context, userUUID, id uuid.UUID) (entity.Collection, []entity.Sample, error) {
+	collection, err := s.repo.GetByID(ctx, id)
+	if errors.Is(err, domain.ErrNotFound) {
+		return collection, nil, err
+	}
+	if err != nil {
+		return collection, nil, fmt.Errorf("failed to get collection: %w", err)
+	}
+	if !collection.IsPublic && collection.UserUUID != userUUID {
+		return entity.Collection{}, nil, domain.ErrNotFound
+	}
+
+	samples, err := s.samples.GetByCollection(ctx, id)
+	if err != nil {
+		return collection, nil, fmt.Errorf("failed to get collection samples: %w", err)
+	}
+
+	return collection, samples, nil
+}
+
+func (s *Service) Update(ctx context.Context, userUUID, id uuid.UUID, name, description *string, isPublic *bool) (entity.Collection, error) {
+	collection, err := s.getOwn(ctx, userUUID, id)
+	if err != nil {
+		return collection, err
+	}
+
+	if name != nil {
+		collection.Name = *name
+	}
+	if description != nil {
+		collection.Description = *description
+	}
+	if isPublic != nil {
+		collection.IsPublic = *isPublic
+	}
+
+	collection.Name, collection.Description, err = normalize(collection.Name, collection.Description)
+	if err != nil {
+		return collection, err
+	}
+	collection.UpdatedAt = time.Now()
+
+	if err = s.repo.Update(ctx, collection); err != nil {
+		return collection, fmt.Errorf("failed to update collection: %w", err)
+	}
+
+	return collection, nil
+}
+
+func (s *Service) Delete(ctx context.Context, userUUID, id uuid.UUID) error {
+	if _, err := s.getOwn(ctx, userUUID, id); err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete collection: %w", err)
+	}
+
+	return nil
+}
+
+// AddSample добавляет семпл в конец подборки. Добавить можно только опубликованный семпл
+func (s *Service) AddSample(ctx context.Context, userUUID, id, sampleID uuid.UUID) error {
+	if _, err := s.getOwn(ctx, userUUID, id); err != nil {
+		return err
+	}
+
+	sample, err := s.samples.GetByID(ctx, sampleID)
+	if errors.Is(err, domain.ErrNotFound) || !sample.Public(time.Now()) {
+		return fmt.Errorf("%w: sample not found", domain.ErrInvalidCollection)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get sample: %w", err)
+	}
+
+	if err = s.repo.AddSample(ctx, id, sampleID, time.Now()); err != nil {
+		return fmt.Errorf("failed to add sample: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Service) RemoveSample(ctx context.Context, userUUID, id, sampleID uuid.UUID) error {
+	if _, err := s.getOwn(ctx, userUUID, id); err != nil {
+		return err
+	}
+
+	err := s.repo.RemoveSample(ctx, id, sampleID, time.Now())
+	if errors.Is(err, domain.ErrNotFound) {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("failed to remove sample: %w", err)
+	}
+
+	return nil
+}
+
+// Reorder задает новый порядок семплов. sampleIDs должен содержать ровно те семплы, что уже есть в подборке
+func (s *Service) Reorder(ctx context.Context, userUUID, id uuid.UUID, sampleIDs []uuid.UUID) error {
+	if _, err := s.getOwn(ctx, userUUID, id); err != nil {
+		return err
+	}
+
+	current, err := s.repo.SampleIDs(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get collection samples: %w", err)
+	}
+
+	if len(sampleIDs) != len(current) {
+		return fmt.Errorf("%w: order must list all %d samples of the collection", domain.ErrInvalidCollection, len(current))
+	}
+	inCollection := make(map[uuid.UUID]bool, len(current))
+	for _, sampleID := range current {
+		inCollection[sampleID] = true
+	}
+	for _, sampleID := range sampleIDs {
+		if !inCollection[sampleID] {
+			return fmt.Errorf("%w: sample %s is not in the collection or listed twice", domain.ErrInvalidCollection, sampleID)
+		}
+		delete(inCollection, sampleID)
+	}
+
+	if err = s.repo.Reorder(ctx, id, sampleIDs, time.Now()); err != nil {
+		return fmt.Errorf("failed to reorder collection: %w", err)
+	}
+
+	return nil
+}
+
+// getOwn - подборка, которую может менять пользователь. Чужие подборки не находятся
+func (s *Service) getOwn(ctx context.Context, userUUID, id uuid.UUID) (entity.Collection, error) {
+	collection, err := s.repo.GetByID(ctx, id)
+	if errors.Is(err, domain.ErrNotFound) {
+		return collection, err
+	}
+	if err != nil {
+		return collection, fmt.Errorf("failed to get collection: %w", err)
+	}
+	if collection.UserUUID != userUUID {
+		return entity.Collection{}, domain.ErrNotFound
+	}
+
+	return collection, nil
+}
+
+func normalize(name, description string) (string, string, error) {
+	name = strings.TrimSpace(name)
+	description = strings.TrimSpace(description)
+
+	if name == "" {
+		return name, description, fmt.Errorf("%w: name is required", domain.ErrInvalidCollection)
+	}
+	if utf8.RuneCountInString(name) > maxNameLength {
+		return name, description, fmt.Errorf("%w: name must be at most %d characters long", domain.ErrInvalidCollection, maxNameLength)
+	}
+	if utf8.RuneCountInString(description) > maxDescriptionLength {
+		return name, description, fmt.Errorf("%w: description must be at most %d characters long", domain.ErrInvalidCollection, maxDescriptionLength)
+	}
+
+	return name, description, nil
+}
@@ -0,0 +1,164 @@
+package collection
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/musicman-backend/internal/domain"
+	"github.com/musicman-backend/internal/domain/constant"
+	"github.com/musicman-backend/internal/domain/entity"
+)
+
+type fakeRepo struct {
+	Repository
+	collections map[uuid.UUID]entity.Collection
+	samples     map[uuid.UUID][]uuid.UUID
+}
+
+func (f *fakeRepo) Create(_ context.Context, collection entity.Collection) (uuid.UUID, error) {
+	collection.ID = uuid.New()
+	f.collections[collection.ID] = collection
+	return collection.ID, nil
+}
+
+func (f *fakeRepo) GetByID(_ context.Context, id uuid.UUID) (entity.Collection, error) {
+	collection, ok := f.collections[id]
+	if !ok {
+		return collection, domain.ErrNotFound
+	}
+	return collection, nil
+}
+
+func (f *fakeRepo) Update(_ context.Context, collection entity.Collection) error {
+	f.collections[collection.ID] = collection
+	return nil
+}
+
+func (f *fakeRepo) AddSample(_ context.Context, collectionID, sampleID uuid.UUID, _ time.Time) error {
+	if !slices.Contains(f.samples[collectionID], sampleID) {
+		f.samples[collectionID] = append(f.samples[collectionID], sampleID)
+	}
+	return nil
+}
+
+func (f *fakeRepo) SampleIDs(_ context.Context, collectionID uuid.UUID) ([]uuid.UUID, error) {
+	return f.samples[collectionID], nil
+}
+
+func (f *fakeRepo) Reorder(_ context.Context, collectionID uuid.UUID, sampleIDs []uuid.UUID, _ time.Time) error {
+	f.samples[collectionID] = sampleIDs
+	return nil
+}
+
+type fakeSamples map[uuid.UUID]entity.Sample
+
+func (f fakeSamples) GetByID(_ context.Context, id uuid.UUID) (entity.Sample, error) {
+	sample, ok := f[id]
+	if !ok {
+		return sample, domain.ErrNotFound
+	}
+	return sample, nil
+}
+
+func (f fakeSamples) GetByCollection(context.Context, uuid.UUID) ([]entity.Sample, error) {
+	return nil, nil
+}
+
+func newTestService() (*Service, *fakeRepo, fakeSamples) {
+	repo := &fakeRepo{collections: map[uuid.UUID]entity.Collection{}, samples: map[uuid.UUID][]uuid.UUID{}}
+	samples := fakeSamples{}
+	return New(repo, samples, nil), repo, samples
+}
+
+func TestPrivateCollectionIsHidden(t *testing.T) {
+	s, _, _ := newTestService()
+	ctx := context.Background()
+	owner, other := uuid.New(), uuid.New()
+
+	collection, err := s.Create(ctx, owner, "  Dark kicks ", "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if collection.Name != "Dark kicks" {
+		t.Fatalf("name %q, want it trimmed", collection.Name)
+	}
+
+	if _, _, err = s.Get(ctx, owner, collection.ID); err != nil {
+		t.Fatalf("owner get: %v", err)
+	}
+	if _, _, err = s.Get(ctx, other, collection.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("private collection of another user: got %v, want ErrNotFound", err)
+	}
+	if _, err = s.Update(ctx, other, collection.ID, nil, nil, nil); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("update by another user: got %v, want ErrNotFound", err)
+	}
+
+	public := true
+	if _, err = s.Update(ctx, owner, collection.ID, nil, nil, &public); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err = s.Get(ctx, other, collection.ID); err != nil {
+		t.Fatalf("public collection of another user: %v", err)
+	}
+
+	blank := " "
+	if _, err = s.Update(ctx, owner, collection.ID, &blank, nil, nil); !errors.Is(err, domain.ErrInvalidCollection) {
+		t.Fatalf("blank name: got %v, want ErrInvalidCollection", err)
+	}
+	if _, err = s.Create(ctx, owner, strings.Repeat("я", maxNameLength+1), "", false); !errors.Is(err, domain.ErrInvalidCollection) {
+		t.Fatalf("long name: got %v, want ErrInvalidCollection", err)
+	}
+}
+
+func TestCollectionSamples(t *testing.T) {
+	s, repo, samples := newTestService()
+	ctx := context.Background()
+	owner := uuid.New()
+
+	collection, err := s.Create(ctx, owner, "Kit", "", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := entity.Sample{ID: uuid.New(), Status: constant.SampleStatusPublished}
+	b := entity.Sample{ID: uuid.New(), Status: constant.SampleStatusPublished}
+	draft := entity.Sample{ID: uuid.New(), Status: constant.SampleStatusDraft}
+	for _, sample := range []entity.Sample{a, b, draft} {
+		samples[sample.ID] = sample
+	}
+
+	for _, id := range []uuid.UUID{a.ID, b.ID} {
+		if err = s.AddSample(ctx, owner, collection.ID, id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = s.AddSample(ctx, owner, collection.ID, draft.ID); !errors.Is(err, domain.ErrInvalidCollection) {
+		t.Fatalf("unpublished sample: got %v, want ErrInvalidCollection", err)
+	}
+	if err = s.AddSample(ctx, uuid.New(), collection.ID, a.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("add to another user's collection: got %v, want ErrNotFound", err)
+	}
+
+	invalid := map[string][]uuid.UUID{
+		"missing sample": {b.ID},
+		"duplicate":      {b.ID, b.ID},
+		"foreign sample": {b.ID, draft.ID},
+	}
+	for name, order := range invalid {
+		if err = s.Reorder(ctx, owner, collection.ID, order); !errors.Is(err, domain.ErrInvalidCollection) {
+			t.Fatalf("%s: got %v, want ErrInvalidCollection", name, err)
+		}
+	}
+
+	if err = s.Reorder(ctx, owner, collection.ID, []uuid.UUID{b.ID, a.ID}); err != nil {
+		t.Fatal(err)
+	}
+	if want := []uuid.UUID{b.ID, a.ID}; !slices.Equal(repo.samples[collection.ID], want) {
+		t.Fatalf("order %v, want %v", repo.samples[collection.ID], want)
+	}
+}
@@ -0,0 +1,26 @@
+package earnings
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/google/uuid"
+
+	"github.com/musicman-backend/internal/domain/entity"
+)
+
+// StubExecutor ничего не переводит, а только пишет выплату в лог.
+// Используется, пока не подключен платежный провайдер для выплат
+type StubExecutor struct{}
+
+func (StubExecutor) Execute(_ context.Context, payout entity.Payout) (string, error) {
+	externalID := "stub-" + uuid.NewString()
+	slog.Info("payout executed by stub",
+		slog.String("payout", payout.ID.String()),
+		slog.String("author", payout.AuthorUUID.String()),
+		slog.Int("amount", payout.Amount),
+		slog.String("external_id", externalID),
+	)
+
+	return externalID, nil
+}
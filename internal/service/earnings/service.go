@@ -0,0 +1,189 @@
+package earnings
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/musicman-backend/config"
+	"github.com/musicman-backend/internal/domain"
+	"github.com/musicman-backend/internal/domain/constant"
+	"github.com/musicman-backend/internal/domain/entity"
+)
+
+const maxRejectReasonLen = 500
+
+type UserRepository interface {
+	GetUserByUUID(ctx context.Context, userUUID uuid.UUID) (entity.User, error)
+	SetCommission(ctx context.Context, userUUID uuid.UUID, percent *int) error
+}
+
+type Repository interface {
+	Summary(ctx context.Context, authorUUID uuid.UUID) (entity.EarningsSummary, error)
+	BySample(ctx context.Context, authorUUID uuid.UUID) ([]entity.SampleEarnings, error)
+	CreatePayout(ctx context.Context, payout entity.Payout) (entity.Payout, error)
+	GetPayout(ctx context.Context, id uuid.UUID) (entity.Payout, error)
+	GetPayouts(ctx context.Context, authorUUID *uuid.UUID, status string) ([]entity.Payout, error)
+	Review(ctx context.Context, id, reviewer uuid.UUID, status, reason string, reviewedAt time.Time) (entity.Payout, error)
+	MarkPaid(ctx context.Context, id uuid.UUID, externalID string, paidAt time.Time) (entity.Payout, error)
+	MarkFailed(ctx context.Context, id uuid.UUID, failure string) (entity.Payout, error)
+}
+
+// PayoutExecutor переводит деньги автору, возвращает идентификатор выплаты у провайдера
+type PayoutExecutor interface {
+	Execute(ctx context.Context, payout entity.Payout) (string, error)
+}
+
+type Service struct {
+	users    UserRepository
+	repo     Repository
+	executor PayoutExecutor
+	cfg      config.Revenue
+}
+
+func New(users UserRepository, repo Repository, executor PayoutExecutor, cfg config.Revenue) *Service {
+	return &Service{
+		users:    users,
+		repo:     repo,
+		executor: executor,
+		cfg:      cfg,
+	}
+}
+
+// Split делит цену покупки между автором и площадкой. Комиссия округляется вниз в пользу автора
+func (s *Service) Split(ctx context.Context, authorUUID uuid.UUID, price int) (entity.Earning, error) {
+	author, err := s.users.GetUserByUUID(ctx, authorUUID)
+	if err != nil {
+		return entity.Earning{}, fmt.Errorf("failed to get author: %w", err)
+	}
+
+	percent := s.commission(author)
+	commission := price * percent / 100
+
+	return entity.Earning{
+		AuthorUUID:        authorUUID,
+		Price:             price,
+		CommissionPercent: percent,
+		Commission:        commission,
+		Amount:            price - commission,
+	}, nil
+}
+
+// GetEarnings возвращает баланс автора с разбивкой по семплам
+func (s *Service) GetEarnings(ctx context.Context, userUUID uuid.UUID) (entity.EarningsSummary, error) {
+	user, err := s.users.GetUserByUUID(ctx, userUUID)
+	if err != nil {
+		return entity.EarningsSummary{}, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	summary, err := s.repo.Summary(ctx, userUUID)
+	if err != nil {
+		return summary, err
+	}
+
+	summary.Samples, err = s.repo.BySample(ctx, userUUID)
+	if err != nil {
+		return summary, err
+	}
+	summary.CommissionPercent = s.commission(user)
+
+	return summary, nil
+}
+
+// RequestPayout создает заявку на вывод, ее рассматривает админ
+func (s *Service) RequestPayout(ctx context.Context, userUUID uuid.UUID, amount int) (entity.Payout, error) {
+	if amount < s.cfg.MinPayout || amount <= 0 {
+		return entity.Payout{}, fmt.Errorf("%w: минимальная сумма вывода %d", domain.ErrInvalidPayout, s.cfg.MinPayout)
+	}
+
+	payout, err := s.repo.CreatePayout(ctx, entity.Payout{
+		AuthorUUID: userUUID,
+		Amount:     amount,
+		Status:     constant.PayoutStatusPending,
+		CreatedAt:  time.Now(),
+	})
+	if errors.Is(err, domain.ErrInsufficientEarnings) {
+		return payout, err
+	}
+	if err != nil {
+		return payout, fmt.Errorf("failed to create payout: %w", err)
+	}
+
+	return payout, nil
+}
+
+func (s *Service) GetPayouts(ctx context.Context, userUUID uuid.UUID) ([]entity.Payout, error) {
+	return s.repo.GetPayouts(ctx, &userUUID, "")
+}
+
+// ListPayouts - заявки всех авторов для админа, пустой status - в любом статусе
+func (s *Service) ListPayouts(ctx context.Context, status string) ([]entity.Payout, error) {
+	return s.repo.GetPayouts(ctx, nil, status)
+}
+
+// ApprovePayout одобряет заявку и сразу выполняет выплату. Если выплата не прошла,
+// заявка становится failed, а сумма возвращается на баланс автора
+func (s *Service) ApprovePayout(ctx context.Context, adminUUID, id uuid.UUID) (entity.Payout, error) {
+	payout, err := s.repo.Review(ctx, id, adminUUID, constant.PayoutStatusApproved, "", time.Now())
+	if err != nil {
+		return payout, s.reviewError(ctx, id, err)
+	}
+
+	externalID, execErr := s.executor.Execute(ctx, payout)
+	if execErr != nil {
+		slog.Error("payout failed", slog.String("payout", id.String()), slog.String("err", execErr.Error()))
+		return s.repo.MarkFailed(ctx, id, execErr.Error())
+	}
+
+	return s.repo.MarkPaid(ctx, id, externalID, time.Now())
+}
+
+// RejectPayout отклоняет заявку с причиной, сумма возвращается на баланс автора
+func (s *Service) RejectPayout(ctx context.Context, adminUUID, id uuid.UUID, reason string) (entity.Payout, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" || len([]rune(reason)) > maxRejectReasonLen {
+		return entity.Payout{}, fmt.Errorf("%w: причина обязательна и не длиннее %d символов", domain.ErrInvalidPayout, maxRejectReasonLen)
+	}
+
+	payout, err := s.repo.Review(ctx, id, adminUUID, constant.PayoutStatusRejected, reason, time.Now())
+	if err != nil {
+		return payout, s.reviewError(ctx, id, err)
+	}
+
+	return payout, nil
+}
+
+// SetCommission задает автору комиссию площадки, nil - комиссия по умолчанию
+func (s *Service) SetCommission(ctx context.Context, authorUUID uuid.UUID, percent *int) error {
+	if percent != nil && (*percent < 0 || *percent > 100) {
+		return fmt.Errorf("%w: комиссия должна быть от 0 до 100", domain.ErrInvalidCommission)
+	}
+
+	return s.users.SetCommission(ctx, authorUUID, percent)
+}
+
+func (s *Service) commission(user entity.User) int {
+	if user.CommissionPercent != nil {
+		return *user.CommissionPercent
+	}
+
+	return s.cfg.Commission
+}
+
+// reviewError отличает несуществующую заявку от уже рассмотренной
+func (s *Service) reviewError(ctx context.Context, id uuid.UUID, err error) error {
+	if !errors.Is(err, domain.ErrPayoutProcessed) {
+		return err
+	}
+
+	if _, getErr := s.repo.GetPayout(ctx, id); errors.Is(getErr, domain.ErrNotFound) {
+		return domain.ErrNotFound
+	}
+
+	return err
+}
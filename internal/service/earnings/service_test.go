@@ -0,0 +1,92 @@
+package earnings
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/musicman-backend/config"
+	"github.com/musicman-backend/internal/domain"
+	"github.com/musicman-backend/internal/domain/constant"
+	"github.com/musicman-backend/internal/domain/entity"
+)
+
+type fakeUsers struct {
+	UserRepository
+	users map[uuid.UUID]entity.User
+}
+
+func (f fakeUsers) GetUserByUUID(_ context.Context, userUUID uuid.UUID) (entity.User, error) {
+	user, ok := f.users[userUUID]
+	if !ok {
+		return user, domain.ErrNotFound
+	}
+	return user, nil
+}
+
+type fakeRepo struct {
+	Repository
+	payouts []entity.Payout
+}
+
+func (f *fakeRepo) CreatePayout(_ context.Context, payout entity.Payout) (entity.Payout, error) {
+	payout.ID = uuid.New()
+	f.payouts = append(f.payouts, payout)
+	return payout, nil
+}
+
+func TestSplitRoundsCommissionDown(t *testing.T) {
+	custom := 0
+	standard, exempt := uuid.New(), uuid.New()
+	s := New(fakeUsers{users: map[uuid.UUID]entity.User{
+		standard: {UUID: standard},
+		exempt:   {UUID: exempt, CommissionPercent: &custom},
+	}}, &fakeRepo{}, nil, config.Revenue{Commission: 15})
+
+	cases := map[string]struct {
+		author     uuid.UUID
+		price      int
+		commission int
+	}{
+		"exact":           {standard, 100, 15},
+		"fraction":        {standard, 99, 14}, // 14.85 в пользу автора
+		"below one token": {standard, 6, 0},   // 0.9
+		"author override": {exempt, 99, 0},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			earning, err := s.Split(context.Background(), tc.author, tc.price)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if earning.Commission != tc.commission || earning.Amount != tc.price-tc.commission {
+				t.Fatalf("commission %d, amount %d, want %d and %d", earning.Commission, earning.Amount, tc.commission, tc.price-tc.commission)
+			}
+		})
+	}
+}
+
+func TestRequestPayoutMinimum(t *testing.T) {
+	repo := &fakeRepo{}
+	s := New(fakeUsers{}, repo, nil, config.Revenue{MinPayout: 500})
+	author := uuid.New()
+
+	for _, amount := range []int{499, 0, -500} {
+		if _, err := s.RequestPayout(context.Background(), author, amount); !errors.Is(err, domain.ErrInvalidPayout) {
+			t.Fatalf("amount %d: got %v, want ErrInvalidPayout", amount, err)
+		}
+	}
+	if len(repo.payouts) != 0 {
+		t.Fatalf("%d payouts created below the minimum", len(repo.payouts))
+	}
+
+	payout, err := s.RequestPayout(context.Background(), author, 500)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if payout.Status != constant.PayoutStatusPending || payout.AuthorUUID != author {
+		t.Fatalf("payout %+v, want a pending request of the author", payout)
+	}
+}
@@ -0,0 +1,134 @@
+package facets
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/musicman-backend/internal/domain/constant"
+	"github.com/musicman-backend/internal/domain/entity"
+	"github.com/musicman-backend/pkg/slug"
+)
+
+const (
+	defaultTTL = 30 * time.Second
+	// maxEntries ограничивает кэш: сочетаний фильтров много, а живут они недолго
+	maxEntries = 1024
+	// maxValues - сколько самых частых авторов и паков отдавать
+	maxValues = 50
+)
+
+type Repository interface {
+	Facets(ctx context.Context, filter entity.SampleFilter) (entity.Facets, error)
+}
+
+type cached struct {
+	facets    entity.Facets
+	expiresAt time.Time
+}
+
+// Service считает фасеты каталога и держит их в памяти ttl, чтобы боковая панель фильтров
+// не пересчитывала каталог на каждый клик
+type Service struct {
+	repo Repository
+	ttl  time.Duration
+
+	mu    sync.Mutex
+	cache map[string]cached
+}
+
+func New(repo Repository, ttl time.Duration) *Service {
+	if ttl <= 0 {
+		ttl = defaultTTL
+	}
+
+	return &Service{
+		repo:  repo,
+		ttl:   ttl,
+		cache: make(map[string]cached),
+	}
+}
+
+// Facets - счетчики семплов по жанрам, авторам, пакам, цене и длительности при фильтре filter.
+// Корзины цены и длительности возвращаются все, в том числе пустые
+func (s *Service) Facets(ctx context.Context, filter entity.SampleFilter) (entity.Facets, error) {
+	filter.Sort = ""
+	filter.Genre = slug.Make(filter.Genre)
+	filter.Tag = slug.Make(filter.Tag)
+	filter.Instrument = slug.Make(filter.Instrument)
+	filter.Mood = slug.Make(filter.Mood)
+
+	key := cacheKey(filter)
+	now := time.Now()
+
+	s.mu.Lock()
+	entry, ok := s.cache[key]
+	s.mu.Unlock()
+	if ok && now.Before(entry.expiresAt) {
+		return entry.facets, nil
+	}
+
+	facets, err := s.repo.Facets(ctx, filter)
+	if err != nil {
+		return facets, fmt.Errorf("failed to count facets: %w", err)
+	}
+
+	facets.Authors = facets.Authors[:min(len(facets.Authors), maxValues)]
+	facets.Packs = facets.Packs[:min(len(facets.Packs), maxValues)]
+	facets.Prices = buckets(facets.Prices, constant.PriceBuckets)
+	facets.Durations = buckets(facets.Durations, constant.DurationBuckets)
+
+	s.store(key, facets, now)
+
+	return facets, nil
+}
+
+func (s *Service) store(key string, facets entity.Facets, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.cache) >= maxEntries {
+		for k, entry := range s.cache {
+			if !now.Before(entry.expiresAt) {
+				delete(s.cache, k)
+			}
+		}
+	}
+	if len(s.cache) >= maxEntries {
+		clear(s.cache)
+	}
+
+	s.cache[key] = cached{facets: facets, expiresAt: now.Add(s.ttl)}
+}
+
+// buckets раскладывает посчитанные корзины в порядке order, отсутствующие - с нулем
+func buckets(values []entity.FacetValue, order []string) []entity.FacetValue {
+	counts := make(map[string]int, len(values))
+	for _, v := range values {
+		counts[v.Value] = v.Count
+	}
+
+	res := make([]entity.FacetValue, len(order))
+	for i, bucket := range order {
+		res[i] = entity.FacetValue{Value: bucket, Label: bucket, Count: counts[bucket]}
+	}
+
+	return res
+}
+
+func cacheKey(filter entity.SampleFilter) string {
+	return fmt.Sprintf("%s|%s|%s|%s|%s|%s|%s|%s",
+		filter.Genre, filter.Tag, filter.Instrument, filter.Mood,
+		optional(filter.Author), optional(filter.PackID), filter.Price, filter.Duration)
+}
+
+func optional(id *uuid.UUID) string {
+	if id == nil {
+		return ""
+	}
+
+	return id.String()
+}
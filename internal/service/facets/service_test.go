@@ -0,0 +1,126 @@
+package facets
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/musicman-backend/internal/domain/constant"
+	"github.com/musicman-backend/internal/domain/entity"
+)
+
+type fakeRepo struct {
+	calls   int
+	filters []entity.SampleFilter
+	facets  entity.Facets
+}
+
+func (f *fakeRepo) Facets(_ context.Context, filter entity.SampleFilter) (entity.Facets, error) {
+	f.calls++
+	f.filters = append(f.filters, filter)
+	return f.facets, nil
+}
+
+func TestFacetsCachesNormalizedFilter(t *testing.T) {
+	ctx := context.Background()
+	repo := &fakeRepo{}
+	s := New(repo, time.Minute)
+
+	if _, err := s.Facets(ctx, entity.SampleFilter{Genre: "Hip Hop", Sort: constant.SortNew}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Facets(ctx, entity.SampleFilter{Genre: "hip-hop"}); err != nil {
+		t.Fatal(err)
+	}
+	if repo.calls != 1 {
+		t.Fatalf("repository called %d times, want 1: same filter must hit the cache", repo.calls)
+	}
+	if got := repo.filters[0]; got.Genre != "hip-hop" || got.Sort != "" {
+		t.Fatalf("repository got genre %q and sort %q, want normalized slug and no sort", got.Genre, got.Sort)
+	}
+
+	author := uuid.New()
+	if _, err := s.Facets(ctx, entity.SampleFilter{Genre: "hip-hop", Author: &author}); err != nil {
+		t.Fatal(err)
+	}
+	if repo.calls != 2 {
+		t.Fatalf("repository called %d times, want 2: other author is another filter", repo.calls)
+	}
+}
+
+func TestFacetsExpire(t *testing.T) {
+	ctx := context.Background()
+	repo := &fakeRepo{}
+	s := New(repo, time.Minute)
+
+	if _, err := s.Facets(ctx, entity.SampleFilter{}); err != nil {
+		t.Fatal(err)
+	}
+
+	key := cacheKey(entity.SampleFilter{})
+	entry := s.cache[key]
+	entry.expiresAt = time.Now().Add(-time.Second)
+	s.cache[key] = entry
+
+	if _, err := s.Facets(ctx, entity.SampleFilter{}); err != nil {
+		t.Fatal(err)
+	}
+	if repo.calls != 2 {
+		t.Fatalf("repository called %d times, want 2 after expiry", repo.calls)
+	}
+}
+
+func TestFacetsShape(t *testing.T) {
+	authors := make([]entity.FacetValue, maxValues+10)
+	for i := range authors {
+		authors[i] = entity.FacetValue{Value: fmt.Sprint(i), Count: len(authors) - i}
+	}
+
+	repo := &fakeRepo{facets: entity.Facets{
+		Authors: authors,
+		Prices:  []entity.FacetValue{{Value: constant.PriceOver500, Count: 2}, {Value: constant.PriceFree, Count: 5}},
+	}}
+	s := New(repo, 0)
+
+	facets, err := s.Facets(context.Background(), entity.SampleFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(facets.Authors) != maxValues {
+		t.Fatalf("%d authors, want %d", len(facets.Authors), maxValues)
+	}
+
+	want := []entity.FacetValue{
+		{Value: constant.PriceFree, Label: constant.PriceFree, Count: 5},
+		{Value: constant.PriceUnder100, Label: constant.PriceUnder100},
+		{Value: constant.Price100To500, Label: constant.Price100To500},
+		{Value: constant.PriceOver500, Label: constant.PriceOver500, Count: 2},
+	}
+	if fmt.Sprint(facets.Prices) != fmt.Sprint(want) {
+		t.Fatalf("prices %v, want %v", facets.Prices, want)
+	}
+	if len(facets.Durations) != len(constant.DurationBuckets) {
+		t.Fatalf("%d duration buckets, want all %d including empty", len(facets.Durations), len(constant.DurationBuckets))
+	}
+}
+
+func TestStoreEvictsWhenFull(t *testing.T) {
+	s := New(&fakeRepo{}, time.Minute)
+	now := time.Now()
+
+	for i := 0; i < maxEntries; i++ {
+		s.store(fmt.Sprint(i), entity.Facets{}, now)
+	}
+	s.store("extra", entity.Facets{}, now)
+
+	if len(s.cache) > maxEntries {
+		t.Fatalf("cache holds %d entries, limit is %d", len(s.cache), maxEntries)
+	}
+	if _, ok := s.cache["extra"]; !ok {
+		t.Fatal("new entry must be stored")
+	}
+}
@@ -0,0 +1,204 @@
+package gc
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/musicman-backend/internal/domain/constant"
+	"github.com/musicman-backend/internal/domain/entity"
+)
+
+type FileRepository interface {
+	ListFiles(ctx context.Context, bucketName string) ([]entity.FileInfo, error)
+	DeleteFile(ctx context.Context, bucketName, objectName string) error
+}
+
+type KeyRepository interface {
+	GetAllKeys(ctx context.Context) ([]string, error)
+}
+
+// CoverRepository - ключи обложек паков, включая паки в корзине
+type CoverRepository interface {
+	GetCoverKeys(ctx context.Context) ([]string, error)
+}
+
+type AvatarRepository interface {
+	GetAvatarKeys(ctx context.Context) ([]string, error)
+}
+
+type SampleRepository interface {
+	GetWithoutAudio(ctx context.Context) ([]entity.Sample, error)
+}
+
+type Config struct {
+	// Bucket бакет с аудио семплов
+	Bucket string
+	// CoverBucket и AvatarBucket - бакеты картинок, в базе ключ картинки, а в бакете ее миниатюры <ключ>/<размер>.jpg
+	CoverBucket  string
+	AvatarBucket string
+	// MinObjectAge объекты моложе этого возраста не считаются осиротевшими - их могут прямо сейчас загружать
+	MinObjectAge time.Duration
+	// TempDir каталог временных файлов загрузки
+	TempDir string
+	// TempFileMaxAge временные файлы старше этого возраста удаляются
+	TempFileMaxAge time.Duration
+}
+
+type Service struct {
+	files   FileRepository
+	keys    KeyRepository
+	covers  CoverRepository
+	avatars AvatarRepository
+	samples SampleRepository
+
+	cfg Config
+}
+
+func New(files FileRepository, keys KeyRepository, covers CoverRepository, avatars AvatarRepository, samples SampleRepository, cfg Config) *Service {
+	if cfg.TempDir == "" {
+		cfg.TempDir = os.TempDir()
+	}
+
+	return &Service{
+		files:   files,
+		keys:    keys,
+		covers:  covers,
+		avatars: avatars,
+		samples: samples,
+		cfg:     cfg,
+	}
+}
+
+// Run сверяет бакеты аудио, обложек и аватаров с базой и чистит устаревшие временные файлы.
+// Осиротевшие объекты удаляются только при deleteOrphans, иначе только попадают в отчет
+func (s *Service) Run(ctx context.Context, deleteOrphans bool) (entity.GCReport, error) {
+	report := entity.GCReport{StartedAt: time.Now()}
+
+	buckets := []struct {
+		name   string
+		keys   func(ctx context.Context) ([]string, error)
+		images bool
+	}{
+		{s.cfg.Bucket, s.keys.GetAllKeys, false},
+		{s.cfg.CoverBucket, s.covers.GetCoverKeys, true},
+		{s.cfg.AvatarBucket, s.avatars.GetAvatarKeys, true},
+	}
+	for _, bucket := range buckets {
+		keys, err := bucket.keys(ctx)
+		if err != nil {
+			return report, fmt.Errorf("failed to get %s keys: %w", bucket.name, err)
+		}
+
+		if err = s.collectObjects(ctx, &report, bucket.name, keys, bucket.images, deleteOrphans); err != nil {
+			return report, err
+		}
+	}
+
+	samples, err := s.samples.GetWithoutAudio(ctx)
+	if err != nil {
+		return report, fmt.Errorf("failed to get samples without audio: %w", err)
+	}
+	report.SamplesWithoutAudio = make([]uuid.UUID, len(samples))
+	for i, sample := range samples {
+		report.SamplesWithoutAudio[i] = sample.ID
+	}
+
+	if err := s.cleanTempFiles(&report); err != nil {
+		return report, err
+	}
+
+	report.FinishedAt = time.Now()
+
+	return report, nil
+}
+
+// collectObjects сверяет бакет с ключами из базы. У картинок ключ - каталог миниатюр,
+// объект принадлежит картинке своего каталога. Старые аватары лежат одним файлом, их ключ - сам объект
+func (s *Service) collectObjects(ctx context.Context, report *entity.GCReport, bucket string, keys []string, images, deleteOrphans bool) error {
+	objects, err := s.files.ListFiles(ctx, bucket)
+	if err != nil {
+		return fmt.Errorf("failed to list %s objects: %w", bucket, err)
+	}
+	report.ObjectsTotal += len(objects)
+
+	referenced := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		referenced[key] = struct{}{}
+	}
+
+	existing := make(map[string]struct{}, len(objects))
+	for _, object := range objects {
+		owner := object.Key
+		if _, ok := referenced[owner]; !ok && images {
+			owner = path.Dir(object.Key)
+		}
+		existing[owner] = struct{}{}
+
+		if _, ok := referenced[owner]; ok {
+			continue
+		}
+		if time.Since(object.LastModified) < s.cfg.MinObjectAge {
+			continue
+		}
+
+		report.OrphanedObjects = append(report.OrphanedObjects, bucket+"/"+object.Key)
+		report.OrphanedBytes += object.Size
+
+		if !deleteOrphans {
+			continue
+		}
+
+		if err := s.files.DeleteFile(ctx, bucket, object.Key); err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("delete object %s/%s: %s", bucket, object.Key, err))
+			continue
+		}
+		report.DeletedObjects++
+	}
+
+	for _, key := range keys {
+		if _, ok := existing[key]; !ok {
+			report.MissingObjects = append(report.MissingObjects, bucket+"/"+key)
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) cleanTempFiles(report *entity.GCReport) error {
+	entries, err := os.ReadDir(s.cfg.TempDir)
+	if err != nil {
+		return fmt.Errorf("failed to read temp dir: %w", err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasPrefix(entry.Name(), constant.TempFilePrefix) {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			// файл могли удалить, пока мы читали каталог
+			continue
+		}
+		if time.Since(info.ModTime()) < s.cfg.TempFileMaxAge {
+			continue
+		}
+
+		path := filepath.Join(s.cfg.TempDir, entry.Name())
+		report.StaleTempFiles = append(report.StaleTempFiles, path)
+
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			report.Errors = append(report.Errors, fmt.Sprintf("remove temp file %s: %s", path, err))
+			continue
+		}
+		report.DeletedTempFiles++
+	}
+
+	return nil
+}
@@ -0,0 +1,109 @@
+package gc
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/musicman-backend/internal/domain/entity"
+)
+
+const (
+	testSamples = "samples"
+	testCovers  = "covers"
+	testAvatars = "avatars"
+)
+
+// fakeFiles - объекты по бакетам, удаленные собираются в deleted как <бакет>/<ключ>
+type fakeFiles struct {
+	buckets map[string][]entity.FileInfo
+	deleted []string
+}
+
+func (f *fakeFiles) ListFiles(_ context.Context, bucketName string) ([]entity.FileInfo, error) {
+	return f.buckets[bucketName], nil
+}
+
+func (f *fakeFiles) DeleteFile(_ context.Context, bucketName, objectName string) error {
+	f.deleted = append(f.deleted, bucketName+"/"+objectName)
+	return nil
+}
+
+type fakeKeys []string
+
+func (f fakeKeys) GetAllKeys(context.Context) ([]string, error)    { return f, nil }
+func (f fakeKeys) GetCoverKeys(context.Context) ([]string, error)  { return f, nil }
+func (f fakeKeys) GetAvatarKeys(context.Context) ([]string, error) { return f, nil }
+
+type fakeSamples struct{}
+
+func (fakeSamples) GetWithoutAudio(context.Context) ([]entity.Sample, error) {
+	return nil, nil
+}
+
+func objects(modified time.Time, keys ...string) []entity.FileInfo {
+	files := make([]entity.FileInfo, len(keys))
+	for i, key := range keys {
+		files[i] = entity.FileInfo{Key: key, Size: 10, LastModified: modified}
+	}
+	return files
+}
+
+func TestRunReconcilesImageBuckets(t *testing.T) {
+	old := time.Now().Add(-48 * time.Hour)
+	files := &fakeFiles{buckets: map[string][]entity.FileInfo{
+		testSamples: objects(old, "samples/a/1/hash.wav", "samples/b/1/hash.wav"),
+		testCovers: append(objects(old, "owner/cover/small.jpg", "owner/cover/large.jpg", "owner/replaced/small.jpg"),
+			objects(time.Now(), "owner/uploading/small.jpg")...),
+		testAvatars: objects(old, "user/avatar/small.jpg", "legacy.png", "user/old/small.jpg"),
+	}}
+
+	s := New(files,
+		fakeKeys{"samples/a/1/hash.wav"},
+		fakeKeys{"owner/cover", "owner/missing"},
+		fakeKeys{"user/avatar", "legacy.png"},
+		fakeSamples{},
+		Config{
+			Bucket:       testSamples,
+			CoverBucket:  testCovers,
+			AvatarBucket: testAvatars,
+			MinObjectAge: time.Hour,
+			TempDir:      t.TempDir(),
+		})
+
+	report, err := s.Run(context.Background(), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	orphans := []string{"samples/samples/b/1/hash.wav", "covers/owner/replaced/small.jpg", "avatars/user/old/small.jpg"}
+	if !slices.Equal(report.OrphanedObjects, orphans) {
+		t.Fatalf("orphaned %v, want %v", report.OrphanedObjects, orphans)
+	}
+	if !slices.Equal(files.deleted, orphans) || report.DeletedObjects != len(orphans) {
+		t.Fatalf("deleted %v, want %v", files.deleted, orphans)
+	}
+	if !slices.Equal(report.MissingObjects, []string{"covers/owner/missing"}) {
+		t.Fatalf("missing %v, want only the cover without objects", report.MissingObjects)
+	}
+	if report.ObjectsTotal != 9 {
+		t.Fatalf("objects total %d, want 9", report.ObjectsTotal)
+	}
+}
+
+func TestRunKeepsOrphansWithoutDelete(t *testing.T) {
+	files := &fakeFiles{buckets: map[string][]entity.FileInfo{
+		testCovers: objects(time.Now().Add(-48*time.Hour), "owner/replaced/small.jpg"),
+	}}
+	s := New(files, fakeKeys{}, fakeKeys{}, fakeKeys{}, fakeSamples{},
+		Config{Bucket: testSamples, CoverBucket: testCovers, AvatarBucket: testAvatars, TempDir: t.TempDir()})
+
+	report, err := s.Run(context.Background(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.OrphanedObjects) != 1 || len(files.deleted) != 0 {
+		t.Fatalf("orphaned %v, deleted %v: without deleteOrphans objects only go to the report", report.OrphanedObjects, files.deleted)
+	}
+}
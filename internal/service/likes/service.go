@@ -0,0 +1,129 @@
+package likes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/musicman-backend/internal/domain"
+	"github.com/musicman-backend/internal/domain/entity"
+)
+
+type Repository interface {
+	LikeSample(ctx context.Context, userUUID, sampleID uuid.UUID) error
+	UnlikeSample(ctx context.Context, userUUID, sampleID uuid.UUID) error
+	LikePack(ctx context.Context, userUUID, packID uuid.UUID) error
+	UnlikePack(ctx context.Context, userUUID, packID uuid.UUID) error
+	LikedSamples(ctx context.Context, userUUID uuid.UUID, sampleIDs []uuid.UUID) (map[uuid.UUID]bool, error)
+}
+
+type SampleRepository interface {
+	GetByID(ctx context.Context, id uuid.UUID) (entity.Sample, error)
+	GetLiked(ctx context.Context, userUUID uuid.UUID) ([]entity.Sample, error)
+}
+
+type PackRepository interface {
+	GetByID(ctx context.Context, id uuid.UUID) (entity.Pack, error)
+	GetLiked(ctx context.Context, userUUID uuid.UUID) ([]entity.Pack, error)
+}
+
+type Service struct {
+	repo    Repository
+	samples SampleRepository
+	packs   PackRepository
+}
+
+func New(repo Repository, samples SampleRepository, packs PackRepository) *Service {
+	return &Service{
+		repo:    repo,
+		samples: samples,
+		packs:   packs,
+	}
+}
+
+// LikeSample ставит лайк опубликованному семплу. Повторный лайк не ошибка
+func (s *Service) LikeSample(ctx context.Context, userUUID, sampleID uuid.UUID) error {
+	sample, err := s.samples.GetByID(ctx, sampleID)
+	if errors.Is(err, domain.ErrNotFound) || !sample.Public(time.Now()) {
+		return domain.ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get sample: %w", err)
+	}
+
+	if err = s.repo.LikeSample(ctx, userUUID, sampleID); err != nil {
+		return fmt.Errorf("failed to like sample: %w", err)
+	}
+
+	return nil
+}
+
+// UnlikeSample снимает лайк, в том числе с удаленного семпла
+func (s *Service) UnlikeSample(ctx context.Context, userUUID, sampleID uuid.UUID) error {
+	if err := s.repo.UnlikeSample(ctx, userUUID, sampleID); err != nil {
+		return fmt.Errorf("failed to unlike sample: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Service) LikePack(ctx context.Context, userUUID, packID uuid.UUID) error {
+	pack, err := s.packs.GetByID(ctx, packID)
+	if errors.Is(err, domain.ErrNotFound) || pack.DeletedAt != nil {
+		return domain.ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get pack: %w", err)
+	}
+
+	if err = s.repo.LikePack(ctx, userUUID, packID); err != nil {
+		return fmt.Errorf("failed to like pack: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Service) UnlikePack(ctx context.Context, userUUID, packID uuid.UUID) error {
+	if err := s.repo.UnlikePack(ctx, userUUID, packID); err != nil {
+		return fmt.Errorf("failed to unlike pack: %w", err)
+	}
+
+	return nil
+}
+
+// GetLiked возвращает лайкнутые пользователем семплы и паки
+func (s *Service) GetLiked(ctx context.Context, userUUID uuid.UUID) ([]entity.Sample, []entity.Pack, error) {
+	samples, err := s.samples.GetLiked(ctx, userUUID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get liked samples: %w", err)
+	}
+
+	packs, err := s.packs.GetLiked(ctx, userUUID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get liked packs: %w", err)
+	}
+
+	return samples, packs, nil
+}
+
+// LikedSamples - какие из семплов лайкнул пользователь, для флага is_liked
+func (s *Service) LikedSamples(ctx context.Context, userUUID uuid.UUID, samples []entity.Sample) (map[uuid.UUID]bool, error) {
+	if len(samples) == 0 {
+		return map[uuid.UUID]bool{}, nil
+	}
+
+	ids := make([]uuid.UUID, len(samples))
+	for i, sample := range samples {
+		ids[i] = sample.ID
+	}
+
+	liked, err := s.repo.LikedSamples(ctx, userUUID, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check likes: %w", err)
+	}
+
+	return liked, nil
+}
@@ -0,0 +1,125 @@
+package likes
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/musicman-backend/internal/domain"
+	"github.com/musicman-backend/internal/domain/constant"
+	"github.com/musicman-backend/internal/domain/entity"
+)
+
+// fakeRepo - лайки как множество пар пользователь-объект
+type fakeRepo struct {
+	Repository
+	liked map[[2]uuid.UUID]bool
+}
+
+func (f *fakeRepo) LikeSample(_ context.Context, userUUID, sampleID uuid.UUID) error {
+	f.liked[[2]uuid.UUID{userUUID, sampleID}] = true
+	return nil
+}
+
+func (f *fakeRepo) UnlikeSample(_ context.Context, userUUID, sampleID uuid.UUID) error {
+	delete(f.liked, [2]uuid.UUID{userUUID, sampleID})
+	return nil
+}
+
+func (f *fakeRepo) LikePack(_ context.Context, userUUID, packID uuid.UUID) error {
+	f.liked[[2]uuid.UUID{userUUID, packID}] = true
+	return nil
+}
+
+func (f *fakeRepo) LikedSamples(_ context.Context, userUUID uuid.UUID, sampleIDs []uuid.UUID) (map[uuid.UUID]bool, error) {
+	liked := make(map[uuid.UUID]bool)
+	for _, id := range sampleIDs {
+		if f.liked[[2]uuid.UUID{userUUID, id}] {
+			liked[id] = true
+		}
+	}
+	return liked, nil
+}
+
+type fakeSamples struct {
+	SampleRepository
+	samples map[uuid.UUID]entity.Sample
+}
+
+func (f fakeSamples) GetByID(_ context.Context, id uuid.UUID) (entity.Sample, error) {
+	sample, ok := f.samples[id]
+	if !ok {
+		return sample, domain.ErrNotFound
+	}
+	return sample, nil
+}
+
+type fakePacks struct {
+	PackRepository
+	packs map[uuid.UUID]entity.Pack
+}
+
+func (f fakePacks) GetByID(_ context.Context, id uuid.UUID) (entity.Pack, error) {
+	pack, ok := f.packs[id]
+	if !ok {
+		return pack, domain.ErrNotFound
+	}
+	return pack, nil
+}
+
+func TestLikeOnlyVisible(t *testing.T) {
+	deletedAt := time.Now()
+	scheduled := time.Now().Add(time.Hour)
+	published := entity.Sample{ID: uuid.New(), Status: constant.SampleStatusPublished}
+	hidden := map[string]entity.Sample{
+		"draft":     {ID: uuid.New(), Status: constant.SampleStatusDraft},
+		"scheduled": {ID: uuid.New(), Status: constant.SampleStatusPublished, PublishAt: &scheduled},
+		"deleted":   {ID: uuid.New(), Status: constant.SampleStatusPublished, DeletedAt: &deletedAt},
+	}
+	samples := map[uuid.UUID]entity.Sample{published.ID: published}
+	for _, sample := range hidden {
+		samples[sample.ID] = sample
+	}
+	pack := entity.Pack{ID: uuid.New()}
+	deletedPack := entity.Pack{ID: uuid.New(), DeletedAt: &deletedAt}
+
+	repo := &fakeRepo{liked: map[[2]uuid.UUID]bool{}}
+	s := New(repo, fakeSamples{samples: samples}, fakePacks{packs: map[uuid.UUID]entity.Pack{pack.ID: pack, deletedPack.ID: deletedPack}})
+	ctx := context.Background()
+	user := uuid.New()
+
+	for i := 0; i < 2; i++ {
+		if err := s.LikeSample(ctx, user, published.ID); err != nil {
+			t.Fatalf("like %d: %v, repeated like must not fail", i+1, err)
+		}
+	}
+	for name, sample := range hidden {
+		if err := s.LikeSample(ctx, user, sample.ID); !errors.Is(err, domain.ErrNotFound) {
+			t.Fatalf("like of a %s sample: got %v, want ErrNotFound", name, err)
+		}
+	}
+	if err := s.LikePack(ctx, user, pack.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.LikePack(ctx, user, deletedPack.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("like of a deleted pack: got %v, want ErrNotFound", err)
+	}
+
+	liked, err := s.LikedSamples(ctx, user, []entity.Sample{published, hidden["draft"]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !liked[published.ID] || len(liked) != 1 {
+		t.Fatalf("liked %v, want only the published sample", liked)
+	}
+
+	if err = s.UnlikeSample(ctx, user, published.ID); err != nil {
+		t.Fatal(err)
+	}
+	if liked, _ = s.LikedSamples(ctx, user, []entity.Sample{published}); liked[published.ID] {
+		t.Fatal("sample is still liked after unlike")
+	}
+}
@@ -1,35 +1,118 @@
 package service
 
 import (
+	"github.com/musicman-backend/config"
 	"github.com/musicman-backend/internal/repository"
+	"github.com/musicman-backend/internal/service/analytics"
+	"github.com/musicman-backend/internal/service/archive"
 	"github.com/musicman-backend/internal/service/auth"
+	"github.com/musicman-backend/internal/service/author"
+	"github.com/musicman-backend/internal/service/collection"
+	"github.com/musicman-backend/internal/service/earnings"
+	"github.com/musicman-backend/internal/service/facets"
+	"github.com/musicman-backend/internal/service/gc"
+	"github.com/musicman-backend/internal/service/likes"
+	"github.com/musicman-backend/internal/service/media"
 	"github.com/musicman-backend/internal/service/music"
+	"github.com/musicman-backend/internal/service/oauth"
 	"github.com/musicman-backend/internal/service/payment"
+	"github.com/musicman-backend/internal/service/profile"
 	"github.com/musicman-backend/internal/service/purchase"
+	"github.com/musicman-backend/internal/service/ratelimit"
+	"github.com/musicman-backend/internal/service/recommendation"
+	"github.com/musicman-backend/internal/service/session"
+	"github.com/musicman-backend/internal/service/taxonomy"
 	"github.com/musicman-backend/internal/service/token"
+	"github.com/musicman-backend/internal/service/twofactor"
 	"github.com/musicman-backend/pkg/client/yookassa"
+	"github.com/musicman-backend/pkg/signer"
 )
 
 type Manager struct {
-	Token    *token.Service
-	Auth     *auth.Service
-	Payment  *payment.Service
-	Music    *music.Service
-	Purchase *purchase.Service
+	Token      *token.Service
+	Auth       *auth.Service
+	Payment    *payment.Service
+	Music      *music.Service
+	Purchase   *purchase.Service
+	GC         *gc.Service
+	RateLimit  *ratelimit.Service
+	OAuth      *oauth.Service
+	TwoFactor  *twofactor.Service
+	Session    *session.Service
+	Profile    *profile.Service
+	Author     *author.Service
+	Earnings   *earnings.Service
+	Likes      *likes.Service
+	Collection *collection.Service
+	Analytics  *analytics.Service
+	Recommend  *recommendation.Service
+	Taxonomy   *taxonomy.Service
+	Facets     *facets.Service
+	Archive    *archive.Service
+	// EventWriter пишет события статистики в фоне, запускается и останавливается вместе с приложением
+	EventWriter *analytics.Writer
 }
 
-func NewManager(repository *repository.Manager, yookassa *yookassa.Client) *Manager {
-	tokenService := token.New("secret")
-	authService := auth.NewService(repository.UserRepository, tokenService)
+func NewManager(cfg *config.Config, repository *repository.Manager, yookassa *yookassa.Client, mailer auth.Mailer, oauthProviders map[string]oauth.Provider, documentSigner *signer.Signer) *Manager {
+	tokenService := token.New("secret", cfg.TwoFactor.ChallengeTTL)
+	var rateLimitStore ratelimit.Store = ratelimit.NewMemoryStore()
+	if cfg.RateLimit.Store == config.RateLimitStorePostgres {
+		rateLimitStore = repository.RateLimitRepository
+	}
+	rateLimitService := ratelimit.New(rateLimitStore, cfg.RateLimit)
+
+	sessionService := session.New(repository.SessionRepository, tokenService, cfg.Sessions)
+	twoFactorService := twofactor.New(repository.UserRepository, repository.RecoveryCodeRepository, rateLimitService, cfg.TwoFactor)
+	authService := auth.NewService(repository.UserRepository, tokenService, sessionService, rateLimitService, repository.UserTokenRepository, mailer, twoFactorService, cfg.Accounts)
+	oauthService := oauth.New(oauthProviders, repository.IdentityRepository, repository.UserRepository, tokenService, sessionService, cfg.OAuth.StateTTL)
+	mediaService := media.New(repository.FileRepository, cfg.Uploads.TempDir)
+	profileService := profile.New(repository.UserRepository, mediaService, sessionService)
 	paymentService := payment.NewService(yookassa, repository.PaymentRepository, repository.UserRepository)
 
-	musicService := music.New(repository.SampleRepository, repository.PackRepository, repository.FileRepository, repository.UserRepository)
-	purchaseService := purchase.New(repository.PurchaseRepository, repository.SampleRepository, repository.UserRepository, musicService)
+	eventWriter := analytics.NewWriter(repository.AnalyticsRepository, cfg.Analytics.BufferSize, cfg.Analytics.BatchSize, cfg.Analytics.FlushInterval)
+	analyticsService := analytics.New(repository.AnalyticsRepository, eventWriter, cfg.Analytics.EventRetention)
+
+	taxonomyService := taxonomy.New(repository.TaxonomyRepository)
+	musicService := music.New(repository.SampleRepository, repository.VersionRepository, repository.PackRepository, repository.FileRepository, repository.UserRepository, taxonomyService, mediaService, cfg.Trash.Retention)
+	earningsService := earnings.New(repository.UserRepository, repository.EarningsRepository, earnings.StubExecutor{}, cfg.Revenue)
+	purchaseService := purchase.New(repository.PurchaseRepository, repository.SampleRepository, repository.UserRepository, musicService, musicService, twoFactorService, earningsService, documentSigner, analyticsService, cfg.TwoFactor.PurchaseThreshold)
+	authorService := author.New(repository.UserRepository, repository.SampleRepository, repository.PackRepository, repository.AuthorRepository)
+	likesService := likes.New(repository.LikeRepository, repository.SampleRepository, repository.PackRepository)
+	collectionService := collection.New(repository.CollectionRepository, repository.SampleRepository, repository.UserRepository)
+	facetsService := facets.New(repository.SampleRepository, cfg.Catalog.FacetsTTL)
+	archiveService := archive.New(repository.PackRepository, repository.SampleRepository, purchaseService, repository.FileRepository, analyticsService, music.BucketName)
+	recommendationService := recommendation.New(repository.RecommendationRepository, repository.SampleRepository, cfg.Recommendations)
+	gcService := gc.New(repository.FileRepository, repository.VersionRepository, repository.PackRepository, repository.UserRepository, repository.SampleRepository, gc.Config{
+		Bucket:         music.BucketName,
+		CoverBucket:    music.CoverBucket,
+		AvatarBucket:   profile.AvatarBucket,
+		MinObjectAge:   cfg.GC.MinObjectAge,
+		TempDir:        cfg.Uploads.TempDir,
+		TempFileMaxAge: cfg.GC.TempFileMaxAge,
+	})
+
 	return &Manager{
-		Token:    tokenService,
-		Auth:     authService,
-		Payment:  paymentService,
-		Music:    musicService,
-		Purchase: purchaseService,
+		Token:      tokenService,
+		Auth:       authService,
+		Payment:    paymentService,
+		Music:      musicService,
+		Purchase:   purchaseService,
+		GC:         gcService,
+		RateLimit:  rateLimitService,
+		OAuth:      oauthService,
+		TwoFactor:  twoFactorService,
+		Session:    sessionService,
+		Profile:    profileService,
+		Author:     authorService,
+		Earnings:   earningsService,
+		Likes:      likesService,
+		Collection: collectionService,
+		Analytics:  analyticsService,
+		Recommend:  recommendationService,
+		Taxonomy:   taxonomyService,
+		Facets:     facetsService,
+		Archive:    archiveService,
+
+		EventWriter: eventWriter,
 	}
 }
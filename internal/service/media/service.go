@@ -0,0 +1,178 @@
+package media
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"log/slog"
+	"os"
+	"path"
+
+	"github.com/google/uuid"
+
+	"github.com/musicman-backend/internal/domain"
+	"github.com/musicman-backend/internal/domain/constant"
+	"github.com/musicman-backend/internal/domain/entity"
+	"github.com/musicman-backend/pkg/imaging"
+)
+
+const thumbnailExt = ".jpg"
+
+type FileStorage interface {
+	UploadFile(ctx context.Context, bucketName, objectName, filePath string) error
+	GetFileURL(ctx context.Context, bucketName, objectName string) (string, error)
+	DeleteFile(ctx context.Context, bucketName, objectName string) error
+	CreateBucketIfNotExists(ctx context.Context, bucketName string) error
+}
+
+// Service хранит загруженные картинки как набор квадратных миниатюр: обложки паков, аватары.
+// Оригинал не сохраняется, в базе лежит только ключ, от которого строятся ключи миниатюр
+type Service struct {
+	files   FileStorage
+	tempDir string
+}
+
+func New(files FileStorage, tempDir string) *Service {
+	return &Service{
+		files:   files,
+		tempDir: tempDir,
+	}
+}
+
+type thumbnail struct {
+	name string
+	side int
+}
+
+func thumbnails(spec entity.ImageSpec) []thumbnail {
+	return []thumbnail{
+		{name: constant.ImageSmall, side: spec.Small},
+		{name: constant.ImageMedium, side: spec.Medium},
+		{name: constant.ImageLarge, side: spec.Large},
+	}
+}
+
+// Upload проверяет формат и размеры картинки filePath и загружает ее миниатюры в бакет spec.
+// Возвращает ключ картинки
+func (s *Service) Upload(ctx context.Context, spec entity.ImageSpec, owner uuid.UUID, filePath string) (string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open image: %w", err)
+	}
+	defer f.Close()
+
+	img, err := imaging.Decode(f, imaging.Limits{MinSide: spec.MinSide, MaxSide: spec.MaxSide})
+	if errors.Is(err, imaging.ErrFormat) || errors.Is(err, imaging.ErrSize) {
+		return "", fmt.Errorf("%w: %v", domain.ErrInvalidImage, err)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if err = s.files.CreateBucketIfNotExists(ctx, spec.Bucket); err != nil {
+		return "", fmt.Errorf("failed to create bucket: %w", err)
+	}
+
+	// у каждой загрузки свой ключ, чтобы старые ссылки не показывали новую картинку из кеша
+	key := owner.String() + "/" + uuid.NewString()
+	for _, t := range thumbnails(spec) {
+		if err = s.uploadThumbnail(ctx, spec.Bucket, objectName(key, t.name), imaging.Square(img, t.side)); err != nil {
+			s.Delete(ctx, spec, key)
+			return "", err
+		}
+	}
+
+	return key, nil
+}
+
+func (s *Service) uploadThumbnail(ctx context.Context, bucket, object string, img image.Image) error {
+	tmp, err := os.CreateTemp(s.tempDir, "thumbnail-*"+thumbnailExt)
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer func() {
+		if err := os.Remove(tmp.Name()); err != nil {
+			slog.Warn("failed to remove temp file", slog.String("path", tmp.Name()), slog.String("err", err.Error()))
+		}
+	}()
+
+	err = imaging.EncodeJPEG(tmp, img)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to encode thumbnail: %w", err)
+	}
+
+	if err = s.files.UploadFile(ctx, bucket, object, tmp.Name()); err != nil {
+		return fmt.Errorf("failed to upload thumbnail: %w", err)
+	}
+
+	return nil
+}
+
+// URLs - ссылки на миниатюры картинки key, пустые, если картинки нет
+func (s *Service) URLs(ctx context.Context, spec entity.ImageSpec, key string) (entity.ImageURLs, error) {
+	var urls entity.ImageURLs
+	if key == "" {
+		return urls, nil
+	}
+
+	// до миниатюр аватар хранился одним файлом с расширением, отдаем его во всех размерах
+	if isLegacy(key) {
+		url, err := s.files.GetFileURL(ctx, spec.Bucket, key)
+		if err != nil {
+			return urls, fmt.Errorf("failed to get image url: %w", err)
+		}
+
+		return entity.ImageURLs{Small: url, Medium: url, Large: url}, nil
+	}
+
+	for _, t := range thumbnails(spec) {
+		url, err := s.files.GetFileURL(ctx, spec.Bucket, objectName(key, t.name))
+		if err != nil {
+			return urls, fmt.Errorf("failed to get image url: %w", err)
+		}
+
+		switch t.name {
+		case constant.ImageSmall:
+			urls.Small = url
+		case constant.ImageMedium:
+			urls.Medium = url
+		case constant.ImageLarge:
+			urls.Large = url
+		}
+	}
+
+	return urls, nil
+}
+
+// Delete удаляет миниатюры картинки key. Ошибки только логируются: картинка уже отвязана
+func (s *Service) Delete(ctx context.Context, spec entity.ImageSpec, key string) {
+	if key == "" {
+		return
+	}
+
+	objects := []string{key}
+	if !isLegacy(key) {
+		objects = objects[:0]
+		for _, t := range thumbnails(spec) {
+			objects = append(objects, objectName(key, t.name))
+		}
+	}
+
+	for _, object := range objects {
+		if err := s.files.DeleteFile(ctx, spec.Bucket, object); err != nil {
+			slog.Warn("failed to delete image", slog.String("key", object), slog.String("err", err.Error()))
+		}
+	}
+}
+
+func objectName(key, size string) string {
+	return key + "/" + size + thumbnailExt
+}
+
+func isLegacy(key string) bool {
+	return path.Ext(key) != ""
+}
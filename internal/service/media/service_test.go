@@ -0,0 +1,136 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/musicman-backend/internal/domain"
+	"github.com/musicman-backend/internal/domain/entity"
+)
+
+// fakeStorage читает загруженный файл сразу: сервис удаляет временные миниатюры после загрузки
+type fakeStorage struct {
+	objects map[string][]byte
+	deleted []string
+}
+
+func (f *fakeStorage) UploadFile(_ context.Context, bucket, object, filePath string) error {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+	f.objects[bucket+"/"+object] = data
+	return nil
+}
+
+func (f *fakeStorage) GetFileURL(_ context.Context, bucket, object string) (string, error) {
+	return "https://files/" + bucket + "/" + object, nil
+}
+
+func (f *fakeStorage) DeleteFile(_ context.Context, bucket, object string) error {
+	f.deleted = append(f.deleted, bucket+"/"+object)
+	return nil
+}
+
+func (f *fakeStorage) CreateBucketIfNotExists(context.Context, string) error {
+	return nil
+}
+
+var testSpec = entity.ImageSpec{Bucket: "covers", MinSide: 100, MaxSide: 1000, Small: 50, Medium: 100, Large: 200}
+
+func imageFile(t *testing.T, width, height int) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "cover.png")
+	if err := os.WriteFile(path, buf.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestUploadStoresThumbnails(t *testing.T) {
+	files := &fakeStorage{objects: map[string][]byte{}}
+	tempDir := t.TempDir()
+	s := New(files, tempDir)
+	owner := uuid.New()
+
+	key, err := s.Upload(context.Background(), testSpec, owner, imageFile(t, 400, 300))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(key, owner.String()+"/") {
+		t.Fatalf("key %q, want it under the owner", key)
+	}
+
+	for name, side := range map[string]int{"small": 50, "medium": 100, "large": 200} {
+		data, ok := files.objects["covers/"+key+"/"+name+".jpg"]
+		if !ok {
+			t.Fatalf("no %s thumbnail among %d objects", name, len(files.objects))
+		}
+		cfg, err := jpeg.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cfg.Width != side || cfg.Height != side {
+			t.Fatalf("%s thumbnail is %dx%d, want %dx%d", name, cfg.Width, cfg.Height, side, side)
+		}
+	}
+
+	if left, _ := os.ReadDir(tempDir); len(left) != 0 {
+		t.Fatalf("temp files left: %v", left)
+	}
+}
+
+func TestUploadRejectsInvalidImage(t *testing.T) {
+	files := &fakeStorage{objects: map[string][]byte{}}
+	s := New(files, t.TempDir())
+
+	text := filepath.Join(t.TempDir(), "cover.png")
+	if err := os.WriteFile(text, []byte("not an image"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, path := range map[string]string{"too small": imageFile(t, 99, 300), "not an image": text} {
+		if _, err := s.Upload(context.Background(), testSpec, uuid.New(), path); !errors.Is(err, domain.ErrInvalidImage) {
+			t.Fatalf("%s: got %v, want ErrInvalidImage", name, err)
+		}
+	}
+	if len(files.objects) != 0 {
+		t.Fatalf("invalid images uploaded %d objects", len(files.objects))
+	}
+}
+
+func TestLegacyImage(t *testing.T) {
+	files := &fakeStorage{objects: map[string][]byte{}}
+	s := New(files, t.TempDir())
+	ctx := context.Background()
+
+	urls, err := s.URLs(ctx, testSpec, "avatar.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if urls.Small != urls.Large || !strings.HasSuffix(urls.Small, "/avatar.png") {
+		t.Fatalf("legacy urls %+v, want the single file in every size", urls)
+	}
+
+	s.Delete(ctx, testSpec, "avatar.png")
+	s.Delete(ctx, testSpec, "owner/key")
+	want := []string{"covers/avatar.png", "covers/owner/key/small.jpg", "covers/owner/key/medium.jpg", "covers/owner/key/large.jpg"}
+	if !slices.Equal(files.deleted, want) {
+		t.Fatalf("deleted %v, want %v", files.deleted, want)
+	}
+}
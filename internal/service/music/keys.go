@@ -0,0 +1,84 @@
+package music
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+// keyPrefix - префикс ключей семплов в бакете, всё что без него - ключи старого формата
+const keyPrefix = "samples/"
+
+const maxFilenameLength = 255
+
+var extPattern = regexp.MustCompile(`^\.[a-z0-9]{1,10}$`)
+
+// ObjectKey - ключ объекта аудио в хранилище: samples/<id>/<version>/<hash>.<ext>
+func ObjectKey(sampleID uuid.UUID, version int, hash, ext string) string {
+	return fmt.Sprintf("%s%s/%d/%s%s", keyPrefix, sampleID, version, hash, ext)
+}
+
+func isLegacyKey(key string) bool {
+	return key != "" && !strings.HasPrefix(key, keyPrefix)
+}
+
+// fileExt - расширение файла в нижнем регистре, пустое если оно выглядит странно
+func fileExt(filename string) string {
+	ext := strings.ToLower(filepath.Ext(filename))
+	if !extPattern.MatchString(ext) {
+		return ""
+	}
+
+	return ext
+}
+
+// SanitizeFilename оставляет от пользовательского имени файла только безопасную для хранения часть
+func SanitizeFilename(filename string) string {
+	filename = strings.ReplaceAll(filename, "\\", "/")
+	filename = filepath.Base(filename)
+
+	filename = strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsControl(r):
+			return -1
+		case r == '/' || r == ':' || r == '*' || r == '?' || r == '"' || r == '<' || r == '>' || r == '|':
+			return '_'
+		}
+		return r
+	}, filename)
+
+	filename = strings.TrimSpace(filename)
+	if filename == "." || filename == ".." {
+		return ""
+	}
+
+	runes := []rune(filename)
+	if len(runes) > maxFilenameLength {
+		filename = string(runes[:maxFilenameLength])
+	}
+
+	return filename
+}
+
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open file: %w", err)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("failed to hash file: %w", err)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
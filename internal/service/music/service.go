@@ -4,22 +4,52 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/musicman-backend/internal/domain"
+	"github.com/musicman-backend/internal/domain/constant"
 	"github.com/musicman-backend/internal/domain/entity"
+	"github.com/musicman-backend/pkg/slug"
 )
 
 const BucketName = "samples"
 
+const maxRejectReasonLen = 500
+
+// CoverBucket - бакет обложек паков, отдельно от аудио
+const CoverBucket = "covers"
+
+// coverImage - обложка не меньше средней миниатюры, чтобы витрина не показывала растянутые картинки
+var coverImage = entity.ImageSpec{
+	Bucket:  CoverBucket,
+	MinSide: 480,
+	MaxSide: 4096,
+	Small:   160,
+	Medium:  480,
+	Large:   1000,
+}
+
 type SampleRepository interface {
-	Create(ctx context.Context, sample entity.Sample) (uuid.UUID, error)
+	Create(ctx context.Context, sample entity.Sample, tagIDs []uuid.UUID) (uuid.UUID, error)
 	GetByID(ctx context.Context, id uuid.UUID) (entity.Sample, error)
-	GetAll(ctx context.Context) ([]entity.Sample, error)
+	GetAll(ctx context.Context, filter entity.SampleFilter) ([]entity.Sample, error)
 	GetByPack(ctx context.Context, packID uuid.UUID) ([]entity.Sample, error)
 	Update(ctx context.Context, sample entity.Sample) error
+	SetLicenses(ctx context.Context, sampleID uuid.UUID, licenses []entity.SampleLicense) error
 	Delete(ctx context.Context, id uuid.UUID) error
+	GetDeleted(ctx context.Context, authorUUID *uuid.UUID) ([]entity.Sample, error)
+	GetLegacyKeyed(ctx context.Context) ([]entity.Sample, error)
+	GetPurgeable(ctx context.Context, before time.Time) ([]entity.Sample, error)
+	SoftDelete(ctx context.Context, id uuid.UUID, deletedAt time.Time) error
+	Restore(ctx context.Context, id uuid.UUID) error
+	Submit(ctx context.Context, id uuid.UUID, publishAt *time.Time, submittedAt time.Time) error
+	Resubmit(ctx context.Context, id uuid.UUID, submittedAt time.Time) error
+	Review(ctx context.Context, id, reviewer uuid.UUID, status, reason string, reviewedAt time.Time) error
+	GetPending(ctx context.Context) ([]entity.Sample, error)
 }
 
 type PackRepository interface {
@@ -27,7 +57,21 @@ type PackRepository interface {
 	GetByID(ctx context.Context, id uuid.UUID) (entity.Pack, error)
 	GetAll(ctx context.Context) ([]entity.Pack, error)
 	Update(ctx context.Context, pack entity.Pack) error
-	Delete(ctx context.Context, id uuid.UUID) error
+	GetDeleted(ctx context.Context, authorUUID *uuid.UUID) ([]entity.Pack, error)
+	SetCover(ctx context.Context, id uuid.UUID, key string) (string, error)
+	AddSample(ctx context.Context, packID, sampleID uuid.UUID, addedAt time.Time) error
+	RemoveSample(ctx context.Context, packID, sampleID uuid.UUID, removedAt time.Time) error
+	SampleIDs(ctx context.Context, packID uuid.UUID) ([]uuid.UUID, error)
+	Reorder(ctx context.Context, packID uuid.UUID, sampleIDs []uuid.UUID, updatedAt time.Time) error
+	SoftDelete(ctx context.Context, id uuid.UUID, deletedAt time.Time) error
+	Restore(ctx context.Context, id uuid.UUID) error
+	DeleteDeletedBefore(ctx context.Context, before time.Time) ([]string, error)
+}
+
+type VersionRepository interface {
+	Create(ctx context.Context, version entity.SampleVersion) (uuid.UUID, error)
+	GetBySample(ctx context.Context, sampleID uuid.UUID) ([]entity.SampleVersion, error)
+	GetByVersion(ctx context.Context, sampleID uuid.UUID, version int) (entity.SampleVersion, error)
 }
 
 type FileRepository interface {
@@ -39,71 +83,113 @@ type FileRepository interface {
 }
 
 type UserRepository interface {
-	GetUserByLogin(ctx context.Context, login string) (entity.User, error)
+	GetUserByUUID(ctx context.Context, userUUID uuid.UUID) (entity.User, error)
+}
+
+// Taxonomy проверяет жанры и теги по справочникам
+type Taxonomy interface {
+	ResolveGenre(ctx context.Context, genre string) (entity.Genre, error)
+	ValidateTags(ctx context.Context, set entity.TagSet) error
+	ResolveTags(ctx context.Context, set entity.TagSet) ([]uuid.UUID, error)
+	SetSampleTags(ctx context.Context, sampleID uuid.UUID, current []entity.Tag, set entity.TagSet) error
+	SetPackTags(ctx context.Context, packID uuid.UUID, current []entity.Tag, set entity.TagSet) error
+}
+
+// Images хранит картинки миниатюрами
+type Images interface {
+	Upload(ctx context.Context, spec entity.ImageSpec, owner uuid.UUID, filePath string) (string, error)
+	URLs(ctx context.Context, spec entity.ImageSpec, key string) (entity.ImageURLs, error)
+	Delete(ctx context.Context, spec entity.ImageSpec, key string)
 }
 
 type Service struct {
-	sampleRepo SampleRepository
-	packRepo   PackRepository
-	fileRepo   FileRepository
-	userRepo   UserRepository
+	sampleRepo  SampleRepository
+	versionRepo VersionRepository
+	packRepo    PackRepository
+	fileRepo    FileRepository
+	userRepo    UserRepository
+	taxonomy    Taxonomy
+	images      Images
+
+	// trashRetention - сколько удаленные семплы и паки можно восстановить из корзины
+	trashRetention time.Duration
 }
 
 func New(
 	sampleRepo SampleRepository,
+	versionRepo VersionRepository,
 	packRepo PackRepository,
 	fileRepo FileRepository,
 	userRepo UserRepository,
+	taxonomy Taxonomy,
+	images Images,
+	trashRetention time.Duration,
 ) *Service {
 	return &Service{
-		sampleRepo: sampleRepo,
-		packRepo:   packRepo,
-		fileRepo:   fileRepo,
-		userRepo:   userRepo,
+		sampleRepo:     sampleRepo,
+		versionRepo:    versionRepo,
+		packRepo:       packRepo,
+		fileRepo:       fileRepo,
+		userRepo:       userRepo,
+		taxonomy:       taxonomy,
+		images:         images,
+		trashRetention: trashRetention,
 	}
 }
 
-func (s *Service) CreateSample(ctx context.Context, author, title, description, genre string, packID *uuid.UUID, price int) (uuid.UUID, error) {
+// CreateSample создает семпл от имени authorUUID - автором всегда становится текущий пользователь.
+// Платный семпл выставляется с коммерческой лицензией по цене price, остальные лицензии задаются через SetLicenses.
+// Жанр берется из справочника, теги проверяются до создания семпла
+func (s *Service) CreateSample(ctx context.Context, authorUUID uuid.UUID, title, description, genre string, packID *uuid.UUID, price int, tags entity.TagSet) (uuid.UUID, error) {
 	var sampleID uuid.UUID
 	if packID != nil {
-		_, err := s.packRepo.GetByID(ctx, *packID)
+		pack, err := s.GetPack(ctx, *packID)
 		if errors.Is(err, domain.ErrNotFound) {
 			return sampleID, err
 		}
 		if err != nil {
 			return sampleID, fmt.Errorf("error getting pack while creating sample: %w", err)
 		}
-	}
-	if _, err := s.userRepo.GetUserByLogin(ctx, author); err != nil {
-		return sampleID, fmt.Errorf("error getting user by login: %w", err)
-	}
-
-	if author == "" {
-		return uuid.Nil, fmt.Errorf("author is empty")
+		if err = s.checkManage(ctx, authorUUID, pack.AuthorUUID); err != nil {
+			return sampleID, err
+		}
 	}
 	if title == "" {
-		return uuid.Nil, fmt.Errorf("description is empty")
+		return uuid.Nil, fmt.Errorf("title is empty")
 	}
 	if description == "" {
-		return uuid.Nil, fmt.Errorf("genre is empty")
+		return uuid.Nil, fmt.Errorf("description is empty")
+	}
+	if price < 0 {
+		return uuid.Nil, fmt.Errorf("%w: цена не может быть отрицательной", domain.ErrInvalidLicense)
 	}
-	if genre == "" {
-		return uuid.Nil, fmt.Errorf("genre is empty")
+	genre, err := s.taxonomy.ResolveGenre(ctx, genre)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	tagIDs, err := s.taxonomy.ResolveTags(ctx, tags)
+	if err != nil {
+		return uuid.Nil, err
 	}
 
 	sample := entity.Sample{
 		Title:       title,
-		Author:      author,
+		AuthorUUID:  authorUUID,
 		Description: description,
 		Genre:       genre,
-		MinioKey:    fmt.Sprintf("sample_%d_%s.wav", time.Now().Unix(), title),
-		PackID:      packID,
 		Price:       price,
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
 	}
+	if price > 0 {
+		sample.Licenses = []entity.SampleLicense{{Type: constant.LicenseCommercial, Price: price}}
+	}
+	if packID != nil {
+		sample.PackIDs = []uuid.UUID{*packID}
+	}
 
-	sampleID, err := s.sampleRepo.Create(ctx, sample)
+	// семпл создается вместе с тегами, лицензией и треком пака, чтобы не оставлять полусозданных
+	sampleID, err = s.sampleRepo.Create(ctx, sample, tagIDs)
 	if err != nil {
 		return sampleID, fmt.Errorf("failed to create sample: %w", err)
 	}
@@ -111,26 +197,264 @@ func (s *Service) CreateSample(ctx context.Context, author, title, description,
 	return sampleID, nil
 }
 
-func (s *Service) UploadAudio(ctx context.Context, audioFilePath string, sampleID uuid.UUID) error {
+// UploadAudio загружает аудио семпла новой версией и делает ее текущей. Загружать может автор или админ.
+// Предыдущие версии остаются в хранилище, чтобы покупатели не потеряли купленный файл
+func (s *Service) UploadAudio(ctx context.Context, userUUID, sampleID uuid.UUID, audioFilePath, filename string, size int64, duration float64) (entity.Sample, error) {
 	sample, err := s.sampleRepo.GetByID(ctx, sampleID)
-	if errors.Is(err, domain.ErrNotFound) {
-		return err
+	if errors.Is(err, domain.ErrNotFound) || sample.DeletedAt != nil {
+		return sample, domain.ErrNotFound
 	}
 	if err != nil {
-		return fmt.Errorf("failed to get sample: %w", err)
+		return sample, fmt.Errorf("failed to get sample: %w", err)
+	}
+
+	if err = s.checkManage(ctx, userUUID, sample.AuthorUUID); err != nil {
+		return sample, err
+	}
+
+	hash, err := hashFile(audioFilePath)
+	if err != nil {
+		return sample, err
+	}
+
+	if hash == sample.AudioHash {
+		// тот же самый файл, новая версия не нужна
+		return sample, nil
+	}
+
+	versions, err := s.versionRepo.GetBySample(ctx, sample.ID)
+	if err != nil {
+		return sample, fmt.Errorf("failed to get sample versions: %w", err)
+	}
+
+	next := 1
+	for _, v := range versions {
+		if v.Version >= next {
+			next = v.Version + 1
+		}
+	}
+
+	version := entity.SampleVersion{
+		SampleID:         sample.ID,
+		Version:          next,
+		MinioKey:         ObjectKey(sample.ID, next, hash, fileExt(filename)),
+		Hash:             hash,
+		OriginalFilename: SanitizeFilename(filename),
+		Size:             size,
+		Duration:         duration,
+		UploadedAt:       time.Now(),
 	}
 
 	if err := s.fileRepo.CreateBucketIfNotExists(ctx, BucketName); err != nil {
-		return fmt.Errorf("failed to create bucket: %w", err)
+		return sample, fmt.Errorf("failed to create bucket: %w", err)
+	}
+
+	if err := s.fileRepo.UploadFile(ctx, BucketName, version.MinioKey, audioFilePath); err != nil {
+		return sample, fmt.Errorf("failed to upload file: %w", err)
+	}
+
+	version.ID, err = s.versionRepo.Create(ctx, version)
+	if err != nil {
+		if delErr := s.fileRepo.DeleteFile(ctx, BucketName, version.MinioKey); delErr != nil {
+			slog.Error("failed to delete uploaded file", slog.String("key", version.MinioKey), slog.String("err", delErr.Error()))
+		}
+		return sample, fmt.Errorf("failed to create sample version: %w", err)
 	}
 
-	if err := s.fileRepo.UploadFile(ctx, BucketName, sample.MinioKey, audioFilePath); err != nil {
+	if err = s.setAudio(ctx, &sample, version); err != nil {
+		return sample, err
+	}
+
+	return sample, nil
+}
+
+// GetSampleVersions возвращает все версии аудио семпла. Видит их только автор или админ:
+// в версиях хеши и исходные имена файлов
+func (s *Service) GetSampleVersions(ctx context.Context, userUUID, sampleID uuid.UUID) ([]entity.SampleVersion, error) {
+	sample, err := s.sampleRepo.GetByID(ctx, sampleID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sample: %w", err)
+	}
+
+	if err = s.checkManage(ctx, userUUID, sample.AuthorUUID); err != nil {
+		return nil, err
+	}
+
+	versions, err := s.versionRepo.GetBySample(ctx, sampleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sample versions: %w", err)
+	}
+
+	return versions, nil
+}
+
+// RollbackSample делает текущей одну из ранее загруженных версий аудио. Откатывать может автор или админ
+func (s *Service) RollbackSample(ctx context.Context, userUUID, sampleID uuid.UUID, version int) (entity.Sample, error) {
+	sample, err := s.sampleRepo.GetByID(ctx, sampleID)
+	if errors.Is(err, domain.ErrNotFound) || sample.DeletedAt != nil {
+		return sample, domain.ErrNotFound
+	}
+	if err != nil {
+		return sample, fmt.Errorf("failed to get sample: %w", err)
+	}
+
+	if err = s.checkManage(ctx, userUUID, sample.AuthorUUID); err != nil {
+		return sample, err
+	}
+
+	v, err := s.versionRepo.GetByVersion(ctx, sampleID, version)
+	if errors.Is(err, domain.ErrNotFound) {
+		return sample, err
+	}
+	if err != nil {
+		return sample, fmt.Errorf("failed to get sample version: %w", err)
+	}
+
+	if err = s.setAudio(ctx, &sample, v); err != nil {
+		return sample, err
+	}
+
+	return sample, nil
+}
+
+// GetVersionKey - ключ аудио конкретной версии семпла
+func (s *Service) GetVersionKey(ctx context.Context, sampleID uuid.UUID, version int) (string, error) {
+	v, err := s.GetVersion(ctx, sampleID, version)
+	if err != nil {
+		return "", err
+	}
+
+	return v.MinioKey, nil
+}
+
+func (s *Service) GetVersion(ctx context.Context, sampleID uuid.UUID, version int) (entity.SampleVersion, error) {
+	v, err := s.versionRepo.GetByVersion(ctx, sampleID, version)
+	if err != nil {
+		return v, fmt.Errorf("failed to get sample version: %w", err)
+	}
+
+	return v, nil
+}
+
+// setAudio делает версию текущей. Опубликованный семпл с новым аудио сначала уходит на модерацию,
+// чтобы непроверенный файл не попал в каталог
+func (s *Service) setAudio(ctx context.Context, sample *entity.Sample, version entity.SampleVersion) error {
+	if sample.Status == constant.SampleStatusPublished && version.Hash != sample.AudioHash {
+		now := time.Now()
+		if err := s.sampleRepo.Resubmit(ctx, sample.ID, now); err != nil {
+			return err
+		}
+		sample.Status = constant.SampleStatusPending
+		sample.SubmittedAt = &now
+		sample.ReviewedAt = nil
+		sample.RejectReason = ""
+	}
+
+	applyVersion(sample, version)
+	if err := s.sampleRepo.Update(ctx, *sample); err != nil {
+		return fmt.Errorf("failed to update sample: %w", err)
+	}
+
+	return nil
+}
+
+func applyVersion(sample *entity.Sample, version entity.SampleVersion) {
+	sample.AudioVersion = version.Version
+	sample.AudioHash = version.Hash
+	sample.MinioKey = version.MinioKey
+	sample.OriginalFilename = version.OriginalFilename
+	sample.Size = version.Size
+	sample.Duration = version.Duration
+	sample.UpdatedAt = time.Now()
+}
+
+// MigrateLegacyKeys переносит объекты с ключами старого формата (sample_<unix>_<title>.wav)
+// в раскладку samples/<id>/<version>/<hash>.<ext> и возвращает количество перенесенных семплов
+func (s *Service) MigrateLegacyKeys(ctx context.Context) (int, error) {
+	// черновики, семплы на модерации, снятые и удаленные тоже переносятся
+	samples, err := s.sampleRepo.GetLegacyKeyed(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get samples: %w", err)
+	}
+
+	migrated := 0
+	for _, sample := range samples {
+		if !isLegacyKey(sample.MinioKey) {
+			continue
+		}
+
+		if err := s.migrateLegacyKey(ctx, sample); err != nil {
+			return migrated, fmt.Errorf("failed to migrate sample %s: %w", sample.ID, err)
+		}
+		migrated++
+	}
+
+	return migrated, nil
+}
+
+func (s *Service) migrateLegacyKey(ctx context.Context, sample entity.Sample) error {
+	legacyKey := sample.MinioKey
+
+	tmp, err := os.CreateTemp("", constant.MigrateTempPattern)
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	_ = tmp.Close()
+	defer os.Remove(tmpPath)
+
+	err = s.fileRepo.DownloadFile(ctx, BucketName, legacyKey, tmpPath)
+	if errors.Is(err, domain.ErrNotFound) {
+		// Раньше ключ выдавался при создании семпла, так что у части семплов аудио так и не загрузили
+		sample.MinioKey = ""
+		sample.AudioVersion = 0
+		sample.AudioHash = ""
+		return s.sampleRepo.Update(ctx, sample)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to download legacy file: %w", err)
+	}
+
+	hash, err := hashFile(tmpPath)
+	if err != nil {
+		return err
+	}
+
+	version := entity.SampleVersion{
+		SampleID:         sample.ID,
+		Version:          1,
+		MinioKey:         ObjectKey(sample.ID, 1, hash, fileExt(legacyKey)),
+		Hash:             hash,
+		OriginalFilename: SanitizeFilename(legacyKey),
+		Size:             sample.Size,
+		Duration:         sample.Duration,
+		UploadedAt:       sample.UpdatedAt,
+	}
+
+	if err := s.fileRepo.UploadFile(ctx, BucketName, version.MinioKey, tmpPath); err != nil {
 		return fmt.Errorf("failed to upload file: %w", err)
 	}
 
+	if _, err := s.versionRepo.Create(ctx, version); err != nil {
+		return fmt.Errorf("failed to create sample version: %w", err)
+	}
+
+	applyVersion(&sample, version)
+	if err := s.sampleRepo.Update(ctx, sample); err != nil {
+		return fmt.Errorf("failed to update sample: %w", err)
+	}
+
+	if err := s.fileRepo.DeleteFile(ctx, BucketName, legacyKey); err != nil {
+		slog.Warn("failed to delete legacy file", slog.String("key", legacyKey), slog.String("err", err.Error()))
+	}
+
 	return nil
 }
 
+// GetSample возвращает семпл, в том числе из корзины - купленные семплы остаются доступны покупателям
 func (s *Service) GetSample(ctx context.Context, id uuid.UUID) (entity.Sample, error) {
 	sample, err := s.sampleRepo.GetByID(ctx, id)
 	if errors.Is(err, domain.ErrNotFound) {
@@ -143,8 +467,15 @@ func (s *Service) GetSample(ctx context.Context, id uuid.UUID) (entity.Sample, e
 	return sample, nil
 }
 
-func (s *Service) GetSamples(ctx context.Context) ([]entity.Sample, error) {
-	samples, err := s.sampleRepo.GetAll(ctx)
+// GetSamples возвращает каталог, filter.Sort - constant.SortNew или constant.SortPopular.
+// Жанр и теги фильтра принимаются в любом написании
+func (s *Service) GetSamples(ctx context.Context, filter entity.SampleFilter) ([]entity.Sample, error) {
+	filter.Genre = slug.Make(filter.Genre)
+	filter.Tag = slug.Make(filter.Tag)
+	filter.Instrument = slug.Make(filter.Instrument)
+	filter.Mood = slug.Make(filter.Mood)
+
+	samples, err := s.sampleRepo.GetAll(ctx, filter)
 	if errors.Is(err, domain.ErrNotFound) {
 		return nil, err
 	}
@@ -155,69 +486,322 @@ func (s *Service) GetSamples(ctx context.Context) ([]entity.Sample, error) {
 	return samples, nil
 }
 
-func (s *Service) UpdateSample(ctx context.Context, id uuid.UUID, packID *uuid.UUID, title, author, description, genre *string, price *int, size *int64, duration *float64) (entity.Sample, error) {
+// UpdateSample обновляет семпл. Изменять может автор или админ, сам автор не меняется
+func (s *Service) UpdateSample(ctx context.Context, userUUID, id uuid.UUID, title, description, genre *string, tags *entity.TagSet) (entity.Sample, error) {
 	existing, err := s.sampleRepo.GetByID(ctx, id)
-	if errors.Is(err, domain.ErrNotFound) {
-		return existing, err
+	if errors.Is(err, domain.ErrNotFound) || existing.DeletedAt != nil {
+		return existing, domain.ErrNotFound
 	}
 	if err != nil {
 		return existing, fmt.Errorf("failed to get sample by id: %w", err)
 	}
 
+	if err = s.checkManage(ctx, userUUID, existing.AuthorUUID); err != nil {
+		return existing, err
+	}
+
 	if title != nil {
 		existing.Title = *title
 	}
-	if author != nil {
-		existing.Author = *author
-	}
 	if description != nil {
 		existing.Description = *description
 	}
 	if genre != nil {
-		existing.Genre = *genre
+		if existing.Genre, err = s.taxonomy.ResolveGenre(ctx, *genre); err != nil {
+			return existing, err
+		}
 	}
-	if packID != nil {
-		if _, err := s.packRepo.GetByID(ctx, *packID); err != nil {
-			return existing, fmt.Errorf("pack not found: %w", err)
+	if tags != nil {
+		if err = s.taxonomy.SetSampleTags(ctx, id, existing.Tags, *tags); err != nil {
+			return existing, err
 		}
-		existing.PackID = packID
 	}
-	if price != nil {
-		existing.Price = *price
+
+	if err = s.sampleRepo.Update(ctx, existing); err != nil {
+		return existing, fmt.Errorf("failed to update sample: %w", err)
 	}
 
-	if size != nil {
-		existing.Size = *size
+	if tags != nil {
+		return s.sampleRepo.GetByID(ctx, id)
 	}
-	if duration != nil {
-		existing.Duration = *duration
+
+	return existing, nil
+}
+
+// SetLicenses заменяет лицензии семпла, пустой список делает семпл бесплатным.
+// Изменять может автор или админ, у проданного эксклюзивно семпла лицензии не меняются
+func (s *Service) SetLicenses(ctx context.Context, userUUID, sampleID uuid.UUID, licenses []entity.SampleLicense) (entity.Sample, error) {
+	sample, err := s.sampleRepo.GetByID(ctx, sampleID)
+	if errors.Is(err, domain.ErrNotFound) || sample.DeletedAt != nil {
+		return sample, domain.ErrNotFound
+	}
+	if err != nil {
+		return sample, fmt.Errorf("failed to get sample by id: %w", err)
 	}
 
-	if err = s.sampleRepo.Update(ctx, existing); err != nil {
-		return existing, fmt.Errorf("failed to update sample: %w", err)
+	if err = s.checkManage(ctx, userUUID, sample.AuthorUUID); err != nil {
+		return sample, err
+	}
+	if sample.DelistedAt != nil {
+		return sample, domain.ErrSampleUnavailable
 	}
 
-	return existing, nil
+	seen := make(map[string]bool, len(licenses))
+	for _, l := range licenses {
+		if _, ok := constant.LicenseTerms[l.Type]; !ok {
+			return sample, fmt.Errorf("%w: неизвестный тип лицензии %q", domain.ErrInvalidLicense, l.Type)
+		}
+		if seen[l.Type] {
+			return sample, fmt.Errorf("%w: лицензия %q указана дважды", domain.ErrInvalidLicense, l.Type)
+		}
+		if l.Price <= 0 {
+			return sample, fmt.Errorf("%w: цена лицензии должна быть больше нуля", domain.ErrInvalidLicense)
+		}
+		seen[l.Type] = true
+	}
+
+	if err = s.sampleRepo.SetLicenses(ctx, sampleID, licenses); err != nil {
+		return sample, fmt.Errorf("failed to set sample licenses: %w", err)
+	}
+
+	sample, err = s.sampleRepo.GetByID(ctx, sampleID)
+	if err != nil {
+		return sample, fmt.Errorf("failed to get sample by id: %w", err)
+	}
+
+	return sample, nil
 }
 
-func (s *Service) DeleteSample(ctx context.Context, id uuid.UUID) error {
+// SubmitSample отправляет черновик на модерацию. Отправить может автор или админ, только с загруженным аудио.
+// publishAt - отложенная публикация, должна быть в будущем
+func (s *Service) SubmitSample(ctx context.Context, userUUID, id uuid.UUID, publishAt *time.Time) (entity.Sample, error) {
 	sample, err := s.sampleRepo.GetByID(ctx, id)
+	if errors.Is(err, domain.ErrNotFound) || sample.DeletedAt != nil {
+		return sample, domain.ErrNotFound
+	}
+	if err != nil {
+		return sample, fmt.Errorf("failed to get sample by id: %w", err)
+	}
+
+	if err = s.checkManage(ctx, userUUID, sample.AuthorUUID); err != nil {
+		return sample, err
+	}
+	if sample.Status != constant.SampleStatusDraft {
+		return sample, fmt.Errorf("%w: на модерацию отправляется только черновик", domain.ErrSampleStatus)
+	}
+	if sample.MinioKey == "" {
+		return sample, fmt.Errorf("%w: сначала загрузите аудио", domain.ErrSampleStatus)
+	}
+
+	now := time.Now()
+	if publishAt != nil {
+		if !publishAt.After(now) {
+			return sample, fmt.Errorf("%w: время публикации должно быть в будущем", domain.ErrInvalidReview)
+		}
+		// publish_at хранится в UTC, каталог сравнивает его с now() AT TIME ZONE 'UTC'
+		utc := publishAt.UTC()
+		publishAt = &utc
+	}
+
+	if err = s.sampleRepo.Submit(ctx, id, publishAt, now); err != nil {
+		return sample, err
+	}
+
+	return s.sampleRepo.GetByID(ctx, id)
+}
+
+// GetPendingSamples возвращает очередь модерации
+func (s *Service) GetPendingSamples(ctx context.Context) ([]entity.Sample, error) {
+	samples, err := s.sampleRepo.GetPending(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending samples: %w", err)
+	}
+
+	return samples, nil
+}
+
+// ApproveSample публикует семпл с модерации, с отложенной публикацией он появится в каталоге в publish_at
+func (s *Service) ApproveSample(ctx context.Context, adminUUID, id uuid.UUID) (entity.Sample, error) {
+	if err := s.sampleRepo.Review(ctx, id, adminUUID, constant.SampleStatusPublished, "", time.Now()); err != nil {
+		return entity.Sample{}, s.reviewError(ctx, id, err)
+	}
+
+	return s.sampleRepo.GetByID(ctx, id)
+}
+
+// RejectSample возвращает семпл автору в черновики с причиной отказа
+func (s *Service) RejectSample(ctx context.Context, adminUUID, id uuid.UUID, reason string) (entity.Sample, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" || len([]rune(reason)) > maxRejectReasonLen {
+		return entity.Sample{}, fmt.Errorf("%w: причина обязательна и не длиннее %d символов", domain.ErrInvalidReview, maxRejectReasonLen)
+	}
+
+	if err := s.sampleRepo.Review(ctx, id, adminUUID, constant.SampleStatusDraft, reason, time.Now()); err != nil {
+		return entity.Sample{}, s.reviewError(ctx, id, err)
+	}
+
+	return s.sampleRepo.GetByID(ctx, id)
+}
+
+// reviewError отличает несуществующий семпл от уже рассмотренного
+func (s *Service) reviewError(ctx context.Context, id uuid.UUID, err error) error {
+	if !errors.Is(err, domain.ErrSampleStatus) {
+		return err
+	}
+
+	if sample, getErr := s.sampleRepo.GetByID(ctx, id); errors.Is(getErr, domain.ErrNotFound) || sample.DeletedAt != nil {
+		return domain.ErrNotFound
+	}
+
+	return fmt.Errorf("%w: семпл не на модерации", err)
+}
+
+// DeleteSample перемещает семпл в корзину. Удалить может автор или админ
+func (s *Service) DeleteSample(ctx context.Context, userUUID, id uuid.UUID) error {
+	sample, err := s.sampleRepo.GetByID(ctx, id)
+	if errors.Is(err, domain.ErrNotFound) || sample.DeletedAt != nil {
+		return domain.ErrNotFound
+	}
 	if err != nil {
 		return fmt.Errorf("failed to delete sample by id: %w", err)
 	}
 
-	if err = s.fileRepo.DeleteFile(ctx, BucketName, sample.MinioKey); err != nil {
-		return fmt.Errorf("failed to delete sample file : %w", err)
+	if err = s.checkManage(ctx, userUUID, sample.AuthorUUID); err != nil {
+		return err
 	}
 
-	if err = s.sampleRepo.Delete(ctx, id); err != nil {
+	if err = s.sampleRepo.SoftDelete(ctx, id, time.Now()); err != nil {
 		return fmt.Errorf("failed to delete sample: %w", err)
 	}
 
 	return nil
 }
 
+// RestoreSample возвращает семпл из корзины, если срок хранения еще не истек
+func (s *Service) RestoreSample(ctx context.Context, userUUID, id uuid.UUID) error {
+	sample, err := s.sampleRepo.GetByID(ctx, id)
+	if errors.Is(err, domain.ErrNotFound) || sample.DeletedAt == nil {
+		return domain.ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get sample: %w", err)
+	}
+
+	if err = s.checkManage(ctx, userUUID, sample.AuthorUUID); err != nil {
+		return err
+	}
+
+	if time.Since(*sample.DeletedAt) > s.trashRetention {
+		return domain.ErrRestoreExpired
+	}
+
+	if err = s.sampleRepo.Restore(ctx, id); err != nil {
+		return fmt.Errorf("failed to restore sample: %w", err)
+	}
+
+	return nil
+}
+
+// GetTrash возвращает содержимое корзины: автору - его семплы и паки, админу - все
+func (s *Service) GetTrash(ctx context.Context, userUUID uuid.UUID) ([]entity.Sample, []entity.Pack, error) {
+	user, err := s.userRepo.GetUserByUUID(ctx, userUUID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	var author *uuid.UUID
+	if user.Role != constant.RoleAdmin {
+		author = &user.UUID
+	}
+
+	samples, err := s.sampleRepo.GetDeleted(ctx, author)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get deleted samples: %w", err)
+	}
+
+	packs, err := s.packRepo.GetDeleted(ctx, author)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get deleted packs: %w", err)
+	}
+
+	return samples, packs, nil
+}
+
+// PurgeTrash окончательно удаляет содержимое корзины старше срока хранения вместе с файлами.
+// Купленные семплы не удаляются никогда, чтобы покупатели могли их скачать
+func (s *Service) PurgeTrash(ctx context.Context) (int, error) {
+	before := time.Now().Add(-s.trashRetention)
+
+	samples, err := s.sampleRepo.GetPurgeable(ctx, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get purgeable samples: %w", err)
+	}
+
+	purged := 0
+	for _, sample := range samples {
+		if err := s.purgeSample(ctx, sample); err != nil {
+			return purged, fmt.Errorf("failed to purge sample %s: %w", sample.ID, err)
+		}
+		purged++
+	}
+
+	covers, err := s.packRepo.DeleteDeletedBefore(ctx, before)
+	if err != nil {
+		return purged, fmt.Errorf("failed to purge packs: %w", err)
+	}
+	for _, key := range covers {
+		s.images.Delete(ctx, coverImage, key)
+	}
+
+	return purged + len(covers), nil
+}
+
+func (s *Service) purgeSample(ctx context.Context, sample entity.Sample) error {
+	versions, err := s.versionRepo.GetBySample(ctx, sample.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get sample versions: %w", err)
+	}
+
+	// сначала удаляем строку: если файл не удалится, его подберет gc,
+	// а ссылка из базы на несуществующий объект сломала бы скачивание
+	if err = s.sampleRepo.Delete(ctx, sample.ID); err != nil {
+		return fmt.Errorf("failed to delete sample: %w", err)
+	}
+
+	for _, v := range versions {
+		if err = s.fileRepo.DeleteFile(ctx, BucketName, v.MinioKey); err != nil {
+			slog.Warn("failed to delete sample file, left for gc",
+				slog.String("key", v.MinioKey), slog.String("err", err.Error()))
+		}
+	}
+
+	return nil
+}
+
+// checkManage - управлять семплами и паками могут их автор и админ
+func (s *Service) checkManage(ctx context.Context, userUUID, authorUUID uuid.UUID) error {
+	if userUUID == authorUUID {
+		return nil
+	}
+
+	user, err := s.userRepo.GetUserByUUID(ctx, userUUID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if user.Role != constant.RoleAdmin {
+		return domain.ErrForbidden
+	}
+
+	return nil
+}
+
 func (s *Service) GetSampleDownloadURL(ctx context.Context, minioKey string) (string, error) {
+	if minioKey == "" {
+		// аудио еще не загружено
+		return "", nil
+	}
+
 	url, err := s.fileRepo.GetFileURL(ctx, BucketName, minioKey)
 	if err != nil {
 		return "", fmt.Errorf("failed to get sample download url: %w", err)
@@ -226,48 +810,68 @@ func (s *Service) GetSampleDownloadURL(ctx context.Context, minioKey string) (st
 	return url, nil
 }
 
-func (s *Service) CreatePack(ctx context.Context, name, description, genre, author string) (uuid.UUID, error) {
-	if _, err := s.userRepo.GetUserByLogin(ctx, author); err != nil {
-		return uuid.Nil, fmt.Errorf("error getting user by login: %w", err)
-	}
-
+// CreatePack создает пак от имени authorUUID - автором всегда становится текущий пользователь
+func (s *Service) CreatePack(ctx context.Context, authorUUID uuid.UUID, name, description, genre string, tags entity.TagSet) (uuid.UUID, error) {
 	if name == "" {
 		return uuid.Nil, fmt.Errorf("name is empty")
 	}
 	if description == "" {
 		return uuid.Nil, fmt.Errorf("description is empty")
 	}
-	if genre == "" {
-		return uuid.Nil, fmt.Errorf("genre is empty")
+	genre, err := s.taxonomy.ResolveGenre(ctx, genre)
+	if err != nil {
+		return uuid.Nil, err
 	}
-	if author == "" {
-		return uuid.Nil, fmt.Errorf("author is empty")
+	if err = s.taxonomy.ValidateTags(ctx, tags); err != nil {
+		return uuid.Nil, err
 	}
 
-	return s.packRepo.Create(ctx, entity.Pack{
+	packID, err := s.packRepo.Create(ctx, entity.Pack{
 		Name:        name,
 		Description: description,
 		Genre:       genre,
-		Author:      author,
+		AuthorUUID:  authorUUID,
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
 	})
+	if err != nil {
+		return packID, err
+	}
+
+	if err = s.taxonomy.SetPackTags(ctx, packID, nil, tags); err != nil {
+		return packID, fmt.Errorf("failed to set pack tags: %w", err)
+	}
+
+	return packID, nil
 }
 
 func (s *Service) GetPack(ctx context.Context, id uuid.UUID) (entity.Pack, error) {
-	return s.packRepo.GetByID(ctx, id)
+	pack, err := s.packRepo.GetByID(ctx, id)
+	if err != nil {
+		return pack, err
+	}
+	if pack.DeletedAt != nil {
+		return pack, domain.ErrNotFound
+	}
+
+	return pack, nil
 }
 
 func (s *Service) GetAllPacks(ctx context.Context) ([]entity.Pack, error) {
 	return s.packRepo.GetAll(ctx)
 }
 
-func (s *Service) UpdatePack(ctx context.Context, id uuid.UUID, name, description, genre *string) error {
-	pack, err := s.packRepo.GetByID(ctx, id)
+// UpdatePack обновляет пак. Изменять может автор или админ
+func (s *Service) UpdatePack(ctx context.Context, userUUID, id uuid.UUID, name, description, genre *string, tags *entity.TagSet) error {
+	pack, err := s.GetPack(ctx, id)
 	if err != nil {
 		return fmt.Errorf("failed get pack by id to update it: %w", err)
 	}
 
+	if err = s.checkManage(ctx, userUUID, pack.AuthorUUID); err != nil {
+		return err
+	}
+
 	if name != nil {
 		pack.Name = *name
 	}
@@ -275,7 +879,14 @@ func (s *Service) UpdatePack(ctx context.Context, id uuid.UUID, name, descriptio
 		pack.Description = *description
 	}
 	if genre != nil {
-		pack.Genre = *genre
+		if pack.Genre, err = s.taxonomy.ResolveGenre(ctx, *genre); err != nil {
+			return err
+		}
+	}
+	if tags != nil {
+		if err = s.taxonomy.SetPackTags(ctx, id, pack.Tags, *tags); err != nil {
+			return err
+		}
 	}
 
 	err = s.packRepo.Update(ctx, pack)
@@ -286,15 +897,109 @@ func (s *Service) UpdatePack(ctx context.Context, id uuid.UUID, name, descriptio
 	return nil
 }
 
-func (s *Service) DeletePack(ctx context.Context, id uuid.UUID) error {
-	return s.packRepo.Delete(ctx, id)
+// SetPackCover загружает обложку пака из файла filePath и удаляет предыдущую. Менять может автор или админ
+func (s *Service) SetPackCover(ctx context.Context, userUUID, id uuid.UUID, filePath string) (entity.Pack, error) {
+	pack, err := s.GetPack(ctx, id)
+	if err != nil {
+		return pack, err
+	}
+
+	if err = s.checkManage(ctx, userUUID, pack.AuthorUUID); err != nil {
+		return pack, err
+	}
+
+	key, err := s.images.Upload(ctx, coverImage, pack.ID, filePath)
+	if err != nil {
+		return pack, err
+	}
+
+	oldKey, err := s.packRepo.SetCover(ctx, id, key)
+	if err != nil {
+		s.images.Delete(ctx, coverImage, key)
+		return pack, err
+	}
+	s.images.Delete(ctx, coverImage, oldKey)
+
+	pack.CoverKey = key
+	return pack, nil
+}
+
+// RemovePackCover убирает обложку пака
+func (s *Service) RemovePackCover(ctx context.Context, userUUID, id uuid.UUID) error {
+	pack, err := s.GetPack(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err = s.checkManage(ctx, userUUID, pack.AuthorUUID); err != nil {
+		return err
+	}
+
+	oldKey, err := s.packRepo.SetCover(ctx, id, "")
+	if err != nil {
+		return err
+	}
+	s.images.Delete(ctx, coverImage, oldKey)
+
+	return nil
+}
+
+// PackCoverURLs - ссылки на миниатюры обложки, пустые, если обложки нет
+func (s *Service) PackCoverURLs(ctx context.Context, pack entity.Pack) (entity.ImageURLs, error) {
+	return s.images.URLs(ctx, coverImage, pack.CoverKey)
+}
+
+// DeletePack перемещает пак в корзину. Семплы пака остаются в каталоге
+func (s *Service) DeletePack(ctx context.Context, userUUID, id uuid.UUID) error {
+	pack, err := s.GetPack(ctx, id)
+	if errors.Is(err, domain.ErrNotFound) {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get pack: %w", err)
+	}
+
+	if err = s.checkManage(ctx, userUUID, pack.AuthorUUID); err != nil {
+		return err
+	}
+
+	if err = s.packRepo.SoftDelete(ctx, id, time.Now()); err != nil {
+		return fmt.Errorf("failed to delete pack: %w", err)
+	}
+
+	return nil
+}
+
+// RestorePack возвращает из корзины пак и семплы, удаленные вместе с ним
+func (s *Service) RestorePack(ctx context.Context, userUUID, id uuid.UUID) error {
+	pack, err := s.packRepo.GetByID(ctx, id)
+	if errors.Is(err, domain.ErrNotFound) || pack.DeletedAt == nil {
+		return domain.ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get pack: %w", err)
+	}
+
+	if err = s.checkManage(ctx, userUUID, pack.AuthorUUID); err != nil {
+		return err
+	}
+
+	if time.Since(*pack.DeletedAt) > s.trashRetention {
+		return domain.ErrRestoreExpired
+	}
+
+	if err = s.packRepo.Restore(ctx, id); err != nil {
+		return fmt.Errorf("failed to restore pack: %w", err)
+	}
+
+	return nil
 }
 
 func (s *Service) GetPackWithSamples(ctx context.Context, id uuid.UUID) (entity.Pack, []entity.Sample, error) {
 	var pack entity.Pack
 	var samples []entity.Sample
 
-	pack, err := s.packRepo.GetByID(ctx, id)
+	pack, err := s.GetPack(ctx, id)
 	if err != nil {
 		return pack, samples, fmt.Errorf("failed to get pack: %w", err)
 	}
@@ -306,3 +1011,95 @@ func (s *Service) GetPackWithSamples(ctx context.Context, id uuid.UUID) (entity.
 
 	return pack, samples, nil
 }
+
+// AddPackSample добавляет семпл последним треком пака. Нужны права и на пак, и на семпл,
+// чтобы нельзя было собрать пак из чужих семплов
+func (s *Service) AddPackSample(ctx context.Context, userUUID, id, sampleID uuid.UUID) error {
+	if _, err := s.getManagedPack(ctx, userUUID, id); err != nil {
+		return err
+	}
+
+	sample, err := s.sampleRepo.GetByID(ctx, sampleID)
+	if errors.Is(err, domain.ErrNotFound) || sample.DeletedAt != nil {
+		return fmt.Errorf("%w: sample not found", domain.ErrInvalidPack)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get sample: %w", err)
+	}
+
+	if err = s.checkManage(ctx, userUUID, sample.AuthorUUID); err != nil {
+		return err
+	}
+
+	if err = s.packRepo.AddSample(ctx, id, sampleID, time.Now()); err != nil {
+		return fmt.Errorf("failed to add sample to pack: %w", err)
+	}
+
+	return nil
+}
+
+// RemovePackSample убирает семпл из пака, сам семпл остается в каталоге
+func (s *Service) RemovePackSample(ctx context.Context, userUUID, id, sampleID uuid.UUID) error {
+	if _, err := s.getManagedPack(ctx, userUUID, id); err != nil {
+		return err
+	}
+
+	err := s.packRepo.RemoveSample(ctx, id, sampleID, time.Now())
+	if errors.Is(err, domain.ErrNotFound) {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("failed to remove sample from pack: %w", err)
+	}
+
+	return nil
+}
+
+// ReorderPack задает порядок треков. sampleIDs должен содержать ровно те семплы, что уже есть в паке
+func (s *Service) ReorderPack(ctx context.Context, userUUID, id uuid.UUID, sampleIDs []uuid.UUID) error {
+	if _, err := s.getManagedPack(ctx, userUUID, id); err != nil {
+		return err
+	}
+
+	current, err := s.packRepo.SampleIDs(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get pack samples: %w", err)
+	}
+
+	if len(sampleIDs) != len(current) {
+		return fmt.Errorf("%w: order must list all %d samples of the pack", domain.ErrInvalidPack, len(current))
+	}
+	inPack := make(map[uuid.UUID]bool, len(current))
+	for _, sampleID := range current {
+		inPack[sampleID] = true
+	}
+	for _, sampleID := range sampleIDs {
+		if !inPack[sampleID] {
+			return fmt.Errorf("%w: sample %s is not in the pack or listed twice", domain.ErrInvalidPack, sampleID)
+		}
+		delete(inPack, sampleID)
+	}
+
+	if err = s.packRepo.Reorder(ctx, id, sampleIDs, time.Now()); err != nil {
+		return fmt.Errorf("failed to reorder pack: %w", err)
+	}
+
+	return nil
+}
+
+// getManagedPack - неудаленный пак, который может менять пользователь
+func (s *Service) getManagedPack(ctx context.Context, userUUID, id uuid.UUID) (entity.Pack, error) {
+	pack, err := s.GetPack(ctx, id)
+	if errors.Is(err, domain.ErrNotFound) {
+		return pack, err
+	}
+	if err != nil {
+		return pack, fmt.Errorf("failed to get pack: %w", err)
+	}
+
+	if err = s.checkManage(ctx, userUUID, pack.AuthorUUID); err != nil {
+		return pack, err
+	}
+
+	return pack, nil
+}
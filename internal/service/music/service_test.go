@@ -0,0 +1,723 @@
+package music
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/musicman-backend/internal/domain"
+	"github.com/musicman-backend/internal/domain/constant"
+	"github.com/musicman-backend/internal/domain/entity"
+)
+
+// fakeSamples повторяет условия запросов репозитория: Update пишет только текстовые поля и аудио,
+// статус меняют Submit, Resubmit и Review
+type fakeSamples struct {
+	SampleRepository
+	samples   map[uuid.UUID]entity.Sample
+	purchased map[uuid.UUID]bool
+}
+
+func (f *fakeSamples) add(sample entity.Sample) entity.Sample {
+	if sample.ID == uuid.Nil {
+		sample.ID = uuid.New()
+	}
+	f.samples[sample.ID] = sample
+	return sample
+}
+
+func (f *fakeSamples) Create(_ context.Context, sample entity.Sample, _ []uuid.UUID) (uuid.UUID, error) {
+	return f.add(sample).ID, nil
+}
+
+func (f *fakeSamples) GetByID(_ context.Context, id uuid.UUID) (entity.Sample, error) {
+	sample, ok := f.samples[id]
+	if !ok {
+		return entity.Sample{}, domain.ErrNotFound
+	}
+	return sample, nil
+}
+
+func (f *fakeSamples) Update(_ context.Context, sample entity.Sample) error {
+	stored := f.samples[sample.ID]
+	stored.Title, stored.Description, stored.Genre = sample.Title, sample.Description, sample.Genre
+	stored.Duration, stored.Size, stored.MinioKey = sample.Duration, sample.Size, sample.MinioKey
+	stored.AudioVersion, stored.AudioHash, stored.OriginalFilename = sample.AudioVersion, sample.AudioHash, sample.OriginalFilename
+	stored.UpdatedAt = sample.UpdatedAt
+	f.samples[sample.ID] = stored
+	return nil
+}
+
+func (f *fakeSamples) Submit(_ context.Context, id uuid.UUID, publishAt *time.Time, submittedAt time.Time) error {
+	sample, ok := f.samples[id]
+	if !ok || sample.Status != constant.SampleStatusDraft || sample.DeletedAt != nil {
+		return domain.ErrSampleStatus
+	}
+	sample.Status, sample.PublishAt, sample.SubmittedAt = constant.SampleStatusPending, publishAt, &submittedAt
+	sample.ReviewedAt, sample.RejectReason = nil, ""
+	f.samples[id] = sample
+	return nil
+}
+
+func (f *fakeSamples) Resubmit(_ context.Context, id uuid.UUID, submittedAt time.Time) error {
+	sample, ok := f.samples[id]
+	if ok && sample.Status == constant.SampleStatusPublished && sample.DeletedAt == nil {
+		sample.Status, sample.SubmittedAt = constant.SampleStatusPending, &submittedAt
+		sample.ReviewedAt, sample.RejectReason = nil, ""
+		f.samples[id] = sample
+	}
+	return nil
+}
+
+func (f *fakeSamples) Review(_ context.Context, id, _ uuid.UUID, status, reason string, reviewedAt time.Time) error {
+	sample, ok := f.samples[id]
+	if !ok || sample.Status != constant.SampleStatusPending {
+		return domain.ErrSampleStatus
+	}
+	sample.Status, sample.RejectReason, sample.ReviewedAt = status, reason, &reviewedAt
+	f.samples[id] = sample
+	return nil
+}
+
+func (f *fakeSamples) SoftDelete(_ context.Context, id uuid.UUID, deletedAt time.Time) error {
+	sample := f.samples[id]
+	sample.DeletedAt = &deletedAt
+	f.samples[id] = sample
+	return nil
+}
+
+func (f *fakeSamples) Restore(_ context.Context, id uuid.UUID) error {
+	sample := f.samples[id]
+	sample.DeletedAt = nil
+	f.samples[id] = sample
+	return nil
+}
+
+func (f *fakeSamples) GetDeleted(_ context.Context, authorUUID *uuid.UUID) ([]entity.Sample, error) {
+	var samples []entity.Sample
+	for _, sample := range f.samples {
+		if sample.DeletedAt != nil && (authorUUID == nil || sample.AuthorUUID == *authorUUID) {
+			samples = append(samples, sample)
+		}
+	}
+	return samples, nil
+}
+
+func (f *fakeSamples) GetPurgeable(_ context.Context, before time.Time) ([]entity.Sample, error) {
+	var samples []entity.Sample
+	for _, sample := range f.samples {
+		if sample.DeletedAt != nil && sample.DeletedAt.Before(before) && !f.purchased[sample.ID] {
+			samples = append(samples, sample)
+		}
+	}
+	return samples, nil
+}
+
+func (f *fakeSamples) Delete(_ context.Context, id uuid.UUID) error {
+	delete(f.samples, id)
+	return nil
+}
+
+type fakeVersions struct {
+	versions []entity.SampleVersion
+}
+
+func (f *fakeVersions) Create(_ context.Context, version entity.SampleVersion) (uuid.UUID, error) {
+	version.ID = uuid.New()
+	f.versions = append(f.versions, version)
+	return version.ID, nil
+}
+
+func (f *fakeVersions) GetBySample(_ context.Context, sampleID uuid.UUID) ([]entity.SampleVersion, error) {
+	var versions []entity.SampleVersion
+	for _, v := range f.versions {
+		if v.SampleID == sampleID {
+			versions = append(versions, v)
+		}
+	}
+	return versions, nil
+}
+
+func (f *fakeVersions) GetByVersion(_ context.Context, sampleID uuid.UUID, version int) (entity.SampleVersion, error) {
+	for _, v := range f.versions {
+		if v.SampleID == sampleID && v.Version == version {
+			return v, nil
+		}
+	}
+	return entity.SampleVersion{}, domain.ErrNotFound
+}
+
+// fakeFiles - ключи объектов в бакете samples
+type fakeFiles struct {
+	FileRepository
+	objects map[string]bool
+}
+
+func (f *fakeFiles) CreateBucketIfNotExists(context.Context, string) error {
+	return nil
+}
+
+func (f *fakeFiles) UploadFile(_ context.Context, _, objectName, _ string) error {
+	f.objects[objectName] = true
+	return nil
+}
+
+func (f *fakeFiles) DeleteFile(_ context.Context, _, objectName string) error {
+	delete(f.objects, objectName)
+	return nil
+}
+
+// fakePacks хранит треки пака по позициям вместе с семплами из корзины, как pack_samples
+type fakePacks struct {
+	PackRepository
+	packs   map[uuid.UUID]entity.Pack
+	tracks  map[uuid.UUID][]uuid.UUID
+	samples *fakeSamples
+}
+
+func (f *fakePacks) Create(_ context.Context, pack entity.Pack) (uuid.UUID, error) {
+	pack.ID = uuid.New()
+	f.packs[pack.ID] = pack
+	return pack.ID, nil
+}
+
+func (f *fakePacks) GetByID(_ context.Context, id uuid.UUID) (entity.Pack, error) {
+	pack, ok := f.packs[id]
+	if !ok {
+		return pack, domain.ErrNotFound
+	}
+	return pack, nil
+}
+
+func (f *fakePacks) SampleIDs(_ context.Context, packID uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	for _, id := range f.tracks[packID] {
+		if f.samples.samples[id].DeletedAt == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// Reorder нумерует все треки: перечисленные по порядку, остальные после них в прежнем порядке
+func (f *fakePacks) Reorder(_ context.Context, packID uuid.UUID, sampleIDs []uuid.UUID, _ time.Time) error {
+	tracks := slices.Clone(sampleIDs)
+	for _, id := range f.tracks[packID] {
+		if !slices.Contains(sampleIDs, id) {
+			tracks = append(tracks, id)
+		}
+	}
+	f.tracks[packID] = tracks
+	return nil
+}
+
+func (f *fakePacks) GetDeleted(_ context.Context, authorUUID *uuid.UUID) ([]entity.Pack, error) {
+	var packs []entity.Pack
+	for _, pack := range f.packs {
+		if pack.DeletedAt != nil && (authorUUID == nil || pack.AuthorUUID == *authorUUID) {
+			packs = append(packs, pack)
+		}
+	}
+	return packs, nil
+}
+
+func (f *fakePacks) DeleteDeletedBefore(_ context.Context, before time.Time) ([]string, error) {
+	var covers []string
+	for id, pack := range f.packs {
+		if pack.DeletedAt != nil && pack.DeletedAt.Before(before) {
+			covers = append(covers, pack.CoverKey)
+			delete(f.packs, id)
+		}
+	}
+	return covers, nil
+}
+
+type fakeImages struct {
+	Images
+	deleted []string
+}
+
+func (f *fakeImages) Delete(_ context.Context, _ entity.ImageSpec, key string) {
+	if key != "" {
+		f.deleted = append(f.deleted, key)
+	}
+}
+
+// fakeTaxonomy принимает любой жанр и теги
+type fakeTaxonomy struct {
+	Taxonomy
+}
+
+func (fakeTaxonomy) ResolveGenre(_ context.Context, genre string) (entity.Genre, error) {
+	return entity.Genre(genre), nil
+}
+
+func (fakeTaxonomy) ValidateTags(context.Context, entity.TagSet) error { return nil }
+
+func (fakeTaxonomy) ResolveTags(context.Context, entity.TagSet) ([]uuid.UUID, error) { return nil, nil }
+
+func (fakeTaxonomy) SetPackTags(context.Context, uuid.UUID, []entity.Tag, entity.TagSet) error {
+	return nil
+}
+
+type fakeUsers map[uuid.UUID]entity.User
+
+func (f fakeUsers) GetUserByUUID(_ context.Context, userUUID uuid.UUID) (entity.User, error) {
+	user, ok := f[userUUID]
+	if !ok {
+		return entity.User{}, domain.ErrNotFound
+	}
+	return user, nil
+}
+
+const trashRetention = 30 * 24 * time.Hour
+
+type testEnv struct {
+	service  *Service
+	samples  *fakeSamples
+	versions *fakeVersions
+	files    *fakeFiles
+	packs    *fakePacks
+	images   *fakeImages
+	author   uuid.UUID
+	admin    uuid.UUID
+	stranger uuid.UUID
+}
+
+func newTestEnv() *testEnv {
+	env := &testEnv{
+		samples:  &fakeSamples{samples: map[uuid.UUID]entity.Sample{}, purchased: map[uuid.UUID]bool{}},
+		packs:    &fakePacks{packs: map[uuid.UUID]entity.Pack{}, tracks: map[uuid.UUID][]uuid.UUID{}},
+		images:   &fakeImages{},
+		versions: &fakeVersions{},
+		files:    &fakeFiles{objects: map[string]bool{}},
+		author:   uuid.New(),
+		admin:    uuid.New(),
+		stranger: uuid.New(),
+	}
+	env.packs.samples = env.samples
+	users := fakeUsers{
+		env.author:   {UUID: env.author, Role: constant.RoleUser},
+		env.admin:    {UUID: env.admin, Role: constant.RoleAdmin},
+		env.stranger: {UUID: env.stranger, Role: constant.RoleUser},
+	}
+	env.service = New(env.samples, env.versions, env.packs, env.files, users, fakeTaxonomy{}, env.images, trashRetention)
+	return env
+}
+
+func audioFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "kick.wav")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestUploadAudioCreatesVersions(t *testing.T) {
+	env := newTestEnv()
+	ctx := context.Background()
+	sample := env.samples.add(entity.Sample{AuthorUUID: env.author, Status: constant.SampleStatusDraft})
+
+	first, err := env.service.UploadAudio(ctx, env.author, sample.ID, audioFile(t, "first"), "Kick.WAV", 5, 1.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.AudioVersion != 1 || first.MinioKey != ObjectKey(sample.ID, 1, first.AudioHash, ".wav") {
+		t.Fatalf("first upload is version %d with key %q", first.AudioVersion, first.MinioKey)
+	}
+
+	same, err := env.service.UploadAudio(ctx, env.author, sample.ID, audioFile(t, "first"), "kick.wav", 5, 1.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if same.AudioVersion != 1 || len(env.versions.versions) != 1 {
+		t.Fatalf("same file created version %d, %d versions stored", same.AudioVersion, len(env.versions.versions))
+	}
+
+	second, err := env.service.UploadAudio(ctx, env.admin, sample.ID, audioFile(t, "second"), "kick.wav", 6, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.AudioVersion != 2 || !env.files.objects[first.MinioKey] || !env.files.objects[second.MinioKey] {
+		t.Fatalf("second upload is version %d, objects %v: previous version must stay in storage", second.AudioVersion, env.files.objects)
+	}
+}
+
+func TestUploadAudioForbiddenForStranger(t *testing.T) {
+	env := newTestEnv()
+	sample := env.samples.add(entity.Sample{AuthorUUID: env.author, Status: constant.SampleStatusDraft})
+
+	_, err := env.service.UploadAudio(context.Background(), env.stranger, sample.ID, audioFile(t, "audio"), "kick.wav", 5, 1)
+	if !errors.Is(err, domain.ErrForbidden) {
+		t.Fatalf("got %v, want ErrForbidden", err)
+	}
+	if len(env.files.objects) != 0 {
+		t.Fatalf("stranger uploaded %v", env.files.objects)
+	}
+}
+
+func TestRollbackSample(t *testing.T) {
+	env := newTestEnv()
+	ctx := context.Background()
+	sample := env.samples.add(entity.Sample{AuthorUUID: env.author, Status: constant.SampleStatusDraft})
+
+	first, err := env.service.UploadAudio(ctx, env.author, sample.ID, audioFile(t, "first"), "kick.wav", 5, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = env.service.UploadAudio(ctx, env.author, sample.ID, audioFile(t, "second"), "kick.wav", 6, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = env.service.RollbackSample(ctx, env.stranger, sample.ID, 1); !errors.Is(err, domain.ErrForbidden) {
+		t.Fatalf("stranger rollback: got %v, want ErrForbidden", err)
+	}
+	if _, err = env.service.RollbackSample(ctx, env.author, sample.ID, 3); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("unknown version: got %v, want ErrNotFound", err)
+	}
+
+	// админ откатывает так же, как и автор
+	rolled, err := env.service.RollbackSample(ctx, env.admin, sample.ID, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored := env.samples.samples[sample.ID]
+	if rolled.AudioVersion != 1 || stored.MinioKey != first.MinioKey || stored.AudioHash != first.AudioHash || stored.Size != 5 {
+		t.Fatalf("after rollback stored sample is version %d with key %q, want version 1", stored.AudioVersion, stored.MinioKey)
+	}
+}
+
+func TestRollbackDeletedSample(t *testing.T) {
+	env := newTestEnv()
+	deletedAt := time.Now()
+	sample := env.samples.add(entity.Sample{AuthorUUID: env.author, DeletedAt: &deletedAt})
+
+	if _, err := env.service.RollbackSample(context.Background(), env.author, sample.ID, 1); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("got %v, want ErrNotFound", err)
+	}
+}
+
+func TestDeleteAndRestoreSample(t *testing.T) {
+	env := newTestEnv()
+	ctx := context.Background()
+	sample := env.samples.add(entity.Sample{AuthorUUID: env.author, Status: constant.SampleStatusPublished})
+
+	if err := env.service.DeleteSample(ctx, env.stranger, sample.ID); !errors.Is(err, domain.ErrForbidden) {
+		t.Fatalf("stranger delete: got %v, want ErrForbidden", err)
+	}
+	if err := env.service.DeleteSample(ctx, env.author, sample.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := env.service.DeleteSample(ctx, env.author, sample.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("second delete: got %v, want ErrNotFound", err)
+	}
+
+	trash, _, err := env.service.GetTrash(ctx, env.author)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trash) != 1 || trash[0].ID != sample.ID {
+		t.Fatalf("author trash %v, want the deleted sample", trash)
+	}
+
+	if err = env.service.RestoreSample(ctx, env.stranger, sample.ID); !errors.Is(err, domain.ErrForbidden) {
+		t.Fatalf("stranger restore: got %v, want ErrForbidden", err)
+	}
+	if err = env.service.RestoreSample(ctx, env.admin, sample.ID); err != nil {
+		t.Fatal(err)
+	}
+	if env.samples.samples[sample.ID].DeletedAt != nil {
+		t.Fatal("sample is still in the trash after restore")
+	}
+	if err = env.service.RestoreSample(ctx, env.author, sample.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("restore of a live sample: got %v, want ErrNotFound", err)
+	}
+}
+
+func TestRestoreExpiredSample(t *testing.T) {
+	env := newTestEnv()
+	deletedAt := time.Now().Add(-trashRetention - time.Hour)
+	sample := env.samples.add(entity.Sample{AuthorUUID: env.author, DeletedAt: &deletedAt})
+
+	if err := env.service.RestoreSample(context.Background(), env.author, sample.ID); !errors.Is(err, domain.ErrRestoreExpired) {
+		t.Fatalf("got %v, want ErrRestoreExpired", err)
+	}
+}
+
+func TestGetTrashScope(t *testing.T) {
+	env := newTestEnv()
+	deletedAt := time.Now()
+	env.samples.add(entity.Sample{AuthorUUID: env.author, DeletedAt: &deletedAt})
+	env.samples.add(entity.Sample{AuthorUUID: env.stranger, DeletedAt: &deletedAt})
+	env.samples.add(entity.Sample{AuthorUUID: env.author})
+
+	own, _, err := env.service.GetTrash(context.Background(), env.author)
+	if err != nil {
+		t.Fatal(err)
+	}
+	all, _, err := env.service.GetTrash(context.Background(), env.admin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(own) != 1 || len(all) != 2 {
+		t.Fatalf("author sees %d and admin %d deleted samples, want 1 and 2", len(own), len(all))
+	}
+}
+
+func TestPurgeTrash(t *testing.T) {
+	env := newTestEnv()
+	ctx := context.Background()
+	expired := time.Now().Add(-trashRetention - time.Hour)
+	recent := time.Now().Add(-time.Hour)
+
+	old := env.samples.add(entity.Sample{AuthorUUID: env.author, DeletedAt: &expired})
+	bought := env.samples.add(entity.Sample{AuthorUUID: env.author, DeletedAt: &expired})
+	fresh := env.samples.add(entity.Sample{AuthorUUID: env.author, DeletedAt: &recent})
+	live := env.samples.add(entity.Sample{AuthorUUID: env.author})
+	env.samples.purchased[bought.ID] = true
+
+	for i, id := range []uuid.UUID{old.ID, old.ID, bought.ID} {
+		key := ObjectKey(id, i+1, "hash", ".wav")
+		env.files.objects[key] = true
+		env.versions.versions = append(env.versions.versions, entity.SampleVersion{SampleID: id, Version: i + 1, MinioKey: key})
+	}
+
+	pack := entity.Pack{ID: uuid.New(), AuthorUUID: env.author, CoverKey: "covers/pack", DeletedAt: &expired}
+	env.packs.packs[pack.ID] = pack
+
+	purged, err := env.service.PurgeTrash(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if purged != 2 {
+		t.Fatalf("purged %d, want the expired sample and pack", purged)
+	}
+
+	if _, ok := env.samples.samples[old.ID]; ok {
+		t.Fatal("expired sample was not purged")
+	}
+	for _, id := range []uuid.UUID{bought.ID, fresh.ID, live.ID} {
+		if _, ok := env.samples.samples[id]; !ok {
+			t.Fatalf("sample %s must survive the purge", id)
+		}
+	}
+	if len(env.files.objects) != 1 || !env.files.objects[ObjectKey(bought.ID, 3, "hash", ".wav")] {
+		t.Fatalf("objects after purge %v, want only the purchased sample audio", env.files.objects)
+	}
+	if len(env.images.deleted) != 1 || env.images.deleted[0] != pack.CoverKey {
+		t.Fatalf("deleted covers %v, want the purged pack cover", env.images.deleted)
+	}
+}
+
+func TestReorderPackWithTrashedSample(t *testing.T) {
+	env := newTestEnv()
+	ctx := context.Background()
+	pack := entity.Pack{ID: uuid.New(), AuthorUUID: env.author}
+	env.packs.packs[pack.ID] = pack
+	a := env.samples.add(entity.Sample{AuthorUUID: env.author, Status: constant.SampleStatusPublished})
+	b := env.samples.add(entity.Sample{AuthorUUID: env.author, Status: constant.SampleStatusPublished})
+	c := env.samples.add(entity.Sample{AuthorUUID: env.author, Status: constant.SampleStatusPublished})
+	env.packs.tracks[pack.ID] = []uuid.UUID{a.ID, b.ID, c.ID}
+
+	if err := env.service.DeleteSample(ctx, env.author, b.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	invalid := map[string][]uuid.UUID{
+		"missing sample": {c.ID},
+		"trashed sample": {c.ID, a.ID, b.ID},
+		"duplicate":      {c.ID, c.ID},
+		"foreign sample": {c.ID, uuid.New()},
+	}
+	for name, order := range invalid {
+		if err := env.service.ReorderPack(ctx, env.author, pack.ID, order); !errors.Is(err, domain.ErrInvalidPack) {
+			t.Fatalf("%s: got %v, want ErrInvalidPack", name, err)
+		}
+	}
+	if err := env.service.ReorderPack(ctx, env.stranger, pack.ID, []uuid.UUID{c.ID, a.ID}); !errors.Is(err, domain.ErrForbidden) {
+		t.Fatalf("stranger reorder: got %v, want ErrForbidden", err)
+	}
+
+	if err := env.service.ReorderPack(ctx, env.author, pack.ID, []uuid.UUID{c.ID, a.ID}); err != nil {
+		t.Fatal(err)
+	}
+	if err := env.service.RestoreSample(ctx, env.author, b.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// восстановленный семпл получает свою позицию после видимых, а не делит ее с одним из них
+	ids, _ := env.packs.SampleIDs(ctx, pack.ID)
+	if want := []uuid.UUID{c.ID, a.ID, b.ID}; !slices.Equal(ids, want) {
+		t.Fatalf("pack order %v, want %v", ids, want)
+	}
+	if err := env.service.ReorderPack(ctx, env.author, pack.ID, []uuid.UUID{b.ID, c.ID, a.ID}); err != nil {
+		t.Fatalf("reorder after restore: %v", err)
+	}
+}
+
+func TestModerationTransitions(t *testing.T) {
+	env := newTestEnv()
+	ctx := context.Background()
+	sample := env.samples.add(entity.Sample{AuthorUUID: env.author, Status: constant.SampleStatusDraft})
+
+	if _, err := env.service.SubmitSample(ctx, env.author, sample.ID, nil); !errors.Is(err, domain.ErrSampleStatus) {
+		t.Fatalf("submit without audio: got %v, want ErrSampleStatus", err)
+	}
+	if _, err := env.service.UploadAudio(ctx, env.author, sample.ID, audioFile(t, "audio"), "kick.wav", 5, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := env.service.SubmitSample(ctx, env.stranger, sample.ID, nil); !errors.Is(err, domain.ErrForbidden) {
+		t.Fatalf("stranger submit: got %v, want ErrForbidden", err)
+	}
+	past := time.Now().Add(-time.Hour)
+	if _, err := env.service.SubmitSample(ctx, env.author, sample.ID, &past); !errors.Is(err, domain.ErrInvalidReview) {
+		t.Fatalf("publish in the past: got %v, want ErrInvalidReview", err)
+	}
+
+	submitted, err := env.service.SubmitSample(ctx, env.author, sample.ID, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if submitted.Status != constant.SampleStatusPending {
+		t.Fatalf("status after submit %q, want pending", submitted.Status)
+	}
+	if _, err = env.service.SubmitSample(ctx, env.author, sample.ID, nil); !errors.Is(err, domain.ErrSampleStatus) {
+		t.Fatalf("second submit: got %v, want ErrSampleStatus", err)
+	}
+
+	if _, err = env.service.RejectSample(ctx, env.admin, sample.ID, "  "); !errors.Is(err, domain.ErrInvalidReview) {
+		t.Fatalf("reject without reason: got %v, want ErrInvalidReview", err)
+	}
+	rejected, err := env.service.RejectSample(ctx, env.admin, sample.ID, "clipping")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rejected.Status != constant.SampleStatusDraft || rejected.RejectReason != "clipping" {
+		t.Fatalf("after reject: status %q, reason %q", rejected.Status, rejected.RejectReason)
+	}
+	if _, err = env.service.ApproveSample(ctx, env.admin, sample.ID); !errors.Is(err, domain.ErrSampleStatus) {
+		t.Fatalf("approve of a draft: got %v, want ErrSampleStatus", err)
+	}
+
+	if _, err = env.service.SubmitSample(ctx, env.author, sample.ID, nil); err != nil {
+		t.Fatal(err)
+	}
+	approved, err := env.service.ApproveSample(ctx, env.admin, sample.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if approved.Status != constant.SampleStatusPublished || approved.RejectReason != "" {
+		t.Fatalf("after approve: status %q, reason %q", approved.Status, approved.RejectReason)
+	}
+	if _, err = env.service.RejectSample(ctx, env.admin, sample.ID, "late"); !errors.Is(err, domain.ErrSampleStatus) {
+		t.Fatalf("reject of a published sample: got %v, want ErrSampleStatus", err)
+	}
+	if _, err = env.service.ApproveSample(ctx, env.admin, uuid.New()); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("approve of an unknown sample: got %v, want ErrNotFound", err)
+	}
+}
+
+func TestAudioChangeResubmitsPublished(t *testing.T) {
+	env := newTestEnv()
+	ctx := context.Background()
+	sample := env.samples.add(entity.Sample{AuthorUUID: env.author, Status: constant.SampleStatusDraft})
+
+	if _, err := env.service.UploadAudio(ctx, env.author, sample.ID, audioFile(t, "first"), "kick.wav", 5, 1); err != nil {
+		t.Fatal(err)
+	}
+	publish := func() {
+		t.Helper()
+		stored := env.samples.samples[sample.ID]
+		if stored.Status == constant.SampleStatusDraft {
+			if _, err := env.service.SubmitSample(ctx, env.author, sample.ID, nil); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if _, err := env.service.ApproveSample(ctx, env.admin, sample.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	publish()
+
+	if _, err := env.service.UploadAudio(ctx, env.author, sample.ID, audioFile(t, "first"), "kick.wav", 5, 1); err != nil {
+		t.Fatal(err)
+	}
+	if status := env.samples.samples[sample.ID].Status; status != constant.SampleStatusPublished {
+		t.Fatalf("same audio moved the sample to %q, want it to stay published", status)
+	}
+
+	uploaded, err := env.service.UploadAudio(ctx, env.author, sample.ID, audioFile(t, "second"), "kick.wav", 6, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored := env.samples.samples[sample.ID]
+	if uploaded.Status != constant.SampleStatusPending || stored.Status != constant.SampleStatusPending || stored.AudioVersion != 2 {
+		t.Fatalf("new audio: returned %q, stored %q version %d, want pending version 2", uploaded.Status, stored.Status, stored.AudioVersion)
+	}
+
+	publish()
+	rolled, err := env.service.RollbackSample(ctx, env.author, sample.ID, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rolled.Status != constant.SampleStatusPending || env.samples.samples[sample.ID].Status != constant.SampleStatusPending {
+		t.Fatalf("rollback of a published sample left it %q, want pending", env.samples.samples[sample.ID].Status)
+	}
+}
+
+func TestCreateTakesAuthorFromCaller(t *testing.T) {
+	env := newTestEnv()
+	ctx := context.Background()
+
+	packID, err := env.service.CreatePack(ctx, env.author, "Drums", "kit", "house", entity.TagSet{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if author := env.packs.packs[packID].AuthorUUID; author != env.author {
+		t.Fatalf("pack author %s, want the caller %s", author, env.author)
+	}
+
+	sampleID, err := env.service.CreateSample(ctx, env.author, "Kick", "punchy", "house", &packID, 0, entity.TagSet{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sample := env.samples.samples[sampleID]
+	if sample.AuthorUUID != env.author || !slices.Equal(sample.PackIDs, []uuid.UUID{packID}) {
+		t.Fatalf("sample author %s in packs %v, want the caller in the new pack", sample.AuthorUUID, sample.PackIDs)
+	}
+
+	if _, err = env.service.CreateSample(ctx, env.stranger, "Snare", "crack", "house", &packID, 0, entity.TagSet{}); !errors.Is(err, domain.ErrForbidden) {
+		t.Fatalf("sample into a foreign pack: got %v, want ErrForbidden", err)
+	}
+	if len(env.samples.samples) != 1 {
+		t.Fatalf("%d samples stored, the forbidden one must not be created", len(env.samples.samples))
+	}
+}
+
+func TestUpdateSampleKeepsAuthor(t *testing.T) {
+	env := newTestEnv()
+	ctx := context.Background()
+	sample := env.samples.add(entity.Sample{AuthorUUID: env.author, Title: "Kick", Status: constant.SampleStatusDraft})
+	title := "Renamed"
+
+	if _, err := env.service.UpdateSample(ctx, env.stranger, sample.ID, &title, nil, nil, nil); !errors.Is(err, domain.ErrForbidden) {
+		t.Fatalf("stranger update: got %v, want ErrForbidden", err)
+	}
+	if env.samples.samples[sample.ID].Title != "Kick" {
+		t.Fatal("forbidden update changed the sample")
+	}
+
+	if _, err := env.service.UpdateSample(ctx, env.admin, sample.ID, &title, nil, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if stored := env.samples.samples[sample.ID]; stored.Title != title || stored.AuthorUUID != env.author {
+		t.Fatalf("after admin update: title %q, author %s, want the author kept", stored.Title, stored.AuthorUUID)
+	}
+}
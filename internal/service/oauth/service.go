@@ -0,0 +1,326 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/musicman-backend/internal/domain"
+	"github.com/musicman-backend/internal/domain/entity"
+	"github.com/musicman-backend/pkg/client/oauth"
+	"github.com/musicman-backend/pkg/username"
+)
+
+const maxLoginAttempts = 10
+
+type Provider interface {
+	AuthCodeURL(state, codeVerifier string) (string, error)
+	Exchange(ctx context.Context, code, codeVerifier string, extra map[string]string) (oauth.Token, error)
+	UserInfo(ctx context.Context, accessToken string) (oauth.UserInfo, error)
+}
+
+type IdentityRepository interface {
+	Create(ctx context.Context, identity entity.UserIdentity) error
+	GetBySubject(ctx context.Context, provider, subject string) (entity.UserIdentity, error)
+	GetByUser(ctx context.Context, userUUID uuid.UUID) ([]entity.UserIdentity, error)
+	Delete(ctx context.Context, userUUID uuid.UUID, provider string) error
+	CreateState(ctx context.Context, state entity.OAuthState) error
+	ConsumeState(ctx context.Context, state string) (entity.OAuthState, error)
+	DeleteExpiredStates(ctx context.Context, before time.Time) (int64, error)
+}
+
+type UserRepository interface {
+	GetUserByUUID(ctx context.Context, userUUID uuid.UUID) (entity.User, error)
+	GetUserByLogin(ctx context.Context, login string) (entity.User, error)
+	GetUserByEmail(ctx context.Context, email string) (entity.User, error)
+	CreateUser(ctx context.Context, login, passHash, email string) (entity.User, error)
+	VerifyEmail(ctx context.Context, userUUID uuid.UUID, email string, verifiedAt time.Time) error
+}
+
+type Tokenizer interface {
+	CreateChallengeToken(ctx context.Context, user entity.User) (string, error)
+}
+
+type Sessions interface {
+	Start(ctx context.Context, user entity.User, client entity.ClientInfo) (string, error)
+}
+
+type Service struct {
+	providers  map[string]Provider
+	identities IdentityRepository
+	users      UserRepository
+	token      Tokenizer
+	sessions   Sessions
+
+	stateTTL time.Duration
+}
+
+func New(providers map[string]Provider, identities IdentityRepository, users UserRepository, token Tokenizer, sessions Sessions, stateTTL time.Duration) *Service {
+	return &Service{
+		providers:  providers,
+		identities: identities,
+		users:      users,
+		token:      token,
+		sessions:   sessions,
+		stateTTL:   stateTTL,
+	}
+}
+
+// Providers - имена настроенных провайдеров
+func (s *Service) Providers() []string {
+	names := make([]string, 0, len(s.providers))
+	for name := range s.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// StartLogin возвращает ссылку на вход у провайдера
+func (s *Service) StartLogin(ctx context.Context, provider string) (string, error) {
+	return s.start(ctx, provider, nil)
+}
+
+// StartLink возвращает ссылку, после входа по которой аккаунт провайдера привяжется к пользователю
+func (s *Service) StartLink(ctx context.Context, provider string, userUUID uuid.UUID) (string, error) {
+	return s.start(ctx, provider, &userUUID)
+}
+
+// Callback завершает вход: меняет код на профиль провайдера, находит или создает пользователя
+// и выдает обычный JWT, а при включенной 2FA - токен второго шага, как и вход по паролю
+func (s *Service) Callback(ctx context.Context, provider, code, state string, extra map[string]string, client entity.ClientInfo) (entity.LoginResult, error) {
+	p, ok := s.providers[provider]
+	if !ok {
+		return entity.LoginResult{}, domain.ErrNotFound
+	}
+
+	st, err := s.identities.ConsumeState(ctx, state)
+	if errors.Is(err, domain.ErrNotFound) {
+		return entity.LoginResult{}, domain.ErrInvalidToken
+	}
+	if err != nil {
+		return entity.LoginResult{}, fmt.Errorf("consume state failed: %w", err)
+	}
+	if st.Provider != provider || time.Now().After(st.ExpiresAt) {
+		return entity.LoginResult{}, domain.ErrInvalidToken
+	}
+
+	token, err := p.Exchange(ctx, code, st.CodeVerifier, extra)
+	if err != nil {
+		return entity.LoginResult{}, fmt.Errorf("%w: %s", domain.ErrProviderFailed, err)
+	}
+
+	info, err := p.UserInfo(ctx, token.AccessToken)
+	if err != nil {
+		return entity.LoginResult{}, fmt.Errorf("%w: %s", domain.ErrProviderFailed, err)
+	}
+
+	identity, err := s.identities.GetBySubject(ctx, provider, info.Subject)
+	found := err == nil
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return entity.LoginResult{}, fmt.Errorf("get identity failed: %w", err)
+	}
+
+	var user entity.User
+	switch {
+	case st.UserUUID != nil:
+		if found && identity.UserUUID != *st.UserUUID {
+			return entity.LoginResult{}, domain.ErrIdentityAlreadyLinked
+		}
+		user, err = s.users.GetUserByUUID(ctx, *st.UserUUID)
+		if err != nil {
+			return entity.LoginResult{}, fmt.Errorf("get user failed: %w", err)
+		}
+	case found:
+		user, err = s.users.GetUserByUUID(ctx, identity.UserUUID)
+		if err != nil {
+			return entity.LoginResult{}, fmt.Errorf("get user failed: %w", err)
+		}
+	default:
+		user, err = s.resolveUser(ctx, provider, info)
+		if err != nil {
+			return entity.LoginResult{}, err
+		}
+	}
+
+	if !found {
+		err = s.identities.Create(ctx, entity.UserIdentity{
+			UserUUID:  user.UUID,
+			Provider:  provider,
+			Subject:   info.Subject,
+			Email:     info.Email,
+			CreatedAt: time.Now(),
+		})
+		if err != nil {
+			return entity.LoginResult{}, err
+		}
+	}
+
+	// при привязке пользователь уже вошел, второй шаг нужен только для входа
+	if user.TwoFactorEnabled() && st.UserUUID == nil {
+		challenge, err := s.token.CreateChallengeToken(ctx, user)
+		if err != nil {
+			return entity.LoginResult{}, err
+		}
+		return entity.LoginResult{ChallengeToken: challenge}, nil
+	}
+
+	jwtToken, err := s.sessions.Start(ctx, user, client)
+	if err != nil {
+		return entity.LoginResult{}, err
+	}
+
+	return entity.LoginResult{Token: jwtToken}, nil
+}
+
+func (s *Service) GetIdentities(ctx context.Context, userUUID uuid.UUID) ([]entity.UserIdentity, error) {
+	identities, err := s.identities.GetByUser(ctx, userUUID)
+	if err != nil {
+		return nil, fmt.Errorf("get identities failed: %w", err)
+	}
+
+	return identities, nil
+}
+
+// Unlink отвязывает провайдера, если у пользователя остается другой способ войти
+func (s *Service) Unlink(ctx context.Context, userUUID uuid.UUID, provider string) error {
+	user, err := s.users.GetUserByUUID(ctx, userUUID)
+	if err != nil {
+		return fmt.Errorf("get user failed: %w", err)
+	}
+
+	identities, err := s.identities.GetByUser(ctx, userUUID)
+	if err != nil {
+		return fmt.Errorf("get identities failed: %w", err)
+	}
+
+	if user.PassHash == "" && len(identities) <= 1 {
+		return domain.ErrLastLoginMethod
+	}
+
+	return s.identities.Delete(ctx, userUUID, provider)
+}
+
+func (s *Service) start(ctx context.Context, provider string, userUUID *uuid.UUID) (string, error) {
+	p, ok := s.providers[provider]
+	if !ok {
+		return "", domain.ErrNotFound
+	}
+
+	state, err := oauth.GenerateVerifier()
+	if err != nil {
+		return "", err
+	}
+	verifier, err := oauth.GenerateVerifier()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+
+	// брошенные входы никто не заберет, чистим их по ходу
+	if _, err = s.identities.DeleteExpiredStates(ctx, now); err != nil {
+		slog.Warn("failed to delete expired oauth states", slog.String("err", err.Error()))
+	}
+
+	err = s.identities.CreateState(ctx, entity.OAuthState{
+		State:        state,
+		Provider:     provider,
+		CodeVerifier: verifier,
+		UserUUID:     userUUID,
+		ExpiresAt:    now.Add(s.stateTTL),
+		CreatedAt:    now,
+	})
+	if err != nil {
+		return "", fmt.Errorf("save state failed: %w", err)
+	}
+
+	return p.AuthCodeURL(state, verifier)
+}
+
+// resolveUser находит пользователя для нового аккаунта провайдера. Привязываем к существующему
+// только если почту подтвердили и провайдер, и мы - иначе так можно было бы захватить чужой аккаунт
+func (s *Service) resolveUser(ctx context.Context, provider string, info oauth.UserInfo) (entity.User, error) {
+	email := ""
+	if info.Email != "" && info.EmailVerified {
+		existing, err := s.users.GetUserByEmail(ctx, info.Email)
+		switch {
+		case err == nil && existing.EmailVerifiedAt != nil:
+			return existing, nil
+		case errors.Is(err, domain.ErrNotFound):
+			email = info.Email
+		case err != nil:
+			return entity.User{}, fmt.Errorf("get user failed: %w", err)
+		}
+	}
+
+	login, err := s.freeLogin(ctx, provider, info)
+	if err != nil {
+		return entity.User{}, err
+	}
+
+	// пароля нет, войти можно только через провайдера, пока не задан пароль через сброс
+	user, err := s.users.CreateUser(ctx, login, "", email)
+	if err != nil {
+		return entity.User{}, fmt.Errorf("create user failed: %w", err)
+	}
+
+	if email != "" {
+		verifiedAt := time.Now()
+		if err = s.users.VerifyEmail(ctx, user.UUID, email, verifiedAt); err != nil {
+			return entity.User{}, fmt.Errorf("verify email failed: %w", err)
+		}
+		user.EmailVerifiedAt = &verifiedAt
+	}
+
+	return user, nil
+}
+
+// freeLogin подбирает свободный логин из логина или почты у провайдера. Логин проходит
+// те же правила, что и при регистрации
+func (s *Service) freeLogin(ctx context.Context, provider string, info oauth.UserInfo) (string, error) {
+	local, _, _ := strings.Cut(info.Email, "@")
+
+	base := username.Sanitize(provider + "_user")
+	for _, candidate := range []string{info.Login, local, provider + "_" + info.Subject} {
+		candidate = username.Sanitize(candidate)
+		if username.Validate(candidate) == nil {
+			base = candidate
+			break
+		}
+	}
+
+	for i := 1; i <= maxLoginAttempts; i++ {
+		login := base
+		if i > 1 {
+			login = withSuffix(base, "_"+strconv.Itoa(i))
+		}
+
+		_, err := s.users.GetUserByLogin(ctx, login)
+		if errors.Is(err, domain.ErrNotFound) {
+			return login, nil
+		}
+		if err != nil {
+			return "", fmt.Errorf("get user failed: %w", err)
+		}
+	}
+
+	return withSuffix(base, "_"+uuid.NewString()[:8]), nil
+}
+
+// withSuffix укорачивает логин так, чтобы с суффиксом он не превысил максимальную длину
+func withSuffix(base, suffix string) string {
+	if len(base)+len(suffix) > username.MaxLength {
+		base = base[:username.MaxLength-len(suffix)]
+	}
+
+	return base + suffix
+}
@@ -0,0 +1,444 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/google/uuid"
+
+	"github.com/musicman-backend/internal/domain"
+	"github.com/musicman-backend/internal/domain/entity"
+	"github.com/musicman-backend/pkg/client/oauth"
+	"github.com/musicman-backend/pkg/client/oauth/oauthtest"
+)
+
+const (
+	providerName = "test"
+	redirectURL  = "http://localhost:3000/oauth/callback"
+)
+
+type fakeIdentities struct {
+	mu         sync.Mutex
+	identities []entity.UserIdentity
+	states     map[string]entity.OAuthState
+}
+
+func (f *fakeIdentities) Create(ctx context.Context, identity entity.UserIdentity) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	identity.ID = uuid.New()
+	f.identities = append(f.identities, identity)
+	return nil
+}
+
+func (f *fakeIdentities) GetBySubject(ctx context.Context, provider, subject string) (entity.UserIdentity, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, identity := range f.identities {
+		if identity.Provider == provider && identity.Subject == subject {
+			return identity, nil
+		}
+	}
+	return entity.UserIdentity{}, domain.ErrNotFound
+}
+
+func (f *fakeIdentities) GetByUser(ctx context.Context, userUUID uuid.UUID) ([]entity.UserIdentity, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var res []entity.UserIdentity
+	for _, identity := range f.identities {
+		if identity.UserUUID == userUUID {
+			res = append(res, identity)
+		}
+	}
+	return res, nil
+}
+
+func (f *fakeIdentities) Delete(ctx context.Context, userUUID uuid.UUID, provider string) error {
+	return nil
+}
+
+func (f *fakeIdentities) CreateState(ctx context.Context, state entity.OAuthState) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.states[state.State] = state
+	return nil
+}
+
+func (f *fakeIdentities) ConsumeState(ctx context.Context, state string) (entity.OAuthState, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	st, ok := f.states[state]
+	if !ok {
+		return st, domain.ErrNotFound
+	}
+	delete(f.states, state)
+	return st, nil
+}
+
+func (f *fakeIdentities) DeleteExpiredStates(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+// fakeUsers повторяет правило репозитория: по почте находится только подтвердивший ее пользователь
+type fakeUsers struct {
+	mu    sync.Mutex
+	users map[uuid.UUID]entity.User
+}
+
+func (f *fakeUsers) add(user entity.User) entity.User {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	user.UUID = uuid.New()
+	f.users[user.UUID] = user
+	return user
+}
+
+func (f *fakeUsers) GetUserByUUID(ctx context.Context, userUUID uuid.UUID) (entity.User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	user, ok := f.users[userUUID]
+	if !ok {
+		return user, domain.ErrNotFound
+	}
+	return user, nil
+}
+
+func (f *fakeUsers) GetUserByLogin(ctx context.Context, login string) (entity.User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, user := range f.users {
+		if user.Login == login {
+			return user, nil
+		}
+	}
+	return entity.User{}, domain.ErrNotFound
+}
+
+func (f *fakeUsers) GetUserByEmail(ctx context.Context, email string) (entity.User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, user := range f.users {
+		if strings.EqualFold(user.Email, email) && user.EmailVerifiedAt != nil {
+			return user, nil
+		}
+	}
+	return entity.User{}, domain.ErrNotFound
+}
+
+func (f *fakeUsers) CreateUser(ctx context.Context, login, passHash, email string) (entity.User, error) {
+	return f.add(entity.User{Login: login, PassHash: passHash, Email: email}), nil
+}
+
+func (f *fakeUsers) VerifyEmail(ctx context.Context, userUUID uuid.UUID, email string, verifiedAt time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	user := f.users[userUUID]
+	user.EmailVerifiedAt = &verifiedAt
+	f.users[userUUID] = user
+	return nil
+}
+
+type fakeTokens struct{}
+
+func (fakeTokens) CreateChallengeToken(ctx context.Context, user entity.User) (string, error) {
+	return "challenge:" + user.UUID.String(), nil
+}
+
+type fakeSessions struct{}
+
+func (fakeSessions) Start(ctx context.Context, user entity.User, client entity.ClientInfo) (string, error) {
+	return "jwt:" + user.UUID.String(), nil
+}
+
+type env struct {
+	service    *Service
+	provider   *oauthtest.Provider
+	server     *httptest.Server
+	users      *fakeUsers
+	identities *fakeIdentities
+}
+
+func newEnv(t *testing.T, user oauthtest.User) *env {
+	t.Helper()
+
+	provider := oauthtest.NewProvider("client", user)
+	server := httptest.NewServer(provider)
+	t.Cleanup(server.Close)
+
+	e := &env{
+		provider:   provider,
+		server:     server,
+		users:      &fakeUsers{users: make(map[uuid.UUID]entity.User)},
+		identities: &fakeIdentities{states: make(map[string]entity.OAuthState)},
+	}
+
+	client := oauth.New(resty.New(), provider.Config(server.URL, redirectURL))
+	e.service = New(map[string]Provider{providerName: client}, e.identities, e.users, fakeTokens{}, fakeSessions{}, time.Minute)
+
+	return e
+}
+
+// authorize проходит вход у провайдера по ссылке из StartLogin и возвращает code и state из редиректа
+func (e *env) authorize(t *testing.T, authURL string) (string, string) {
+	t.Helper()
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := client.Get(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("authorize: status %d", resp.StatusCode)
+	}
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(location.String(), redirectURL) {
+		t.Fatalf("redirected to %s", location)
+	}
+
+	return location.Query().Get("code"), location.Query().Get("state")
+}
+
+func (e *env) login(t *testing.T) (entity.LoginResult, error) {
+	t.Helper()
+
+	authURL, err := e.service.StartLogin(context.Background(), providerName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, state := e.authorize(t, authURL)
+
+	return e.service.Callback(context.Background(), providerName, code, state, nil, entity.ClientInfo{})
+}
+
+func userFromResult(t *testing.T, e *env, result entity.LoginResult) entity.User {
+	t.Helper()
+
+	raw, ok := strings.CutPrefix(result.Token, "jwt:")
+	if !ok {
+		t.Fatalf("expected jwt, got %+v", result)
+	}
+	user, err := e.users.GetUserByUUID(context.Background(), uuid.MustParse(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return user
+}
+
+func TestCallbackCreatesUserAndReusesIdentity(t *testing.T) {
+	e := newEnv(t, oauthtest.User{Subject: "42", Email: "new@example.com", EmailVerified: true, Login: "new.user"})
+
+	result, err := e.login(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := userFromResult(t, e, result)
+	if user.Login != "new.user" || user.Email != "new@example.com" || user.EmailVerifiedAt == nil {
+		t.Fatalf("unexpected user %+v", user)
+	}
+
+	result, err = e.login(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again := userFromResult(t, e, result); again.UUID != user.UUID {
+		t.Fatalf("second login created another user %s", again.UUID)
+	}
+	if len(e.users.users) != 1 || len(e.identities.identities) != 1 {
+		t.Fatalf("%d users, %d identities; want 1 and 1", len(e.users.users), len(e.identities.identities))
+	}
+}
+
+func TestCallbackPicksValidLogin(t *testing.T) {
+	tests := []struct {
+		name     string
+		user     oauthtest.User
+		existing string
+		want     string
+	}{
+		{"reserved login falls back to email", oauthtest.User{Subject: "1", Login: "me", Email: "artist@example.com"}, "", "artist"},
+		{"deleted prefix falls back to subject", oauthtest.User{Subject: "1234", Login: "deleted-abc", Email: "x@example.com"}, "", "test_1234"},
+		{"taken login gets a suffix", oauthtest.User{Subject: "1", Login: "artist"}, "artist", "artist_2"},
+		{"suffix keeps max length", oauthtest.User{Subject: "1", Login: strings.Repeat("a", 40)}, strings.Repeat("a", 32), strings.Repeat("a", 30) + "_2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newEnv(t, tt.user)
+			if tt.existing != "" {
+				e.users.add(entity.User{Login: tt.existing})
+			}
+
+			result, err := e.login(t)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if user := userFromResult(t, e, result); user.Login != tt.want {
+				t.Fatalf("login %q, want %q", user.Login, tt.want)
+			}
+		})
+	}
+}
+
+func TestCallbackLinksVerifiedEmail(t *testing.T) {
+	e := newEnv(t, oauthtest.User{Subject: "42", Email: "Owner@Example.com", EmailVerified: true})
+
+	verifiedAt := time.Now()
+	owner := e.users.add(entity.User{Login: "owner", Email: "owner@example.com", EmailVerifiedAt: &verifiedAt})
+
+	result, err := e.login(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user := userFromResult(t, e, result); user.UUID != owner.UUID {
+		t.Fatalf("verified email must link to existing user, got %s", user.Login)
+	}
+}
+
+func TestCallbackDoesNotLinkUnverifiedEmail(t *testing.T) {
+	cases := map[string]struct {
+		providerVerified bool
+		ownerVerified    bool
+	}{
+		"provider did not verify": {providerVerified: false, ownerVerified: true},
+		"we did not verify":       {providerVerified: true, ownerVerified: false},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := newEnv(t, oauthtest.User{Subject: "42", Email: "owner@example.com", EmailVerified: tc.providerVerified})
+
+			owner := entity.User{Login: "owner", Email: "owner@example.com"}
+			if tc.ownerVerified {
+				verifiedAt := time.Now()
+				owner.EmailVerifiedAt = &verifiedAt
+			}
+			owner = e.users.add(owner)
+
+			result, err := e.login(t)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if user := userFromResult(t, e, result); user.UUID == owner.UUID {
+				t.Fatal("unverified email must not link to existing user")
+			}
+		})
+	}
+}
+
+func TestCallbackTwoFactor(t *testing.T) {
+	e := newEnv(t, oauthtest.User{Subject: "42", Email: "owner@example.com", EmailVerified: true})
+
+	now := time.Now()
+	owner := e.users.add(entity.User{Login: "owner", Email: "owner@example.com", EmailVerifiedAt: &now, TOTPEnabledAt: &now})
+
+	result, err := e.login(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Token != "" || result.ChallengeToken != "challenge:"+owner.UUID.String() {
+		t.Fatalf("expected second step, got %+v", result)
+	}
+}
+
+func TestCallbackState(t *testing.T) {
+	ctx := context.Background()
+	e := newEnv(t, oauthtest.User{Subject: "42"})
+
+	authURL, err := e.service.StartLogin(ctx, providerName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, state := e.authorize(t, authURL)
+
+	if _, err = e.service.Callback(ctx, providerName, code, "forged", nil, entity.ClientInfo{}); !errors.Is(err, domain.ErrInvalidToken) {
+		t.Fatalf("unknown state: expected ErrInvalidToken, got %v", err)
+	}
+
+	if _, err = e.service.Callback(ctx, providerName, code, state, nil, entity.ClientInfo{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = e.service.Callback(ctx, providerName, code, state, nil, entity.ClientInfo{}); !errors.Is(err, domain.ErrInvalidToken) {
+		t.Fatalf("reused state: expected ErrInvalidToken, got %v", err)
+	}
+
+	if _, err = e.service.Callback(ctx, "other", code, state, nil, entity.ClientInfo{}); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("unknown provider: expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestCallbackPKCE(t *testing.T) {
+	ctx := context.Background()
+	e := newEnv(t, oauthtest.User{Subject: "42"})
+
+	first, err := e.service.StartLogin(ctx, providerName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := e.service.StartLogin(ctx, providerName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	code, _ := e.authorize(t, first)
+	_, state := e.authorize(t, second)
+
+	// код выдан под challenge первого входа, verifier второго к нему не подходит
+	_, err = e.service.Callback(ctx, providerName, code, state, nil, entity.ClientInfo{})
+	if !errors.Is(err, domain.ErrProviderFailed) {
+		t.Fatalf("expected ErrProviderFailed, got %v", err)
+	}
+}
+
+func TestCallbackLink(t *testing.T) {
+	ctx := context.Background()
+	e := newEnv(t, oauthtest.User{Subject: "42"})
+
+	current := e.users.add(entity.User{Login: "current"})
+	other := e.users.add(entity.User{Login: "other"})
+
+	authURL, err := e.service.StartLink(ctx, providerName, current.UUID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, state := e.authorize(t, authURL)
+	if _, err = e.service.Callback(ctx, providerName, code, state, nil, entity.ClientInfo{}); err != nil {
+		t.Fatal(err)
+	}
+
+	authURL, err = e.service.StartLink(ctx, providerName, other.UUID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, state = e.authorize(t, authURL)
+	_, err = e.service.Callback(ctx, providerName, code, state, nil, entity.ClientInfo{})
+	if !errors.Is(err, domain.ErrIdentityAlreadyLinked) {
+		t.Fatalf("expected ErrIdentityAlreadyLinked, got %v", err)
+	}
+}
@@ -0,0 +1,210 @@
+package profile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/musicman-backend/internal/domain"
+	"github.com/musicman-backend/internal/domain/entity"
+	"github.com/musicman-backend/pkg/username"
+)
+
+// reauthWindow - аккаунт без пароля и 2FA подтверждает опасные действия входом не раньше этого срока
+const reauthWindow = 10 * time.Minute
+
+// AvatarBucket - бакет аватаров, отдельно от аудио
+const AvatarBucket = "avatars"
+
+var avatarImage = entity.ImageSpec{
+	Bucket:  AvatarBucket,
+	MinSide: 128,
+	MaxSide: 4096,
+	Small:   64,
+	Medium:  256,
+	Large:   512,
+}
+
+type UserRepository interface {
+	GetUserByUUID(ctx context.Context, userUUID uuid.UUID) (entity.User, error)
+	GetUserByLogin(ctx context.Context, login string) (entity.User, error)
+	UpdateProfile(ctx context.Context, userUUID uuid.UUID, update entity.ProfileUpdate) error
+	SetAvatar(ctx context.Context, userUUID uuid.UUID, key string) (string, error)
+	ChangeLogin(ctx context.Context, userUUID uuid.UUID, oldLogin, newLogin string) error
+	Anonymize(ctx context.Context, userUUID uuid.UUID, login string, deletedAt time.Time) error
+}
+
+// Images хранит картинки миниатюрами
+type Images interface {
+	Upload(ctx context.Context, spec entity.ImageSpec, owner uuid.UUID, filePath string) (string, error)
+	URLs(ctx context.Context, spec entity.ImageSpec, key string) (entity.ImageURLs, error)
+	Delete(ctx context.Context, spec entity.ImageSpec, key string)
+}
+
+type Sessions interface {
+	Get(ctx context.Context, userUUID, sessionID uuid.UUID) (entity.Session, error)
+	Reissue(ctx context.Context, user entity.User, sessionID uuid.UUID) (string, error)
+	RevokeAll(ctx context.Context, userUUID uuid.UUID) error
+}
+
+type Service struct {
+	users    UserRepository
+	images   Images
+	sessions Sessions
+}
+
+func New(users UserRepository, images Images, sessions Sessions) *Service {
+	return &Service{
+		users:    users,
+		images:   images,
+		sessions: sessions,
+	}
+}
+
+func (s *Service) GetProfile(ctx context.Context, userUUID uuid.UUID) (entity.User, error) {
+	return s.users.GetUserByUUID(ctx, userUUID)
+}
+
+func (s *Service) UpdateProfile(ctx context.Context, userUUID uuid.UUID, update entity.ProfileUpdate) (entity.User, error) {
+	update, err := normalizeProfile(update)
+	if err != nil {
+		return entity.User{}, err
+	}
+
+	if err = s.users.UpdateProfile(ctx, userUUID, update); err != nil {
+		return entity.User{}, err
+	}
+
+	return s.users.GetUserByUUID(ctx, userUUID)
+}
+
+// SetAvatar загружает аватар из файла filePath и удаляет предыдущий
+func (s *Service) SetAvatar(ctx context.Context, userUUID uuid.UUID, filePath string) (entity.User, error) {
+	key, err := s.images.Upload(ctx, avatarImage, userUUID, filePath)
+	if err != nil {
+		return entity.User{}, err
+	}
+
+	oldKey, err := s.users.SetAvatar(ctx, userUUID, key)
+	if err != nil {
+		s.deleteAvatar(ctx, key)
+		return entity.User{}, err
+	}
+	s.deleteAvatar(ctx, oldKey)
+
+	return s.users.GetUserByUUID(ctx, userUUID)
+}
+
+func (s *Service) RemoveAvatar(ctx context.Context, userUUID uuid.UUID) (entity.User, error) {
+	oldKey, err := s.users.SetAvatar(ctx, userUUID, "")
+	if err != nil {
+		return entity.User{}, err
+	}
+	s.deleteAvatar(ctx, oldKey)
+
+	return s.users.GetUserByUUID(ctx, userUUID)
+}
+
+// AvatarURLs - ссылки на миниатюры аватара, пустые, если его нет
+func (s *Service) AvatarURLs(ctx context.Context, key string) (entity.ImageURLs, error) {
+	return s.images.URLs(ctx, avatarImage, key)
+}
+
+// ChangeLogin меняет логин после проверки пароля. Логин зашит в JWT, поэтому для текущей сессии
+// возвращается новый токен
+func (s *Service) ChangeLogin(ctx context.Context, userUUID, sessionID uuid.UUID, login, password string) (string, error) {
+	user, err := s.users.GetUserByUUID(ctx, userUUID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if err = s.confirm(ctx, user, sessionID, password); err != nil {
+		return "", err
+	}
+
+	if err = username.Validate(login); err != nil {
+		return "", fmt.Errorf("%w: %s", domain.ErrInvalidLogin, err)
+	}
+	if login == user.Login {
+		return s.sessions.Reissue(ctx, user, sessionID)
+	}
+
+	_, err = s.users.GetUserByLogin(ctx, login)
+	if err == nil {
+		return "", domain.ErrUserAlreadyExists
+	}
+	if !errors.Is(err, domain.ErrNotFound) {
+		return "", fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if err = s.users.ChangeLogin(ctx, userUUID, user.Login, login); err != nil {
+		return "", err
+	}
+
+	user.Login = login
+
+	return s.sessions.Reissue(ctx, user, sessionID)
+}
+
+// DeleteAccount обезличивает аккаунт: персональные данные стираются, покупки и платежи остаются
+// для отчетности. Семплы и паки автора уходят в корзину и снимаются с продажи, купленные остаются
+// у покупателей под обезличенным автором
+func (s *Service) DeleteAccount(ctx context.Context, userUUID, sessionID uuid.UUID, password string) error {
+	user, err := s.users.GetUserByUUID(ctx, userUUID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if err = s.confirm(ctx, user, sessionID, password); err != nil {
+		return err
+	}
+
+	// сначала отзываем сессии, чтобы сбросить кеш проверок, сами строки удалит Anonymize
+	if err = s.sessions.RevokeAll(ctx, userUUID); err != nil {
+		return err
+	}
+
+	if err = s.users.Anonymize(ctx, userUUID, username.Deleted(userUUID), time.Now()); err != nil {
+		return err
+	}
+
+	s.deleteAvatar(ctx, user.AvatarKey)
+
+	return nil
+}
+
+func (s *Service) deleteAvatar(ctx context.Context, key string) {
+	s.images.Delete(ctx, avatarImage, key)
+}
+
+// confirm подтверждает опасное действие паролем. Аккаунту без пароля подтверждать нечем: при включенной 2FA
+// код уже проверил middleware.RequireTwoFactor, без нее нужен свежий вход через провайдера
+func (s *Service) confirm(ctx context.Context, user entity.User, sessionID uuid.UUID, password string) error {
+	if user.PassHash != "" {
+		if bcrypt.CompareHashAndPassword([]byte(user.PassHash), []byte(password)) != nil {
+			return domain.ErrInvalidCredentials
+		}
+		return nil
+	}
+
+	if user.TwoFactorEnabled() {
+		return nil
+	}
+
+	session, err := s.sessions.Get(ctx, user.UUID, sessionID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return domain.ErrReauthRequired
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get session: %w", err)
+	}
+	if time.Since(session.CreatedAt) > reauthWindow {
+		return domain.ErrReauthRequired
+	}
+
+	return nil
+}
@@ -0,0 +1,106 @@
+package profile
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/musicman-backend/internal/domain"
+	"github.com/musicman-backend/internal/domain/entity"
+)
+
+type fakeUsers struct {
+	UserRepository
+	users      map[uuid.UUID]entity.User
+	anonymized []uuid.UUID
+}
+
+func (f *fakeUsers) GetUserByUUID(_ context.Context, userUUID uuid.UUID) (entity.User, error) {
+	user, ok := f.users[userUUID]
+	if !ok {
+		return user, domain.ErrNotFound
+	}
+	return user, nil
+}
+
+func (f *fakeUsers) Anonymize(_ context.Context, userUUID uuid.UUID, _ string, _ time.Time) error {
+	f.anonymized = append(f.anonymized, userUUID)
+	return nil
+}
+
+// fakeSessions - сессии по id, чужая сессия не находится
+type fakeSessions struct {
+	sessions map[uuid.UUID]entity.Session
+}
+
+func (f fakeSessions) Get(_ context.Context, userUUID, sessionID uuid.UUID) (entity.Session, error) {
+	session, ok := f.sessions[sessionID]
+	if !ok || session.UserUUID != userUUID {
+		return entity.Session{}, domain.ErrNotFound
+	}
+	return session, nil
+}
+
+func (f fakeSessions) Reissue(context.Context, entity.User, uuid.UUID) (string, error) {
+	return "token", nil
+}
+
+func (f fakeSessions) RevokeAll(context.Context, uuid.UUID) error {
+	return nil
+}
+
+type fakeImages struct {
+	Images
+}
+
+func (fakeImages) Delete(context.Context, entity.ImageSpec, string) {}
+
+func TestDeleteAccountConfirmation(t *testing.T) {
+	passHash, err := bcrypt.GenerateFromPassword([]byte("secret-password"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	enabledAt := time.Now()
+	withPassword := entity.User{UUID: uuid.New(), PassHash: string(passHash)}
+	oauthOnly := entity.User{UUID: uuid.New()}
+	oauthTwoFactor := entity.User{UUID: uuid.New(), TOTPSecret: "secret", TOTPEnabledAt: &enabledAt}
+
+	fresh, stale := uuid.New(), uuid.New()
+	sessions := fakeSessions{sessions: map[uuid.UUID]entity.Session{
+		fresh: {ID: fresh, UserUUID: oauthOnly.UUID, CreatedAt: time.Now().Add(-time.Minute)},
+		stale: {ID: stale, UserUUID: oauthOnly.UUID, CreatedAt: time.Now().Add(-reauthWindow - time.Minute)},
+	}}
+
+	cases := map[string]struct {
+		user     entity.User
+		session  uuid.UUID
+		password string
+		err      error
+	}{
+		"right password":          {withPassword, stale, "secret-password", nil},
+		"wrong password":          {withPassword, fresh, "wrong", domain.ErrInvalidCredentials},
+		"oauth fresh sign-in":     {oauthOnly, fresh, "", nil},
+		"oauth stale sign-in":     {oauthOnly, stale, "", domain.ErrReauthRequired},
+		"oauth token without sid": {oauthOnly, uuid.Nil, "", domain.ErrReauthRequired},
+		"oauth with 2fa":          {oauthTwoFactor, stale, "", nil},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			users := &fakeUsers{users: map[uuid.UUID]entity.User{tc.user.UUID: tc.user}}
+			s := New(users, fakeImages{}, sessions)
+
+			err := s.DeleteAccount(context.Background(), tc.user.UUID, tc.session, tc.password)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("got %v, want %v", err, tc.err)
+			}
+			if deleted := len(users.anonymized) == 1; deleted != (tc.err == nil) {
+				t.Fatalf("account deleted: %v, error: %v", deleted, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package profile
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/musicman-backend/internal/domain"
+	"github.com/musicman-backend/internal/domain/entity"
+)
+
+const (
+	maxDisplayNameLength = 64
+	maxBioLength         = 1000
+	maxLinks             = 5
+	maxLinkLength        = 200
+)
+
+// normalizeProfile обрезает пробелы и проверяет длины и ссылки
+func normalizeProfile(update entity.ProfileUpdate) (entity.ProfileUpdate, error) {
+	if update.DisplayName != nil {
+		name := strings.TrimSpace(*update.DisplayName)
+		if utf8.RuneCountInString(name) > maxDisplayNameLength {
+			return update, fmt.Errorf("%w: display name must be at most %d characters long", domain.ErrInvalidProfile, maxDisplayNameLength)
+		}
+		update.DisplayName = &name
+	}
+
+	if update.Bio != nil {
+		bio := strings.TrimSpace(*update.Bio)
+		if utf8.RuneCountInString(bio) > maxBioLength {
+			return update, fmt.Errorf("%w: bio must be at most %d characters long", domain.ErrInvalidProfile, maxBioLength)
+		}
+		update.Bio = &bio
+	}
+
+	if update.Links != nil {
+		links := make([]string, 0, len(*update.Links))
+		for _, link := range *update.Links {
+			link = strings.TrimSpace(link)
+			if link == "" {
+				continue
+			}
+			if err := validateLink(link); err != nil {
+				return update, err
+			}
+			links = append(links, link)
+		}
+		if len(links) > maxLinks {
+			return update, fmt.Errorf("%w: at most %d links allowed", domain.ErrInvalidProfile, maxLinks)
+		}
+		update.Links = &links
+	}
+
+	return update, nil
+}
+
+func validateLink(link string) error {
+	if len(link) > maxLinkLength {
+		return fmt.Errorf("%w: link must be at most %d characters long", domain.ErrInvalidProfile, maxLinkLength)
+	}
+
+	u, err := url.Parse(link)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: link %q must be an http(s) url", domain.ErrInvalidProfile, link)
+	}
+
+	return nil
+}
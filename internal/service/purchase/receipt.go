@@ -0,0 +1,128 @@
+package purchase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/musicman-backend/internal/domain"
+	"github.com/musicman-backend/internal/domain/entity"
+	"github.com/musicman-backend/pkg/signer"
+)
+
+const receiptType = "receipt"
+
+// receipt - содержимое чека покупки. Только неизменяемые данные покупки,
+// поэтому повторная выдача дает тот же документ и ту же подпись
+type receipt struct {
+	Type          string    `json:"type"`
+	PurchaseID    uuid.UUID `json:"purchase_id"`
+	UserUUID      uuid.UUID `json:"user_uuid"`
+	SampleID      uuid.UUID `json:"sample_id"`
+	SampleVersion int       `json:"sample_version"`
+	SampleHash    string    `json:"sample_hash"` // sha256 купленного аудио
+	License       string    `json:"license"`
+	Price         int       `json:"price"`
+	PurchasedAt   time.Time `json:"purchased_at"`
+}
+
+// Receipt выдает подписанный чек по покупке пользователя
+func (s *Service) Receipt(ctx context.Context, userUUID, purchaseID uuid.UUID) (entity.SignedDocument, error) {
+	purchase, err := s.purchaseRepo.GetByID(ctx, purchaseID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return entity.SignedDocument{}, err
+	}
+	if err != nil {
+		return entity.SignedDocument{}, fmt.Errorf("failed to get purchase: %w", err)
+	}
+	if purchase.UserUUID != userUUID {
+		return entity.SignedDocument{}, domain.ErrNotFound
+	}
+
+	hash, err := s.purchasedHash(ctx, purchase)
+	if err != nil {
+		return entity.SignedDocument{}, err
+	}
+
+	payload, err := json.Marshal(receipt{
+		Type:          receiptType,
+		PurchaseID:    purchase.ID,
+		UserUUID:      purchase.UserUUID,
+		SampleID:      purchase.SampleID,
+		SampleVersion: purchase.SampleVersion,
+		SampleHash:    hash,
+		License:       purchase.License,
+		Price:         purchase.Price,
+		PurchasedAt:   purchase.CreatedAt.UTC(),
+	})
+	if err != nil {
+		return entity.SignedDocument{}, fmt.Errorf("failed to marshal receipt: %w", err)
+	}
+
+	return s.sign(payload), nil
+}
+
+// purchasedHash - хеш аудио той версии, которую покупатель купил
+func (s *Service) purchasedHash(ctx context.Context, purchase entity.Purchase) (string, error) {
+	if purchase.SampleVersion > 0 {
+		version, err := s.versions.GetVersion(ctx, purchase.SampleID, purchase.SampleVersion)
+		if err != nil {
+			return "", fmt.Errorf("failed to get purchased version: %w", err)
+		}
+
+		return version.Hash, nil
+	}
+
+	// покупка до версионирования - хеша купленного файла нет, берем текущий
+	sample, err := s.sampleRepo.GetByID(ctx, purchase.SampleID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get sample: %w", err)
+	}
+
+	return sample.AudioHash, nil
+}
+
+// VerifyReceipt проверяет подпись чека и что покупка по нему существует.
+// Чеки под выведенными из оборота ключами тоже принимаются.
+// Возвращает ErrInvalidSignature, если чек подделан или подписан неизвестным ключом, и ErrNotFound, если покупки нет
+func (s *Service) VerifyReceipt(ctx context.Context, doc entity.SignedDocument) (entity.Purchase, error) {
+	if doc.Algorithm != signer.Algorithm || !s.signer.Verify(doc.KeyID, doc.Payload, doc.Signature) {
+		return entity.Purchase{}, domain.ErrInvalidSignature
+	}
+
+	var r receipt
+	if err := json.Unmarshal(doc.Payload, &r); err != nil || r.Type != receiptType {
+		// подпись верна, но это другой документ площадки, например сертификат лицензии
+		return entity.Purchase{}, domain.ErrInvalidSignature
+	}
+
+	purchase, err := s.purchaseRepo.GetByID(ctx, r.PurchaseID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return purchase, err
+	}
+	if err != nil {
+		return purchase, fmt.Errorf("failed to get purchase: %w", err)
+	}
+	if purchase.UserUUID != r.UserUUID || purchase.SampleID != r.SampleID || purchase.License != r.License {
+		return entity.Purchase{}, domain.ErrNotFound
+	}
+
+	return purchase, nil
+}
+
+func (s *Service) sign(payload []byte) entity.SignedDocument {
+	return entity.SignedDocument{
+		Payload:   payload,
+		Signature: s.signer.Sign(payload),
+		KeyID:     s.signer.KeyID(),
+		Algorithm: signer.Algorithm,
+	}
+}
+
+// SigningKey - публичный ключ для проверки документов площадки без сервера
+func (s *Service) SigningKey() (keyID, algorithm, publicKey string) {
+	return s.signer.KeyID(), signer.Algorithm, s.signer.PublicKey()
+}
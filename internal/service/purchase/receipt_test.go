@@ -0,0 +1,137 @@
+package purchase
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/musicman-backend/internal/domain"
+	"github.com/musicman-backend/internal/domain/constant"
+	"github.com/musicman-backend/internal/domain/entity"
+	"github.com/musicman-backend/pkg/signer"
+)
+
+// fakeVersions - хеш аудио каждой версии
+type fakeVersions map[int]string
+
+func (f fakeVersions) GetVersion(_ context.Context, sampleID uuid.UUID, version int) (entity.SampleVersion, error) {
+	hash, ok := f[version]
+	if !ok {
+		return entity.SampleVersion{}, domain.ErrNotFound
+	}
+	return entity.SampleVersion{SampleID: sampleID, Version: version, Hash: hash}, nil
+}
+
+func (f fakeVersions) GetVersionKey(context.Context, uuid.UUID, int) (string, error) {
+	return "", nil
+}
+
+func newReceiptService(t *testing.T) (*Service, *store, entity.Purchase) {
+	t.Helper()
+	keys, err := signer.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sample := testSample(entity.SampleLicense{Type: constant.LicenseCommercial, Price: 100})
+	sample.AudioHash = "current-hash"
+	purchase := entity.Purchase{
+		ID:            uuid.New(),
+		UserUUID:      uuid.New(),
+		SampleID:      sample.ID,
+		SampleVersion: 1,
+		License:       constant.LicenseCommercial,
+		Price:         100,
+		CreatedAt:     time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC),
+	}
+	db := &store{
+		samples:   map[uuid.UUID]entity.Sample{sample.ID: sample},
+		tokens:    map[uuid.UUID]int{purchase.UserUUID: 0},
+		purchases: []entity.Purchase{purchase},
+	}
+	s := New(fakePurchases{store: db}, fakeSamples{db}, fakeUsers{db}, fakeURLs{}, fakeVersions{1: "purchased-hash"},
+		nil, fakeSplitter{}, keys, fakeEvents{}, 0)
+
+	return s, db, purchase
+}
+
+func TestReceiptVerifies(t *testing.T) {
+	s, _, purchase := newReceiptService(t)
+	ctx := context.Background()
+
+	doc, err := s.Receipt(ctx, purchase.UserUUID, purchase.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var r receipt
+	if err = json.Unmarshal(doc.Payload, &r); err != nil {
+		t.Fatal(err)
+	}
+	if r.SampleHash != "purchased-hash" || r.Price != 100 || r.License != constant.LicenseCommercial {
+		t.Fatalf("receipt %+v, want the hash of the purchased version", r)
+	}
+
+	again, err := s.Receipt(ctx, purchase.UserUUID, purchase.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(again.Payload, doc.Payload) || again.Signature != doc.Signature {
+		t.Fatal("reissued receipt must be the same document")
+	}
+
+	verified, err := s.VerifyReceipt(ctx, doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if verified.ID != purchase.ID {
+		t.Fatalf("verified purchase %s, want %s", verified.ID, purchase.ID)
+	}
+
+	if _, err = s.Receipt(ctx, uuid.New(), purchase.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("receipt of a foreign purchase: got %v, want ErrNotFound", err)
+	}
+}
+
+func TestVerifyReceiptRejectsForgery(t *testing.T) {
+	s, db, purchase := newReceiptService(t)
+	ctx := context.Background()
+
+	doc, err := s.Receipt(ctx, purchase.UserUUID, purchase.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tampered := doc
+	tampered.Payload = bytes.Replace(doc.Payload, []byte(`"price":100`), []byte(`"price":1`), 1)
+	if _, err = s.VerifyReceipt(ctx, tampered); !errors.Is(err, domain.ErrInvalidSignature) {
+		t.Fatalf("tampered receipt: got %v, want ErrInvalidSignature", err)
+	}
+
+	other, err := signer.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	foreign := doc
+	foreign.Signature, foreign.KeyID = other.Sign(doc.Payload), other.KeyID()
+	if _, err = s.VerifyReceipt(ctx, foreign); !errors.Is(err, domain.ErrInvalidSignature) {
+		t.Fatalf("receipt signed by an unknown key: got %v, want ErrInvalidSignature", err)
+	}
+
+	certificate, err := s.LicenseCertificate(ctx, purchase.UserUUID, purchase.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = s.VerifyReceipt(ctx, certificate); !errors.Is(err, domain.ErrInvalidSignature) {
+		t.Fatalf("license certificate as receipt: got %v, want ErrInvalidSignature", err)
+	}
+
+	db.purchases = nil
+	if _, err = s.VerifyReceipt(ctx, doc); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("receipt of a missing purchase: got %v, want ErrNotFound", err)
+	}
+}
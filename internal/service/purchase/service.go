@@ -2,19 +2,23 @@ package purchase
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/musicman-backend/internal/domain"
+	"github.com/musicman-backend/internal/domain/constant"
 	"github.com/musicman-backend/internal/domain/entity"
 )
 
 type PurchaseRepository interface {
-	Create(ctx context.Context, purchase entity.Purchase) (uuid.UUID, error)
+	Create(ctx context.Context, purchase entity.Purchase, earning entity.Earning) (uuid.UUID, error)
 	GetByUserAndSample(ctx context.Context, userUUID, sampleID uuid.UUID) (entity.Purchase, error)
 	GetByUser(ctx context.Context, userUUID uuid.UUID) ([]entity.Purchase, error)
+	GetByID(ctx context.Context, id uuid.UUID) (entity.Purchase, error)
+	UpdateFollowUpdates(ctx context.Context, id uuid.UUID, followUpdates bool, sampleVersion int) error
 }
 
 type SampleRepository interface {
@@ -25,9 +29,36 @@ type UrlGetter interface {
 	GetSampleDownloadURL(ctx context.Context, minioKey string) (string, error)
 }
 
+type VersionGetter interface {
+	GetVersionKey(ctx context.Context, sampleID uuid.UUID, version int) (string, error)
+	GetVersion(ctx context.Context, sampleID uuid.UUID, version int) (entity.SampleVersion, error)
+}
+
 type UserRepository interface {
 	GetUserByUUID(ctx context.Context, userUUID uuid.UUID) (entity.User, error)
-	UpdateUserBalance(ctx context.Context, userUUID uuid.UUID, amount int) error
+}
+
+// SensitiveChecker требует свежий код 2FA, если она включена у пользователя
+type SensitiveChecker interface {
+	CheckSensitive(ctx context.Context, userUUID uuid.UUID, code string) error
+}
+
+// RevenueSplitter делит цену покупки между автором и площадкой
+type RevenueSplitter interface {
+	Split(ctx context.Context, authorUUID uuid.UUID, price int) (entity.Earning, error)
+}
+
+// Signer подписывает сертификаты лицензий и чеки покупок
+type Signer interface {
+	Sign(data []byte) string
+	Verify(keyID string, data []byte, signature string) bool
+	KeyID() string
+	PublicKey() string
+}
+
+// EventRecorder пишет покупку в статистику продаж
+type EventRecorder interface {
+	RecordPurchase(sampleID, userUUID uuid.UUID, amount int)
 }
 
 type Service struct {
@@ -35,26 +66,45 @@ type Service struct {
 	sampleRepo   SampleRepository
 	userRepo     UserRepository
 	url          UrlGetter
+	versions     VersionGetter
+	sensitive    SensitiveChecker
+	revenue      RevenueSplitter
+	signer       Signer
+	events       EventRecorder
+
+	// twoFactorThreshold покупки от этой цены требуют кода 2FA, 0 - не требуют
+	twoFactorThreshold int
 }
 
-func New(purchaseRepo PurchaseRepository, sampleRepo SampleRepository, userRepo UserRepository, url UrlGetter) *Service {
+func New(purchaseRepo PurchaseRepository, sampleRepo SampleRepository, userRepo UserRepository, url UrlGetter, versions VersionGetter, sensitive SensitiveChecker, revenue RevenueSplitter, signer Signer, events EventRecorder, twoFactorThreshold int) *Service {
 	return &Service{
-		purchaseRepo: purchaseRepo,
-		sampleRepo:   sampleRepo,
-		userRepo:     userRepo,
-		url:          url,
+		purchaseRepo:       purchaseRepo,
+		sampleRepo:         sampleRepo,
+		userRepo:           userRepo,
+		url:                url,
+		versions:           versions,
+		sensitive:          sensitive,
+		revenue:            revenue,
+		signer:             signer,
+		events:             events,
+		twoFactorThreshold: twoFactorThreshold,
 	}
 }
 
-func (s *Service) PurchaseSample(ctx context.Context, userUUID, sampleID uuid.UUID) (entity.Purchase, error) {
+// PurchaseSample покупает лицензию licenseType на семпл, пустой тип - коммерческая лицензия.
+// twoFactorCode нужен только для дорогих покупок при включенной 2FA
+func (s *Service) PurchaseSample(ctx context.Context, userUUID, sampleID uuid.UUID, licenseType, twoFactorCode string) (entity.Purchase, error) {
 	// 1. Получить семпл по ID
 	sample, err := s.sampleRepo.GetByID(ctx, sampleID)
-	if errors.Is(err, domain.ErrNotFound) {
-		return entity.Purchase{}, err
+	if errors.Is(err, domain.ErrNotFound) || sample.DeletedAt != nil {
+		return entity.Purchase{}, domain.ErrNotFound
 	}
 	if err != nil {
 		return entity.Purchase{}, fmt.Errorf("failed to get sample: %w", err)
 	}
+	if !sample.Public(time.Now()) {
+		return entity.Purchase{}, domain.ErrSampleUnavailable
+	}
 
 	// 2. Проверить, не куплен ли уже семпл
 	_, err = s.purchaseRepo.GetByUserAndSample(ctx, userUUID, sampleID)
@@ -74,15 +124,29 @@ func (s *Service) PurchaseSample(ctx context.Context, userUUID, sampleID uuid.UU
 		return entity.Purchase{}, fmt.Errorf("failed to get user: %w", err)
 	}
 
-	if sample.Price == 0 {
+	if len(sample.Licenses) == 0 {
 		return entity.Purchase{}, domain.ErrSampleIsFree
 	}
 
-	// 4. Проверить баланс токенов
-	if user.Tokens < sample.Price {
+	if licenseType == "" {
+		licenseType = constant.LicenseCommercial
+	}
+	license, ok := sample.License(licenseType)
+	if !ok {
+		return entity.Purchase{}, domain.ErrLicenseNotOffered
+	}
+
+	// 4. Проверить баланс токенов, окончательно он проверяется при списании в транзакции покупки
+	if user.Tokens < license.Price {
 		return entity.Purchase{}, domain.ErrInsufficientTokens
 	}
 
+	if s.twoFactorThreshold > 0 && license.Price >= s.twoFactorThreshold {
+		if err = s.sensitive.CheckSensitive(ctx, userUUID, twoFactorCode); err != nil {
+			return entity.Purchase{}, err
+		}
+	}
+
 	sampleURL, err := s.url.GetSampleDownloadURL(ctx, sample.MinioKey)
 	if err != nil {
 		return entity.Purchase{}, fmt.Errorf("failed to get sample download url: %w", err)
@@ -93,24 +157,32 @@ func (s *Service) PurchaseSample(ctx context.Context, userUUID, sampleID uuid.UU
 		ID:          uuid.New(),
 		UserUUID:    userUUID,
 		SampleID:    sampleID,
-		Price:       sample.Price,
+		Price:       license.Price,
+		License:     license.Type,
 		CreatedAt:   time.Now(),
 		Sample:      &sample,
 		ListenURL:   sampleURL,
 		DownloadURL: sampleURL,
+
+		SampleVersion: sample.AudioVersion,
 	}
 
-	purchaseID, err := s.purchaseRepo.Create(ctx, purchase)
+	earning, err := s.revenue.Split(ctx, sample.AuthorUUID, license.Price)
 	if err != nil {
-		return entity.Purchase{}, fmt.Errorf("failed to create purchase: %w", err)
+		return entity.Purchase{}, fmt.Errorf("failed to split revenue: %w", err)
 	}
-	purchase.ID = purchaseID
 
-	// 6. Списывать токены
-	err = s.userRepo.UpdateUserBalance(ctx, userUUID, -sample.Price)
+	// токены списываются, а доля автора начисляется в той же транзакции, что и покупка
+	purchaseID, err := s.purchaseRepo.Create(ctx, purchase, earning)
+	if errors.Is(err, domain.ErrSampleUnavailable) || errors.Is(err, domain.ErrInsufficientTokens) {
+		return entity.Purchase{}, err
+	}
 	if err != nil {
-		return entity.Purchase{}, fmt.Errorf("failed to update user balance: %w", err)
+		return entity.Purchase{}, fmt.Errorf("failed to create purchase: %w", err)
 	}
+	purchase.ID = purchaseID
+
+	s.events.RecordPurchase(sampleID, userUUID, license.Price)
 
 	return purchase, nil
 }
@@ -135,3 +207,136 @@ func (s *Service) IsPurchased(ctx context.Context, userUUID, sampleID uuid.UUID)
 
 	return true, nil
 }
+
+// PurchasedKey возвращает ключ аудио, доступного пользователю по покупке семпла
+func (s *Service) PurchasedKey(ctx context.Context, userUUID uuid.UUID, sample entity.Sample) (string, bool, error) {
+	_, key, isPurchased, err := s.Purchased(ctx, userUUID, sample)
+
+	return key, isPurchased, err
+}
+
+// Purchased возвращает покупку семпла пользователем и ключ доступного по ней аудио
+func (s *Service) Purchased(ctx context.Context, userUUID uuid.UUID, sample entity.Sample) (entity.Purchase, string, bool, error) {
+	purchase, err := s.purchaseRepo.GetByUserAndSample(ctx, userUUID, sample.ID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return purchase, "", false, nil
+	}
+	if err != nil {
+		return purchase, "", false, fmt.Errorf("failed to check purchase: %w", err)
+	}
+
+	key, err := s.DownloadKey(ctx, purchase, sample)
+	if err != nil {
+		return purchase, "", false, err
+	}
+
+	return purchase, key, true, nil
+}
+
+// DownloadKey - ключ аудио купленной версии, либо текущей, если покупатель подписан на обновления
+func (s *Service) DownloadKey(ctx context.Context, purchase entity.Purchase, sample entity.Sample) (string, error) {
+	if purchase.FollowUpdates || purchase.SampleVersion == 0 || purchase.SampleVersion == sample.AudioVersion {
+		return sample.MinioKey, nil
+	}
+
+	key, err := s.versions.GetVersionKey(ctx, sample.ID, purchase.SampleVersion)
+	if err != nil {
+		return "", fmt.Errorf("failed to get purchased version key: %w", err)
+	}
+
+	return key, nil
+}
+
+// SetFollowUpdates включает или выключает получение новых версий аудио по покупке.
+// При отключении покупка закрепляется за текущей версией семпла
+func (s *Service) SetFollowUpdates(ctx context.Context, userUUID, purchaseID uuid.UUID, followUpdates bool) (entity.Purchase, error) {
+	purchase, err := s.purchaseRepo.GetByID(ctx, purchaseID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return purchase, err
+	}
+	if err != nil {
+		return purchase, fmt.Errorf("failed to get purchase: %w", err)
+	}
+
+	if purchase.UserUUID != userUUID {
+		return purchase, domain.ErrNotFound
+	}
+
+	if !followUpdates && purchase.FollowUpdates {
+		sample, err := s.sampleRepo.GetByID(ctx, purchase.SampleID)
+		if err != nil {
+			return purchase, fmt.Errorf("failed to get sample: %w", err)
+		}
+		purchase.SampleVersion = sample.AudioVersion
+	}
+	purchase.FollowUpdates = followUpdates
+
+	err = s.purchaseRepo.UpdateFollowUpdates(ctx, purchase.ID, purchase.FollowUpdates, purchase.SampleVersion)
+	if err != nil {
+		return purchase, fmt.Errorf("failed to update purchase: %w", err)
+	}
+
+	return purchase, nil
+}
+
+// licenseCertificate - содержимое сертификата лицензии, подписывается как есть
+type licenseCertificate struct {
+	CertificateID uuid.UUID `json:"certificate_id"`
+	PurchaseID    uuid.UUID `json:"purchase_id"`
+	License       string    `json:"license"`
+	Terms         string    `json:"terms"`
+	SampleID      uuid.UUID `json:"sample_id"`
+	SampleTitle   string    `json:"sample_title"`
+	AuthorUUID    uuid.UUID `json:"author_uuid"`
+	Author        string    `json:"author"`
+	BuyerUUID     uuid.UUID `json:"buyer_uuid"`
+	Buyer         string    `json:"buyer"`
+	Price         int       `json:"price"`
+	PurchasedAt   time.Time `json:"purchased_at"`
+	IssuedAt      time.Time `json:"issued_at"`
+}
+
+// LicenseCertificate выдает подписанный сертификат лицензии по покупке пользователя
+func (s *Service) LicenseCertificate(ctx context.Context, userUUID, purchaseID uuid.UUID) (entity.SignedDocument, error) {
+	purchase, err := s.purchaseRepo.GetByID(ctx, purchaseID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return entity.SignedDocument{}, err
+	}
+	if err != nil {
+		return entity.SignedDocument{}, fmt.Errorf("failed to get purchase: %w", err)
+	}
+	if purchase.UserUUID != userUUID {
+		return entity.SignedDocument{}, domain.ErrNotFound
+	}
+
+	sample, err := s.sampleRepo.GetByID(ctx, purchase.SampleID)
+	if err != nil {
+		return entity.SignedDocument{}, fmt.Errorf("failed to get sample: %w", err)
+	}
+
+	buyer, err := s.userRepo.GetUserByUUID(ctx, userUUID)
+	if err != nil {
+		return entity.SignedDocument{}, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	payload, err := json.Marshal(licenseCertificate{
+		CertificateID: uuid.New(),
+		PurchaseID:    purchase.ID,
+		License:       purchase.License,
+		Terms:         constant.LicenseTerms[purchase.License],
+		SampleID:      sample.ID,
+		SampleTitle:   sample.Title,
+		AuthorUUID:    sample.AuthorUUID,
+		Author:        sample.AuthorName,
+		BuyerUUID:     buyer.UUID,
+		Buyer:         buyer.Login,
+		Price:         purchase.Price,
+		PurchasedAt:   purchase.CreatedAt,
+		IssuedAt:      time.Now().UTC(),
+	})
+	if err != nil {
+		return entity.SignedDocument{}, fmt.Errorf("failed to marshal certificate: %w", err)
+	}
+
+	return s.sign(payload), nil
+}
@@ -0,0 +1,198 @@
+package purchase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/musicman-backend/internal/domain"
+	"github.com/musicman-backend/internal/domain/constant"
+	"github.com/musicman-backend/internal/domain/entity"
+)
+
+// store - общее состояние фейков: семплы, балансы и покупки, как в одной базе
+type store struct {
+	samples   map[uuid.UUID]entity.Sample
+	tokens    map[uuid.UUID]int
+	purchases []entity.Purchase
+	earnings  []entity.Earning
+}
+
+type fakePurchases struct {
+	PurchaseRepository
+	*store
+}
+
+// Create повторяет проверки транзакции покупки: снятый с продажи семпл и баланс покупателя
+func (f fakePurchases) Create(_ context.Context, purchase entity.Purchase, earning entity.Earning) (uuid.UUID, error) {
+	sample := f.samples[purchase.SampleID]
+	if sample.DelistedAt != nil {
+		return uuid.Nil, domain.ErrSampleUnavailable
+	}
+	if f.tokens[purchase.UserUUID] < purchase.Price {
+		return uuid.Nil, domain.ErrInsufficientTokens
+	}
+
+	f.tokens[purchase.UserUUID] -= purchase.Price
+	if purchase.License == constant.LicenseExclusive {
+		sample.DelistedAt = &purchase.CreatedAt
+		sample.Status = constant.SampleStatusDelisted
+		f.samples[sample.ID] = sample
+	}
+	f.purchases = append(f.purchases, purchase)
+	f.earnings = append(f.earnings, earning)
+
+	return purchase.ID, nil
+}
+
+func (f fakePurchases) GetByUserAndSample(_ context.Context, userUUID, sampleID uuid.UUID) (entity.Purchase, error) {
+	for _, p := range f.purchases {
+		if p.UserUUID == userUUID && p.SampleID == sampleID {
+			return p, nil
+		}
+	}
+	return entity.Purchase{}, domain.ErrNotFound
+}
+
+func (f fakePurchases) GetByID(_ context.Context, id uuid.UUID) (entity.Purchase, error) {
+	for _, p := range f.purchases {
+		if p.ID == id {
+			return p, nil
+		}
+	}
+	return entity.Purchase{}, domain.ErrNotFound
+}
+
+type fakeSamples struct{ *store }
+
+func (f fakeSamples) GetByID(_ context.Context, id uuid.UUID) (entity.Sample, error) {
+	sample, ok := f.samples[id]
+	if !ok {
+		return sample, domain.ErrNotFound
+	}
+	return sample, nil
+}
+
+type fakeUsers struct{ *store }
+
+func (f fakeUsers) GetUserByUUID(_ context.Context, userUUID uuid.UUID) (entity.User, error) {
+	tokens, ok := f.tokens[userUUID]
+	if !ok {
+		return entity.User{}, domain.ErrNotFound
+	}
+	return entity.User{UUID: userUUID, Tokens: tokens}, nil
+}
+
+type fakeURLs struct{}
+
+func (fakeURLs) GetSampleDownloadURL(_ context.Context, minioKey string) (string, error) {
+	return "https://files/" + minioKey, nil
+}
+
+// fakeSplitter - комиссия 10% без округлений, доли проверяются в тестах earnings
+type fakeSplitter struct{}
+
+func (fakeSplitter) Split(_ context.Context, authorUUID uuid.UUID, price int) (entity.Earning, error) {
+	return entity.Earning{AuthorUUID: authorUUID, Price: price, Commission: price / 10, Amount: price - price/10}, nil
+}
+
+type fakeEvents struct{}
+
+func (fakeEvents) RecordPurchase(uuid.UUID, uuid.UUID, int) {}
+
+func newTestService(db *store) *Service {
+	return New(fakePurchases{store: db}, fakeSamples{db}, fakeUsers{db}, fakeURLs{}, nil, nil, fakeSplitter{}, nil, fakeEvents{}, 0)
+}
+
+func testSample(licenses ...entity.SampleLicense) entity.Sample {
+	return entity.Sample{
+		ID:         uuid.New(),
+		AuthorUUID: uuid.New(),
+		MinioKey:   "samples/key.wav",
+		Status:     constant.SampleStatusPublished,
+		Licenses:   licenses,
+	}
+}
+
+func TestPurchaseInsufficientTokens(t *testing.T) {
+	sample := testSample(entity.SampleLicense{Type: constant.LicenseCommercial, Price: 100})
+	buyer := uuid.New()
+	db := &store{
+		samples: map[uuid.UUID]entity.Sample{sample.ID: sample},
+		tokens:  map[uuid.UUID]int{buyer: 99},
+	}
+
+	_, err := newTestService(db).PurchaseSample(context.Background(), buyer, sample.ID, "", "")
+	if !errors.Is(err, domain.ErrInsufficientTokens) {
+		t.Fatalf("got %v, want ErrInsufficientTokens", err)
+	}
+	if db.tokens[buyer] != 99 || len(db.purchases) != 0 || len(db.earnings) != 0 {
+		t.Fatalf("failed purchase changed state: tokens %d, purchases %d, earnings %d", db.tokens[buyer], len(db.purchases), len(db.earnings))
+	}
+}
+
+func TestPurchaseExclusiveSecondBuyer(t *testing.T) {
+	sample := testSample(
+		entity.SampleLicense{Type: constant.LicenseCommercial, Price: 100},
+		entity.SampleLicense{Type: constant.LicenseExclusive, Price: 1000},
+	)
+	first, second := uuid.New(), uuid.New()
+	db := &store{
+		samples: map[uuid.UUID]entity.Sample{sample.ID: sample},
+		tokens:  map[uuid.UUID]int{first: 1000, second: 1000},
+	}
+	s := newTestService(db)
+	ctx := context.Background()
+
+	purchase, err := s.PurchaseSample(ctx, first, sample.ID, constant.LicenseExclusive, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if purchase.License != constant.LicenseExclusive || db.tokens[first] != 0 {
+		t.Fatalf("license %q, tokens left %d", purchase.License, db.tokens[first])
+	}
+	if len(db.earnings) != 1 || db.earnings[0].AuthorUUID != sample.AuthorUUID || db.earnings[0].Amount != 900 {
+		t.Fatalf("author earnings %+v, want 900 credited to the author", db.earnings)
+	}
+
+	for _, license := range []string{constant.LicenseExclusive, constant.LicenseCommercial} {
+		_, err = s.PurchaseSample(ctx, second, sample.ID, license, "")
+		if !errors.Is(err, domain.ErrSampleUnavailable) {
+			t.Fatalf("%s after exclusive: got %v, want ErrSampleUnavailable", license, err)
+		}
+	}
+	if db.tokens[second] != 1000 || len(db.purchases) != 1 {
+		t.Fatalf("second buyer was charged: tokens %d, purchases %d", db.tokens[second], len(db.purchases))
+	}
+}
+
+func TestPurchaseExclusiveSoldConcurrently(t *testing.T) {
+	sample := testSample(entity.SampleLicense{Type: constant.LicenseExclusive, Price: 1000})
+	buyer := uuid.New()
+	soldAt := time.Now()
+	sold := sample
+	sold.DelistedAt = &soldAt
+	db := &store{
+		samples: map[uuid.UUID]entity.Sample{sample.ID: sold},
+		tokens:  map[uuid.UUID]int{buyer: 1000},
+	}
+	// семпл прочитан до того, как параллельная эксклюзивная покупка сняла его с продажи
+	s := New(fakePurchases{store: db}, staleSamples{sample}, fakeUsers{db}, fakeURLs{}, nil, nil, fakeSplitter{}, nil, fakeEvents{}, 0)
+
+	_, err := s.PurchaseSample(context.Background(), buyer, sample.ID, constant.LicenseExclusive, "")
+	if !errors.Is(err, domain.ErrSampleUnavailable) {
+		t.Fatalf("got %v, want ErrSampleUnavailable", err)
+	}
+	if db.tokens[buyer] != 1000 {
+		t.Fatalf("buyer was charged: tokens %d", db.tokens[buyer])
+	}
+}
+
+type staleSamples struct{ sample entity.Sample }
+
+func (f staleSamples) GetByID(context.Context, uuid.UUID) (entity.Sample, error) {
+	return f.sample, nil
+}
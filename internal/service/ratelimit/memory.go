@@ -0,0 +1,49 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/musicman-backend/internal/domain/entity"
+)
+
+// MemoryStore хранит счетчики в памяти процесса. Подходит, пока инстанс один
+type MemoryStore struct {
+	mu     sync.Mutex
+	states map[string]entity.RateLimitState
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		states: make(map[string]entity.RateLimitState),
+	}
+}
+
+func (m *MemoryStore) Update(ctx context.Context, key string, fn func(state entity.RateLimitState) entity.RateLimitState) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state, ok := m.states[key]
+	if !ok {
+		state.Key = key
+	}
+	m.states[key] = fn(state)
+
+	return nil
+}
+
+func (m *MemoryStore) DeleteIdle(ctx context.Context, before time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var deleted int64
+	for key, state := range m.states {
+		if state.UpdatedAt.Before(before) && !state.LockedUntil.After(time.Now()) {
+			delete(m.states, key)
+			deleted++
+		}
+	}
+
+	return deleted, nil
+}
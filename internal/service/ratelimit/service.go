@@ -0,0 +1,227 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/musicman-backend/config"
+	"github.com/musicman-backend/internal/domain"
+	"github.com/musicman-backend/internal/domain/entity"
+)
+
+// maxLockoutDoublings не дает длительности блокировки переполниться, если MaxDuration не задан
+const maxLockoutDoublings = 20
+
+type Store interface {
+	// Update атомарно читает состояние ключа, применяет fn и сохраняет результат
+	Update(ctx context.Context, key string, fn func(state entity.RateLimitState) entity.RateLimitState) error
+	// DeleteIdle удаляет ключи, не менявшиеся с before
+	DeleteIdle(ctx context.Context, before time.Time) (int64, error)
+}
+
+type Service struct {
+	store Store
+	cfg   config.RateLimit
+}
+
+func New(store Store, cfg config.RateLimit) *Service {
+	return &Service{
+		store: store,
+		cfg:   cfg,
+	}
+}
+
+// AllowSignIn ограничивает попытки входа с одного ip
+func (s *Service) AllowSignIn(ctx context.Context, ip string) error {
+	return s.allow(ctx, "sign-in:ip:"+ip, s.cfg.SignIn)
+}
+
+// AllowSignUp ограничивает регистрации с одного ip
+func (s *Service) AllowSignUp(ctx context.Context, ip string) error {
+	return s.allow(ctx, "sign-up:ip:"+ip, s.cfg.SignUp)
+}
+
+// AllowForgotPassword ограничивает запросы писем со сбросом пароля с одного ip
+func (s *Service) AllowForgotPassword(ctx context.Context, ip string) error {
+	return s.allow(ctx, "forgot-password:ip:"+ip, s.cfg.ForgotPassword)
+}
+
+// AllowLogin ограничивает попытки входа в аккаунт и проверяет, не заблокирован ли он
+func (s *Service) AllowLogin(ctx context.Context, login string) error {
+	if err := s.checkLockout(ctx, lockoutKey(login)); err != nil {
+		return err
+	}
+
+	return s.allow(ctx, "sign-in:login:"+login, s.cfg.Login)
+}
+
+// LoginFailed учитывает неудачный вход. После порога каждая неудача блокирует аккаунт
+// вдвое дольше предыдущей
+func (s *Service) LoginFailed(ctx context.Context, login string) error {
+	if err := s.registerFailure(ctx, lockoutKey(login)); err != nil {
+		return fmt.Errorf("failed to register login failure: %w", err)
+	}
+
+	return nil
+}
+
+// LoginSucceeded сбрасывает счетчик неудачных входов
+func (s *Service) LoginSucceeded(ctx context.Context, login string) error {
+	if err := s.resetFailures(ctx, lockoutKey(login)); err != nil {
+		return fmt.Errorf("failed to reset login failures: %w", err)
+	}
+
+	return nil
+}
+
+// AllowTwoFactor проверяет, не заблокирована ли проверка кодов 2FA пользователя. Счетчик отдельный
+// от входа: коды проверяются и у уже вошедшего пользователя, например с украденным JWT
+func (s *Service) AllowTwoFactor(ctx context.Context, userUUID uuid.UUID) error {
+	return s.checkLockout(ctx, twoFactorKey(userUUID))
+}
+
+// TwoFactorFailed учитывает неверный код 2FA, блокировка растет так же, как у входа
+func (s *Service) TwoFactorFailed(ctx context.Context, userUUID uuid.UUID) error {
+	if err := s.registerFailure(ctx, twoFactorKey(userUUID)); err != nil {
+		return fmt.Errorf("failed to register two-factor failure: %w", err)
+	}
+
+	return nil
+}
+
+// TwoFactorSucceeded сбрасывает счетчик неверных кодов 2FA
+func (s *Service) TwoFactorSucceeded(ctx context.Context, userUUID uuid.UUID) error {
+	if err := s.resetFailures(ctx, twoFactorKey(userUUID)); err != nil {
+		return fmt.Errorf("failed to reset two-factor failures: %w", err)
+	}
+
+	return nil
+}
+
+// checkLockout возвращает *domain.RetryAfterError, пока ключ заблокирован
+func (s *Service) checkLockout(ctx context.Context, key string) error {
+	var locked time.Duration
+	now := time.Now()
+
+	err := s.store.Update(ctx, key, func(state entity.RateLimitState) entity.RateLimitState {
+		if state.LockedUntil.After(now) {
+			locked = state.LockedUntil.Sub(now)
+		}
+		return state
+	})
+	if err != nil {
+		return fmt.Errorf("failed to check lockout: %w", err)
+	}
+	if locked > 0 {
+		return &domain.RetryAfterError{RetryAfter: locked}
+	}
+
+	return nil
+}
+
+func (s *Service) registerFailure(ctx context.Context, key string) error {
+	lockout := s.cfg.Lockout
+	if lockout.Threshold <= 0 {
+		return nil
+	}
+
+	now := time.Now()
+	return s.store.Update(ctx, key, func(state entity.RateLimitState) entity.RateLimitState {
+		state.Failures++
+		state.UpdatedAt = now
+
+		over := state.Failures - lockout.Threshold
+		if over < 0 {
+			return state
+		}
+
+		duration := lockout.BaseDuration
+		for i := 0; i < over && i < maxLockoutDoublings; i++ {
+			duration *= 2
+		}
+		if lockout.MaxDuration > 0 && duration > lockout.MaxDuration {
+			duration = lockout.MaxDuration
+		}
+		state.LockedUntil = now.Add(duration)
+
+		return state
+	})
+}
+
+func (s *Service) resetFailures(ctx context.Context, key string) error {
+	now := time.Now()
+	return s.store.Update(ctx, key, func(state entity.RateLimitState) entity.RateLimitState {
+		state.Failures = 0
+		state.LockedUntil = time.Time{}
+		state.UpdatedAt = now
+		return state
+	})
+}
+
+// Cleanup забывает ключи, которые не использовались дольше IdleTTL
+func (s *Service) Cleanup(ctx context.Context) (int64, error) {
+	if s.cfg.IdleTTL <= 0 {
+		return 0, nil
+	}
+
+	deleted, err := s.store.DeleteIdle(ctx, time.Now().Add(-s.cfg.IdleTTL))
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete idle rate limit keys: %w", err)
+	}
+
+	return deleted, nil
+}
+
+// allow списывает токен из бакета ключа. Бакет пополняется на Requests токенов за Period,
+// вмещает не больше Burst
+func (s *Service) allow(ctx context.Context, key string, limit config.Limit) error {
+	if limit.Requests <= 0 || limit.Period <= 0 {
+		return nil
+	}
+
+	burst := float64(limit.Burst)
+	if burst <= 0 {
+		burst = float64(limit.Requests)
+	}
+	perSecond := float64(limit.Requests) / limit.Period.Seconds()
+
+	var retryAfter time.Duration
+	now := time.Now()
+
+	err := s.store.Update(ctx, key, func(state entity.RateLimitState) entity.RateLimitState {
+		if state.UpdatedAt.IsZero() {
+			state.Tokens = burst
+		} else if elapsed := now.Sub(state.UpdatedAt).Seconds(); elapsed > 0 {
+			state.Tokens = math.Min(burst, state.Tokens+elapsed*perSecond)
+		}
+		state.UpdatedAt = now
+
+		if state.Tokens < 1 {
+			retryAfter = time.Duration((1 - state.Tokens) / perSecond * float64(time.Second))
+			return state
+		}
+
+		state.Tokens--
+		return state
+	})
+	if err != nil {
+		return fmt.Errorf("failed to take rate limit token: %w", err)
+	}
+	if retryAfter > 0 {
+		return &domain.RetryAfterError{RetryAfter: retryAfter}
+	}
+
+	return nil
+}
+
+func lockoutKey(login string) string {
+	return "lockout:" + login
+}
+
+func twoFactorKey(userUUID uuid.UUID) string {
+	return "lockout:2fa:" + userUUID.String()
+}
@@ -0,0 +1,189 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/musicman-backend/config"
+	"github.com/musicman-backend/internal/domain"
+	"github.com/musicman-backend/internal/domain/entity"
+)
+
+func retryAfter(t *testing.T, err error) time.Duration {
+	t.Helper()
+
+	var retryErr *domain.RetryAfterError
+	if !errors.As(err, &retryErr) {
+		t.Fatalf("expected RetryAfterError, got %v", err)
+	}
+
+	return retryErr.RetryAfter
+}
+
+func TestAllowBurst(t *testing.T) {
+	ctx := context.Background()
+	s := New(NewMemoryStore(), config.RateLimit{SignIn: config.Limit{Requests: 3, Period: time.Hour}})
+
+	for i := 0; i < 3; i++ {
+		if err := s.AllowSignIn(ctx, "10.0.0.1"); err != nil {
+			t.Fatalf("request %d: %v", i+1, err)
+		}
+	}
+
+	wait := retryAfter(t, s.AllowSignIn(ctx, "10.0.0.1"))
+	// один токен пополняется за Period / Requests
+	if wait <= 0 || wait > 20*time.Minute {
+		t.Fatalf("retry after %s, want (0, 20m]", wait)
+	}
+
+	if err := s.AllowSignIn(ctx, "10.0.0.2"); err != nil {
+		t.Fatalf("other ip must have its own bucket: %v", err)
+	}
+}
+
+func TestAllowBurstOverridesRequests(t *testing.T) {
+	ctx := context.Background()
+	s := New(NewMemoryStore(), config.RateLimit{SignUp: config.Limit{Requests: 10, Period: time.Hour, Burst: 1}})
+
+	if err := s.AllowSignUp(ctx, "10.0.0.1"); err != nil {
+		t.Fatal(err)
+	}
+	retryAfter(t, s.AllowSignUp(ctx, "10.0.0.1"))
+}
+
+func TestAllowRefill(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	s := New(store, config.RateLimit{SignIn: config.Limit{Requests: 2, Period: time.Minute}})
+
+	for i := 0; i < 2; i++ {
+		if err := s.AllowSignIn(ctx, "10.0.0.1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	retryAfter(t, s.AllowSignIn(ctx, "10.0.0.1"))
+
+	// за полминуты бакет получает один токен
+	err := store.Update(ctx, "sign-in:ip:10.0.0.1", func(state entity.RateLimitState) entity.RateLimitState {
+		state.UpdatedAt = state.UpdatedAt.Add(-30 * time.Second)
+		return state
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = s.AllowSignIn(ctx, "10.0.0.1"); err != nil {
+		t.Fatalf("token must be refilled: %v", err)
+	}
+	retryAfter(t, s.AllowSignIn(ctx, "10.0.0.1"))
+}
+
+func TestAllowDisabled(t *testing.T) {
+	ctx := context.Background()
+	s := New(NewMemoryStore(), config.RateLimit{})
+
+	for i := 0; i < 100; i++ {
+		if err := s.AllowForgotPassword(ctx, "10.0.0.1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestLockout(t *testing.T) {
+	ctx := context.Background()
+	s := New(NewMemoryStore(), config.RateLimit{Lockout: config.Lockout{
+		Threshold:    2,
+		BaseDuration: time.Minute,
+		MaxDuration:  3 * time.Minute,
+	}})
+
+	if err := s.LoginFailed(ctx, "user"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AllowLogin(ctx, "user"); err != nil {
+		t.Fatalf("one failure must not lock: %v", err)
+	}
+
+	steps := []time.Duration{time.Minute, 2 * time.Minute, 3 * time.Minute, 3 * time.Minute}
+	for i, want := range steps {
+		if err := s.LoginFailed(ctx, "user"); err != nil {
+			t.Fatal(err)
+		}
+
+		wait := retryAfter(t, s.AllowLogin(ctx, "user"))
+		if wait > want || wait < want-time.Second {
+			t.Fatalf("failure %d: locked for %s, want %s", i+2, wait, want)
+		}
+	}
+
+	if err := s.AllowLogin(ctx, "other"); err != nil {
+		t.Fatalf("lockout must not affect other logins: %v", err)
+	}
+
+	if err := s.LoginSucceeded(ctx, "user"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AllowLogin(ctx, "user"); err != nil {
+		t.Fatalf("success must reset lockout: %v", err)
+	}
+}
+
+func TestTwoFactorLockout(t *testing.T) {
+	ctx := context.Background()
+	s := New(NewMemoryStore(), config.RateLimit{Lockout: config.Lockout{
+		Threshold:    1,
+		BaseDuration: time.Minute,
+	}})
+	userUUID := uuid.New()
+
+	if err := s.TwoFactorFailed(ctx, userUUID); err != nil {
+		t.Fatal(err)
+	}
+	retryAfter(t, s.AllowTwoFactor(ctx, userUUID))
+
+	if err := s.AllowLogin(ctx, userUUID.String()); err != nil {
+		t.Fatalf("two-factor lockout must not lock sign in: %v", err)
+	}
+	if err := s.AllowTwoFactor(ctx, uuid.New()); err != nil {
+		t.Fatalf("lockout must not affect other users: %v", err)
+	}
+
+	if err := s.TwoFactorSucceeded(ctx, userUUID); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AllowTwoFactor(ctx, userUUID); err != nil {
+		t.Fatalf("success must reset lockout: %v", err)
+	}
+}
+
+func TestCleanup(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	s := New(store, config.RateLimit{
+		SignIn:  config.Limit{Requests: 1, Period: time.Minute},
+		IdleTTL: time.Hour,
+	})
+
+	if err := s.AllowSignIn(ctx, "10.0.0.1"); err != nil {
+		t.Fatal(err)
+	}
+	err := store.Update(ctx, "sign-in:ip:10.0.0.1", func(state entity.RateLimitState) entity.RateLimitState {
+		state.UpdatedAt = state.UpdatedAt.Add(-2 * time.Hour)
+		return state
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deleted, err := s.Cleanup(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 1 {
+		t.Fatalf("deleted %d keys, want 1", deleted)
+	}
+}
@@ -0,0 +1,147 @@
+package recommendation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/musicman-backend/config"
+	"github.com/musicman-backend/internal/domain"
+	"github.com/musicman-backend/internal/domain/entity"
+)
+
+const (
+	defaultNeighbours     = 50
+	defaultTrendingWindow = 7 * 24 * time.Hour
+)
+
+type Repository interface {
+	Catalogue(ctx context.Context) ([]entity.SampleFeatures, error)
+	Purchases(ctx context.Context) ([]entity.UserSample, error)
+	ReplaceNeighbours(ctx context.Context, neighbours []entity.SampleNeighbour) error
+	Neighbours(ctx context.Context, sampleID uuid.UUID, limit int) ([]uuid.UUID, error)
+	Seeds(ctx context.Context, userUUID uuid.UUID) ([]uuid.UUID, error)
+	Feed(ctx context.Context, userUUID uuid.UUID, seeds []uuid.UUID, limit int) ([]uuid.UUID, error)
+	Trending(ctx context.Context, since time.Time, exclude []uuid.UUID, excludeAuthor uuid.UUID, limit int) ([]uuid.UUID, error)
+}
+
+type SampleRepository interface {
+	GetByID(ctx context.Context, id uuid.UUID) (entity.Sample, error)
+	GetPublished(ctx context.Context, ids []uuid.UUID) ([]entity.Sample, error)
+}
+
+type Service struct {
+	repo    Repository
+	samples SampleRepository
+	cfg     config.Recommendations
+}
+
+func New(repo Repository, samples SampleRepository, cfg config.Recommendations) *Service {
+	if cfg.Neighbours <= 0 {
+		cfg.Neighbours = defaultNeighbours
+	}
+	if cfg.TrendingWindow <= 0 {
+		cfg.TrendingWindow = defaultTrendingWindow
+	}
+
+	return &Service{
+		repo:    repo,
+		samples: samples,
+		cfg:     cfg,
+	}
+}
+
+// Rebuild пересчитывает похожие семплы для всего каталога
+func (s *Service) Rebuild(ctx context.Context) error {
+	started := time.Now()
+
+	catalogue, err := s.repo.Catalogue(ctx)
+	if err != nil {
+		return err
+	}
+
+	purchases, err := s.repo.Purchases(ctx)
+	if err != nil {
+		return err
+	}
+
+	result := neighbours(catalogue, purchases, s.cfg.Neighbours)
+	if err = s.repo.ReplaceNeighbours(ctx, result); err != nil {
+		return err
+	}
+
+	slog.Info("sample neighbours rebuilt",
+		slog.Int("samples", len(catalogue)),
+		slog.Int("purchases", len(purchases)),
+		slog.Int("neighbours", len(result)),
+		slog.Duration("took", time.Since(started)),
+	)
+
+	return nil
+}
+
+// Similar - семплы, похожие на sampleID. Пока похожих не хватает (новый семпл, расчет еще не прошел),
+// список добирается популярными
+func (s *Service) Similar(ctx context.Context, sampleID uuid.UUID, limit int) ([]entity.Sample, error) {
+	sample, err := s.samples.GetByID(ctx, sampleID)
+	if errors.Is(err, domain.ErrNotFound) || sample.DeletedAt != nil {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sample: %w", err)
+	}
+
+	ids, err := s.repo.Neighbours(ctx, sampleID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	ids, err = s.fillTrending(ctx, ids, append([]uuid.UUID{sampleID}, ids...), uuid.Nil, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.samples.GetPublished(ctx, ids)
+}
+
+// Feed - лента пользователя: семплы, похожие на купленные и лайкнутые им, без его собственных.
+// Новому пользователю достаются популярные
+func (s *Service) Feed(ctx context.Context, userUUID uuid.UUID, limit int) ([]entity.Sample, error) {
+	seeds, err := s.repo.Seeds(ctx, userUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []uuid.UUID
+	if len(seeds) > 0 {
+		ids, err = s.repo.Feed(ctx, userUUID, seeds, limit)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ids, err = s.fillTrending(ctx, ids, append(seeds, ids...), userUUID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.samples.GetPublished(ctx, ids)
+}
+
+// fillTrending дополняет ids популярными семплами до limit
+func (s *Service) fillTrending(ctx context.Context, ids, exclude []uuid.UUID, excludeAuthor uuid.UUID, limit int) ([]uuid.UUID, error) {
+	if len(ids) >= limit {
+		return ids, nil
+	}
+
+	trending, err := s.repo.Trending(ctx, time.Now().Add(-s.cfg.TrendingWindow), exclude, excludeAuthor, limit-len(ids))
+	if err != nil {
+		return nil, err
+	}
+
+	return append(ids, trending...), nil
+}
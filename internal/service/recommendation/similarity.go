@@ -0,0 +1,165 @@
+package recommendation
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/musicman-backend/internal/domain/entity"
+)
+
+const (
+	// вклад совместных покупок и признаков самого семпла в итоговое сходство
+	collaborativeWeight = 0.7
+	contentWeight       = 0.3
+
+	// веса признаков внутри контентного сходства, в сумме 1
+	genreWeight    = 0.5
+	authorWeight   = 0.2
+	priceWeight    = 0.15
+	durationWeight = 0.15
+
+	// contentWindow - сколько ближайших по длительности семплов того же жанра или автора
+	// сравнивать с каждым, чтобы расчет не рос квадратично на больших жанрах
+	contentWindow = 50
+	// maxUserPurchases - у покупателей с огромной библиотекой учитываются только первые покупки,
+	// иначе один такой пользователь дает квадрат пар и связывает все со всем
+	maxUserPurchases = 500
+)
+
+type pair struct {
+	a, b int // индексы в каталоге, a < b
+}
+
+// neighbours считает для каждого семпла каталога до k самых похожих.
+// Сходство - косинусная мера совместных покупок плюс совпадение жанра, автора, цены и длительности
+func neighbours(catalogue []entity.SampleFeatures, purchases []entity.UserSample, k int) []entity.SampleNeighbour {
+	index := make(map[uuid.UUID]int, len(catalogue))
+	for i, f := range catalogue {
+		index[f.ID] = i
+	}
+
+	// семплы каждого покупателя, только опубликованные и без повторов
+	byUser := make(map[uuid.UUID][]int)
+	seen := make(map[entity.UserSample]bool, len(purchases))
+	for _, p := range purchases {
+		i, ok := index[p.SampleID]
+		if !ok || seen[p] || len(byUser[p.UserUUID]) >= maxUserPurchases {
+			continue
+		}
+		seen[p] = true
+		byUser[p.UserUUID] = append(byUser[p.UserUUID], i)
+	}
+
+	buyers := make([]int, len(catalogue))
+	together := make(map[pair]int)
+	for _, items := range byUser {
+		for x, i := range items {
+			buyers[i]++
+			for _, j := range items[x+1:] {
+				together[ordered(i, j)]++
+			}
+		}
+	}
+
+	candidates := make(map[pair]struct{}, len(together))
+	for p := range together {
+		candidates[p] = struct{}{}
+	}
+	addContentCandidates(catalogue, candidates, func(f entity.SampleFeatures) string { return normalizeGenre(f.Genre) })
+	addContentCandidates(catalogue, candidates, func(f entity.SampleFeatures) string { return f.AuthorUUID.String() })
+
+	scored := make([][]entity.SampleNeighbour, len(catalogue))
+	for p := range candidates {
+		var collaborative float64
+		if n := together[p]; n > 0 {
+			collaborative = float64(n) / math.Sqrt(float64(buyers[p.a])*float64(buyers[p.b]))
+		}
+
+		score := collaborativeWeight*collaborative + contentWeight*contentSimilarity(catalogue[p.a], catalogue[p.b])
+		if score <= 0 {
+			continue
+		}
+
+		a, b := catalogue[p.a].ID, catalogue[p.b].ID
+		scored[p.a] = append(scored[p.a], entity.SampleNeighbour{SampleID: a, NeighbourID: b, Score: score})
+		scored[p.b] = append(scored[p.b], entity.SampleNeighbour{SampleID: b, NeighbourID: a, Score: score})
+	}
+
+	var result []entity.SampleNeighbour
+	for _, list := range scored {
+		sort.Slice(list, func(i, j int) bool {
+			if list[i].Score != list[j].Score {
+				return list[i].Score > list[j].Score
+			}
+			return list[i].NeighbourID.String() < list[j].NeighbourID.String()
+		})
+		if len(list) > k {
+			list = list[:k]
+		}
+		result = append(result, list...)
+	}
+
+	return result
+}
+
+// addContentCandidates добавляет пары семплов с одинаковым key, близких по длительности
+func addContentCandidates(catalogue []entity.SampleFeatures, candidates map[pair]struct{}, key func(entity.SampleFeatures) string) {
+	groups := make(map[string][]int)
+	for i, f := range catalogue {
+		if k := key(f); k != "" {
+			groups[k] = append(groups[k], i)
+		}
+	}
+
+	for _, group := range groups {
+		sort.Slice(group, func(i, j int) bool {
+			return catalogue[group[i]].Duration < catalogue[group[j]].Duration
+		})
+
+		for x, i := range group {
+			for y := x + 1; y < len(group) && y <= x+contentWindow; y++ {
+				candidates[ordered(i, group[y])] = struct{}{}
+			}
+		}
+	}
+}
+
+func contentSimilarity(a, b entity.SampleFeatures) float64 {
+	var score float64
+	if genre := normalizeGenre(a.Genre); genre != "" && genre == normalizeGenre(b.Genre) {
+		score += genreWeight
+	}
+	if a.AuthorUUID == b.AuthorUUID {
+		score += authorWeight
+	}
+
+	score += priceWeight * closeness(float64(a.Price), float64(b.Price))
+	score += durationWeight * closeness(a.Duration, b.Duration)
+
+	return score
+}
+
+// closeness - 1 для равных значений, ближе к 0 чем сильнее они отличаются
+func closeness(a, b float64) float64 {
+	high := math.Max(a, b)
+	if high <= 0 {
+		return 1
+	}
+
+	return math.Min(a, b) / high
+}
+
+func normalizeGenre(genre entity.Genre) string {
+	return strings.ToLower(strings.TrimSpace(genre))
+}
+
+func ordered(i, j int) pair {
+	if i > j {
+		i, j = j, i
+	}
+
+	return pair{a: i, b: j}
+}
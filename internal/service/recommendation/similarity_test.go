@@ -0,0 +1,148 @@
+package recommendation
+
+import (
+	"math"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/musicman-backend/internal/domain/entity"
+)
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestCloseness(t *testing.T) {
+	tests := []struct {
+		a, b, want float64
+	}{
+		{100, 100, 1},
+		{50, 100, 0.5},
+		{100, 50, 0.5},
+		{0, 0, 1},
+		{0, 10, 0},
+	}
+
+	for _, tt := range tests {
+		if got := closeness(tt.a, tt.b); !almostEqual(got, tt.want) {
+			t.Fatalf("closeness(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestContentSimilarity(t *testing.T) {
+	author := uuid.New()
+	a := entity.SampleFeatures{AuthorUUID: author, Genre: "Hip Hop", Price: 100, Duration: 10}
+
+	same := entity.SampleFeatures{AuthorUUID: author, Genre: " hip hop ", Price: 100, Duration: 10}
+	if got := contentSimilarity(a, same); !almostEqual(got, 1) {
+		t.Fatalf("identical features scored %v, want 1", got)
+	}
+
+	other := entity.SampleFeatures{AuthorUUID: uuid.New(), Genre: "techno", Price: 50, Duration: 5}
+	want := priceWeight*0.5 + durationWeight*0.5
+	if got := contentSimilarity(a, other); !almostEqual(got, want) {
+		t.Fatalf("different genre and author scored %v, want %v", got, want)
+	}
+
+	noGenre := entity.SampleFeatures{AuthorUUID: uuid.New(), Price: 100, Duration: 10}
+	if got := contentSimilarity(noGenre, entity.SampleFeatures{AuthorUUID: uuid.New(), Price: 100, Duration: 10}); !almostEqual(got, priceWeight+durationWeight) {
+		t.Fatalf("empty genres must not match, scored %v", got)
+	}
+}
+
+// catalogue - семплы без общих жанров и авторов, чтобы сходство давали только покупки
+func catalogue(n int) []entity.SampleFeatures {
+	genres := []entity.Genre{"house", "techno", "ambient", "trap"}
+
+	features := make([]entity.SampleFeatures, n)
+	for i := range features {
+		features[i] = entity.SampleFeatures{
+			ID:         uuid.New(),
+			AuthorUUID: uuid.New(),
+			Genre:      genres[i%len(genres)],
+			Price:      100 * (i + 1),
+			Duration:   float64(10 * (i + 1)),
+		}
+	}
+
+	return features
+}
+
+func byNeighbour(result []entity.SampleNeighbour, sampleID uuid.UUID) []entity.SampleNeighbour {
+	var list []entity.SampleNeighbour
+	for _, n := range result {
+		if n.SampleID == sampleID {
+			list = append(list, n)
+		}
+	}
+
+	return list
+}
+
+func TestNeighboursCollaborative(t *testing.T) {
+	items := catalogue(3)
+	a, b, c := items[0].ID, items[1].ID, items[2].ID
+	u1, u2, u3 := uuid.New(), uuid.New(), uuid.New()
+
+	purchases := []entity.UserSample{
+		{UserUUID: u1, SampleID: a}, {UserUUID: u1, SampleID: b}, {UserUUID: u1, SampleID: a},
+		{UserUUID: u2, SampleID: a}, {UserUUID: u2, SampleID: b},
+		{UserUUID: u3, SampleID: a}, {UserUUID: u3, SampleID: c},
+		// семпл не из каталога, например снятый с публикации
+		{UserUUID: u3, SampleID: uuid.New()},
+	}
+
+	result := neighbours(items, purchases, 10)
+
+	forA := byNeighbour(result, a)
+	if len(forA) != 2 || forA[0].NeighbourID != b || forA[1].NeighbourID != c {
+		t.Fatalf("neighbours of a: %+v, want b then c", forA)
+	}
+
+	// a купили трое, b - двое, вместе - двое; повторная покупка u1 не считается
+	wantAB := collaborativeWeight*2/math.Sqrt(3*2) + contentWeight*contentSimilarity(items[0], items[1])
+	if !almostEqual(forA[0].Score, wantAB) {
+		t.Fatalf("score a-b %v, want %v", forA[0].Score, wantAB)
+	}
+
+	forB := byNeighbour(result, b)
+	if len(forB) != 1 || forB[0].NeighbourID != a || !almostEqual(forB[0].Score, forA[0].Score) {
+		t.Fatalf("neighbours of b: %+v, want a with the same score", forB)
+	}
+
+	if limited := byNeighbour(neighbours(items, purchases, 1), a); len(limited) != 1 || limited[0].NeighbourID != b {
+		t.Fatalf("k=1 neighbours of a: %+v, want only b", limited)
+	}
+}
+
+func TestNeighboursContentOnly(t *testing.T) {
+	author := uuid.New()
+	items := []entity.SampleFeatures{
+		{ID: uuid.New(), AuthorUUID: uuid.New(), Genre: "House", Price: 100, Duration: 10},
+		{ID: uuid.New(), AuthorUUID: uuid.New(), Genre: "house", Price: 100, Duration: 10},
+		{ID: uuid.New(), AuthorUUID: author, Genre: "techno", Price: 100, Duration: 10},
+		{ID: uuid.New(), AuthorUUID: author, Genre: "ambient", Price: 100, Duration: 10},
+		{ID: uuid.New(), AuthorUUID: uuid.New(), Genre: "trap", Price: 100, Duration: 10},
+	}
+
+	result := neighbours(items, nil, 10)
+
+	sameGenre := byNeighbour(result, items[0].ID)
+	if len(sameGenre) != 1 || sameGenre[0].NeighbourID != items[1].ID {
+		t.Fatalf("neighbours of a house sample: %+v, want the other house sample", sameGenre)
+	}
+	if want := contentWeight * (genreWeight + priceWeight + durationWeight); !almostEqual(sameGenre[0].Score, want) {
+		t.Fatalf("same genre score %v, want %v", sameGenre[0].Score, want)
+	}
+
+	sameAuthor := byNeighbour(result, items[2].ID)
+	if len(sameAuthor) != 1 || sameAuthor[0].NeighbourID != items[3].ID {
+		t.Fatalf("neighbours of an author's sample: %+v, want the other sample of the author", sameAuthor)
+	}
+
+	if alone := byNeighbour(result, items[4].ID); len(alone) != 0 {
+		t.Fatalf("sample without purchases, genre or author matches got neighbours %+v", alone)
+	}
+}
@@ -0,0 +1,53 @@
+package session
+
+import "strings"
+
+// браузеры и системы в порядке проверки: Edge и Opera пишут в User-Agent и Chrome, Chrome - Safari
+var (
+	browsers = []struct{ token, name string }{
+		{"YaBrowser", "Яндекс Браузер"},
+		{"Edg/", "Edge"},
+		{"OPR/", "Opera"},
+		{"Firefox/", "Firefox"},
+		{"Chrome/", "Chrome"},
+		{"Safari/", "Safari"},
+		{"okhttp", "Android app"},
+		{"CFNetwork", "iOS app"},
+	}
+	systems = []struct{ token, name string }{
+		{"Android", "Android"},
+		{"iPhone", "iPhone"},
+		{"iPad", "iPad"},
+		{"Windows", "Windows"},
+		{"Mac OS X", "macOS"},
+		{"Linux", "Linux"},
+	}
+)
+
+// DeviceName - понятное имя устройства по User-Agent, например "Chrome, Windows"
+func DeviceName(userAgent string) string {
+	var browser, system string
+	for _, b := range browsers {
+		if strings.Contains(userAgent, b.token) {
+			browser = b.name
+			break
+		}
+	}
+	for _, s := range systems {
+		if strings.Contains(userAgent, s.token) {
+			system = s.name
+			break
+		}
+	}
+
+	switch {
+	case browser != "" && system != "":
+		return browser + ", " + system
+	case browser != "":
+		return browser
+	case system != "":
+		return system
+	default:
+		return "Неизвестное устройство"
+	}
+}
@@ -0,0 +1,223 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"github.com/musicman-backend/config"
+	"github.com/musicman-backend/internal/domain"
+	"github.com/musicman-backend/internal/domain/entity"
+)
+
+const (
+	defaultTTL           = 365 * 24 * time.Hour
+	defaultTouchInterval = time.Minute
+
+	maxDeviceNameLength = 128
+	maxUserAgentLength  = 512
+)
+
+type Repository interface {
+	Create(ctx context.Context, session entity.Session) error
+	GetByID(ctx context.Context, id uuid.UUID) (entity.Session, error)
+	GetActiveByUser(ctx context.Context, userUUID uuid.UUID, now time.Time) ([]entity.Session, error)
+	Revoke(ctx context.Context, userUUID, id uuid.UUID, revokedAt time.Time) error
+	RevokeAll(ctx context.Context, userUUID, except uuid.UUID, revokedAt time.Time) ([]uuid.UUID, error)
+	Touch(ctx context.Context, id uuid.UUID, ip string, seenAt time.Time) (bool, error)
+	DeleteStale(ctx context.Context, before time.Time) (int64, error)
+}
+
+type Tokenizer interface {
+	CreateToken(ctx context.Context, user entity.User, sessionID uuid.UUID, expiresAt time.Time) (string, error)
+}
+
+type Service struct {
+	repo  Repository
+	token Tokenizer
+	cfg   config.Sessions
+
+	// checked - когда сессия последний раз подтвердилась базой, чтобы не ходить в нее на каждый запрос
+	mu      sync.Mutex
+	checked map[uuid.UUID]time.Time
+}
+
+func New(repo Repository, token Tokenizer, cfg config.Sessions) *Service {
+	if cfg.TTL <= 0 {
+		cfg.TTL = defaultTTL
+	}
+	if cfg.TouchInterval <= 0 {
+		cfg.TouchInterval = defaultTouchInterval
+	}
+
+	return &Service{
+		repo:    repo,
+		token:   token,
+		cfg:     cfg,
+		checked: make(map[uuid.UUID]time.Time),
+	}
+}
+
+// Start создает сессию и выдает привязанный к ней JWT
+func (s *Service) Start(ctx context.Context, user entity.User, client entity.ClientInfo) (string, error) {
+	now := time.Now()
+
+	deviceName := strings.TrimSpace(client.DeviceName)
+	if deviceName == "" {
+		deviceName = DeviceName(client.UserAgent)
+	}
+
+	session := entity.Session{
+		ID:         uuid.New(),
+		UserUUID:   user.UUID,
+		DeviceName: truncate(deviceName, maxDeviceNameLength),
+		UserAgent:  truncate(client.UserAgent, maxUserAgentLength),
+		IP:         client.IP,
+		CreatedAt:  now,
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(s.cfg.TTL),
+	}
+
+	if err := s.repo.Create(ctx, session); err != nil {
+		return "", err
+	}
+
+	return s.token.CreateToken(ctx, user, session.ID, session.ExpiresAt)
+}
+
+func (s *Service) List(ctx context.Context, userUUID uuid.UUID) ([]entity.Session, error) {
+	return s.repo.GetActiveByUser(ctx, userUUID, time.Now())
+}
+
+func (s *Service) Revoke(ctx context.Context, userUUID, sessionID uuid.UUID) error {
+	if err := s.repo.Revoke(ctx, userUUID, sessionID, time.Now()); err != nil {
+		return err
+	}
+
+	s.forget(sessionID)
+
+	return nil
+}
+
+// RevokeAll завершает все сессии пользователя, например после сброса пароля
+func (s *Service) RevokeAll(ctx context.Context, userUUID uuid.UUID) error {
+	return s.RevokeOthers(ctx, userUUID, uuid.Nil)
+}
+
+// RevokeOthers завершает все сессии пользователя, кроме текущей
+func (s *Service) RevokeOthers(ctx context.Context, userUUID, current uuid.UUID) error {
+	ids, err := s.repo.RevokeAll(ctx, userUUID, current, time.Now())
+	if err != nil {
+		return err
+	}
+
+	s.forget(ids...)
+
+	return nil
+}
+
+// Get возвращает сессию пользователя, чужая сессия - domain.ErrNotFound
+func (s *Service) Get(ctx context.Context, userUUID, sessionID uuid.UUID) (entity.Session, error) {
+	session, err := s.repo.GetByID(ctx, sessionID)
+	if err != nil {
+		return session, err
+	}
+	if session.UserUUID != userUUID {
+		return entity.Session{}, domain.ErrNotFound
+	}
+
+	return session, nil
+}
+
+// Reissue выдает новый JWT для той же сессии, например когда в токене поменялся логин
+func (s *Service) Reissue(ctx context.Context, user entity.User, sessionID uuid.UUID) (string, error) {
+	session, err := s.repo.GetByID(ctx, sessionID)
+	if errors.Is(err, domain.ErrNotFound) || (err == nil && (session.UserUUID != user.UUID || session.RevokedAt != nil)) {
+		return "", domain.ErrInvalidToken
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return s.token.CreateToken(ctx, user, session.ID, session.ExpiresAt)
+}
+
+// Check пропускает запрос, только если сессия токена активна. В базу ходит не чаще раза
+// в TouchInterval на сессию и заодно обновляет last_seen и ip
+func (s *Service) Check(ctx context.Context, claims entity.JWTClaims, ip string) error {
+	now := time.Now()
+
+	sessionID := claims.SessionID
+	if sessionID == uuid.Nil {
+		// токен выдан до появления сессий, срок его жизни уже проверен вместе с подписью
+		if now.Before(s.cfg.LegacyTokensUntil) {
+			return nil
+		}
+		return domain.ErrInvalidToken
+	}
+
+	s.mu.Lock()
+	checkedAt, ok := s.checked[sessionID]
+	s.mu.Unlock()
+	if ok && now.Sub(checkedAt) < s.cfg.TouchInterval {
+		return nil
+	}
+
+	active, err := s.repo.Touch(ctx, sessionID, ip, now)
+	if err != nil {
+		return fmt.Errorf("failed to check session: %w", err)
+	}
+	if !active {
+		s.forget(sessionID)
+		return domain.ErrInvalidToken
+	}
+
+	s.mu.Lock()
+	s.checked[sessionID] = now
+	s.mu.Unlock()
+
+	return nil
+}
+
+// Cleanup удаляет из базы давно отозванные и истекшие сессии и чистит кеш проверок
+func (s *Service) Cleanup(ctx context.Context) (int64, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	for id, checkedAt := range s.checked {
+		if now.Sub(checkedAt) >= s.cfg.TouchInterval {
+			delete(s.checked, id)
+		}
+	}
+	s.mu.Unlock()
+
+	return s.repo.DeleteStale(ctx, now.Add(-s.cfg.Retention))
+}
+
+func (s *Service) forget(ids ...uuid.UUID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, id := range ids {
+		delete(s.checked, id)
+	}
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+
+	// не режем посередине многобайтового символа
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+
+	return s[:n]
+}
@@ -0,0 +1,73 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/musicman-backend/config"
+	"github.com/musicman-backend/internal/domain"
+	"github.com/musicman-backend/internal/domain/entity"
+)
+
+// fakeSessions - активные сессии, Touch считает обращения к базе
+type fakeSessions struct {
+	Repository
+	active  map[uuid.UUID]bool
+	touches int
+}
+
+func (f *fakeSessions) Touch(_ context.Context, id uuid.UUID, _ string, _ time.Time) (bool, error) {
+	f.touches++
+	return f.active[id], nil
+}
+
+func TestCheckSession(t *testing.T) {
+	active, revoked := uuid.New(), uuid.New()
+	repo := &fakeSessions{active: map[uuid.UUID]bool{active: true}}
+	s := New(repo, nil, config.Sessions{TouchInterval: time.Hour})
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if err := s.Check(ctx, entity.JWTClaims{SessionID: active}, "127.0.0.1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if repo.touches != 1 {
+		t.Fatalf("%d touches, want the second check served from cache", repo.touches)
+	}
+
+	if err := s.Check(ctx, entity.JWTClaims{SessionID: revoked}, "127.0.0.1"); !errors.Is(err, domain.ErrInvalidToken) {
+		t.Fatalf("revoked session: got %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestCheckLegacyToken(t *testing.T) {
+	legacy := entity.JWTClaims{UserUUID: uuid.New()}
+	ctx := context.Background()
+
+	cases := map[string]struct {
+		until time.Time
+		err   error
+	}{
+		"not configured": {time.Time{}, domain.ErrInvalidToken},
+		"before cutoff":  {time.Now().Add(time.Hour), nil},
+		"after cutoff":   {time.Now().Add(-time.Hour), domain.ErrInvalidToken},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			repo := &fakeSessions{}
+			s := New(repo, nil, config.Sessions{LegacyTokensUntil: tc.until})
+
+			if err := s.Check(ctx, legacy, "127.0.0.1"); !errors.Is(err, tc.err) {
+				t.Fatalf("got %v, want %v", err, tc.err)
+			}
+			if repo.touches != 0 {
+				t.Fatal("token without session must not touch the sessions table")
+			}
+		})
+	}
+}
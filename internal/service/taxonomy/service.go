@@ -0,0 +1,435 @@
+package taxonomy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"github.com/musicman-backend/internal/domain"
+	"github.com/musicman-backend/internal/domain/constant"
+	"github.com/musicman-backend/internal/domain/entity"
+	"github.com/musicman-backend/pkg/slug"
+)
+
+const (
+	minNameLength = 2
+	maxNameLength = 32
+
+	defaultTagsLimit = 50
+	maxTagsLimit     = 200
+)
+
+// maxTags - сколько тегов каждого вида можно повесить на семпл или пак
+var maxTags = map[string]int{
+	constant.TagKindTag:        10,
+	constant.TagKindInstrument: 5,
+	constant.TagKindMood:       3,
+}
+
+type Repository interface {
+	Genres(ctx context.Context) ([]entity.GenreNode, error)
+	GenreBySlug(ctx context.Context, slug string) (entity.GenreNode, error)
+	CreateGenre(ctx context.Context, genre entity.GenreNode) (uuid.UUID, error)
+	UpdateGenre(ctx context.Context, genre entity.GenreNode) error
+	MergeGenres(ctx context.Context, source, target entity.GenreNode) error
+	Tags(ctx context.Context, kind, search string, limit int) ([]entity.Tag, error)
+	TagByID(ctx context.Context, id uuid.UUID) (entity.Tag, error)
+	TagsBySlugs(ctx context.Context, kind string, slugs []string) ([]entity.Tag, error)
+	CreateTag(ctx context.Context, tag entity.Tag) (uuid.UUID, error)
+	EnsureTags(ctx context.Context, kind string, tags []entity.Tag) ([]entity.Tag, error)
+	RenameTag(ctx context.Context, tag entity.Tag) error
+	MergeTags(ctx context.Context, sourceID, targetID uuid.UUID) error
+	SetSampleTags(ctx context.Context, sampleID uuid.UUID, tagIDs []uuid.UUID) error
+	SetPackTags(ctx context.Context, packID uuid.UUID, tagIDs []uuid.UUID) error
+}
+
+type Service struct {
+	repo Repository
+}
+
+func New(repo Repository) *Service {
+	return &Service{repo: repo}
+}
+
+// ResolveGenre находит жанр справочника по slug или названию в любом написании
+func (s *Service) ResolveGenre(ctx context.Context, genre string) (entity.Genre, error) {
+	key := slug.Make(genre)
+	if key == "" {
+		return "", fmt.Errorf("%w: genre is empty", domain.ErrInvalidGenre)
+	}
+
+	node, err := s.repo.GenreBySlug(ctx, key)
+	if errors.Is(err, domain.ErrNotFound) {
+		return "", fmt.Errorf("%w: unknown genre %q", domain.ErrInvalidGenre, genre)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return node.Slug, nil
+}
+
+// SetSampleTags проверяет и сохраняет теги семпла. Свободные теги создаются при первом использовании,
+// инструменты и настроения берутся только из справочника. Виды с nil в set остаются как в current
+func (s *Service) SetSampleTags(ctx context.Context, sampleID uuid.UUID, current []entity.Tag, set entity.TagSet) error {
+	ids, err := s.resolveTags(ctx, current, set)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.SetSampleTags(ctx, sampleID, ids)
+}
+
+func (s *Service) SetPackTags(ctx context.Context, packID uuid.UUID, current []entity.Tag, set entity.TagSet) error {
+	ids, err := s.resolveTags(ctx, current, set)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.SetPackTags(ctx, packID, ids)
+}
+
+// ResolveTags возвращает id тегов набора, не привязывая их. Свободные теги создаются, как и в SetSampleTags
+func (s *Service) ResolveTags(ctx context.Context, set entity.TagSet) ([]uuid.UUID, error) {
+	return s.resolveTags(ctx, nil, set)
+}
+
+// ValidateTags проверяет теги без сохранения, чтобы не создавать семпл с неверными тегами
+func (s *Service) ValidateTags(ctx context.Context, set entity.TagSet) error {
+	for kind, names := range byKind(set) {
+		tags, err := normalizeTags(kind, names)
+		if err != nil {
+			return err
+		}
+		if kind != constant.TagKindTag {
+			if _, err = s.existing(ctx, kind, tags); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) resolveTags(ctx context.Context, current []entity.Tag, set entity.TagSet) ([]uuid.UUID, error) {
+	requested := byKind(set)
+
+	var ids []uuid.UUID
+	for _, t := range current {
+		if _, replaced := requested[t.Kind]; !replaced {
+			ids = append(ids, t.ID)
+		}
+	}
+
+	for kind, names := range requested {
+		tags, err := normalizeTags(kind, names)
+		if err != nil {
+			return nil, err
+		}
+		if len(tags) == 0 {
+			continue
+		}
+
+		if kind == constant.TagKindTag {
+			tags, err = s.repo.EnsureTags(ctx, kind, tags)
+		} else {
+			tags, err = s.existing(ctx, kind, tags)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for _, t := range tags {
+			ids = append(ids, t.ID)
+		}
+	}
+
+	return ids, nil
+}
+
+// existing возвращает теги справочника, неизвестный тег - ошибка
+func (s *Service) existing(ctx context.Context, kind string, tags []entity.Tag) ([]entity.Tag, error) {
+	if len(tags) == 0 {
+		return nil, nil
+	}
+
+	slugs := make([]string, len(tags))
+	for i, t := range tags {
+		slugs[i] = t.Slug
+	}
+
+	found, err := s.repo.TagsBySlugs(ctx, kind, slugs)
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]bool, len(found))
+	for _, t := range found {
+		known[t.Slug] = true
+	}
+	for _, t := range tags {
+		if !known[t.Slug] {
+			return nil, fmt.Errorf("%w: unknown %s %q", domain.ErrInvalidTag, kind, t.Name)
+		}
+	}
+
+	return found, nil
+}
+
+// Genres - справочник жанров со счетчиками семплов и паков
+func (s *Service) Genres(ctx context.Context) ([]entity.GenreNode, error) {
+	return s.repo.Genres(ctx)
+}
+
+// Tags - теги вида kind со счетчиками семплов, search - начало названия
+func (s *Service) Tags(ctx context.Context, kind, search string, limit int) ([]entity.Tag, error) {
+	if _, ok := constant.TagKinds[kind]; kind != "" && !ok {
+		return nil, fmt.Errorf("%w: unknown kind %q", domain.ErrInvalidTag, kind)
+	}
+	if limit <= 0 {
+		limit = defaultTagsLimit
+	}
+	limit = min(limit, maxTagsLimit)
+
+	return s.repo.Tags(ctx, kind, slug.Make(search), limit)
+}
+
+// CreateGenre добавляет жанр в справочник, parent - slug родителя или пусто для корневого
+func (s *Service) CreateGenre(ctx context.Context, name, parent string) (entity.GenreNode, error) {
+	genre := entity.GenreNode{CreatedAt: time.Now()}
+
+	var err error
+	if genre.Name, genre.Slug, err = normalizeName(name, domain.ErrInvalidGenre); err != nil {
+		return genre, err
+	}
+	if parent != "" {
+		if genre.ParentID, err = s.parentID(ctx, parent); err != nil {
+			return genre, err
+		}
+	}
+
+	genre.ID, err = s.repo.CreateGenre(ctx, genre)
+	if err != nil {
+		return genre, err
+	}
+
+	return genre, nil
+}
+
+// UpdateGenre переименовывает жанр и меняет родителя. nil - оставить как есть, пустой parent - сделать корневым
+func (s *Service) UpdateGenre(ctx context.Context, genreSlug string, name, parent *string) (entity.GenreNode, error) {
+	genre, err := s.repo.GenreBySlug(ctx, genreSlug)
+	if err != nil {
+		return genre, err
+	}
+
+	if name != nil {
+		if genre.Name, genre.Slug, err = normalizeName(*name, domain.ErrInvalidGenre); err != nil {
+			return genre, err
+		}
+	}
+
+	if parent != nil {
+		genre.ParentID = nil
+		if *parent != "" {
+			if genre.ParentID, err = s.parentID(ctx, *parent); err != nil {
+				return genre, err
+			}
+			if err = s.checkCycle(ctx, genre.ID, *genre.ParentID); err != nil {
+				return genre, err
+			}
+		}
+	}
+
+	if err = s.repo.UpdateGenre(ctx, genre); err != nil {
+		return genre, err
+	}
+
+	return genre, nil
+}
+
+// MergeGenre переносит все из жанра genreSlug в into и удаляет его
+func (s *Service) MergeGenre(ctx context.Context, genreSlug, into string) (entity.GenreNode, error) {
+	source, err := s.repo.GenreBySlug(ctx, genreSlug)
+	if err != nil {
+		return entity.GenreNode{}, err
+	}
+
+	target, err := s.repo.GenreBySlug(ctx, slug.Make(into))
+	if errors.Is(err, domain.ErrNotFound) {
+		return target, fmt.Errorf("%w: unknown genre %q", domain.ErrInvalidGenre, into)
+	}
+	if err != nil {
+		return target, err
+	}
+	if source.ID == target.ID {
+		return target, fmt.Errorf("%w: genre can not be merged into itself", domain.ErrInvalidGenre)
+	}
+	// target внутри поддерева source после удаления source оторвался бы от дерева
+	if err = s.checkCycle(ctx, source.ID, target.ID); err != nil {
+		return target, err
+	}
+
+	if err = s.repo.MergeGenres(ctx, source, target); err != nil {
+		return target, err
+	}
+
+	return target, nil
+}
+
+// CreateTag добавляет тег в справочник, так заводятся новые инструменты и настроения
+func (s *Service) CreateTag(ctx context.Context, kind, name string) (entity.Tag, error) {
+	tag := entity.Tag{Kind: kind}
+	if _, ok := constant.TagKinds[kind]; !ok {
+		return tag, fmt.Errorf("%w: unknown kind %q", domain.ErrInvalidTag, kind)
+	}
+
+	var err error
+	if tag.Name, tag.Slug, err = normalizeName(name, domain.ErrInvalidTag); err != nil {
+		return tag, err
+	}
+
+	tag.ID, err = s.repo.CreateTag(ctx, tag)
+	if err != nil {
+		return tag, err
+	}
+
+	return tag, nil
+}
+
+// RenameTag меняет название и slug тега. Если тег с новым slug уже есть - их нужно объединить
+func (s *Service) RenameTag(ctx context.Context, id uuid.UUID, name string) (entity.Tag, error) {
+	tag, err := s.repo.TagByID(ctx, id)
+	if err != nil {
+		return tag, err
+	}
+
+	if tag.Name, tag.Slug, err = normalizeName(name, domain.ErrInvalidTag); err != nil {
+		return tag, err
+	}
+
+	if err = s.repo.RenameTag(ctx, tag); err != nil {
+		return tag, err
+	}
+
+	return tag, nil
+}
+
+// MergeTags переносит семплы и паки тега id на тег into того же вида и удаляет id
+func (s *Service) MergeTags(ctx context.Context, id, into uuid.UUID) (entity.Tag, error) {
+	source, err := s.repo.TagByID(ctx, id)
+	if err != nil {
+		return source, err
+	}
+
+	target, err := s.repo.TagByID(ctx, into)
+	if errors.Is(err, domain.ErrNotFound) {
+		return target, fmt.Errorf("%w: unknown tag %s", domain.ErrInvalidTag, into)
+	}
+	if err != nil {
+		return target, err
+	}
+	if source.ID == target.ID {
+		return target, fmt.Errorf("%w: tag can not be merged into itself", domain.ErrInvalidTag)
+	}
+	if source.Kind != target.Kind {
+		return target, fmt.Errorf("%w: can not merge %s into %s", domain.ErrInvalidTag, source.Kind, target.Kind)
+	}
+
+	if err = s.repo.MergeTags(ctx, source.ID, target.ID); err != nil {
+		return target, err
+	}
+
+	return target, nil
+}
+
+func (s *Service) parentID(ctx context.Context, parent string) (*uuid.UUID, error) {
+	node, err := s.repo.GenreBySlug(ctx, slug.Make(parent))
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil, fmt.Errorf("%w: unknown parent genre %q", domain.ErrInvalidGenre, parent)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &node.ID, nil
+}
+
+// checkCycle запрещает делать жанр потомком самого себя
+func (s *Service) checkCycle(ctx context.Context, genreID, parentID uuid.UUID) error {
+	genres, err := s.repo.Genres(ctx)
+	if err != nil {
+		return err
+	}
+
+	parents := make(map[uuid.UUID]*uuid.UUID, len(genres))
+	for _, g := range genres {
+		parents[g.ID] = g.ParentID
+	}
+
+	for id := &parentID; id != nil; id = parents[*id] {
+		if *id == genreID {
+			return fmt.Errorf("%w: genre can not be nested into itself", domain.ErrInvalidGenre)
+		}
+	}
+
+	return nil
+}
+
+func byKind(set entity.TagSet) map[string][]string {
+	kinds := make(map[string][]string, len(maxTags))
+	if set.Tags != nil {
+		kinds[constant.TagKindTag] = set.Tags
+	}
+	if set.Instruments != nil {
+		kinds[constant.TagKindInstrument] = set.Instruments
+	}
+	if set.Moods != nil {
+		kinds[constant.TagKindMood] = set.Moods
+	}
+
+	return kinds
+}
+
+// normalizeTags проверяет названия и убирает повторы в разном написании
+func normalizeTags(kind string, names []string) ([]entity.Tag, error) {
+	seen := make(map[string]bool, len(names))
+	var tags []entity.Tag
+	for _, n := range names {
+		name, key, err := normalizeName(n, domain.ErrInvalidTag)
+		if err != nil {
+			return nil, err
+		}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		tags = append(tags, entity.Tag{Kind: kind, Slug: key, Name: name})
+	}
+
+	if len(tags) > maxTags[kind] {
+		return nil, fmt.Errorf("%w: at most %d of kind %s", domain.ErrInvalidTag, maxTags[kind], kind)
+	}
+
+	return tags, nil
+}
+
+// normalizeName схлопывает пробелы в названии и проверяет длину, возвращает название и slug
+func normalizeName(name string, invalid error) (string, string, error) {
+	name = strings.Join(strings.Fields(name), " ")
+	if length := utf8.RuneCountInString(name); length < minNameLength || length > maxNameLength {
+		return "", "", fmt.Errorf("%w: name must be from %d to %d characters", invalid, minNameLength, maxNameLength)
+	}
+
+	key := slug.Make(name)
+	if key == "" {
+		return "", "", fmt.Errorf("%w: name %q must contain letters or digits", invalid, name)
+	}
+
+	return name, key, nil
+}
@@ -0,0 +1,197 @@
+package taxonomy
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/musicman-backend/internal/domain"
+	"github.com/musicman-backend/internal/domain/constant"
+	"github.com/musicman-backend/internal/domain/entity"
+)
+
+// fakeRepo - справочник жанров по slug и теги по id
+type fakeRepo struct {
+	Repository
+	genres     map[string]entity.GenreNode
+	tags       map[uuid.UUID]entity.Tag
+	sampleTags []uuid.UUID
+}
+
+func newFakeRepo() *fakeRepo {
+	return &fakeRepo{genres: map[string]entity.GenreNode{}, tags: map[uuid.UUID]entity.Tag{}}
+}
+
+func (f *fakeRepo) genre(slug string, parent *entity.GenreNode) entity.GenreNode {
+	node := entity.GenreNode{ID: uuid.New(), Slug: slug, Name: slug}
+	if parent != nil {
+		node.ParentID = &parent.ID
+	}
+	f.genres[slug] = node
+	return node
+}
+
+func (f *fakeRepo) tag(kind, slug string) entity.Tag {
+	tag := entity.Tag{ID: uuid.New(), Kind: kind, Slug: slug, Name: slug}
+	f.tags[tag.ID] = tag
+	return tag
+}
+
+func (f *fakeRepo) Genres(context.Context) ([]entity.GenreNode, error) {
+	var genres []entity.GenreNode
+	for _, g := range f.genres {
+		genres = append(genres, g)
+	}
+	return genres, nil
+}
+
+func (f *fakeRepo) GenreBySlug(_ context.Context, slug string) (entity.GenreNode, error) {
+	genre, ok := f.genres[slug]
+	if !ok {
+		return genre, domain.ErrNotFound
+	}
+	return genre, nil
+}
+
+func (f *fakeRepo) UpdateGenre(context.Context, entity.GenreNode) error {
+	return nil
+}
+
+func (f *fakeRepo) TagByID(_ context.Context, id uuid.UUID) (entity.Tag, error) {
+	tag, ok := f.tags[id]
+	if !ok {
+		return tag, domain.ErrNotFound
+	}
+	return tag, nil
+}
+
+func (f *fakeRepo) TagsBySlugs(_ context.Context, kind string, slugs []string) ([]entity.Tag, error) {
+	var found []entity.Tag
+	for _, t := range f.tags {
+		if t.Kind == kind && slices.Contains(slugs, t.Slug) {
+			found = append(found, t)
+		}
+	}
+	return found, nil
+}
+
+// EnsureTags создает недостающие теги, как INSERT ... ON CONFLICT
+func (f *fakeRepo) EnsureTags(ctx context.Context, kind string, tags []entity.Tag) ([]entity.Tag, error) {
+	var ensured []entity.Tag
+	for _, t := range tags {
+		found, _ := f.TagsBySlugs(ctx, kind, []string{t.Slug})
+		if len(found) == 0 {
+			found = []entity.Tag{f.tag(kind, t.Slug)}
+		}
+		ensured = append(ensured, found[0])
+	}
+	return ensured, nil
+}
+
+func (f *fakeRepo) SetSampleTags(_ context.Context, _ uuid.UUID, tagIDs []uuid.UUID) error {
+	f.sampleTags = tagIDs
+	return nil
+}
+
+func TestResolveGenreSpelling(t *testing.T) {
+	repo := newFakeRepo()
+	repo.genre("hip-hop", nil)
+	s := New(repo)
+
+	for _, spelling := range []string{"Hip-Hop", "hip hop", " HIP_HOP "} {
+		genre, err := s.ResolveGenre(context.Background(), spelling)
+		if err != nil || genre != "hip-hop" {
+			t.Fatalf("%q resolved to %q, %v", spelling, genre, err)
+		}
+	}
+	for _, unknown := range []string{"hiphop", "", "--"} {
+		if _, err := s.ResolveGenre(context.Background(), unknown); !errors.Is(err, domain.ErrInvalidGenre) {
+			t.Fatalf("%q: got %v, want ErrInvalidGenre", unknown, err)
+		}
+	}
+}
+
+func TestSetSampleTags(t *testing.T) {
+	repo := newFakeRepo()
+	piano := repo.tag(constant.TagKindInstrument, "piano")
+	dark := repo.tag(constant.TagKindMood, "dark")
+	s := New(repo)
+	ctx := context.Background()
+
+	err := s.SetSampleTags(ctx, uuid.New(), nil, entity.TagSet{
+		Tags:        []string{"Lo-Fi", "lo fi", "vinyl"},
+		Instruments: []string{"Piano"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(repo.sampleTags) != 3 || len(repo.tags) != 4 {
+		t.Fatalf("%d sample tags, %d tags total: free tags must be created once per spelling", len(repo.sampleTags), len(repo.tags))
+	}
+
+	// настроения заменяются, остальные виды остаются как были
+	current := []entity.Tag{piano, repo.tags[repo.sampleTags[0]]}
+	if err = s.SetSampleTags(ctx, uuid.New(), current, entity.TagSet{Moods: []string{"Dark"}}); err != nil {
+		t.Fatal(err)
+	}
+	if len(repo.sampleTags) != 3 || !slices.Contains(repo.sampleTags, piano.ID) || !slices.Contains(repo.sampleTags, dark.ID) {
+		t.Fatalf("sample tags %v, want the current ones kept and the mood added", repo.sampleTags)
+	}
+
+	invalid := map[string]entity.TagSet{
+		"unknown instrument": {Instruments: []string{"theremin"}},
+		"too short":          {Tags: []string{"x"}},
+		"too many moods":     {Moods: []string{"dark", "calm", "epic", "sad"}},
+	}
+	for name, set := range invalid {
+		if err = s.ValidateTags(ctx, set); !errors.Is(err, domain.ErrInvalidTag) {
+			t.Fatalf("%s: got %v, want ErrInvalidTag", name, err)
+		}
+	}
+}
+
+func TestGenreHierarchyCycles(t *testing.T) {
+	repo := newFakeRepo()
+	electronic := repo.genre("electronic", nil)
+	house := repo.genre("house", &electronic)
+	repo.genre("deep-house", &house)
+	s := New(repo)
+	ctx := context.Background()
+
+	parent := "Deep House"
+	if _, err := s.UpdateGenre(ctx, "electronic", nil, &parent); !errors.Is(err, domain.ErrInvalidGenre) {
+		t.Fatalf("nesting a genre into its descendant: got %v, want ErrInvalidGenre", err)
+	}
+	if _, err := s.MergeGenre(ctx, "house", "deep-house"); !errors.Is(err, domain.ErrInvalidGenre) {
+		t.Fatalf("merging a genre into its descendant: got %v, want ErrInvalidGenre", err)
+	}
+	if _, err := s.MergeGenre(ctx, "house", "House"); !errors.Is(err, domain.ErrInvalidGenre) {
+		t.Fatalf("merging a genre into itself: got %v, want ErrInvalidGenre", err)
+	}
+
+	root := ""
+	updated, err := s.UpdateGenre(ctx, "house", nil, &root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.ParentID != nil {
+		t.Fatalf("empty parent must make the genre a root, got parent %s", updated.ParentID)
+	}
+}
+
+func TestMergeTagsOfDifferentKinds(t *testing.T) {
+	repo := newFakeRepo()
+	piano := repo.tag(constant.TagKindInstrument, "piano")
+	dark := repo.tag(constant.TagKindMood, "dark")
+	s := New(repo)
+
+	if _, err := s.MergeTags(context.Background(), piano.ID, dark.ID); !errors.Is(err, domain.ErrInvalidTag) {
+		t.Fatalf("got %v, want ErrInvalidTag", err)
+	}
+	if _, err := s.MergeTags(context.Background(), piano.ID, piano.ID); !errors.Is(err, domain.ErrInvalidTag) {
+		t.Fatalf("merge into itself: got %v, want ErrInvalidTag", err)
+	}
+}
@@ -13,27 +13,34 @@ import (
 )
 
 const (
-	Day = 24 * time.Hour
-
-	ExpireTime = 365 * Day
-
 	issuer = "musicman-backend"
+
+	// challengeAudience - токен второго шага входа, для доступа к API он не годится
+	challengeAudience   = "2fa"
+	defaultChallengeTTL = 5 * time.Minute
 )
 
 type Service struct {
-	secret []byte
+	secret       []byte
+	challengeTTL time.Duration
 }
 
-func New(secret string) *Service {
-	return &Service{secret: []byte(secret)}
+func New(secret string, challengeTTL time.Duration) *Service {
+	if challengeTTL <= 0 {
+		challengeTTL = defaultChallengeTTL
+	}
+
+	return &Service{secret: []byte(secret), challengeTTL: challengeTTL}
 }
 
-func (s *Service) CreateToken(ctx context.Context, user entity.User) (string, error) {
+// CreateToken выдает JWT сессии sessionID, действующий до expiresAt
+func (s *Service) CreateToken(ctx context.Context, user entity.User, sessionID uuid.UUID, expiresAt time.Time) (string, error) {
 	claims := entity.JWTClaims{
-		UserUUID: user.UUID,
-		Login:    user.Login,
+		UserUUID:  user.UUID,
+		Login:     user.Login,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ExpireTime)),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
 			Issuer:    issuer,
@@ -58,6 +65,46 @@ func (s *Service) VerifyToken(ctx context.Context, tokenString string) (entity.J
 		return entity.JWTClaims{}, fmt.Errorf("parse token failed: %w", err)
 	}
 
+	// у обычного токена аудитории нет, так токен второго шага не пройдет вместо него
+	if claims, ok := token.Claims.(*entity.JWTClaims); ok && token.Valid && len(claims.Audience) == 0 {
+		return *claims, nil
+	}
+
+	return entity.JWTClaims{}, domain.ErrInvalidToken
+}
+
+// CreateChallengeToken выдает короткоживущий токен, который после пароля меняется на JWT по коду 2FA
+func (s *Service) CreateChallengeToken(ctx context.Context, user entity.User) (string, error) {
+	claims := entity.JWTClaims{
+		UserUUID: user.UUID,
+		Login:    user.Login,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(s.challengeTTL)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			NotBefore: jwt.NewNumericDate(time.Now()),
+			Issuer:    issuer,
+			Subject:   user.UUID.String(),
+			Audience:  jwt.ClaimStrings{challengeAudience},
+			ID:        uuid.New().String(),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+
+	tokenString, err := token.SignedString(s.secret)
+	if err != nil {
+		return "", fmt.Errorf("sign challenge token failed: %w", err)
+	}
+
+	return tokenString, nil
+}
+
+func (s *Service) VerifyChallengeToken(ctx context.Context, tokenString string) (entity.JWTClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &entity.JWTClaims{}, s.keyFunc, jwt.WithAudience(challengeAudience))
+	if err != nil {
+		return entity.JWTClaims{}, domain.ErrInvalidToken
+	}
+
 	if claims, ok := token.Claims.(*entity.JWTClaims); ok && token.Valid {
 		return *claims, nil
 	}
@@ -0,0 +1,321 @@
+package twofactor
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/skip2/go-qrcode"
+
+	"github.com/musicman-backend/config"
+	"github.com/musicman-backend/internal/domain"
+	"github.com/musicman-backend/internal/domain/entity"
+	"github.com/musicman-backend/pkg/totp"
+)
+
+const (
+	recoveryCodesCount = 10
+	// код восстановления - 10 символов без похожих друг на друга букв и цифр, в виде xxxxx-xxxxx
+	recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+	recoveryCodeLength   = 10
+
+	// допускаем расхождение часов телефона и сервера на один интервал в каждую сторону
+	totpSkew = 1
+	qrSize   = 256
+)
+
+type UserRepository interface {
+	GetUserByUUID(ctx context.Context, userUUID uuid.UUID) (entity.User, error)
+	SetTOTPSecret(ctx context.Context, userUUID uuid.UUID, secret string) error
+	EnableTOTP(ctx context.Context, userUUID uuid.UUID, enabledAt time.Time) error
+	DisableTOTP(ctx context.Context, userUUID uuid.UUID) error
+	UseTOTPStep(ctx context.Context, userUUID uuid.UUID, step int64) (bool, error)
+}
+
+type RecoveryCodeRepository interface {
+	Replace(ctx context.Context, userUUID uuid.UUID, hashes []string, createdAt time.Time) error
+	Use(ctx context.Context, userUUID uuid.UUID, hash string, usedAt time.Time) error
+	CountUnused(ctx context.Context, userUUID uuid.UUID) (int, error)
+	DeleteByUser(ctx context.Context, userUUID uuid.UUID) error
+}
+
+// Limiter считает неверные коды по пользователю и блокирует подбор
+type Limiter interface {
+	AllowTwoFactor(ctx context.Context, userUUID uuid.UUID) error
+	TwoFactorFailed(ctx context.Context, userUUID uuid.UUID) error
+	TwoFactorSucceeded(ctx context.Context, userUUID uuid.UUID) error
+}
+
+type Service struct {
+	users    UserRepository
+	recovery RecoveryCodeRepository
+	limiter  Limiter
+	cfg      config.TwoFactor
+}
+
+func New(users UserRepository, recovery RecoveryCodeRepository, limiter Limiter, cfg config.TwoFactor) *Service {
+	return &Service{
+		users:    users,
+		recovery: recovery,
+		limiter:  limiter,
+		cfg:      cfg,
+	}
+}
+
+func (s *Service) Status(ctx context.Context, userUUID uuid.UUID) (entity.TwoFactorStatus, error) {
+	user, err := s.users.GetUserByUUID(ctx, userUUID)
+	if err != nil {
+		return entity.TwoFactorStatus{}, fmt.Errorf("failed to get user: %w", err)
+	}
+	if !user.TwoFactorEnabled() {
+		return entity.TwoFactorStatus{}, nil
+	}
+
+	count, err := s.recovery.CountUnused(ctx, userUUID)
+	if err != nil {
+		return entity.TwoFactorStatus{}, err
+	}
+
+	return entity.TwoFactorStatus{Enabled: true, EnabledAt: user.TOTPEnabledAt, RecoveryCodes: count}, nil
+}
+
+// Enroll начинает настройку 2FA: генерирует новый секрет, включится он только после Confirm
+func (s *Service) Enroll(ctx context.Context, userUUID uuid.UUID) (entity.TwoFactorEnrollment, error) {
+	user, err := s.users.GetUserByUUID(ctx, userUUID)
+	if err != nil {
+		return entity.TwoFactorEnrollment{}, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user.TwoFactorEnabled() {
+		return entity.TwoFactorEnrollment{}, domain.ErrTwoFactorEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return entity.TwoFactorEnrollment{}, err
+	}
+
+	if err = s.users.SetTOTPSecret(ctx, userUUID, secret); err != nil {
+		return entity.TwoFactorEnrollment{}, err
+	}
+
+	uri := totp.URI(s.cfg.Issuer, user.Login, secret)
+	png, err := qrcode.Encode(uri, qrcode.Medium, qrSize)
+	if err != nil {
+		return entity.TwoFactorEnrollment{}, fmt.Errorf("failed to encode qr code: %w", err)
+	}
+
+	return entity.TwoFactorEnrollment{Secret: secret, URI: uri, QRCode: png}, nil
+}
+
+// Confirm включает 2FA по первому коду из приложения и возвращает коды восстановления.
+// Коды показываются один раз, хранятся только их хеши
+func (s *Service) Confirm(ctx context.Context, userUUID uuid.UUID, code string) ([]string, error) {
+	user, err := s.users.GetUserByUUID(ctx, userUUID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user.TwoFactorEnabled() {
+		return nil, domain.ErrTwoFactorEnabled
+	}
+	if user.TOTPSecret == "" {
+		return nil, domain.ErrTwoFactorDisabled
+	}
+
+	err = s.guard(ctx, user.UUID, func() error {
+		return s.checkTOTP(ctx, user, code)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	codes, err := s.replaceRecoveryCodes(ctx, userUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.users.EnableTOTP(ctx, userUUID, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// Disable выключает 2FA, нужен действующий код или код восстановления
+func (s *Service) Disable(ctx context.Context, userUUID uuid.UUID, code string) error {
+	user, err := s.users.GetUserByUUID(ctx, userUUID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if err = s.Verify(ctx, user, code); err != nil {
+		return err
+	}
+
+	if err = s.users.DisableTOTP(ctx, userUUID); err != nil {
+		return err
+	}
+
+	return s.recovery.DeleteByUser(ctx, userUUID)
+}
+
+// RegenerateRecoveryCodes выдает новый набор кодов, старые перестают действовать
+func (s *Service) RegenerateRecoveryCodes(ctx context.Context, userUUID uuid.UUID, code string) ([]string, error) {
+	user, err := s.users.GetUserByUUID(ctx, userUUID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if err = s.Verify(ctx, user, code); err != nil {
+		return nil, err
+	}
+
+	return s.replaceRecoveryCodes(ctx, userUUID)
+}
+
+// Verify проверяет код из приложения, а если это не он - код восстановления.
+// Принятый код повторно не принимается, после нескольких неверных кодов подряд
+// проверка блокируется и возвращается *domain.RetryAfterError
+func (s *Service) Verify(ctx context.Context, user entity.User, code string) error {
+	if !user.TwoFactorEnabled() {
+		return domain.ErrTwoFactorDisabled
+	}
+
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return domain.ErrTwoFactorRequired
+	}
+
+	return s.guard(ctx, user.UUID, func() error {
+		if len(code) == totp.Digits {
+			return s.checkTOTP(ctx, user, code)
+		}
+
+		err := s.recovery.Use(ctx, user.UUID, hashRecoveryCode(code), time.Now())
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.ErrInvalidTwoFactorCode
+		}
+
+		return err
+	})
+}
+
+// CheckSensitive - свежая проверка 2FA перед важным действием. Без включенной 2FA код не нужен
+func (s *Service) CheckSensitive(ctx context.Context, userUUID uuid.UUID, code string) error {
+	user, err := s.users.GetUserByUUID(ctx, userUUID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if !user.TwoFactorEnabled() {
+		return nil
+	}
+
+	return s.Verify(ctx, user, code)
+}
+
+// guard пропускает проверку кода через счетчик неверных попыток. Ошибки хранилища лимитов
+// не мешают проверке, как и при входе
+func (s *Service) guard(ctx context.Context, userUUID uuid.UUID, check func() error) error {
+	err := s.limiter.AllowTwoFactor(ctx, userUUID)
+
+	var retryErr *domain.RetryAfterError
+	if errors.As(err, &retryErr) {
+		return err
+	}
+	if err != nil {
+		slog.Error("failed to check two-factor rate limit", slog.String("err", err.Error()))
+	}
+
+	err = check()
+	switch {
+	case errors.Is(err, domain.ErrInvalidTwoFactorCode):
+		if limitErr := s.limiter.TwoFactorFailed(ctx, userUUID); limitErr != nil {
+			slog.Error("failed to register two-factor failure", slog.String("err", limitErr.Error()))
+		}
+	case err == nil:
+		if limitErr := s.limiter.TwoFactorSucceeded(ctx, userUUID); limitErr != nil {
+			slog.Error("failed to reset two-factor failures", slog.String("err", limitErr.Error()))
+		}
+	}
+
+	return err
+}
+
+func (s *Service) checkTOTP(ctx context.Context, user entity.User, code string) error {
+	step, ok := totp.Validate(user.TOTPSecret, code, time.Now(), totpSkew)
+	if !ok {
+		return domain.ErrInvalidTwoFactorCode
+	}
+
+	fresh, err := s.users.UseTOTPStep(ctx, user.UUID, step)
+	if err != nil {
+		return err
+	}
+	if !fresh {
+		return domain.ErrInvalidTwoFactorCode
+	}
+
+	return nil
+}
+
+func (s *Service) replaceRecoveryCodes(ctx context.Context, userUUID uuid.UUID) ([]string, error) {
+	codes := make([]string, recoveryCodesCount)
+	hashes := make([]string, recoveryCodesCount)
+	for i := range codes {
+		code, err := newRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes[i] = code
+		hashes[i] = hashRecoveryCode(code)
+	}
+
+	if err := s.recovery.Replace(ctx, userUUID, hashes, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// newRecoveryCode отбрасывает байты из неполного последнего круга алфавита,
+// иначе первые буквы выпадали бы чаще остальных
+func newRecoveryCode() (string, error) {
+	limit := 256 - 256%len(recoveryCodeAlphabet)
+	raw := make([]byte, recoveryCodeLength)
+
+	code := make([]byte, 0, recoveryCodeLength+1)
+	for len(code) < recoveryCodeLength+1 {
+		if _, err := rand.Read(raw); err != nil {
+			return "", fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+
+		for _, b := range raw {
+			if int(b) >= limit {
+				continue
+			}
+			if len(code) == recoveryCodeLength/2 {
+				code = append(code, '-')
+			}
+			code = append(code, recoveryCodeAlphabet[int(b)%len(recoveryCodeAlphabet)])
+			if len(code) == recoveryCodeLength+1 {
+				break
+			}
+		}
+	}
+
+	return string(code), nil
+}
+
+// hashRecoveryCode не учитывает регистр и дефис, чтобы код можно было ввести как удобно
+func hashRecoveryCode(code string) string {
+	code = strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(code))
+
+	return hex.EncodeToString(sum[:])
+}
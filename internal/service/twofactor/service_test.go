@@ -0,0 +1,219 @@
+package twofactor
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/musicman-backend/config"
+	"github.com/musicman-backend/internal/domain"
+	"github.com/musicman-backend/internal/domain/entity"
+	"github.com/musicman-backend/internal/service/ratelimit"
+	"github.com/musicman-backend/pkg/totp"
+)
+
+type fakeUsers struct {
+	user entity.User
+}
+
+func (f *fakeUsers) GetUserByUUID(_ context.Context, userUUID uuid.UUID) (entity.User, error) {
+	if userUUID != f.user.UUID {
+		return entity.User{}, domain.ErrNotFound
+	}
+	return f.user, nil
+}
+
+func (f *fakeUsers) SetTOTPSecret(_ context.Context, _ uuid.UUID, secret string) error {
+	f.user.TOTPSecret = secret
+	return nil
+}
+
+func (f *fakeUsers) EnableTOTP(_ context.Context, _ uuid.UUID, enabledAt time.Time) error {
+	f.user.TOTPEnabledAt = &enabledAt
+	return nil
+}
+
+func (f *fakeUsers) DisableTOTP(context.Context, uuid.UUID) error {
+	f.user.TOTPSecret = ""
+	f.user.TOTPEnabledAt = nil
+	return nil
+}
+
+func (f *fakeUsers) UseTOTPStep(_ context.Context, _ uuid.UUID, step int64) (bool, error) {
+	if step <= f.user.TOTPLastStep {
+		return false, nil
+	}
+	f.user.TOTPLastStep = step
+	return true, nil
+}
+
+type fakeRecovery struct {
+	unused map[string]bool
+}
+
+func (f *fakeRecovery) Replace(_ context.Context, _ uuid.UUID, hashes []string, _ time.Time) error {
+	f.unused = make(map[string]bool, len(hashes))
+	for _, hash := range hashes {
+		f.unused[hash] = true
+	}
+	return nil
+}
+
+func (f *fakeRecovery) Use(_ context.Context, _ uuid.UUID, hash string, _ time.Time) error {
+	if !f.unused[hash] {
+		return domain.ErrNotFound
+	}
+	delete(f.unused, hash)
+	return nil
+}
+
+func (f *fakeRecovery) CountUnused(context.Context, uuid.UUID) (int, error) {
+	return len(f.unused), nil
+}
+
+func (f *fakeRecovery) DeleteByUser(context.Context, uuid.UUID) error {
+	f.unused = nil
+	return nil
+}
+
+type brokenLimiter struct{}
+
+func (brokenLimiter) AllowTwoFactor(context.Context, uuid.UUID) error {
+	return errors.New("store is down")
+}
+
+func (brokenLimiter) TwoFactorFailed(context.Context, uuid.UUID) error {
+	return errors.New("store is down")
+}
+
+func (brokenLimiter) TwoFactorSucceeded(context.Context, uuid.UUID) error {
+	return errors.New("store is down")
+}
+
+func newEnabled(t *testing.T, limiter Limiter) (*Service, *fakeUsers, []string) {
+	t.Helper()
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	users := &fakeUsers{user: entity.User{UUID: uuid.New(), Login: "user", TOTPSecret: secret}}
+	s := New(users, &fakeRecovery{}, limiter, config.TwoFactor{Issuer: "Musicman"})
+
+	codes, err := s.Confirm(context.Background(), users.user.UUID, currentCode(t, secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return s, users, codes
+}
+
+func currentCode(t *testing.T, secret string) string {
+	t.Helper()
+
+	code, err := totp.Code(secret, totp.Step(time.Now()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code
+}
+
+func newLimiter(threshold int) *ratelimit.Service {
+	return ratelimit.New(ratelimit.NewMemoryStore(), config.RateLimit{Lockout: config.Lockout{
+		Threshold:    threshold,
+		BaseDuration: time.Minute,
+	}})
+}
+
+func TestVerifyLocksOutAfterWrongCodes(t *testing.T) {
+	ctx := context.Background()
+	s, users, codes := newEnabled(t, newLimiter(2))
+
+	for _, code := range []string{"000000", "wrong-code"} {
+		if err := s.CheckSensitive(ctx, users.user.UUID, code); !errors.Is(err, domain.ErrInvalidTwoFactorCode) {
+			t.Fatalf("code %q: got %v, want ErrInvalidTwoFactorCode", code, err)
+		}
+	}
+
+	err := s.CheckSensitive(ctx, users.user.UUID, codes[0])
+
+	var retryErr *domain.RetryAfterError
+	if !errors.As(err, &retryErr) {
+		t.Fatalf("locked user must get RetryAfterError even with a valid code, got %v", err)
+	}
+
+	if err = s.Disable(ctx, users.user.UUID, codes[0]); !errors.As(err, &retryErr) {
+		t.Fatalf("disable must be locked too, got %v", err)
+	}
+	if !users.user.TwoFactorEnabled() {
+		t.Fatal("2FA must stay enabled while locked")
+	}
+}
+
+func TestVerifySuccessResetsFailures(t *testing.T) {
+	ctx := context.Background()
+	s, users, codes := newEnabled(t, newLimiter(2))
+
+	if err := s.CheckSensitive(ctx, users.user.UUID, "000000"); !errors.Is(err, domain.ErrInvalidTwoFactorCode) {
+		t.Fatal(err)
+	}
+	if err := s.CheckSensitive(ctx, users.user.UUID, codes[0]); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.CheckSensitive(ctx, users.user.UUID, "000000"); !errors.Is(err, domain.ErrInvalidTwoFactorCode) {
+		t.Fatalf("failures must be reset by a valid code, got %v", err)
+	}
+}
+
+func TestVerifyRecoveryCodeSingleUse(t *testing.T) {
+	ctx := context.Background()
+	s, users, codes := newEnabled(t, newLimiter(5))
+
+	if err := s.CheckSensitive(ctx, users.user.UUID, strings.ToUpper(codes[1])); err != nil {
+		t.Fatalf("recovery code must be case insensitive: %v", err)
+	}
+	if err := s.CheckSensitive(ctx, users.user.UUID, codes[1]); !errors.Is(err, domain.ErrInvalidTwoFactorCode) {
+		t.Fatalf("used recovery code: got %v, want ErrInvalidTwoFactorCode", err)
+	}
+}
+
+func TestVerifyFailsOpenOnLimiterErrors(t *testing.T) {
+	ctx := context.Background()
+	s, users, codes := newEnabled(t, brokenLimiter{})
+
+	if err := s.CheckSensitive(ctx, users.user.UUID, codes[0]); err != nil {
+		t.Fatalf("limiter errors must not block a valid code: %v", err)
+	}
+	if err := s.CheckSensitive(ctx, users.user.UUID, "000000"); !errors.Is(err, domain.ErrInvalidTwoFactorCode) {
+		t.Fatalf("got %v, want ErrInvalidTwoFactorCode", err)
+	}
+}
+
+func TestNewRecoveryCode(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		code, err := newRecoveryCode()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(code) != recoveryCodeLength+1 || code[recoveryCodeLength/2] != '-' {
+			t.Fatalf("code %q is not in xxxxx-xxxxx form", code)
+		}
+		for _, r := range strings.Replace(code, "-", "", 1) {
+			if !strings.ContainsRune(recoveryCodeAlphabet, r) {
+				t.Fatalf("code %q has %q outside the alphabet", code, r)
+			}
+		}
+
+		if seen[code] {
+			t.Fatalf("code %q repeated", code)
+		}
+		seen[code] = true
+	}
+}
@@ -0,0 +1,193 @@
+package oauth
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// Client - authorization code flow с PKCE для одного провайдера
+type Client struct {
+	client *resty.Client
+	cfg    Config
+}
+
+func New(client *resty.Client, cfg Config) *Client {
+	if cfg.UserInfoAuth == "" {
+		cfg.UserInfoAuth = UserInfoAuthBearer
+	}
+	if cfg.SubjectField == "" {
+		cfg.SubjectField = "sub"
+	}
+
+	return &Client{
+		client: client,
+		cfg:    cfg,
+	}
+}
+
+// AuthCodeURL - куда отправить пользователя для входа у провайдера
+func (c *Client) AuthCodeURL(state, codeVerifier string) (string, error) {
+	authURL, err := url.Parse(c.cfg.AuthURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid auth url: %w", err)
+	}
+
+	query := authURL.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", c.cfg.ClientID)
+	query.Set("redirect_uri", c.cfg.RedirectURL)
+	query.Set("state", state)
+	query.Set("code_challenge", CodeChallenge(codeVerifier))
+	query.Set("code_challenge_method", "S256")
+	if len(c.cfg.Scopes) > 0 {
+		query.Set("scope", strings.Join(c.cfg.Scopes, " "))
+	}
+	authURL.RawQuery = query.Encode()
+
+	return authURL.String(), nil
+}
+
+// Exchange меняет код из редиректа на access token
+func (c *Client) Exchange(ctx context.Context, code, codeVerifier string, extra map[string]string) (Token, error) {
+	form := map[string]string{
+		"grant_type":    "authorization_code",
+		"code":          code,
+		"redirect_uri":  c.cfg.RedirectURL,
+		"client_id":     c.cfg.ClientID,
+		"code_verifier": codeVerifier,
+	}
+	if c.cfg.ClientSecret != "" {
+		form["client_secret"] = c.cfg.ClientSecret
+	}
+	for k, v := range extra {
+		form[k] = v
+	}
+
+	var response Token
+	resp, err := c.client.R().
+		SetContext(ctx).
+		SetHeader("Accept", "application/json").
+		SetFormData(form).
+		SetResult(&response).
+		Post(c.cfg.TokenURL)
+	if err != nil {
+		return Token{}, fmt.Errorf("error exchanging code: %w", err)
+	}
+
+	if resp.IsError() {
+		return Token{}, fmt.Errorf("error exchanging code: %s, body: %s", resp.Status(), resp.Body())
+	}
+
+	if response.AccessToken == "" {
+		return Token{}, fmt.Errorf("error exchanging code: empty access token, body: %s", resp.Body())
+	}
+
+	return response, nil
+}
+
+// UserInfo запрашивает профиль и достает из него поля по путям из конфига
+func (c *Client) UserInfo(ctx context.Context, accessToken string) (UserInfo, error) {
+	req := c.client.R().
+		SetContext(ctx).
+		SetHeader("Accept", "application/json")
+
+	var resp *resty.Response
+	var err error
+	switch c.cfg.UserInfoAuth {
+	case UserInfoAuthForm:
+		resp, err = req.SetFormData(map[string]string{
+			"access_token": accessToken,
+			"client_id":    c.cfg.ClientID,
+		}).Post(c.cfg.UserInfoURL)
+	case UserInfoAuthOAuth:
+		resp, err = req.SetHeader("Authorization", "OAuth "+accessToken).Get(c.cfg.UserInfoURL)
+	default:
+		resp, err = req.SetAuthToken(accessToken).Get(c.cfg.UserInfoURL)
+	}
+	if err != nil {
+		return UserInfo{}, fmt.Errorf("error getting user info: %w", err)
+	}
+
+	if resp.IsError() {
+		return UserInfo{}, fmt.Errorf("error getting user info: %s, body: %s", resp.Status(), resp.Body())
+	}
+
+	var raw map[string]any
+	decoder := json.NewDecoder(bytes.NewReader(resp.Body()))
+	decoder.UseNumber()
+	if err = decoder.Decode(&raw); err != nil {
+		return UserInfo{}, fmt.Errorf("error decoding user info: %w", err)
+	}
+
+	info := UserInfo{
+		Subject: lookup(raw, c.cfg.SubjectField),
+		Email:   lookup(raw, c.cfg.EmailField),
+		Login:   lookup(raw, c.cfg.LoginField),
+	}
+	if info.Subject == "" {
+		return UserInfo{}, fmt.Errorf("error getting user info: no %s in response", c.cfg.SubjectField)
+	}
+
+	if info.Email != "" {
+		if c.cfg.TrustEmail {
+			info.EmailVerified = true
+		} else if c.cfg.EmailVerifiedField != "" {
+			info.EmailVerified, _ = strconv.ParseBool(lookup(raw, c.cfg.EmailVerifiedField))
+		}
+	}
+
+	return info, nil
+}
+
+// GenerateVerifier - случайный code_verifier для PKCE, он же подходит для state
+func GenerateVerifier() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate verifier: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// CodeChallenge - S256 challenge для verifier
+func CodeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// lookup достает значение по пути через точку и приводит его к строке
+func lookup(raw map[string]any, path string) string {
+	if path == "" {
+		return ""
+	}
+
+	var value any = raw
+	for _, key := range strings.Split(path, ".") {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return ""
+		}
+		value = object[key]
+	}
+
+	switch v := value.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,29 @@
+package oauth
+
+const (
+	// UserInfoAuthBearer - Authorization: Bearer <token> (OIDC, Google)
+	UserInfoAuthBearer = "bearer"
+	// UserInfoAuthOAuth - Authorization: OAuth <token> (Yandex ID)
+	UserInfoAuthOAuth = "oauth"
+	// UserInfoAuthForm - POST с access_token и client_id в форме (VK ID)
+	UserInfoAuthForm = "form"
+)
+
+type Config struct {
+	ClientID     string
+	ClientSecret string
+	AuthURL      string
+	TokenURL     string
+	UserInfoURL  string
+	RedirectURL  string
+	Scopes       []string
+	UserInfoAuth string
+
+	// пути к полям в ответе userinfo через точку, например "user.user_id"
+	SubjectField       string
+	EmailField         string
+	EmailVerifiedField string
+	LoginField         string
+	// TrustEmail провайдер отдает только подтвержденную почту, EmailVerifiedField не нужен
+	TrustEmail bool
+}
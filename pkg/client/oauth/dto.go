@@ -0,0 +1,15 @@
+package oauth
+
+type Token struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int    `json:"expires_in"`
+	IDToken     string `json:"id_token"`
+}
+
+type UserInfo struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Login         string
+}
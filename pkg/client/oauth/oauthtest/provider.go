@@ -0,0 +1,191 @@
+// Package oauthtest - локальный фейковый OAuth2 провайдер с PKCE для тестов и разработки.
+// Сразу «входит» под заданным пользователем и проверяет все, что проверил бы настоящий:
+// client_id, redirect_uri, одноразовость кода и code_verifier.
+//
+//	provider := oauthtest.NewProvider("client", oauthtest.User{Subject: "42", Email: "user@example.com", EmailVerified: true})
+//	server := httptest.NewServer(provider)
+//	cfg := provider.Config(server.URL, "http://localhost:3000/oauth/callback")
+package oauthtest
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/musicman-backend/pkg/client/oauth"
+)
+
+type User struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Login         string
+}
+
+type grant struct {
+	challenge   string
+	redirectURI string
+}
+
+type Provider struct {
+	clientID string
+
+	mu     sync.Mutex
+	user   User
+	codes  map[string]grant
+	tokens map[string]User
+	mux    *http.ServeMux
+}
+
+func NewProvider(clientID string, user User) *Provider {
+	p := &Provider{
+		clientID: clientID,
+		user:     user,
+		codes:    make(map[string]grant),
+		tokens:   make(map[string]User),
+		mux:      http.NewServeMux(),
+	}
+
+	p.mux.HandleFunc("/authorize", p.authorize)
+	p.mux.HandleFunc("/token", p.token)
+	p.mux.HandleFunc("/userinfo", p.userInfo)
+
+	return p
+}
+
+// SetUser меняет пользователя, под которым пройдут следующие входы
+func (p *Provider) SetUser(user User) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.user = user
+}
+
+// Config - настройки клиента для провайдера, поднятого на baseURL
+func (p *Provider) Config(baseURL, redirectURL string) oauth.Config {
+	baseURL = strings.TrimRight(baseURL, "/")
+
+	return oauth.Config{
+		ClientID:           p.clientID,
+		ClientSecret:       "secret",
+		AuthURL:            baseURL + "/authorize",
+		TokenURL:           baseURL + "/token",
+		UserInfoURL:        baseURL + "/userinfo",
+		RedirectURL:        redirectURL,
+		Scopes:             []string{"openid", "email"},
+		UserInfoAuth:       oauth.UserInfoAuthBearer,
+		SubjectField:       "sub",
+		EmailField:         "email",
+		EmailVerifiedField: "email_verified",
+		LoginField:         "preferred_username",
+	}
+}
+
+func (p *Provider) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.mux.ServeHTTP(w, r)
+}
+
+func (p *Provider) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("response_type") != "code" || query.Get("client_id") != p.clientID {
+		writeError(w, http.StatusBadRequest, "invalid_request")
+		return
+	}
+	if query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request")
+		return
+	}
+
+	redirect, err := url.Parse(query.Get("redirect_uri"))
+	if err != nil || redirect.Host == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request")
+		return
+	}
+
+	code := randomString()
+
+	p.mu.Lock()
+	p.codes[code] = grant{challenge: query.Get("code_challenge"), redirectURI: redirect.String()}
+	p.mu.Unlock()
+
+	values := redirect.Query()
+	values.Set("code", code)
+	values.Set("state", query.Get("state"))
+	redirect.RawQuery = values.Encode()
+
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+func (p *Provider) token(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.ParseForm() != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request")
+		return
+	}
+	if r.PostForm.Get("grant_type") != "authorization_code" || r.PostForm.Get("client_id") != p.clientID {
+		writeError(w, http.StatusBadRequest, "invalid_client")
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	code := r.PostForm.Get("code")
+	g, ok := p.codes[code]
+	delete(p.codes, code)
+
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if !ok || g.redirectURI != r.PostForm.Get("redirect_uri") || base64.RawURLEncoding.EncodeToString(sum[:]) != g.challenge {
+		writeError(w, http.StatusBadRequest, "invalid_grant")
+		return
+	}
+
+	accessToken := randomString()
+	p.tokens[accessToken] = p.user
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token": accessToken,
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+	})
+}
+
+func (p *Provider) userInfo(w http.ResponseWriter, r *http.Request) {
+	accessToken, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+	p.mu.Lock()
+	user, found := p.tokens[accessToken]
+	p.mu.Unlock()
+
+	if !ok || !found {
+		writeError(w, http.StatusUnauthorized, "invalid_token")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"sub":                user.Subject,
+		"email":              user.Email,
+		"email_verified":     user.EmailVerified,
+		"preferred_username": user.Login,
+	})
+}
+
+func writeError(w http.ResponseWriter, status int, code string) {
+	writeJSON(w, status, map[string]string{"error": code})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func randomString() string {
+	buf := make([]byte, 16)
+	_, _ = rand.Read(buf)
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
@@ -0,0 +1,86 @@
+// Package imaging - проверка загруженных картинок и квадратные миниатюры на чистом Go
+package imaging
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// jpegQuality - качество миниатюр, заметной разницы выше нет, а файлы растут
+const jpegQuality = 85
+
+var (
+	ErrFormat = errors.New("unsupported image format")
+	ErrSize   = errors.New("image size out of bounds")
+)
+
+// Formats - поддерживаемые форматы в терминах image.Decode
+var Formats = map[string]struct{}{
+	"png":  {},
+	"jpeg": {},
+	"gif":  {},
+	"webp": {},
+}
+
+// Limits - допустимая длина каждой стороны картинки в пикселях
+type Limits struct {
+	MinSide int
+	MaxSide int
+}
+
+// Decode читает картинку. Формат и размеры проверяются по заголовку до полного декодирования,
+// чтобы огромная картинка не раздувала память. У gif берется первый кадр
+func Decode(r io.ReadSeeker, limits Limits) (image.Image, error) {
+	cfg, format, err := image.DecodeConfig(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: only png, jpeg, webp and gif are supported", ErrFormat)
+	}
+	if _, ok := Formats[format]; !ok {
+		return nil, fmt.Errorf("%w: %s", ErrFormat, format)
+	}
+
+	if min(cfg.Width, cfg.Height) < limits.MinSide || max(cfg.Width, cfg.Height) > limits.MaxSide {
+		return nil, fmt.Errorf("%w: got %dx%d, each side must be %d to %d px",
+			ErrSize, cfg.Width, cfg.Height, limits.MinSide, limits.MaxSide)
+	}
+
+	if _, err = r.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to rewind image: %w", err)
+	}
+
+	img, _, err := image.Decode(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrFormat, err)
+	}
+
+	return img, nil
+}
+
+// Square вырезает из центра квадрат и уменьшает его до стороны side, маленькие картинки не растягиваются.
+// Прозрачное заливается белым: миниатюры сохраняются в JPEG
+func Square(img image.Image, side int) image.Image {
+	b := img.Bounds()
+	crop := min(b.Dx(), b.Dy())
+	side = min(side, crop)
+
+	x := b.Min.X + (b.Dx()-crop)/2
+	y := b.Min.Y + (b.Dy()-crop)/2
+
+	dst := image.NewRGBA(image.Rect(0, 0, side, side))
+	draw.Draw(dst, dst.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, image.Rect(x, y, x+crop, y+crop), draw.Over, nil)
+
+	return dst
+}
+
+func EncodeJPEG(w io.Writer, img image.Image) error {
+	return jpeg.Encode(w, img, &jpeg.Options{Quality: jpegQuality})
+}
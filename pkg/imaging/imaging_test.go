@@ -0,0 +1,63 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func pngOf(t *testing.T, width, height int) *bytes.Reader {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), A: 255})
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return bytes.NewReader(buf.Bytes())
+}
+
+func TestDecodeLimits(t *testing.T) {
+	limits := Limits{MinSide: 100, MaxSide: 400}
+
+	img, err := Decode(pngOf(t, 300, 100), limits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Bounds().Dx() != 300 || img.Bounds().Dy() != 100 {
+		t.Fatalf("decoded %v, want 300x100", img.Bounds())
+	}
+
+	for _, size := range [][2]int{{99, 200}, {200, 401}} {
+		if _, err = Decode(pngOf(t, size[0], size[1]), limits); !errors.Is(err, ErrSize) {
+			t.Fatalf("%dx%d: got %v, want ErrSize", size[0], size[1], err)
+		}
+	}
+	if _, err = Decode(strings.NewReader("not an image"), limits); !errors.Is(err, ErrFormat) {
+		t.Fatalf("text: got %v, want ErrFormat", err)
+	}
+}
+
+func TestSquare(t *testing.T) {
+	img, err := Decode(pngOf(t, 300, 100), Limits{MaxSide: 1000})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if b := Square(img, 50).Bounds(); b.Dx() != 50 || b.Dy() != 50 {
+		t.Fatalf("thumbnail %v, want 50x50", b)
+	}
+	// маленькая картинка не растягивается до запрошенной стороны
+	if b := Square(img, 500).Bounds(); b.Dx() != 100 || b.Dy() != 100 {
+		t.Fatalf("thumbnail %v, want the 100x100 center crop", b)
+	}
+}
@@ -0,0 +1,61 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// File складывает письма в файл вместо отправки, пустой путь - только в лог.
+// Для локальной разработки и тестов: ссылки из писем видны без почтового сервера
+type File struct {
+	mu   sync.Mutex
+	path string
+	from string
+}
+
+func NewFile(path, from string) *File {
+	return &File{
+		path: path,
+		from: from,
+	}
+}
+
+func (f *File) Send(ctx context.Context, msg Message) error {
+	if err := validateHeader(msg.To); err != nil {
+		return err
+	}
+	if err := validateHeader(msg.Subject); err != nil {
+		return err
+	}
+
+	if f.path == "" {
+		slog.Info("mail",
+			slog.String("to", msg.To),
+			slog.String("subject", msg.Subject),
+			slog.String("body", msg.Body),
+		)
+		return nil
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open mail file: %w", err)
+	}
+
+	_, err = file.Write(append(msg.build(f.from, time.Now()), "\r\n\r\n"...))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write mail: %w", err)
+	}
+
+	return nil
+}
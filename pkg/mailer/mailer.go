@@ -0,0 +1,41 @@
+package mailer
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"strings"
+	"time"
+)
+
+// Message - письмо с текстовым телом
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// build собирает письмо в формате RFC 5322
+func (m Message) build(from string, date time.Time) []byte {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", m.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", m.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	buf.WriteString("\r\n")
+	buf.WriteString(strings.ReplaceAll(strings.ReplaceAll(m.Body, "\r\n", "\n"), "\n", "\r\n"))
+
+	return buf.Bytes()
+}
+
+func validateHeader(value string) error {
+	if strings.ContainsAny(value, "\r\n") {
+		return fmt.Errorf("header contains line break: %q", value)
+	}
+
+	return nil
+}
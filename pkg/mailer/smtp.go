@@ -0,0 +1,75 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// SMTP отправляет письма через SMTP сервер, STARTTLS включается, если сервер его поддерживает
+type SMTP struct {
+	addr string
+	host string
+	auth smtp.Auth
+	from string
+}
+
+func NewSMTP(cfg SMTPConfig) *SMTP {
+	var auth smtp.Auth
+	if cfg.Username != "" {
+		auth = smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)
+	}
+
+	return &SMTP{
+		addr: net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)),
+		host: cfg.Host,
+		auth: auth,
+		from: cfg.From,
+	}
+}
+
+func (s *SMTP) Send(ctx context.Context, msg Message) error {
+	if err := validateHeader(msg.To); err != nil {
+		return err
+	}
+	if err := validateHeader(msg.Subject); err != nil {
+		return err
+	}
+
+	from, err := mail.ParseAddress(s.from)
+	if err != nil {
+		return fmt.Errorf("invalid sender address: %w", err)
+	}
+	to, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return fmt.Errorf("invalid recipient address: %w", err)
+	}
+
+	// net/smtp не принимает контекст, поэтому ждем отправку в отдельной горутине
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- smtp.SendMail(s.addr, s.auth, from.Address, []string{to.Address}, msg.build(s.from, time.Now()))
+	}()
+
+	select {
+	case err = <-errChan:
+		if err != nil {
+			return fmt.Errorf("failed to send mail: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("failed to send mail: %w", ctx.Err())
+	}
+}
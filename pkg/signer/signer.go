@@ -0,0 +1,108 @@
+// Package signer - подпись документов Ed25519. Подпись и ключ передаются в base64 (std, с паддингом),
+// идентификатор ключа - первые 8 байт sha256 от публичного ключа в hex
+package signer
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+)
+
+const Algorithm = "Ed25519"
+
+type Signer struct {
+	private ed25519.PrivateKey
+	keyID   string
+	// retired - ключи, которыми подписывали раньше, по идентификатору
+	retired map[string]ed25519.PublicKey
+}
+
+// New создает подписчика из seed ключа в base64
+func New(seed string) (*Signer, error) {
+	raw, err := base64.StdEncoding.DecodeString(seed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode signing key: %w", err)
+	}
+	if len(raw) != ed25519.SeedSize {
+		return nil, fmt.Errorf("signing key must be %d bytes, got %d", ed25519.SeedSize, len(raw))
+	}
+
+	return fromKey(ed25519.NewKeyFromSeed(raw)), nil
+}
+
+// Generate создает подписчика со случайным ключом. Подписи перестанут проверяться после перезапуска
+func Generate() (*Signer, error) {
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate signing key: %w", err)
+	}
+
+	return fromKey(private), nil
+}
+
+func fromKey(private ed25519.PrivateKey) *Signer {
+	return &Signer{
+		private: private,
+		keyID:   keyID(private.Public().(ed25519.PublicKey)),
+	}
+}
+
+func keyID(public ed25519.PublicKey) string {
+	sum := sha256.Sum256(public)
+
+	return hex.EncodeToString(sum[:8])
+}
+
+// AddRetired добавляет выведенный из оборота публичный ключ в base64. Новые документы им
+// не подписываются, но выданные раньше продолжают проверяться
+func (s *Signer) AddRetired(publicKey string) error {
+	raw, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil {
+		return fmt.Errorf("failed to decode retired key: %w", err)
+	}
+	if len(raw) != ed25519.PublicKeySize {
+		return fmt.Errorf("retired key must be %d bytes, got %d", ed25519.PublicKeySize, len(raw))
+	}
+
+	if s.retired == nil {
+		s.retired = make(map[string]ed25519.PublicKey)
+	}
+	s.retired[keyID(raw)] = raw
+
+	return nil
+}
+
+// Sign подписывает data и возвращает подпись в base64
+func (s *Signer) Sign(data []byte) string {
+	return base64.StdEncoding.EncodeToString(ed25519.Sign(s.private, data))
+}
+
+// Verify проверяет подпись в base64 под data ключом keyID: текущим или выведенным из оборота
+func (s *Signer) Verify(keyID string, data []byte, signature string) bool {
+	public, ok := s.retired[keyID]
+	if keyID == s.keyID {
+		public, ok = s.private.Public().(ed25519.PublicKey), true
+	}
+	if !ok {
+		return false
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil || len(raw) != ed25519.SignatureSize {
+		return false
+	}
+
+	return ed25519.Verify(public, data, raw)
+}
+
+func (s *Signer) KeyID() string {
+	return s.keyID
+}
+
+// PublicKey - публичный ключ в base64, по нему подпись можно проверить без сервера
+func (s *Signer) PublicKey() string {
+	return base64.StdEncoding.EncodeToString(s.private.Public().(ed25519.PublicKey))
+}
@@ -0,0 +1,96 @@
+package signer
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func TestSignVerify(t *testing.T) {
+	s, err := Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := []byte(`{"purchase_id":"1"}`)
+	signature := s.Sign(data)
+
+	if !s.Verify(s.KeyID(), data, signature) {
+		t.Fatal("signature must verify")
+	}
+	if s.Verify(s.KeyID(), []byte(`{"purchase_id":"2"}`), signature) {
+		t.Fatal("signature must not verify other data")
+	}
+	if s.Verify("0000000000000000", data, signature) {
+		t.Fatal("unknown key id must be rejected")
+	}
+	if s.Verify(s.KeyID(), data, "not base64!") || s.Verify(s.KeyID(), data, base64.StdEncoding.EncodeToString([]byte("short"))) {
+		t.Fatal("malformed signature must be rejected")
+	}
+}
+
+func TestNewIsDeterministic(t *testing.T) {
+	seed := base64.StdEncoding.EncodeToString(make([]byte, ed25519.SeedSize))
+
+	first, err := New(seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := New(seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if first.KeyID() != second.KeyID() || first.PublicKey() != second.PublicKey() {
+		t.Fatal("same seed must give the same key")
+	}
+	if len(first.KeyID()) != 16 {
+		t.Fatalf("key id %q must be 8 bytes in hex", first.KeyID())
+	}
+
+	data := []byte("certificate")
+	if !second.Verify(first.KeyID(), data, first.Sign(data)) {
+		t.Fatal("signature must verify after restart with the same seed")
+	}
+}
+
+func TestNewRejectsInvalidSeed(t *testing.T) {
+	for _, seed := range []string{"", "not base64!", base64.StdEncoding.EncodeToString([]byte("short"))} {
+		if _, err := New(seed); err == nil {
+			t.Fatalf("seed %q must be rejected", seed)
+		}
+	}
+}
+
+func TestVerifyRetiredKey(t *testing.T) {
+	old, err := Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	current, err := Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := []byte("receipt")
+	signature := old.Sign(data)
+
+	if current.Verify(old.KeyID(), data, signature) {
+		t.Fatal("old key must not verify before it is added as retired")
+	}
+
+	if err = current.AddRetired(old.PublicKey()); err != nil {
+		t.Fatal(err)
+	}
+	if !current.Verify(old.KeyID(), data, signature) {
+		t.Fatal("retired key must verify documents signed before rotation")
+	}
+	if current.Verify(current.KeyID(), data, signature) {
+		t.Fatal("signature must be checked with the key named by key id")
+	}
+
+	if err = current.AddRetired(strings.Repeat("A", 8)); err == nil {
+		t.Fatal("short retired key must be rejected")
+	}
+}
@@ -0,0 +1,35 @@
+// Package slug - идентификаторы жанров и тегов для ссылок и фильтров
+package slug
+
+import (
+	"strings"
+	"unicode"
+)
+
+// MaxLength - длина slug в базе
+const MaxLength = 50
+
+// Make приводит название к slug: нижний регистр, буквы и цифры, остальное - одиночные дефисы.
+// "Hip Hop", "hip-hop" и " HIP_HOP " дают "hip-hop". Совпадает с правилом миграции справочников
+func Make(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+			continue
+		}
+		dash = true
+	}
+
+	result := []rune(b.String())
+	if len(result) > MaxLength {
+		result = []rune(strings.TrimRight(string(result[:MaxLength]), "-"))
+	}
+
+	return string(result)
+}
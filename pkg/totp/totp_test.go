@@ -0,0 +1,104 @@
+package totp
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// секрет из RFC 6238 "12345678901234567890" в base32
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCodeRFCVectors(t *testing.T) {
+	// последние шесть цифр восьмизначных кодов из приложения B RFC 6238 для SHA1
+	vectors := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, v := range vectors {
+		code, err := Code(rfcSecret, Step(time.Unix(v.unix, 0)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if code != v.code {
+			t.Fatalf("t=%d: code %s, want %s", v.unix, code, v.code)
+		}
+	}
+}
+
+func TestValidateSkew(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	step := Step(now)
+
+	prev, err := Code(rfcSecret, step-1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, ok := Validate(rfcSecret, prev, now, 1)
+	if !ok || got != step-1 {
+		t.Fatalf("previous step code: step %d ok %v, want %d", got, ok, step-1)
+	}
+
+	if _, ok = Validate(rfcSecret, prev, now, 0); ok {
+		t.Fatal("previous step code must be rejected without skew")
+	}
+
+	old, err := Code(rfcSecret, step-2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok = Validate(rfcSecret, old, now, 1); ok {
+		t.Fatal("code outside the window must be rejected")
+	}
+}
+
+func TestValidateRejects(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+
+	for _, code := range []string{"", "00592", "0059240", "005925"} {
+		if _, ok := Validate(rfcSecret, code, now, 1); ok {
+			t.Fatalf("code %q must be rejected", code)
+		}
+	}
+
+	if _, ok := Validate("not base32!", "005924", now, 1); ok {
+		t.Fatal("invalid secret must be rejected")
+	}
+
+	if _, ok := Validate(strings.ToLower(rfcSecret), " 005924 ", now, 0); !ok {
+		t.Fatal("lowercase secret and surrounding spaces must be accepted")
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := encoding.DecodeString(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(key) != secretBytes {
+		t.Fatalf("secret has %d bytes, want %d", len(key), secretBytes)
+	}
+}
+
+func TestURI(t *testing.T) {
+	uri := URI("Musicman", "dj shadow", rfcSecret)
+
+	want := "otpauth://totp/Musicman:dj%20shadow?algorithm=SHA1&digits=6&issuer=Musicman&period=30&secret=" + rfcSecret
+	if uri != want {
+		t.Fatalf("uri %s, want %s", uri, want)
+	}
+}
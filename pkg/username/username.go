@@ -0,0 +1,63 @@
+// Package username - правила логина пользователя, общие для регистрации, смены логина и входа через OAuth
+package username
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+const (
+	MinLength = 3
+	MaxLength = 32
+
+	// deletedPrefix занят за удаленными аккаунтами
+	deletedPrefix = "deleted-"
+)
+
+var (
+	pattern    = regexp.MustCompile(`^[a-zA-Z0-9_.-]+$`)
+	disallowed = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+
+	// reserved совпадают с путями API, например /authors/me
+	reserved = map[string]bool{
+		"me": true,
+	}
+)
+
+// Validate проверяет логин, который выбирает сам пользователь
+func Validate(login string) error {
+	if len(login) < MinLength || len(login) > MaxLength {
+		return fmt.Errorf("login must be %d to %d characters long", MinLength, MaxLength)
+	}
+	if !pattern.MatchString(login) {
+		return errors.New("login may contain only latin letters, digits, '_', '.' and '-'")
+	}
+
+	lower := strings.ToLower(login)
+	if reserved[lower] || strings.HasPrefix(lower, deletedPrefix) {
+		return errors.New("login is reserved")
+	}
+
+	return nil
+}
+
+// Sanitize убирает из строки недопустимые символы и обрезает ее до MaxLength.
+// Результат может оказаться коротким или зарезервированным, его нужно проверить Validate
+func Sanitize(s string) string {
+	s = disallowed.ReplaceAllString(s, "")
+	if len(s) > MaxLength {
+		s = s[:MaxLength]
+	}
+
+	return s
+}
+
+// Deleted - логин удаленного аккаунта. Validate такие логины не пропускает,
+// а полный uuid исключает совпадение двух удаленных аккаунтов
+func Deleted(userUUID uuid.UUID) string {
+	return deletedPrefix + userUUID.String()
+}
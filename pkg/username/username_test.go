@@ -0,0 +1,45 @@
+package username
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestValidate(t *testing.T) {
+	valid := []string{"abc", "dj.shadow", "Beat_Maker-99", strings.Repeat("a", MaxLength), "deleted"}
+	for _, login := range valid {
+		if err := Validate(login); err != nil {
+			t.Fatalf("login %q: %v", login, err)
+		}
+	}
+
+	invalid := []string{"", "ab", strings.Repeat("a", MaxLength+1), "dj shadow", "бит", "me", "ME", "deleted-1234abcd", "Deleted-x"}
+	for _, login := range invalid {
+		if err := Validate(login); err == nil {
+			t.Fatalf("login %q must be rejected", login)
+		}
+	}
+}
+
+func TestSanitize(t *testing.T) {
+	if got := Sanitize("dj shadow!"); got != "djshadow" {
+		t.Fatalf("got %q, want djshadow", got)
+	}
+	if got := Sanitize(strings.Repeat("ab", MaxLength)); len(got) != MaxLength {
+		t.Fatalf("sanitized login has %d characters, want %d", len(got), MaxLength)
+	}
+}
+
+func TestDeleted(t *testing.T) {
+	userUUID := uuid.New()
+
+	login := Deleted(userUUID)
+	if login != "deleted-"+userUUID.String() {
+		t.Fatalf("got %q, want the full uuid", login)
+	}
+	if Validate(login) == nil {
+		t.Fatal("deleted login must not be available for registration")
+	}
+}